@@ -0,0 +1,174 @@
+// Package assetapi defines the requests, and responses of the wallet
+// operations specific to colored channels, in a form the RPC server is able
+// to map directly onto its own messages.
+//
+// The messages of lnrpc are denominated in satoshis, as inherited from lnd.
+// Rather than bolting asset fields onto each of them, the asset operations
+// are described here: every amount is denominated in base units of the asset
+// identified by the message, apart from those explicitly denominated in
+// satoshis, such as the carrier budget and fuel.
+package assetapi
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// ErrMissingAssetID is returned when a request doesn't identify the
+	// asset it operates on.
+	ErrMissingAssetID = errors.New("asset id must be specified")
+
+	// ErrMissingTarget is returned when a request to open a channel
+	// identifies neither the peer, nor the node to open it with.
+	ErrMissingTarget = errors.New("either the target peer id or the " +
+		"target node must be specified")
+
+	// ErrInvalidAmount is returned when an asset amount within a request
+	// is either negative, or zero where a positive amount is required.
+	ErrInvalidAmount = errors.New("invalid asset amount")
+)
+
+// OpenAssetChannelRequest is a request to open a channel denominated in an
+// asset with a remote peer.
+type OpenAssetChannelRequest struct {
+	// TargetPeerID is the ID of the connected peer to open the channel
+	// with. Either it, or TargetNode must be set.
+	TargetPeerID int32 `json:"target_peer_id,omitempty"`
+
+	// TargetNode is the lightning ID of the node to open the channel
+	// with.
+	TargetNode []byte `json:"target_node,omitempty"`
+
+	// AssetID is the identifier of the asset the channel is denominated
+	// in.
+	AssetID string `json:"asset_id"`
+
+	// LocalFundingAmount is the amount of the asset we contribute to the
+	// channel.
+	LocalFundingAmount btcutil.Amount `json:"local_funding_amount"`
+
+	// RemoteFundingAmount is the amount of the asset the remote peer is
+	// requested to contribute to the channel.
+	RemoteFundingAmount btcutil.Amount `json:"remote_funding_amount,omitempty"`
+
+	// CarrierSatBudget is the number of satoshis we're willing to spend
+	// on dust carrier outputs and fees within the channel. If zero, then
+	// the wallet's default budget is used.
+	CarrierSatBudget btcutil.Amount `json:"carrier_sat_budget,omitempty"`
+
+	// NumConfs is the number of confirmations of the funding transaction
+	// required before the channel is considered open.
+	NumConfs uint32 `json:"num_confs,omitempty"`
+}
+
+// Capacity returns the capacity of the requested channel, denominated in its
+// asset.
+func (r *OpenAssetChannelRequest) Capacity() btcutil.Amount {
+	return r.LocalFundingAmount + r.RemoteFundingAmount
+}
+
+// Validate ensures the request identifies the target of the channel, its
+// asset, and a positive capacity funded by non-negative contributions.
+func (r *OpenAssetChannelRequest) Validate() error {
+	switch {
+	case r.TargetPeerID == 0 && len(r.TargetNode) == 0:
+		return ErrMissingTarget
+
+	case len(r.TargetNode) != 0 && len(r.TargetNode) != wire.HashSize:
+		return fmt.Errorf("target node must be %v bytes, got %v",
+			wire.HashSize, len(r.TargetNode))
+
+	case r.AssetID == "":
+		return ErrMissingAssetID
+
+	case r.LocalFundingAmount < 0 || r.RemoteFundingAmount < 0 ||
+		r.Capacity() <= 0:
+		return ErrInvalidAmount
+
+	case r.CarrierSatBudget < 0:
+		return fmt.Errorf("carrier budget of %v cannot be negative",
+			r.CarrierSatBudget)
+	}
+
+	return nil
+}
+
+// AssetBalanceResponse details our balance of an asset, both within the
+// wallet, and across our channels.
+type AssetBalanceResponse struct {
+	// AssetID is the identifier of the asset the balances are
+	// denominated in.
+	AssetID string `json:"asset_id"`
+
+	// WalletBalance is the amount of the asset held within the confirmed
+	// colored outputs of the wallet.
+	WalletBalance btcutil.Amount `json:"wallet_balance"`
+
+	// ChannelBalance is our balance of the asset across all open
+	// channels.
+	ChannelBalance btcutil.Amount `json:"channel_balance"`
+
+	// PendingChannelBalance is our balance of the asset across channels
+	// which are still pending open.
+	PendingChannelBalance btcutil.Amount `json:"pending_channel_balance"`
+
+	// FuelBalance is the number of satoshis held within the uncolored
+	// outputs of the wallet, used to pay for the carrier outputs and
+	// fees of colored transactions.
+	FuelBalance btcutil.Amount `json:"fuel_balance"`
+}
+
+// TotalBalance returns our total balance of the asset, within the wallet and
+// across both open, and pending channels.
+func (r *AssetBalanceResponse) TotalBalance() btcutil.Amount {
+	return r.WalletBalance + r.ChannelBalance + r.PendingChannelBalance
+}
+
+// SendAssetRequest is a request to send a payment denominated in an asset
+// through the network.
+type SendAssetRequest struct {
+	// Dest is the lightning ID of the payment's destination.
+	Dest []byte `json:"dest"`
+
+	// AssetID is the identifier of the asset the payment is denominated
+	// in.
+	AssetID string `json:"asset_id"`
+
+	// Amount is the amount of the asset to be received by the
+	// destination.
+	Amount btcutil.Amount `json:"amount"`
+
+	// MaxFee is the largest fee, in the asset, we're willing to pay to
+	// route the payment. If zero, then the fee is unbounded.
+	MaxFee btcutil.Amount `json:"max_fee,omitempty"`
+
+	// PaymentHash is the hash of the preimage the destination reveals to
+	// settle the payment.
+	PaymentHash []byte `json:"payment_hash,omitempty"`
+}
+
+// Validate ensures the request identifies its destination, and asset, along
+// with a positive amount.
+func (r *SendAssetRequest) Validate() error {
+	switch {
+	case len(r.Dest) != wire.HashSize:
+		return fmt.Errorf("destination must be %v bytes, got %v",
+			wire.HashSize, len(r.Dest))
+
+	case r.AssetID == "":
+		return ErrMissingAssetID
+
+	case r.Amount <= 0 || r.MaxFee < 0:
+		return ErrInvalidAmount
+
+	case len(r.PaymentHash) != 0 && len(r.PaymentHash) != wire.HashSize:
+		return fmt.Errorf("payment hash must be %v bytes, got %v",
+			wire.HashSize, len(r.PaymentHash))
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package assetapi
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestOpenAssetChannelRequestValidate tests that requests to open an asset
+// channel are only accepted with a target, an asset, and a valid capacity.
+func TestOpenAssetChannelRequestValidate(t *testing.T) {
+	validRequest := func() *OpenAssetChannelRequest {
+		return &OpenAssetChannelRequest{
+			TargetNode:         bytes.Repeat([]byte{1}, wire.HashSize),
+			AssetID:            "La3Ubh",
+			LocalFundingAmount: 1000,
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(*OpenAssetChannelRequest)
+		valid  bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(r *OpenAssetChannelRequest) {},
+			valid:  true,
+		},
+		{
+			name: "target peer id",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.TargetNode = nil
+				r.TargetPeerID = 1
+			},
+			valid: true,
+		},
+		{
+			name: "dual funded",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.RemoteFundingAmount = 1000
+			},
+			valid: true,
+		},
+		{
+			name: "no target",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.TargetNode = nil
+			},
+		},
+		{
+			name: "short target node",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.TargetNode = r.TargetNode[1:]
+			},
+		},
+		{
+			name: "no asset",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.AssetID = ""
+			},
+		},
+		{
+			name: "zero capacity",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.LocalFundingAmount = 0
+			},
+		},
+		{
+			name: "negative remote funding",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.RemoteFundingAmount = -1
+			},
+		},
+		{
+			name: "negative carrier budget",
+			mutate: func(r *OpenAssetChannelRequest) {
+				r.CarrierSatBudget = -1
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		req := validRequest()
+		testCase.mutate(req)
+
+		err := req.Validate()
+		switch {
+		case testCase.valid && err != nil:
+			t.Fatalf("%v: valid request rejected: %v", testCase.name,
+				err)
+		case !testCase.valid && err == nil:
+			t.Fatalf("%v: invalid request accepted", testCase.name)
+		}
+	}
+}
+
+// TestSendAssetRequestValidate tests that requests to send an asset payment
+// are only accepted with a destination, an asset, and a positive amount.
+func TestSendAssetRequestValidate(t *testing.T) {
+	validRequest := func() *SendAssetRequest {
+		return &SendAssetRequest{
+			Dest:    bytes.Repeat([]byte{1}, wire.HashSize),
+			AssetID: "La3Ubh",
+			Amount:  10,
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(*SendAssetRequest)
+		valid  bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(r *SendAssetRequest) {},
+			valid:  true,
+		},
+		{
+			name: "payment hash",
+			mutate: func(r *SendAssetRequest) {
+				r.PaymentHash = make([]byte, wire.HashSize)
+			},
+			valid: true,
+		},
+		{
+			name: "no destination",
+			mutate: func(r *SendAssetRequest) {
+				r.Dest = nil
+			},
+		},
+		{
+			name: "no asset",
+			mutate: func(r *SendAssetRequest) {
+				r.AssetID = ""
+			},
+		},
+		{
+			name: "zero amount",
+			mutate: func(r *SendAssetRequest) {
+				r.Amount = 0
+			},
+		},
+		{
+			name: "negative max fee",
+			mutate: func(r *SendAssetRequest) {
+				r.MaxFee = -1
+			},
+		},
+		{
+			name: "short payment hash",
+			mutate: func(r *SendAssetRequest) {
+				r.PaymentHash = make([]byte, wire.HashSize-1)
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		req := validRequest()
+		testCase.mutate(req)
+
+		err := req.Validate()
+		switch {
+		case testCase.valid && err != nil:
+			t.Fatalf("%v: valid request rejected: %v", testCase.name,
+				err)
+		case !testCase.valid && err == nil:
+			t.Fatalf("%v: invalid request accepted", testCase.name)
+		}
+	}
+}
+
+// TestAssetBalanceTotal tests that the total balance of an asset includes
+// the wallet, and both open and pending channels, but not the fuel.
+func TestAssetBalanceTotal(t *testing.T) {
+	resp := &AssetBalanceResponse{
+		AssetID:               "La3Ubh",
+		WalletBalance:         1,
+		ChannelBalance:        10,
+		PendingChannelBalance: 100,
+		FuelBalance:           1000,
+	}
+	if resp.TotalBalance() != 111 {
+		t.Fatalf("expected total balance of 111, got %v",
+			resp.TotalBalance())
+	}
+}
@@ -18,6 +18,11 @@ const (
 	// notifierType uniquely identifies this concrete implementation of the
 	// ChainNotifier interface.
 	notifierType = "btcd"
+
+	// reorgSafetyLimit is the number of blocks for which we retain the
+	// confirmation notifications triggered within each block. Re-orgs
+	// deeper than this limit won't be reported to clients.
+	reorgSafetyLimit = 100
 )
 
 // BtcdNotifier implements the ChainNotifier interface using btcd's websockets
@@ -35,14 +40,25 @@ type BtcdNotifier struct {
 	// clients to listen for same spend. Would we ever need this?
 	spendNotifications map[wire.OutPoint]*spendNotification
 
+	// mempoolSpendNotifications are dispatched as soon as a transaction
+	// spending the target outpoint is accepted into the mempool, rather
+	// than once the spend is confirmed.
+	mempoolSpendNotifications map[wire.OutPoint]*spendNotification
+
 	confNotifications map[wire.ShaHash][]*confirmationsNotification
 	confHeap          *confirmationHeap
 
+	// blockConfs tracks the confirmation notifications whose target
+	// transaction was included within a particular block. If the block
+	// is disconnected, the clients are notified of the re-org, and the
+	// notifications are re-armed.
+	blockConfs map[wire.ShaHash]*blockConfEntry
+
 	blockEpochClients []chan *chainntnfs.BlockEpoch
 
 	connectedBlockHashes    chan *blockNtfn
 	disconnectedBlockHashes chan *blockNtfn
-	relevantTxs             chan *btcutil.Tx
+	relevantTxs             chan *txUpdate
 
 	wg   sync.WaitGroup
 	quit chan struct{}
@@ -58,13 +74,15 @@ func New(config *btcrpcclient.ConnConfig) (*BtcdNotifier, error) {
 	notifier := &BtcdNotifier{
 		notificationRegistry: make(chan interface{}),
 
-		spendNotifications: make(map[wire.OutPoint]*spendNotification),
-		confNotifications:  make(map[wire.ShaHash][]*confirmationsNotification),
-		confHeap:           newConfirmationHeap(),
+		spendNotifications:        make(map[wire.OutPoint]*spendNotification),
+		mempoolSpendNotifications: make(map[wire.OutPoint]*spendNotification),
+		confNotifications:         make(map[wire.ShaHash][]*confirmationsNotification),
+		confHeap:                  newConfirmationHeap(),
+		blockConfs:                make(map[wire.ShaHash]*blockConfEntry),
 
 		connectedBlockHashes:    make(chan *blockNtfn, 20),
 		disconnectedBlockHashes: make(chan *blockNtfn, 20),
-		relevantTxs:             make(chan *btcutil.Tx, 100),
+		relevantTxs:             make(chan *txUpdate, 100),
 
 		quit: make(chan struct{}),
 	}
@@ -130,6 +148,9 @@ func (b *BtcdNotifier) Stop() error {
 	for _, spendClient := range b.spendNotifications {
 		close(spendClient.spendChan)
 	}
+	for _, spendClient := range b.mempoolSpendNotifications {
+		close(spendClient.spendChan)
+	}
 	for _, confClients := range b.confNotifications {
 		for _, confClient := range confClients {
 			close(confClient.finConf)
@@ -157,12 +178,31 @@ func (b *BtcdNotifier) onBlockConnected(hash *wire.ShaHash, height int32, t time
 
 // onBlockDisconnected implements on OnBlockDisconnected callback for btcrpcclient.
 func (b *BtcdNotifier) onBlockDisconnected(hash *wire.ShaHash, height int32, t time.Time) {
+	select {
+	case b.disconnectedBlockHashes <- &blockNtfn{hash, height}:
+	case <-b.quit:
+	}
+}
+
+// blockConfEntry houses the set of confirmation notifications whose target
+// transaction was included within a particular block.
+type blockConfEntry struct {
+	height int32
+	ntfns  []*confirmationsNotification
+}
+
+// txUpdate couples a transaction which spends a watched outpoint along with
+// the details of the block it was included within. If the transaction has
+// only been accepted into the mempool, then details will be nil.
+type txUpdate struct {
+	tx      *btcutil.Tx
+	details *btcjson.BlockDetails
 }
 
 // onRedeemingTx implements on OnRedeemingTx callback for btcrpcclient.
 func (b *BtcdNotifier) onRedeemingTx(transaction *btcutil.Tx, details *btcjson.BlockDetails) {
 	select {
-	case b.relevantTxs <- transaction:
+	case b.relevantTxs <- &txUpdate{transaction, details}:
 	case <-b.quit:
 	}
 }
@@ -177,8 +217,28 @@ out:
 			switch msg := registerMsg.(type) {
 			case *spendNotification:
 				chainntnfs.Log.Infof("New spend subscription: "+
-					"utxo=%v", msg.targetOutpoint)
-				b.spendNotifications[*msg.targetOutpoint] = msg
+					"utxo=%v, mempool=%v", msg.targetOutpoint,
+					msg.mempool)
+				if msg.mempool {
+					b.mempoolSpendNotifications[*msg.targetOutpoint] = msg
+				} else {
+					b.spendNotifications[*msg.targetOutpoint] = msg
+				}
+			case *spendCancel:
+				chainntnfs.Log.Infof("Cancelling spend "+
+					"subscription: utxo=%v, mempool=%v",
+					msg.targetOutpoint, msg.mempool)
+				ntfns := b.spendNotifications
+				if msg.mempool {
+					ntfns = b.mempoolSpendNotifications
+				}
+
+				// A later registration for the same outpoint
+				// replaces the cancelled one, so only drop
+				// the entry if it's still ours.
+				if ntfns[*msg.targetOutpoint] == msg.spendNotification {
+					delete(ntfns, *msg.targetOutpoint)
+				}
 			case *confirmationsNotification:
 				chainntnfs.Log.Infof("New confirmations "+
 					"subscription: txid=%v, numconfs=%v",
@@ -190,13 +250,12 @@ out:
 				b.blockEpochClients = append(b.blockEpochClients,
 					msg.epochChan)
 			}
-		case staleBlockHash := <-b.disconnectedBlockHashes:
-			// TODO(roasbeef): re-orgs
-			//  * second channel to notify of confirmation decrementing
-			//    re-org?
-			//  * notify of negative confirmations
+		case staleBlock := <-b.disconnectedBlockHashes:
 			chainntnfs.Log.Warnf("Block disconnected from main "+
-				"chain: %v", staleBlockHash)
+				"chain: height=%v, sha=%v", staleBlock.height,
+				staleBlock.sha)
+
+			b.handleDisconnectedBlock(staleBlock)
 		case connectedBlock := <-b.connectedBlockHashes:
 			newBlock, err := b.chainConn.GetBlock(connectedBlock.sha)
 			if err != nil {
@@ -219,7 +278,16 @@ out:
 				// on a heap to be triggered in the future once
 				// additional confirmations are attained.
 				txSha := tx.Sha()
-				b.checkConfirmationTrigger(txSha, newHeight)
+				b.checkConfirmationTrigger(txSha,
+					connectedBlock.sha, newHeight)
+			}
+
+			// Prune any confirmation records which are now too
+			// deep to be affected by a re-org.
+			for blockHash, entry := range b.blockConfs {
+				if entry.height <= newHeight-reorgSafetyLimit {
+					delete(b.blockConfs, blockHash)
+				}
 			}
 
 			// A new block has been connected to the main
@@ -227,32 +295,12 @@ out:
 			// which may have been triggered by this new block.
 			b.notifyConfs(newHeight)
 		case newSpend := <-b.relevantTxs:
-			// First, check if this transaction spends an output
-			// that has an existing spend notification for it.
-			for i, txIn := range newSpend.MsgTx().TxIn {
-				prevOut := txIn.PreviousOutPoint
-
-				// If this transaction indeed does spend an
-				// output which we have a registered notification
-				// for, then create a spend summary, finally
-				// sending off the details to the notification
-				// subscriber.
-				if ntfn, ok := b.spendNotifications[prevOut]; ok {
-					spenderSha := newSpend.Sha()
-					spendDetails := &chainntnfs.SpendDetail{
-						SpentOutPoint: ntfn.targetOutpoint,
-						SpenderTxHash: spenderSha,
-						// TODO(roasbeef): copy tx?
-						SpendingTx:        newSpend.MsgTx(),
-						SpenderInputIndex: uint32(i),
-					}
-
-					chainntnfs.Log.Infof("Dispatching "+
-						"spend notification for "+
-						"outpoint=%v", ntfn.targetOutpoint)
-					ntfn.spendChan <- spendDetails
-					delete(b.spendNotifications, prevOut)
-				}
+			// Clients which requested mempool notifications are
+			// notified of the first sighting of the spend, while
+			// all others must wait for it to be confirmed.
+			b.dispatchSpends(newSpend, b.mempoolSpendNotifications)
+			if newSpend.details != nil {
+				b.dispatchSpends(newSpend, b.spendNotifications)
 			}
 		case <-b.quit:
 			break out
@@ -261,6 +309,45 @@ out:
 	b.wg.Done()
 }
 
+// dispatchSpends checks if the passed transaction spends any outputs with a
+// spend notification registered within the passed set of notifications. If
+// so, a spend summary is created and sent to the notification subscriber.
+func (b *BtcdNotifier) dispatchSpends(newSpend *txUpdate,
+	ntfns map[wire.OutPoint]*spendNotification) {
+
+	// A spending height of zero indicates that the spend hasn't yet been
+	// included within a block.
+	var spendHeight int32
+	if newSpend.details != nil {
+		spendHeight = newSpend.details.Height
+	}
+
+	for i, txIn := range newSpend.tx.MsgTx().TxIn {
+		prevOut := txIn.PreviousOutPoint
+
+		// If this transaction indeed does spend an output which we
+		// have a registered notification for, then create a spend
+		// summary, finally sending off the details to the
+		// notification subscriber.
+		if ntfn, ok := ntfns[prevOut]; ok {
+			spendDetails := &chainntnfs.SpendDetail{
+				SpentOutPoint: ntfn.targetOutpoint,
+				SpenderTxHash: newSpend.tx.Sha(),
+				// TODO(roasbeef): copy tx?
+				SpendingTx:        newSpend.tx.MsgTx(),
+				SpenderInputIndex: uint32(i),
+				SpendingHeight:    spendHeight,
+			}
+
+			chainntnfs.Log.Infof("Dispatching spend notification "+
+				"for outpoint=%v, mempool=%v",
+				ntfn.targetOutpoint, ntfn.mempool)
+			ntfn.spendChan <- spendDetails
+			delete(ntfns, prevOut)
+		}
+	}
+}
+
 // notifyBlockEpochs notifies all registered block epoch clients of the newly
 // connected block to the main chain.
 func (b *BtcdNotifier) notifyBlockEpochs(newHeight int32, newSha *wire.ShaHash) {
@@ -314,7 +401,8 @@ func (b *BtcdNotifier) notifyConfs(newBlockHeight int32) {
 // matches, yet needs additional confirmations, it is added to the confirmation
 // heap to be triggered at a later time.
 // TODO(roasbeef): perhaps lookup, then track by inputs instead?
-func (b *BtcdNotifier) checkConfirmationTrigger(txSha *wire.ShaHash, blockHeight int32) {
+func (b *BtcdNotifier) checkConfirmationTrigger(txSha, blockHash *wire.ShaHash,
+	blockHeight int32) {
 
 	// If a confirmation notification has been registered
 	// for this txid, then either trigger a notification
@@ -330,6 +418,15 @@ func (b *BtcdNotifier) checkConfirmationTrigger(txSha *wire.ShaHash, blockHeight
 			delete(b.confNotifications, *txSha)
 		}()
 
+		// Record the block which confirmed the transaction so we're
+		// able to re-arm the notifications in the case of a re-org.
+		entry, ok := b.blockConfs[*blockHash]
+		if !ok {
+			entry = &blockConfEntry{height: blockHeight}
+			b.blockConfs[*blockHash] = entry
+		}
+		entry.ntfns = append(entry.ntfns, confClients...)
+
 		for _, confClient := range confClients {
 			if confClient.numConfirmations == 1 {
 				chainntnfs.Log.Infof("Dispatching single conf "+
@@ -356,14 +453,90 @@ func (b *BtcdNotifier) checkConfirmationTrigger(txSha *wire.ShaHash, blockHeight
 	}
 }
 
+// handleDisconnectedBlock processes a block which has been disconnected from
+// the main chain. Each client whose target transaction was included within
+// the stale block is sent the depth of the re-org over its NegativeConf
+// channel. The related notifications are then removed from the confirmation
+// heap, and re-registered so they'll be dispatched once again if the
+// transaction is re-included within the main chain.
+func (b *BtcdNotifier) handleDisconnectedBlock(staleBlock *blockNtfn) {
+	entry, ok := b.blockConfs[*staleBlock.sha]
+	if !ok {
+		return
+	}
+	delete(b.blockConfs, *staleBlock.sha)
+
+	for _, confClient := range entry.ntfns {
+		reorgDepth := staleBlock.height - int32(confClient.initialConfirmHeight) + 1
+		if reorgDepth < 1 {
+			reorgDepth = 1
+		}
+
+		chainntnfs.Log.Infof("Dispatching negative conf notification, "+
+			"sha=%v, depth=%v", confClient.txid, reorgDepth)
+
+		// Attempt a non-blocking send. If the client hasn't yet
+		// consumed a prior re-org notification, then it's already
+		// aware of the transaction's disconnection.
+		select {
+		case confClient.negativeConf <- reorgDepth:
+		default:
+		}
+
+		// Any confirmation the client hasn't yet consumed is now
+		// stale, so we drain it to make room for the next one.
+		select {
+		case <-confClient.finConf:
+		default:
+		}
+
+		// If the notification is still awaiting additional
+		// confirmations, then remove it from the heap.
+		for i, heapEntry := range b.confHeap.items {
+			if heapEntry.confirmationsNotification == confClient {
+				heap.Remove(b.confHeap, i)
+				break
+			}
+		}
+
+		// Finally, re-arm the notification so it'll trigger once the
+		// transaction has been re-confirmed.
+		confClient.initialConfirmHeight = 0
+		txid := *confClient.txid
+		b.confNotifications[txid] = append(b.confNotifications[txid],
+			confClient)
+	}
+}
+
 // spendNotification couples a target outpoint along with the channel used for
 // notifications once a spend of the outpoint has been detected.
 type spendNotification struct {
 	targetOutpoint *wire.OutPoint
 
+	// mempool indicates that the notification should be dispatched once
+	// the spending transaction is accepted into the mempool.
+	mempool bool
+
 	spendChan chan *chainntnfs.SpendDetail
 }
 
+// spendCancel is a message sent to the notificationDispatcher in order to
+// drop a prior spend registration.
+type spendCancel struct {
+	*spendNotification
+}
+
+// cancelSpend returns a closure which drops the passed spend registration
+// once executed.
+func (b *BtcdNotifier) cancelSpend(ntfn *spendNotification) func() {
+	return func() {
+		select {
+		case b.notificationRegistry <- &spendCancel{ntfn}:
+		case <-b.quit:
+		}
+	}
+}
+
 // RegisterSpendNotification registers an intent to be notified once the target
 // outpoint has been spent by a transaction on-chain. Once a spend of the target
 // outpoint has been detected, the details of the spending event will be sent
@@ -380,7 +553,34 @@ func (b *BtcdNotifier) RegisterSpendNtfn(outpoint *wire.OutPoint) (*chainntnfs.S
 
 	b.notificationRegistry <- ntfn
 
-	return &chainntnfs.SpendEvent{ntfn.spendChan}, nil
+	return &chainntnfs.SpendEvent{
+		Spend:  ntfn.spendChan,
+		Cancel: b.cancelSpend(ntfn),
+	}, nil
+}
+
+// RegisterMempoolSpendNtfn registers an intent to be notified once the target
+// outpoint has been spent by a transaction which has been accepted into the
+// mempool. The notification is dispatched either when the spending
+// transaction is first seen within the mempool, or once it's included within
+// a block, whichever happens first.
+func (b *BtcdNotifier) RegisterMempoolSpendNtfn(outpoint *wire.OutPoint) (*chainntnfs.SpendEvent, error) {
+	if err := b.chainConn.NotifySpent([]*wire.OutPoint{outpoint}); err != nil {
+		return nil, err
+	}
+
+	ntfn := &spendNotification{
+		targetOutpoint: outpoint,
+		mempool:        true,
+		spendChan:      make(chan *chainntnfs.SpendDetail, 1),
+	}
+
+	b.notificationRegistry <- ntfn
+
+	return &chainntnfs.SpendEvent{
+		Spend:  ntfn.spendChan,
+		Cancel: b.cancelSpend(ntfn),
+	}, nil
 }
 
 // confirmationNotification represents a client's intent to receive a
@@ -392,7 +592,7 @@ type confirmationsNotification struct {
 	numConfirmations     uint32
 
 	finConf      chan int32
-	negativeConf chan int32 // TODO(roasbeef): re-org funny business
+	negativeConf chan int32
 }
 
 // RegisterConfirmationsNotification registers a notification with BtcdNotifier
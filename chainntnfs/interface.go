@@ -29,11 +29,17 @@ type ChainNotifier interface {
 	// outpoint is succesfully spent within a confirmed transaction. The
 	// returned SpendEvent will receive a send on the 'Spend' transaction
 	// once a transaction spending the input is detected on the blockchain.
-	//
-	// NOTE: This notifications should be triggered once the transaction is
-	// *seen* on the network, not when it has received a single confirmation.
 	RegisterSpendNtfn(outpoint *wire.OutPoint) (*SpendEvent, error)
 
+	// RegisterMempoolSpendNtfn registers an intent to be notified once the
+	// target outpoint is spent by a transaction accepted into the mempool.
+	// Unlike RegisterSpendNtfn, this notification is triggered once the
+	// spending transaction is *seen* on the network, allowing callers to
+	// react to a spend (such as the broadcast of a revoked commitment)
+	// before it has received a single confirmation. The SpendingHeight of
+	// the dispatched SpendDetail will be zero if the spend is unconfirmed.
+	RegisterMempoolSpendNtfn(outpoint *wire.OutPoint) (*SpendEvent, error)
+
 	// RegisterBlockEpochNtfn registers an intent to be notified of each
 	// new block connected to the tip of the main chain. The returned
 	// BlockEpochEvent struct contains a channel which will be sent upon
@@ -63,13 +69,12 @@ type ChainNotifier interface {
 //
 // If the event that the original transaction becomes re-org'd out of the main
 // chain, the 'NegativeConf' will be sent upon with a value representing the
-// depth of the re-org.
+// depth of the re-org. The notification is then re-armed, so the 'Confirmed'
+// channel will be sent upon once again after the transaction has been
+// re-included within the main chain and reaches the targeted number of
+// confirmations.
 type ConfirmationEvent struct {
 	Confirmed chan int32 // MUST be buffered.
-	// TODO(roasbeef): all goroutines on ln channel updates should also
-	// have a struct chan that's closed if funding gets re-org out. Need
-	// to sync, to request another confirmation event ntfn, then re-open
-	// channel after confs.
 
 	NegativeConf chan int32 // MUST be buffered.
 }
@@ -87,11 +92,16 @@ type SpendDetail struct {
 	SpendingHeight    int32
 }
 
-// SpendEvent encapsulates a spentness notification. Its only field 'Spend' will
-// be sent upon once the target output passed into RegisterSpendNtfn has been
+// SpendEvent encapsulates a spentness notification. Its field 'Spend' will be
+// sent upon once the target output passed into RegisterSpendNtfn has been
 // spent on the blockchain.
 type SpendEvent struct {
 	Spend chan *SpendDetail // MUST be buffered.
+
+	// Cancel is a closure which should be executed by the caller once
+	// the spend is no longer of interest. The notifier then drops the
+	// registration, after which the Spend channel is never sent upon.
+	Cancel func()
 }
 
 // BlockEpoch represents meta-data concerning each new block connected to the
@@ -283,6 +283,156 @@ func testSpendNotification(miner *rpctest.Harness,
 	}
 }
 
+func testMempoolSpendNotification(miner *rpctest.Harness,
+	notifier chainntnfs.ChainNotifier, t *testing.T) {
+
+	// We'd like to test that a client registered for a mempool spend
+	// notification is notified as soon as the spending transaction is
+	// broadcast, without any blocks being mined.
+	txid, err := getTestTxId(miner)
+	if err != nil {
+		t.Fatalf("unable to create test addr: %v", err)
+	}
+	if _, err := miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate single block: %v", err)
+	}
+
+	wrappedTx, err := miner.Node.GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("unable to get new tx: %v", err)
+	}
+	tx := wrappedTx.MsgTx()
+
+	outIndex := -1
+	var pkScript []byte
+	for i, txOut := range tx.TxOut {
+		if bytes.Contains(txOut.PkScript, testAddr.ScriptAddress()) {
+			pkScript = txOut.PkScript
+			outIndex = i
+			break
+		}
+	}
+	if outIndex == -1 {
+		t.Fatalf("unable to locate new output")
+	}
+
+	outpoint := wire.NewOutPoint(txid, uint32(outIndex))
+	spentIntent, err := notifier.RegisterMempoolSpendNtfn(outpoint)
+	if err != nil {
+		t.Fatalf("unable to register for spend ntfn: %v", err)
+	}
+
+	spendingTx := wire.NewMsgTx()
+	spendingTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *outpoint,
+	})
+	spendingTx.AddTxOut(&wire.TxOut{
+		Value:    1e8,
+		PkScript: pkScript,
+	})
+	sigScript, err := txscript.SignatureScript(spendingTx, 0, pkScript,
+		txscript.SigHashAll, privKey, true)
+	if err != nil {
+		t.Fatalf("unable to sign tx: %v", err)
+	}
+	spendingTx.TxIn[0].SignatureScript = sigScript
+
+	// Broadcast our spending transaction, but don't mine a block. The
+	// notification should be sent off regardless.
+	spenderSha, err := miner.Node.SendRawTransaction(spendingTx, true)
+	if err != nil {
+		t.Fatalf("unable to brodacst tx: %v", err)
+	}
+
+	select {
+	case ntfn := <-spentIntent.Spend:
+		if !bytes.Equal(ntfn.SpenderTxHash.Bytes(), spenderSha.Bytes()) {
+			t.Fatalf("ntfn includes wrong spender tx sha, reports %v intead of %v",
+				ntfn.SpenderTxHash.Bytes(), spenderSha.Bytes())
+		}
+		if ntfn.SpendingHeight != 0 {
+			t.Fatalf("unconfirmed spend should have a height of "+
+				"zero, instead has %v", ntfn.SpendingHeight)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("mempool spend ntfn never received")
+	}
+
+	// Clear out the mempool for the following tests.
+	if _, err := miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate single block: %v", err)
+	}
+}
+
+func testCancelSpendNotification(miner *rpctest.Harness,
+	notifier chainntnfs.ChainNotifier, t *testing.T) {
+
+	// We'd like to test that once a spend notification has been
+	// cancelled, the spend of its target outpoint is no longer
+	// dispatched to the client.
+	txid, err := getTestTxId(miner)
+	if err != nil {
+		t.Fatalf("unable to create test addr: %v", err)
+	}
+	if _, err := miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate single block: %v", err)
+	}
+
+	wrappedTx, err := miner.Node.GetRawTransaction(txid)
+	if err != nil {
+		t.Fatalf("unable to get new tx: %v", err)
+	}
+	tx := wrappedTx.MsgTx()
+
+	outIndex := -1
+	var pkScript []byte
+	for i, txOut := range tx.TxOut {
+		if bytes.Contains(txOut.PkScript, testAddr.ScriptAddress()) {
+			pkScript = txOut.PkScript
+			outIndex = i
+			break
+		}
+	}
+	if outIndex == -1 {
+		t.Fatalf("unable to locate new output")
+	}
+
+	outpoint := wire.NewOutPoint(txid, uint32(outIndex))
+	spentIntent, err := notifier.RegisterSpendNtfn(outpoint)
+	if err != nil {
+		t.Fatalf("unable to register for spend ntfn: %v", err)
+	}
+	spentIntent.Cancel()
+
+	spendingTx := wire.NewMsgTx()
+	spendingTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *outpoint,
+	})
+	spendingTx.AddTxOut(&wire.TxOut{
+		Value:    1e8,
+		PkScript: pkScript,
+	})
+	sigScript, err := txscript.SignatureScript(spendingTx, 0, pkScript,
+		txscript.SigHashAll, privKey, true)
+	if err != nil {
+		t.Fatalf("unable to sign tx: %v", err)
+	}
+	spendingTx.TxIn[0].SignatureScript = sigScript
+
+	if _, err := miner.Node.SendRawTransaction(spendingTx, true); err != nil {
+		t.Fatalf("unable to brodacst tx: %v", err)
+	}
+	if _, err := miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate single block: %v", err)
+	}
+
+	select {
+	case <-spentIntent.Spend:
+		t.Fatalf("spend ntfn dispatched after being cancelled")
+	case <-time.After(2 * time.Second):
+	}
+}
+
 func testBlockEpochNotification(miner *rpctest.Harness,
 	notifier chainntnfs.ChainNotifier, t *testing.T) {
 
@@ -387,6 +537,8 @@ var ntfnTests = []func(node *rpctest.Harness, notifier chainntnfs.ChainNotifier,
 	testBatchConfirmationNotification,
 	testMultiClientConfirmationNotification,
 	testSpendNotification,
+	testMempoolSpendNotification,
+	testCancelSpendNotification,
 	testBlockEpochNotification,
 }
 
@@ -0,0 +1,205 @@
+package chainntnfs
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// HeightScheduler is a small service built on top of a stream of block epoch
+// notifications which allows callers to register an intent to have a
+// function executed once the main chain reaches a target height. The
+// utxoNursery uses the scheduler to sweep time-locked outputs once their CSV
+// delay has passed, and to bump the fees of unconfirmed sweeps, rather than
+// scanning all of its outputs with each new block.
+//
+// As the scheduler is driven purely by the passed BlockEpochEvent, timeout
+// handling can be tested by feeding the scheduler a fake stream of blocks.
+type HeightScheduler struct {
+	started int32 // To be used atomically.
+	stopped int32 // To be used atomically.
+
+	epochs *BlockEpochEvent
+
+	// bestHeight is the height of the latest block the scheduler has
+	// processed.
+	bestHeight int32
+
+	// taskMtx guards access to the task heap, and bestHeight.
+	taskMtx sync.Mutex
+	tasks   taskHeap
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewHeightScheduler creates a new instance of the HeightScheduler which will
+// dispatch tasks as new blocks are received over the passed BlockEpochEvent.
+// The startHeight should be the height of the current tip of the main chain.
+func NewHeightScheduler(epochs *BlockEpochEvent,
+	startHeight int32) *HeightScheduler {
+
+	return &HeightScheduler{
+		epochs:     epochs,
+		bestHeight: startHeight,
+		quit:       make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine responsible for dispatching tasks as new
+// blocks are connected.
+func (h *HeightScheduler) Start() error {
+	if atomic.AddInt32(&h.started, 1) != 1 {
+		return nil
+	}
+
+	h.wg.Add(1)
+	go h.scheduler()
+
+	return nil
+}
+
+// Stop signals the scheduler for a graceful shutdown. Any tasks which haven't
+// yet been triggered are discarded.
+func (h *HeightScheduler) Stop() error {
+	if atomic.AddInt32(&h.stopped, 1) != 1 {
+		return nil
+	}
+
+	close(h.quit)
+	h.wg.Wait()
+
+	return nil
+}
+
+// ScheduleAt registers a function to be executed once the main chain reaches
+// the target height. The function is passed the height of the block which
+// triggered its execution. If the target height has already been reached,
+// then the function is executed immediately.
+//
+// NOTE: Scheduled functions are executed serially from within the scheduler,
+// so they MUST NOT block.
+func (h *HeightScheduler) ScheduleAt(height int32, f func(int32)) error {
+	if atomic.LoadInt32(&h.stopped) != 0 {
+		return fmt.Errorf("scheduler is shutting down")
+	}
+
+	h.taskMtx.Lock()
+	bestHeight := h.bestHeight
+	if height > bestHeight {
+		heap.Push(&h.tasks, &scheduledTask{
+			height: height,
+			f:      f,
+		})
+		h.taskMtx.Unlock()
+		return nil
+	}
+	h.taskMtx.Unlock()
+
+	f(bestHeight)
+	return nil
+}
+
+// ScheduleAfter registers a function to be executed once numBlocks have been
+// connected on top of the current best block.
+func (h *HeightScheduler) ScheduleAfter(numBlocks uint32, f func(int32)) error {
+	return h.ScheduleAt(h.BestHeight()+int32(numBlocks), f)
+}
+
+// BestHeight returns the height of the latest block processed by the
+// scheduler.
+func (h *HeightScheduler) BestHeight() int32 {
+	h.taskMtx.Lock()
+	defer h.taskMtx.Unlock()
+
+	return h.bestHeight
+}
+
+// PendingTasks returns the number of tasks which have yet to be triggered.
+func (h *HeightScheduler) PendingTasks() int {
+	h.taskMtx.Lock()
+	defer h.taskMtx.Unlock()
+
+	return h.tasks.Len()
+}
+
+// scheduler is the primary goroutine of the HeightScheduler. With each new
+// block epoch, all tasks whose target height has been reached are executed.
+//
+// NOTE: This MUST be run as a goroutine.
+func (h *HeightScheduler) scheduler() {
+	defer h.wg.Done()
+
+	for {
+		select {
+		case epoch, ok := <-h.epochs.Epochs:
+			if !ok {
+				return
+			}
+
+			for _, task := range h.triggeredTasks(epoch.Height) {
+				task.f(epoch.Height)
+			}
+		case <-h.quit:
+			return
+		}
+	}
+}
+
+// triggeredTasks updates the best known height, then pops and returns all
+// tasks whose target height is at or below the new height.
+func (h *HeightScheduler) triggeredTasks(newHeight int32) []*scheduledTask {
+	h.taskMtx.Lock()
+	defer h.taskMtx.Unlock()
+
+	if newHeight > h.bestHeight {
+		h.bestHeight = newHeight
+	}
+
+	var triggered []*scheduledTask
+	for h.tasks.Len() > 0 && h.tasks[0].height <= h.bestHeight {
+		triggered = append(triggered, heap.Pop(&h.tasks).(*scheduledTask))
+	}
+
+	return triggered
+}
+
+// scheduledTask is a function to be executed once the main chain reaches a
+// particular height.
+type scheduledTask struct {
+	height int32
+	f      func(int32)
+}
+
+// taskHeap is a min-heap of scheduledTasks ordered by their target height.
+type taskHeap []*scheduledTask
+
+// Len returns the number of items in the priority queue. It is part of the
+// heap.Interface implementation.
+func (t taskHeap) Len() int { return len(t) }
+
+// Less returns whether the item in the priority queue with index i should sort
+// before the item with index j. It is part of the heap.Interface implementation.
+func (t taskHeap) Less(i, j int) bool { return t[i].height < t[j].height }
+
+// Swap swaps the items at the passed indices in the priority queue. It is
+// part of the heap.Interface implementation.
+func (t taskHeap) Swap(i, j int) { t[i], t[j] = t[j], t[i] }
+
+// Push pushes the passed item onto the priority queue. It is part of the
+// heap.Interface implementation.
+func (t *taskHeap) Push(x interface{}) {
+	*t = append(*t, x.(*scheduledTask))
+}
+
+// Pop removes the highest priority item (according to Less) from the priority
+// queue and returns it. It is part of the heap.Interface implementation.
+func (t *taskHeap) Pop() interface{} {
+	old := *t
+	n := len(old)
+	x := old[n-1]
+	old[n-1] = nil
+	*t = old[0 : n-1]
+	return x
+}
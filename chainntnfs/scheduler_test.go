@@ -0,0 +1,84 @@
+package chainntnfs
+
+import (
+	"testing"
+	"time"
+)
+
+// TestHeightScheduler tests that tasks registered with the HeightScheduler are
+// executed once, and only once, a block at their target height is received.
+func TestHeightScheduler(t *testing.T) {
+	epochs := &BlockEpochEvent{
+		Epochs: make(chan *BlockEpoch, 20),
+	}
+	scheduler := NewHeightScheduler(epochs, 100)
+	if err := scheduler.Start(); err != nil {
+		t.Fatalf("unable to start scheduler: %v", err)
+	}
+	defer scheduler.Stop()
+
+	// A task scheduled at, or below the current height should be executed
+	// immediately.
+	var immediateHeight int32
+	scheduler.ScheduleAt(90, func(h int32) {
+		immediateHeight = h
+	})
+	if immediateHeight != 100 {
+		t.Fatalf("task should've been executed at height 100, instead "+
+			"executed at %v", immediateHeight)
+	}
+
+	// Next, schedule a series of tasks in the future, out of order.
+	triggered := make(chan int32, 3)
+	for _, height := range []int32{103, 101, 102} {
+		scheduler.ScheduleAt(height, func(h int32) {
+			triggered <- h
+		})
+	}
+	if scheduler.PendingTasks() != 3 {
+		t.Fatalf("expected 3 pending tasks, instead have %v",
+			scheduler.PendingTasks())
+	}
+
+	// Feed the scheduler a fake chain, one block at a time. Each block
+	// should trigger exactly one task, in order of height.
+	for height := int32(101); height <= 103; height++ {
+		epochs.Epochs <- &BlockEpoch{Height: height}
+
+		select {
+		case h := <-triggered:
+			if h != height {
+				t.Fatalf("task triggered at wrong height: "+
+					"expected %v, got %v", height, h)
+			}
+		case <-time.After(time.Second * 2):
+			t.Fatalf("task at height %v never triggered", height)
+		}
+	}
+
+	if scheduler.PendingTasks() != 0 {
+		t.Fatalf("expected no pending tasks, instead have %v",
+			scheduler.PendingTasks())
+	}
+	if scheduler.BestHeight() != 103 {
+		t.Fatalf("expected best height of 103, instead have %v",
+			scheduler.BestHeight())
+	}
+
+	// Finally, a task scheduled relative to the current height should be
+	// triggered after the proper number of blocks, even if the chain skips
+	// a height.
+	scheduler.ScheduleAfter(2, func(h int32) {
+		triggered <- h
+	})
+	epochs.Epochs <- &BlockEpoch{Height: 106}
+	select {
+	case h := <-triggered:
+		if h != 106 {
+			t.Fatalf("task triggered at wrong height: expected "+
+				"106, got %v", h)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatalf("relative task never triggered")
+	}
+}
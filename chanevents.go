@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// chanEventPostTimeout is the time an external system is given to accept an
+// event posted by a channel event webhook.
+const chanEventPostTimeout = 10 * time.Second
+
+// ChannelEventType denotes the kind of event a ChannelEvent describes.
+type ChannelEventType uint8
+
+const (
+	// ChannelOpened is dispatched once the funding transaction of a
+	// channel has been confirmed, and the channel is ready for use.
+	ChannelOpened ChannelEventType = iota
+
+	// ChannelClosed is dispatched once a channel has been closed, either
+	// cooperatively, or unilaterally by either party.
+	ChannelClosed
+
+	// ChannelBreached is dispatched once the remote party has broadcast a
+	// revoked commitment transaction of a channel.
+	ChannelBreached
+
+	// HTLCSettled is dispatched once an HTLC has been settled. An
+	// incoming HTLC is settled once we reveal its preimage, while an
+	// outgoing HTLC is settled once the remote party's settle has been
+	// locked into both commitment chains.
+	HTLCSettled
+
+	// FundingChangeConfirmed is dispatched for each of our change outputs
+	// within the funding transaction of a channel once it has been
+	// confirmed, as the funds they hold are then spendable again.
+	FundingChangeConfirmed
+)
+
+// String returns a human readable representation of the event type.
+func (t ChannelEventType) String() string {
+	switch t {
+	case ChannelOpened:
+		return "ChannelOpened"
+	case ChannelClosed:
+		return "ChannelClosed"
+	case ChannelBreached:
+		return "ChannelBreached"
+	case HTLCSettled:
+		return "HTLCSettled"
+	case FundingChangeConfirmed:
+		return "FundingChangeConfirmed"
+	default:
+		return "Unknown"
+	}
+}
+
+// ChannelEvent describes a change to one of our channels which is of interest
+// to systems keeping their own accounts of the funds held within them, such
+// as exchanges crediting, or debiting their users.
+type ChannelEvent struct {
+	// Type is the kind of event which has taken place.
+	Type ChannelEventType
+
+	// Timestamp is the time at which the event was dispatched.
+	Timestamp time.Time
+
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// RemoteID is the identity of the remote party of the channel.
+	RemoteID [32]byte
+
+	// AssetID is the identifier of the asset the channel is denominated
+	// in. An empty AssetID denotes a channel of plain satoshis. For
+	// FundingChangeConfirmed events, it's instead the asset carried by
+	// the change output, which is empty for uncolored fuel change.
+	AssetID string
+
+	// Capacity, LocalBalance and RemoteBalance are the settled state of
+	// the channel at the time of the event, denominated in the channel's
+	// asset.
+	Capacity      btcutil.Amount
+	LocalBalance  btcutil.Amount
+	RemoteBalance btcutil.Amount
+
+	// CloseType and ClosingTxid detail the closure of the channel, and
+	// are only set for ChannelClosed and ChannelBreached events. The
+	// ClosingTxid may be nil if the closing transaction is unknown.
+	CloseType   channeldb.ClosureType
+	ClosingTxid *wire.ShaHash
+
+	// Incoming, PaymentHash and Amount detail the settled HTLC, and are
+	// only set for HTLCSettled events.
+	Incoming    bool
+	PaymentHash [32]byte
+	Amount      btcutil.Amount
+
+	// ChangeOutPoint is the outpoint of the confirmed change output, and
+	// is only set for FundingChangeConfirmed events, along with the
+	// Amount of the change.
+	ChangeOutPoint *wire.OutPoint
+}
+
+// ChannelEventHook is a callback invoked upon each ChannelEvent. Hooks are
+// invoked sequentially from a single goroutine, in the order the events took
+// place, so a hook which blocks delays the delivery of all later events, but
+// never the operation of the channels themselves.
+type ChannelEventHook func(event *ChannelEvent)
+
+// channelEventBus dispatches the events of all our channels to the registered
+// hooks. Events are queued without bound, so the channel state machines
+// dispatching them are never blocked by a slow hook.
+type channelEventBus struct {
+	started  int32 // atomic
+	shutdown int32 // atomic
+
+	sync.Mutex
+	hooks      map[uint64]ChannelEventHook
+	nextHookID uint64
+	queue      []*ChannelEvent
+
+	// newEvents is sent upon once an event is added to an empty queue.
+	newEvents chan struct{}
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// newChannelEventBus creates a new channelEventBus without any registered
+// hooks.
+func newChannelEventBus() *channelEventBus {
+	return &channelEventBus{
+		hooks:     make(map[uint64]ChannelEventHook),
+		newEvents: make(chan struct{}, 1),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine dispatching events to the registered hooks.
+func (b *channelEventBus) Start() error {
+	if !atomic.CompareAndSwapInt32(&b.started, 0, 1) {
+		return nil
+	}
+
+	b.wg.Add(1)
+	go b.eventDispatcher()
+
+	return nil
+}
+
+// Stop gracefully stops the dispatch of events, then waits until the
+// dispatching goroutine has exited. Events still queued are dropped.
+func (b *channelEventBus) Stop() error {
+	if !atomic.CompareAndSwapInt32(&b.shutdown, 0, 1) {
+		return nil
+	}
+
+	close(b.quit)
+	b.wg.Wait()
+
+	return nil
+}
+
+// subscribe registers the passed hook, returning a function which cancels
+// the registration.
+func (b *channelEventBus) subscribe(hook ChannelEventHook) func() {
+	b.Lock()
+	defer b.Unlock()
+
+	hookID := b.nextHookID
+	b.nextHookID++
+	b.hooks[hookID] = hook
+
+	return func() {
+		b.Lock()
+		delete(b.hooks, hookID)
+		b.Unlock()
+	}
+}
+
+// notify queues the passed event for dispatch to all registered hooks. If no
+// hooks are registered, then the event is discarded.
+func (b *channelEventBus) notify(event *ChannelEvent) {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.hooks) == 0 {
+		return
+	}
+
+	event.Timestamp = time.Now()
+	b.queue = append(b.queue, event)
+
+	select {
+	case b.newEvents <- struct{}{}:
+	default:
+	}
+}
+
+// eventDispatcher is the goroutine which drains the event queue, invoking
+// each registered hook upon each event in turn.
+//
+// NOTE: This MUST be run as a goroutine.
+func (b *channelEventBus) eventDispatcher() {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case <-b.newEvents:
+		case <-b.quit:
+			return
+		}
+
+		for {
+			b.Lock()
+			if len(b.queue) == 0 {
+				b.Unlock()
+				break
+			}
+			event := b.queue[0]
+			b.queue[0] = nil
+			b.queue = b.queue[1:]
+
+			hooks := make([]ChannelEventHook, 0, len(b.hooks))
+			for _, hook := range b.hooks {
+				hooks = append(hooks, hook)
+			}
+			b.Unlock()
+
+			for _, hook := range hooks {
+				hook(event)
+			}
+
+			select {
+			case <-b.quit:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// RegisterChannelEventHook registers the passed hook to be invoked upon the
+// opening, closure and breach of each of our channels, and the settlement of
+// each HTLC over them, returning a function which cancels the registration.
+func (s *server) RegisterChannelEventHook(hook ChannelEventHook) func() {
+	return s.chanEvents.subscribe(hook)
+}
+
+// channelEventJSON is the JSON encoding of a ChannelEvent, as posted by a
+// channel event webhook. Fields specific to other event types are omitted.
+type channelEventJSON struct {
+	Type           string `json:"type"`
+	Timestamp      int64  `json:"timestamp"`
+	ChanPoint      string `json:"chan_point"`
+	RemoteID       string `json:"remote_id"`
+	AssetID        string `json:"asset_id"`
+	Capacity       int64  `json:"capacity"`
+	LocalBalance   int64  `json:"local_balance"`
+	RemoteBalance  int64  `json:"remote_balance"`
+	CloseType      string `json:"close_type,omitempty"`
+	ClosingTxid    string `json:"closing_txid,omitempty"`
+	Incoming       bool   `json:"incoming,omitempty"`
+	PaymentHash    string `json:"payment_hash,omitempty"`
+	Amount         int64  `json:"amount,omitempty"`
+	ChangeOutPoint string `json:"change_outpoint,omitempty"`
+}
+
+// newChannelEventJSON returns the JSON encoding of the passed event.
+func newChannelEventJSON(e *ChannelEvent) *channelEventJSON {
+	j := &channelEventJSON{
+		Type:          e.Type.String(),
+		Timestamp:     e.Timestamp.Unix(),
+		ChanPoint:     e.ChanPoint.String(),
+		RemoteID:      hex.EncodeToString(e.RemoteID[:]),
+		AssetID:       e.AssetID,
+		Capacity:      int64(e.Capacity),
+		LocalBalance:  int64(e.LocalBalance),
+		RemoteBalance: int64(e.RemoteBalance),
+	}
+
+	switch e.Type {
+	case ChannelClosed, ChannelBreached:
+		j.CloseType = e.CloseType.String()
+		if e.ClosingTxid != nil {
+			j.ClosingTxid = e.ClosingTxid.String()
+		}
+	case HTLCSettled:
+		j.Incoming = e.Incoming
+		j.PaymentHash = hex.EncodeToString(e.PaymentHash[:])
+		j.Amount = int64(e.Amount)
+	case FundingChangeConfirmed:
+		j.ChangeOutPoint = e.ChangeOutPoint.String()
+		j.Amount = int64(e.Amount)
+	}
+
+	return j
+}
+
+// newChannelEventWebhook returns a ChannelEventHook which posts each event as
+// JSON to the passed URL, allowing external systems, such as exchanges, to
+// keep their accounts of the funds within our channels without polling. An
+// event the external system fails to accept is logged, and not retried.
+func newChannelEventWebhook(url string) ChannelEventHook {
+	client := &http.Client{Timeout: chanEventPostTimeout}
+
+	return func(event *ChannelEvent) {
+		body, err := json.Marshal(newChannelEventJSON(event))
+		if err != nil {
+			srvrLog.Errorf("unable to encode %v event of "+
+				"ChannelPoint(%v): %v", event.Type,
+				event.ChanPoint, err)
+			return
+		}
+
+		resp, err := client.Post(url, "application/json",
+			bytes.NewReader(body))
+		if err != nil {
+			srvrLog.Errorf("unable to post %v event of "+
+				"ChannelPoint(%v): %v", event.Type,
+				event.ChanPoint, err)
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			srvrLog.Errorf("%v event of ChannelPoint(%v) rejected "+
+				"with status %v", event.Type, event.ChanPoint,
+				resp.Status)
+		}
+	}
+}
+
+// notifyChannelEvent dispatches an event of the passed type for the channel
+// described by the snapshot. The snapshot's balances are copied into the
+// event, with any details specific to the event type filled in by the
+// passed function, if non-nil.
+func (s *server) notifyChannelEvent(eventType ChannelEventType,
+	snapshot *channeldb.ChannelSnapshot, details func(*ChannelEvent)) {
+
+	event := &ChannelEvent{
+		Type:          eventType,
+		ChanPoint:     *snapshot.ChannelPoint,
+		RemoteID:      snapshot.RemoteID,
+		AssetID:       snapshot.AssetID,
+		Capacity:      snapshot.Capacity,
+		LocalBalance:  snapshot.LocalBalance,
+		RemoteBalance: snapshot.RemoteBalance,
+	}
+	if details != nil {
+		details(event)
+	}
+
+	s.chanEvents.notify(event)
+}
+
+// notifyFundingChange dispatches a FundingChangeConfirmed event for each of
+// the passed change outputs within the confirmed funding transaction of the
+// channel described by the snapshot.
+func (s *server) notifyFundingChange(snapshot *channeldb.ChannelSnapshot,
+	changes []*lnwallet.FundingChange) {
+
+	for _, change := range changes {
+		change := change
+		s.notifyChannelEvent(FundingChangeConfirmed, snapshot,
+			func(e *ChannelEvent) {
+				e.ChangeOutPoint = &change.OutPoint
+				e.Amount = change.Amount
+				e.AssetID = change.AssetID
+			},
+		)
+	}
+}
+
+// notifyHTLCSettled dispatches an HTLCSettled event for an HTLC of the
+// channel driven by the passed commitment state.
+func (p *peer) notifyHTLCSettled(state *commitmentState, incoming bool,
+	rHash [32]byte, amt btcutil.Amount) {
+
+	p.server.notifyChannelEvent(HTLCSettled, state.channel.StateSnapshot(),
+		func(e *ChannelEvent) {
+			e.Incoming = incoming
+			e.PaymentHash = rHash
+			e.Amount = amt
+		},
+	)
+}
+
+// notifyChannelClosed dispatches a ChannelClosed event for the passed channel,
+// or a ChannelBreached event if the channel was closed by the broadcast of a
+// revoked state.
+func (p *peer) notifyChannelClosed(snapshot *channeldb.ChannelSnapshot,
+	closeType channeldb.ClosureType, closingTxid *wire.ShaHash) {
+
+	eventType := ChannelClosed
+	if closeType == channeldb.BreachClose {
+		eventType = ChannelBreached
+	}
+
+	p.server.notifyChannelEvent(eventType, snapshot, func(e *ChannelEvent) {
+		e.CloseType = closeType
+		e.ClosingTxid = closingTxid
+	})
+}
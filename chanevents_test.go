@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestChannelEventBus tests that the events dispatched to the event bus are
+// delivered to each registered hook in order, and that hooks no longer
+// receive events once their registration has been cancelled.
+func TestChannelEventBus(t *testing.T) {
+	bus := newChannelEventBus()
+	if err := bus.Start(); err != nil {
+		t.Fatalf("unable to start event bus: %v", err)
+	}
+	defer bus.Stop()
+
+	// Events dispatched before any hook is registered are discarded.
+	bus.notify(&ChannelEvent{Type: ChannelOpened})
+
+	first := make(chan *ChannelEvent, 10)
+	second := make(chan *ChannelEvent, 10)
+	cancelFirst := bus.subscribe(func(e *ChannelEvent) { first <- e })
+	bus.subscribe(func(e *ChannelEvent) { second <- e })
+
+	types := []ChannelEventType{HTLCSettled, ChannelBreached, ChannelClosed}
+	for i, eventType := range types {
+		bus.notify(&ChannelEvent{
+			Type:      eventType,
+			ChanPoint: wire.OutPoint{Index: uint32(i)},
+		})
+	}
+
+	for _, events := range []chan *ChannelEvent{first, second} {
+		for i, eventType := range types {
+			select {
+			case e := <-events:
+				if e.Type != eventType || e.ChanPoint.Index != uint32(i) {
+					t.Fatalf("event #%v: expected %v, got %v "+
+						"for %v", i, eventType, e.Type,
+						e.ChanPoint)
+				}
+				if e.Timestamp.IsZero() {
+					t.Fatalf("event #%v not timestamped", i)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("event #%v not delivered", i)
+			}
+		}
+	}
+
+	cancelFirst()
+	bus.notify(&ChannelEvent{
+		Type:      ChannelClosed,
+		CloseType: channeldb.CooperativeClose,
+	})
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Fatalf("event not delivered")
+	}
+	select {
+	case e := <-first:
+		t.Fatalf("cancelled hook received event %v", e.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestChannelEventWebhook tests that the channel event webhook posts each
+// event as JSON, omitting the fields of other event types.
+func TestChannelEventWebhook(t *testing.T) {
+	posted := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			var event map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+				t.Errorf("unable to decode event: %v", err)
+			}
+			posted <- event
+		},
+	))
+	defer server.Close()
+
+	hook := newChannelEventWebhook(server.URL)
+	hook(&ChannelEvent{
+		Type:        HTLCSettled,
+		ChanPoint:   wire.OutPoint{Index: 1},
+		AssetID:     "asset",
+		Incoming:    true,
+		PaymentHash: [32]byte{1},
+		Amount:      1000,
+	})
+
+	event := <-posted
+	if event["type"] != "HTLCSettled" || event["asset_id"] != "asset" ||
+		event["incoming"] != true || event["amount"] != float64(1000) {
+
+		t.Fatalf("unexpected event posted: %v", event)
+	}
+	if _, ok := event["close_type"]; ok {
+		t.Fatalf("close type posted for settled htlc: %v", event)
+	}
+}
@@ -0,0 +1,287 @@
+package channeldb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+var (
+	// auditLogBucket is the bucket storing the audit log of every
+	// transaction the wallet has broadcast. Each key within the bucket is
+	// a big-endian sequence number assigned upon insertion, and the value
+	// a serialized AuditRecord. The log is append-only: records are never
+	// modified or removed.
+	auditLogBucket = []byte("audit")
+)
+
+const (
+	// MaxResponseAuditRecords is the max number of audit records that
+	// will be returned by a single query response.
+	MaxResponseAuditRecords = 50000
+)
+
+// TxPurpose describes the role a transaction recorded within the audit log
+// plays within the lifetime of a channel.
+type TxPurpose uint8
+
+const (
+	// TxFunding is a transaction funding a new channel.
+	TxFunding TxPurpose = iota
+
+	// TxFundingAbort is a transaction spending the inputs of a funding
+	// transaction back to the wallet, aborting the opening of a channel.
+	TxFundingAbort
+
+	// TxForceClose is a commitment transaction broadcast in order to
+	// unilaterally close a channel.
+	TxForceClose
+
+	// TxCooperativeClose is a transaction mutually closing a channel.
+	TxCooperativeClose
+
+	// TxSweep is a transaction sweeping time-locked outputs of closed
+	// channels back to the wallet.
+	TxSweep
+)
+
+// String returns a human readable version of the TxPurpose.
+func (p TxPurpose) String() string {
+	switch p {
+	case TxFunding:
+		return "Funding"
+	case TxFundingAbort:
+		return "FundingAbort"
+	case TxForceClose:
+		return "ForceClose"
+	case TxCooperativeClose:
+		return "CooperativeClose"
+	case TxSweep:
+		return "Sweep"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(p))
+	}
+}
+
+// AuditRecord is an entry within the audit log, recording a single
+// transaction broadcast by the wallet along with the outcome of the
+// broadcast.
+type AuditRecord struct {
+	// Seq is the sequence number of the record within the audit log,
+	// assigned once the record is added.
+	Seq uint64
+
+	// Timestamp is the time the transaction was broadcast.
+	Timestamp time.Time
+
+	// Purpose is the role the transaction plays within the lifetime of
+	// its channel.
+	Purpose TxPurpose
+
+	// ChanPoint is the channel the transaction belongs to. It's left
+	// zero for transactions which don't belong to a single channel, such
+	// as sweeps.
+	ChanPoint wire.OutPoint
+
+	// AssetID is the identifier of the colored asset carried by the
+	// transaction, if any.
+	AssetID string
+
+	// RawTx is the serialized transaction.
+	RawTx []byte
+
+	// Instructions is the colored coins payload within the transaction's
+	// OP_RETURN output, encoding the asset transfer instructions. It's
+	// empty for transactions without an OP_RETURN output.
+	Instructions []byte
+
+	// Result is the error the broadcast failed with, or empty if the
+	// transaction was accepted.
+	Result string
+}
+
+// AuditQuery represents a query to the audit log. All records within the
+// time slice which match each of the non-zero filters are returned.
+type AuditQuery struct {
+	// StartTime is the start time of the time slice.
+	StartTime time.Time
+
+	// EndTime is the end time of the time slice.
+	EndTime time.Time
+
+	// ChanPoint, if non-nil, restricts the query to records of the target
+	// channel.
+	ChanPoint *wire.OutPoint
+
+	// AssetID, if non-empty, restricts the query to records carrying the
+	// target asset.
+	AssetID string
+
+	// Purposes, if non-empty, restricts the query to records serving one
+	// of the listed purposes.
+	Purposes []TxPurpose
+
+	// NumMaxRecords is the max number of records to return. If zero,
+	// then MaxResponseAuditRecords is used.
+	NumMaxRecords uint32
+}
+
+// matches returns true if the passed record satisfies the query.
+func (q *AuditQuery) matches(r *AuditRecord) bool {
+	switch {
+	case r.Timestamp.Before(q.StartTime) || r.Timestamp.After(q.EndTime):
+		return false
+	case q.ChanPoint != nil && r.ChanPoint != *q.ChanPoint:
+		return false
+	case q.AssetID != "" && r.AssetID != q.AssetID:
+		return false
+	}
+
+	if len(q.Purposes) == 0 {
+		return true
+	}
+	for _, purpose := range q.Purposes {
+		if r.Purpose == purpose {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddAuditRecord appends the passed record to the audit log, assigning it the
+// next sequence number.
+func (d *DB) AddAuditRecord(record *AuditRecord) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		logBucket, err := tx.CreateBucketIfNotExists(auditLogBucket)
+		if err != nil {
+			return err
+		}
+
+		seq, err := logBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializeAuditRecord(&b, record); err != nil {
+			return err
+		}
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], seq)
+		if err := logBucket.Put(key[:], b.Bytes()); err != nil {
+			return err
+		}
+
+		record.Seq = seq
+		return nil
+	})
+}
+
+// QueryAuditLog returns all records within the audit log which satisfy the
+// passed query, in the order they were added.
+func (d *DB) QueryAuditLog(q AuditQuery) ([]AuditRecord, error) {
+	maxRecords := q.NumMaxRecords
+	if maxRecords == 0 || maxRecords > MaxResponseAuditRecords {
+		maxRecords = MaxResponseAuditRecords
+	}
+
+	var records []AuditRecord
+	err := d.store.View(func(tx *bolt.Tx) error {
+		logBucket := tx.Bucket(auditLogBucket)
+		if logBucket == nil {
+			return nil
+		}
+
+		return logBucket.ForEach(func(k, v []byte) error {
+			if uint32(len(records)) >= maxRecords {
+				return nil
+			}
+
+			record, err := deserializeAuditRecord(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			record.Seq = byteOrder.Uint64(k)
+
+			if q.matches(record) {
+				records = append(records, *record)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+func serializeAuditRecord(w io.Writer, r *AuditRecord) error {
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(r.Timestamp.UnixNano()))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(r.Purpose)}); err != nil {
+		return err
+	}
+	if err := writeOutpoint(w, &r.ChanPoint); err != nil {
+		return err
+	}
+
+	for _, field := range [][]byte{[]byte(r.AssetID), r.RawTx,
+		r.Instructions, []byte(r.Result)} {
+
+		if err := wire.WriteVarBytes(w, 0, field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deserializeAuditRecord(r io.Reader) (*AuditRecord, error) {
+	record := &AuditRecord{}
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	record.Timestamp = time.Unix(0, int64(byteOrder.Uint64(scratch[:])))
+	if _, err := io.ReadFull(r, scratch[:1]); err != nil {
+		return nil, err
+	}
+	record.Purpose = TxPurpose(scratch[0])
+	if err := readOutpoint(r, &record.ChanPoint); err != nil {
+		return nil, err
+	}
+
+	assetID, err := wire.ReadVarBytes(r, 0, 1000, "assetID")
+	if err != nil {
+		return nil, err
+	}
+	record.AssetID = string(assetID)
+
+	record.RawTx, err = wire.ReadVarBytes(r, 0, wire.MaxBlockPayload, "rawTx")
+	if err != nil {
+		return nil, err
+	}
+	record.Instructions, err = wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+		"instructions")
+	if err != nil {
+		return nil, err
+	}
+	result, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload, "result")
+	if err != nil {
+		return nil, err
+	}
+	record.Result = string(result)
+
+	return record, nil
+}
@@ -0,0 +1,80 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestAuditLogQuery(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	const assetID = "La3Ubh2cbnLM2a5X3Ceb9Q9TNQ1eJvkGr6sHW1"
+	chanA := wire.OutPoint{Hash: key, Index: 0}
+	chanB := wire.OutPoint{Hash: key, Index: 1}
+
+	// Record a funding and force close transaction for the first channel,
+	// a funding and cooperative close transaction for the second, and a
+	// sweep of both, each a second apart.
+	startTime := time.Unix(1000, 0)
+	records := []*AuditRecord{
+		{Purpose: TxFunding, ChanPoint: chanA, AssetID: assetID},
+		{Purpose: TxFunding, ChanPoint: chanB},
+		{Purpose: TxForceClose, ChanPoint: chanA, AssetID: assetID},
+		{Purpose: TxCooperativeClose, ChanPoint: chanB, Result: "rejected"},
+		{Purpose: TxSweep},
+	}
+	for i, record := range records {
+		record.Timestamp = startTime.Add(time.Duration(i) * time.Second)
+		record.RawTx = []byte{byte(i), 1, 2, 3}
+		record.Instructions = []byte{byte(i), 4, 5}
+
+		if err := db.AddAuditRecord(record); err != nil {
+			t.Fatalf("unable to add audit record: %v", err)
+		}
+		if record.Seq != uint64(i+1) {
+			t.Fatalf("expected sequence number %v, got %v", i+1,
+				record.Seq)
+		}
+	}
+
+	query := func(q AuditQuery, expected ...int) {
+		q.StartTime = startTime
+		if q.EndTime.IsZero() {
+			q.EndTime = startTime.Add(time.Hour)
+		}
+
+		found, err := db.QueryAuditLog(q)
+		if err != nil {
+			t.Fatalf("unable to query audit log: %v", err)
+		}
+
+		expectedRecords := make([]AuditRecord, 0, len(expected))
+		for _, i := range expected {
+			expectedRecords = append(expectedRecords, *records[i])
+		}
+		if len(found) != len(expectedRecords) ||
+			(len(found) != 0 && !reflect.DeepEqual(found, expectedRecords)) {
+			t.Fatalf("query %+v: expected %v, got %v", q,
+				spew.Sdump(expectedRecords), spew.Sdump(found))
+		}
+	}
+
+	// All records should be returned in order by a query without any
+	// filters, while each filter should restrict the records returned.
+	query(AuditQuery{}, 0, 1, 2, 3, 4)
+	query(AuditQuery{ChanPoint: &chanA}, 0, 2)
+	query(AuditQuery{AssetID: assetID}, 0, 2)
+	query(AuditQuery{Purposes: []TxPurpose{TxFunding, TxSweep}}, 0, 1, 4)
+	query(AuditQuery{ChanPoint: &chanB, Purposes: []TxPurpose{
+		TxCooperativeClose}}, 3)
+	query(AuditQuery{EndTime: startTime.Add(time.Second)}, 0, 1)
+	query(AuditQuery{NumMaxRecords: 2}, 0, 1)
+}
@@ -2,6 +2,7 @@ package channeldb
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"sync"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/boltdb/bolt"
 	"github.com/lightningnetwork/lnd/elkrem"
+	"github.com/lightningnetwork/lnd/shachain"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
@@ -55,6 +57,7 @@ var (
 	// caused by serializing/deserializing the *entire* struct with each
 	// update.
 	chanCapacityPrefix = []byte("ccp")
+	btcCapacityPrefix  = []byte("bcp")
 	selfBalancePrefix  = []byte("sbp")
 	theirBalancePrefix = []byte("tbp")
 	minFeePerKbPrefix  = []byte("mfp")
@@ -62,6 +65,8 @@ var (
 	satSentPrefix      = []byte("ssp")
 	satRecievedPrefix  = []byte("srp")
 	netFeesPrefix      = []byte("ntp")
+	assetIDPrefix      = []byte("aip")
+	featuresPrefix     = []byte("cfp")
 
 	// chanIDKey stores the node, and channelID for an active channel.
 	chanIDKey = []byte("cik")
@@ -92,6 +97,41 @@ var (
 	deliveryScriptsKey = []byte("dsk")
 )
 
+// DustPolicy determines the value in satoshis of the colored outputs of the
+// transactions spending a channel's funding output.
+type DustPolicy uint8
+
+const (
+	// DustPolicyFixedCarrier gives each colored output of the commitment
+	// and cooperative close transactions a fixed carrier amount, paid out
+	// of the satoshis carried by the funding output.
+	DustPolicyFixedCarrier DustPolicy = 0
+)
+
+// ChannelFeatures is the version of the colored channel protocol negotiated
+// by both parties during the funding workflow. As both parties construct the
+// channel's transactions independently, the features are fixed for the
+// lifetime of the channel, allowing the format of the transactions to evolve
+// without stranding existing channels. The zero value denotes the original
+// protocol, which channels created before the features were stored use.
+type ChannelFeatures struct {
+	// InstructionVersion is the version of the encoding of the colored
+	// coins instructions embedded within the channel's transactions.
+	InstructionVersion uint8
+
+	// DustPolicy is the policy determining the value of the colored
+	// outputs of the channel's transactions.
+	DustPolicy DustPolicy
+
+	// SecondLevelHTLCs indicates HTLC outputs of the commitment
+	// transactions are spent via second-level HTLC transactions.
+	SecondLevelHTLCs bool
+
+	// ShaChain indicates the revocation secrets of the channel are
+	// derived from a BOLT shachain rather than an elkrem.
+	ShaChain bool
+}
+
 // OpenChannel encapsulates the persistent and dynamic state of an open channel
 // with a remote node. An open channel supports several options for on-disk
 // serialization depending on the exact context. Full (upon channel creation)
@@ -107,15 +147,31 @@ type OpenChannel struct {
 	ChanID      *wire.OutPoint
 	MinFeePerKb btcutil.Amount
 
+	// AssetID is the identifier of the colored asset the channel is
+	// denominated in.
+	AssetID string
+
+	// Features is the version of the colored channel protocol negotiated
+	// for the channel.
+	Features ChannelFeatures
+
 	// Keys for both sides to be used for the commitment transactions.
 	OurCommitKey   *btcec.PublicKey
 	TheirCommitKey *btcec.PublicKey
 
 	// Tracking total channel capacity, and the amount of funds allocated
-	// to each side.
-	Capacity     btcutil.Amount
-	OurBalance   btcutil.Amount
-	TheirBalance btcutil.Amount
+	// to each side. The capacity and both balances are denominated in the
+	// channel's asset.
+	AssetCapacity btcutil.Amount
+	OurBalance    btcutil.Amount
+	TheirBalance  btcutil.Amount
+
+	// BtcCapacity is the value in satoshis of the funding output, which
+	// only carries the channel's asset, and so bears no relation to the
+	// AssetCapacity. This is the amount committed to by the sighash of
+	// each transaction spending the funding output. A BtcCapacity of zero
+	// denotes a channel created before the two were tracked apart.
+	BtcCapacity btcutil.Amount
 
 	// Our current commitment transaction along with their signature for
 	// our commitment transaction.
@@ -138,8 +194,13 @@ type OpenChannel struct {
 	// aren't yet able to verify that it's actually in the hash chain.
 	TheirCurrentRevocation     *btcec.PublicKey
 	TheirCurrentRevocationHash [32]byte
-	LocalElkrem                *elkrem.ElkremSender
-	RemoteElkrem               *elkrem.ElkremReceiver
+
+	// LocalElkrem produces the secrets revoking our commitments, and
+	// RemoteElkrem stores those revealed by the remote party. Each is
+	// either an elkrem, or a BOLT shachain, as dictated by the ShaChain
+	// feature of the channel.
+	LocalElkrem  shachain.Producer
+	RemoteElkrem shachain.Store
 
 	// The pkScript for both sides to be used for final delivery in the case
 	// of a cooperative close.
@@ -209,14 +270,35 @@ func (c *OpenChannel) FullSync() error {
 // UpdateCommitment updates the on-disk state of our currently broadcastable
 // commitment state. This method is to be called once we have revoked our prior
 // commitment state, accepting the new state as defined by the passed
-// parameters.
+// parameters. All fields are written within a single database transaction,
+// which acts as the commit point of the state transition: if the transaction
+// fails, then neither the on-disk, nor the in-memory state is modified.
 func (c *OpenChannel) UpdateCommitment(newCommitment *wire.MsgTx,
 	newSig []byte, delta *ChannelDelta) error {
 
 	c.Lock()
 	defer c.Unlock()
 
-	return c.Db.store.Update(func(tx *bolt.Tx) error {
+	// The put helpers below read directly from the channel's fields, so we
+	// stash the prior values in order to roll back the in-memory state if
+	// the transaction fails to commit.
+	var (
+		prevCommitTx     = c.OurCommitTx
+		prevCommitSig    = c.OurCommitSig
+		prevOurBalance   = c.OurBalance
+		prevTheirBalance = c.TheirBalance
+		prevNumUpdates   = c.NumUpdates
+		prevHtlcs        = c.Htlcs
+	)
+
+	c.OurCommitTx = newCommitment
+	c.OurCommitSig = newSig
+	c.OurBalance = delta.LocalBalance
+	c.TheirBalance = delta.RemoteBalance
+	c.NumUpdates = uint64(delta.UpdateNum)
+	c.Htlcs = delta.Htlcs
+
+	err := c.Db.store.Update(func(tx *bolt.Tx) error {
 		chanBucket, err := tx.CreateBucketIfNotExists(openChannelBucket)
 		if err != nil {
 			return err
@@ -228,16 +310,6 @@ func (c *OpenChannel) UpdateCommitment(newCommitment *wire.MsgTx,
 			return err
 		}
 
-		// TODO(roasbeef): modify the funcs below to take values
-		// directly, otherwise need to roll back to prior state. Could
-		// also make copy above, then modify to pass in.
-		c.OurCommitTx = newCommitment
-		c.OurCommitSig = newSig
-		c.OurBalance = delta.LocalBalance
-		c.TheirBalance = delta.RemoteBalance
-		c.NumUpdates = uint64(delta.UpdateNum)
-		c.Htlcs = delta.Htlcs
-
 		// First we'll write out the current latest dynamic channel
 		// state: the current channel balance, the number of updates,
 		// and our latest commitment transaction+sig.
@@ -257,6 +329,25 @@ func (c *OpenChannel) UpdateCommitment(newCommitment *wire.MsgTx,
 
 		return nil
 	})
+	if err != nil {
+		c.OurCommitTx = prevCommitTx
+		c.OurCommitSig = prevCommitSig
+		c.OurBalance = prevOurBalance
+		c.TheirBalance = prevTheirBalance
+		c.NumUpdates = prevNumUpdates
+		c.Htlcs = prevHtlcs
+		return err
+	}
+
+	c.Db.notifyDelta(&ReplicatedDelta{
+		ChanPoint: *c.ChanID,
+		Kind:      LocalDelta,
+		Delta:     delta,
+		CommitTx:  newCommitment,
+		CommitSig: newSig,
+	})
+
+	return nil
 }
 
 // HTLC is the on-disk representation of a hash time-locked contract. HTLC's
@@ -283,7 +374,19 @@ type HTLC struct {
 	// closure.
 	RevocationDelay uint32
 
-	// TODO(roasbeef): add output index?
+	// OutputIndex is the index of the HTLC's output within the commitment
+	// transaction, or -1 if it's unknown, as is the case for HTLCs
+	// recorded prior to the output's script metadata being persisted.
+	OutputIndex int32
+
+	// WitnessScript is the witness script of the HTLC's output, allowing
+	// the output to be swept without re-deriving the script. It's nil if
+	// the OutputIndex is unknown.
+	WitnessScript []byte
+
+	// ScriptHash is the sha256 hash of the WitnessScript, as committed to
+	// by the p2wsh output script of the HTLC's output.
+	ScriptHash [32]byte
 }
 
 // Copy returns a full copy of the target HTLC.
@@ -293,8 +396,13 @@ func (h *HTLC) Copy() HTLC {
 		Amt:             h.Amt,
 		RefundTimeout:   h.RefundTimeout,
 		RevocationDelay: h.RevocationDelay,
+		OutputIndex:     h.OutputIndex,
+		ScriptHash:      h.ScriptHash,
 	}
 	copy(clone.RHash[:], h.RHash[:])
+	if h.WitnessScript != nil {
+		clone.WitnessScript = append([]byte(nil), h.WitnessScript...)
+	}
 
 	return clone
 }
@@ -316,7 +424,19 @@ type ChannelDelta struct {
 // this log can be consulted in order to reconstruct the state needed to
 // rectify the situation.
 func (c *OpenChannel) AppendToRevocationLog(delta *ChannelDelta) error {
-	return c.Db.store.Update(func(tx *bolt.Tx) error {
+	return c.RecordRemoteRevocation(delta, nil)
+}
+
+// RecordRemoteRevocation persists all the state modified by the receipt of a
+// valid revocation from the remote party: the updated elkrem state, the delta
+// of the revoked state transition, and if non-nil, the data required to
+// punish a broadcast of the revoked commitment. Everything is written within
+// a single database transaction, so the revocation is either recorded in
+// full, or not at all.
+func (c *OpenChannel) RecordRemoteRevocation(delta *ChannelDelta,
+	revoked *RevokedCommitment) error {
+
+	err := c.Db.store.Update(func(tx *bolt.Tx) error {
 		chanBucket, err := tx.CreateBucketIfNotExists(openChannelBucket)
 		if err != nil {
 			return err
@@ -345,9 +465,26 @@ func (c *OpenChannel) AppendToRevocationLog(delta *ChannelDelta) error {
 		if err != nil {
 			return err
 		}
+		if err := appendChannelLogEntry(logBucket, delta, c.ChanID); err != nil {
+			return err
+		}
+
+		if revoked == nil {
+			return nil
+		}
+		return putRevokedCommitment(nodeChanBucket, c.ChanID, revoked)
+	})
+	if err != nil {
+		return err
+	}
 
-		return appendChannelLogEntry(logBucket, delta, c.ChanID)
+	c.Db.notifyDelta(&ReplicatedDelta{
+		ChanPoint: *c.ChanID,
+		Kind:      RemoteDelta,
+		Delta:     delta,
 	})
+
+	return nil
 }
 
 // FindPreviousState scans through the append-only log in an attempt to recover
@@ -384,12 +521,98 @@ func (c *OpenChannel) FindPreviousState(updateNum uint64) (*ChannelDelta, error)
 	return delta, nil
 }
 
+// ClosureType is an enum like structure that details exactly _how_ a channel
+// was closed.
+type ClosureType uint8
+
+const (
+	// CooperativeClose indicates that a channel has been closed
+	// cooperatively. This means that both channel peers were online and
+	// signed a new transaction paying out the settled balance of the
+	// contract.
+	CooperativeClose ClosureType = iota
+
+	// ForceClose indicates that we closed the channel by broadcasting our
+	// current commitment transaction.
+	ForceClose
+
+	// RemoteForceClose indicates that the remote party closed the channel
+	// by broadcasting their current commitment transaction.
+	RemoteForceClose
+
+	// BreachClose indicates that the remote party attempted to broadcast
+	// a prior revoked channel state.
+	BreachClose
+
+	// FundingCanceled indicates that the funding transaction was double
+	// spent by us before it confirmed, cancelling the channel.
+	FundingCanceled
+)
+
+// String returns a human readable version of the ClosureType.
+func (c ClosureType) String() string {
+	switch c {
+	case CooperativeClose:
+		return "CooperativeClose"
+	case ForceClose:
+		return "ForceClose"
+	case RemoteForceClose:
+		return "RemoteForceClose"
+	case BreachClose:
+		return "BreachClose"
+	case FundingCanceled:
+		return "FundingCanceled"
+	default:
+		return "UnknownClose"
+	}
+}
+
+// ChannelCloseSummary contains the final state of a channel at the point it
+// was closed. Once a channel is closed, all the information pertaining to
+// that channel within the openChannelBucket is deleted, and a compact summary
+// is written to the closedChannelBucket so that accounting of the colored
+// funds within the channel survives its deletion.
+type ChannelCloseSummary struct {
+	// ChanPoint is the outpoint of the funding transaction.
+	ChanPoint wire.OutPoint
+
+	// RemoteID is the identity of the remote peer of the channel.
+	RemoteID [wire.HashSize]byte
+
+	// AssetID is the identifier of the colored asset the channel was
+	// denominated in.
+	AssetID string
+
+	// Capacity was the total capacity of the channel, denominated in the
+	// channel's asset.
+	Capacity btcutil.Amount
+
+	// OurBalance is our final settled balance at the time of closure.
+	OurBalance btcutil.Amount
+
+	// TheirBalance is the remote party's final settled balance at the
+	// time of closure.
+	TheirBalance btcutil.Amount
+
+	// CloseType details exactly _how_ the channel was closed.
+	CloseType ClosureType
+
+	// ClosingTXID is the txid of the transaction which closed the
+	// channel.
+	ClosingTXID wire.ShaHash
+
+	// CloseHeight is the height at which the closing transaction was
+	// confirmed. A height of zero indicates that the closing transaction
+	// hadn't yet been confirmed when the summary was recorded.
+	CloseHeight uint32
+}
+
 // CloseChannel closes a previously active lightning channel. Closing a channel
 // entails deleting all saved state within the database concerning this
 // channel, as well as created a small channel summary for record keeping
 // purposes.
 // TODO(roasbeef): delete on-disk set of HTLC's
-func (c *OpenChannel) CloseChannel() error {
+func (c *OpenChannel) CloseChannel(summary *ChannelCloseSummary) error {
 	return c.Db.store.Update(func(tx *bolt.Tx) error {
 		// First fetch the top level bucket which stores all data related to
 		// current, active channels.
@@ -426,9 +649,15 @@ func (c *OpenChannel) CloseChannel() error {
 			return err
 		}
 
+		// Any messages pending retransmission are no longer of use
+		// once the channel is closed.
+		if err := deleteRetransmissions(tx, outPointBytes); err != nil {
+			return err
+		}
+
 		// Finally, create a summary of this channel in the closed
 		// channel bucket for this node.
-		return putClosedChannelSummary(tx, outPointBytes)
+		return putClosedChannelSummary(tx, outPointBytes, summary)
 	})
 }
 
@@ -440,7 +669,12 @@ type ChannelSnapshot struct {
 
 	ChannelPoint *wire.OutPoint
 
+	AssetID string
+
+	// Capacity and both balances are denominated in the channel's asset,
+	// while BtcCapacity is the value in satoshis of the funding output.
 	Capacity      btcutil.Amount
+	BtcCapacity   btcutil.Amount
 	LocalBalance  btcutil.Amount
 	RemoteBalance btcutil.Amount
 
@@ -461,7 +695,9 @@ func (c *OpenChannel) Snapshot() *ChannelSnapshot {
 
 	snapshot := &ChannelSnapshot{
 		ChannelPoint:          c.ChanID,
-		Capacity:              c.Capacity,
+		AssetID:               c.AssetID,
+		Capacity:              c.AssetCapacity,
+		BtcCapacity:           c.BtcCapacity,
 		LocalBalance:          c.OurBalance,
 		RemoteBalance:         c.TheirBalance,
 		NumUpdates:            c.NumUpdates,
@@ -480,17 +716,105 @@ func (c *OpenChannel) Snapshot() *ChannelSnapshot {
 	return snapshot
 }
 
-func putClosedChannelSummary(tx *bolt.Tx, chanID []byte) error {
-	// For now, a summary of a closed channel simply involves recording the
-	// outpoint of the funding transaction.
+func putClosedChannelSummary(tx *bolt.Tx, chanID []byte,
+	summary *ChannelCloseSummary) error {
+
 	closedChanBucket, err := tx.CreateBucketIfNotExists(closedChannelBucket)
 	if err != nil {
 		return err
 	}
 
-	// TODO(roasbeef): add other info
-	//  * should likely have each in own bucket per node
-	return closedChanBucket.Put(chanID, nil)
+	var b bytes.Buffer
+	if err := serializeChannelCloseSummary(&b, summary); err != nil {
+		return err
+	}
+
+	return closedChanBucket.Put(chanID, b.Bytes())
+}
+
+func serializeChannelCloseSummary(w io.Writer, cs *ChannelCloseSummary) error {
+	if err := writeOutpoint(w, &cs.ChanPoint); err != nil {
+		return err
+	}
+	if _, err := w.Write(cs.RemoteID[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, []byte(cs.AssetID)); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(cs.Capacity))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], uint64(cs.OurBalance))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], uint64(cs.TheirBalance))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if _, err := w.Write([]byte{byte(cs.CloseType)}); err != nil {
+		return err
+	}
+	if _, err := w.Write(cs.ClosingTXID[:]); err != nil {
+		return err
+	}
+
+	byteOrder.PutUint32(scratch[:4], cs.CloseHeight)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func deserializeChannelCloseSummary(r io.Reader) (*ChannelCloseSummary, error) {
+	cs := &ChannelCloseSummary{}
+
+	if err := readOutpoint(r, &cs.ChanPoint); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, cs.RemoteID[:]); err != nil {
+		return nil, err
+	}
+	assetID, err := wire.ReadVarBytes(r, 0, 1000, "assetID")
+	if err != nil {
+		return nil, err
+	}
+	cs.AssetID = string(assetID)
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	cs.Capacity = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	cs.OurBalance = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	cs.TheirBalance = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	if _, err := io.ReadFull(r, scratch[:1]); err != nil {
+		return nil, err
+	}
+	cs.CloseType = ClosureType(scratch[0])
+	if _, err := io.ReadFull(r, cs.ClosingTXID[:]); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	cs.CloseHeight = byteOrder.Uint32(scratch[:4])
+
+	return cs, nil
 }
 
 // putChannel serializes, and stores the current state of the channel in its
@@ -516,6 +840,12 @@ func putOpenChannel(openChanBucket *bolt.Bucket, nodeChanBucket *bolt.Bucket,
 	if err := putChanNetFee(openChanBucket, channel); err != nil {
 		return err
 	}
+	if err := putChanAssetID(openChanBucket, channel); err != nil {
+		return err
+	}
+	if err := putChanFeatures(openChanBucket, channel); err != nil {
+		return err
+	}
 
 	// Next, write out the fields of the channel update less frequently.
 	if err := putChannelIDs(nodeChanBucket, channel); err != nil {
@@ -598,6 +928,12 @@ func fetchOpenChannel(openChanBucket *bolt.Bucket, nodeChanBucket *bolt.Bucket,
 	if err = fetchChanNetFee(openChanBucket, channel); err != nil {
 		return nil, err
 	}
+	if err = fetchChanAssetID(openChanBucket, channel); err != nil {
+		return nil, err
+	}
+	if err = fetchChanFeatures(openChanBucket, channel); err != nil {
+		return nil, err
+	}
 
 	return channel, nil
 }
@@ -622,6 +958,12 @@ func deleteOpenChannel(openChanBucket *bolt.Bucket, nodeChanBucket *bolt.Bucket,
 	if err := deleteChanNetFee(openChanBucket, channelID); err != nil {
 		return err
 	}
+	if err := deleteChanAssetID(openChanBucket, channelID); err != nil {
+		return err
+	}
+	if err := deleteChanFeatures(openChanBucket, channelID); err != nil {
+		return err
+	}
 
 	// Finally, delete all the fields directly within the node's channel
 	// bucket.
@@ -652,6 +994,7 @@ func putChanCapacity(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
 	scratch1 := make([]byte, 8)
 	scratch2 := make([]byte, 8)
 	scratch3 := make([]byte, 8)
+	scratch4 := make([]byte, 8)
 
 	var b bytes.Buffer
 	if err := writeOutpoint(&b, channel.ChanID); err != nil {
@@ -662,11 +1005,17 @@ func putChanCapacity(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
 	copy(keyPrefix[3:], b.Bytes())
 
 	copy(keyPrefix[:3], chanCapacityPrefix)
-	byteOrder.PutUint64(scratch1, uint64(channel.Capacity))
+	byteOrder.PutUint64(scratch1, uint64(channel.AssetCapacity))
 	if err := openChanBucket.Put(keyPrefix, scratch1); err != nil {
 		return err
 	}
 
+	copy(keyPrefix[:3], btcCapacityPrefix)
+	byteOrder.PutUint64(scratch4, uint64(channel.BtcCapacity))
+	if err := openChanBucket.Put(keyPrefix, scratch4); err != nil {
+		return err
+	}
+
 	copy(keyPrefix[:3], selfBalancePrefix)
 	byteOrder.PutUint64(scratch2, uint64(channel.OurBalance))
 	if err := openChanBucket.Put(keyPrefix, scratch2); err != nil {
@@ -687,6 +1036,11 @@ func deleteChanCapacity(openChanBucket *bolt.Bucket, chanID []byte) error {
 		return err
 	}
 
+	copy(keyPrefix[:3], btcCapacityPrefix)
+	if err := openChanBucket.Delete(keyPrefix); err != nil {
+		return err
+	}
+
 	copy(keyPrefix[:3], selfBalancePrefix)
 	if err := openChanBucket.Delete(keyPrefix); err != nil {
 		return err
@@ -708,7 +1062,14 @@ func fetchChanCapacity(openChanBucket *bolt.Bucket, channel *OpenChannel) error
 
 	copy(keyPrefix[:3], chanCapacityPrefix)
 	capacityBytes := openChanBucket.Get(keyPrefix)
-	channel.Capacity = btcutil.Amount(byteOrder.Uint64(capacityBytes))
+	channel.AssetCapacity = btcutil.Amount(byteOrder.Uint64(capacityBytes))
+
+	// Channels created before the satoshi capacity was stored won't have
+	// an entry, in which case the BtcCapacity is left as zero.
+	copy(keyPrefix[:3], btcCapacityPrefix)
+	if btcCapacityBytes := openChanBucket.Get(keyPrefix); btcCapacityBytes != nil {
+		channel.BtcCapacity = btcutil.Amount(byteOrder.Uint64(btcCapacityBytes))
+	}
 
 	copy(keyPrefix[:3], selfBalancePrefix)
 	selfBalanceBytes := openChanBucket.Get(keyPrefix)
@@ -894,6 +1255,106 @@ func fetchChanNetFee(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
 	return nil
 }
 
+func putChanAssetID(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, channel.ChanID); err != nil {
+		return err
+	}
+
+	keyPrefix := make([]byte, 3+b.Len())
+	copy(keyPrefix, assetIDPrefix)
+	copy(keyPrefix[3:], b.Bytes())
+
+	return openChanBucket.Put(keyPrefix, []byte(channel.AssetID))
+}
+
+func deleteChanAssetID(openChanBucket *bolt.Bucket, chanID []byte) error {
+	keyPrefix := make([]byte, 3+len(chanID))
+	copy(keyPrefix, assetIDPrefix)
+	copy(keyPrefix[3:], chanID)
+	return openChanBucket.Delete(keyPrefix)
+}
+
+func fetchChanAssetID(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, channel.ChanID); err != nil {
+		return err
+	}
+
+	keyPrefix := make([]byte, 3+b.Len())
+	copy(keyPrefix, assetIDPrefix)
+	copy(keyPrefix[3:], b.Bytes())
+
+	// Channels created before the asset ID was stored won't have an
+	// entry, in which case the asset ID is left blank.
+	channel.AssetID = string(openChanBucket.Get(keyPrefix))
+
+	return nil
+}
+
+func putChanFeatures(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, channel.ChanID); err != nil {
+		return err
+	}
+
+	keyPrefix := make([]byte, 3+b.Len())
+	copy(keyPrefix, featuresPrefix)
+	copy(keyPrefix[3:], b.Bytes())
+
+	var flags byte
+	if channel.Features.SecondLevelHTLCs {
+		flags |= 1
+	}
+	if channel.Features.ShaChain {
+		flags |= 2
+	}
+	features := []byte{
+		channel.Features.InstructionVersion,
+		byte(channel.Features.DustPolicy),
+		flags,
+	}
+
+	return openChanBucket.Put(keyPrefix, features)
+}
+
+func deleteChanFeatures(openChanBucket *bolt.Bucket, chanID []byte) error {
+	keyPrefix := make([]byte, 3+len(chanID))
+	copy(keyPrefix, featuresPrefix)
+	copy(keyPrefix[3:], chanID)
+	return openChanBucket.Delete(keyPrefix)
+}
+
+func fetchChanFeatures(openChanBucket *bolt.Bucket, channel *OpenChannel) error {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, channel.ChanID); err != nil {
+		return err
+	}
+
+	keyPrefix := make([]byte, 3+b.Len())
+	copy(keyPrefix, featuresPrefix)
+	copy(keyPrefix[3:], b.Bytes())
+
+	// Channels created before the features were negotiated won't have an
+	// entry, in which case the original protocol is in use.
+	features := openChanBucket.Get(keyPrefix)
+	if features == nil {
+		return nil
+	}
+	if len(features) != 3 {
+		return fmt.Errorf("invalid channel features: %x", features)
+	}
+
+	channel.Features = ChannelFeatures{
+		InstructionVersion: features[0],
+		DustPolicy:         DustPolicy(features[1]),
+		SecondLevelHTLCs:   features[2]&1 != 0,
+		ShaChain:           features[2]&2 != 0,
+	}
+
+	return nil
+}
+
 func putChannelIDs(nodeChanBucket *bolt.Bucket, channel *OpenChannel) error {
 	// TODO(roabeef): just pass in chanID everywhere for puts
 	var b bytes.Buffer
@@ -1166,6 +1627,22 @@ func fetchChanFundingInfo(nodeChanBucket *bolt.Bucket, channel *OpenChannel) err
 	return nil
 }
 
+const (
+	// elkremScheme denotes revocation secrets derived from an elkrem.
+	elkremScheme byte = 0
+
+	// shaChainScheme denotes revocation secrets derived from a BOLT
+	// shachain.
+	shaChainScheme byte = 1
+)
+
+// A compile time check to ensure the elkrem sender and receiver may serve as
+// the revocation secret producer and store of a channel.
+var (
+	_ shachain.Producer = (*elkrem.ElkremSender)(nil)
+	_ shachain.Store    = (*elkrem.ElkremReceiver)(nil)
+)
+
 func putChanElkremState(nodeChanBucket *bolt.Bucket, channel *OpenChannel) error {
 	var bc bytes.Buffer
 	if err := writeOutpoint(&bc, channel.ChanID); err != nil {
@@ -1202,6 +1679,15 @@ func putChanElkremState(nodeChanBucket *bolt.Bucket, channel *OpenChannel) error
 		return err
 	}
 
+	// The scheme of the revocation secrets is appended, so the state of
+	// channels created before the shachain was introduced, which lacks
+	// it, is read as an elkrem.
+	if _, ok := channel.LocalElkrem.(*shachain.RevocationProducer); ok {
+		if err := b.WriteByte(shaChainScheme); err != nil {
+			return err
+		}
+	}
+
 	return nodeChanBucket.Put(elkremKey, b.Bytes())
 }
 
@@ -1241,21 +1727,52 @@ func fetchChanElkremState(nodeChanBucket *bolt.Bucket, channel *OpenChannel) err
 	if err != nil {
 		return err
 	}
-	elkremRoot, err := wire.NewShaHash(senderBytes)
+
+	// Both a full elkrem receiver, and a full shachain store exceed 1000
+	// bytes.
+	reciverBytes, err := wire.ReadVarBytes(elkremStateBytes, 0, 4096, "")
 	if err != nil {
 		return err
 	}
-	channel.LocalElkrem = elkrem.NewElkremSender(*elkremRoot)
 
-	reciverBytes, err := wire.ReadVarBytes(elkremStateBytes, 0, 1000, "")
-	if err != nil {
+	scheme, err := elkremStateBytes.ReadByte()
+	switch {
+	case err == io.EOF:
+		scheme = elkremScheme
+	case err != nil:
 		return err
 	}
-	remoteE, err := elkrem.ElkremReceiverFromBytes(reciverBytes)
-	if err != nil {
-		return err
+
+	switch scheme {
+	case elkremScheme:
+		elkremRoot, err := wire.NewShaHash(senderBytes)
+		if err != nil {
+			return err
+		}
+		channel.LocalElkrem = elkrem.NewElkremSender(*elkremRoot)
+
+		remoteE, err := elkrem.ElkremReceiverFromBytes(reciverBytes)
+		if err != nil {
+			return err
+		}
+		channel.RemoteElkrem = remoteE
+
+	case shaChainScheme:
+		producer, err := shachain.RevocationProducerFromBytes(senderBytes)
+		if err != nil {
+			return err
+		}
+		channel.LocalElkrem = producer
+
+		store, err := shachain.RevocationStoreFromBytes(reciverBytes)
+		if err != nil {
+			return err
+		}
+		channel.RemoteElkrem = store
+
+	default:
+		return fmt.Errorf("unknown revocation scheme %v", scheme)
 	}
-	channel.RemoteElkrem = remoteE
 
 	return nil
 }
@@ -1312,12 +1829,62 @@ func fetchChanDeliveryScripts(nodeChanBucket *bolt.Bucket, channel *OpenChannel)
 	return nil
 }
 
-// htlcDiskSize represents the number of btyes a serialized HTLC takes up on
-// disk. The size of an HTLC on disk is 49 bytes total: incoming (1) + amt (8)
-// + rhash (32) + timeouts (8)
+// htlcDiskSize represents the number of btyes the fixed portion of a
+// serialized HTLC takes up on disk. The size is 49 bytes total: incoming (1)
+// + amt (8) + rhash (32) + timeouts (8). Since database version 1, it's
+// followed by the output index (4), and the var-bytes witness script.
 const htlcDiskSize = 1 + 8 + 32 + 4 + 4
 
+// serializeHTLC writes the passed HTLC, along with the script metadata of its
+// output, to the passed io.Writer.
 func serializeHTLC(w io.Writer, h *HTLC) error {
+	if err := serializeHTLCv0(w, h); err != nil {
+		return err
+	}
+
+	var scratch [4]byte
+	byteOrder.PutUint32(scratch[:], uint32(h.OutputIndex))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	return wire.WriteVarBytes(w, 0, h.WitnessScript)
+}
+
+// deserializeHTLC reads an HTLC written by serializeHTLC from the passed
+// io.Reader.
+func deserializeHTLC(r io.Reader) (*HTLC, error) {
+	h, err := deserializeHTLCv0(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var scratch [4]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	h.OutputIndex = int32(byteOrder.Uint32(scratch[:]))
+
+	h.WitnessScript, err = wire.ReadVarBytes(r, 0, maxHTLCScriptSize,
+		"htlc witness script")
+	if err != nil {
+		return nil, err
+	}
+	if len(h.WitnessScript) == 0 {
+		h.WitnessScript = nil
+	} else {
+		h.ScriptHash = sha256.Sum256(h.WitnessScript)
+	}
+
+	return h, nil
+}
+
+// maxHTLCScriptSize is the maximum size of a serialized HTLC witness script.
+const maxHTLCScriptSize = 10000
+
+// serializeHTLCv0 writes the fixed portion of the passed HTLC, as serialized
+// prior to database version 1.
+func serializeHTLCv0(w io.Writer, h *HTLC) error {
 	var buf [htlcDiskSize]byte
 
 	var boolByte [1]byte
@@ -1344,8 +1911,12 @@ func serializeHTLC(w io.Writer, h *HTLC) error {
 	return nil
 }
 
-func deserializeHTLC(r io.Reader) (*HTLC, error) {
-	h := &HTLC{}
+// deserializeHTLCv0 reads the fixed portion of an HTLC, as serialized prior
+// to database version 1. The output index of the returned HTLC is unknown.
+func deserializeHTLCv0(r io.Reader) (*HTLC, error) {
+	h := &HTLC{
+		OutputIndex: -1,
+	}
 
 	var scratch [8]byte
 
@@ -1419,11 +1990,19 @@ func fetchCurrentHtlcs(nodeChanBucket *bolt.Bucket,
 		return nil, nil
 	}
 
+	return deserializeHTLCs(htlcBytes, deserializeHTLC)
+}
+
+// deserializeHTLCs reads the set of HTLCs serialized back to back within the
+// passed bytes, using the passed function to read each HTLC.
+func deserializeHTLCs(htlcBytes []byte,
+	readHTLC func(io.Reader) (*HTLC, error)) ([]*HTLC, error) {
+
 	// TODO(roasbeef): can preallocate here
 	var htlcs []*HTLC
 	htlcReader := bytes.NewReader(htlcBytes)
 	for htlcReader.Len() != 0 {
-		htlc, err := deserializeHTLC(htlcReader)
+		htlc, err := readHTLC(htlcReader)
 		if err != nil {
 			return nil, err
 		}
@@ -1465,6 +2044,14 @@ func serializeChannelDelta(w io.Writer, delta *ChannelDelta) error {
 }
 
 func deserializeChannelDelta(r io.Reader) (*ChannelDelta, error) {
+	return deserializeChannelDeltaWith(r, deserializeHTLC)
+}
+
+// deserializeChannelDeltaWith reads a channel delta from the passed
+// io.Reader, using the passed function to read each of its HTLCs.
+func deserializeChannelDeltaWith(r io.Reader,
+	readHTLC func(io.Reader) (*HTLC, error)) (*ChannelDelta, error) {
+
 	var (
 		err     error
 		scratch [8]byte
@@ -1492,7 +2079,7 @@ func deserializeChannelDelta(r io.Reader) (*ChannelDelta, error) {
 	}
 	delta.Htlcs = make([]*HTLC, numHtlcs)
 	for i := uint64(0); i < numHtlcs; i++ {
-		htlc, err := deserializeHTLC(r)
+		htlc, err := readHTLC(r)
 		if err != nil {
 			return nil, err
 		}
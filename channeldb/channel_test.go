@@ -2,6 +2,7 @@ package channeldb
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/elkrem"
+	"github.com/lightningnetwork/lnd/shachain"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/chaincfg"
 	"github.com/roasbeef/btcd/txscript"
@@ -136,9 +138,12 @@ func createTestChannelState(cdb *DB) (*OpenChannel, error) {
 		TheirLNID:                  key,
 		ChanID:                     id,
 		MinFeePerKb:                btcutil.Amount(5000),
+		AssetID:                    "La4szjzKfJyHQ75qgDEnbzp4qY8GQeDR5Z7h2W",
+		Features:                   ChannelFeatures{InstructionVersion: 1, SecondLevelHTLCs: true},
 		OurCommitKey:               privKey.PubKey(),
 		TheirCommitKey:             pubKey,
-		Capacity:                   btcutil.Amount(10000),
+		AssetCapacity:              btcutil.Amount(10000),
+		BtcCapacity:                btcutil.Amount(8190),
 		OurBalance:                 btcutil.Amount(3000),
 		TheirBalance:               btcutil.Amount(9000),
 		OurCommitTx:                testTx,
@@ -209,6 +214,14 @@ func TestOpenChannelPutGetDelete(t *testing.T) {
 	if state.MinFeePerKb != newState.MinFeePerKb {
 		t.Fatalf("fee/kb doens't match")
 	}
+	if state.AssetID != newState.AssetID {
+		t.Fatalf("asset id's don't match: %v vs %v", state.AssetID,
+			newState.AssetID)
+	}
+	if state.Features != newState.Features {
+		t.Fatalf("features don't match: %v vs %v", state.Features,
+			newState.Features)
+	}
 
 	if !bytes.Equal(state.OurCommitKey.SerializeCompressed(),
 		newState.OurCommitKey.SerializeCompressed()) {
@@ -219,9 +232,13 @@ func TestOpenChannelPutGetDelete(t *testing.T) {
 		t.Fatalf("their commit key dont't match")
 	}
 
-	if state.Capacity != newState.Capacity {
-		t.Fatalf("capacity doesn't match: %v vs %v", state.Capacity,
-			newState.Capacity)
+	if state.AssetCapacity != newState.AssetCapacity {
+		t.Fatalf("capacity doesn't match: %v vs %v", state.AssetCapacity,
+			newState.AssetCapacity)
+	}
+	if state.BtcCapacity != newState.BtcCapacity {
+		t.Fatalf("btc capacity doesn't match: %v vs %v",
+			state.BtcCapacity, newState.BtcCapacity)
 	}
 	if state.OurBalance != newState.OurBalance {
 		t.Fatalf("our balance doesn't match")
@@ -322,10 +339,36 @@ func TestOpenChannelPutGetDelete(t *testing.T) {
 	// the database. This involves "closing" the channel which removes all
 	// written state, and creates a small "summary" elsewhere within the
 	// database.
-	if err := state.CloseChannel(); err != nil {
+	closeSummary := &ChannelCloseSummary{
+		ChanPoint:    *state.ChanID,
+		RemoteID:     state.TheirLNID,
+		AssetID:      "La3Ubh2cbnLM2a5X3Ceb9Q9TNQ1eJvkGr6sHW1",
+		Capacity:     state.AssetCapacity,
+		OurBalance:   state.OurBalance,
+		TheirBalance: state.TheirBalance,
+		CloseType:    CooperativeClose,
+		ClosingTXID:  testTx.TxSha(),
+		CloseHeight:  100,
+	}
+	if err := state.CloseChannel(closeSummary); err != nil {
 		t.Fatalf("unable to close channel: %v", err)
 	}
 
+	// A summary of the channel should now be retrievable from the set of
+	// closed channels.
+	closedChans, err := cdb.ListClosedChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch closed channels: %v", err)
+	}
+	if len(closedChans) != 1 {
+		t.Fatalf("expected a single closed channel, instead have %v",
+			len(closedChans))
+	}
+	if !reflect.DeepEqual(closeSummary, closedChans[0]) {
+		t.Fatalf("close summaries don't match: %v vs %v",
+			spew.Sdump(closeSummary), spew.Sdump(closedChans[0]))
+	}
+
 	// As the channel is now closed, attempting to fetch all open channels
 	// for our fake node ID should return an empty slice.
 	openChans, err := cdb.FetchOpenChannels(&nodeID)
@@ -364,12 +407,16 @@ func TestChannelStateTransition(t *testing.T) {
 		if i > 5 {
 			incoming = true
 		}
+		witnessScript := bytes.Repeat([]byte{byte(i)}, 20)
 		htlc := &HTLC{
 			Incoming:        incoming,
 			Amt:             50000,
 			RHash:           key,
 			RefundTimeout:   i,
 			RevocationDelay: i + 2,
+			OutputIndex:     int32(i),
+			WitnessScript:   witnessScript,
+			ScriptHash:      sha256.Sum256(witnessScript),
 		}
 		htlcs = append(htlcs, htlc)
 	}
@@ -478,3 +525,209 @@ func TestChannelStateTransition(t *testing.T) {
 		t.Fatalf("revocation state wasn't synced!")
 	}
 }
+
+func TestRevokedCommitmentPutFetch(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	// Before any revoked states have been recorded, lookups should fail.
+	if _, err := channel.FetchRevokedCommitment(0); err != ErrRevokedCommitNotFound {
+		t.Fatalf("expected ErrRevokedCommitNotFound, got %v", err)
+	}
+
+	revoked := &RevokedCommitment{
+		UpdateNum:     42,
+		CommitTxid:    testTx.TxSha(),
+		RevocationKey: pubKey,
+		Outputs: []*RevokedOutput{
+			{
+				Index:         0,
+				Value:         546,
+				AssetAmt:      5000,
+				PkScript:      testTx.TxOut[0].PkScript,
+				WitnessScript: bytes.Repeat([]byte{0x51}, 30),
+				IsHTLC:        false,
+			},
+			{
+				Index:         1,
+				Value:         546,
+				AssetAmt:      100,
+				PkScript:      testTx.TxOut[0].PkScript,
+				WitnessScript: bytes.Repeat([]byte{0x52}, 100),
+				IsHTLC:        true,
+				IsIncoming:    true,
+			},
+		},
+	}
+	copy(revoked.RevocationPreimage[:], bytes.Repeat([]byte{0xaa}, 32))
+
+	if err := channel.PutRevokedCommitment(revoked); err != nil {
+		t.Fatalf("unable to store revoked commitment: %v", err)
+	}
+
+	// The revoked state should be retrievable by both its update number,
+	// and the txid of the revoked commitment transaction.
+	diskRevoked, err := channel.FetchRevokedCommitment(42)
+	if err != nil {
+		t.Fatalf("unable to fetch revoked commitment: %v", err)
+	}
+	if !reflect.DeepEqual(revoked, diskRevoked) {
+		t.Fatalf("revoked commitments don't match: %v vs %v",
+			spew.Sdump(revoked), spew.Sdump(diskRevoked))
+	}
+
+	txid := testTx.TxSha()
+	diskRevoked, err = channel.FetchRevokedCommitmentByTxid(&txid)
+	if err != nil {
+		t.Fatalf("unable to fetch revoked commitment by txid: %v", err)
+	}
+	if !reflect.DeepEqual(revoked, diskRevoked) {
+		t.Fatalf("revoked commitments don't match: %v vs %v",
+			spew.Sdump(revoked), spew.Sdump(diskRevoked))
+	}
+
+	var unknownTxid wire.ShaHash
+	if _, err := channel.FetchRevokedCommitmentByTxid(&unknownTxid); err != ErrRevokedCommitNotFound {
+		t.Fatalf("expected ErrRevokedCommitNotFound, got %v", err)
+	}
+}
+
+func TestRecordRemoteRevocation(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	delta := &ChannelDelta{
+		LocalBalance:  btcutil.Amount(1e8),
+		RemoteBalance: btcutil.Amount(1e8),
+		UpdateNum:     7,
+	}
+	revoked := &RevokedCommitment{
+		UpdateNum:     7,
+		CommitTxid:    testTx.TxSha(),
+		RevocationKey: pubKey,
+	}
+
+	// Rotate the revocation state, then record the revocation. The new
+	// revocation state, the delta, and the revoked commitment should all
+	// be written together.
+	newRevocation := bytes.Repeat([]byte{7}, 32)
+	copy(channel.TheirCurrentRevocationHash[:], newRevocation)
+	if err := channel.RecordRemoteRevocation(delta, revoked); err != nil {
+		t.Fatalf("unable to record revocation: %v", err)
+	}
+
+	if _, err := channel.FindPreviousState(7); err != nil {
+		t.Fatalf("unable to fetch past delta: %v", err)
+	}
+	diskRevoked, err := channel.FetchRevokedCommitment(7)
+	if err != nil {
+		t.Fatalf("unable to fetch revoked commitment: %v", err)
+	}
+	if !reflect.DeepEqual(revoked.CommitTxid, diskRevoked.CommitTxid) {
+		t.Fatalf("revoked commitments don't match: %v vs %v",
+			spew.Sdump(revoked), spew.Sdump(diskRevoked))
+	}
+
+	nodeID := wire.ShaHash(channel.TheirLNID)
+	updatedChannel, err := cdb.FetchOpenChannels(&nodeID)
+	if err != nil {
+		t.Fatalf("unable to fetch updated channel: %v", err)
+	}
+	if !bytes.Equal(updatedChannel[0].TheirCurrentRevocationHash[:],
+		newRevocation) {
+		t.Fatalf("revocation state wasn't synced!")
+	}
+}
+
+func TestShaChainStatePutFetch(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	state, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+
+	// Replace the elkrem revocation state with a shachain one, progressed
+	// over 1000 channel updates.
+	producer := shachain.NewRevocationProducer(wire.ShaHash(key))
+	store := shachain.NewRevocationStore()
+	for i := 0; i < 1000; i++ {
+		preImage, err := producer.AtIndex(uint64(i))
+		if err != nil {
+			t.Fatalf("unable to produce pre-image: %v", err)
+		}
+		if err := store.AddNext(preImage); err != nil {
+			t.Fatalf("unable to store pre-image: %v", err)
+		}
+	}
+	state.LocalElkrem = producer
+	state.RemoteElkrem = store
+	state.Features.ShaChain = true
+
+	if err := state.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	nodeID := wire.ShaHash(state.TheirLNID)
+	openChannels, err := cdb.FetchOpenChannels(&nodeID)
+	if err != nil {
+		t.Fatalf("unable to fetch open channel: %v", err)
+	}
+	newState := openChannels[0]
+
+	if !reflect.DeepEqual(state.Features, newState.Features) {
+		t.Fatalf("features don't match: %v vs %v", state.Features,
+			newState.Features)
+	}
+	if _, ok := newState.LocalElkrem.(*shachain.RevocationProducer); !ok {
+		t.Fatalf("expected shachain producer, got %T",
+			newState.LocalElkrem)
+	}
+	if _, ok := newState.RemoteElkrem.(*shachain.RevocationStore); !ok {
+		t.Fatalf("expected shachain store, got %T",
+			newState.RemoteElkrem)
+	}
+
+	expected, _ := producer.AtIndex(999)
+	local, err := newState.LocalElkrem.AtIndex(999)
+	if err != nil {
+		t.Fatalf("unable to produce pre-image: %v", err)
+	}
+	remote, err := newState.RemoteElkrem.AtIndex(999)
+	if err != nil {
+		t.Fatalf("unable to fetch pre-image: %v", err)
+	}
+	if !local.IsEqual(expected) || !remote.IsEqual(expected) {
+		t.Fatalf("pre-images don't match")
+	}
+	if newState.RemoteElkrem.UpTo() != 999 {
+		t.Fatalf("expected store up to 999, got %v",
+			newState.RemoteElkrem.UpTo())
+	}
+}
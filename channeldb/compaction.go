@@ -0,0 +1,430 @@
+package channeldb
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// PruneConfig houses the parameters which control which records are removed
+// from the database by PruneChannelState.
+type PruneConfig struct {
+	// RevocationRetention is the number of past states for which
+	// revocation data (channel deltas and revoked commitments) is retained
+	// for each open channel. If zero, then all revocation data for open
+	// channels is retained.
+	//
+	// NOTE: Any state pruned from the revocation log can no longer be
+	// punished if broadcast by the remote party, so this value should
+	// only be set on nodes which accept that risk in exchange for a
+	// bounded database size.
+	RevocationRetention uint64
+}
+
+// PruneSummary reports the number of records removed by a call to
+// PruneChannelState.
+type PruneSummary struct {
+	// NumHtlcRecords is the number of stale HTLC sets removed for channels
+	// which have been fully closed.
+	NumHtlcRecords uint32
+
+	// NumChannelDeltas is the number of entries removed from the channel
+	// delta log.
+	NumChannelDeltas uint32
+
+	// NumRevokedCommits is the number of entries removed from the revoked
+	// commitment log, along with their txid index entries.
+	NumRevokedCommits uint32
+}
+
+// BucketSize details the on-disk footprint of a single top-level bucket.
+type BucketSize struct {
+	// NumKeys is the total number of keys within the bucket, including
+	// the keys within all nested buckets.
+	NumKeys int
+
+	// BytesInUse is the number of bytes actually used to store the
+	// bucket's data, excluding free space within allocated pages.
+	BytesInUse int
+
+	// BytesAllocated is the number of bytes allocated to the bucket's
+	// pages.
+	BytesAllocated int
+}
+
+// SizeMetrics is a summary of the size of the database, both on disk and
+// broken down by each of the top-level buckets.
+type SizeMetrics struct {
+	// FileSize is the size of the database file on disk.
+	FileSize int64
+
+	// FreePages is the number of pages within the file which are free,
+	// and will be reclaimed by a call to Compact.
+	FreePages int
+
+	// Buckets maps the name of each top-level bucket to its size.
+	Buckets map[string]*BucketSize
+}
+
+// SizeMetrics returns the current size metrics of the database.
+func (d *DB) SizeMetrics() (*SizeMetrics, error) {
+	metrics := &SizeMetrics{
+		Buckets: make(map[string]*BucketSize),
+	}
+
+	err := d.store.View(func(tx *bolt.Tx) error {
+		metrics.FileSize = tx.Size()
+
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			stats := b.Stats()
+			inUse := stats.BranchInuse + stats.LeafInuse
+			allocated := stats.BranchAlloc + stats.LeafAlloc
+
+			metrics.Buckets[string(name)] = &BucketSize{
+				NumKeys:        stats.KeyN,
+				BytesInUse:     inUse,
+				BytesAllocated: allocated,
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dbStats := d.store.Stats()
+	metrics.FreePages = dbStats.FreePageN
+
+	return metrics, nil
+}
+
+// PruneChannelState removes records from the database which are no longer
+// required. The set of HTLC's for channels which have been fully closed is
+// removed, along with all revocation data for closed channels. Additionally,
+// for open channels, revocation data for states older than the configured
+// retention is removed. All records are removed within a single transaction.
+func (d *DB) PruneChannelState(cfg *PruneConfig) (*PruneSummary, error) {
+	summary := &PruneSummary{}
+
+	err := d.store.Update(func(tx *bolt.Tx) error {
+		openChanBucket := tx.Bucket(openChannelBucket)
+		if openChanBucket == nil {
+			return ErrNoChanDBExists
+		}
+
+		// Gather the set of node buckets up front, as the bucket can't
+		// be modified while we're iterating over it.
+		var nodeIDs [][]byte
+		err := openChanBucket.ForEach(func(k, v []byte) error {
+			// Only nested buckets have a nil value.
+			if v == nil {
+				nodeIDs = append(nodeIDs, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, nodeID := range nodeIDs {
+			nodeChanBucket := openChanBucket.Bucket(nodeID)
+
+			openChans, err := fetchNodeChanHeights(openChanBucket,
+				nodeChanBucket)
+			if err != nil {
+				return err
+			}
+
+			if err := pruneNodeChanState(nodeChanBucket, openChans,
+				cfg, summary); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Pruned %v htlc sets, %v channel deltas, and %v revoked "+
+		"commitments from channeldb", summary.NumHtlcRecords,
+		summary.NumChannelDeltas, summary.NumRevokedCommits)
+
+	return summary, nil
+}
+
+// fetchNodeChanHeights returns the current update number of each channel
+// still open within the passed node's channel bucket.
+func fetchNodeChanHeights(openChanBucket,
+	nodeChanBucket *bolt.Bucket) (map[wire.OutPoint]uint64, error) {
+
+	openChans := make(map[wire.OutPoint]uint64)
+
+	chanIndex := nodeChanBucket.Bucket(chanIDBucket)
+	if chanIndex == nil {
+		return openChans, nil
+	}
+
+	err := chanIndex.ForEach(func(k, v []byte) error {
+		chanID := &wire.OutPoint{}
+		if err := readOutpoint(bytes.NewReader(k), chanID); err != nil {
+			return err
+		}
+
+		channel := &OpenChannel{ChanID: chanID}
+		if err := fetchChanNumUpdates(openChanBucket, channel); err != nil {
+			return err
+		}
+
+		openChans[*chanID] = channel.NumUpdates
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return openChans, nil
+}
+
+// pruneNodeChanState prunes all stale records within a node's channel bucket
+// given the set of channels which are still open with the node.
+func pruneNodeChanState(nodeChanBucket *bolt.Bucket,
+	openChans map[wire.OutPoint]uint64, cfg *PruneConfig,
+	summary *PruneSummary) error {
+
+	// isStale returns true if the state for the channel at the target
+	// update number should be removed.
+	isStale := func(chanPoint wire.OutPoint, updateNum uint64) bool {
+		numUpdates, ok := openChans[chanPoint]
+		if !ok {
+			return true
+		}
+
+		retention := cfg.RevocationRetention
+		return retention != 0 && updateNum+retention < numUpdates
+	}
+
+	// First, remove the set of HTLC's for any channel which is no longer
+	// open. The key for each set is: chk || txid || index. As keys can't
+	// be deleted while iterating, each stale key is copied out first.
+	var staleKeys [][]byte
+	c := nodeChanBucket.Cursor()
+	for k, _ := c.Seek(currentHtlcKey); k != nil &&
+		bytes.HasPrefix(k, currentHtlcKey); k, _ = c.Next() {
+
+		if len(k) != len(currentHtlcKey)+36 {
+			continue
+		}
+
+		chanPoint := parseChanPointKey(k[len(currentHtlcKey):])
+		if _, ok := openChans[chanPoint]; !ok {
+			staleKeys = append(staleKeys, append([]byte(nil), k...))
+		}
+	}
+	for _, k := range staleKeys {
+		if err := nodeChanBucket.Delete(k); err != nil {
+			return err
+		}
+		summary.NumHtlcRecords++
+	}
+
+	// Next, prune the channel delta log. The key for each entry is:
+	// txid || index || updateNum, with a 4-byte update number.
+	if logBucket := nodeChanBucket.Bucket(channelLogBucket); logBucket != nil {
+		staleKeys = staleKeys[:0]
+		err := logBucket.ForEach(func(k, v []byte) error {
+			if len(k) != 40 {
+				return nil
+			}
+
+			chanPoint := parseChanPointKey(k[:36])
+			updateNum := uint64(byteOrder.Uint32(k[36:]))
+			if isStale(chanPoint, updateNum) {
+				staleKeys = append(staleKeys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range staleKeys {
+			if err := logBucket.Delete(k); err != nil {
+				return err
+			}
+			summary.NumChannelDeltas++
+		}
+	}
+
+	// Finally, prune the revoked commitment log along with its txid
+	// index. The key for each entry is: txid || index || updateNum, with
+	// an 8-byte update number.
+	revokedBucket := nodeChanBucket.Bucket(revokedCommitBucket)
+	txidIndex := nodeChanBucket.Bucket(revokedTxidIndexBucket)
+	if revokedBucket == nil || txidIndex == nil {
+		return nil
+	}
+
+	var staleTxids [][]byte
+	err := txidIndex.ForEach(func(txid, entryKey []byte) error {
+		if len(entryKey) != 44 {
+			return nil
+		}
+
+		chanPoint := parseChanPointKey(entryKey[:36])
+		updateNum := byteOrder.Uint64(entryKey[36:])
+		if isStale(chanPoint, updateNum) {
+			staleTxids = append(staleTxids, append([]byte(nil), txid...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, txid := range staleTxids {
+		entryKey := txidIndex.Get(txid)
+		if err := revokedBucket.Delete(entryKey); err != nil {
+			return err
+		}
+		if err := txidIndex.Delete(txid); err != nil {
+			return err
+		}
+		summary.NumRevokedCommits++
+	}
+
+	return nil
+}
+
+// parseChanPointKey parses a channel point encoded as: txid || index.
+func parseChanPointKey(k []byte) wire.OutPoint {
+	var chanPoint wire.OutPoint
+	copy(chanPoint.Hash[:], k[:32])
+	chanPoint.Index = byteOrder.Uint32(k[32:36])
+	return chanPoint
+}
+
+// Compact rewrites the entire database into a fresh file, reclaiming all the
+// free pages left behind by prior deletions, such as those performed by
+// PruneChannelState. The compacted copy is written alongside the current
+// database, then atomically swapped in place of the original.
+//
+// NOTE: The database must not be in use by any other goroutine while it is
+// being compacted.
+func (d *DB) Compact() error {
+	path := filepath.Join(d.dbPath, dbName)
+	tempPath := path + ".compact"
+
+	// If a prior compaction was interrupted, then a partial copy may
+	// still be lingering, so we remove it before starting afresh.
+	if fileExists(tempPath) {
+		if err := os.Remove(tempPath); err != nil {
+			return err
+		}
+	}
+
+	compactDB, err := bolt.Open(tempPath, 0600, nil)
+	if err != nil {
+		return err
+	}
+
+	err = d.store.View(func(srcTx *bolt.Tx) error {
+		return compactDB.Update(func(dstTx *bolt.Tx) error {
+			return srcTx.ForEach(func(name []byte, b *bolt.Bucket) error {
+				dstBucket, err := dstTx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+
+				return copyBucket(dstBucket, b)
+			})
+		})
+	})
+	if err != nil {
+		compactDB.Close()
+		os.Remove(tempPath)
+		return err
+	}
+	if err := compactDB.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+
+	// With the compacted copy written, we close the current database,
+	// swap the two files, and re-open the now compacted database. The
+	// original is moved aside rather than replaced, so it can be restored
+	// should any step of the swap fail.
+	if err := d.store.Close(); err != nil {
+		os.Remove(tempPath)
+		return err
+	}
+	backupPath := path + ".bak"
+	if err := os.Rename(path, backupPath); err != nil {
+		os.Remove(tempPath)
+		return d.reopen(path, err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return d.restore(backupPath, path, err)
+	}
+
+	bdb, err := openBolt(path)
+	if err != nil {
+		return d.restore(backupPath, path, err)
+	}
+	d.store = bdb
+
+	return os.Remove(backupPath)
+}
+
+// restore moves the original database file, set aside by Compact, back in
+// place, then re-opens it. The passed error, which caused the compaction to
+// fail, is returned.
+func (d *DB) restore(backupPath, path string, compactErr error) error {
+	if err := os.Rename(backupPath, path); err != nil {
+		return fmt.Errorf("unable to restore database from %v after "+
+			"failed compaction (%v): %v", backupPath, compactErr, err)
+	}
+
+	return d.reopen(path, compactErr)
+}
+
+// reopen re-opens the original database file after a failed compaction,
+// returning the error which caused the compaction to fail.
+func (d *DB) reopen(path string, compactErr error) error {
+	bdb, err := openBolt(path)
+	if err != nil {
+		return fmt.Errorf("unable to re-open database after failed "+
+			"compaction (%v): %v", compactErr, err)
+	}
+	d.store = bdb
+
+	return compactErr
+}
+
+// copyBucket recursively copies all the keys and nested buckets within the
+// src bucket into the dst bucket.
+func copyBucket(dst, src *bolt.Bucket) error {
+	// Entries are inserted in sorted order, so we can fill each page
+	// entirely.
+	dst.FillPercent = 1.0
+
+	return src.ForEach(func(k, v []byte) error {
+		// A nil value indicates a nested bucket.
+		if v == nil {
+			nestedDst, err := dst.CreateBucket(k)
+			if err != nil {
+				return err
+			}
+
+			return copyBucket(nestedDst, src.Bucket(k))
+		}
+
+		return dst.Put(k, v)
+	})
+}
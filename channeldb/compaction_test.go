@@ -0,0 +1,189 @@
+package channeldb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestPruneChannelStateAndCompact(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	channel.NumUpdates = 5
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	// Record five states within both the channel delta log, and the
+	// revoked commitment log.
+	for i := uint32(1); i <= 5; i++ {
+		delta := &ChannelDelta{
+			LocalBalance:  channel.OurBalance,
+			RemoteBalance: channel.TheirBalance,
+			UpdateNum:     i,
+		}
+		if err := channel.AppendToRevocationLog(delta); err != nil {
+			t.Fatalf("unable to append to revocation log: %v", err)
+		}
+
+		revoked := &RevokedCommitment{
+			UpdateNum:     uint64(i),
+			CommitTxid:    wire.ShaHash{byte(i)},
+			RevocationKey: pubKey,
+		}
+		if err := channel.PutRevokedCommitment(revoked); err != nil {
+			t.Fatalf("unable to store revoked commitment: %v", err)
+		}
+	}
+
+	// With a retention of two states, the entries for the first two
+	// states should be pruned.
+	summary, err := cdb.PruneChannelState(&PruneConfig{
+		RevocationRetention: 2,
+	})
+	if err != nil {
+		t.Fatalf("unable to prune channel state: %v", err)
+	}
+	if summary.NumChannelDeltas != 2 || summary.NumRevokedCommits != 2 {
+		t.Fatalf("expected 2 deltas and 2 revoked commits pruned, "+
+			"instead got %v and %v", summary.NumChannelDeltas,
+			summary.NumRevokedCommits)
+	}
+	if _, err := channel.FindPreviousState(2); err == nil {
+		t.Fatalf("pruned state should no longer be found")
+	}
+	if _, err := channel.FetchRevokedCommitment(2); err != ErrRevokedCommitNotFound {
+		t.Fatalf("expected ErrRevokedCommitNotFound, got %v", err)
+	}
+	staleTxid := wire.ShaHash{2}
+	if _, err := channel.FetchRevokedCommitmentByTxid(&staleTxid); err != ErrRevokedCommitNotFound {
+		t.Fatalf("expected ErrRevokedCommitNotFound, got %v", err)
+	}
+	if _, err := channel.FindPreviousState(3); err != nil {
+		t.Fatalf("unable to fetch retained state: %v", err)
+	}
+	if _, err := channel.FetchRevokedCommitment(3); err != nil {
+		t.Fatalf("unable to fetch retained revoked state: %v", err)
+	}
+
+	// Once the channel is closed, all remaining revocation data, along
+	// with the stale HTLC set, should be pruned even without a retention
+	// limit.
+	closeSummary := &ChannelCloseSummary{
+		ChanPoint:    *channel.ChanID,
+		RemoteID:     channel.TheirLNID,
+		Capacity:     channel.AssetCapacity,
+		OurBalance:   channel.OurBalance,
+		TheirBalance: channel.TheirBalance,
+		CloseType:    CooperativeClose,
+		ClosingTXID:  testTx.TxSha(),
+	}
+	if err := channel.CloseChannel(closeSummary); err != nil {
+		t.Fatalf("unable to close channel: %v", err)
+	}
+	summary, err = cdb.PruneChannelState(&PruneConfig{})
+	if err != nil {
+		t.Fatalf("unable to prune channel state: %v", err)
+	}
+	if summary.NumHtlcRecords != 1 || summary.NumChannelDeltas != 3 ||
+		summary.NumRevokedCommits != 3 {
+		t.Fatalf("unexpected prune summary: %v htlc sets, %v deltas, "+
+			"%v revoked commits", summary.NumHtlcRecords,
+			summary.NumChannelDeltas, summary.NumRevokedCommits)
+	}
+
+	// Finally, compacting the database should shrink it on disk, while
+	// leaving the remaining records intact.
+	before, err := cdb.SizeMetrics()
+	if err != nil {
+		t.Fatalf("unable to fetch size metrics: %v", err)
+	}
+	if err := cdb.Compact(); err != nil {
+		t.Fatalf("unable to compact database: %v", err)
+	}
+	after, err := cdb.SizeMetrics()
+	if err != nil {
+		t.Fatalf("unable to fetch size metrics: %v", err)
+	}
+	if after.FileSize > before.FileSize {
+		t.Fatalf("database grew after compaction: %v -> %v",
+			before.FileSize, after.FileSize)
+	}
+	if _, ok := after.Buckets[string(openChannelBucket)]; !ok {
+		t.Fatalf("open channel bucket missing after compaction")
+	}
+
+	closedChans, err := cdb.ListClosedChannels()
+	if err != nil {
+		t.Fatalf("unable to list closed channels: %v", err)
+	}
+	if len(closedChans) != 1 {
+		t.Fatalf("expected 1 closed channel, instead have %v",
+			len(closedChans))
+	}
+}
+
+// TestCompactFailureReopens tests that a compaction failing once the database
+// has been closed leaves the original database open, and intact.
+func TestCompactFailureReopens(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	// Occupy the path the original database is moved aside to with a
+	// non-empty directory, so the swap of the two files fails.
+	backupPath := filepath.Join(cdb.dbPath, dbName) + ".bak"
+	if err := os.MkdirAll(filepath.Join(backupPath, "x"), 0700); err != nil {
+		t.Fatalf("unable to create directory: %v", err)
+	}
+
+	if err := cdb.Compact(); err == nil {
+		t.Fatalf("compaction succeeded despite failed swap")
+	}
+
+	// The original database should've been re-opened, with the compacted
+	// copy removed.
+	if fileExists(filepath.Join(cdb.dbPath, dbName) + ".compact") {
+		t.Fatalf("compacted copy left behind")
+	}
+	openChans, err := cdb.FetchAllChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch channels after failed "+
+			"compaction: %v", err)
+	}
+	if len(openChans) != 1 {
+		t.Fatalf("expected 1 open channel, instead have %v",
+			len(openChans))
+	}
+
+	// Once the swap is able to succeed, so does the compaction.
+	if err := os.RemoveAll(backupPath); err != nil {
+		t.Fatalf("unable to remove directory: %v", err)
+	}
+	if err := cdb.Compact(); err != nil {
+		t.Fatalf("unable to compact database: %v", err)
+	}
+	if fileExists(backupPath) {
+		t.Fatalf("original database left behind")
+	}
+}
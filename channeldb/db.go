@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/boltdb/bolt"
 	"github.com/roasbeef/btcd/chaincfg"
@@ -23,6 +24,10 @@ var (
 	byteOrder = binary.BigEndian
 )
 
+// dbLockTimeout is the time to wait for the exclusive lock bolt holds on the
+// database file, before concluding the database is in use by another process.
+var dbLockTimeout = 2 * time.Second
+
 var bufPool = &sync.Pool{
 	New: func() interface{} { return new(bytes.Buffer) },
 }
@@ -31,14 +36,21 @@ var bufPool = &sync.Pool{
 // information related to nodes, routing data, open/closed channels, fee
 // schedules, and reputation data.
 type DB struct {
-	store *bolt.DB
+	store  *bolt.DB
+	dbPath string
 
 	netParams *chaincfg.Params
+
+	// deltaObserver is notified of each committed channel delta, allowing
+	// the channel state to be replicated elsewhere.
+	observerMtx   sync.RWMutex
+	deltaObserver DeltaObserver
 }
 
 // Open opens an existing channeldb created under the passed namespace with
 // sensitive data encrypted by the passed EncryptorDecryptor implementation.
-// TODO(roasbeef): versioning?
+// If the on-disk schema is out of date, all pending migrations are applied
+// before the database is returned.
 func Open(dbPath string, netParams *chaincfg.Params) (*DB, error) {
 	path := filepath.Join(dbPath, dbName)
 
@@ -48,12 +60,34 @@ func Open(dbPath string, netParams *chaincfg.Params) (*DB, error) {
 		}
 	}
 
-	bdb, err := bolt.Open(path, 0600, nil)
+	bdb, err := openBolt(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return &DB{store: bdb, netParams: netParams}, nil
+	chanDB := &DB{store: bdb, dbPath: dbPath, netParams: netParams}
+
+	// Synchronize the version of the database, applying any migrations
+	// required to bring it up to date.
+	if err := chanDB.syncVersions(dbVersions, dbPath); err != nil {
+		bdb.Close()
+		return nil, err
+	}
+
+	return chanDB, nil
+}
+
+// openBolt opens the bolt database at the passed path. Running two instances
+// against the same database would corrupt the state of their channels, so
+// rather than blocking indefinitely on the lock held by another process,
+// ErrDBInUse is returned.
+func openBolt(path string) (*bolt.DB, error) {
+	bdb, err := bolt.Open(path, 0600, &bolt.Options{Timeout: dbLockTimeout})
+	if err == bolt.ErrTimeout {
+		return nil, ErrDBInUse
+	}
+
+	return bdb, err
 }
 
 // Wipe completely deletes all saved state within all used buckets within the
@@ -92,7 +126,7 @@ func createChannelDB(dbPath string) error {
 	}
 
 	path := filepath.Join(dbPath, dbName)
-	bdb, err := bolt.Open(path, 0600, nil)
+	bdb, err := openBolt(path)
 	if err != nil {
 		return err
 	}
@@ -110,7 +144,14 @@ func createChannelDB(dbPath string) error {
 			return err
 		}
 
-		return nil
+		if _, err := tx.CreateBucket(fundingBundleBucket); err != nil {
+			return err
+		}
+
+		// Freshly created databases start out at the latest version,
+		// so no migrations need to be applied.
+		meta := &Meta{DbVersionNumber: latestDBVersion}
+		return putMeta(meta, tx)
 	})
 	if err != nil {
 		return fmt.Errorf("unable to create new channeldb")
@@ -151,40 +192,116 @@ func (d *DB) FetchOpenChannels(nodeID *wire.ShaHash) ([]*OpenChannel, error) {
 			return nil
 		}
 
-		// Once we have the node's channel bucket, iterate through each
-		// item in the inner chan ID bucket. This bucket acts as an
-		// index for all channels we currently have open with this node.
-		nodeChanIDBucket := nodeChanBucket.Bucket(chanIDBucket[:])
-		if nodeChanIDBucket == nil {
+		var err error
+		channels, err = d.fetchNodeChannels(openChanBucket, nodeChanBucket)
+		return err
+	})
+
+	return channels, err
+}
+
+// FetchAllChannels returns all stored currently active/open channels, across
+// all the nodes we have channels open with. In the case that no active
+// channels are known, then a zero-length slice is returned.
+func (d *DB) FetchAllChannels() ([]*OpenChannel, error) {
+	var channels []*OpenChannel
+	err := d.store.View(func(tx *bolt.Tx) error {
+		openChanBucket := tx.Bucket(openChannelBucket)
+		if openChanBucket == nil {
 			return nil
 		}
-		err := nodeChanIDBucket.ForEach(func(k, v []byte) error {
-			if k == nil {
-				return nil
-			}
 
-			outBytes := bytes.NewReader(k)
-			chanID := &wire.OutPoint{}
-			if err := readOutpoint(outBytes, chanID); err != nil {
-				return err
+		return openChanBucket.ForEach(func(k, v []byte) error {
+			// Only nested buckets have a nil value.
+			if v != nil {
+				return nil
 			}
 
-			oChannel, err := fetchOpenChannel(openChanBucket,
-				nodeChanBucket, chanID)
+			nodeChans, err := d.fetchNodeChannels(openChanBucket,
+				openChanBucket.Bucket(k))
 			if err != nil {
 				return err
 			}
-			oChannel.Db = d
 
-			channels = append(channels, oChannel)
+			channels = append(channels, nodeChans...)
 			return nil
 		})
+	})
+
+	return channels, err
+}
+
+// fetchNodeChannels returns all the channels stored within the passed node
+// channel bucket.
+func (d *DB) fetchNodeChannels(openChanBucket,
+	nodeChanBucket *bolt.Bucket) ([]*OpenChannel, error) {
+
+	// Once we have the node's channel bucket, iterate through each item in
+	// the inner chan ID bucket. This bucket acts as an index for all
+	// channels we currently have open with this node.
+	nodeChanIDBucket := nodeChanBucket.Bucket(chanIDBucket[:])
+	if nodeChanIDBucket == nil {
+		return nil, nil
+	}
+
+	var channels []*OpenChannel
+	err := nodeChanIDBucket.ForEach(func(k, v []byte) error {
+		if k == nil {
+			return nil
+		}
+
+		outBytes := bytes.NewReader(k)
+		chanID := &wire.OutPoint{}
+		if err := readOutpoint(outBytes, chanID); err != nil {
+			return err
+		}
+
+		oChannel, err := fetchOpenChannel(openChanBucket,
+			nodeChanBucket, chanID)
 		if err != nil {
 			return err
 		}
+		oChannel.Db = d
 
+		channels = append(channels, oChannel)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
-	return channels, err
+	return channels, nil
+}
+
+// ListClosedChannels returns a slice of summaries of all the channels which
+// have been closed, and deleted from the set of open channels.
+func (d *DB) ListClosedChannels() ([]*ChannelCloseSummary, error) {
+	var chanSummaries []*ChannelCloseSummary
+	err := d.store.View(func(tx *bolt.Tx) error {
+		closedChanBucket := tx.Bucket(closedChannelBucket)
+		if closedChanBucket == nil {
+			return nil
+		}
+
+		return closedChanBucket.ForEach(func(k, v []byte) error {
+			// Channels closed before summaries were recorded only
+			// store the channel point, so we skip them.
+			if len(v) == 0 {
+				return nil
+			}
+
+			summary, err := deserializeChannelCloseSummary(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+
+			chanSummaries = append(chanSummaries, summary)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return chanSummaries, nil
 }
@@ -5,6 +5,9 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/roasbeef/btcd/wire"
 )
 
 func TestOpenWithCreate(t *testing.T) {
@@ -31,3 +34,82 @@ func TestOpenWithCreate(t *testing.T) {
 		t.Fatalf("channeldb failed to create data directory")
 	}
 }
+
+// TestOpenInUse tests that opening a database already held open by another
+// instance fails with ErrDBInUse, rather than blocking indefinitely.
+func TestOpenInUse(t *testing.T) {
+	defer func(timeout time.Duration) {
+		dbLockTimeout = timeout
+	}(dbLockTimeout)
+	dbLockTimeout = 100 * time.Millisecond
+
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	if _, err := Open(cdb.dbPath, netParams); err != ErrDBInUse {
+		t.Fatalf("expected ErrDBInUse, got %v", err)
+	}
+
+	// Once the first instance closes the database, it can be opened
+	// again.
+	if err := cdb.Close(); err != nil {
+		t.Fatalf("unable to close database: %v", err)
+	}
+	cdb, err = Open(cdb.dbPath, netParams)
+	if err != nil {
+		t.Fatalf("unable to reopen database: %v", err)
+	}
+	cdb.Close()
+}
+
+// TestFetchAllChannels tests that the open channels of all nodes are
+// returned, rather than those of a single node.
+func TestFetchAllChannels(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channels, err := cdb.FetchAllChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch channels: %v", err)
+	}
+	if len(channels) != 0 {
+		t.Fatalf("expected no channels, got %v", len(channels))
+	}
+
+	// Store a channel with each of two distinct nodes.
+	for i := byte(0); i < 2; i++ {
+		state, err := createTestChannelState(cdb)
+		if err != nil {
+			t.Fatalf("unable to create channel state: %v", err)
+		}
+		state.TheirLNID[0] = i
+		state.ChanID = &wire.OutPoint{Index: uint32(i)}
+		if err := state.FullSync(); err != nil {
+			t.Fatalf("unable to save channel state: %v", err)
+		}
+	}
+
+	channels, err = cdb.FetchAllChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch channels: %v", err)
+	}
+	if len(channels) != 2 {
+		t.Fatalf("expected 2 channels, got %v", len(channels))
+	}
+	for _, channel := range channels {
+		if channel.TheirLNID[0] != byte(channel.ChanID.Index) {
+			t.Fatalf("ChannelPoint(%v) returned for wrong node %x",
+				channel.ChanID, channel.TheirLNID)
+		}
+		if channel.Db != cdb {
+			t.Fatalf("ChannelPoint(%v) not bound to database",
+				channel.ChanID)
+		}
+	}
+}
@@ -4,11 +4,21 @@ import "fmt"
 
 var (
 	ErrNoChanDBExists = fmt.Errorf("channel db has not yet been created")
+	ErrDBReversion    = fmt.Errorf("channel db cannot revert to prior version")
+	ErrDBInUse        = fmt.Errorf("channel db is in use by another process")
 
 	ErrNoActiveChannels = fmt.Errorf("no active channels exist")
 	ErrChannelNoExist   = fmt.Errorf("this channel does not exist")
 	ErrNoPastDeltas     = fmt.Errorf("channel has no recorded deltas")
 
+	ErrRevokedCommitNotFound = fmt.Errorf("unable to locate revoked commitment")
+
 	ErrInvoiceNotFound  = fmt.Errorf("unable to locate invoice")
 	ErrDuplicateInvoice = fmt.Errorf("invoice with payment hash already exists")
+
+	ErrPreimageNotFound = fmt.Errorf("unable to locate preimage")
+
+	ErrPeerBackupNotFound = fmt.Errorf("unable to locate peer backup")
+
+	ErrFundingBundleNotFound = fmt.Errorf("unable to locate funding bundle")
 )
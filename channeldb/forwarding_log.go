@@ -0,0 +1,276 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// forwardingLogBucket is the bucket that we'll use to store the
+	// forwarding log. The forwarding log contains a time series database
+	// of the forwarding history of a lightning daemon. Each key within the
+	// bucket is a timestamp (in nano seconds since the unix epoch), and
+	// the value a serialized ForwardingEvent.
+	forwardingLogBucket = []byte("fwd")
+
+	// fwdLogMtx serializes writers to the forwarding log, ensuring that
+	// each timestamp key is unique.
+	fwdLogMtx sync.Mutex
+)
+
+const (
+	// MaxResponseEvents is the max number of forwarding events that will
+	// be returned by a single query response.
+	MaxResponseEvents = 50000
+)
+
+// ForwardingEvent is an event in the forwarding log's time series. Each
+// forwarding event logs the creation and tear-down of a payment circuit. A
+// circuit is created once an incoming HTLC has been fully forwarded, and
+// destroyed once the payment has been settled.
+type ForwardingEvent struct {
+	// Timestamp is the settlement time of this payment circuit.
+	Timestamp time.Time
+
+	// IncomingChanID is the incoming channel ID of the payment circuit.
+	IncomingChanID wire.OutPoint
+
+	// OutgoingChanID is the outgoing channel ID of the payment circuit.
+	OutgoingChanID wire.OutPoint
+
+	// IncomingAssetID is the identifier of the colored asset the incoming
+	// HTLC was denominated in.
+	IncomingAssetID string
+
+	// OutgoingAssetID is the identifier of the colored asset the outgoing
+	// HTLC was denominated in. This differs from the IncomingAssetID if
+	// the payment was converted from one asset into another.
+	OutgoingAssetID string
+
+	// AmtIn is the amount of the incoming HTLC, denominated in the
+	// incoming asset.
+	AmtIn btcutil.Amount
+
+	// AmtOut is the amount of the outgoing HTLC, denominated in the
+	// outgoing asset.
+	AmtOut btcutil.Amount
+}
+
+// Fee returns the fee earned for forwarding the payment, denominated in the
+// event's asset. The fee of a payment converted from one asset into another
+// can't be expressed in a single asset, so false is returned for such events.
+func (f *ForwardingEvent) Fee() (btcutil.Amount, bool) {
+	if f.IncomingAssetID != f.OutgoingAssetID {
+		return 0, false
+	}
+
+	return f.AmtIn - f.AmtOut, true
+}
+
+// ForwardingEventQuery represents a query to the forwarding log payment
+// circuit time series database. The query allows a caller to retrieve all
+// records for a particular time slice, optionally restricted to a single
+// asset.
+type ForwardingEventQuery struct {
+	// StartTime is the start time of the time slice.
+	StartTime time.Time
+
+	// EndTime is the end time of the time slice.
+	EndTime time.Time
+
+	// AssetID, if non-empty, restricts the query to events whose incoming
+	// or outgoing HTLC was denominated in the target asset.
+	AssetID string
+
+	// NumMaxEvents is the max number of events to return. If zero, then
+	// MaxResponseEvents is used.
+	NumMaxEvents uint32
+}
+
+// AddForwardingEvents adds a series of forwarding events to the database.
+// Events are keyed by their timestamp, so if an event collides with an
+// existing entry, its key is bumped by a nanosecond until a free slot is
+// found.
+func (d *DB) AddForwardingEvents(events []ForwardingEvent) error {
+	fwdLogMtx.Lock()
+	defer fwdLogMtx.Unlock()
+
+	return d.store.Update(func(tx *bolt.Tx) error {
+		logBucket, err := tx.CreateBucketIfNotExists(forwardingLogBucket)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		for i := range events {
+			event := &events[i]
+
+			// Two events may share a timestamp, so in order to
+			// avoid clobbering an existing entry, we bump the key
+			// until a free slot is found.
+			var timestamp [8]byte
+			eventTime := event.Timestamp.UnixNano()
+			for {
+				byteOrder.PutUint64(timestamp[:], uint64(eventTime))
+				if logBucket.Get(timestamp[:]) == nil {
+					break
+				}
+				eventTime++
+			}
+
+			b.Reset()
+			if err := serializeForwardingEvent(&b, event); err != nil {
+				return err
+			}
+			if err := logBucket.Put(timestamp[:], b.Bytes()); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// QueryForwardingEvents returns all the forwarding events which fall within
+// the time slice specified by the passed query, in chronological order.
+func (d *DB) QueryForwardingEvents(q ForwardingEventQuery) ([]ForwardingEvent, error) {
+	maxEvents := q.NumMaxEvents
+	if maxEvents == 0 || maxEvents > MaxResponseEvents {
+		maxEvents = MaxResponseEvents
+	}
+
+	var events []ForwardingEvent
+	err := d.store.View(func(tx *bolt.Tx) error {
+		logBucket := tx.Bucket(forwardingLogBucket)
+		if logBucket == nil {
+			return nil
+		}
+
+		var startKey, endKey [8]byte
+		byteOrder.PutUint64(startKey[:], uint64(q.StartTime.UnixNano()))
+		byteOrder.PutUint64(endKey[:], uint64(q.EndTime.UnixNano()))
+
+		// As the keys are big-endian timestamps, a cursor seek allows
+		// us to efficiently scan only the relevant time slice.
+		c := logBucket.Cursor()
+		for k, v := c.Seek(startKey[:]); k != nil &&
+			bytes.Compare(k, endKey[:]) <= 0; k, v = c.Next() {
+
+			event, err := deserializeForwardingEvent(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			event.Timestamp = time.Unix(0, int64(byteOrder.Uint64(k)))
+
+			if q.AssetID != "" && event.IncomingAssetID != q.AssetID &&
+				event.OutgoingAssetID != q.AssetID {
+
+				continue
+			}
+
+			events = append(events, *event)
+			if uint32(len(events)) >= maxEvents {
+				return nil
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// ForwardingRevenue returns the total fees earned from forwarding payments
+// denominated in the target asset within the passed time slice. Payments
+// converted from, or into the asset are skipped, as the revenue of such
+// payments is earned through the spread of the swap rate instead.
+func (d *DB) ForwardingRevenue(assetID string, startTime,
+	endTime time.Time) (btcutil.Amount, error) {
+
+	events, err := d.QueryForwardingEvents(ForwardingEventQuery{
+		StartTime: startTime,
+		EndTime:   endTime,
+		AssetID:   assetID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var revenue btcutil.Amount
+	for i := range events {
+		if fee, ok := events[i].Fee(); ok {
+			revenue += fee
+		}
+	}
+
+	return revenue, nil
+}
+
+func serializeForwardingEvent(w io.Writer, f *ForwardingEvent) error {
+	if err := writeOutpoint(w, &f.IncomingChanID); err != nil {
+		return err
+	}
+	if err := writeOutpoint(w, &f.OutgoingChanID); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, []byte(f.IncomingAssetID)); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, []byte(f.OutgoingAssetID)); err != nil {
+		return err
+	}
+
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], uint64(f.AmtIn))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], uint64(f.AmtOut))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func deserializeForwardingEvent(r io.Reader) (*ForwardingEvent, error) {
+	f := &ForwardingEvent{}
+
+	if err := readOutpoint(r, &f.IncomingChanID); err != nil {
+		return nil, err
+	}
+	if err := readOutpoint(r, &f.OutgoingChanID); err != nil {
+		return nil, err
+	}
+	incomingAssetID, err := wire.ReadVarBytes(r, 0, 1000, "assetID")
+	if err != nil {
+		return nil, err
+	}
+	f.IncomingAssetID = string(incomingAssetID)
+	outgoingAssetID, err := wire.ReadVarBytes(r, 0, 1000, "assetID")
+	if err != nil {
+		return nil, err
+	}
+	f.OutgoingAssetID = string(outgoingAssetID)
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	f.AmtIn = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	f.AmtOut = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	return f, nil
+}
@@ -0,0 +1,128 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+func TestForwardingLogQuery(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	const (
+		assetA = "La3Ubh2cbnLM2a5X3Ceb9Q9TNQ1eJvkGr6sHW1"
+		assetB = "LaAnnL5NoBeD7YJvvzsHeHzvDvPbScRygCVwqs"
+	)
+
+	// Create a series of forwarding events, alternating between two
+	// assets, each a second apart. The last event converts asset A into
+	// asset B.
+	startTime := time.Unix(1000, 0)
+	numEvents := 10
+	events := make([]ForwardingEvent, numEvents)
+	for i := 0; i < numEvents; i++ {
+		assetID := assetA
+		if i%2 == 1 {
+			assetID = assetB
+		}
+
+		events[i] = ForwardingEvent{
+			Timestamp:       startTime.Add(time.Duration(i) * time.Second),
+			IncomingChanID:  wire.OutPoint{Hash: key, Index: uint32(i)},
+			OutgoingChanID:  wire.OutPoint{Hash: key, Index: uint32(i + 1)},
+			IncomingAssetID: assetID,
+			OutgoingAssetID: assetID,
+			AmtIn:           btcutil.Amount(1000 + i),
+			AmtOut:          btcutil.Amount(1000),
+		}
+	}
+	events[numEvents-1].IncomingAssetID = assetA
+	if err := db.AddForwardingEvents(events); err != nil {
+		t.Fatalf("unable to add forwarding events: %v", err)
+	}
+
+	// A query spanning the entire time series should return all events,
+	// in order.
+	allEvents, err := db.QueryForwardingEvents(ForwardingEventQuery{
+		StartTime: startTime,
+		EndTime:   startTime.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("unable to query forwarding log: %v", err)
+	}
+	if !reflect.DeepEqual(events, allEvents) {
+		t.Fatalf("events mismatch: expected %v, got %v",
+			spew.Sdump(events), spew.Sdump(allEvents))
+	}
+
+	// Restricting the query to a single asset and a narrower time slice
+	// should only return the matching subset.
+	assetEvents, err := db.QueryForwardingEvents(ForwardingEventQuery{
+		StartTime: startTime.Add(2 * time.Second),
+		EndTime:   startTime.Add(6 * time.Second),
+		AssetID:   assetA,
+	})
+	if err != nil {
+		t.Fatalf("unable to query forwarding log: %v", err)
+	}
+	if len(assetEvents) != 3 {
+		t.Fatalf("expected 3 events, instead got %v", len(assetEvents))
+	}
+	for _, event := range assetEvents {
+		if event.IncomingAssetID != assetA {
+			t.Fatalf("event for wrong asset returned: %v",
+				event.IncomingAssetID)
+		}
+	}
+
+	// The max events limit should be respected.
+	limitedEvents, err := db.QueryForwardingEvents(ForwardingEventQuery{
+		StartTime:    startTime,
+		EndTime:      startTime.Add(time.Hour),
+		NumMaxEvents: 4,
+	})
+	if err != nil {
+		t.Fatalf("unable to query forwarding log: %v", err)
+	}
+	if len(limitedEvents) != 4 {
+		t.Fatalf("expected 4 events, instead got %v", len(limitedEvents))
+	}
+
+	// A converted payment matches both of its assets, while its fee can't
+	// be expressed in either.
+	convertedEvents, err := db.QueryForwardingEvents(ForwardingEventQuery{
+		StartTime: startTime.Add(9 * time.Second),
+		EndTime:   startTime.Add(time.Hour),
+		AssetID:   assetA,
+	})
+	if err != nil {
+		t.Fatalf("unable to query forwarding log: %v", err)
+	}
+	if len(convertedEvents) != 1 {
+		t.Fatalf("expected 1 event, instead got %v",
+			len(convertedEvents))
+	}
+	if _, ok := convertedEvents[0].Fee(); ok {
+		t.Fatalf("fee of converted payment expressed in single asset")
+	}
+
+	// Finally, the revenue for asset B should be the sum of the fees of
+	// all odd indexed events, other than the converted payment: 1 + 3 +
+	// 5 + 7.
+	revenue, err := db.ForwardingRevenue(assetB, startTime,
+		startTime.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unable to compute revenue: %v", err)
+	}
+	if revenue != 16 {
+		t.Fatalf("expected revenue of 16, instead got %v", revenue)
+	}
+}
@@ -0,0 +1,95 @@
+package channeldb
+
+import (
+	"bytes"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+var (
+	// fundingBundleBucket is the bucket which houses the proof bundles of
+	// our funding transactions, generated as each channel opens. Each key
+	// within the bucket is the funding outpoint of a channel, and the
+	// value is its serialized bundle.
+	fundingBundleBucket = []byte("funding-bundles")
+)
+
+// PutFundingBundle stores the serialized proof bundle of the funding
+// transaction of the target channel, replacing any bundle previously stored
+// for it.
+func (d *DB) PutFundingBundle(chanPoint *wire.OutPoint, bundle []byte) error {
+	var key bytes.Buffer
+	if err := writeOutpoint(&key, chanPoint); err != nil {
+		return err
+	}
+
+	return d.store.Update(func(tx *bolt.Tx) error {
+		bundles, err := tx.CreateBucketIfNotExists(fundingBundleBucket)
+		if err != nil {
+			return err
+		}
+
+		return bundles.Put(key.Bytes(), bundle)
+	})
+}
+
+// FetchFundingBundle returns the serialized proof bundle of the funding
+// transaction of the target channel. If no bundle has been stored for the
+// channel, then ErrFundingBundleNotFound is returned.
+func (d *DB) FetchFundingBundle(chanPoint *wire.OutPoint) ([]byte, error) {
+	var key bytes.Buffer
+	if err := writeOutpoint(&key, chanPoint); err != nil {
+		return nil, err
+	}
+
+	var bundle []byte
+	err := d.store.View(func(tx *bolt.Tx) error {
+		bundles := tx.Bucket(fundingBundleBucket)
+		if bundles == nil {
+			return ErrFundingBundleNotFound
+		}
+
+		v := bundles.Get(key.Bytes())
+		if v == nil {
+			return ErrFundingBundleNotFound
+		}
+
+		// The returned slice is only valid for the lifetime of the
+		// transaction, so a copy is made.
+		bundle = make([]byte, len(v))
+		copy(bundle, v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// FetchAllFundingBundles returns the serialized proof bundles of the funding
+// transactions of all channels, ordered by their funding outpoint.
+func (d *DB) FetchAllFundingBundles() ([][]byte, error) {
+	var bundles [][]byte
+	err := d.store.View(func(tx *bolt.Tx) error {
+		bundleBucket := tx.Bucket(fundingBundleBucket)
+		if bundleBucket == nil {
+			return nil
+		}
+
+		return bundleBucket.ForEach(func(k, v []byte) error {
+			bundle := make([]byte, len(v))
+			copy(bundle, v)
+			bundles = append(bundles, bundle)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return bundles, nil
+}
@@ -0,0 +1,61 @@
+package channeldb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestFundingBundleStore(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	chanA := wire.OutPoint{Hash: wire.ShaHash{1}, Index: 0}
+	chanB := wire.OutPoint{Hash: wire.ShaHash{1}, Index: 1}
+
+	if _, err := db.FetchFundingBundle(&chanA); err != ErrFundingBundleNotFound {
+		t.Fatalf("expected ErrFundingBundleNotFound, got %v", err)
+	}
+	all, err := db.FetchAllFundingBundles()
+	if err != nil {
+		t.Fatalf("unable to fetch bundles: %v", err)
+	}
+	if len(all) != 0 {
+		t.Fatalf("expected no bundles, got %v", len(all))
+	}
+
+	bundles := [][]byte{{1, 2, 3}, {4, 5, 6, 7}}
+	if err := db.PutFundingBundle(&chanB, bundles[1]); err != nil {
+		t.Fatalf("unable to put bundle: %v", err)
+	}
+	if err := db.PutFundingBundle(&chanA, bundles[0]); err != nil {
+		t.Fatalf("unable to put bundle: %v", err)
+	}
+
+	bundle, err := db.FetchFundingBundle(&chanA)
+	if err != nil {
+		t.Fatalf("unable to fetch bundle: %v", err)
+	}
+	if !bytes.Equal(bundle, bundles[0]) {
+		t.Fatalf("expected bundle %x, got %x", bundles[0], bundle)
+	}
+
+	// All bundles are returned in order of their funding outpoint.
+	all, err = db.FetchAllFundingBundles()
+	if err != nil {
+		t.Fatalf("unable to fetch bundles: %v", err)
+	}
+	if len(all) != len(bundles) {
+		t.Fatalf("expected %v bundles, got %v", len(bundles), len(all))
+	}
+	for i := range bundles {
+		if !bytes.Equal(all[i], bundles[i]) {
+			t.Fatalf("bundle #%v: expected %x, got %x", i,
+				bundles[i], all[i])
+		}
+	}
+}
@@ -26,6 +26,7 @@ func TestInvoiceWorkflow(t *testing.T) {
 	copy(fakeInvoice.Receipt[:], []byte("recipt"))
 	copy(fakeInvoice.Terms.PaymentPreimage[:], rev[:])
 	fakeInvoice.Terms.Value = btcutil.Amount(10000)
+	fakeInvoice.Terms.AssetID = "Ua3Kt1H8mBXGN9vjEqGkjcZGz5sF3y9tYBYbb7"
 
 	// Add the invoice to the database, this should suceed as there aren't
 	// any existing invoices within the database with the same payment
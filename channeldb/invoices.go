@@ -91,6 +91,12 @@ type ContractTerm struct {
 	// satisfied by the above preimage.
 	Value btcutil.Amount
 
+	// AssetID is the identifier of the colored asset the Value is
+	// denominated in. An empty AssetID denotes an invoice payable in
+	// satoshis. An HTLC paying to this invoice must be extended over a
+	// channel of the same asset.
+	AssetID string
+
 	// Settled indicates if this particular contract term has been fully
 	// settled by the payer.
 	Settled bool
@@ -274,7 +280,7 @@ func serializeInvoice(w io.Writer, i *Invoice) error {
 		return err
 	}
 
-	return nil
+	return wire.WriteVarBytes(w, 0, []byte(i.Terms.AssetID))
 }
 
 func fetchInvoice(invoiceNum []byte, invoices *bolt.Bucket) (*Invoice, error) {
@@ -324,6 +330,17 @@ func deserializeInvoice(r io.Reader) (*Invoice, error) {
 		invoice.Terms.Settled = true
 	}
 
+	// Invoices written before asset denominated invoices were introduced
+	// end here, and are payable in satoshis.
+	assetID, err := wire.ReadVarBytes(r, 0, 1000, "assetID")
+	switch {
+	case err == io.EOF:
+		return invoice, nil
+	case err != nil:
+		return nil, err
+	}
+	invoice.Terms.AssetID = string(assetID)
+
 	return invoice, nil
 }
 
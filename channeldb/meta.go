@@ -0,0 +1,238 @@
+package channeldb
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// metaBucket stores all the meta information concerning the state of
+	// the database.
+	metaBucket = []byte("metadata")
+
+	// dbVersionKey is a boltdb key and it's used for storing/retrieving
+	// the current database version.
+	dbVersionKey = []byte("dbp")
+)
+
+// migration is a function which takes a prior outdated version of the
+// database instance and mutates the key/bucket structure to arrive at a more
+// up-to-date version of the database.
+type migration func(tx *bolt.Tx) error
+
+// version pairs a database version number with the migration required to
+// arrive at that version from the version directly preceding it.
+type version struct {
+	number    uint32
+	migration migration
+}
+
+var (
+	// dbVersions is storing all versions of database. If current version
+	// of database don't match with latest version this list will be used
+	// for retrieving all migration function that are need to apply to the
+	// current db.
+	//
+	// NOTE: Versions MUST be listed in ascending order, and new migrations
+	// should only ever be appended to the end of this slice.
+	dbVersions = []version{
+		{
+			// The base DB version requires no migration.
+			number:    0,
+			migration: nil,
+		},
+		{
+			// Version 1 records the asset ID each invoice is
+			// denominated in.
+			number:    1,
+			migration: migrateInvoiceAssetIDs,
+		},
+		{
+			// Version 2 stores the satoshi value of each channel's
+			// funding output apart from its asset capacity.
+			number:    2,
+			migration: migrateBtcCapacity,
+		},
+		{
+			// Version 3 stores the colored channel protocol
+			// features negotiated by each channel.
+			number:    3,
+			migration: migrateChanFeatures,
+		},
+		{
+			// Version 4 persists the output index, and witness
+			// script of each HTLC.
+			number:    4,
+			migration: migrateHTLCScripts,
+		},
+		{
+			// Version 5 adds the bucket storing the proof bundles
+			// of funding transactions.
+			number:    5,
+			migration: migrateFundingBundles,
+		},
+		{
+			// Version 6 records both the incoming and outgoing
+			// asset of each forwarding event.
+			number:    6,
+			migration: migrateForwardingAssets,
+		},
+	}
+
+	// latestDBVersion is the version number new databases are created
+	// with.
+	latestDBVersion = getLatestDBVersion(dbVersions)
+)
+
+// Meta structure holds the database meta information.
+type Meta struct {
+	DbVersionNumber uint32
+}
+
+// FetchMeta fetches the meta data from boltdb and returns filled meta
+// structure.
+func (d *DB) FetchMeta() (*Meta, error) {
+	meta := &Meta{}
+
+	err := d.store.View(func(tx *bolt.Tx) error {
+		return fetchMeta(meta, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// fetchMeta reads the meta data from the passed transaction into the passed
+// Meta struct. If no version has been recorded, the version is assumed to be
+// zero.
+func fetchMeta(meta *Meta, tx *bolt.Tx) error {
+	bucket := tx.Bucket(metaBucket)
+	if bucket == nil {
+		meta.DbVersionNumber = 0
+		return nil
+	}
+
+	data := bucket.Get(dbVersionKey)
+	if data == nil {
+		meta.DbVersionNumber = 0
+	} else {
+		meta.DbVersionNumber = byteOrder.Uint32(data)
+	}
+
+	return nil
+}
+
+// putMeta writes the passed meta data to the database within the passed
+// transaction.
+func putMeta(meta *Meta, tx *bolt.Tx) error {
+	bucket, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+
+	var scratch [4]byte
+	byteOrder.PutUint32(scratch[:], meta.DbVersionNumber)
+	return bucket.Put(dbVersionKey, scratch[:])
+}
+
+// syncVersions is used to safely migrate the database to the latest version
+// described by the passed set of versions. Before any migrations are applied,
+// a backup of the database is written alongside the database file. All
+// migrations are then applied within a single transaction, so a failed
+// migration leaves the database untouched.
+func (d *DB) syncVersions(versions []version, dbPath string) error {
+	meta := &Meta{}
+	err := d.store.View(func(tx *bolt.Tx) error {
+		return fetchMeta(meta, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	latestVersion := getLatestDBVersion(versions)
+	switch {
+
+	// If the database reports a higher version that we are aware of, the
+	// user is probably trying to revert to a prior version of lnd. We fail
+	// here to prevent reversions and unintended corruption.
+	case meta.DbVersionNumber > latestVersion:
+		log.Errorf("Refusing to revert from db_version=%d to "+
+			"lower version=%d", meta.DbVersionNumber,
+			latestVersion)
+		return ErrDBReversion
+
+	// If the current database version matches the latest version number,
+	// then we don't need to perform any migrations.
+	case meta.DbVersionNumber == latestVersion:
+		return nil
+	}
+
+	log.Infof("Performing database schema migration")
+
+	// Otherwise, we fetch the migrations which need to applied, and
+	// execute them serially within a single database transaction to ensure
+	// the migration is atomic.
+	migrations, migrationVersions := getMigrationsToApply(versions,
+		meta.DbVersionNumber)
+
+	// Before touching the database, write out a backup copy tagged with
+	// the current version so the prior state can be restored by hand if
+	// a migration turns out to be faulty.
+	backupPath := filepath.Join(dbPath, fmt.Sprintf("%s.v%d.bak", dbName,
+		meta.DbVersionNumber))
+	log.Infof("Backing up channeldb version %d to %v",
+		meta.DbVersionNumber, backupPath)
+	err = d.store.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(backupPath, 0600)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to backup channeldb before "+
+			"migration: %v", err)
+	}
+
+	return d.store.Update(func(tx *bolt.Tx) error {
+		for i, migration := range migrations {
+			if migration == nil {
+				continue
+			}
+
+			log.Infof("Applying migration #%v", migrationVersions[i])
+
+			if err := migration(tx); err != nil {
+				log.Infof("Unable to apply migration #%v",
+					migrationVersions[i])
+				return err
+			}
+		}
+
+		meta.DbVersionNumber = latestVersion
+		return putMeta(meta, tx)
+	})
+}
+
+// getLatestDBVersion returns the version number of the latest version within
+// the passed set of versions.
+func getLatestDBVersion(versions []version) uint32 {
+	return versions[len(versions)-1].number
+}
+
+// getMigrationsToApply retrieves the migration function that should be
+// applied to the database.
+func getMigrationsToApply(versions []version,
+	currentVersion uint32) ([]migration, []uint32) {
+	migrations := make([]migration, 0, len(versions))
+	migrationVersions := make([]uint32, 0, len(versions))
+
+	for _, v := range versions {
+		if v.number > currentVersion {
+			migrations = append(migrations, v.migration)
+			migrationVersions = append(migrationVersions, v.number)
+		}
+	}
+
+	return migrations, migrationVersions
+}
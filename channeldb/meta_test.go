@@ -0,0 +1,135 @@
+package channeldb
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+)
+
+// TestVersionFetchPut checks the propernces of fetch/put methods
+// and also initialization of meta data in case if don't have any in
+// database.
+func TestVersionFetchPut(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	meta, err := db.FetchMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.DbVersionNumber != latestDBVersion {
+		t.Fatal("initialization of meta information wasn't performed")
+	}
+
+	newVersion := latestDBVersion + 1
+	meta.DbVersionNumber = newVersion
+
+	err = db.store.Update(func(tx *bolt.Tx) error {
+		return putMeta(meta, tx)
+	})
+	if err != nil {
+		t.Fatalf("update of meta failed %v", err)
+	}
+
+	meta, err = db.FetchMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if meta.DbVersionNumber != newVersion {
+		t.Fatal("update of meta information wasn't performed")
+	}
+
+	// The database is now at a version we don't know of, so attempting to
+	// sync should fail rather than revert the database.
+	if err := db.syncVersions(dbVersions, ""); err != ErrDBReversion {
+		t.Fatalf("expected ErrDBReversion, got %v", err)
+	}
+}
+
+// TestMigrationWithBackup tests that pending migrations are applied in order,
+// that the database version is bumped, and that a backup of the database is
+// written before the migrations are applied.
+func TestMigrationWithBackup(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	// Freshly created databases start out at the latest version, so the
+	// database is reverted to the base version first.
+	err = db.store.Update(func(tx *bolt.Tx) error {
+		return putMeta(&Meta{DbVersionNumber: 0}, tx)
+	})
+	if err != nil {
+		t.Fatalf("unable to revert db version: %v", err)
+	}
+
+	var applied []uint32
+	versions := []version{
+		{number: 0},
+		{
+			number: 1,
+			migration: func(tx *bolt.Tx) error {
+				applied = append(applied, 1)
+				_, err := tx.CreateBucketIfNotExists([]byte("migrated"))
+				return err
+			},
+		},
+		{
+			number: 2,
+			migration: func(tx *bolt.Tx) error {
+				applied = append(applied, 2)
+				return nil
+			},
+		},
+	}
+
+	dbPath := filepath.Dir(db.store.Path())
+	if err := db.syncVersions(versions, dbPath); err != nil {
+		t.Fatalf("unable to sync versions: %v", err)
+	}
+
+	if len(applied) != 2 || applied[0] != 1 || applied[1] != 2 {
+		t.Fatalf("migrations applied incorrectly: %v", applied)
+	}
+
+	meta, err := db.FetchMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.DbVersionNumber != 2 {
+		t.Fatalf("db version should be 2, instead is %v",
+			meta.DbVersionNumber)
+	}
+
+	err = db.store.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte("migrated")) == nil {
+			t.Fatalf("migration wasn't applied")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backupPath := filepath.Join(dbPath, dbName+".v0.bak")
+	if !fileExists(backupPath) {
+		t.Fatalf("backup wasn't created before migrating")
+	}
+
+	// Syncing once again should be a no-op.
+	applied = nil
+	if err := db.syncVersions(versions, dbPath); err != nil {
+		t.Fatalf("unable to sync versions: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Fatalf("migrations re-applied: %v", applied)
+	}
+}
@@ -0,0 +1,317 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// migrateInvoiceAssetIDs migrates the database from version 0 to version 1,
+// in which each serialized invoice ends with the asset ID its value is
+// denominated in. Invoices written prior lack it, and are payable in
+// satoshis, so they're rewritten with an empty asset ID.
+func migrateInvoiceAssetIDs(tx *bolt.Tx) error {
+	invoices := tx.Bucket(invoiceBucket)
+	if invoices == nil {
+		return nil
+	}
+
+	migrated := make(map[string][]byte)
+	err := invoices.ForEach(func(k, v []byte) error {
+		// Skip over the payment hash index, along with the invoice
+		// counter.
+		if v == nil || bytes.Equal(k, numInvoicesKey) {
+			return nil
+		}
+
+		invoice, err := deserializeInvoice(bytes.NewReader(v))
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializeInvoice(&b, invoice); err != nil {
+			return err
+		}
+		migrated[string(k)] = b.Bytes()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range migrated {
+		if err := invoices.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateBtcCapacity migrates the database from version 1 to version 2, in
+// which the satoshi value of each channel's funding output is stored apart
+// from its asset capacity. Channels created prior used the asset capacity as
+// the value of their funding output, so it's recorded as such.
+func migrateBtcCapacity(tx *bolt.Tx) error {
+	return migrateChanKeys(tx, btcCapacityPrefix,
+		func(openChanBucket *bolt.Bucket, chanID []byte) []byte {
+			key := append(append([]byte(nil), chanCapacityPrefix...),
+				chanID...)
+			return openChanBucket.Get(key)
+		},
+	)
+}
+
+// migrateChanFeatures migrates the database from version 2 to version 3, in
+// which the colored channel protocol features negotiated by each channel
+// are stored. Channels created prior use the original protocol, denoted by
+// the zero features.
+func migrateChanFeatures(tx *bolt.Tx) error {
+	return migrateChanKeys(tx, featuresPrefix,
+		func(*bolt.Bucket, []byte) []byte {
+			return []byte{0, 0, 0}
+		},
+	)
+}
+
+// migrateChanKeys writes the value returned by the passed function under the
+// given key prefix for each open channel lacking an entry.
+func migrateChanKeys(tx *bolt.Tx, prefix []byte,
+	value func(*bolt.Bucket, []byte) []byte) error {
+
+	openChanBucket := tx.Bucket(openChannelBucket)
+	if openChanBucket == nil {
+		return nil
+	}
+
+	// Each open channel has an entry under the capacity prefix, keyed by
+	// its channel point. As keys can't be added while iterating, the
+	// missing entries are gathered first.
+	migrated := make(map[string][]byte)
+	c := openChanBucket.Cursor()
+	for k, _ := c.Seek(chanCapacityPrefix); k != nil &&
+		bytes.HasPrefix(k, chanCapacityPrefix); k, _ = c.Next() {
+
+		chanID := k[len(chanCapacityPrefix):]
+		key := append(append([]byte(nil), prefix...), chanID...)
+		if openChanBucket.Get(key) != nil {
+			continue
+		}
+
+		v := value(openChanBucket, chanID)
+		if v == nil {
+			continue
+		}
+		migrated[string(key)] = append([]byte(nil), v...)
+	}
+
+	for k, v := range migrated {
+		if err := openChanBucket.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateHTLCScripts migrates the database from version 3 to version 4, in
+// which each serialized HTLC is followed by the output index, and witness
+// script of its output. Both the current set of HTLCs of each channel, and
+// the HTLCs within each channel delta of the revocation log are rewritten.
+// As the script metadata of existing HTLCs isn't known, their output index is
+// recorded as unknown, without a witness script.
+func migrateHTLCScripts(tx *bolt.Tx) error {
+	openChanBucket := tx.Bucket(openChannelBucket)
+	if openChanBucket == nil {
+		return nil
+	}
+
+	// Gather the set of node buckets up front, as the bucket can't be
+	// modified while we're iterating over it.
+	var nodeIDs [][]byte
+	err := openChanBucket.ForEach(func(k, v []byte) error {
+		// Only nested buckets have a nil value.
+		if v == nil {
+			nodeIDs = append(nodeIDs, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, nodeID := range nodeIDs {
+		nodeChanBucket := openChanBucket.Bucket(nodeID)
+
+		if err := migrateCurrentHtlcs(nodeChanBucket); err != nil {
+			return err
+		}
+
+		logBucket := nodeChanBucket.Bucket(channelLogBucket)
+		if logBucket == nil {
+			continue
+		}
+		if err := migrateChannelLog(logBucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateCurrentHtlcs rewrites the current set of HTLCs of each channel
+// within the passed node's channel bucket in the version 4 format.
+func migrateCurrentHtlcs(nodeChanBucket *bolt.Bucket) error {
+	// As keys can't be modified while iterating, each set of HTLCs is
+	// re-serialized first, and written once the iteration completes.
+	migrated := make(map[string][]byte)
+	c := nodeChanBucket.Cursor()
+	for k, v := c.Seek(currentHtlcKey); k != nil &&
+		bytes.HasPrefix(k, currentHtlcKey); k, v = c.Next() {
+
+		htlcs, err := deserializeHTLCs(v, deserializeHTLCv0)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		for _, htlc := range htlcs {
+			if err := serializeHTLC(&b, htlc); err != nil {
+				return err
+			}
+		}
+		migrated[string(k)] = b.Bytes()
+	}
+
+	for k, v := range migrated {
+		if err := nodeChanBucket.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateChannelLog rewrites each channel delta within the passed revocation
+// log in the version 4 format.
+func migrateChannelLog(logBucket *bolt.Bucket) error {
+	migrated := make(map[string][]byte)
+	err := logBucket.ForEach(func(k, v []byte) error {
+		delta, err := deserializeChannelDeltaWith(bytes.NewReader(v),
+			deserializeHTLCv0)
+		if err != nil {
+			return err
+		}
+
+		var b bytes.Buffer
+		if err := serializeChannelDelta(&b, delta); err != nil {
+			return err
+		}
+		migrated[string(k)] = b.Bytes()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range migrated {
+		if err := logBucket.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateFundingBundles migrates the database from version 4 to version 5,
+// in which the proof bundles of each channel's funding transaction are
+// stored within a bucket of their own. Channels funded prior lack a bundle,
+// so only the bucket is created.
+func migrateFundingBundles(tx *bolt.Tx) error {
+	_, err := tx.CreateBucketIfNotExists(fundingBundleBucket)
+	return err
+}
+
+// migrateForwardingAssets migrates the database from version 5 to version 6,
+// in which each forwarding event records the asset of both the incoming and
+// the outgoing HTLC. Events written prior record a single asset, shared by
+// both HTLCs, so it's recorded as the outgoing asset as well.
+func migrateForwardingAssets(tx *bolt.Tx) error {
+	logBucket := tx.Bucket(forwardingLogBucket)
+	if logBucket == nil {
+		return nil
+	}
+
+	migrated := make(map[string][]byte)
+	err := logBucket.ForEach(func(k, v []byte) error {
+		event, err := deserializeForwardingEventv0(v)
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			return nil
+		}
+
+		var b bytes.Buffer
+		if err := serializeForwardingEvent(&b, event); err != nil {
+			return err
+		}
+		migrated[string(k)] = b.Bytes()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range migrated {
+		if err := logBucket.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deserializeForwardingEventv0 deserializes a forwarding event written prior
+// to database version 6, laid out as: incoming chan point || outgoing chan
+// point || asset ID || amt in (8) || amt out (8). If the event has already
+// been written in the current format, nil is returned.
+func deserializeForwardingEventv0(v []byte) (*ForwardingEvent, error) {
+	r := bytes.NewReader(v)
+
+	event := &ForwardingEvent{}
+	if err := readOutpoint(r, &event.IncomingChanID); err != nil {
+		return nil, err
+	}
+	if err := readOutpoint(r, &event.OutgoingChanID); err != nil {
+		return nil, err
+	}
+	assetID, err := wire.ReadVarBytes(r, 0, 1000, "assetID")
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the two amounts follow the asset ID of a legacy event, while
+	// an event in the current format carries the outgoing asset ID first.
+	if r.Len() != 16 {
+		return nil, nil
+	}
+	event.IncomingAssetID = string(assetID)
+	event.OutgoingAssetID = string(assetID)
+
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	event.AmtIn = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	event.AmtOut = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	return event, nil
+}
@@ -0,0 +1,374 @@
+package channeldb
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// revertDB applies the passed modifications, and reverts the database to the
+// version preceding the passed version. The database is then migrated to the
+// passed version, applying only the migration under test.
+func revertDB(t *testing.T, db *DB, version uint32, f func(tx *bolt.Tx) error) {
+	err := db.store.Update(func(tx *bolt.Tx) error {
+		if err := f(tx); err != nil {
+			return err
+		}
+		return putMeta(&Meta{DbVersionNumber: version - 1}, tx)
+	})
+	if err != nil {
+		t.Fatalf("unable to write legacy state: %v", err)
+	}
+
+	dbPath := filepath.Dir(db.store.Path())
+	if err := db.syncVersions(dbVersions[:version+1], dbPath); err != nil {
+		t.Fatalf("unable to migrate database: %v", err)
+	}
+}
+
+// TestMigrateInvoiceAssetIDs tests that invoices serialized prior to
+// database version 1 are rewritten with an empty asset ID.
+func TestMigrateInvoiceAssetIDs(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	invoice := &Invoice{CreationDate: time.Unix(1000, 0)}
+	copy(invoice.Memo[:], []byte("memo"))
+	invoice.Terms.PaymentPreimage = [32]byte{0x01}
+	invoice.Terms.Value = 10000
+	if err := db.AddInvoice(invoice); err != nil {
+		t.Fatalf("unable to add invoice: %v", err)
+	}
+
+	// A legacy invoice lacks the trailing asset ID, which is a single
+	// byte for an empty asset ID.
+	var invoiceKey, expected []byte
+	revertDB(t, db, 1, func(tx *bolt.Tx) error {
+		invoices := tx.Bucket(invoiceBucket)
+		err := invoices.ForEach(func(k, v []byte) error {
+			if v != nil && !bytes.Equal(k, numInvoicesKey) {
+				invoiceKey = append([]byte(nil), k...)
+				expected = append([]byte(nil), v...)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return invoices.Put(invoiceKey, expected[:len(expected)-1])
+	})
+
+	err = db.store.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(invoiceBucket).Get(invoiceKey)
+		if !bytes.Equal(v, expected) {
+			t.Fatalf("invoice migrated incorrectly: expected %x, "+
+				"got %x", expected, v)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to read migrated state: %v", err)
+	}
+}
+
+// TestMigrateBtcCapacity tests that channels written prior to database
+// version 2 have their funding output's value set to their asset capacity
+// once migrated.
+func TestMigrateBtcCapacity(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	state, err := createTestChannelState(db)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	state.BtcCapacity = state.AssetCapacity + 1000
+	if err := state.FullSync(); err != nil {
+		t.Fatalf("unable to save channel state: %v", err)
+	}
+
+	revertDB(t, db, 2, func(tx *bolt.Tx) error {
+		var b bytes.Buffer
+		if err := writeOutpoint(&b, state.ChanID); err != nil {
+			return err
+		}
+
+		key := append(append([]byte(nil), btcCapacityPrefix...),
+			b.Bytes()...)
+		return tx.Bucket(openChannelBucket).Delete(key)
+	})
+
+	nodeID := wire.ShaHash(state.TheirLNID)
+	channels, err := db.FetchOpenChannels(&nodeID)
+	if err != nil {
+		t.Fatalf("unable to fetch channels: %v", err)
+	}
+	if len(channels) != 1 {
+		t.Fatalf("expected 1 channel, got %v", len(channels))
+	}
+	if channels[0].BtcCapacity != state.AssetCapacity {
+		t.Fatalf("expected btc capacity %v, got %v",
+			state.AssetCapacity, channels[0].BtcCapacity)
+	}
+}
+
+// TestMigrateChanFeatures tests that channels written prior to database
+// version 3 use the original colored channel protocol once migrated.
+func TestMigrateChanFeatures(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	state, err := createTestChannelState(db)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	state.Features = ChannelFeatures{
+		InstructionVersion: 1,
+		SecondLevelHTLCs:   true,
+	}
+	if err := state.FullSync(); err != nil {
+		t.Fatalf("unable to save channel state: %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, state.ChanID); err != nil {
+		t.Fatalf("unable to write outpoint: %v", err)
+	}
+	key := append(append([]byte(nil), featuresPrefix...), b.Bytes()...)
+	revertDB(t, db, 3, func(tx *bolt.Tx) error {
+		return tx.Bucket(openChannelBucket).Delete(key)
+	})
+
+	// The features of the channel are now recorded explicitly.
+	err = db.store.View(func(tx *bolt.Tx) error {
+		features := tx.Bucket(openChannelBucket).Get(key)
+		if !bytes.Equal(features, []byte{0, 0, 0}) {
+			t.Fatalf("expected original features recorded, got %x",
+				features)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to read migrated state: %v", err)
+	}
+
+	nodeID := wire.ShaHash(state.TheirLNID)
+	channels, err := db.FetchOpenChannels(&nodeID)
+	if err != nil {
+		t.Fatalf("unable to fetch channels: %v", err)
+	}
+	if len(channels) != 1 {
+		t.Fatalf("expected 1 channel, got %v", len(channels))
+	}
+	if channels[0].Features != (ChannelFeatures{}) {
+		t.Fatalf("expected original features, got %+v",
+			channels[0].Features)
+	}
+}
+
+// TestMigrateHTLCScripts tests that HTLCs serialized prior to database
+// version 4, both within the current set of HTLCs of a channel, and within
+// the revocation log, are readable once migrated, with an unknown output
+// index.
+func TestMigrateHTLCScripts(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	nodeID := []byte{0x01}
+	chanPoint := &wire.OutPoint{Hash: wire.ShaHash{0x02}, Index: 1}
+	legacyHTLC := &HTLC{
+		Incoming:        true,
+		Amt:             1000,
+		RHash:           [32]byte{0x03},
+		RefundTimeout:   100,
+		RevocationDelay: 4,
+	}
+
+	// Write a legacy set of current HTLCs, and a legacy channel delta
+	// carrying the same HTLC.
+	revertDB(t, db, 4, func(tx *bolt.Tx) error {
+		nodeChanBucket, err := tx.Bucket(openChannelBucket).
+			CreateBucketIfNotExists(nodeID)
+		if err != nil {
+			return err
+		}
+
+		var htlcBytes bytes.Buffer
+		for i := 0; i < 2; i++ {
+			if err := serializeHTLCv0(&htlcBytes, legacyHTLC); err != nil {
+				return err
+			}
+		}
+		htlcKey := makeHtlcKey(chanPoint)
+		if err := nodeChanBucket.Put(htlcKey[:], htlcBytes.Bytes()); err != nil {
+			return err
+		}
+
+		// A legacy delta is laid out as: local balance (8) || remote
+		// balance (8) || update num (4) || num htlcs || htlcs.
+		var deltaBytes bytes.Buffer
+		deltaBytes.Write(make([]byte, 8+8))
+		deltaBytes.Write([]byte{0, 0, 0, 7})
+		wire.WriteVarInt(&deltaBytes, 0, 1)
+		if err := serializeHTLCv0(&deltaBytes, legacyHTLC); err != nil {
+			return err
+		}
+		logBucket, err := nodeChanBucket.CreateBucketIfNotExists(
+			channelLogBucket)
+		if err != nil {
+			return err
+		}
+		logKey := makeLogKey(chanPoint, 7)
+		if err := logBucket.Put(logKey[:], deltaBytes.Bytes()); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	expectedHTLC := *legacyHTLC
+	expectedHTLC.OutputIndex = -1
+	checkHTLC := func(htlc *HTLC) {
+		if !reflect.DeepEqual(*htlc, expectedHTLC) {
+			t.Fatalf("expected htlc %+v, got %+v", expectedHTLC,
+				*htlc)
+		}
+	}
+
+	err = db.store.View(func(tx *bolt.Tx) error {
+		nodeChanBucket := tx.Bucket(openChannelBucket).Bucket(nodeID)
+
+		htlcs, err := fetchCurrentHtlcs(nodeChanBucket, chanPoint)
+		if err != nil {
+			return err
+		}
+		if len(htlcs) != 2 {
+			t.Fatalf("expected 2 htlcs, got %v", len(htlcs))
+		}
+		for _, htlc := range htlcs {
+			checkHTLC(htlc)
+		}
+
+		logBucket := nodeChanBucket.Bucket(channelLogBucket)
+		delta, err := fetchChannelLogEntry(logBucket, chanPoint, 7)
+		if err != nil {
+			return err
+		}
+		if delta.UpdateNum != 7 || len(delta.Htlcs) != 1 {
+			t.Fatalf("delta migrated incorrectly: %+v", delta)
+		}
+		checkHTLC(delta.Htlcs[0])
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to read migrated state: %v", err)
+	}
+}
+
+// TestMigrateFundingBundles tests that databases created prior to version 5
+// gain the bucket storing funding proof bundles once migrated.
+func TestMigrateFundingBundles(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	revertDB(t, db, 5, func(tx *bolt.Tx) error {
+		return tx.DeleteBucket(fundingBundleBucket)
+	})
+
+	err = db.store.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(fundingBundleBucket) == nil {
+			t.Fatalf("funding bundle bucket not created")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to read migrated state: %v", err)
+	}
+}
+
+// TestMigrateForwardingAssets tests that forwarding events written prior to
+// database version 6 record their single asset as both the incoming and
+// outgoing asset once migrated, while events already in the current format
+// are left as is.
+func TestMigrateForwardingAssets(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUp()
+
+	legacyEvent := ForwardingEvent{
+		Timestamp:       time.Unix(1000, 0),
+		IncomingChanID:  wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 1},
+		OutgoingChanID:  wire.OutPoint{Hash: wire.ShaHash{0x02}, Index: 2},
+		IncomingAssetID: "asset",
+		OutgoingAssetID: "asset",
+		AmtIn:           1010,
+		AmtOut:          1000,
+	}
+	convertedEvent := legacyEvent
+	convertedEvent.Timestamp = time.Unix(2000, 0)
+	convertedEvent.OutgoingAssetID = ""
+	err = db.AddForwardingEvents([]ForwardingEvent{
+		legacyEvent, convertedEvent,
+	})
+	if err != nil {
+		t.Fatalf("unable to add forwarding events: %v", err)
+	}
+
+	// A legacy event is laid out as: incoming chan point || outgoing
+	// chan point || asset ID || amt in (8) || amt out (8).
+	revertDB(t, db, 6, func(tx *bolt.Tx) error {
+		var b bytes.Buffer
+		writeOutpoint(&b, &legacyEvent.IncomingChanID)
+		writeOutpoint(&b, &legacyEvent.OutgoingChanID)
+		wire.WriteVarBytes(&b, 0, []byte(legacyEvent.IncomingAssetID))
+		var scratch [8]byte
+		byteOrder.PutUint64(scratch[:], uint64(legacyEvent.AmtIn))
+		b.Write(scratch[:])
+		byteOrder.PutUint64(scratch[:], uint64(legacyEvent.AmtOut))
+		b.Write(scratch[:])
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], uint64(legacyEvent.Timestamp.UnixNano()))
+		return tx.Bucket(forwardingLogBucket).Put(key[:], b.Bytes())
+	})
+
+	events, err := db.QueryForwardingEvents(ForwardingEventQuery{
+		StartTime: time.Unix(0, 0),
+		EndTime:   time.Unix(3000, 0),
+	})
+	if err != nil {
+		t.Fatalf("unable to query forwarding events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", len(events))
+	}
+	for i, expected := range []ForwardingEvent{legacyEvent, convertedEvent} {
+		if !reflect.DeepEqual(events[i], expected) {
+			t.Fatalf("expected event %+v, got %+v", expected,
+				events[i])
+		}
+	}
+}
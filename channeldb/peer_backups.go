@@ -0,0 +1,57 @@
+package channeldb
+
+import (
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/wire"
+)
+
+var (
+	// peerBackupBucket is the bucket which houses the opaque backups of
+	// channel state our peers have sent us for safekeeping. Each key
+	// within the bucket is the identity of a peer, and the value is the
+	// latest backup received from them.
+	peerBackupBucket = []byte("peer-backups")
+)
+
+// PutPeerBackup stores the passed backup on behalf of the target peer,
+// replacing any backup previously stored for them.
+func (d *DB) PutPeerBackup(nodeID *wire.ShaHash, backup []byte) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		backups, err := tx.CreateBucketIfNotExists(peerBackupBucket)
+		if err != nil {
+			return err
+		}
+
+		return backups.Put(nodeID[:], backup)
+	})
+}
+
+// FetchPeerBackup returns the latest backup stored on behalf of the target
+// peer. If no backup has been stored for the peer, then
+// ErrPeerBackupNotFound is returned.
+func (d *DB) FetchPeerBackup(nodeID *wire.ShaHash) ([]byte, error) {
+	var backup []byte
+	err := d.store.View(func(tx *bolt.Tx) error {
+		backups := tx.Bucket(peerBackupBucket)
+		if backups == nil {
+			return ErrPeerBackupNotFound
+		}
+
+		v := backups.Get(nodeID[:])
+		if v == nil {
+			return ErrPeerBackupNotFound
+		}
+
+		// The returned slice is only valid for the lifetime of the
+		// transaction, so a copy is made.
+		backup = make([]byte, len(v))
+		copy(backup, v)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup, nil
+}
@@ -0,0 +1,52 @@
+package channeldb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestPeerBackupStore(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	var alice, bob wire.ShaHash
+	copy(alice[:], bytes.Repeat([]byte{1}, 32))
+	copy(bob[:], bytes.Repeat([]byte{2}, 32))
+
+	if _, err := db.FetchPeerBackup(&alice); err != ErrPeerBackupNotFound {
+		t.Fatalf("expected ErrPeerBackupNotFound, got %v", err)
+	}
+
+	// A later backup from the same peer replaces the earlier one, while
+	// the backups of other peers are left untouched.
+	backups := [][]byte{{1, 2, 3}, {4, 5, 6, 7}, {8}}
+	if err := db.PutPeerBackup(&alice, backups[0]); err != nil {
+		t.Fatalf("unable to put backup: %v", err)
+	}
+	if err := db.PutPeerBackup(&bob, backups[2]); err != nil {
+		t.Fatalf("unable to put backup: %v", err)
+	}
+	if err := db.PutPeerBackup(&alice, backups[1]); err != nil {
+		t.Fatalf("unable to put backup: %v", err)
+	}
+
+	backup, err := db.FetchPeerBackup(&alice)
+	if err != nil {
+		t.Fatalf("unable to fetch backup: %v", err)
+	}
+	if !bytes.Equal(backup, backups[1]) {
+		t.Fatalf("expected backup %x, got %x", backups[1], backup)
+	}
+	backup, err = db.FetchPeerBackup(&bob)
+	if err != nil {
+		t.Fatalf("unable to fetch backup: %v", err)
+	}
+	if !bytes.Equal(backup, backups[2]) {
+		t.Fatalf("expected backup %x, got %x", backups[2], backup)
+	}
+}
@@ -0,0 +1,113 @@
+package channeldb
+
+import (
+	"crypto/sha256"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// preimageBucket is the bucket which houses all the preimages learned
+	// by settling HTLCs, across all channels. Each key within the bucket
+	// is a payment hash, and the value is the preimage, followed by the
+	// absolute expiry height of the HTLC it settled.
+	preimageBucket = []byte("preimages")
+)
+
+// AddPreimage records the passed preimage within the preimage store, along
+// with the expiry height of the HTLC it settled. The preimage must be
+// retained until at least the expiry height, as it may be required to claim
+// an incoming HTLC output on-chain after a force close. If the preimage is
+// already known, then the later of the two expiry heights is kept.
+func (d *DB) AddPreimage(preimage [32]byte, expiry uint32) error {
+	paymentHash := sha256.Sum256(preimage[:])
+
+	return d.store.Update(func(tx *bolt.Tx) error {
+		preimages, err := tx.CreateBucketIfNotExists(preimageBucket)
+		if err != nil {
+			return err
+		}
+
+		if v := preimages.Get(paymentHash[:]); v != nil {
+			if byteOrder.Uint32(v[32:]) >= expiry {
+				return nil
+			}
+		}
+
+		var entry [36]byte
+		copy(entry[:32], preimage[:])
+		byteOrder.PutUint32(entry[32:], expiry)
+
+		return preimages.Put(paymentHash[:], entry[:])
+	})
+}
+
+// LookupPreimage returns the preimage of the passed payment hash, along with
+// the expiry height it's retained until. If the preimage isn't known, then
+// ErrPreimageNotFound is returned.
+func (d *DB) LookupPreimage(paymentHash [32]byte) ([32]byte, uint32, error) {
+	var (
+		preimage [32]byte
+		expiry   uint32
+	)
+	err := d.store.View(func(tx *bolt.Tx) error {
+		preimages := tx.Bucket(preimageBucket)
+		if preimages == nil {
+			return ErrPreimageNotFound
+		}
+
+		v := preimages.Get(paymentHash[:])
+		if v == nil {
+			return ErrPreimageNotFound
+		}
+
+		copy(preimage[:], v[:32])
+		expiry = byteOrder.Uint32(v[32:])
+
+		return nil
+	})
+	if err != nil {
+		return preimage, 0, err
+	}
+
+	return preimage, expiry, nil
+}
+
+// PrunePreimages removes all preimages whose expiry height is below the passed
+// height, returning the number of preimages removed.
+func (d *DB) PrunePreimages(height uint32) (uint32, error) {
+	var numPruned uint32
+	err := d.store.Update(func(tx *bolt.Tx) error {
+		preimages := tx.Bucket(preimageBucket)
+		if preimages == nil {
+			return nil
+		}
+
+		// Keys can't be deleted while iterating with ForEach, so we
+		// first gather all the expired payment hashes.
+		var expired [][]byte
+		err := preimages.ForEach(func(k, v []byte) error {
+			if byteOrder.Uint32(v[32:]) < height {
+				expired = append(expired, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := preimages.Delete(k); err != nil {
+				return err
+			}
+		}
+		numPruned = uint32(len(expired))
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return numPruned, nil
+}
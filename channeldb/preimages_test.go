@@ -0,0 +1,66 @@
+package channeldb
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestPreimageStore(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	var preimage1, preimage2 [32]byte
+	copy(preimage1[:], bytes.Repeat([]byte{1}, 32))
+	copy(preimage2[:], bytes.Repeat([]byte{2}, 32))
+	hash1 := sha256.Sum256(preimage1[:])
+	hash2 := sha256.Sum256(preimage2[:])
+
+	if _, _, err := db.LookupPreimage(hash1); err != ErrPreimageNotFound {
+		t.Fatalf("expected ErrPreimageNotFound, got %v", err)
+	}
+
+	if err := db.AddPreimage(preimage1, 100); err != nil {
+		t.Fatalf("unable to add preimage: %v", err)
+	}
+	if err := db.AddPreimage(preimage2, 200); err != nil {
+		t.Fatalf("unable to add preimage: %v", err)
+	}
+
+	// Re-adding a known preimage with an earlier expiry shouldn't shorten
+	// its retention, while a later expiry should extend it.
+	if err := db.AddPreimage(preimage1, 50); err != nil {
+		t.Fatalf("unable to add preimage: %v", err)
+	}
+	if err := db.AddPreimage(preimage1, 150); err != nil {
+		t.Fatalf("unable to add preimage: %v", err)
+	}
+
+	preimage, expiry, err := db.LookupPreimage(hash1)
+	if err != nil {
+		t.Fatalf("unable to lookup preimage: %v", err)
+	}
+	if preimage != preimage1 || expiry != 150 {
+		t.Fatalf("expected preimage %x with expiry 150, got %x with "+
+			"expiry %v", preimage1, preimage, expiry)
+	}
+
+	// Pruning at a height between the two expiries should only remove the
+	// first preimage.
+	numPruned, err := db.PrunePreimages(151)
+	if err != nil {
+		t.Fatalf("unable to prune preimages: %v", err)
+	}
+	if numPruned != 1 {
+		t.Fatalf("expected 1 preimage pruned, got %v", numPruned)
+	}
+	if _, _, err := db.LookupPreimage(hash1); err != ErrPreimageNotFound {
+		t.Fatalf("expected ErrPreimageNotFound, got %v", err)
+	}
+	if _, _, err := db.LookupPreimage(hash2); err != nil {
+		t.Fatalf("unable to lookup preimage: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package channeldb
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// maxReplicatedCommitSize is the largest serialized commitment transaction
+// accepted within a replicated delta.
+const maxReplicatedCommitSize = 1 << 20
+
+// DeltaKind denotes which commitment chain of a channel a replicated delta
+// advances.
+type DeltaKind uint8
+
+const (
+	// LocalDelta is the delta of our new broadcastable commitment, as
+	// recorded by UpdateCommitment once we've revoked our prior state.
+	LocalDelta DeltaKind = iota
+
+	// RemoteDelta is the delta of a remote commitment appended to the
+	// revocation log, once the remote party has revoked it.
+	RemoteDelta
+)
+
+// String returns a human readable name of the delta kind.
+func (k DeltaKind) String() string {
+	switch k {
+	case LocalDelta:
+		return "local"
+	case RemoteDelta:
+		return "remote"
+	default:
+		return fmt.Sprintf("DeltaKind(%d)", uint8(k))
+	}
+}
+
+// ReplicatedDelta is a channel delta which has been durably committed to the
+// database, as handed to the database's DeltaObserver.
+type ReplicatedDelta struct {
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// Kind denotes which commitment chain the delta advances.
+	Kind DeltaKind
+
+	// Delta is the state transition itself.
+	Delta *ChannelDelta
+
+	// CommitTx and CommitSig are our new broadcastable commitment, along
+	// with the remote party's signature for it. They're only set for
+	// local deltas.
+	CommitTx  *wire.MsgTx
+	CommitSig []byte
+}
+
+// Encode serializes the replicated delta to the passed io.Writer.
+func (r *ReplicatedDelta) Encode(w io.Writer) error {
+	if err := writeOutpoint(w, &r.ChanPoint); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(r.Kind)}); err != nil {
+		return err
+	}
+	if err := serializeChannelDelta(w, r.Delta); err != nil {
+		return err
+	}
+
+	var commitTx bytes.Buffer
+	if r.CommitTx != nil {
+		if err := r.CommitTx.Serialize(&commitTx); err != nil {
+			return err
+		}
+	}
+	if err := wire.WriteVarBytes(w, 0, commitTx.Bytes()); err != nil {
+		return err
+	}
+
+	return wire.WriteVarBytes(w, 0, r.CommitSig)
+}
+
+// Decode deserializes a replicated delta from the passed io.Reader.
+func (r *ReplicatedDelta) Decode(rd io.Reader) error {
+	if err := readOutpoint(rd, &r.ChanPoint); err != nil {
+		return err
+	}
+
+	var kind [1]byte
+	if _, err := io.ReadFull(rd, kind[:]); err != nil {
+		return err
+	}
+	r.Kind = DeltaKind(kind[0])
+	if r.Kind != LocalDelta && r.Kind != RemoteDelta {
+		return fmt.Errorf("unknown delta kind %v", r.Kind)
+	}
+
+	delta, err := deserializeChannelDelta(rd)
+	if err != nil {
+		return err
+	}
+	r.Delta = delta
+
+	commitTx, err := wire.ReadVarBytes(rd, 0, maxReplicatedCommitSize,
+		"commitTx")
+	if err != nil {
+		return err
+	}
+	r.CommitTx = nil
+	if len(commitTx) != 0 {
+		r.CommitTx = wire.NewMsgTx()
+		if err := r.CommitTx.Deserialize(bytes.NewReader(commitTx)); err != nil {
+			return err
+		}
+	}
+
+	r.CommitSig, err = wire.ReadVarBytes(rd, 0, 80, "commitSig")
+	if err != nil {
+		return err
+	}
+	if len(r.CommitSig) == 0 {
+		r.CommitSig = nil
+	}
+
+	return nil
+}
+
+// DeltaObserver is notified of each channel delta once the state transition
+// recording it has been committed to the database. It's called synchronously
+// from the goroutine carrying out the state transition, so it must not block.
+type DeltaObserver func(*ReplicatedDelta)
+
+// SetDeltaObserver registers the passed observer, replacing any observer
+// previously registered. A nil observer unregisters the current one.
+func (d *DB) SetDeltaObserver(observer DeltaObserver) {
+	d.observerMtx.Lock()
+	d.deltaObserver = observer
+	d.observerMtx.Unlock()
+}
+
+// notifyDelta hands the passed committed delta to the registered observer,
+// if any.
+func (d *DB) notifyDelta(delta *ReplicatedDelta) {
+	d.observerMtx.RLock()
+	observer := d.deltaObserver
+	d.observerMtx.RUnlock()
+
+	if observer != nil {
+		observer(delta)
+	}
+}
@@ -0,0 +1,115 @@
+package channeldb
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestDeltaObserver tests that the registered DeltaObserver is handed each
+// committed local and remote delta, and that the replicated deltas survive a
+// round trip through their serialization.
+func TestDeltaObserver(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	var observed []*ReplicatedDelta
+	cdb.SetDeltaObserver(func(delta *ReplicatedDelta) {
+		observed = append(observed, delta)
+	})
+
+	localDelta := &ChannelDelta{
+		LocalBalance:  btcutil.Amount(2e8),
+		RemoteBalance: btcutil.Amount(1e8),
+		Htlcs: []*HTLC{{
+			Amt:           5000,
+			RHash:         key,
+			RefundTimeout: 10,
+			OutputIndex:   -1,
+		}},
+		UpdateNum: 1,
+	}
+	newSig := bytes.Repeat([]byte{3}, 71)
+	newTx := channel.OurCommitTx.Copy()
+	if err := channel.UpdateCommitment(newTx, newSig, localDelta); err != nil {
+		t.Fatalf("unable to update commitment: %v", err)
+	}
+
+	remoteDelta := &ChannelDelta{
+		LocalBalance:  btcutil.Amount(2e8),
+		RemoteBalance: btcutil.Amount(1e8),
+		UpdateNum:     1,
+	}
+	if err := channel.RecordRemoteRevocation(remoteDelta, nil); err != nil {
+		t.Fatalf("unable to record revocation: %v", err)
+	}
+
+	expected := []*ReplicatedDelta{
+		{
+			ChanPoint: *channel.ChanID,
+			Kind:      LocalDelta,
+			Delta:     localDelta,
+			CommitTx:  newTx,
+			CommitSig: newSig,
+		},
+		{
+			ChanPoint: *channel.ChanID,
+			Kind:      RemoteDelta,
+			Delta:     remoteDelta,
+		},
+	}
+	if !reflect.DeepEqual(observed, expected) {
+		t.Fatalf("expected deltas %v, observed %v", spew.Sdump(expected),
+			spew.Sdump(observed))
+	}
+
+	for _, delta := range observed {
+		var b bytes.Buffer
+		if err := delta.Encode(&b); err != nil {
+			t.Fatalf("unable to encode delta: %v", err)
+		}
+
+		decoded := &ReplicatedDelta{}
+		if err := decoded.Decode(&b); err != nil {
+			t.Fatalf("unable to decode delta: %v", err)
+		}
+		if decoded.ChanPoint != delta.ChanPoint ||
+			decoded.Kind != delta.Kind ||
+			!reflect.DeepEqual(decoded.Delta, delta.Delta) ||
+			!bytes.Equal(decoded.CommitSig, delta.CommitSig) {
+
+			t.Fatalf("decoded delta doesn't match: %v vs %v",
+				spew.Sdump(decoded), spew.Sdump(delta))
+		}
+		if (decoded.CommitTx == nil) != (delta.CommitTx == nil) ||
+			(delta.CommitTx != nil &&
+				decoded.CommitTx.TxSha() != delta.CommitTx.TxSha()) {
+
+			t.Fatalf("decoded commitment doesn't match")
+		}
+	}
+
+	// Once unregistered, the observer is no longer notified.
+	cdb.SetDeltaObserver(nil)
+	remoteDelta.UpdateNum = 2
+	if err := channel.RecordRemoteRevocation(remoteDelta, nil); err != nil {
+		t.Fatalf("unable to record revocation: %v", err)
+	}
+	if len(observed) != 2 {
+		t.Fatalf("unregistered observer notified")
+	}
+}
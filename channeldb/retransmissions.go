@@ -0,0 +1,150 @@
+package channeldb
+
+import (
+	"bytes"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	// retransmitBucket is the bucket which houses the outbound state
+	// update messages of each channel which haven't yet been acknowledged
+	// by the remote party. Each channel has a sub-bucket keyed by its
+	// channel point, whose entries are keyed by an increasing sequence
+	// number. Each value is the commitment height the message pertains
+	// to, followed by the serialized message.
+	retransmitBucket = []byte("rtx")
+)
+
+// Retransmission is an outbound state update message which must be sent
+// again should the connection to the remote party be lost before it's been
+// acknowledged.
+type Retransmission struct {
+	// Seq orders the retransmissions of a channel by the time they were
+	// first sent.
+	Seq uint64
+
+	// Height is the commitment height the message pertains to.
+	Height uint64
+
+	// Msg is the serialized message.
+	Msg []byte
+}
+
+// AddRetransmission records the serialized message within the channel's
+// retransmission queue, returning the sequence number it was assigned.
+func (c *OpenChannel) AddRetransmission(height uint64, msg []byte) (uint64, error) {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, c.ChanID); err != nil {
+		return 0, err
+	}
+
+	var seq uint64
+	err := c.Db.store.Update(func(tx *bolt.Tx) error {
+		retransmissions, err := tx.CreateBucketIfNotExists(retransmitBucket)
+		if err != nil {
+			return err
+		}
+		chanQueue, err := retransmissions.CreateBucketIfNotExists(b.Bytes())
+		if err != nil {
+			return err
+		}
+
+		seq, err = chanQueue.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], seq)
+
+		value := make([]byte, 8+len(msg))
+		byteOrder.PutUint64(value[:8], height)
+		copy(value[8:], msg)
+
+		return chanQueue.Put(key[:], value)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// FetchRetransmissions returns all messages within the channel's
+// retransmission queue, in the order they were added.
+func (c *OpenChannel) FetchRetransmissions() ([]*Retransmission, error) {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, c.ChanID); err != nil {
+		return nil, err
+	}
+
+	var queue []*Retransmission
+	err := c.Db.store.View(func(tx *bolt.Tx) error {
+		retransmissions := tx.Bucket(retransmitBucket)
+		if retransmissions == nil {
+			return nil
+		}
+		chanQueue := retransmissions.Bucket(b.Bytes())
+		if chanQueue == nil {
+			return nil
+		}
+
+		return chanQueue.ForEach(func(k, v []byte) error {
+			msg := make([]byte, len(v)-8)
+			copy(msg, v[8:])
+
+			queue = append(queue, &Retransmission{
+				Seq:    byteOrder.Uint64(k),
+				Height: byteOrder.Uint64(v[:8]),
+				Msg:    msg,
+			})
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return queue, nil
+}
+
+// AckRetransmission removes the message with the passed sequence number from
+// the channel's retransmission queue, as it's been acknowledged by the remote
+// party.
+func (c *OpenChannel) AckRetransmission(seq uint64) error {
+	var b bytes.Buffer
+	if err := writeOutpoint(&b, c.ChanID); err != nil {
+		return err
+	}
+
+	return c.Db.store.Update(func(tx *bolt.Tx) error {
+		retransmissions := tx.Bucket(retransmitBucket)
+		if retransmissions == nil {
+			return nil
+		}
+		chanQueue := retransmissions.Bucket(b.Bytes())
+		if chanQueue == nil {
+			return nil
+		}
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], seq)
+
+		return chanQueue.Delete(key[:])
+	})
+}
+
+// deleteRetransmissions removes the entire retransmission queue of the
+// channel identified by the passed serialized channel point.
+func deleteRetransmissions(tx *bolt.Tx, chanID []byte) error {
+	retransmissions := tx.Bucket(retransmitBucket)
+	if retransmissions == nil {
+		return nil
+	}
+	if retransmissions.Bucket(chanID) == nil {
+		return nil
+	}
+
+	return retransmissions.DeleteBucket(chanID)
+}
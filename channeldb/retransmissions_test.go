@@ -0,0 +1,70 @@
+package channeldb
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRetransmissionQueue(t *testing.T) {
+	cdb, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test database: %v", err)
+	}
+	defer cleanUp()
+
+	channel, err := createTestChannelState(cdb)
+	if err != nil {
+		t.Fatalf("unable to create channel state: %v", err)
+	}
+	if err := channel.FullSync(); err != nil {
+		t.Fatalf("unable to save and serialize channel state: %v", err)
+	}
+
+	queue, err := channel.FetchRetransmissions()
+	if err != nil {
+		t.Fatalf("unable to fetch retransmissions: %v", err)
+	}
+	if len(queue) != 0 {
+		t.Fatalf("expected empty queue, got %v entries", len(queue))
+	}
+
+	msgs := [][]byte{{1, 1}, {2, 2, 2}, {3}}
+	seqs := make([]uint64, len(msgs))
+	for i, msg := range msgs {
+		seqs[i], err = channel.AddRetransmission(uint64(i+10), msg)
+		if err != nil {
+			t.Fatalf("unable to add retransmission: %v", err)
+		}
+	}
+
+	// Acknowledging the middle message should leave the others in the
+	// order they were added.
+	if err := channel.AckRetransmission(seqs[1]); err != nil {
+		t.Fatalf("unable to ack retransmission: %v", err)
+	}
+	queue, err = channel.FetchRetransmissions()
+	if err != nil {
+		t.Fatalf("unable to fetch retransmissions: %v", err)
+	}
+	if len(queue) != 2 {
+		t.Fatalf("expected 2 entries, got %v", len(queue))
+	}
+	for i, j := range []int{0, 2} {
+		if queue[i].Seq != seqs[j] || queue[i].Height != uint64(j+10) ||
+			!bytes.Equal(queue[i].Msg, msgs[j]) {
+			t.Fatalf("entry #%v doesn't match: %v", i, queue[i])
+		}
+	}
+
+	// Once the channel is closed, its queue should be removed.
+	if err := channel.CloseChannel(&ChannelCloseSummary{}); err != nil {
+		t.Fatalf("unable to close channel: %v", err)
+	}
+	queue, err = channel.FetchRetransmissions()
+	if err != nil {
+		t.Fatalf("unable to fetch retransmissions: %v", err)
+	}
+	if len(queue) != 0 {
+		t.Fatalf("queue not removed on close, got %v entries", len(queue))
+	}
+}
@@ -0,0 +1,369 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// revokedCommitBucket is a sub-bucket of a node's channel bucket which
+	// stores the data required to construct a justice transaction for
+	// each revoked remote commitment. Entries are keyed by: chanPoint ||
+	// updateNum.
+	revokedCommitBucket = []byte("rcb")
+
+	// revokedTxidIndexBucket is a sub-bucket of a node's channel bucket
+	// which maps the txid of a revoked commitment to the key of its entry
+	// within the revokedCommitBucket. This index allows the breach
+	// arbiter to quickly determine if a spend of the funding output is a
+	// revoked state.
+	revokedTxidIndexBucket = []byte("rti")
+)
+
+// RevokedOutput describes a single output on a revoked remote commitment
+// transaction which we're able to sweep using the revocation key.
+type RevokedOutput struct {
+	// Index is the index of the output within the commitment transaction.
+	Index uint32
+
+	// Value is the amount of satoshis carried by the output. For colored
+	// commitments, this is typically the dust carrier amount.
+	Value btcutil.Amount
+
+	// AssetAmt is the amount of the channel's colored asset assigned to
+	// this output.
+	AssetAmt btcutil.Amount
+
+	// PkScript is the public key script of the output.
+	PkScript []byte
+
+	// WitnessScript is the full witness script committed to by PkScript.
+	WitnessScript []byte
+
+	// IsHTLC denotes if this output is an HTLC output, rather than the
+	// remote party's delayed balance output.
+	IsHTLC bool
+
+	// IsIncoming denotes if the HTLC output pays to us. This determines
+	// which revocation clause of the HTLC script must be satisfied in
+	// order to sweep the output.
+	IsIncoming bool
+}
+
+// RevokedCommitment encapsulates all the data needed to construct a penalty
+// transaction sweeping all the outputs of a revoked remote commitment
+// transaction.
+type RevokedCommitment struct {
+	// UpdateNum is the height of the revoked commitment.
+	UpdateNum uint64
+
+	// CommitTxid is the txid of the revoked commitment transaction.
+	CommitTxid wire.ShaHash
+
+	// RevocationPreimage is the revocation pre-image given to us by the
+	// remote party for this state.
+	RevocationPreimage [32]byte
+
+	// RevocationKey is the revocation public key used within the outputs
+	// of the revoked commitment transaction.
+	RevocationKey *btcec.PublicKey
+
+	// Outputs is the set of outputs we're able to sweep.
+	Outputs []*RevokedOutput
+}
+
+// PutRevokedCommitment persists the passed revoked commitment within the
+// channel's revocation log, additionally indexing the entry by the txid of
+// the revoked commitment transaction.
+func (c *OpenChannel) PutRevokedCommitment(rc *RevokedCommitment) error {
+	return c.Db.store.Update(func(tx *bolt.Tx) error {
+		chanBucket, err := tx.CreateBucketIfNotExists(openChannelBucket)
+		if err != nil {
+			return err
+		}
+
+		nodeChanBucket, err := chanBucket.CreateBucketIfNotExists(c.TheirLNID[:])
+		if err != nil {
+			return err
+		}
+
+		return putRevokedCommitment(nodeChanBucket, c.ChanID, rc)
+	})
+}
+
+// putRevokedCommitment writes the revoked commitment, along with its txid
+// index entry into the passed node's channel bucket.
+func putRevokedCommitment(nodeChanBucket *bolt.Bucket, chanID *wire.OutPoint,
+	rc *RevokedCommitment) error {
+
+	revokedBucket, err := nodeChanBucket.CreateBucketIfNotExists(revokedCommitBucket)
+	if err != nil {
+		return err
+	}
+	txidIndex, err := nodeChanBucket.CreateBucketIfNotExists(revokedTxidIndexBucket)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := serializeRevokedCommitment(&b, rc); err != nil {
+		return err
+	}
+
+	entryKey := makeRevokedCommitKey(chanID, rc.UpdateNum)
+	if err := revokedBucket.Put(entryKey[:], b.Bytes()); err != nil {
+		return err
+	}
+
+	return txidIndex.Put(rc.CommitTxid[:], entryKey[:])
+}
+
+// FetchRevokedCommitment retrieves the revoked commitment at the target
+// update number from the channel's revocation log.
+func (c *OpenChannel) FetchRevokedCommitment(updateNum uint64) (*RevokedCommitment, error) {
+	var rc *RevokedCommitment
+	err := c.Db.store.View(func(tx *bolt.Tx) error {
+		revokedBucket, _, err := fetchRevocationBuckets(tx, c.TheirLNID[:])
+		if err != nil {
+			return err
+		}
+
+		entryKey := makeRevokedCommitKey(c.ChanID, updateNum)
+		rc, err = fetchRevokedCommitment(revokedBucket, entryKey[:])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+// FetchRevokedCommitmentByTxid retrieves a revoked commitment from the
+// channel's revocation log by the txid of the revoked commitment transaction.
+// If the txid doesn't correspond to a known revoked state,
+// ErrRevokedCommitNotFound is returned.
+func (c *OpenChannel) FetchRevokedCommitmentByTxid(txid *wire.ShaHash) (*RevokedCommitment, error) {
+	var rc *RevokedCommitment
+	err := c.Db.store.View(func(tx *bolt.Tx) error {
+		revokedBucket, txidIndex, err := fetchRevocationBuckets(tx,
+			c.TheirLNID[:])
+		if err != nil {
+			return err
+		}
+
+		entryKey := txidIndex.Get(txid[:])
+		if entryKey == nil {
+			return ErrRevokedCommitNotFound
+		}
+
+		rc, err = fetchRevokedCommitment(revokedBucket, entryKey)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rc, nil
+}
+
+// fetchRevocationBuckets returns the revoked commitment bucket, and the txid
+// index bucket for the channels shared with the target node.
+func fetchRevocationBuckets(tx *bolt.Tx, nodeID []byte) (*bolt.Bucket, *bolt.Bucket, error) {
+	chanBucket := tx.Bucket(openChannelBucket)
+	if chanBucket == nil {
+		return nil, nil, ErrNoChanDBExists
+	}
+
+	nodeChanBucket := chanBucket.Bucket(nodeID)
+	if nodeChanBucket == nil {
+		return nil, nil, ErrNoActiveChannels
+	}
+
+	revokedBucket := nodeChanBucket.Bucket(revokedCommitBucket)
+	txidIndex := nodeChanBucket.Bucket(revokedTxidIndexBucket)
+	if revokedBucket == nil || txidIndex == nil {
+		return nil, nil, ErrRevokedCommitNotFound
+	}
+
+	return revokedBucket, txidIndex, nil
+}
+
+func fetchRevokedCommitment(revokedBucket *bolt.Bucket,
+	entryKey []byte) (*RevokedCommitment, error) {
+
+	entryBytes := revokedBucket.Get(entryKey)
+	if entryBytes == nil {
+		return nil, ErrRevokedCommitNotFound
+	}
+
+	return deserializeRevokedCommitment(bytes.NewReader(entryBytes))
+}
+
+func makeRevokedCommitKey(o *wire.OutPoint, updateNum uint64) [44]byte {
+	var (
+		scratch [8]byte
+		n       int
+		k       [44]byte
+	)
+
+	n += copy(k[:], o.Hash[:])
+
+	byteOrder.PutUint32(scratch[:4], o.Index)
+	n += copy(k[n:], scratch[:4])
+
+	byteOrder.PutUint64(scratch[:], updateNum)
+	copy(k[n:], scratch[:])
+
+	return k
+}
+
+func serializeRevokedCommitment(w io.Writer, rc *RevokedCommitment) error {
+	var scratch [8]byte
+
+	byteOrder.PutUint64(scratch[:], rc.UpdateNum)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(rc.CommitTxid[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(rc.RevocationPreimage[:]); err != nil {
+		return err
+	}
+	revKey := rc.RevocationKey.SerializeCompressed()
+	if err := wire.WriteVarBytes(w, 0, revKey); err != nil {
+		return err
+	}
+
+	numOutputs := uint64(len(rc.Outputs))
+	if err := wire.WriteVarInt(w, 0, numOutputs); err != nil {
+		return err
+	}
+	for _, output := range rc.Outputs {
+		if err := serializeRevokedOutput(w, output); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deserializeRevokedCommitment(r io.Reader) (*RevokedCommitment, error) {
+	var scratch [8]byte
+
+	rc := &RevokedCommitment{}
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	rc.UpdateNum = byteOrder.Uint64(scratch[:])
+	if _, err := io.ReadFull(r, rc.CommitTxid[:]); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, rc.RevocationPreimage[:]); err != nil {
+		return nil, err
+	}
+	revKey, err := wire.ReadVarBytes(r, 0, 33, "revocation key")
+	if err != nil {
+		return nil, err
+	}
+	rc.RevocationKey, err = btcec.ParsePubKey(revKey, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	numOutputs, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	rc.Outputs = make([]*RevokedOutput, numOutputs)
+	for i := uint64(0); i < numOutputs; i++ {
+		rc.Outputs[i], err = deserializeRevokedOutput(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rc, nil
+}
+
+func serializeRevokedOutput(w io.Writer, o *RevokedOutput) error {
+	var scratch [8]byte
+
+	byteOrder.PutUint32(scratch[:4], o.Index)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], uint64(o.Value))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint64(scratch[:], uint64(o.AssetAmt))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, o.PkScript); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, o.WitnessScript); err != nil {
+		return err
+	}
+
+	var flags [2]byte
+	if o.IsHTLC {
+		flags[0] = 1
+	}
+	if o.IsIncoming {
+		flags[1] = 1
+	}
+	if _, err := w.Write(flags[:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func deserializeRevokedOutput(r io.Reader) (*RevokedOutput, error) {
+	var (
+		scratch [8]byte
+		err     error
+	)
+
+	o := &RevokedOutput{}
+
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return nil, err
+	}
+	o.Index = byteOrder.Uint32(scratch[:4])
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	o.Value = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	o.AssetAmt = btcutil.Amount(byteOrder.Uint64(scratch[:]))
+
+	o.PkScript, err = wire.ReadVarBytes(r, 0, 10000, "pkscript")
+	if err != nil {
+		return nil, err
+	}
+	o.WitnessScript, err = wire.ReadVarBytes(r, 0, 10000, "witness script")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:2]); err != nil {
+		return nil, err
+	}
+	o.IsHTLC = scratch[0] == 1
+	o.IsIncoming = scratch[1] == 1
+
+	return o, nil
+}
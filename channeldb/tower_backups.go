@@ -0,0 +1,151 @@
+package channeldb
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/boltdb/bolt"
+	"github.com/roasbeef/btcd/btcec"
+)
+
+var (
+	// towerBackupBucket is the bucket which houses the revoked states
+	// queued for backup to our watchtowers. Entries are keyed by a
+	// big-endian sequence number, so they're iterated in the order they
+	// were queued.
+	towerBackupBucket = []byte("tower-backups")
+)
+
+// TowerBackup is a revoked state queued for backup to our watchtowers. The
+// entry remains queued until each of the towers has accepted the justice
+// transaction sweeping the revoked commitment.
+type TowerBackup struct {
+	// Seq is the sequence number of the entry within the queue. It's
+	// assigned once the entry is added.
+	Seq uint64
+
+	// CommitKey is our commitment key within the channel the revoked
+	// commitment belongs to.
+	CommitKey *btcec.PublicKey
+
+	// CsvDelay is the delay of the remote party's output within the
+	// revoked commitment.
+	CsvDelay uint32
+
+	// Revoked is the revoked commitment to be swept.
+	Revoked *RevokedCommitment
+}
+
+// AddTowerBackup appends the passed revoked state to the queue of states to
+// be backed up, assigning its sequence number.
+func (d *DB) AddTowerBackup(b *TowerBackup) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		backups, err := tx.CreateBucketIfNotExists(towerBackupBucket)
+		if err != nil {
+			return err
+		}
+
+		seq, err := backups.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		var v bytes.Buffer
+		if err := serializeTowerBackup(&v, b); err != nil {
+			return err
+		}
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], seq)
+		if err := backups.Put(key[:], v.Bytes()); err != nil {
+			return err
+		}
+
+		b.Seq = seq
+		return nil
+	})
+}
+
+// FetchTowerBackups returns all revoked states queued for backup, in the
+// order they were queued.
+func (d *DB) FetchTowerBackups() ([]*TowerBackup, error) {
+	var towerBackups []*TowerBackup
+	err := d.store.View(func(tx *bolt.Tx) error {
+		backups := tx.Bucket(towerBackupBucket)
+		if backups == nil {
+			return nil
+		}
+
+		return backups.ForEach(func(k, v []byte) error {
+			b, err := deserializeTowerBackup(bytes.NewReader(v))
+			if err != nil {
+				return err
+			}
+			b.Seq = byteOrder.Uint64(k)
+
+			towerBackups = append(towerBackups, b)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return towerBackups, nil
+}
+
+// DeleteTowerBackup removes the revoked state with the passed sequence
+// number from the queue. Removing an entry which isn't queued is a no-op.
+func (d *DB) DeleteTowerBackup(seq uint64) error {
+	return d.store.Update(func(tx *bolt.Tx) error {
+		backups := tx.Bucket(towerBackupBucket)
+		if backups == nil {
+			return nil
+		}
+
+		var key [8]byte
+		byteOrder.PutUint64(key[:], seq)
+		return backups.Delete(key[:])
+	})
+}
+
+func serializeTowerBackup(w io.Writer, b *TowerBackup) error {
+	if _, err := w.Write(b.CommitKey.SerializeCompressed()); err != nil {
+		return err
+	}
+
+	var scratch [4]byte
+	byteOrder.PutUint32(scratch[:], b.CsvDelay)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	return serializeRevokedCommitment(w, b.Revoked)
+}
+
+func deserializeTowerBackup(r io.Reader) (*TowerBackup, error) {
+	b := &TowerBackup{}
+
+	var commitKey [33]byte
+	if _, err := io.ReadFull(r, commitKey[:]); err != nil {
+		return nil, err
+	}
+	var err error
+	b.CommitKey, err = btcec.ParsePubKey(commitKey[:], btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	var scratch [4]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return nil, err
+	}
+	b.CsvDelay = byteOrder.Uint32(scratch[:])
+
+	b.Revoked, err = deserializeRevokedCommitment(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
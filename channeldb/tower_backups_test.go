@@ -0,0 +1,67 @@
+package channeldb
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestTowerBackupQueue tests that queued revoked states are returned in the
+// order they were queued until removed.
+func TestTowerBackupQueue(t *testing.T) {
+	db, cleanUp, err := makeTestDB()
+	if err != nil {
+		t.Fatalf("unable to make test db: %v", err)
+	}
+	defer cleanUp()
+
+	backups, err := db.FetchTowerBackups()
+	if err != nil {
+		t.Fatalf("unable to fetch backups: %v", err)
+	}
+	if len(backups) != 0 {
+		t.Fatalf("expected empty queue, got %v backups", len(backups))
+	}
+	if err := db.DeleteTowerBackup(1); err != nil {
+		t.Fatalf("unable to delete unknown backup: %v", err)
+	}
+
+	var queued []*TowerBackup
+	for i := uint64(0); i < 3; i++ {
+		b := &TowerBackup{
+			CommitKey: pubKey,
+			CsvDelay:  uint32(i + 4),
+			Revoked: &RevokedCommitment{
+				UpdateNum:     i,
+				CommitTxid:    wire.ShaHash{byte(i)},
+				RevocationKey: pubKey,
+				Outputs: []*RevokedOutput{
+					{
+						Index:         1,
+						Value:         546,
+						AssetAmt:      1000,
+						PkScript:      []byte{0x00, 0x20},
+						WitnessScript: []byte{0x51},
+					},
+				},
+			},
+		}
+		if err := db.AddTowerBackup(b); err != nil {
+			t.Fatalf("unable to add backup: %v", err)
+		}
+		queued = append(queued, b)
+	}
+
+	if err := db.DeleteTowerBackup(queued[1].Seq); err != nil {
+		t.Fatalf("unable to delete backup: %v", err)
+	}
+	backups, err = db.FetchTowerBackups()
+	if err != nil {
+		t.Fatalf("unable to fetch backups: %v", err)
+	}
+	expected := []*TowerBackup{queued[0], queued[2]}
+	if !reflect.DeepEqual(backups, expected) {
+		t.Fatalf("expected backups %v, got %v", expected, backups)
+	}
+}
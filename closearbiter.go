@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// defaultForceCloseGrace is the default period a peer may remain
+	// disconnected while HTLCs are outstanding within our channels with
+	// it, before the channels are force closed.
+	defaultForceCloseGrace = time.Hour
+
+	// forceCloseCheckInterval is the interval at which the channels of
+	// disconnected peers are checked against the force close policy.
+	forceCloseCheckInterval = time.Minute
+)
+
+// forceClosePolicy governs the automatic unilateral closure of channels
+// whose peer has become unresponsive. A channel without any outstanding HTLCs
+// is never closed automatically, as its balances are safe for as long as the
+// peer doesn't broadcast a revoked state, which the watchtowers guard
+// against. Outstanding HTLCs on the other hand must be resolved on-chain
+// before they time out.
+type forceClosePolicy struct {
+	// autoClose toggles whether channels are actually closed. If false,
+	// each channel which would be closed is only logged.
+	autoClose bool
+
+	// gracePeriod is the period a peer may remain unresponsive while
+	// HTLCs are outstanding, before the channel is closed.
+	gracePeriod time.Duration
+
+	// maxExposure is, per asset, the total value of outstanding HTLCs
+	// beyond which the channel is closed as soon as the peer becomes
+	// unresponsive, without waiting out the grace period. Assets without
+	// an entry are always granted the grace period.
+	maxExposure map[string]btcutil.Amount
+}
+
+// newForceClosePolicy creates a new forceClosePolicy. Each max exposure is of
+// the form <asset_id>:<amount>.
+func newForceClosePolicy(autoClose bool, gracePeriod time.Duration,
+	maxExposures []string) (*forceClosePolicy, error) {
+
+	if gracePeriod < 0 {
+		return nil, fmt.Errorf("force close grace period cannot be " +
+			"negative")
+	}
+
+	p := &forceClosePolicy{
+		autoClose:   autoClose,
+		gracePeriod: gracePeriod,
+		maxExposure: make(map[string]btcutil.Amount),
+	}
+
+	for _, exposure := range maxExposures {
+		parts := strings.Split(exposure, ":")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid max htlc exposure %q, "+
+				"must be of the form <asset_id>:<amount>",
+				exposure)
+		}
+
+		amt, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max htlc exposure %q: "+
+				"%v", exposure, err)
+		}
+		if amt < 0 {
+			return nil, fmt.Errorf("invalid max htlc exposure %q, "+
+				"amount cannot be negative", exposure)
+		}
+
+		p.maxExposure[parts[0]] = btcutil.Amount(amt)
+	}
+
+	return p, nil
+}
+
+// shouldClose returns whether the channel described by the passed snapshot
+// should be force closed, its peer having been unresponsive for the passed
+// duration, along with the reason why.
+func (p *forceClosePolicy) shouldClose(snapshot *channeldb.ChannelSnapshot,
+	unresponsive time.Duration) (bool, string) {
+
+	var exposure btcutil.Amount
+	for _, htlc := range snapshot.Htlcs {
+		exposure += htlc.Amt
+	}
+	if exposure == 0 {
+		return false, ""
+	}
+
+	maxExposure, ok := p.maxExposure[snapshot.AssetID]
+	if ok && exposure > maxExposure {
+		return true, fmt.Sprintf("htlc exposure of %v exceeds max of %v",
+			exposure, maxExposure)
+	}
+
+	if unresponsive >= p.gracePeriod {
+		return true, fmt.Sprintf("peer unresponsive for %v with %v "+
+			"of htlcs outstanding", unresponsive, exposure)
+	}
+
+	return false, ""
+}
+
+// closeArbiter applies the forceClosePolicy to the channels of peers which
+// have disconnected from us. Each disconnected peer is tracked until it
+// reconnects, with its channels periodically checked against the policy.
+//
+// TODO: also track peers with open channels which haven't connected since
+// start up.
+type closeArbiter struct {
+	started  int32 // atomic
+	shutdown int32 // atomic
+
+	policy *forceClosePolicy
+	server *server
+
+	// peerStatus is sent upon whenever a peer connects, or disconnects.
+	peerStatus chan *peerStatusMsg
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// peerStatusMsg notifies the closeArbiter of a peer connecting, or
+// disconnecting.
+type peerStatusMsg struct {
+	nodeID wire.ShaHash
+	online bool
+}
+
+// newCloseArbiter creates a new closeArbiter applying the passed policy.
+func newCloseArbiter(s *server, policy *forceClosePolicy) *closeArbiter {
+	return &closeArbiter{
+		policy:     policy,
+		server:     s,
+		peerStatus: make(chan *peerStatusMsg),
+		quit:       make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine applying the force close policy.
+func (c *closeArbiter) Start() error {
+	if !atomic.CompareAndSwapInt32(&c.started, 0, 1) {
+		return nil
+	}
+
+	c.wg.Add(1)
+	go c.arbiter()
+
+	return nil
+}
+
+// Stop gracefully stops the closeArbiter, waiting until its goroutine has
+// exited.
+func (c *closeArbiter) Stop() error {
+	if !atomic.CompareAndSwapInt32(&c.shutdown, 0, 1) {
+		return nil
+	}
+
+	close(c.quit)
+	c.wg.Wait()
+
+	return nil
+}
+
+// peerOnline notifies the closeArbiter that the target peer has connected,
+// suspending the policy for its channels.
+func (c *closeArbiter) peerOnline(nodeID wire.ShaHash) {
+	c.notifyPeerStatus(&peerStatusMsg{nodeID: nodeID, online: true})
+}
+
+// peerOffline notifies the closeArbiter that the target peer has
+// disconnected, starting the grace period of its channels.
+func (c *closeArbiter) peerOffline(nodeID wire.ShaHash) {
+	c.notifyPeerStatus(&peerStatusMsg{nodeID: nodeID})
+}
+
+func (c *closeArbiter) notifyPeerStatus(msg *peerStatusMsg) {
+	select {
+	case c.peerStatus <- msg:
+	case <-c.quit:
+	}
+}
+
+// arbiter is the goroutine tracking disconnected peers, and closing their
+// channels as dictated by the policy.
+//
+// NOTE: This MUST be run as a goroutine.
+func (c *closeArbiter) arbiter() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(forceCloseCheckInterval)
+	defer ticker.Stop()
+
+	// offlineSince maps each disconnected peer to the time at which it
+	// disconnected, while flagged holds the channels already logged as
+	// due to be closed with auto close disabled, so they're only logged
+	// once.
+	offlineSince := make(map[wire.ShaHash]time.Time)
+	flagged := make(map[wire.OutPoint]struct{})
+
+	for {
+		select {
+		case msg := <-c.peerStatus:
+			if msg.online {
+				delete(offlineSince, msg.nodeID)
+				continue
+			}
+
+			// A peer whose HTLC exposure already exceeds the max
+			// is checked right away.
+			offlineSince[msg.nodeID] = time.Now()
+			c.checkChannels(msg.nodeID, 0, flagged)
+
+		case <-ticker.C:
+			for nodeID, since := range offlineSince {
+				c.checkChannels(nodeID, time.Since(since), flagged)
+			}
+
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// checkChannels checks each channel with the target peer against the
+// policy, force closing those due to be closed.
+func (c *closeArbiter) checkChannels(nodeID wire.ShaHash,
+	unresponsive time.Duration, flagged map[wire.OutPoint]struct{}) {
+
+	channels, err := c.server.chanDB.FetchOpenChannels(&nodeID)
+	if err != nil {
+		srvrLog.Errorf("unable to fetch channels of node %x: %v",
+			nodeID[:], err)
+		return
+	}
+
+	for _, dbChan := range channels {
+		snapshot := dbChan.Snapshot()
+		shouldClose, reason := c.policy.shouldClose(snapshot, unresponsive)
+		if !shouldClose {
+			continue
+		}
+
+		chanPoint := *snapshot.ChannelPoint
+		if !c.policy.autoClose {
+			if _, ok := flagged[chanPoint]; !ok {
+				srvrLog.Warnf("ChannelPoint(%v) due to be force "+
+					"closed, auto close disabled: %v",
+					chanPoint, reason)
+				flagged[chanPoint] = struct{}{}
+			}
+			continue
+		}
+
+		srvrLog.Warnf("Force closing ChannelPoint(%v): %v", chanPoint,
+			reason)
+
+		if err := c.forceClose(dbChan); err != nil {
+			srvrLog.Errorf("unable to force close "+
+				"ChannelPoint(%v): %v", chanPoint, err)
+		}
+	}
+}
+
+// forceClose broadcasts the latest commitment transaction of the passed
+// channel, hands its outputs over to the utxoNursery, and removes the channel
+// from the database.
+func (c *closeArbiter) forceClose(dbChan *channeldb.OpenChannel) error {
+	s := c.server
+	channel, err := lnwallet.NewLightningChannel(s.lnwallet.Signer, s.bio,
+		s.chainNotifier, dbChan)
+	if err != nil {
+		return err
+	}
+
+	snapshot := channel.StateSnapshot()
+	closeSummary, err := channel.ForceClose()
+	if err != nil {
+		channel.Stop()
+		return err
+	}
+
+	closeTx := closeSummary.CloseTx
+	txid := closeTx.TxSha()
+	err = s.lnwallet.PublishAuditedTransaction(closeTx,
+		channeldb.TxForceClose, *channel.ChannelPoint(),
+		snapshot.AssetID)
+	if err != nil {
+		channel.Stop()
+		return err
+	}
+
+	s.utxoNursery.incubateOutputs(closeSummary)
+	s.utxoNursery.watchCommitSpends(channel, closeSummary.SelfOutpoint)
+	s.chanGraph.RemoveChannel(*channel.ChannelPoint())
+
+	err = channel.DeleteState(channeldb.ForceClose, &txid, 0)
+	if err != nil {
+		return err
+	}
+
+	s.notifyChannelEvent(ChannelClosed, snapshot, func(e *ChannelEvent) {
+		e.CloseType = channeldb.ForceClose
+		e.ClosingTxid = &txid
+	})
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+)
+
+// TestForceClosePolicy tests that channels are only force closed once the
+// grace period has elapsed with HTLCs outstanding, or right away if the HTLCs
+// exceed the max exposure for the channel's asset.
+func TestForceClosePolicy(t *testing.T) {
+	policy, err := newForceClosePolicy(true, time.Hour,
+		[]string{"asset:1000"})
+	if err != nil {
+		t.Fatalf("unable to create policy: %v", err)
+	}
+
+	htlcs := []channeldb.HTLC{{Amt: 400}, {Amt: 500}}
+	tests := []struct {
+		assetID      string
+		htlcs        []channeldb.HTLC
+		unresponsive time.Duration
+		shouldClose  bool
+	}{
+		// Without any HTLCs outstanding, the channel is left open.
+		{assetID: "asset", unresponsive: 2 * time.Hour},
+
+		// HTLCs within the max exposure are granted the grace period.
+		{assetID: "asset", htlcs: htlcs, unresponsive: time.Minute},
+		{
+			assetID:      "asset",
+			htlcs:        htlcs,
+			unresponsive: time.Hour,
+			shouldClose:  true,
+		},
+
+		// Exceeding the max exposure forgoes the grace period.
+		{
+			assetID:     "asset",
+			htlcs:       append(htlcs, channeldb.HTLC{Amt: 101}),
+			shouldClose: true,
+		},
+
+		// Assets without a max exposure are always granted the grace
+		// period.
+		{
+			assetID: "other",
+			htlcs:   []channeldb.HTLC{{Amt: 1 << 40}},
+		},
+	}
+
+	for i, test := range tests {
+		snapshot := &channeldb.ChannelSnapshot{
+			AssetID: test.assetID,
+			Htlcs:   test.htlcs,
+		}
+		shouldClose, reason := policy.shouldClose(snapshot,
+			test.unresponsive)
+		if shouldClose != test.shouldClose {
+			t.Fatalf("test #%v: expected shouldClose=%v, got %v (%v)",
+				i, test.shouldClose, shouldClose, reason)
+		}
+	}
+
+	invalid := [][]string{{"asset"}, {":100"}, {"asset:-1"}, {"asset:x"}}
+	for _, exposures := range invalid {
+		_, err := newForceClosePolicy(true, time.Hour, exposures)
+		if err == nil {
+			t.Fatalf("invalid max exposure %v accepted", exposures)
+		}
+	}
+}
@@ -311,6 +311,12 @@ var CloseChannelCommand = cli.Command{
 			Name:  "block",
 			Usage: "block until the channel is closed",
 		},
+		cli.StringFlag{
+			Name: "delivery_addr",
+			Usage: "if set, send our settled balance to this address " +
+				"rather than the one negotiated when opening the " +
+				"channel, only valid for cooperative closures",
+		},
 	},
 	Action: closeChannel,
 }
@@ -330,7 +336,8 @@ func closeChannel(ctx *cli.Context) error {
 			FundingTxid: txid[:],
 			OutputIndex: uint32(ctx.Int("output_index")),
 		},
-		Force: ctx.Bool("force"),
+		Force:           ctx.Bool("force"),
+		DeliveryAddress: ctx.String("delivery_addr"),
 	}
 
 	stream, err := client.CloseChannel(ctxb, req)
@@ -370,6 +377,94 @@ func closeChannel(ctx *cli.Context) error {
 	return nil
 }
 
+var AbortFundingCommand = cli.Command{
+	Name: "abortfunding",
+	Description: "Cancel a pending channel we funded, whose funding " +
+		"transaction has yet to confirm, by double spending the " +
+		"funding transaction's inputs back to the wallet.",
+	Usage: "abortfunding --funding_txid=T --output_index=N",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "funding_txid",
+			Usage: "the txid of the channel's funding transaction",
+		},
+		cli.IntFlag{
+			Name:  "output_index",
+			Usage: "the output index of the channel's funding output",
+		},
+	},
+	Action: abortFunding,
+}
+
+func abortFunding(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	txid, err := wire.NewShaHashFromStr(ctx.String("funding_txid"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.AbortFundingRequest{
+		ChannelPoint: &lnrpc.ChannelPoint{
+			FundingTxid: txid[:],
+			OutputIndex: uint32(ctx.Int("output_index")),
+		},
+	}
+	resp, err := client.AbortFunding(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var ExportFundingBundleCommand = cli.Command{
+	Name: "exportfundingbundle",
+	Description: "Export the proof bundle of a channel's funding " +
+		"transaction, allowing the issuer of the channel's asset to " +
+		"verify the channel holds it.",
+	Usage: "exportfundingbundle --funding_txid=T --output_index=N",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "funding_txid",
+			Usage: "the txid of the channel's funding transaction",
+		},
+		cli.IntFlag{
+			Name:  "output_index",
+			Usage: "the output index of the channel's funding output",
+		},
+	},
+	Action: exportFundingBundle,
+}
+
+func exportFundingBundle(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	txid, err := wire.NewShaHashFromStr(ctx.String("funding_txid"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.FundingBundleRequest{
+		ChannelPoint: &lnrpc.ChannelPoint{
+			FundingTxid: txid[:],
+			OutputIndex: uint32(ctx.Int("output_index")),
+		},
+	}
+	resp, err := client.ExportFundingBundle(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var ListPeersCommand = cli.Command{
 	Name:        "listpeers",
 	Description: "List all active, currently connected peers.",
@@ -509,6 +604,323 @@ func pendingChannels(ctx *cli.Context) error {
 	return nil
 }
 
+var EstimateTxCommand = cli.Command{
+	Name:  "estimatetx",
+	Usage: "quote the inputs and fee of a transaction paying an amount of the active asset",
+	Description: "Perform coin selection for a transaction paying the " +
+		"given amount of the active asset to the given number of " +
+		"outputs, without locking any of the selected outputs.",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "asset_amount",
+			Usage: "the amount of the active asset paid by the transaction",
+		},
+		cli.IntFlag{
+			Name:  "num_outputs",
+			Usage: "the number of outputs the asset is paid to, defaults to 1",
+		},
+		cli.IntFlag{
+			Name:  "sat_per_byte",
+			Usage: "the fee rate to quote the fee at, defaults to 10",
+		},
+	},
+	Action: estimateTx,
+}
+
+func estimateTx(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.EstimateTxRequest{
+		AssetAmount: int64(ctx.Int("asset_amount")),
+		NumOutputs:  uint32(ctx.Int("num_outputs")),
+		SatPerByte:  uint64(ctx.Int("sat_per_byte")),
+	}
+	resp, err := client.EstimateTx(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var ForwardingHistoryCommand = cli.Command{
+	Name:  "fwdinghistory",
+	Usage: "list the payments forwarded by the node, along with their fees",
+	Description: "Query the payments forwarded within the given time " +
+		"slice. The fee of a payment converted from one asset into " +
+		"another is reported as zero.",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "start_time",
+			Usage: "the unix timestamp to start the query from",
+		},
+		cli.IntFlag{
+			Name:  "end_time",
+			Usage: "the unix timestamp to end the query at, defaults to now",
+		},
+		cli.StringFlag{
+			Name:  "asset",
+			Usage: "only list payments either paid or forwarded in this asset",
+		},
+		cli.IntFlag{
+			Name:  "max_events",
+			Usage: "the max number of events to return",
+		},
+	},
+	Action: forwardingHistory,
+}
+
+func forwardingHistory(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.ForwardingHistoryRequest{
+		StartTime:    int64(ctx.Int("start_time")),
+		EndTime:      int64(ctx.Int("end_time")),
+		AssetId:      ctx.String("asset"),
+		NumMaxEvents: uint32(ctx.Int("max_events")),
+	}
+	resp, err := client.ForwardingHistory(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var ListHeldHTLCsCommand = cli.Command{
+	Name:   "listheldhtlcs",
+	Usage:  "list incoming HTLCs held until they're resolved via resolvehtlc",
+	Action: listHeldHTLCs,
+}
+
+func listHeldHTLCs(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	resp, err := client.ListHeldHTLCs(ctxb, &lnrpc.ListHeldHTLCsRequest{})
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var ResolveHTLCCommand = cli.Command{
+	Name: "resolvehtlc",
+	Description: "Resolve an incoming HTLC held as lnd was started with " +
+		"holdhtlcs, either settling it with its preimage, or failing " +
+		"it back to the remote party.",
+	Usage: "resolvehtlc --funding_txid=T --output_index=N --index=I " +
+		"(--preimage=P | --fail)",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "funding_txid",
+			Usage: "the txid of the funding transaction of the HTLC's channel",
+		},
+		cli.IntFlag{
+			Name:  "output_index",
+			Usage: "the output index of the funding output of the HTLC's channel",
+		},
+		cli.IntFlag{
+			Name:  "index",
+			Usage: "the index of the HTLC within the channel",
+		},
+		cli.StringFlag{
+			Name:  "preimage",
+			Usage: "the hex encoded preimage settling the HTLC",
+		},
+		cli.BoolFlag{
+			Name:  "fail",
+			Usage: "fail the HTLC rather than settle it",
+		},
+	},
+	Action: resolveHTLC,
+}
+
+func resolveHTLC(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	txid, err := wire.NewShaHashFromStr(ctx.String("funding_txid"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.ResolveHTLCRequest{
+		ChannelPoint: &lnrpc.ChannelPoint{
+			FundingTxid: txid[:],
+			OutputIndex: uint32(ctx.Int("output_index")),
+		},
+		Index: uint64(ctx.Int("index")),
+		Fail:  ctx.Bool("fail"),
+	}
+	if !req.Fail {
+		req.Preimage, err = hex.DecodeString(ctx.String("preimage"))
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, err := client.ResolveHTLC(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var RecoverChannelsCommand = cli.Command{
+	Name:  "recoverchannels",
+	Usage: "verify all open channels against the chain",
+	Description: "Verify the funding output of each open channel, " +
+		"reporting the channels which were recovered, along with " +
+		"those requiring manual action. The wallet must be fully " +
+		"synced.",
+	Action: recoverChannels,
+}
+
+func recoverChannels(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.RecoverChannelsRequest{}
+	resp, err := client.RecoverChannels(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var ClosedChannelsCommand = cli.Command{
+	Name:        "closedchannels",
+	Description: "list all channels which have been closed",
+	Action:      closedChannels,
+}
+
+func closedChannels(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.ClosedChannelsRequest{}
+	resp, err := client.ClosedChannels(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var LiquidityReportCommand = cli.Command{
+	Name: "liquidityreport",
+	Description: "report the combined balances of all open channels, " +
+		"grouped by peer and asset",
+	Action: liquidityReport,
+}
+
+func liquidityReport(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.LiquidityReportRequest{}
+	resp, err := client.LiquidityReport(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var AuditLogCommand = cli.Command{
+	Name:  "auditlog",
+	Usage: "list the transactions broadcast by the wallet",
+	Description: "Query the audit log of the transactions broadcast " +
+		"within the given time slice, along with the outcome of " +
+		"each broadcast.",
+	Flags: []cli.Flag{
+		cli.IntFlag{
+			Name:  "start_time",
+			Usage: "the unix timestamp to start the query from",
+		},
+		cli.IntFlag{
+			Name:  "end_time",
+			Usage: "the unix timestamp to end the query at, defaults to now",
+		},
+		cli.StringFlag{
+			Name:  "asset",
+			Usage: "only list transactions carrying this asset",
+		},
+		cli.StringFlag{
+			Name:  "funding_txid",
+			Usage: "only list transactions of the channel funded by this txid",
+		},
+		cli.IntFlag{
+			Name:  "output_index",
+			Usage: "the output index of the channel's funding output",
+		},
+		cli.StringFlag{
+			Name: "purposes",
+			Usage: "only list transactions serving one of these " +
+				"comma separated purposes: Funding, FundingAbort, " +
+				"ForceClose, CooperativeClose, Sweep",
+		},
+		cli.IntFlag{
+			Name:  "max_records",
+			Usage: "the max number of records to return",
+		},
+	},
+	Action: auditLog,
+}
+
+func auditLog(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	req := &lnrpc.AuditLogRequest{
+		StartTime:     int64(ctx.Int("start_time")),
+		EndTime:       int64(ctx.Int("end_time")),
+		AssetId:       ctx.String("asset"),
+		NumMaxRecords: uint32(ctx.Int("max_records")),
+	}
+	if ctx.String("funding_txid") != "" {
+		txid, err := wire.NewShaHashFromStr(ctx.String("funding_txid"))
+		if err != nil {
+			return err
+		}
+		req.ChannelPoint = &lnrpc.ChannelPoint{
+			FundingTxid: txid[:],
+			OutputIndex: uint32(ctx.Int("output_index")),
+		}
+	}
+	if ctx.String("purposes") != "" {
+		req.Purposes = strings.Split(ctx.String("purposes"), ",")
+	}
+	resp, err := client.QueryAuditLog(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var SendPaymentCommand = cli.Command{
 	Name:        "sendpayment",
 	Description: "send a payment over lightning",
@@ -570,6 +982,280 @@ func sendPaymentCommand(ctx *cli.Context) error {
 	return nil
 }
 
+var KeysendCommand = cli.Command{
+	Name:  "keysend",
+	Usage: "send a spontaneous payment to a node, without an invoice",
+	Description: "Pay the given amount to the node with the given " +
+		"public key. The preimage of the payment is generated " +
+		"locally, and sent to the node encrypted within the HTLC.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dest",
+			Usage: "the hex encoded public key of the payment recipient",
+		},
+		cli.IntFlag{
+			Name:  "amt",
+			Usage: "the amount to send",
+		},
+	},
+	Action: keysend,
+}
+
+func keysend(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	destPubkey, err := hex.DecodeString(ctx.String("dest"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.KeysendRequest{
+		DestPubkey: destPubkey,
+		Amt:        int64(ctx.Int("amt")),
+	}
+	resp, err := client.SendKeysend(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var SendMultiPartPaymentCommand = cli.Command{
+	Name:  "sendmpp",
+	Usage: "pay a node over several channels at once",
+	Description: "Pay the given amount of an asset to a directly " +
+		"connected node, splitting the payment across the channels " +
+		"with the node should no single channel be able to carry it.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dest",
+			Usage: "lightning address of the payment recipient",
+		},
+		cli.StringFlag{
+			Name:  "asset_id",
+			Usage: "the asset the payment is denominated in",
+		},
+		cli.IntFlag{
+			Name:  "amt",
+			Usage: "the total amount of the payment",
+		},
+		cli.StringFlag{
+			Name:  "payment_hash",
+			Usage: "the hex encoded hash all parts of the payment pay to",
+		},
+	},
+	Action: sendMultiPartPayment,
+}
+
+func sendMultiPartPayment(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	dest, err := hex.DecodeString(ctx.String("dest"))
+	if err != nil {
+		return err
+	}
+	paymentHash, err := hex.DecodeString(ctx.String("payment_hash"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.MultiPartPaymentRequest{
+		Dest:        dest,
+		AssetId:     ctx.String("asset_id"),
+		Amt:         int64(ctx.Int("amt")),
+		PaymentHash: paymentHash,
+	}
+	resp, err := client.SendMultiPartPayment(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var RebalanceCommand = cli.Command{
+	Name: "rebalance",
+	Description: "Move the given amount of an asset from our side of " +
+		"one channel to our side of another by paying ourselves " +
+		"through the network. The fees of the forwarding nodes are " +
+		"paid on top of the amount.",
+	Usage: "rebalance --asset_id=A --amt=N --from_txid=T --from_index=I " +
+		"--to_txid=T --to_index=I",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "asset_id",
+			Usage: "the asset both channels are denominated in",
+		},
+		cli.IntFlag{
+			Name:  "amt",
+			Usage: "the amount to shift",
+		},
+		cli.StringFlag{
+			Name:  "from_txid",
+			Usage: "the txid of the funding transaction of the channel to shift funds from",
+		},
+		cli.IntFlag{
+			Name:  "from_index",
+			Usage: "the output index of the funding output of the channel to shift funds from",
+		},
+		cli.StringFlag{
+			Name:  "to_txid",
+			Usage: "the txid of the funding transaction of the channel to shift funds to",
+		},
+		cli.IntFlag{
+			Name:  "to_index",
+			Usage: "the output index of the funding output of the channel to shift funds to",
+		},
+	},
+	Action: rebalance,
+}
+
+func rebalance(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	fromTxid, err := wire.NewShaHashFromStr(ctx.String("from_txid"))
+	if err != nil {
+		return err
+	}
+	toTxid, err := wire.NewShaHashFromStr(ctx.String("to_txid"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.RebalanceRequest{
+		AssetId: ctx.String("asset_id"),
+		Amt:     int64(ctx.Int("amt")),
+		FromChan: &lnrpc.ChannelPoint{
+			FundingTxid: fromTxid[:],
+			OutputIndex: uint32(ctx.Int("from_index")),
+		},
+		ToChan: &lnrpc.ChannelPoint{
+			FundingTxid: toTxid[:],
+			OutputIndex: uint32(ctx.Int("to_index")),
+		},
+	}
+	resp, err := client.Rebalance(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var PayBTCInvoiceCommand = cli.Command{
+	Name:  "paybtcinvoice",
+	Usage: "pay satoshis to a node from our channels of an asset",
+	Description: "Pay the given number of satoshis to a node, locked " +
+		"to the given payment hash, from our channels of an asset. " +
+		"The asset is converted into bitcoin at the asset's " +
+		"designated conversion node.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "asset_id",
+			Usage: "the asset the payment is paid from",
+		},
+		cli.StringFlag{
+			Name:  "dest",
+			Usage: "lightning address of the payment recipient",
+		},
+		cli.StringFlag{
+			Name:  "payment_hash",
+			Usage: "the hex encoded payment hash of the invoice",
+		},
+		cli.IntFlag{
+			Name:  "amt",
+			Usage: "the number of satoshis to pay",
+		},
+	},
+	Action: payBTCInvoice,
+}
+
+func payBTCInvoice(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	dest, err := hex.DecodeString(ctx.String("dest"))
+	if err != nil {
+		return err
+	}
+	paymentHash, err := hex.DecodeString(ctx.String("payment_hash"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.PayBTCInvoiceRequest{
+		AssetId:     ctx.String("asset_id"),
+		Dest:        dest,
+		PaymentHash: paymentHash,
+		Amt:         int64(ctx.Int("amt")),
+	}
+	resp, err := client.PayBTCInvoice(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
+var ProbeRouteCommand = cli.Command{
+	Name:  "proberoute",
+	Usage: "test whether a payment may currently be made, without making it",
+	Description: "Send an HTLC paying to an unknown hash along a route " +
+		"to the given node, reporting the route if the HTLC reached " +
+		"the node.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "dest",
+			Usage: "lightning address of the node to probe",
+		},
+		cli.StringFlag{
+			Name:  "asset_id",
+			Usage: "the asset the probe is denominated in",
+		},
+		cli.IntFlag{
+			Name:  "amt",
+			Usage: "the amount to probe the route for",
+		},
+	},
+	Action: probeRoute,
+}
+
+func probeRoute(ctx *cli.Context) error {
+	ctxb := context.Background()
+	client := getClient(ctx)
+
+	dest, err := hex.DecodeString(ctx.String("dest"))
+	if err != nil {
+		return err
+	}
+
+	req := &lnrpc.ProbeRouteRequest{
+		Dest:    dest,
+		AssetId: ctx.String("asset_id"),
+		Amt:     int64(ctx.Int("amt")),
+	}
+	resp, err := client.ProbeRoute(ctxb, req)
+	if err != nil {
+		return err
+	}
+
+	printRespJson(resp)
+
+	return nil
+}
+
 var ShowRoutingTableCommand = cli.Command{
 	Name:        "showroutingtable",
 	Description: "shows routing table for a node",
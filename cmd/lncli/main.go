@@ -62,6 +62,21 @@ func main() {
 		PendingChannelsCommand,
 		SendPaymentCommand,
 		ShowRoutingTableCommand,
+		ListHeldHTLCsCommand,
+		ResolveHTLCCommand,
+		ForwardingHistoryCommand,
+		EstimateTxCommand,
+		KeysendCommand,
+		SendMultiPartPaymentCommand,
+		RebalanceCommand,
+		RecoverChannelsCommand,
+		PayBTCInvoiceCommand,
+		ProbeRouteCommand,
+		AbortFundingCommand,
+		ClosedChannelsCommand,
+		LiquidityReportCommand,
+		AuditLogCommand,
+		ExportFundingBundleCommand,
 	}
 
 	if err := app.Run(os.Args); err != nil {
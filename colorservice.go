@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcrpcclient"
+	"github.com/roasbeef/btcutil"
+)
+
+// mockColorService is an in-process stand-in for the cc-encoding-api and
+// cc-txo-color services relied upon by the lnd nodes within the integration
+// test network. It serves the local kernel of the lndcc package over http,
+// so transfer instructions are encoded deterministically, and the color of an
+// output is resolved by replaying the transfers of the transactions known to
+// the harness' btcd node, starting from the outputs explicitly issued by the
+// harness.
+type mockColorService struct {
+	*lndcc.LocalKernel
+
+	// assetID is the asset carried by all outputs issued by the harness.
+	assetID string
+
+	server *httptest.Server
+}
+
+// newMockColorService creates and starts a new mock color service issuing
+// outputs of the passed asset, which resolves transactions using the passed
+// rpc client. The backing btcd node must maintain a transaction index in
+// order for confirmed transactions to be found.
+func newMockColorService(chain *btcrpcclient.Client,
+	assetID string) *mockColorService {
+
+	m := &mockColorService{
+		LocalKernel: lndcc.NewLocalKernel(&rpcTxSource{chain}, assetID),
+		assetID:     assetID,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/encode", m.handleEncode)
+	mux.HandleFunc("/txo/", m.handleTxo)
+	m.server = httptest.NewServer(mux)
+
+	return m
+}
+
+// Env returns the environment variables which point an lnd process towards
+// the mock color service, and the asset it issues.
+func (m *mockColorService) Env() []string {
+	return []string{
+		fmt.Sprintf("CC_ENCODING_URL=%v", m.server.URL),
+		fmt.Sprintf("CC_TXO_URL=%v/txo", m.server.URL),
+		fmt.Sprintf("CC_ASSET_ID=%v", m.assetID),
+	}
+}
+
+// Close shuts down the mock color service.
+func (m *mockColorService) Close() {
+	m.server.Close()
+}
+
+// handleEncode serves requests to encode a list of transfer instructions.
+func (m *mockColorService) handleEncode(w http.ResponseWriter, r *http.Request) {
+	var insts []lndcc.Instruction
+	if err := json.NewDecoder(r.Body).Decode(&insts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payload, err := lndcc.EncodeTransfer(insts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write(payload)
+}
+
+// handleTxo serves requests for the color of an output, identified by a path
+// of the form /txo/<txid>/<index>.
+func (m *mockColorService) handleTxo(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/txo/"), "/")
+	if len(parts) != 2 {
+		http.Error(w, "malformed outpoint", http.StatusBadRequest)
+		return
+	}
+	txid, err := wire.NewShaHashFromStr(parts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	index, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	txo, err := m.TxoData(wire.OutPoint{Hash: *txid, Index: uint32(index)})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(txo)
+}
+
+// rpcTxSource fetches transactions for the local kernel over the rpc
+// interface of a btcd node.
+type rpcTxSource struct {
+	client *btcrpcclient.Client
+}
+
+// GetTransaction returns the full transaction identified by the passed
+// transaction ID.
+//
+// This is part of the lndcc.TxSource interface.
+func (r *rpcTxSource) GetTransaction(txid *wire.ShaHash) (*wire.MsgTx, error) {
+	tx, err := r.client.GetRawTransaction(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	return tx.MsgTx(), nil
+}
+
+// newTrustLocalKernel creates a local kernel issuing outputs of the passed
+// asset, which is used in place of the external colored coins services when
+// running with the trustlocalcolor option. Each issuance is of the form
+// <txid>:<index>:<amount>, marking the output as carrying amount units of the
+// asset. As the issuances are taken on trust, this is only permitted on the
+// simulation test network.
+func newTrustLocalKernel(chain lndcc.TxSource, assetID string,
+	issuances []string) (*lndcc.LocalKernel, error) {
+
+	if assetID == "" {
+		return nil, fmt.Errorf("CC_ASSET_ID must be set to issue " +
+			"local colored outputs")
+	}
+
+	kernel := lndcc.NewLocalKernel(chain, assetID)
+	for _, issuance := range issuances {
+		parts := strings.Split(issuance, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid color issuance %q, must "+
+				"be of the form <txid>:<index>:<amount>", issuance)
+		}
+
+		txid, err := wire.NewShaHashFromStr(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid color issuance %q: %v",
+				issuance, err)
+		}
+		index, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid color issuance %q: %v",
+				issuance, err)
+		}
+		amt, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil || amt <= 0 {
+			return nil, fmt.Errorf("invalid color issuance %q, amount "+
+				"must be a positive integer", issuance)
+		}
+
+		op := wire.OutPoint{Hash: *txid, Index: uint32(index)}
+		kernel.Issue(op, btcutil.Amount(amt))
+	}
+
+	return kernel, nil
+}
@@ -7,8 +7,10 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	flags "github.com/btcsuite/go-flags"
+	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/roasbeef/btcutil"
 )
 
@@ -25,6 +27,10 @@ const (
 	defaultRPCUser        = "user"
 	defaultRPCPass        = "passwd"
 	defaultSPVHostAdr     = "localhost:18333"
+
+	defaultMaxPeerPendingChannels = 2
+	defaultMaxPendingChannels     = 50
+	defaultMaxQueuedChannels      = 20
 )
 
 var (
@@ -53,8 +59,12 @@ type config struct {
 
 	DebugLevel string `short:"d" long:"debuglevel" description:"Logging level for all subsystems {trace, debug, info, warn, error, critical} -- You may also specify <subsystem>=<level>,<subsystem2>=<level>,... to set the log level for individual subsystems -- Use show to list available subsystems"`
 
+	DumpTxns bool `long:"dumptxns" description:"Dump raw transactions in full within the log -- expensive for commitment transactions carrying many HTLCs, so by default transactions are logged as a single line summary of their txid, outputs, and asset total"`
+
 	Profile string `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 
+	MetricsListen string `long:"metricslisten" description:"If set, serve metrics in the Prometheus text format at /metrics on the given interface/port"`
+
 	PeerPort int    `long:"peerport" description:"The port to listen on for incoming p2p connections"`
 	RPCPort  int    `long:"rpcport" description:"The port for the rpc server"`
 	SPVMode  bool   `long:"spv" description:"assert to enter spv wallet mode"`
@@ -68,6 +78,84 @@ type config struct {
 	TestNet3   bool   `long:"testnet" description:"Use the test network"`
 	SimNet     bool   `long:"simnet" description:"Use the simulation test network"`
 	SegNet     bool   `long:"segnet" description:"Use the segragated witness test network"`
+
+	MinCsvDelay uint32 `long:"mincsvdelay" description:"The minimum CSV delay, in blocks, accepted for either side of a channel"`
+	MaxCsvDelay uint32 `long:"maxcsvdelay" description:"The maximum CSV delay, in blocks, accepted for either side of a channel"`
+
+	SwapRates         []string `long:"swaprate" description:"Add an exchange rate used to forward HTLCs between channels of different assets, of the form <from_asset>:<to_asset>:<rate> -- BTC denotes plain bitcoin"`
+	AssetDivisibility []string `long:"assetdivisibility" description:"Set the divisibility of an asset, of the form <asset>:<decimal_places> -- swap rates between assets with a known divisibility are quoted per whole unit rather than per base unit"`
+	RoundingPolicy    string   `long:"roundingpolicy" description:"How fractional amounts of an asset resulting from a swap are rounded to whole base units {rounddown, accumulate} -- rounddown keeps the remainder of each swap, while accumulate forwards the remainders once they add up to a whole base unit"`
+
+	ConversionNodes       []string `long:"conversionnode" description:"Designate a node through which invoices denominated in BTC are paid from our channels of an asset, of the form <node_pubkey>:<asset>:<rate> -- rate is the number of satoshis the node quotes for each unit of the asset"`
+	MaxConversionSlippage uint32   `long:"maxconversionslippage" description:"The allowance, in millionths of the asset amount quoted, paid on top of a conversion in case the conversion node's rate has moved -- any allowance not consumed is kept by the conversion node"`
+
+	FeeBase    int64  `long:"feebase" description:"The fixed fee, denominated in the channel's asset, advertised for forwarding an HTLC over our channels"`
+	FeeRate    uint32 `long:"feerate" description:"The proportional fee, in millionths of the forwarded amount, advertised for forwarding an HTLC over our channels"`
+	CarrierFee int64  `long:"carrierfee" description:"The fee, in satoshis, advertised for forwarding an HTLC over our colored channels to cover the dust output carrying the asset"`
+
+	TimeLockDelta uint32 `long:"timelockdelta" description:"The minimum number of blocks, advertised for our channels, required between the expiry of an incoming HTLC and the expiry of the HTLC it's forwarded as"`
+
+	MaxRevocationWindow int `long:"maxrevocationwindow" description:"The maximum number of revocations held for a peer's commitment chain within each channel, bounding the memory a peer can consume by extending its revocation window"`
+
+	CloseCarrierAmount    int64 `long:"closecarrier" description:"The value, in satoshis, given to each colored output of a cooperative close transaction -- must match the value used by the remote peer"`
+	CloseFoldThreshold    int64 `long:"closefoldthreshold" description:"Asset balances below this amount are folded into the larger balance of the other party when cooperatively closing a channel, rather than given an output of their own -- must match the value used by the remote peer"`
+	CloseCompensationRate int64 `long:"closecompensation" description:"The number of satoshis paid, per unit of asset, to a party whose balance is folded when cooperatively closing a channel -- must match the value used by the remote peer"`
+
+	MaxChanCapacity      int64    `long:"maxchancapacity" description:"The largest capacity, in units of the channel's asset, of any channel we'll open or accept -- overridden for an asset by assetmaxchancapacity"`
+	AssetMaxChanCapacity []string `long:"assetmaxchancapacity" description:"Set the largest capacity of channels denominated in an asset, of the form <asset_id>:<amount> -- an amount of 0 lifts the bound for the asset"`
+	Wumbo                bool     `long:"wumbo" description:"Lift the maxchancapacity bound, allowing channels of any capacity for assets without an assetmaxchancapacity"`
+
+	AllowedAssets []string `long:"allowasset" description:"Only open, accept, and forward over channels denominated in this asset -- may be given multiple times, by default all assets are allowed"`
+	DeniedAssets  []string `long:"denyasset" description:"Never open, accept, or forward over channels denominated in this asset -- may be given multiple times, takes precedence over allowasset"`
+
+	MaxPeerPendingChannels int `long:"maxpeerpendingchannels" description:"The maximum number of channels a single peer may have pending with us at once, further requests being queued until one of its pending channels is opened"`
+	MaxPendingChannels     int `long:"maxpendingchannels" description:"The maximum number of channels pending with us across all peers at once, further requests being queued until a pending channel is opened"`
+	MaxQueuedChannels      int `long:"maxqueuedchannels" description:"The maximum number of channel requests queued across all peers while waiting for a pending channel to be opened, further requests being rejected"`
+
+	ReadOnly bool `long:"readonly" description:"Start the wallet in read-only mode, refusing to sign or broadcast any transaction while still serving balance queries, channel snapshots, and watching the chain -- useful while investigating a suspected breach, or when running an auditor"`
+
+	ChannelAccount string `long:"channelaccount" description:"The name of a wallet account dedicated to the keys, change, and sweeps of channels, separating channel collateral from general funds. The account is created if it doesn't exist, and renamed should the name later change. By default, channel funds share the default account"`
+
+	ShaChain bool `long:"shachain" description:"Propose deriving the revocation secrets of new channels from a BOLT shachain rather than an elkrem, easing interop with standard lnd peers. The shachain is only used if the remote peer supports it"`
+
+	PeerUpdateRate    float64       `long:"peerupdaterate" description:"The number of channel updates per second a peer may send us across all of its channels -- 0 disables the limit"`
+	PeerUpdateBurst   int           `long:"peerupdateburst" description:"The number of channel updates a peer may send us in a burst beyond its update rate"`
+	MaxPendingCommits int           `long:"maxpendingcommits" description:"The number of commitment signatures sent by a peer which may await processing at once -- 0 disables the limit"`
+	RateLimitPenalty  string        `long:"ratelimitpenalty" description:"The penalty applied to a peer exceeding its update limits: ignore stops reading from the peer for the ignore period, close force closes the channel targeted by the offending update"`
+	PeerIgnorePeriod  time.Duration `long:"peerignoreperiod" description:"The period during which we stop reading from a peer exceeding its update limits, if the ratelimitpenalty is ignore"`
+
+	LowFuelThreshold int64 `long:"lowfuelthreshold" description:"Warn once the satoshis held within uncolored outputs, used to pay for the carrier outputs and fees of colored transactions, fall below this amount"`
+
+	ParallelCommitments bool `long:"parallelcommitments" description:"When responding to a new commitment from a peer, construct and colorify both new commitments concurrently, reducing the latency of each round trip when the color encoder is the bottleneck"`
+
+	TrustLocalColor bool     `long:"trustlocalcolor" description:"Derive the color of outputs from an embedded kernel replaying the transfers since the issuances given with colorissue, rather than from the colored coins services -- only permitted on simnet"`
+	ColorIssuances  []string `long:"colorissue" description:"Add an output issued the asset given by CC_ASSET_ID when running with trustlocalcolor, of the form <txid>:<index>:<amount>"`
+
+	Watchtowers []string `long:"watchtower" description:"Add the URL of a watchtower to back up justice transactions for revoked channel states to"`
+	TowerListen string   `long:"towerlisten" description:"If set, run a watchtower server on behalf of other nodes, accepting backups on the given interface/port"`
+	TowerQuota  uint32   `long:"towerquota" description:"The maximum number of justice transactions the watchtower server stores for a single client"`
+
+	AutoForceClose  bool          `long:"autoforceclose" description:"Automatically force close channels with HTLCs outstanding once their peer has been disconnected for the grace period -- if disabled, such channels are only logged"`
+	ForceCloseGrace time.Duration `long:"forceclosegrace" description:"The period a peer may remain disconnected while HTLCs are outstanding within our channels with it before they're force closed"`
+	MaxHTLCExposure []string      `long:"maxhtlcexposure" description:"Force close the channels of a disconnected peer without waiting out the grace period if their outstanding HTLCs exceed this value, of the form <asset_id>:<amount>"`
+
+	PeerBackup bool `long:"peerbackup" description:"Exchange encrypted backups of channel state with peers, storing theirs and requesting ours back after losing local state -- a peer may then force close any channel it claims to have lost"`
+
+	HoldHTLCs bool `long:"holdhtlcs" description:"Hold incoming HTLCs which neither pay to one of our invoices, nor are forwarded, until they're settled or failed via resolvehtlc -- enabling swaps where the preimage is only obtained after some external event. Held HTLCs must be resolved before they expire"`
+
+	ChanEventURL string `long:"chaneventurl" description:"If set, post each channel event -- the opening, closure or breach of a channel, the settlement of an HTLC, and the confirmation of funding change -- as JSON to the given URL"`
+
+	CompactDB           bool   `long:"compactdb" description:"Prune the records of closed channels from the channel database on startup, then compact it, reclaiming the space freed"`
+	RevocationRetention uint64 `long:"revocationretention" description:"When compacting the channel database, the number of past states of each open channel whose revocation data is retained -- states pruned can no longer be punished if broadcast, so 0 retains all of them"`
+
+	RequireFundingProof bool `long:"requirefundingproof" description:"Reject inbound single funder channels unless the initiator presents a valid SPV proof of the funding transaction's confirmation -- if disabled, invalid proofs are only logged"`
+
+	ZeroConfPeers   []string `long:"zeroconfpeer" description:"The hex encoded identity public key of a peer whose inbound channels are usable as soon as their funding transaction is broadcast, once it has been verified within the mempool"`
+	ZeroConfTimeout uint32   `long:"zeroconftimeout" description:"The number of blocks within which the funding transaction of a zero-conf channel must confirm, before the channel is force closed"`
+
+	ReplicateTo    string `long:"replicateto" description:"Replicate each committed channel state to a cold standby node, of the form <pubkey>@<host:port> -- revocations are withheld from peers until the standby has acknowledged the new state"`
+	StandbyListen  string `long:"standbylisten" description:"If set, act as a cold standby, accepting the replicated channel state of the node given by standbyprimary on the given interface/port"`
+	StandbyPrimary string `long:"standbyprimary" description:"The hex encoded identity public key of the node whose channel state is accepted when acting as a standby"`
 }
 
 // loadConfig initializes and parses the config using a config file and command
@@ -92,6 +180,36 @@ func loadConfig() (*config, error) {
 		RPCPass:    defaultRPCPass,
 		RPCCert:    defaultRPCCertFile,
 		SPVHostAdr: defaultSPVHostAdr,
+
+		MinCsvDelay: lnwallet.DefaultMinCsvDelay,
+		MaxCsvDelay: lnwallet.DefaultMaxCsvDelay,
+
+		MaxRevocationWindow: lnwallet.DefaultMaxRevocationWindow,
+
+		RoundingPolicy: lnwallet.RoundDown.String(),
+
+		CloseCarrierAmount: lnwallet.DefaultCloseCarrierAmount,
+
+		MaxChanCapacity: lnwallet.DefaultMaxChanCapacity,
+
+		TimeLockDelta: defaultTimeLockDelta,
+
+		MaxConversionSlippage: defaultMaxConversionSlippage,
+
+		ZeroConfTimeout: defaultZeroConfTimeout,
+
+		MaxPeerPendingChannels: defaultMaxPeerPendingChannels,
+		MaxPendingChannels:     defaultMaxPendingChannels,
+		MaxQueuedChannels:      defaultMaxQueuedChannels,
+
+		LowFuelThreshold: lnwallet.DefaultLowFuelThreshold,
+		ForceCloseGrace:  defaultForceCloseGrace,
+
+		PeerUpdateRate:    defaultPeerUpdateRate,
+		PeerUpdateBurst:   defaultPeerUpdateBurst,
+		MaxPendingCommits: defaultMaxPendingCommits,
+		RateLimitPenalty:  penaltyIgnore.String(),
+		PeerIgnorePeriod:  defaultPeerIgnorePeriod,
 	}
 
 	// Pre-parse the command line options to pick up an alternative config
@@ -172,6 +290,153 @@ func loadConfig() (*config, error) {
 		}
 	}
 
+	// The advertised forwarding fees can't be negative.
+	if cfg.FeeBase < 0 || cfg.CarrierFee < 0 {
+		str := "%s: The feebase and carrierfee options can't be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Forwarded HTLCs must expire strictly before the incoming HTLC, so
+	// that the incoming HTLC can still be claimed once the outgoing HTLC
+	// is settled.
+	if cfg.TimeLockDelta == 0 {
+		str := "%s: The timelockdelta option must be positive"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// The embedded color kernel takes its issuances on trust, so it must
+	// never be relied upon where the outputs hold any real value.
+	if cfg.TrustLocalColor && !cfg.SimNet {
+		str := "%s: The trustlocalcolor option may only be used on simnet"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if len(cfg.ColorIssuances) != 0 && !cfg.TrustLocalColor {
+		str := "%s: The colorissue option requires trustlocalcolor"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Our peers extend their revocation windows to the initial size upon
+	// each connection, so anything smaller would reject them outright.
+	if cfg.MaxRevocationWindow < lnwallet.InitialRevocationWindow {
+		str := "%s: The maxrevocationwindow option must be at least %d"
+		err := fmt.Errorf(str, funcName,
+			lnwallet.InitialRevocationWindow)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	if _, err := lnwallet.ParseRoundingPolicy(cfg.RoundingPolicy); err != nil {
+		str := "%s: Invalid rounding policy: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// Outputs of the cooperative close transaction must remain relayable.
+	closePolicy := cfg.closePolicy()
+	if err := closePolicy.Validate(); err != nil {
+		str := "%s: Invalid cooperative close policy: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	if cfg.MaxChanCapacity < 0 {
+		str := "%s: The maxchancapacity option must not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if _, err := cfg.assetMaxChanCapacity(); err != nil {
+		str := "%s: Invalid assetmaxchancapacity option: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	// An asset both allowed and denied is most likely a typo, as the
+	// denylist would silently win.
+	allowedAssets := cfg.allowedAssets()
+	for _, assetID := range cfg.DeniedAssets {
+		if _, ok := allowedAssets[assetID]; ok {
+			str := "%s: Asset %q is given to both the allowasset " +
+				"and denyasset options"
+			err := fmt.Errorf(str, funcName, assetID)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
+	// Without room for at least a single pending channel, every channel
+	// request would be queued forever.
+	if cfg.MaxPeerPendingChannels < 1 || cfg.MaxPendingChannels < 1 {
+		str := "%s: The maxpeerpendingchannels and maxpendingchannels " +
+			"options must be positive"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.MaxQueuedChannels < 0 {
+		str := "%s: The maxqueuedchannels option must not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.LowFuelThreshold < 0 {
+		str := "%s: The lowfuelthreshold option must not be negative"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+
+	if cfg.ReplicateTo != "" {
+		if _, _, err := parseReplicaAddr(cfg.ReplicateTo); err != nil {
+			str := "%s: Invalid replicateto option: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+	if (cfg.StandbyListen == "") != (cfg.StandbyPrimary == "") {
+		str := "%s: The standbylisten and standbyprimary options must " +
+			"be set together"
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, err
+	}
+	if cfg.StandbyPrimary != "" {
+		if _, err := parseReplicaPubKey(cfg.StandbyPrimary); err != nil {
+			str := "%s: Invalid standbyprimary option: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, err
+		}
+	}
+
 	// Append the network type to the data directory so it is "namespaced"
 	// per network. In addition to the block database, there are other
 	// pieces of data that are saved to disk such as address manager state.
@@ -298,3 +563,72 @@ func supportedSubsystems() []string {
 	sort.Strings(subsystems)
 	return subsystems
 }
+
+// closePolicy returns the policy determining the outputs of the cooperative
+// close transactions of our channels.
+func (c *config) closePolicy() lnwallet.CloseOutputPolicy {
+	return lnwallet.CloseOutputPolicy{
+		CarrierAmount:        btcutil.Amount(c.CloseCarrierAmount),
+		FoldThreshold:        btcutil.Amount(c.CloseFoldThreshold),
+		FoldCompensationRate: btcutil.Amount(c.CloseCompensationRate),
+	}
+}
+
+// maxChanCapacity returns the largest capacity of any channel we'll open or
+// accept, unless overridden for its asset, or zero if the wumbo option lifts
+// the bound.
+func (c *config) maxChanCapacity() btcutil.Amount {
+	if c.Wumbo {
+		return 0
+	}
+
+	return btcutil.Amount(c.MaxChanCapacity)
+}
+
+// assetMaxChanCapacity parses the largest capacity of channels denominated in
+// each asset given by the assetmaxchancapacity option, keyed by asset ID.
+func (c *config) assetMaxChanCapacity() (map[string]btcutil.Amount, error) {
+	capacities := make(map[string]btcutil.Amount)
+	for _, maxCapacity := range c.AssetMaxChanCapacity {
+		parts := strings.Split(maxCapacity, ":")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid max channel capacity %q, "+
+				"must be of the form <asset_id>:<amount>",
+				maxCapacity)
+		}
+
+		amt, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max channel capacity "+
+				"%q: %v", maxCapacity, err)
+		}
+		if amt < 0 {
+			return nil, fmt.Errorf("invalid max channel capacity "+
+				"%q, amount cannot be negative", maxCapacity)
+		}
+
+		capacities[parts[0]] = btcutil.Amount(amt)
+	}
+
+	return capacities, nil
+}
+
+// allowedAssets returns the set of assets given by the allowasset option.
+func (c *config) allowedAssets() map[string]struct{} {
+	return assetSet(c.AllowedAssets)
+}
+
+// deniedAssets returns the set of assets given by the denyasset option.
+func (c *config) deniedAssets() map[string]struct{} {
+	return assetSet(c.DeniedAssets)
+}
+
+// assetSet returns the set of the passed asset IDs.
+func assetSet(assetIDs []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(assetIDs))
+	for _, assetID := range assetIDs {
+		set[assetID] = struct{}{}
+	}
+
+	return set
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcutil"
+)
+
+// defaultMaxConversionSlippage is the default allowance, in millionths of the
+// asset amount quoted, paid on top of a conversion in case the conversion
+// node's rate has moved against us since it was quoted.
+const defaultMaxConversionSlippage = 10000
+
+// conversionNode is a node designated to swap an asset for bitcoin when we
+// pay an invoice denominated in satoshis from our channels of the asset.
+type conversionNode struct {
+	// node is the lightning ID of the conversion node.
+	node router.NodeID
+
+	// assetID is the asset swapped for bitcoin, as identified within the
+	// channel graph.
+	assetID string
+
+	// rate is the number of satoshis the node forwards for each base unit
+	// of the asset received, as quoted by the node.
+	rate float64
+}
+
+// conversionPolicy dictates how invoices denominated in satoshis are paid
+// from our channels of other assets.
+type conversionPolicy struct {
+	// nodes maps each asset to the node designated to swap it for
+	// bitcoin.
+	nodes map[string]*conversionNode
+
+	// maxSlippage is the allowance, in millionths of the asset amount
+	// quoted, paid on top of each conversion. Any allowance not consumed
+	// by a move in the node's rate is kept by the node.
+	maxSlippage uint32
+}
+
+// newConversionPolicy creates a new conversionPolicy from the passed set of
+// conversion nodes. Each node is of the form: <node_pubkey>:<asset>:<rate>,
+// where rate is the number of satoshis the node forwards for each base unit
+// of asset received.
+func newConversionPolicy(nodes []string,
+	maxSlippage uint32) (*conversionPolicy, error) {
+
+	if maxSlippage >= 1000000 {
+		return nil, fmt.Errorf("conversion slippage of %v millionths "+
+			"must be below 1000000", maxSlippage)
+	}
+
+	p := &conversionPolicy{
+		nodes:       make(map[string]*conversionNode),
+		maxSlippage: maxSlippage,
+	}
+	for _, node := range nodes {
+		parts := strings.Split(node, ":")
+		if len(parts) != 3 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid conversion node %q, must "+
+				"be of the form <node_pubkey>:<asset>:<rate>",
+				node)
+		}
+
+		pubKey, err := parseReplicaPubKey(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid conversion node %q: %v",
+				node, err)
+		}
+
+		assetID := graphAssetID(parts[1])
+		if assetID == "" {
+			return nil, fmt.Errorf("invalid conversion node %q, "+
+				"bitcoin needs no conversion", node)
+		}
+		if _, ok := p.nodes[assetID]; ok {
+			return nil, fmt.Errorf("duplicate conversion node for "+
+				"asset %q", parts[1])
+		}
+
+		rate, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || rate <= 0 || math.IsInf(rate, 0) {
+			return nil, fmt.Errorf("invalid conversion node %q, "+
+				"rate must be positive", node)
+		}
+
+		p.nodes[assetID] = &conversionNode{
+			node:    fastsha256.Sum256(pubKey.SerializeCompressed()),
+			assetID: assetID,
+			rate:    rate,
+		}
+	}
+
+	return p, nil
+}
+
+// withSlippage returns the passed asset amount along with the policy's
+// slippage allowance, rounded up.
+func (p *conversionPolicy) withSlippage(amt btcutil.Amount) btcutil.Amount {
+	allowance := (amt*btcutil.Amount(p.maxSlippage) + 999999) / 1000000
+	return amt + allowance
+}
+
+// conversionQuote is the route, and cost, of paying an invoice denominated in
+// satoshis from our channels of an asset.
+type conversionQuote struct {
+	// node is the conversion node crossed by the route.
+	node *conversionNode
+
+	// route carries the asset from us to the conversion node, which
+	// forwards satoshis along the remainder of the route.
+	route *router.Route
+
+	// convAmt is the amount of the asset delivered to the conversion
+	// node, of which allowance covers slippage in the node's rate.
+	convAmt   btcutil.Amount
+	allowance btcutil.Amount
+
+	// sendAmt is the amount of the asset we send over the first hop of
+	// the route, including the fees charged by each forwarding node.
+	sendAmt btcutil.Amount
+}
+
+// quoteConversion finds a route paying amt satoshis to dest from our channels
+// of the passed asset, crossing the asset's designated conversion node. The
+// satoshis forwarded by the conversion node, including the fees charged by
+// each following node, are converted into the asset at the node's quoted
+// rate, and the policy's slippage allowance is added on top.
+func (s *server) quoteConversion(assetID string, dest router.NodeID,
+	amt btcutil.Amount) (*conversionQuote, error) {
+
+	if amt <= 0 {
+		return nil, fmt.Errorf("invalid payment amount %v", amt)
+	}
+	if assetID == "" {
+		return nil, fmt.Errorf("payments in bitcoin need no conversion")
+	}
+
+	conv, ok := s.conversion.nodes[assetID]
+	if !ok {
+		return nil, fmt.Errorf("no conversion node for asset %q",
+			assetID)
+	}
+	self := router.NodeID(s.lightningID)
+	if conv.node == self || conv.node == dest {
+		return nil, fmt.Errorf("conversion node can't be an endpoint " +
+			"of the payment")
+	}
+
+	// The conversion node must receive enough of the asset to forward the
+	// satoshis onwards, along with the fee it charges for its bitcoin
+	// channel.
+	btcRoute, err := s.chanGraph.FindRoute(conv.node, dest, "", "", amt)
+	if err != nil {
+		return nil, err
+	}
+	first := btcRoute.Hops[0]
+	btcIn := first.Amount + first.Channel.Fee(first.Amount)
+
+	// The conversion node rounds down when converting, so the amount of
+	// the asset it must receive is rounded up.
+	needed := math.Ceil(float64(btcIn) / conv.rate)
+	if needed > math.MaxInt64/2 {
+		return nil, fmt.Errorf("%v satoshis worth too much of asset %q",
+			btcIn, assetID)
+	}
+	convAmt := s.conversion.withSlippage(btcutil.Amount(needed))
+
+	assetRoute, err := s.chanGraph.FindRoute(self, conv.node, assetID,
+		assetID, convAmt)
+	if err != nil {
+		return nil, err
+	}
+
+	route := &router.Route{
+		Hops: append(assetRoute.Hops, btcRoute.Hops...),
+	}
+
+	return &conversionQuote{
+		node:      conv,
+		route:     route,
+		convAmt:   convAmt,
+		allowance: convAmt - btcutil.Amount(needed),
+		sendAmt:   route.Hops[0].Amount,
+	}, nil
+}
+
+// PayBTCInvoice pays amt satoshis to dest, locked to the passed payment hash,
+// from our channels of an asset. The payment crosses the asset's designated
+// conversion node, which swaps the asset for bitcoin. The quote the payment
+// was sent under is returned once the payment has been settled.
+func (s *server) PayBTCInvoice(assetID string, dest router.NodeID,
+	paymentHash [32]byte, amt btcutil.Amount) (*conversionQuote, error) {
+
+	quote, err := s.quoteConversion(graphAssetID(assetID), dest, amt)
+	if err != nil {
+		return nil, err
+	}
+
+	height, err := s.currentHeight()
+	if err != nil {
+		return nil, err
+	}
+	route := quote.route
+	expiries := route.HopExpiries(height, finalExpiryDelta)
+	payload := newForwardPayload(route.Hops[1:], expiries[1:], nil)
+
+	srvrLog.Infof("Paying %v satoshis via conversion node %x over route "+
+		"%v, sending %v of %q (slippage allowance of %v)", amt,
+		quote.node.node[:], route, quote.sendAmt, quote.node.assetID,
+		quote.allowance)
+
+	first := route.Hops[0]
+	htlcPkt := &htlcPacket{
+		msg: &lnwire.HTLCAddRequest{
+			Expiry:           expiries[0],
+			Amount:           lnwire.CreditsAmount(first.Amount),
+			RedemptionHashes: [][32]byte{paymentHash},
+			OnionBlob:        payload,
+		},
+		outgoingChan: &first.Channel.ChanPoint,
+	}
+	if err := s.htlcSwitch.SendHTLC(htlcPkt); err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestConversionPolicy tests the parsing of designated conversion nodes.
+func TestConversionPolicy(t *testing.T) {
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	pubBytes := priv.PubKey().SerializeCompressed()
+	pubHex := hex.EncodeToString(pubBytes)
+
+	policy, err := newConversionPolicy([]string{pubHex + ":assetA:0.5"},
+		defaultMaxConversionSlippage)
+	if err != nil {
+		t.Fatalf("unable to create policy: %v", err)
+	}
+	conv, ok := policy.nodes["assetA"]
+	if !ok {
+		t.Fatalf("no conversion node for assetA")
+	}
+	if conv.node != router.NodeID(fastsha256.Sum256(pubBytes)) ||
+		conv.rate != 0.5 {
+		t.Fatalf("unexpected conversion node %v", conv)
+	}
+
+	invalid := [][]string{
+		{pubHex + ":assetA"},
+		{"zz:assetA:0.5"},
+		{pubHex + ":BTC:0.5"},
+		{pubHex + ":assetA:-1"},
+		{pubHex + ":assetA:0.5", pubHex + ":assetA:0.6"},
+	}
+	for _, nodes := range invalid {
+		_, err := newConversionPolicy(nodes, defaultMaxConversionSlippage)
+		if err == nil {
+			t.Fatalf("invalid conversion nodes %v accepted", nodes)
+		}
+	}
+	if _, err := newConversionPolicy(nil, 1000000); err == nil {
+		t.Fatalf("slippage of the entire amount accepted")
+	}
+}
+
+// TestQuoteConversion tests that a payment of satoshis is routed from our
+// channel of an asset through the asset's conversion node, which is sent
+// enough of the asset to cover the satoshis forwarded, the fees charged along
+// the way, and the slippage allowance.
+func TestQuoteConversion(t *testing.T) {
+	us, bob, dest := router.NodeID{1}, router.NodeID{3}, router.NodeID{4}
+	conv := router.NodeID{2}
+
+	// us -assetA-> conv -BTC-> bob -BTC-> dest, where conv charges a base
+	// fee of 5 satoshis, and bob charges 1%.
+	usConv := &router.ChannelEdge{
+		ChanPoint: wire.OutPoint{Index: 1},
+		Node1:     us,
+		Node2:     conv,
+		AssetID:   "assetA",
+		Capacity:  1e6,
+	}
+	convBob := &router.ChannelEdge{
+		ChanPoint: wire.OutPoint{Index: 2},
+		Node1:     conv,
+		Node2:     bob,
+		Capacity:  1e6,
+		FeeBase:   5,
+	}
+	bobDest := &router.ChannelEdge{
+		ChanPoint: wire.OutPoint{Index: 3},
+		Node1:     bob,
+		Node2:     dest,
+		Capacity:  1e6,
+		FeeRate:   10000,
+	}
+	graph := router.NewGraph()
+	for _, edge := range []*router.ChannelEdge{usConv, convBob, bobDest} {
+		if err := graph.AddChannel(edge); err != nil {
+			t.Fatalf("unable to add channel: %v", err)
+		}
+	}
+
+	s := &server{
+		chanGraph:   graph,
+		lightningID: us,
+		conversion: &conversionPolicy{
+			nodes: map[string]*conversionNode{
+				"assetA": {node: conv, assetID: "assetA", rate: 0.5},
+			},
+			maxSlippage: defaultMaxConversionSlippage,
+		},
+	}
+
+	// Bob must receive 1010 satoshis to forward 1000 to dest, so conv must
+	// forward 1010, receiving 1015 worth of assetA: 2030 units, along with
+	// a 1% allowance of 21 units.
+	quote, err := s.quoteConversion("assetA", dest, 1000)
+	if err != nil {
+		t.Fatalf("unable to quote conversion: %v", err)
+	}
+	if quote.convAmt != 2051 || quote.allowance != 21 ||
+		quote.sendAmt != 2051 {
+		t.Fatalf("expected to send 2051 with allowance of 21, got %v "+
+			"with allowance of %v", quote.sendAmt, quote.allowance)
+	}
+
+	hops := quote.route.Hops
+	if len(hops) != 3 {
+		t.Fatalf("expected 3 hops, got %v", len(hops))
+	}
+	expected := []*router.ChannelEdge{usConv, convBob, bobDest}
+	for i, hop := range hops {
+		if hop.Channel != expected[i] {
+			t.Fatalf("hop #%v: expected ChannelPoint(%v), got "+
+				"ChannelPoint(%v)", i, expected[i].ChanPoint,
+				hop.Channel.ChanPoint)
+		}
+	}
+	if hops[0].AssetID != "assetA" || hops[1].AssetID != "" ||
+		hops[1].Amount != 1010 {
+		t.Fatalf("unexpected route %v", quote.route)
+	}
+
+	// Assets without a conversion node, and bitcoin itself, can't be
+	// converted.
+	if _, err := s.quoteConversion("assetB", dest, 1000); err == nil {
+		t.Fatalf("asset without conversion node quoted")
+	}
+	if _, err := s.quoteConversion("", dest, 1000); err == nil {
+		t.Fatalf("conversion of bitcoin quoted")
+	}
+	if _, err := s.quoteConversion("assetA", conv, 1000); err == nil {
+		t.Fatalf("payment to conversion node quoted")
+	}
+}
+
+// TestConvertForward tests that HTLCs forwarded between channels of
+// different assets are converted at the switch's swap rates, with channels
+// paying satoshis mapped to bitcoin.
+func TestConvertForward(t *testing.T) {
+	rates, err := newStaticRateProvider([]string{"assetA:BTC:0.5"}, nil,
+		lnwallet.RoundDown)
+	if err != nil {
+		t.Fatalf("unable to create rate provider: %v", err)
+	}
+	s := &server{htlcSwitch: newHtlcSwitch(rates)}
+
+	amt, err := s.convertForward("assetA", "", 2030)
+	if err != nil {
+		t.Fatalf("unable to convert amount: %v", err)
+	}
+	if amt != 1015 {
+		t.Fatalf("expected 1015 satoshis, got %v", amt)
+	}
+	if _, err := s.convertForward("", "assetA", 1015); err == nil {
+		t.Fatalf("conversion without swap rate accepted")
+	}
+
+	s = &server{htlcSwitch: newHtlcSwitch(nil)}
+	if _, err := s.convertForward("assetA", "", 2030); err == nil {
+		t.Fatalf("conversion without rate provider accepted")
+	}
+}
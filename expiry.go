@@ -0,0 +1,49 @@
+package main
+
+import "fmt"
+
+const (
+	// defaultTimeLockDelta is the default number of blocks we require
+	// between the expiry of an incoming HTLC, and the expiry of the HTLC
+	// it's forwarded as. Colored HTLCs are claimed on-chain through the
+	// colored coins service in addition to the chain, so a generous delta
+	// is kept.
+	defaultTimeLockDelta = 144
+
+	// finalExpiryDelta is the number of blocks the final node of a route
+	// is given to settle an HTLC before it expires.
+	finalExpiryDelta = 9
+)
+
+// checkExpiry checks the expiry of an HTLC we're asked to forward against
+// the expiry of the incoming HTLC and the current height. The incoming HTLC
+// must outlive the outgoing HTLC by at least our time lock delta, giving us
+// enough time to claim the incoming HTLC on-chain once the outgoing HTLC is
+// settled. Otherwise, the downstream node could settle with us after we're
+// no longer able to settle upstream.
+func (f *forwardingPolicy) checkExpiry(inExpiry, outExpiry,
+	height uint32) error {
+
+	if outExpiry <= height {
+		return fmt.Errorf("outgoing expiry %v has already passed at "+
+			"height %v", outExpiry, height)
+	}
+	if inExpiry < outExpiry || inExpiry-outExpiry < f.timeLockDelta {
+		return fmt.Errorf("incoming expiry %v doesn't leave a delta "+
+			"of %v blocks over outgoing expiry %v", inExpiry,
+			f.timeLockDelta, outExpiry)
+	}
+
+	return nil
+}
+
+// currentHeight returns the height of the best block known to the chain
+// backend.
+func (s *server) currentHeight() (uint32, error) {
+	height, err := s.bio.GetCurrentHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(height), nil
+}
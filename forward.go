@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// forwardRecordType is the first byte of an HTLC payload which
+	// instructs the receiver to forward the HTLC over one of its
+	// channels.
+	forwardRecordType = 0x66
+
+	// forwardRecordHeaderSize is the size of a forwarding record without
+	// the payload of the forwarded HTLC: the record type, the outpoint of
+	// the next channel, the amount to be forwarded, then its expiry.
+	forwardRecordHeaderSize = 1 + 32 + 4 + 8 + 4
+)
+
+// errNoForwardRecord is returned when an HTLC's payload doesn't carry a
+// forwarding record.
+var errNoForwardRecord = errors.New("payload carries no forwarding record")
+
+// forwardRecord is carried within the payload of an HTLC which is to be
+// forwarded by its receiver over the next channel of its route. The HTLC
+// forwarded carries the record's payload in turn, allowing an HTLC to be
+// routed through several nodes.
+//
+// TODO: replace with the onion once the mix header is parsed.
+type forwardRecord struct {
+	// nextChan is the channel the HTLC is to be forwarded over.
+	nextChan wire.OutPoint
+
+	// amt is the amount to be forwarded. The difference between the
+	// incoming amount and amt must cover the forwarding node's fee.
+	amt btcutil.Amount
+
+	// expiry is the absolute expiry height of the forwarded HTLC. The
+	// incoming HTLC must outlive it by the forwarding node's time lock
+	// delta.
+	expiry uint32
+
+	// payload is the payload of the forwarded HTLC.
+	payload []byte
+}
+
+// encode serializes the forwarding record into an HTLC payload.
+func (r *forwardRecord) encode() []byte {
+	var b bytes.Buffer
+	b.WriteByte(forwardRecordType)
+	b.Write(r.nextChan.Hash[:])
+	binary.Write(&b, binary.BigEndian, r.nextChan.Index)
+	binary.Write(&b, binary.BigEndian, uint64(r.amt))
+	binary.Write(&b, binary.BigEndian, r.expiry)
+	b.Write(r.payload)
+
+	return b.Bytes()
+}
+
+// decodeForwardRecord parses the forwarding record within the passed HTLC
+// payload. If the payload doesn't carry a forwarding record, then
+// errNoForwardRecord is returned.
+func decodeForwardRecord(payload []byte) (*forwardRecord, error) {
+	if len(payload) < forwardRecordHeaderSize ||
+		payload[0] != forwardRecordType {
+		return nil, errNoForwardRecord
+	}
+
+	record := &forwardRecord{}
+	copy(record.nextChan.Hash[:], payload[1:33])
+	record.nextChan.Index = binary.BigEndian.Uint32(payload[33:37])
+	record.amt = btcutil.Amount(binary.BigEndian.Uint64(payload[37:45]))
+	record.expiry = binary.BigEndian.Uint32(payload[45:49])
+	if record.amt <= 0 {
+		return nil, fmt.Errorf("invalid forwarding amount %v",
+			record.amt)
+	}
+	if len(payload) > forwardRecordHeaderSize {
+		record.payload = payload[forwardRecordHeaderSize:]
+	}
+
+	return record, nil
+}
+
+// newForwardPayload returns the payload of an HTLC which, once sent over the
+// first hop of a route, is forwarded along the passed remaining hops, each
+// HTLC expiring at the corresponding height of expiries. The final node of the
+// route receives the passed final payload.
+func newForwardPayload(hops []*router.Hop, expiries []uint32,
+	final []byte) []byte {
+
+	payload := final
+	for i := len(hops) - 1; i >= 0; i-- {
+		record := &forwardRecord{
+			nextChan: hops[i].Channel.ChanPoint,
+			amt:      hops[i].Amount,
+			expiry:   expiries[i],
+			payload:  payload,
+		}
+		payload = record.encode()
+	}
+
+	return payload
+}
+
+// forwardHTLC hands a locked-in incoming HTLC carrying a forwarding record to
+// the server, which forwards it over the next channel of its route. The
+// incoming HTLC is resolved once the forwarded HTLC has been.
+func (p *peer) forwardHTLC(state *commitmentState,
+	htlc *lnwallet.PaymentDescriptor, record *forwardRecord) {
+
+	go p.server.forwardHTLC(newInterceptedHTLC(state, htlc), record)
+}
+
+// forwardHTLC forwards the incoming HTLC over the channel designated by its
+// forwarding record. If the forwarded HTLC is settled, then the incoming HTLC
+// is settled with the preimage revealed downstream, and the payment circuit
+// is recorded within the forwarding log. Otherwise, the incoming HTLC is
+// failed back.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) forwardHTLC(in *InterceptedHTLC, record *forwardRecord) {
+	outgoingAssetID, err := s.checkForward(in, record)
+	if err != nil {
+		srvrLog.Errorf("refusing to forward htlc %v of "+
+			"ChannelPoint(%v): %v", in.Index, in.ChanPoint, err)
+		if err := in.Fail(); err != nil {
+			srvrLog.Errorf("unable to fail htlc: %v", err)
+		}
+		return
+	}
+
+	htlcPkt := &htlcPacket{
+		msg: &lnwire.HTLCAddRequest{
+			Expiry:           record.expiry,
+			Amount:           lnwire.CreditsAmount(record.amt),
+			RedemptionHashes: [][32]byte{in.PaymentHash},
+			OnionBlob:        record.payload,
+		},
+		outgoingChan: &record.nextChan,
+	}
+	if err := s.htlcSwitch.SendHTLC(htlcPkt); err != nil {
+		srvrLog.Errorf("unable to forward htlc %v of ChannelPoint(%v) "+
+			"over ChannelPoint(%v): %v", in.Index, in.ChanPoint,
+			record.nextChan, err)
+
+		// The reason cited downstream is relayed upstream, allowing
+		// the sender to tell where along the route the HTLC failed.
+		reason := lnwire.FailUnspecified
+		if failErr, ok := err.(*htlcFailError); ok {
+			reason = failErr.reason
+		}
+		if err := in.failWithReason(reason); err != nil {
+			srvrLog.Errorf("unable to fail htlc: %v", err)
+		}
+		return
+	}
+
+	// The preimage revealed by the downstream settle was stored by the
+	// outgoing channel as it was received.
+	preimage, _, err := s.chanDB.LookupPreimage(in.PaymentHash)
+	if err != nil {
+		srvrLog.Errorf("unable to find preimage of forwarded htlc "+
+			"%x: %v", in.PaymentHash[:], err)
+		return
+	}
+	if err := in.Settle(preimage); err != nil {
+		srvrLog.Errorf("unable to settle htlc: %v", err)
+		return
+	}
+
+	err = s.chanDB.AddForwardingEvents([]channeldb.ForwardingEvent{{
+		Timestamp:       time.Now(),
+		IncomingChanID:  in.ChanPoint,
+		OutgoingChanID:  record.nextChan,
+		IncomingAssetID: in.AssetID,
+		OutgoingAssetID: outgoingAssetID,
+		AmtIn:           in.Amount,
+		AmtOut:          record.amt,
+	}})
+	if err != nil {
+		srvrLog.Errorf("unable to record forwarding event: %v", err)
+	}
+}
+
+// checkForward ensures the incoming HTLC may be forwarded as instructed by
+// its forwarding record: the next channel must be one of our channels, and
+// the incoming amount must cover the forwarded amount along with our fee. If
+// the next channel is denominated in another asset, then the incoming amount
+// is first converted via the switch's RateProvider, and our fee is charged in
+// the asset forwarded. The asset of the next channel is returned.
+func (s *server) checkForward(in *InterceptedHTLC,
+	record *forwardRecord) (string, error) {
+
+	edge, err := s.chanGraph.Channel(record.nextChan)
+	if err != nil {
+		return "", err
+	}
+	if edge.Node1 != s.lightningID && edge.Node2 != s.lightningID {
+		return "", fmt.Errorf("ChannelPoint(%v) isn't one of our "+
+			"channels", record.nextChan)
+	}
+	if record.nextChan == in.ChanPoint {
+		return "", fmt.Errorf("htlc can't be forwarded over the " +
+			"channel it was received on")
+	}
+	for _, assetID := range []string{in.AssetID, edge.AssetID} {
+		if !s.lnwallet.AssetAllowed(assetID) {
+			return "", fmt.Errorf("asset %q not allowed by policy",
+				assetID)
+		}
+	}
+
+	amtIn := in.Amount
+	if edge.AssetID != in.AssetID {
+		amtIn, err = s.convertForward(in.AssetID, edge.AssetID, in.Amount)
+		if err != nil {
+			return "", fmt.Errorf("can't forward htlc of asset %q "+
+				"over channel of asset %q: %v", in.AssetID,
+				edge.AssetID, err)
+		}
+	}
+
+	fee := s.policy.fee(record.amt)
+	if amtIn < record.amt+fee {
+		return "", fmt.Errorf("incoming amount %v doesn't cover "+
+			"forwarded amount %v and fee %v", amtIn, record.amt, fee)
+	}
+
+	height, err := s.currentHeight()
+	if err != nil {
+		return "", err
+	}
+	if err := s.policy.checkExpiry(in.Expiry, record.expiry, height); err != nil {
+		return "", err
+	}
+
+	return edge.AssetID, nil
+}
+
+// convertForward converts the incoming amount of an HTLC of fromAsset into
+// the amount of toAsset it's worth, at the swap rates quoted by the switch.
+// Both assets are given as they're identified within the channel graph.
+func (s *server) convertForward(fromAsset, toAsset string,
+	amt btcutil.Amount) (btcutil.Amount, error) {
+
+	if s.htlcSwitch.rates == nil {
+		return 0, fmt.Errorf("cross-asset forwarding not supported")
+	}
+
+	return s.htlcSwitch.rates.ConvertAmount(rateAssetID(fromAsset),
+		rateAssetID(toAsset), amt)
+}
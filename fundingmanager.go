@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"sync"
 	"sync/atomic"
 
@@ -33,6 +35,11 @@ type reservationWithCtx struct {
 	reservation *lnwallet.ChannelReservation
 	peer        *peer
 
+	// fsm drives the reservation through the funding workflow, ensuring
+	// each message from the remote peer is only processed at the step of
+	// the workflow it belongs to.
+	fsm *lnwallet.FundingStateMachine
+
 	updates chan *lnrpc.OpenStatusUpdate
 	err     chan error
 }
@@ -124,20 +131,36 @@ type fundingManager struct {
 	// requests from a local sub-system within the daemon.
 	fundingRequests chan *initFundingMsg
 
+	// limits bounds the number of concurrent reservations remote peers
+	// may open with us. Requests over the limits are held within
+	// queuedRequests, in the order they were received, until enough
+	// reservations have been released.
+	limits         *reservationLimits
+	queuedRequests []*fundingRequestMsg
+
+	// reservationReleased is sent upon each time a reservation is removed
+	// from the set of active reservations, signalling the
+	// reservationCoordinator to re-examine the queued requests.
+	reservationReleased chan struct{}
+
 	quit chan struct{}
 	wg   sync.WaitGroup
 }
 
 // newFundingManager creates and initializes a new instance of the
 // fundingManager.
-func newFundingManager(w *lnwallet.LightningWallet) *fundingManager {
+func newFundingManager(w *lnwallet.LightningWallet,
+	limits *reservationLimits) *fundingManager {
+
 	return &fundingManager{
-		activeReservations: make(map[int32]pendingChannels),
-		wallet:             w,
-		fundingMsgs:        make(chan interface{}, msgBufferSize),
-		fundingRequests:    make(chan *initFundingMsg, msgBufferSize),
-		queries:            make(chan interface{}, 1),
-		quit:               make(chan struct{}),
+		activeReservations:  make(map[int32]pendingChannels),
+		wallet:              w,
+		fundingMsgs:         make(chan interface{}, msgBufferSize),
+		fundingRequests:     make(chan *initFundingMsg, msgBufferSize),
+		queries:             make(chan interface{}, 1),
+		limits:              limits,
+		reservationReleased: make(chan struct{}, 1),
+		quit:                make(chan struct{}),
 	}
 }
 
@@ -194,6 +217,7 @@ type pendingChannel struct {
 	capacity      btcutil.Amount
 	localBalance  btcutil.Amount
 	remoteBalance btcutil.Amount
+	state         lnwallet.ReservationState
 }
 
 type pendingChansReq struct {
@@ -211,6 +235,34 @@ func (f *fundingManager) PendingChannels() []*pendingChannel {
 	return <-resp
 }
 
+type abortFundingReq struct {
+	chanPoint wire.OutPoint
+	resp      chan *lnwallet.ChannelReservation
+}
+
+// AbortChannelFunding cancels the pending channel with the target funding
+// outpoint, whose funding transaction has been broadcast but has yet to
+// confirm, by double spending the funding transaction's inputs back to the
+// wallet. The replacement transaction is returned upon success.
+func (f *fundingManager) AbortChannelFunding(
+	chanPoint wire.OutPoint) (*wire.MsgTx, error) {
+
+	resp := make(chan *lnwallet.ChannelReservation, 1)
+
+	req := &abortFundingReq{chanPoint, resp}
+	f.queries <- req
+
+	// The reservation is released by the goroutine awaiting its funding
+	// transaction's confirmation, once notified of the replacement.
+	res := <-resp
+	if res == nil {
+		return nil, fmt.Errorf("ChannelPoint(%v) isn't pending",
+			chanPoint)
+	}
+
+	return res.AbortFunding()
+}
+
 // reservationCoordinator is the primary goroutine tasked with progressing the
 // funding workflow between the wallet, and any outside peers or local callers.
 //
@@ -222,7 +274,7 @@ out:
 		case msg := <-f.fundingMsgs:
 			switch fmsg := msg.(type) {
 			case *fundingRequestMsg:
-				f.handleFundingRequest(fmsg)
+				f.admitFundingRequest(fmsg)
 			case *fundingResponseMsg:
 				f.handleFundingResponse(fmsg)
 			case *fundingCompleteMsg:
@@ -234,12 +286,16 @@ out:
 			}
 		case req := <-f.fundingRequests:
 			f.handleInitFundingMsg(req)
+		case <-f.reservationReleased:
+			f.processQueuedRequests()
 		case req := <-f.queries:
 			switch msg := req.(type) {
 			case *numPendingReq:
 				f.handleNumPending(msg)
 			case *pendingChansReq:
 				f.handlePendingChannels(msg)
+			case *abortFundingReq:
+				f.handleAbortFunding(msg)
 			}
 		case <-f.quit:
 			break out
@@ -277,6 +333,7 @@ func (f *fundingManager) handlePendingChannels(msg *pendingChansReq) {
 				capacity:      localFund + remoteFund,
 				localBalance:  localFund,
 				remoteBalance: remoteFund,
+				state:         res.State(),
 			}
 			pendingChannels = append(pendingChannels, pendingChan)
 		}
@@ -284,12 +341,231 @@ func (f *fundingManager) handlePendingChannels(msg *pendingChansReq) {
 	msg.resp <- pendingChannels
 }
 
+// handleAbortFunding responds to a request for the pending reservation
+// funding the target outpoint, sending nil if there is none.
+func (f *fundingManager) handleAbortFunding(msg *abortFundingReq) {
+	f.resMtx.RLock()
+	defer f.resMtx.RUnlock()
+
+	for _, peerChannels := range f.activeReservations {
+		for _, pendingChan := range peerChannels {
+			fundingPoint := pendingChan.reservation.FundingOutpoint()
+			if fundingPoint != nil && *fundingPoint == msg.chanPoint {
+				msg.resp <- pendingChan.reservation
+				return
+			}
+		}
+	}
+
+	msg.resp <- nil
+}
+
 // processFundingRequest sends a message to the fundingManager allowing it to
 // intiate the new funding workflow with the source peer.
 func (f *fundingManager) processFundingRequest(msg *lnwire.SingleFundingRequest, peer *peer) {
 	f.fundingMsgs <- &fundingRequestMsg{msg, peer}
 }
 
+// reservationLimits bounds the number of reservations remote peers may have
+// pending with us concurrently, preventing a misbehaving peer from tying up
+// our wallet's resources with an unbounded number of channel reservations.
+type reservationLimits struct {
+	// maxPerPeer is the maximum number of reservations a single peer may
+	// have pending at once.
+	maxPerPeer int
+
+	// maxTotal is the maximum number of reservations pending across all
+	// peers at once.
+	maxTotal int
+
+	// maxQueued is the maximum number of funding requests held across all
+	// peers while waiting for a reservation to be released. Requests
+	// received once the queue is full are rejected.
+	maxQueued int
+}
+
+// errTooManyPendingChannels is the ErrorID of the ErrorGeneric message sent
+// to peers whose funding requests are rejected for exceeding the reservation
+// limits.
+const errTooManyPendingChannels uint16 = 1
+
+// errContributionRejected is the ErrorID of the ErrorGeneric message sent to
+// peers whose contribution to a pending channel fails validation.
+const errContributionRejected uint16 = 3
+
+// sendContributionRejection informs the peer that its contribution to the
+// target pending channel was rejected, if the rejection stems from the
+// contribution failing validation. As the peer is disconnected right after,
+// this blocks until the message has been written out, or the peer exits.
+func sendContributionRejection(p *peer, pendingID uint64, err error) {
+	rejectErr, ok := err.(*lnwallet.ContributionError)
+	if !ok {
+		return
+	}
+
+	errMsg := lnwire.NewErrorGeneric()
+	errMsg.ChannelPoint = &wire.OutPoint{}
+	errMsg.ErrorID = errContributionRejected
+	errMsg.Problem = fmt.Sprintf("contribution for pendingId=%v "+
+		"rejected: %v", pendingID, rejectErr)
+
+	sent := make(chan struct{}, 1)
+	p.queueMsg(errMsg, sent)
+	select {
+	case <-sent:
+	case <-p.quit:
+	}
+}
+
+// releaseReservation stops tracking the target reservation, signalling the
+// reservationCoordinator that any queued funding requests may now be
+// admitted.
+func (f *fundingManager) releaseReservation(peerID int32, chanID uint64) {
+	f.resMtx.Lock()
+	delete(f.activeReservations[peerID], chanID)
+	f.resMtx.Unlock()
+
+	select {
+	case f.reservationReleased <- struct{}{}:
+	default:
+	}
+}
+
+// logReservationState logs each state transition of the reservation with the
+// target pending channel ID, until it reaches a terminal state, so a funding
+// workflow stalled at a particular step can be spotted.
+//
+// NOTE: This MUST be run as a goroutine.
+func (f *fundingManager) logReservationState(peerID int32, chanID uint64,
+	sub *lnwallet.ReservationStateSubscription) {
+
+	defer f.wg.Done()
+	defer sub.Cancel()
+
+	for {
+		select {
+		case state, ok := <-sub.Updates:
+			if !ok {
+				return
+			}
+
+			fndgLog.Infof("Reservation for pendingID(%v) with "+
+				"peerID(%v) is now %v", chanID, peerID, state)
+
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// numPendingReservations returns the number of reservations pending with the
+// target peer, along with the total number pending across all peers.
+// Reservations left behind by peers which have since disconnected aren't
+// counted.
+func (f *fundingManager) numPendingReservations(peerID int32) (int, int) {
+	f.resMtx.RLock()
+	defer f.resMtx.RUnlock()
+
+	var numPeer, numTotal int
+	for id, peerChannels := range f.activeReservations {
+		for _, resCtx := range peerChannels {
+			if atomic.LoadInt32(&resCtx.peer.disconnect) != 0 {
+				continue
+			}
+
+			numTotal++
+			if id == peerID {
+				numPeer++
+			}
+		}
+	}
+
+	return numPeer, numTotal
+}
+
+// withinLimits returns true if a new reservation may be created with the
+// target peer without exceeding the reservation limits.
+func (f *fundingManager) withinLimits(peerID int32) bool {
+	numPeer, numTotal := f.numPendingReservations(peerID)
+	return numPeer < f.limits.maxPerPeer && numTotal < f.limits.maxTotal
+}
+
+// admitFundingRequest handles a funding request from a remote peer, if the
+// reservation limits permit it. Otherwise, the request is queued until a
+// reservation is released, or rejected if the queue is already full, or the
+// peer already has as many requests queued as it may have reservations
+// pending.
+//
+// NOTE: This MUST only be called from the reservationCoordinator.
+func (f *fundingManager) admitFundingRequest(fmsg *fundingRequestMsg) {
+	// Requests queued earlier take precedence over this one, so first
+	// admit any which a released reservation now permits.
+	f.processQueuedRequests()
+
+	if f.withinLimits(fmsg.peer.id) {
+		f.handleFundingRequest(fmsg)
+		return
+	}
+
+	var numQueued int
+	for _, queued := range f.queuedRequests {
+		if queued.peer.id == fmsg.peer.id {
+			numQueued++
+		}
+	}
+	if len(f.queuedRequests) >= f.limits.maxQueued ||
+		numQueued >= f.limits.maxPerPeer {
+
+		fndgLog.Warnf("Rejecting fundingRequest(pendingId=%v) from "+
+			"peerID(%v): too many pending channels",
+			fmsg.msg.ChannelID, fmsg.peer.id)
+
+		errMsg := lnwire.NewErrorGeneric()
+		errMsg.ChannelPoint = &wire.OutPoint{}
+		errMsg.ErrorID = errTooManyPendingChannels
+		errMsg.Problem = fmt.Sprintf("funding request for pendingId=%v "+
+			"rejected: too many pending channels", fmsg.msg.ChannelID)
+		fmsg.peer.queueMsg(errMsg, nil)
+		return
+	}
+
+	fndgLog.Infof("Queueing fundingRequest(pendingId=%v) from peerID(%v) "+
+		"until a pending channel is released", fmsg.msg.ChannelID,
+		fmsg.peer.id)
+
+	f.queuedRequests = append(f.queuedRequests, fmsg)
+}
+
+// processQueuedRequests handles each queued funding request, in the order
+// they were received, which the reservation limits now permit. Requests from
+// peers which have since disconnected are dropped.
+//
+// NOTE: This MUST only be called from the reservationCoordinator.
+func (f *fundingManager) processQueuedRequests() {
+	remaining := f.queuedRequests[:0]
+	for _, fmsg := range f.queuedRequests {
+		switch {
+		case atomic.LoadInt32(&fmsg.peer.disconnect) != 0:
+			fndgLog.Debugf("Dropping queued fundingRequest"+
+				"(pendingId=%v) from disconnected peerID(%v)",
+				fmsg.msg.ChannelID, fmsg.peer.id)
+
+		case f.withinLimits(fmsg.peer.id):
+			f.handleFundingRequest(fmsg)
+
+		default:
+			remaining = append(remaining, fmsg)
+		}
+	}
+
+	// Clear the tail of the backing array so the dropped requests, and
+	// the peers they reference, may be garbage collected.
+	for i := len(remaining); i < len(f.queuedRequests); i++ {
+		f.queuedRequests[i] = nil
+	}
+	f.queuedRequests = remaining
+}
+
 // handleSingleFundingRequest creates an initial 'ChannelReservation' within
 // the wallet, then responds to the source peer with a single funder response
 // message progressing the funding workflow.
@@ -325,12 +601,19 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 	if _, ok := f.activeReservations[fmsg.peer.id]; !ok {
 		f.activeReservations[fmsg.peer.id] = make(pendingChannels)
 	}
-	f.activeReservations[fmsg.peer.id][msg.ChannelID] = &reservationWithCtx{
+	resCtx := &reservationWithCtx{
 		reservation: reservation,
 		peer:        fmsg.peer,
+		fsm: lnwallet.NewFundingStateMachine(reservation,
+			lnwallet.FundingResponder),
 	}
+	f.activeReservations[fmsg.peer.id][msg.ChannelID] = resCtx
 	f.resMtx.Unlock()
 
+	f.wg.Add(1)
+	go f.logReservationState(fmsg.peer.id, msg.ChannelID,
+		reservation.SubscribeState())
+
 	// With our portion of the reservation initialied, process the
 	// initiators contribution to the channel.
 	_, addrs, _, err := txscript.ExtractPkScriptAddrs(msg.DeliveryPkScript, activeNetParams.Params)
@@ -344,9 +627,18 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 		CommitKey:       msg.CommitmentKey,
 		DeliveryAddress: addrs[0],
 		CsvDelay:        delay,
+		AssetParams: lnwallet.AssetParams{
+			AssetID:          msg.AssetID,
+			DustLimit:        msg.AssetDustLimit,
+			MaxHTLCValue:     msg.MaxHTLCAssetValue,
+			CarrierSatBudget: msg.CarrierSatBudget,
+		},
+		Features: lnwallet.FeaturesFromWire(msg.InstructionVersion,
+			msg.DustPolicy, msg.FeatureFlags),
 	}
-	if err := reservation.ProcessSingleContribution(contribution); err != nil {
+	if err := resCtx.fsm.StepContribution(contribution); err != nil {
 		fndgLog.Errorf("unable to add contribution reservation: %v", err)
+		sendContributionRejection(fmsg.peer, msg.ChannelID, err)
 		fmsg.peer.Disconnect()
 		return
 	}
@@ -365,6 +657,13 @@ func (f *fundingManager) handleFundingRequest(fmsg *fundingRequestMsg) {
 		ourContribution.RevocationKey, ourContribution.CommitKey,
 		ourContribution.MultiSigKey, ourContribution.CsvDelay,
 		deliveryScript)
+	fundingResp.AssetID = ourContribution.AssetParams.AssetID
+	fundingResp.AssetDustLimit = ourContribution.AssetParams.DustLimit
+	fundingResp.MaxHTLCAssetValue = ourContribution.AssetParams.MaxHTLCValue
+	fundingResp.CarrierSatBudget = ourContribution.AssetParams.CarrierSatBudget
+	fundingResp.InstructionVersion = ourContribution.Features.InstructionVersion
+	fundingResp.DustPolicy = uint8(ourContribution.Features.DustPolicy)
+	fundingResp.FeatureFlags = lnwallet.WireFeatureFlags(&ourContribution.Features)
 
 	fmsg.peer.queueMsg(fundingResp, nil)
 }
@@ -405,10 +704,19 @@ func (f *fundingManager) handleFundingResponse(fmsg *fundingResponseMsg) {
 		DeliveryAddress: addrs[0],
 		RevocationKey:   msg.RevocationKey,
 		CsvDelay:        msg.CsvDelay,
+		AssetParams: lnwallet.AssetParams{
+			AssetID:          msg.AssetID,
+			DustLimit:        msg.AssetDustLimit,
+			MaxHTLCValue:     msg.MaxHTLCAssetValue,
+			CarrierSatBudget: msg.CarrierSatBudget,
+		},
+		Features: lnwallet.FeaturesFromWire(msg.InstructionVersion,
+			msg.DustPolicy, msg.FeatureFlags),
 	}
-	if err := resCtx.reservation.ProcessContribution(contribution); err != nil {
+	if err := resCtx.fsm.StepContribution(contribution); err != nil {
 		fndgLog.Errorf("Unable to process contribution from %v: %v",
 			sourcePeer, err)
+		sendContributionRejection(fmsg.peer, msg.ChannelID, err)
 		fmsg.peer.Disconnect()
 		resCtx.err <- err
 		return
@@ -436,6 +744,7 @@ func (f *fundingManager) handleFundingResponse(fmsg *fundingResponseMsg) {
 	revocationKey := resCtx.reservation.OurContribution().RevocationKey
 	fundingComplete := lnwire.NewSingleFundingComplete(msg.ChannelID,
 		outPoint, commitSig, revocationKey)
+	fundingComplete.FundingValue = resCtx.reservation.FundingValue()
 	sourcePeer.queueMsg(fundingComplete, nil)
 }
 
@@ -469,8 +778,14 @@ func (f *fundingManager) handleFundingComplete(fmsg *fundingCompleteMsg) {
 	// Append a sighash type of SigHashAll to the signature as it's the
 	// sighash type used implicitly within this type of channel for
 	// commitment transactions.
-	revokeKey := fmsg.msg.RevocationKey
-	if err := resCtx.reservation.CompleteReservationSingle(revokeKey, fundingOut, commitSig); err != nil {
+	sigs := &lnwallet.FundingSigs{
+		CommitSig:       commitSig,
+		RevocationKey:   fmsg.msg.RevocationKey,
+		FundingOutpoint: fundingOut,
+		FundingValue:    fmsg.msg.FundingValue,
+	}
+	if err := resCtx.fsm.StepSignatures(sigs); err != nil {
+
 		// TODO(roasbeef): better error logging: peerID, channelID, etc.
 		fndgLog.Errorf("unable to complete single reservation: %v", err)
 		fmsg.peer.Disconnect()
@@ -521,7 +836,8 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 	// transaction. We'll verify the signature for validity, then commit
 	// the state to disk as we can now open the channel.
 	commitSig := fmsg.msg.CommitSignature.Serialize()
-	if err := resCtx.reservation.CompleteReservation(nil, commitSig); err != nil {
+	sigs := &lnwallet.FundingSigs{CommitSig: commitSig}
+	if err := resCtx.fsm.StepSignatures(sigs); err != nil {
 		fndgLog.Errorf("unable to complete reservation sign complete: %v", err)
 		fmsg.peer.Disconnect()
 		resCtx.err <- err
@@ -549,15 +865,33 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 	// once it reaches a sufficient number of confirmations.
 	// TODO(roasbeef): semaphore to limit active chan open goroutines
 	go func() {
+		// The final step of the workflow blocks until the funding
+		// transaction confirms, so it's awaited alongside our quit
+		// signal. The step only fails if the funding transaction was
+		// replaced via AbortFunding, in which case a nil channel is
+		// delivered.
+		opened := make(chan *lnwallet.LightningChannel, 1)
+		go func() {
+			openChan, _ := resCtx.fsm.StepOpen()
+			opened <- openChan
+		}()
+
 		select {
 		// TODO(roasbeef): need to persist pending broadcast channels,
 		// send chan open proof during scan of blocks mined while down.
-		case openChan := <-resCtx.reservation.DispatchChan():
+		case openChan := <-opened:
 			// This reservation is no longer pending as the funding
 			// transaction has been fully confirmed.
-			f.resMtx.Lock()
-			delete(f.activeReservations[fmsg.peer.id], chanID)
-			f.resMtx.Unlock()
+			f.releaseReservation(fmsg.peer.id, chanID)
+
+			// If the funding transaction was replaced before it
+			// confirmed, then the channel will never be opened.
+			if openChan == nil {
+				fndgLog.Infof("ChannelPoint(%v) with peerID(%v) "+
+					"was aborted before confirmation",
+					fundingPoint, fmsg.peer.id)
+				return
+			}
 
 			fndgLog.Infof("ChannelPoint(%v) with peerID(%v) is now active",
 				fundingPoint, fmsg.peer.id)
@@ -572,13 +906,31 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 			// Next, we queue a message to notify the remote peer
 			// that the channel is open. We additionally provide an
 			// SPV proof allowing them to verify the transaction
-			// inclusion.
-			// TODO(roasbeef): obtain SPV proof from sub-system.
-			//  * ChainNotifier constructs proof also?
-			spvProof := []byte("fake proof")
+			// inclusion. Should we fail to construct the proof,
+			// an empty one is sent, leaving it up to the remote
+			// peer whether to accept the channel regardless. A
+			// zero-conf channel has no proof to offer, so the
+			// remote peer verifies its funding transaction within
+			// the mempool instead.
+			var (
+				spvProof []byte
+				err      error
+			)
+			if resCtx.reservation.ZeroConf() {
+				go fmsg.peer.server.watchZeroConfChannel(*fundingPoint)
+			} else {
+				spvProof, err = resCtx.reservation.FundingProof()
+				if err != nil {
+					fndgLog.Errorf("unable to create spv "+
+						"proof for ChannelPoint(%v): %v",
+						fundingPoint, err)
+				}
+			}
 			fundingOpen := lnwire.NewSingleFundingOpenProof(chanID, spvProof)
 			fmsg.peer.queueMsg(fundingOpen, nil)
 
+			f.recordFundingBundle(resCtx.reservation)
+
 			// Register the new link with the L3 routing manager
 			// so this new channel can be utilized during path
 			// finding.
@@ -591,6 +943,12 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 					Cpt: capacity,
 				},
 			)
+			fmsg.peer.server.addChannelToGraph(chanInfo)
+
+			// As the funding transaction has now confirmed, so has
+			// any change it returned to us.
+			fmsg.peer.server.notifyFundingChange(chanInfo,
+				resCtx.reservation.FundingChange())
 
 			// Finally give the caller a final update notifying
 			// them that the channel is now open.
@@ -612,6 +970,32 @@ func (f *fundingManager) handleFundingSignComplete(fmsg *fundingSignCompleteMsg)
 	}()
 }
 
+// recordFundingBundle generates, and stores the FundingBundle of the newly
+// opened channel, for later export to the issuer of its asset. A failure is
+// only logged, as the channel itself is unaffected.
+func (f *fundingManager) recordFundingBundle(res *lnwallet.ChannelReservation) {
+	fundingPoint := res.FundingOutpoint()
+
+	bundle, err := res.FundingBundle()
+	if err != nil {
+		fndgLog.Errorf("unable to create funding bundle for "+
+			"ChannelPoint(%v): %v", fundingPoint, err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := bundle.Encode(&b); err != nil {
+		fndgLog.Errorf("unable to encode funding bundle for "+
+			"ChannelPoint(%v): %v", fundingPoint, err)
+		return
+	}
+	err = f.wallet.ChannelDB.PutFundingBundle(fundingPoint, b.Bytes())
+	if err != nil {
+		fndgLog.Errorf("unable to store funding bundle for "+
+			"ChannelPoint(%v): %v", fundingPoint, err)
+	}
+}
+
 // processFundingOpenProof sends a message to the fundingManager allowing it
 // to process the final message recieved when the daemon is on the responding
 // side of a single funder channel workflow.
@@ -629,14 +1013,44 @@ func (f *fundingManager) handleFundingOpen(fmsg *fundingOpenMsg) {
 	f.resMtx.RUnlock()
 
 	// The channel initiator has claimed the channel is now open, so we'll
-	// verify the contained SPV proof for validity.
-	// TODO(roasbeef): send off to the spv proof verifier, in the routing
-	// sub-module.
+	// verify the contained SPV proof for validity against our own view of
+	// the chain. Unless configured to require a valid proof, a failure is
+	// only logged, as peers may not yet present a proper proof. Peers we
+	// accept zero-conf channels from may instead open the channel before
+	// the funding transaction confirms, in which case it's verified within
+	// the mempool, then watched until it confirms.
+	zeroConf := false
+	err := resCtx.reservation.VerifyFundingProof(fmsg.msg.SpvProof)
+	switch {
+	case err != nil && fmsg.peer.server.zeroConf.accepts(fmsg.peer.lightningID):
+		if err := resCtx.reservation.VerifyUnconfirmedFunding(); err != nil {
+			fndgLog.Errorf("Invalid zero-conf funding for "+
+				"ChannelPoint(%v) from peerID(%v): %v",
+				resCtx.reservation.FundingOutpoint(),
+				fmsg.peer.id, err)
+			fmsg.peer.Disconnect()
+			return
+		}
+		zeroConf = true
+
+	case err != nil && cfg.RequireFundingProof:
+		fndgLog.Errorf("Invalid funding proof for ChannelPoint(%v) "+
+			"from peerID(%v): %v", resCtx.reservation.FundingOutpoint(),
+			fmsg.peer.id, err)
+		fmsg.peer.Disconnect()
+		return
+
+	case err != nil:
+		fndgLog.Warnf("Unable to verify funding proof for "+
+			"ChannelPoint(%v) from peerID(%v), accepting "+
+			"regardless: %v", resCtx.reservation.FundingOutpoint(),
+			fmsg.peer.id, err)
+	}
 
 	// Now that we've verified the initiator's proof, we'll commit the
 	// channel state to disk, and notify the source peer of a newly opened
 	// channel.
-	openChan, err := resCtx.reservation.FinalizeReservation()
+	openChan, err := resCtx.fsm.StepOpen()
 	if err != nil {
 		fndgLog.Errorf("unable to finalize reservation: %v", err)
 		fmsg.peer.Disconnect()
@@ -645,9 +1059,9 @@ func (f *fundingManager) handleFundingOpen(fmsg *fundingOpenMsg) {
 
 	// The reservation has been completed, therefore we can stop tracking
 	// it within our active reservations map.
-	f.resMtx.Lock()
-	delete(f.activeReservations[fmsg.peer.id], fmsg.msg.ChannelID)
-	f.resMtx.Unlock()
+	f.releaseReservation(fmsg.peer.id, fmsg.msg.ChannelID)
+
+	f.recordFundingBundle(resCtx.reservation)
 
 	fndgLog.Infof("FundingOpen: ChannelPoint(%v) with peerID(%v) is now open",
 		resCtx.reservation.FundingOutpoint, fmsg.peer.id)
@@ -662,6 +1076,15 @@ func (f *fundingManager) handleFundingOpen(fmsg *fundingOpenMsg) {
 			Cpt: capacity,
 		},
 	)
+	fmsg.peer.server.addChannelToGraph(openChan.StateSnapshot())
+
+	if zeroConf {
+		fndgLog.Infof("ChannelPoint(%v) with peerID(%v) opened "+
+			"zero-conf", resCtx.reservation.FundingOutpoint(),
+			fmsg.peer.id)
+		go fmsg.peer.server.watchZeroConfChannel(
+			*resCtx.reservation.FundingOutpoint())
+	}
 
 	// Finally, notify the target peer of the newly open channel.
 	fmsg.peer.newChannels <- openChan
@@ -721,9 +1144,15 @@ func (f *fundingManager) handleInitFundingMsg(msg *initFundingMsg) {
 		peer:        msg.peer,
 		updates:     msg.updates,
 		err:         msg.err,
+		fsm: lnwallet.NewFundingStateMachine(reservation,
+			lnwallet.FundingInitiator),
 	}
 	f.resMtx.Unlock()
 
+	f.wg.Add(1)
+	go f.logReservationState(msg.peer.id, chanID,
+		reservation.SubscribeState())
+
 	// Once the reservation has been created, and indexed, queue a funding
 	// request to the remote peer, kicking off the funding workflow.
 	contribution := reservation.OurContribution()
@@ -749,5 +1178,12 @@ func (f *fundingManager) handleInitFundingMsg(msg *initFundingMsg) {
 		contribution.MultiSigKey,
 		deliveryScript,
 	)
+	fundingReq.AssetID = contribution.AssetParams.AssetID
+	fundingReq.AssetDustLimit = contribution.AssetParams.DustLimit
+	fundingReq.MaxHTLCAssetValue = contribution.AssetParams.MaxHTLCValue
+	fundingReq.CarrierSatBudget = contribution.AssetParams.CarrierSatBudget
+	fundingReq.InstructionVersion = contribution.Features.InstructionVersion
+	fundingReq.DustPolicy = uint8(contribution.Features.DustPolicy)
+	fundingReq.FeatureFlags = lnwallet.WireFeatureFlags(&contribution.Features)
 	msg.peer.queueMsg(fundingReq, nil)
 }
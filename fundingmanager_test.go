@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// TestReservationLimits tests that funding requests over the reservation
+// limits are queued in the order they're received, that requests are rejected
+// once the queue is full, and that queued requests from peers which have
+// disconnected are dropped.
+func TestReservationLimits(t *testing.T) {
+	f := newFundingManager(nil, &reservationLimits{
+		maxPerPeer: 1,
+		maxTotal:   2,
+		maxQueued:  2,
+	})
+
+	newTestPeer := func(id int32) *peer {
+		return &peer{
+			id:            id,
+			outgoingQueue: make(chan outgoinMsg, outgoingQueueLen),
+		}
+	}
+	alice, bob, carol := newTestPeer(1), newTestPeer(2), newTestPeer(3)
+
+	// Fill the global limit with a pending reservation from both Alice
+	// and Bob.
+	for i, p := range []*peer{alice, bob} {
+		f.activeReservations[p.id] = pendingChannels{
+			uint64(i): &reservationWithCtx{peer: p},
+		}
+	}
+	if f.withinLimits(carol.id) {
+		t.Fatalf("carol admitted over the global limit")
+	}
+
+	request := func(p *peer, chanID uint64) {
+		f.admitFundingRequest(&fundingRequestMsg{
+			msg:  &lnwire.SingleFundingRequest{ChannelID: chanID},
+			peer: p,
+		})
+	}
+	assertRejected := func(p *peer, rejected bool) {
+		select {
+		case msg := <-p.outgoingQueue:
+			if !rejected {
+				t.Fatalf("peer %v unexpectedly sent %v", p.id,
+					msg.msg)
+			}
+			errMsg, ok := msg.msg.(*lnwire.ErrorGeneric)
+			if !ok || errMsg.ErrorID != errTooManyPendingChannels {
+				t.Fatalf("peer %v sent %v, expected rejection",
+					p.id, msg.msg)
+			}
+		default:
+			if rejected {
+				t.Fatalf("peer %v wasn't sent a rejection", p.id)
+			}
+		}
+	}
+
+	// Alice's request is over her own limit, and is queued.
+	request(alice, 10)
+	assertRejected(alice, false)
+
+	// A second request from Alice would exceed her share of the queue.
+	request(alice, 11)
+	assertRejected(alice, true)
+
+	// Carol's request is over the global limit, and is queued, filling
+	// the queue, so a further request from Bob is rejected.
+	request(carol, 20)
+	assertRejected(carol, false)
+	request(bob, 30)
+	assertRejected(bob, true)
+
+	if len(f.queuedRequests) != 2 {
+		t.Fatalf("expected 2 queued requests, got %v",
+			len(f.queuedRequests))
+	}
+	if f.queuedRequests[0].peer != alice || f.queuedRequests[1].peer != carol {
+		t.Fatalf("queued requests out of order")
+	}
+
+	// Once Carol disconnects, her queued request is dropped, while
+	// Alice's remains queued as her own reservation is still pending.
+	carol.disconnect = 1
+	f.processQueuedRequests()
+	if len(f.queuedRequests) != 1 || f.queuedRequests[0].peer != alice {
+		t.Fatalf("expected only alice's request to remain queued")
+	}
+
+	// Reservations left behind by disconnected peers don't count towards
+	// the limits.
+	bob.disconnect = 1
+	if numPeer, numTotal := f.numPendingReservations(alice.id); numPeer != 1 ||
+		numTotal != 1 {
+
+		t.Fatalf("expected 1 pending reservation for alice of 1 total, "+
+			"got %v of %v", numPeer, numTotal)
+	}
+	if !f.withinLimits(carol.id) || f.withinLimits(alice.id) {
+		t.Fatalf("limits not applied to connected peers alone")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcutil"
+)
+
+// forwardingPolicy is the fee policy we advertise for forwarding HTLCs over
+// our channels. Fees are charged by the node forwarding an HTLC, for the
+// channel it forwards over.
+type forwardingPolicy struct {
+	// feeBase is the fixed fee, denominated in the channel's asset,
+	// charged per forwarded HTLC.
+	feeBase btcutil.Amount
+
+	// feeRate is the proportional fee, in millionths of the forwarded
+	// amount.
+	feeRate uint32
+
+	// carrierFee is the number of satoshis charged per forwarded HTLC,
+	// covering the dust output carrying the HTLC's asset.
+	carrierFee btcutil.Amount
+
+	// timeLockDelta is the minimum number of blocks required between the
+	// expiry of an incoming HTLC, and the expiry of the HTLC it's
+	// forwarded as.
+	timeLockDelta uint32
+}
+
+// fee returns the fee charged under the policy for forwarding amt.
+func (f *forwardingPolicy) fee(amt btcutil.Amount) btcutil.Amount {
+	return f.feeBase + amt*btcutil.Amount(f.feeRate)/1000000
+}
+
+// newChannelAnnouncement creates the announcement advertising the passed
+// channel edge to the network.
+func newChannelAnnouncement(edge *router.ChannelEdge) *lnwire.ChannelAnnouncement {
+	chanPoint := edge.ChanPoint
+	return &lnwire.ChannelAnnouncement{
+		ChannelPoint:  &chanPoint,
+		NodeID1:       edge.Node1,
+		NodeID2:       edge.Node2,
+		AssetID:       edge.AssetID,
+		Capacity:      edge.Capacity,
+		FeeBase:       edge.FeeBase,
+		FeeRate:       edge.FeeRate,
+		CarrierFee:    edge.CarrierFee,
+		TimeLockDelta: edge.TimeLockDelta,
+	}
+}
+
+// validateChannelAnnouncement checks the announced channel against the color
+// of its funding output, as reported by the colored coins service. The
+// funding output must hold exactly the announced capacity of the announced
+// asset.
+func validateChannelAnnouncement(ann *lnwire.ChannelAnnouncement,
+	txo *lndcc.TxoData) error {
+
+	if txo.AssetId != ann.AssetID {
+		return fmt.Errorf("funding output %v holds asset %q, channel "+
+			"announced as %q", ann.ChannelPoint, txo.AssetId,
+			ann.AssetID)
+	}
+	if txo.Value != ann.Capacity {
+		return fmt.Errorf("funding output %v holds %v, channel "+
+			"announced with capacity %v", ann.ChannelPoint,
+			txo.Value, ann.Capacity)
+	}
+
+	return nil
+}
+
+// processChannelAnnouncement validates a channel announcement received from
+// the passed peer, adding the channel to the channel graph and relaying the
+// announcement to our other peers if it's new to us. As validation queries
+// the colored coins service, announcements are processed outside of the
+// peer's readHandler.
+//
+// TODO: announcements aren't yet signed by the channel's endpoints, so only
+// the color and capacity of the channel are verified.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) processChannelAnnouncement(ann *lnwire.ChannelAnnouncement,
+	src *peer) {
+
+	if err := ann.Validate(); err != nil {
+		srvrLog.Warnf("invalid channel announcement from %v: %v", src, err)
+		return
+	}
+
+	edge := &router.ChannelEdge{
+		ChanPoint:     *ann.ChannelPoint,
+		Node1:         ann.NodeID1,
+		Node2:         ann.NodeID2,
+		AssetID:       ann.AssetID,
+		Capacity:      ann.Capacity,
+		FeeBase:       ann.FeeBase,
+		FeeRate:       ann.FeeRate,
+		CarrierFee:    ann.CarrierFee,
+		TimeLockDelta: ann.TimeLockDelta,
+	}
+
+	// If we already know of the channel, and it's unchanged, then there's
+	// nothing to do. This also prevents announcements from being relayed
+	// in a loop.
+	if known, err := s.chanGraph.Channel(edge.ChanPoint); err == nil &&
+		*known == *edge {
+		return
+	}
+
+	txo, err := lndcc.GetTxoData(edge.ChanPoint)
+	if err != nil {
+		srvrLog.Errorf("unable to fetch funding output of announced "+
+			"ChannelPoint(%v): %v", edge.ChanPoint, err)
+		return
+	}
+	if err := validateChannelAnnouncement(ann, txo); err != nil {
+		srvrLog.Warnf("rejecting channel announcement from %v: %v",
+			src, err)
+		return
+	}
+
+	if err := s.chanGraph.AddChannel(edge); err != nil {
+		srvrLog.Errorf("unable to add ChannelPoint(%v) to graph: %v",
+			edge.ChanPoint, err)
+		return
+	}
+
+	srvrLog.Debugf("Added announced ChannelPoint(%v) of %v %q to graph",
+		edge.ChanPoint, edge.Capacity, edge.AssetID)
+
+	s.broadcastMessage(ann, src)
+}
+
+// broadcastMessage sends the message to all connected peers, other than the
+// passed peer, which may be nil.
+func (s *server) broadcastMessage(msg lnwire.Message, skip *peer) {
+	for _, p := range s.Peers() {
+		if p == skip {
+			continue
+		}
+		p.queueMsg(msg, nil)
+	}
+}
+
+// syncChannelGraph sends the announcements of all channels within our channel
+// graph to a newly connected peer.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) syncChannelGraph(p *peer) {
+	for _, edge := range s.chanGraph.Channels() {
+		p.queueMsg(newChannelAnnouncement(edge), nil)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestValidateChannelAnnouncement tests that announced channels are only
+// accepted if their funding output holds the announced asset and capacity,
+// and that announcements faithfully carry a channel's fee policy.
+func TestValidateChannelAnnouncement(t *testing.T) {
+	edge := &router.ChannelEdge{
+		ChanPoint:     wire.OutPoint{Index: 1},
+		Node1:         router.NodeID{1},
+		Node2:         router.NodeID{2},
+		AssetID:       "assetA",
+		Capacity:      5000,
+		FeeBase:       10,
+		FeeRate:       1000,
+		CarrierFee:    600,
+		TimeLockDelta: 144,
+	}
+	ann := newChannelAnnouncement(edge)
+	if err := ann.Validate(); err != nil {
+		t.Fatalf("invalid announcement: %v", err)
+	}
+	if ann.FeeBase != edge.FeeBase || ann.FeeRate != edge.FeeRate ||
+		ann.CarrierFee != edge.CarrierFee ||
+		ann.TimeLockDelta != edge.TimeLockDelta {
+		t.Fatalf("announcement doesn't carry fee policy: %v", ann)
+	}
+
+	txo := &lndcc.TxoData{AssetId: "assetA", Value: 5000}
+	if err := validateChannelAnnouncement(ann, txo); err != nil {
+		t.Fatalf("valid announcement rejected: %v", err)
+	}
+
+	// An announcement mislabeling the funding output's asset should be
+	// rejected.
+	txo = &lndcc.TxoData{AssetId: "assetB", Value: 5000}
+	if err := validateChannelAnnouncement(ann, txo); err == nil {
+		t.Fatalf("announcement with wrong asset accepted")
+	}
+
+	// As should an announcement overstating the channel's capacity.
+	txo = &lndcc.TxoData{AssetId: "assetA", Value: 4000}
+	if err := validateChannelAnnouncement(ann, txo); err == nil {
+		t.Fatalf("announcement with wrong capacity accepted")
+	}
+}
+
+// TestCheckExpiry tests that HTLCs are only forwarded if the incoming HTLC
+// outlives the outgoing HTLC by at least our time lock delta, and the
+// outgoing HTLC hasn't already expired.
+func TestCheckExpiry(t *testing.T) {
+	policy := &forwardingPolicy{timeLockDelta: 40}
+
+	tests := []struct {
+		inExpiry  uint32
+		outExpiry uint32
+		valid     bool
+	}{
+		{inExpiry: 1100, outExpiry: 1060, valid: true},
+		{inExpiry: 1200, outExpiry: 1060, valid: true},
+		{inExpiry: 1099, outExpiry: 1060, valid: false},
+		{inExpiry: 1000, outExpiry: 1060, valid: false},
+		{inExpiry: 1040, outExpiry: 1000, valid: false},
+		{inExpiry: 1030, outExpiry: 990, valid: false},
+	}
+
+	for i, test := range tests {
+		err := policy.checkExpiry(test.inExpiry, test.outExpiry, 1000)
+		if test.valid && err != nil {
+			t.Fatalf("test #%v: valid expiry rejected: %v", i, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("test #%v: invalid expiry accepted", i)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// heldHTLCKey uniquely identifies an incoming HTLC across all channels.
+type heldHTLCKey struct {
+	chanPoint wire.OutPoint
+	index     uint64
+}
+
+// htlcHolder is an HTLCInterceptor which holds each intercepted HTLC until
+// it's either settled or failed over RPC. The holder is registered as the
+// server's interceptor when lnd is started with holdhtlcs.
+type htlcHolder struct {
+	sync.Mutex
+	htlcs map[heldHTLCKey]*InterceptedHTLC
+}
+
+// newHTLCHolder creates a new htlcHolder which isn't holding any HTLCs.
+func newHTLCHolder() *htlcHolder {
+	return &htlcHolder{
+		htlcs: make(map[heldHTLCKey]*InterceptedHTLC),
+	}
+}
+
+// A compile time check to ensure htlcHolder implements the HTLCInterceptor
+// interface.
+var _ HTLCInterceptor = (*htlcHolder)(nil)
+
+// InterceptHTLC holds the passed HTLC until it's resolved.
+//
+// NOTE: Part of the HTLCInterceptor interface.
+func (h *htlcHolder) InterceptHTLC(htlc *InterceptedHTLC) {
+	h.Lock()
+	h.htlcs[heldHTLCKey{htlc.ChanPoint, htlc.Index}] = htlc
+	h.Unlock()
+
+	srvrLog.Infof("Holding htlc %v of ChannelPoint(%v) paying %v of "+
+		"asset %q to hash %x, expiring at height %v", htlc.Index,
+		htlc.ChanPoint, htlc.Amount, htlc.AssetID, htlc.PaymentHash[:],
+		htlc.Expiry)
+}
+
+// heldHTLCs returns all HTLCs currently held.
+func (h *htlcHolder) heldHTLCs() []*InterceptedHTLC {
+	h.Lock()
+	defer h.Unlock()
+
+	htlcs := make([]*InterceptedHTLC, 0, len(h.htlcs))
+	for _, htlc := range h.htlcs {
+		htlcs = append(htlcs, htlc)
+	}
+
+	return htlcs
+}
+
+// resolve settles the target held HTLC with the passed preimage, or fails it
+// if the preimage is nil. An HTLC is no longer held once a resolution has
+// been attempted, as it's then either resolved, or its channel is no longer
+// active. A preimage not matching the HTLC's payment hash is rejected, leaving
+// the HTLC held.
+func (h *htlcHolder) resolve(chanPoint wire.OutPoint, index uint64,
+	preimage *[32]byte) error {
+
+	key := heldHTLCKey{chanPoint, index}
+
+	h.Lock()
+	htlc, ok := h.htlcs[key]
+	if !ok {
+		h.Unlock()
+		return fmt.Errorf("htlc %v of ChannelPoint(%v) isn't held",
+			index, chanPoint)
+	}
+	if preimage != nil && fastsha256.Sum256(preimage[:]) != htlc.PaymentHash {
+		h.Unlock()
+		return fmt.Errorf("preimage doesn't match payment hash %x",
+			htlc.PaymentHash[:])
+	}
+	delete(h.htlcs, key)
+	h.Unlock()
+
+	// The resolution is handed to the htlcManager of the HTLC's channel,
+	// so the lock isn't held while doing so.
+	if preimage != nil {
+		return htlc.Settle(*preimage)
+	}
+	return htlc.Fail()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestHTLCHolder tests that intercepted HTLCs are held until resolved, and
+// that a preimage not matching the payment hash leaves the HTLC held.
+func TestHTLCHolder(t *testing.T) {
+	holder := newHTLCHolder()
+
+	preimage := [32]byte{1}
+	chanPoint := wire.OutPoint{Hash: wire.ShaHash{2}, Index: 1}
+	resolutions := make(chan *htlcResolution, 2)
+	quit := make(chan struct{})
+	for i := uint64(0); i < 2; i++ {
+		holder.InterceptHTLC(&InterceptedHTLC{
+			ChanPoint:   chanPoint,
+			Index:       i,
+			PaymentHash: fastsha256.Sum256(preimage[:]),
+			resolutions: resolutions,
+			quit:        quit,
+		})
+	}
+	if len(holder.heldHTLCs()) != 2 {
+		t.Fatalf("expected 2 held htlcs, got %v", len(holder.heldHTLCs()))
+	}
+
+	if err := holder.resolve(chanPoint, 0, &[32]byte{3}); err == nil {
+		t.Fatalf("htlc settled with invalid preimage")
+	}
+	if err := holder.resolve(chanPoint, 0, &preimage); err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	if res := <-resolutions; res.index != 0 || res.preimage == nil {
+		t.Fatalf("invalid settle resolution: %v", res)
+	}
+	if err := holder.resolve(chanPoint, 1, nil); err != nil {
+		t.Fatalf("unable to fail htlc: %v", err)
+	}
+	if res := <-resolutions; res.index != 1 || res.preimage != nil {
+		t.Fatalf("invalid fail resolution: %v", res)
+	}
+
+	// Once resolved, the HTLCs are no longer held.
+	if len(holder.heldHTLCs()) != 0 {
+		t.Fatalf("resolved htlcs still held")
+	}
+	if err := holder.resolve(chanPoint, 0, &preimage); err == nil {
+		t.Fatalf("htlc resolved twice")
+	}
+}
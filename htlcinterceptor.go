@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// HTLCInterceptor is implemented by sub-systems which wish to decide the fate
+// of incoming HTLCs which don't pay to one of our invoices. Interception
+// enables flows such as submarine swaps, or exchanges, where the preimage of
+// an HTLC is only obtained after some external event, e.g. an on-chain
+// payment, has taken place.
+type HTLCInterceptor interface {
+	// InterceptHTLC is called each time an incoming HTLC is fully locked
+	// into both commitment chains. The call must not block. The HTLC is
+	// held until it's resolved via either its Settle or Fail method, which
+	// may be called asynchronously at any later point.
+	InterceptHTLC(htlc *InterceptedHTLC)
+}
+
+// htlcResolution is sent to the htlcManager of a channel in order to resolve
+// an intercepted HTLC. If the preimage is nil, then the HTLC is failed back to
+// the remote party, citing the reason of the failure.
+type htlcResolution struct {
+	index    uint64
+	amt      btcutil.Amount
+	preimage *[32]byte
+	reason   lnwire.FailReason
+}
+
+// InterceptedHTLC is an incoming HTLC handed to an HTLCInterceptor once it
+// has been fully locked in.
+type InterceptedHTLC struct {
+	// ChanPoint is the channel the HTLC was received on.
+	ChanPoint wire.OutPoint
+
+	// Index is the index of the HTLC within the remote party's update log.
+	Index uint64
+
+	// PaymentHash is the hash the preimage of which settles the HTLC.
+	PaymentHash [32]byte
+
+	// AssetID is the identifier of the asset the HTLC is denominated in.
+	// An empty AssetID denotes an HTLC paying satoshis.
+	AssetID string
+
+	// Amount is the amount of the asset carried by the HTLC.
+	Amount btcutil.Amount
+
+	// Expiry is the absolute height after which the HTLC expires. The
+	// HTLC must be resolved before this height.
+	Expiry uint32
+
+	// Payload is the opaque payload which accompanied the HTLC.
+	Payload []byte
+
+	resolveOnce sync.Once
+	resolutions chan<- *htlcResolution
+	quit        <-chan struct{}
+}
+
+// Settle settles the intercepted HTLC with the passed preimage. An error is
+// returned if the preimage doesn't match the HTLC's payment hash, if the HTLC
+// has already been resolved, or if the channel is no longer active.
+func (h *InterceptedHTLC) Settle(preimage [32]byte) error {
+	if fastsha256.Sum256(preimage[:]) != h.PaymentHash {
+		return fmt.Errorf("preimage doesn't match payment hash %x",
+			h.PaymentHash[:])
+	}
+
+	return h.resolve(&htlcResolution{
+		index:    h.Index,
+		amt:      h.Amount,
+		preimage: &preimage,
+	})
+}
+
+// Fail fails the intercepted HTLC back to the remote party. An error is
+// returned if the HTLC has already been resolved, or if the channel is no
+// longer active.
+func (h *InterceptedHTLC) Fail() error {
+	return h.failWithReason(lnwire.FailUnspecified)
+}
+
+// failWithReason fails the intercepted HTLC back to the remote party, citing
+// the passed reason of the failure.
+func (h *InterceptedHTLC) failWithReason(reason lnwire.FailReason) error {
+	return h.resolve(&htlcResolution{
+		index:  h.Index,
+		reason: reason,
+	})
+}
+
+// resolve hands the resolution to the htlcManager of the HTLC's channel. Only
+// the first resolution of an HTLC is accepted.
+func (h *InterceptedHTLC) resolve(res *htlcResolution) error {
+	resolved := false
+	h.resolveOnce.Do(func() {
+		resolved = true
+	})
+	if !resolved {
+		return fmt.Errorf("htlc %v of ChannelPoint(%v) already resolved",
+			h.Index, h.ChanPoint)
+	}
+
+	select {
+	case h.resolutions <- res:
+		return nil
+	case <-h.quit:
+		return fmt.Errorf("ChannelPoint(%v) is no longer active",
+			h.ChanPoint)
+	}
+}
+
+// RegisterHTLCInterceptor registers the passed interceptor, replacing any
+// existing interceptor. All incoming HTLCs which don't pay to one of our
+// invoices are subsequently handed to the interceptor once locked in. A nil
+// interceptor disables interception.
+func (s *server) RegisterHTLCInterceptor(interceptor HTLCInterceptor) {
+	s.interceptorMtx.Lock()
+	s.interceptor = interceptor
+	s.interceptorMtx.Unlock()
+}
+
+// htlcInterceptor returns the currently registered interceptor, or nil if
+// none is registered.
+func (s *server) htlcInterceptor() HTLCInterceptor {
+	s.interceptorMtx.RLock()
+	defer s.interceptorMtx.RUnlock()
+
+	return s.interceptor
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+)
+
+// TestInterceptedHTLCResolution tests that an intercepted HTLC can only be
+// resolved once, and only settled with the preimage of its payment hash.
+func TestInterceptedHTLCResolution(t *testing.T) {
+	preimage := [32]byte{1}
+	resolutions := make(chan *htlcResolution, 1)
+	quit := make(chan struct{})
+
+	htlc := &InterceptedHTLC{
+		Index:       3,
+		PaymentHash: fastsha256.Sum256(preimage[:]),
+		Amount:      1000,
+		resolutions: resolutions,
+		quit:        quit,
+	}
+
+	if err := htlc.Settle([32]byte{2}); err == nil {
+		t.Fatalf("htlc settled with invalid preimage")
+	}
+	if err := htlc.Settle(preimage); err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	res := <-resolutions
+	if res.index != htlc.Index || res.preimage == nil ||
+		*res.preimage != preimage {
+		t.Fatalf("invalid resolution: %v", res)
+	}
+
+	if err := htlc.Fail(); err == nil {
+		t.Fatalf("htlc resolved twice")
+	}
+
+	// Once the channel is no longer active, resolving should fail rather
+	// than block.
+	close(quit)
+	failed := &InterceptedHTLC{
+		Index:       4,
+		resolutions: make(chan *htlcResolution),
+		quit:        quit,
+	}
+	if err := failed.Fail(); err == nil {
+		t.Fatalf("htlc of inactive channel resolved")
+	}
+}
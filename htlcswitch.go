@@ -28,6 +28,10 @@ const (
 type link struct {
 	capacity btcutil.Amount
 
+	// assetID is the identifier of the asset the link's channel is
+	// denominated in.
+	assetID string
+
 	availableBandwidth btcutil.Amount
 
 	linkChan chan *htlcPacket
@@ -47,6 +51,19 @@ type htlcPacket struct {
 	msg lnwire.Message
 	amt btcutil.Amount
 
+	// incomingChan is the channel the HTLC was received on if the packet
+	// is being forwarded, and nil if the payment was initiated locally.
+	// If the incoming and outgoing channels are denominated in different
+	// assets, then the outgoing amount is converted using the switch's
+	// RateProvider.
+	incomingChan *wire.OutPoint
+
+	// outgoingChan, if non-nil, restricts the switch to sending the HTLC
+	// over the link of this particular channel. This allows each part of
+	// a multi-part payment to be sent over a distinct channel. If set,
+	// then dest may be left empty.
+	outgoingChan *wire.OutPoint
+
 	err chan error
 }
 
@@ -79,6 +96,10 @@ type htlcSwitch struct {
 
 	htlcPlex chan *htlcPacket
 
+	// rates is consulted when forwarding an HTLC between two channels
+	// denominated in different assets.
+	rates RateProvider
+
 	// TODO(roasbeef): messaging chan to/from upper layer (routing - L3)
 
 	// TODO(roasbeef): sampler to log sat/sec and tx/sec
@@ -87,9 +108,11 @@ type htlcSwitch struct {
 	quit chan struct{}
 }
 
-// newHtlcSwitch creates a new htlcSwitch.
-func newHtlcSwitch() *htlcSwitch {
+// newHtlcSwitch creates a new htlcSwitch. The passed RateProvider is consulted
+// when forwarding HTLCs across channels of different assets.
+func newHtlcSwitch(rates RateProvider) *htlcSwitch {
 	return &htlcSwitch{
+		rates:            rates,
 		chanIndex:        make(map[wire.OutPoint]*link),
 		interfaces:       make(map[wire.ShaHash][]*link),
 		linkControl:      make(chan interface{}),
@@ -156,7 +179,14 @@ out:
 	for {
 		select {
 		case htlcPkt := <-h.outgoingPayments:
+			// If the packet is bound to a particular channel, then
+			// it's sent over the interface of that channel's peer.
 			dest := htlcPkt.dest
+			if htlcPkt.outgoingChan != nil {
+				if link, ok := h.chanIndex[*htlcPkt.outgoingChan]; ok {
+					dest = link.peer.lightningID
+				}
+			}
 			chanInterface, ok := h.interfaces[dest]
 			if !ok {
 				err := fmt.Errorf("Unable to locate link %x", dest)
@@ -166,33 +196,63 @@ out:
 			}
 
 			wireMsg := htlcPkt.msg.(*lnwire.HTLCAddRequest)
-			amt := btcutil.Amount(wireMsg.Amount)
+			incomingAmt := btcutil.Amount(wireMsg.Amount)
 
 			// Handle this send request in a distinct goroutine in
 			// order to avoid a possible deadlock between the htlc
 			// switch and channel's htlc manager.
 			var sent bool
-			for _, link := range chanInterface {
+			for _, outLink := range chanInterface {
+				if htlcPkt.outgoingChan != nil &&
+					*htlcPkt.outgoingChan != *outLink.chanPoint {
+					continue
+				}
+
+				// If the HTLC is being forwarded from a channel
+				// of another asset, then the amount sent over
+				// this link must first be converted.
+				amt, err := h.outgoingAmount(htlcPkt, incomingAmt,
+					outLink)
+				if err != nil {
+					hswcLog.Debugf("Unable to forward over "+
+						"link %v: %v", outLink.chanPoint, err)
+					continue
+				}
+
 				// TODO(roasbeef): implement HTLC fragmentation
 				//  * avoid full channel depletion at higher
 				//    level (here) instead of within state
 				//    machine?
-				if link.availableBandwidth < amt {
+				if outLink.availableBandwidth < amt {
 					continue
 				}
 
 				hswcLog.Tracef("Sending %v to %x", amt, dest[:])
 
 				// TODO(roasbeef): peer downstream should set chanPoint
-				wireMsg.ChannelPoint = link.chanPoint
-				go func() {
-					link.linkChan <- htlcPkt
-				}()
+				//
+				// The message is copied before its amount is
+				// converted, so the packet retains the incoming
+				// amount should the send fail, or be retried.
+				outMsg := *wireMsg
+				outMsg.ChannelPoint = outLink.chanPoint
+				outMsg.Amount = lnwire.CreditsAmount(amt)
+				outPkt := *htlcPkt
+				outPkt.msg = &outMsg
+				outPkt.amt = amt
+				go func(l *link) {
+					l.linkChan <- &outPkt
+				}(outLink)
 
 				// TODO(roasbeef): update link info on
 				// timeout/settle
-				link.availableBandwidth -= amt
+				outLink.availableBandwidth -= amt
 				sent = true
+
+				// As the amount of the HTLC may have been
+				// converted for this particular link, the
+				// packet can only be sent over a single link.
+				break
 			}
 
 			if sent {
@@ -233,6 +293,53 @@ out:
 	h.wg.Done()
 }
 
+// outgoingAmount returns the amount to be sent over the passed link for the
+// HTLC within the packet. If the HTLC is being forwarded from a channel
+// denominated in another asset, then the switch's RateProvider is consulted
+// to convert the incoming amount into the asset of the outgoing link.
+func (h *htlcSwitch) outgoingAmount(pkt *htlcPacket, incomingAmt btcutil.Amount,
+	outgoing *link) (btcutil.Amount, error) {
+
+	if pkt.incomingChan == nil {
+		return incomingAmt, nil
+	}
+
+	incoming, ok := h.chanIndex[*pkt.incomingChan]
+	if !ok {
+		return 0, fmt.Errorf("unknown incoming link %v",
+			pkt.incomingChan)
+	}
+
+	fromAsset, toAsset := incoming.asset(), outgoing.asset()
+	if fromAsset == toAsset {
+		return incomingAmt, nil
+	}
+
+	if h.rates == nil {
+		return 0, fmt.Errorf("cross-asset forwarding from %v to %v "+
+			"not supported", fromAsset, toAsset)
+	}
+
+	amt, err := h.rates.ConvertAmount(fromAsset, toAsset, incomingAmt)
+	if err != nil {
+		return 0, err
+	}
+
+	hswcLog.Debugf("Converted %v of %v into %v of %v for forwarded HTLC",
+		incomingAmt, fromAsset, amt, toAsset)
+
+	return amt, nil
+}
+
+// asset returns the asset the link's channel is denominated in. Channels
+// without an asset ID are treated as plain bitcoin channels.
+func (l *link) asset() string {
+	if l.assetID == "" {
+		return btcAssetID
+	}
+	return l.assetID
+}
+
 // networkAdmin is responsible for handline requests to register, unregister,
 // and close any link. In the event that a unregister requests leaves an
 // interface with no active links, that interface is garbage collected.
@@ -250,6 +357,8 @@ out:
 				h.handleUnregisterLink(req)
 			case *linkInfoUpdateMsg:
 				h.handleLinkUpdate(req)
+			case *linkBandwidthReq:
+				h.handleLinkBandwidth(req)
 			}
 		case <-h.quit:
 			break out
@@ -264,6 +373,7 @@ func (h *htlcSwitch) handleRegisterLink(req *registerLinkMsg) {
 	chanPoint := req.linkInfo.ChannelPoint
 	newLink := &link{
 		capacity:           req.linkInfo.Capacity,
+		assetID:            req.linkInfo.AssetID,
 		availableBandwidth: req.linkInfo.LocalBalance,
 		linkChan:           req.linkChan,
 		peer:               req.peer,
@@ -352,6 +462,21 @@ func (h *htlcSwitch) handleLinkUpdate(req *linkInfoUpdateMsg) {
 		req.bandwidthDelta)
 }
 
+// handleLinkBandwidth responds with the available bandwidth of each link to
+// the target interface which is denominated in the requested asset.
+func (h *htlcSwitch) handleLinkBandwidth(req *linkBandwidthReq) {
+	bandwidths := make(map[wire.OutPoint]btcutil.Amount)
+	for _, link := range h.interfaces[req.chanInterface] {
+		if link.assetID != req.assetID {
+			continue
+		}
+
+		bandwidths[*link.chanPoint] = link.availableBandwidth
+	}
+
+	req.resp <- bandwidths
+}
+
 // registerLinkMsg is message which requests a new link to be registered.
 type registerLinkMsg struct {
 	peer     *peer
@@ -404,25 +529,32 @@ type closeLinkReq struct {
 	chanPoint  *wire.OutPoint
 	forceClose bool
 
+	// deliveryScript, if non-nil, overrides our delivery script for a
+	// cooperative closure.
+	deliveryScript []byte
+
 	updates chan *lnrpc.CloseStatusUpdate
 	err     chan error
 }
 
 // CloseLink closes an active link targetted by it's channel point. Closing the
 // link initiates a cooperative channel closure iff forceClose is false. If
-// forceClose is true, then a unilateral channel closure is executed.
+// forceClose is true, then a unilateral channel closure is executed. If
+// deliveryScript is non-nil, then our settled balance within a cooperative
+// closure is sent to it rather than to our negotiated delivery script.
 // TODO(roabeef): bool flag for timeout
-func (h *htlcSwitch) CloseLink(chanPoint *wire.OutPoint,
-	forceClose bool) (chan *lnrpc.CloseStatusUpdate, chan error) {
+func (h *htlcSwitch) CloseLink(chanPoint *wire.OutPoint, forceClose bool,
+	deliveryScript []byte) (chan *lnrpc.CloseStatusUpdate, chan error) {
 
 	updateChan := make(chan *lnrpc.CloseStatusUpdate, 1)
 	errChan := make(chan error, 1)
 
 	h.linkControl <- &closeLinkReq{
-		chanPoint:  chanPoint,
-		forceClose: forceClose,
-		updates:    updateChan,
-		err:        errChan,
+		chanPoint:      chanPoint,
+		forceClose:     forceClose,
+		deliveryScript: deliveryScript,
+		updates:        updateChan,
+		err:            errChan,
 	}
 
 	return updateChan, errChan
@@ -443,3 +575,24 @@ type linkInfoUpdateMsg struct {
 func (h *htlcSwitch) UpdateLink(chanPoint *wire.OutPoint, bandwidthDelta btcutil.Amount) {
 	h.linkControl <- &linkInfoUpdateMsg{chanPoint, bandwidthDelta}
 }
+
+// linkBandwidthReq is a request for the available bandwidth of each link to a
+// target interface which is denominated in a particular asset.
+type linkBandwidthReq struct {
+	chanInterface wire.ShaHash
+	assetID       string
+
+	resp chan map[wire.OutPoint]btcutil.Amount
+}
+
+// LinkBandwidths returns the available bandwidth of each link to the target
+// interface denominated in the passed asset, keyed by the channel point of the
+// link. An empty assetID selects links paying satoshis.
+func (h *htlcSwitch) LinkBandwidths(chanInterface wire.ShaHash,
+	assetID string) map[wire.OutPoint]btcutil.Amount {
+
+	resp := make(chan map[wire.OutPoint]btcutil.Amount, 1)
+	h.linkControl <- &linkBandwidthReq{chanInterface, assetID, resp}
+
+	return <-resp
+}
@@ -2,76 +2,131 @@ package main
 
 import (
 	"bytes"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
 )
 
-// invoice represents a payment invoice which will be dispatched via the
-// Lightning Network.
-type invoice struct {
-	value btcutil.Amount
-
-	paymentHash     wire.ShaHash
-	paymentPreimage wire.ShaHash
-
-	// TODO(roasbeef): other contract stuff
-}
-
 // invoiceRegistry is a central registry of all the outstanding invoices
-// created by the daemon. The registry is a thin wrapper around a map in order
-// to ensure that all updates/reads are thread safe.
+// created by the daemon. The registry is a thin wrapper around the invoices
+// stored within the channel database, along with a set of in-memory debug
+// invoices, in order to ensure that all updates/reads are thread safe.
 type invoiceRegistry struct {
 	sync.RWMutex
-	invoiceIndex map[wire.ShaHash]*invoice
+
+	cdb *channeldb.DB
+
+	// debugInvoices is a map which stores special "debug" invoices which
+	// should only be created/used when manual tests require an invoice
+	// that *all* nodes are able to fully settle.
+	debugInvoices map[wire.ShaHash]*channeldb.Invoice
 }
 
-// newInvoiceRegistry creates a new invoice registry.
-func newInvoiceRegistry() *invoiceRegistry {
+// newInvoiceRegistry creates a new invoice registry backed by the passed
+// channel database.
+func newInvoiceRegistry(cdb *channeldb.DB) *invoiceRegistry {
 	return &invoiceRegistry{
-		invoiceIndex: make(map[wire.ShaHash]*invoice),
+		cdb:           cdb,
+		debugInvoices: make(map[wire.ShaHash]*channeldb.Invoice),
 	}
 }
 
-// addInvoice adds an invoice for the specified amount, identified by the
-// passed preimage. Once this invoice is added, sub-systems within the daemon
-// add/forward HTLC's are able to obtain the proper preimage required for
-// redemption in the case that we're the final destination.
-func (i *invoiceRegistry) addInvoice(amt btcutil.Amount, preimage wire.ShaHash) {
+// addDebugInvoice adds a debug invoice for the specified amount, identified
+// by the passed preimage. Debug invoices are payable in any asset, and are
+// never written to the database.
+func (i *invoiceRegistry) addDebugInvoice(amt btcutil.Amount, preimage wire.ShaHash) {
 	paymentHash := wire.ShaHash(fastsha256.Sum256(preimage[:]))
 
-	i.Lock()
-	i.invoiceIndex[paymentHash] = &invoice{
-		value:           amt,
-		paymentHash:     paymentHash,
-		paymentPreimage: preimage,
+	invoice := &channeldb.Invoice{
+		CreationDate: time.Now(),
+		Terms: channeldb.ContractTerm{
+			Value:           amt,
+			PaymentPreimage: preimage,
+		},
 	}
+
+	i.Lock()
+	i.debugInvoices[paymentHash] = invoice
 	i.Unlock()
+
+	ltndLog.Debugf("Adding debug invoice: hash=%v, amt=%v", paymentHash,
+		amt)
+}
+
+// addInvoice adds an invoice, denominated in the asset of its contract terms,
+// to the database. Once this invoice is added, sub-systems within the daemon
+// add/forward HTLC's are able to obtain the proper preimage required for
+// redemption in the case that we're the final destination.
+func (i *invoiceRegistry) addInvoice(invoice *channeldb.Invoice) error {
+	return i.cdb.AddInvoice(invoice)
 }
 
 // lookupInvoice looks up an invoice by it's payment hash (R-Hash), if found
-// then we're able to pull the funds pending within an HTLC.
-func (i *invoiceRegistry) lookupInvoice(hash wire.ShaHash) (*invoice, bool) {
+// then we're able to pull the funds pending within an HTLC, once the HTLC
+// has been checked against the invoice's terms.
+func (i *invoiceRegistry) lookupInvoice(rHash wire.ShaHash) (*channeldb.Invoice, error) {
 	i.RLock()
-	inv, ok := i.invoiceIndex[hash]
+	invoice, ok := i.debugInvoices[rHash]
 	i.RUnlock()
+	if ok {
+		return invoice, nil
+	}
 
-	return inv, ok
+	return i.cdb.LookupInvoice(rHash)
+}
+
+// settleInvoice marks the invoice identified by the passed payment hash as
+// fully settled. Debug invoices may be settled any number of times, so they
+// are left untouched.
+func (i *invoiceRegistry) settleInvoice(rHash wire.ShaHash) error {
+	i.RLock()
+	_, ok := i.debugInvoices[rHash]
+	i.RUnlock()
+	if ok {
+		return nil
+	}
+
+	return i.cdb.SettleInvoice(rHash)
+}
+
+// checkInvoiceTerms returns an error if an HTLC of the passed amount, extended
+// over a channel of the passed asset, doesn't satisfy the terms of the
+// invoice. As the payment of an exotic asset is only meaningful in that asset,
+// an HTLC of any other asset, or of an insufficient amount, isn't accepted.
+// Debug invoices accept an HTLC of any asset.
+func (i *invoiceRegistry) checkInvoiceTerms(invoice *channeldb.Invoice,
+	assetID string, amt btcutil.Amount) error {
+
+	paymentHash := wire.ShaHash(fastsha256.Sum256(
+		invoice.Terms.PaymentPreimage[:]))
+
+	i.RLock()
+	_, isDebug := i.debugInvoices[paymentHash]
+	i.RUnlock()
+
+	switch {
+	case invoice.Terms.Settled && !isDebug:
+		return fmt.Errorf("invoice %v already settled", paymentHash)
+
+	case invoice.Terms.AssetID != assetID && !isDebug:
+		return fmt.Errorf("invoice %v is payable in asset %q, htlc "+
+			"extended in asset %q", paymentHash,
+			invoice.Terms.AssetID, assetID)
+
+	case amt < invoice.Terms.Value:
+		return fmt.Errorf("invoice %v requires %v, htlc only pays %v",
+			paymentHash, invoice.Terms.Value, amt)
+	}
+
+	return nil
 }
 
 var (
 	debugPre, _ = wire.NewShaHash(bytes.Repeat([]byte{1}, 32))
 	debugHash   = wire.ShaHash(fastsha256.Sum256(debugPre[:]))
 )
-
-// debugInvoice is a fake invoice created for debugging purposes within the
-// daemon.
-func (i *invoiceRegistry) debugInvoice() *invoice {
-	return &invoice{
-		value:           btcutil.Amount(100000 * 1e8),
-		paymentPreimage: *debugPre,
-		paymentHash:     debugHash,
-	}
-}
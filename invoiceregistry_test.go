@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestCheckInvoiceTerms tests that an incoming HTLC only satisfies an asset
+// denominated invoice if it's extended in the same asset, for at least the
+// invoice's amount.
+func TestCheckInvoiceTerms(t *testing.T) {
+	registry := newInvoiceRegistry(nil)
+	registry.addDebugInvoice(1000, *debugPre)
+
+	assetInvoice := &channeldb.Invoice{
+		Terms: channeldb.ContractTerm{
+			PaymentPreimage: [32]byte{2},
+			Value:           500,
+			AssetID:         "assetA",
+		},
+	}
+	debugInvoice, err := registry.lookupInvoice(debugHash)
+	if err != nil {
+		t.Fatalf("unable to find debug invoice: %v", err)
+	}
+
+	testCases := []struct {
+		invoice *channeldb.Invoice
+		assetID string
+		amt     btcutil.Amount
+		valid   bool
+	}{
+		{assetInvoice, "assetA", 500, true},
+		{assetInvoice, "assetA", 501, true},
+		{assetInvoice, "assetA", 499, false},
+		{assetInvoice, "assetB", 500, false},
+		{assetInvoice, "", 500, false},
+
+		// The debug invoice can be paid in any asset.
+		{debugInvoice, "assetB", 1000, true},
+		{debugInvoice, "", 999, false},
+	}
+	for i, testCase := range testCases {
+		err := registry.checkInvoiceTerms(testCase.invoice,
+			testCase.assetID, testCase.amt)
+		if testCase.valid && err != nil {
+			t.Fatalf("#%v: htlc should satisfy invoice: %v", i, err)
+		}
+		if !testCase.valid && err == nil {
+			t.Fatalf("#%v: htlc shouldn't satisfy invoice", i)
+		}
+	}
+
+	// Once settled, an asset invoice can't be paid again.
+	assetInvoice.Terms.Settled = true
+	err = registry.checkInvoiceTerms(assetInvoice, "assetA", 500)
+	if err == nil {
+		t.Fatalf("settled invoice shouldn't be payable")
+	}
+
+	// Debug invoices are never recorded as settled.
+	if err := registry.settleInvoice(debugHash); err != nil {
+		t.Fatalf("unable to settle debug invoice: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/codahale/chacha20poly1305"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// keysendRecordType is the first byte of an HTLC payload which carries
+	// the payment preimage, encrypted to the receiver.
+	keysendRecordType = 0x6b
+
+	// keysendRecordSize is the size of a keysend record: the record type,
+	// an ephemeral public key, then the encrypted preimage along with its
+	// authentication tag.
+	keysendRecordSize = 1 + 33 + 32 + chacha20poly1305.TagSize
+)
+
+var (
+	// errNoKeysendRecord is returned when an HTLC's payload doesn't carry
+	// a keysend record.
+	errNoKeysendRecord = errors.New("payload carries no keysend record")
+
+	// keysendNonce is the nonce used to encrypt the preimage within a
+	// keysend record. As the encryption key is derived from a fresh
+	// ephemeral key for each record, a fixed nonce is safe.
+	keysendNonce [chacha20poly1305.NonceSize]byte
+)
+
+// newKeysendRecord creates a payload for an HTLC which allows the receiver,
+// identified by the passed public key, to settle the HTLC without a prior
+// invoice. The preimage is encrypted to the receiver under a key derived via
+// ECDH with an ephemeral key, and authenticated along with its payment hash.
+func newKeysendRecord(destKey *btcec.PublicKey, preimage [32]byte) ([]byte, error) {
+	ephemeralPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+
+	sessionKey := fastsha256.Sum256(
+		btcec.GenerateSharedSecret(ephemeralPriv, destKey),
+	)
+	aead, err := chacha20poly1305.New(sessionKey[:])
+	if err != nil {
+		return nil, err
+	}
+
+	paymentHash := fastsha256.Sum256(preimage[:])
+
+	record := make([]byte, 0, keysendRecordSize)
+	record = append(record, keysendRecordType)
+	record = append(record, ephemeralPriv.PubKey().SerializeCompressed()...)
+	record = aead.Seal(record, keysendNonce[:], preimage[:], paymentHash[:])
+
+	return record, nil
+}
+
+// decodeKeysendRecord decrypts the preimage carried within the keysend record
+// of an HTLC's payload, using our identity key. If the payload doesn't carry a
+// keysend record, then errNoKeysendRecord is returned. An error is also
+// returned if the preimage wasn't encrypted to us, or doesn't match the
+// payment hash of the HTLC.
+func decodeKeysendRecord(payload []byte, identityPriv *btcec.PrivateKey,
+	paymentHash [32]byte) ([32]byte, error) {
+
+	var preimage [32]byte
+	if len(payload) != keysendRecordSize || payload[0] != keysendRecordType {
+		return preimage, errNoKeysendRecord
+	}
+
+	ephemeralPub, err := btcec.ParsePubKey(payload[1:34], btcec.S256())
+	if err != nil {
+		return preimage, err
+	}
+
+	sessionKey := fastsha256.Sum256(
+		btcec.GenerateSharedSecret(identityPriv, ephemeralPub),
+	)
+	aead, err := chacha20poly1305.New(sessionKey[:])
+	if err != nil {
+		return preimage, err
+	}
+
+	plaintext, err := aead.Open(nil, keysendNonce[:], payload[34:],
+		paymentHash[:])
+	if err != nil {
+		return preimage, fmt.Errorf("unable to decrypt keysend "+
+			"record: %v", err)
+	}
+	copy(preimage[:], plaintext)
+
+	// The payment hash is authenticated along with the preimage, but we
+	// ensure the preimage actually settles the HTLC before revealing it.
+	if fastsha256.Sum256(preimage[:]) != paymentHash {
+		return preimage, fmt.Errorf("keysend preimage doesn't match "+
+			"payment hash %x", paymentHash[:])
+	}
+
+	return preimage, nil
+}
+
+// sendKeysendPayment sends a spontaneous payment of the passed amount to the
+// node identified by the passed public key, without requiring an invoice from
+// the receiver. A fresh preimage is generated, and encrypted to the receiver
+// within the HTLC's payload. The preimage is returned once the payment has
+// been sent.
+func (s *server) sendKeysendPayment(destKey *btcec.PublicKey,
+	amt btcutil.Amount) ([32]byte, error) {
+
+	var preimage [32]byte
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return preimage, err
+	}
+
+	record, err := newKeysendRecord(destKey, preimage)
+	if err != nil {
+		return preimage, err
+	}
+
+	htlcAdd := &lnwire.HTLCAddRequest{
+		Amount:           lnwire.CreditsAmount(amt),
+		RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage[:])},
+		OnionBlob:        record,
+	}
+	htlcPkt := &htlcPacket{
+		dest: wire.ShaHash(fastsha256.Sum256(
+			destKey.SerializeCompressed())),
+		msg: htlcAdd,
+	}
+	if err := s.htlcSwitch.SendHTLC(htlcPkt); err != nil {
+		return preimage, err
+	}
+
+	return preimage, nil
+}
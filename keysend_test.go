@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// TestKeysendRecord tests that the preimage within a keysend record can only
+// be recovered by the receiver it was encrypted to, and only for the HTLC
+// paying to its hash.
+func TestKeysendRecord(t *testing.T) {
+	receiverPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	otherPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	preimage := [32]byte{7}
+	paymentHash := fastsha256.Sum256(preimage[:])
+
+	record, err := newKeysendRecord(receiverPriv.PubKey(), preimage)
+	if err != nil {
+		t.Fatalf("unable to create keysend record: %v", err)
+	}
+	if len(record) != keysendRecordSize {
+		t.Fatalf("expected record of %v bytes, got %v",
+			keysendRecordSize, len(record))
+	}
+
+	decoded, err := decodeKeysendRecord(record, receiverPriv, paymentHash)
+	if err != nil {
+		t.Fatalf("unable to decode keysend record: %v", err)
+	}
+	if decoded != preimage {
+		t.Fatalf("expected preimage %x, got %x", preimage, decoded)
+	}
+
+	// Only the receiver should be able to decrypt the preimage.
+	if _, err := decodeKeysendRecord(record, otherPriv, paymentHash); err == nil {
+		t.Fatalf("record decrypted with wrong key")
+	}
+
+	// The record is bound to the payment hash of its HTLC.
+	if _, err := decodeKeysendRecord(record, receiverPriv, [32]byte{}); err == nil {
+		t.Fatalf("record accepted for wrong payment hash")
+	}
+
+	// Payloads which don't carry a record should be distinguished.
+	_, err = decodeKeysendRecord([]byte{1, 2, 3}, receiverPriv, paymentHash)
+	if err != errNoKeysendRecord {
+		t.Fatalf("expected errNoKeysendRecord, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcutil"
+)
+
+// AssetLiquidity is the combined liquidity of all open channels with a single
+// peer which are denominated in the same asset. Balances only reflect the
+// settled state of each channel, with HTLCs still in flight reported
+// separately.
+type AssetLiquidity struct {
+	// LightningID identifies the remote peer.
+	LightningID [32]byte
+
+	// AssetID is the asset the channels are denominated in. An empty
+	// AssetID denotes channels paying satoshis.
+	AssetID string
+
+	// NumChannels is the number of channels aggregated.
+	NumChannels int
+
+	// Capacity is the combined capacity of all channels.
+	Capacity btcutil.Amount
+
+	// LocalBalance and RemoteBalance are the combined settled balances of
+	// our side and the peer's side across all channels.
+	LocalBalance  btcutil.Amount
+	RemoteBalance btcutil.Amount
+
+	// NumPendingHTLCs is the number of HTLCs still in flight across all
+	// channels.
+	NumPendingHTLCs int
+
+	// PendingIncoming is the amount locked within HTLCs offered to us by
+	// the peer, which we'll receive if settled.
+	PendingIncoming btcutil.Amount
+
+	// PendingOutgoing is the amount locked within HTLCs we've offered to
+	// the peer, which we'll lose if settled.
+	PendingOutgoing btcutil.Amount
+}
+
+// liquidityKey identifies a peer and asset pair whose channels are
+// aggregated.
+type liquidityKey struct {
+	lightningID [32]byte
+	assetID     string
+}
+
+// byPeerAndAsset sorts liquidity reports by peer, then asset, in order to
+// yield reports in a deterministic order.
+type byPeerAndAsset []*AssetLiquidity
+
+func (b byPeerAndAsset) Len() int      { return len(b) }
+func (b byPeerAndAsset) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byPeerAndAsset) Less(i, j int) bool {
+	cmp := bytes.Compare(b[i].LightningID[:], b[j].LightningID[:])
+	if cmp != 0 {
+		return cmp < 0
+	}
+	return b[i].AssetID < b[j].AssetID
+}
+
+// aggregateLiquidity groups the passed channel snapshots by remote peer and
+// asset, summing the balances and pending HTLCs within each group.
+func aggregateLiquidity(snapshots []*channeldb.ChannelSnapshot) []*AssetLiquidity {
+	groups := make(map[liquidityKey]*AssetLiquidity)
+	for _, snapshot := range snapshots {
+		key := liquidityKey{snapshot.RemoteID, snapshot.AssetID}
+		group, ok := groups[key]
+		if !ok {
+			group = &AssetLiquidity{
+				LightningID: snapshot.RemoteID,
+				AssetID:     snapshot.AssetID,
+			}
+			groups[key] = group
+		}
+
+		group.NumChannels++
+		group.Capacity += snapshot.Capacity
+		group.LocalBalance += snapshot.LocalBalance
+		group.RemoteBalance += snapshot.RemoteBalance
+
+		for _, htlc := range snapshot.Htlcs {
+			group.NumPendingHTLCs++
+			if htlc.Incoming {
+				group.PendingIncoming += htlc.Amt
+			} else {
+				group.PendingOutgoing += htlc.Amt
+			}
+		}
+	}
+
+	report := make(byPeerAndAsset, 0, len(groups))
+	for _, group := range groups {
+		report = append(report, group)
+	}
+	sort.Sort(report)
+
+	return report
+}
+
+// LiquidityReport returns the liquidity of all open channels, grouped by
+// remote peer and asset, allowing the colored inventory held within our
+// channels to be monitored.
+func (s *server) LiquidityReport() []*AssetLiquidity {
+	var snapshots []*channeldb.ChannelSnapshot
+	for _, peer := range s.Peers() {
+		snapshots = append(snapshots, peer.ChannelSnapshots()...)
+	}
+
+	return aggregateLiquidity(snapshots)
+}
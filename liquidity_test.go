@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+)
+
+// TestAggregateLiquidity tests that channel balances and pending HTLCs are
+// summed per peer and asset.
+func TestAggregateLiquidity(t *testing.T) {
+	alice, bob := [32]byte{1}, [32]byte{2}
+	snapshots := []*channeldb.ChannelSnapshot{
+		{
+			RemoteID:      bob,
+			AssetID:       "assetA",
+			Capacity:      1000,
+			LocalBalance:  600,
+			RemoteBalance: 300,
+			Htlcs: []channeldb.HTLC{
+				{Incoming: true, Amt: 60},
+				{Incoming: false, Amt: 40},
+			},
+		},
+		{
+			RemoteID:      alice,
+			AssetID:       "assetA",
+			Capacity:      500,
+			LocalBalance:  500,
+			RemoteBalance: 0,
+		},
+		{
+			RemoteID:      bob,
+			AssetID:       "assetA",
+			Capacity:      2000,
+			LocalBalance:  100,
+			RemoteBalance: 1880,
+			Htlcs: []channeldb.HTLC{
+				{Incoming: true, Amt: 20},
+			},
+		},
+		{
+			RemoteID:      bob,
+			AssetID:       "",
+			Capacity:      1e8,
+			LocalBalance:  5e7,
+			RemoteBalance: 5e7,
+		},
+	}
+
+	report := aggregateLiquidity(snapshots)
+	expected := []AssetLiquidity{
+		{
+			LightningID:   alice,
+			AssetID:       "assetA",
+			NumChannels:   1,
+			Capacity:      500,
+			LocalBalance:  500,
+			RemoteBalance: 0,
+		},
+		{
+			LightningID:   bob,
+			AssetID:       "",
+			NumChannels:   1,
+			Capacity:      1e8,
+			LocalBalance:  5e7,
+			RemoteBalance: 5e7,
+		},
+		{
+			LightningID:     bob,
+			AssetID:         "assetA",
+			NumChannels:     2,
+			Capacity:        3000,
+			LocalBalance:    700,
+			RemoteBalance:   2180,
+			NumPendingHTLCs: 3,
+			PendingIncoming: 80,
+			PendingOutgoing: 40,
+		},
+	}
+	if len(report) != len(expected) {
+		t.Fatalf("expected %v groups, got %v", len(expected), len(report))
+	}
+	for i, group := range report {
+		if *group != expected[i] {
+			t.Fatalf("group #%v: expected %+v, got %+v", i,
+				expected[i], *group)
+		}
+	}
+}
@@ -16,10 +16,13 @@ import (
 
 	"github.com/lightningnetwork/lnd/chainntnfs/btcdnotify"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
+	"github.com/lightningnetwork/lnd/metrics"
 	"github.com/roasbeef/btcrpcclient"
+	"github.com/roasbeef/btcutil"
 )
 
 var (
@@ -48,6 +51,10 @@ func lndMain() error {
 		return err
 	}
 
+	// Raw transactions are only dumped in full within the log if
+	// requested, as doing so is expensive.
+	lnwallet.EnableTxDumps(cfg.DumpTxns)
+
 	// Enable http profiling server if requested.
 	if cfg.Profile != "" {
 		go func() {
@@ -68,6 +75,15 @@ func lndMain() error {
 	}
 	defer chanDB.Close()
 
+	// If requested, prune the records no longer required from the
+	// channeldb, then compact it before it's put to use.
+	if cfg.CompactDB {
+		if err := compactChanDB(chanDB); err != nil {
+			fmt.Println("unable to compact channeldb: ", err)
+			return err
+		}
+	}
+
 	// Next load btcd's TLS cert for the RPC connection. If a raw cert was
 	// specified in the config, then we'll se that directly. Otherwise, we
 	// attempt to read the cert from the path specified in the config.
@@ -119,13 +135,14 @@ func lndMain() error {
 
 	// TODO(roasbeef): paarse config here select chosen WalletController
 	walletConfig := &btcwallet.Config{
-		PrivatePass: []byte("hello"),
-		DataDir:     filepath.Join(loadedConfig.DataDir, "lnwallet"),
-		RpcHost:     fmt.Sprintf("%v:%v", rpcIP[0], activeNetParams.rpcPort),
-		RpcUser:     loadedConfig.RPCUser,
-		RpcPass:     loadedConfig.RPCPass,
-		CACert:      rpcCert,
-		NetParams:   activeNetParams.Params,
+		PrivatePass:    []byte("hello"),
+		DataDir:        filepath.Join(loadedConfig.DataDir, "lnwallet"),
+		RpcHost:        fmt.Sprintf("%v:%v", rpcIP[0], activeNetParams.rpcPort),
+		RpcUser:        loadedConfig.RPCUser,
+		RpcPass:        loadedConfig.RPCPass,
+		CACert:         rpcCert,
+		NetParams:      activeNetParams.Params,
+		ChannelAccount: cfg.ChannelAccount,
 	}
 	wc, err := btcwallet.New(walletConfig)
 	if err != nil {
@@ -135,10 +152,45 @@ func lndMain() error {
 	signer := wc
 	bio := wc
 
+	// On simnet, the color of outputs may be derived locally rather than
+	// through the colored coins services, so no external infrastructure
+	// is needed for development and testing.
+	if cfg.TrustLocalColor {
+		kernel, err := newTrustLocalKernel(bio,
+			os.Getenv("CC_ASSET_ID"), cfg.ColorIssuances)
+		if err != nil {
+			fmt.Printf("unable to create local color kernel: %v\n", err)
+			return err
+		}
+		lndcc.UseLocalKernel(kernel)
+		ltndLog.Infof("Using local color kernel with %v issuances",
+			len(cfg.ColorIssuances))
+	}
+
 	// Create, and start the lnwallet, which handles the core payment
 	// channel logic, and exposes control via proxy state machines.
-	wallet, err := lnwallet.NewLightningWallet(chanDB, notifier,
-		wc, signer, bio, activeNetParams.Params)
+	assetMaxChanCapacity, err := cfg.assetMaxChanCapacity()
+	if err != nil {
+		fmt.Printf("unable to parse max channel capacities: %v\n", err)
+		return err
+	}
+	walletPolicy := &lnwallet.Config{
+		MinCsvDelay:          cfg.MinCsvDelay,
+		MaxCsvDelay:          cfg.MaxCsvDelay,
+		MaxChanCapacity:      cfg.maxChanCapacity(),
+		AssetMaxChanCapacity: assetMaxChanCapacity,
+		AllowedAssets:        cfg.allowedAssets(),
+		DeniedAssets:         cfg.deniedAssets(),
+		LowFuelThreshold:     btcutil.Amount(cfg.LowFuelThreshold),
+		ReadOnly:             cfg.ReadOnly,
+		ShaChain:             cfg.ShaChain,
+	}
+	if cfg.ReadOnly {
+		ltndLog.Warn("Wallet is read-only, channels won't be able " +
+			"to advance, close, or sweep their outputs")
+	}
+	wallet, err := lnwallet.NewLightningWallet(walletPolicy, chanDB,
+		notifier, wc, signer, bio, activeNetParams.Params)
 	if err != nil {
 		fmt.Printf("unable to create wallet: %v\n", err)
 		return err
@@ -149,6 +201,13 @@ func lndMain() error {
 	}
 	ltndLog.Info("LightningWallet opened")
 
+	// Ensure the asset we're configured to operate on was issued on the
+	// network we're operating on, before any channels are opened for it.
+	if err := wallet.ValidateAsset(); err != nil {
+		fmt.Printf("unable to validate asset: %v\n", err)
+		return err
+	}
+
 	// Set up the core server which will listen for incoming peer
 	// connections.
 	defaultListenAddrs := []string{
@@ -164,6 +223,24 @@ func lndMain() error {
 		return err
 	}
 
+	// Serve the metrics recorded by the daemon's subsystems if requested.
+	if cfg.MetricsListen != "" {
+		server.registerMetrics()
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", metrics.DefaultRegistry)
+
+			ltndLog.Infof("Metrics server listening on %s",
+				cfg.MetricsListen)
+			err := http.ListenAndServe(cfg.MetricsListen, mux)
+			ltndLog.Errorf("metrics server stopped: %v", err)
+		}()
+	}
+
+	// Expose the volatile state of the wallet and our channels alongside
+	// the profiling endpoints, so it may be inspected at runtime.
+	http.Handle("/debug/lnwallet/", server.newInspector())
+
 	addInterruptHandler(func() {
 		ltndLog.Infof("Gracefully shutting down the server...")
 		server.Stop()
@@ -193,6 +270,40 @@ func lndMain() error {
 	return nil
 }
 
+// compactChanDB prunes the records of closed channels, along with the
+// revocation data of open channels beyond the configured retention, from the
+// channeldb, then compacts it. As compaction requires exclusive access to the
+// database, this MUST only be called before the database is put to use.
+func compactChanDB(chanDB *channeldb.DB) error {
+	before, err := chanDB.SizeMetrics()
+	if err != nil {
+		return err
+	}
+
+	summary, err := chanDB.PruneChannelState(&channeldb.PruneConfig{
+		RevocationRetention: cfg.RevocationRetention,
+	})
+	if err != nil {
+		return err
+	}
+	ltndLog.Infof("Pruned %v htlc sets, %v channel deltas, and %v "+
+		"revoked commitments from channeldb", summary.NumHtlcRecords,
+		summary.NumChannelDeltas, summary.NumRevokedCommits)
+
+	if err := chanDB.Compact(); err != nil {
+		return err
+	}
+
+	after, err := chanDB.SizeMetrics()
+	if err != nil {
+		return err
+	}
+	ltndLog.Infof("Compacted channeldb from %v to %v bytes",
+		before.FileSize, after.FileSize)
+
+	return nil
+}
+
 func main() {
 	// Use all processor cores.
 	// TODO(roasbeef): remove this if required version # is > 1.6?
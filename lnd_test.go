@@ -11,6 +11,7 @@ import (
 
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/roasbeef/btcd/rpctest"
+	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcrpcclient"
 	"github.com/roasbeef/btcutil"
@@ -33,7 +34,7 @@ func assertTxInBlock(block *btcutil.Block, txid *wire.ShaHash, t *testing.T) {
 // waiting for channels to open/close.
 func getChannelHelpers(ctxb context.Context, net *networkHarness,
 	t *testing.T) (func(*lightningNode, *lightningNode, btcutil.Amount) *lnrpc.ChannelPoint,
-	func(*lightningNode, *lnrpc.ChannelPoint)) {
+	func(*lightningNode, *lnrpc.ChannelPoint) *wire.ShaHash) {
 
 	openChannel := func(alice *lightningNode, bob *lightningNode, amount btcutil.Amount) *lnrpc.ChannelPoint {
 		chanOpenUpdate, err := net.OpenChannel(ctxb, alice, bob, amount, 1)
@@ -76,7 +77,7 @@ func getChannelHelpers(ctxb context.Context, net *networkHarness,
 		return fundingChanPoint
 	}
 
-	closeChannel := func(node *lightningNode, fundingChanPoint *lnrpc.ChannelPoint) {
+	closeChannel := func(node *lightningNode, fundingChanPoint *lnrpc.ChannelPoint) *wire.ShaHash {
 		closeUpdates, err := net.CloseChannel(ctxb, node, fundingChanPoint, false)
 		if err != nil {
 			t.Fatalf("unable to close channel: %v", err)
@@ -100,6 +101,7 @@ func getChannelHelpers(ctxb context.Context, net *networkHarness,
 		}
 		assertTxInBlock(block, closingTxid, t)
 
+		return closingTxid
 	}
 
 	return openChannel, closeChannel
@@ -291,10 +293,246 @@ mempoolPoll:
 	assertTxInBlock(block, sweepTx.Sha(), t)
 }
 
-var lndTestCases = map[string]lndTestCase{
-	"basic funding flow":    testBasicChannelFunding,
-	"channel force closure": testChannelForceClosure,
-	"channel balance":       testChannelBalance,
+// sendPayments sends numPayments payments of amt each from one node to the
+// other over their direct channel, blocking until each has been acknowledged
+// by the sender.
+func sendPayments(ctxb context.Context, from, to *lightningNode,
+	amt btcutil.Amount, numPayments int, t *testing.T) {
+
+	sendStream, err := from.SendPayment(ctxb)
+	if err != nil {
+		t.Fatalf("unable to create payment stream: %v", err)
+	}
+	req := &lnrpc.SendRequest{
+		Dest: to.LightningID[:],
+		Amt:  int64(amt),
+	}
+	for i := 0; i < numPayments; i++ {
+		if err := sendStream.Send(req); err != nil {
+			t.Fatalf("unable to send payment: %v", err)
+		}
+	}
+	for i := 0; i < numPayments; i++ {
+		if _, err := sendStream.Recv(); err != nil {
+			t.Fatalf("error when attempting recv: %v", err)
+		}
+	}
+}
+
+// waitForChannelBalance blocks until the channel balance of the target node
+// reaches the passed amount, failing the test after a timeout.
+func waitForChannelBalance(ctxb context.Context, node *lightningNode,
+	amt btcutil.Amount, t *testing.T) {
+
+	var balance btcutil.Amount
+	timeout := time.After(time.Second * 10)
+	for {
+		resp, err := node.ChannelBalance(ctxb, &lnrpc.ChannelBalanceRequest{})
+		if err != nil {
+			t.Fatalf("unable to get channel balance: %v", err)
+		}
+		balance = btcutil.Amount(resp.Balance)
+		if balance == amt {
+			return
+		}
+
+		select {
+		case <-timeout:
+			t.Fatalf("channel balance of %v never reached, instead "+
+				"have %v", amt, balance)
+		case <-time.After(time.Millisecond * 100):
+		}
+	}
+}
+
+// testColoredCooperativeClose creates a new channel between Alice and Bob
+// denominated in the harness' asset, sends several payments from Alice to
+// Bob, then cooperatively closes the channel. The outputs of the closing
+// transaction should carry the final balance of each side, in the asset the
+// channel was funded with.
+func testColoredCooperativeClose(net *networkHarness, t *testing.T) {
+	ctxb := context.Background()
+	openChannel, closeChannel := getChannelHelpers(ctxb, net, t)
+
+	const numPayments = 5
+	chanAmt := btcutil.Amount(5e7)
+	paymentAmt := btcutil.Amount(1e6)
+	chanPoint := openChannel(net.Alice, net.Bob, chanAmt)
+
+	// The funding output should carry the full capacity of the channel.
+	fundingTxID, err := wire.NewShaHash(chanPoint.FundingTxid)
+	if err != nil {
+		t.Fatalf("unable to create sha hash: %v", err)
+	}
+	_, fundingAssets, err := net.FetchTxAssets(fundingTxID)
+	if err != nil {
+		t.Fatalf("unable to fetch funding tx assets: %v", err)
+	}
+	fundingAsset := fundingAssets[chanPoint.OutputIndex]
+	if fundingAsset.AssetId != harnessAssetID || fundingAsset.Value != chanAmt {
+		t.Fatalf("funding output carries %v, expected %v of %v",
+			fundingAsset, chanAmt, harnessAssetID)
+	}
+
+	// With the channel open, Alice sends a series of payments to Bob, after
+	// which the balances of both sides should reflect the payments.
+	sendPayments(ctxb, net.Alice, net.Bob, paymentAmt, numPayments, t)
+	bobAmt := paymentAmt * numPayments
+	aliceAmt := chanAmt - bobAmt
+	waitForChannelBalance(ctxb, net.Bob, bobAmt, t)
+	waitForChannelBalance(ctxb, net.Alice, aliceAmt, t)
+
+	// Once the channel is closed, the closing transaction should pay each
+	// side its balance in the channel's asset, with none of the capacity
+	// left unaccounted for.
+	closingTxID := closeChannel(net.Alice, chanPoint)
+	_, closingAssets, err := net.FetchTxAssets(closingTxID)
+	if err != nil {
+		t.Fatalf("unable to fetch closing tx assets: %v", err)
+	}
+
+	var closingAmts []btcutil.Amount
+	for _, txo := range closingAssets {
+		if txo.Value == 0 {
+			continue
+		}
+		if txo.AssetId != harnessAssetID {
+			t.Fatalf("closing output carries %v, expected %v",
+				txo, harnessAssetID)
+		}
+		closingAmts = append(closingAmts, txo.Value)
+	}
+	if len(closingAmts) != 2 {
+		t.Fatalf("expected 2 colored closing outputs, instead have %v",
+			len(closingAmts))
+	}
+	if !(closingAmts[0] == aliceAmt && closingAmts[1] == bobAmt) &&
+		!(closingAmts[0] == bobAmt && closingAmts[1] == aliceAmt) {
+		t.Fatalf("closing outputs carry %v, expected alice=%v, bob=%v",
+			closingAmts, aliceAmt, bobAmt)
+	}
+}
+
+// testColoredForceClosure creates a new channel between Alice and Bob
+// denominated in the harness' asset, sends a payment from Alice to Bob, then
+// force closes the channel from Alice's side. The broadcast commitment
+// transaction should carry Alice's balance within her delayed output, and
+// Bob's balance within the output paying to him directly.
+//
+// TODO: also assert the asset carried by the sweep of Alice's delayed output
+// once the nursery sweeps colored outputs.
+func testColoredForceClosure(net *networkHarness, t *testing.T) {
+	ctxb := context.Background()
+	openChannel, _ := getChannelHelpers(ctxb, net, t)
+
+	chanAmt := btcutil.Amount(5e7)
+	bobAmt := btcutil.Amount(2e6)
+	aliceAmt := chanAmt - bobAmt
+	chanPoint := openChannel(net.Alice, net.Bob, chanAmt)
+
+	sendPayments(ctxb, net.Alice, net.Bob, bobAmt, 1, t)
+	waitForChannelBalance(ctxb, net.Bob, bobAmt, t)
+	waitForChannelBalance(ctxb, net.Alice, aliceAmt, t)
+
+	closeUpdate, err := net.CloseChannel(ctxb, net.Alice, chanPoint, true)
+	if err != nil {
+		t.Fatalf("unable to execute force channel closure: %v", err)
+	}
+	if _, err := net.Miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block: %v", err)
+	}
+	closingTxID, err := net.WaitForChannelClose(closeUpdate)
+	if err != nil {
+		t.Fatalf("error while waiting for channel close: %v", err)
+	}
+
+	commitTx, commitAssets, err := net.FetchTxAssets(closingTxID)
+	if err != nil {
+		t.Fatalf("unable to fetch commitment tx assets: %v", err)
+	}
+
+	var delayedAmt, directAmt btcutil.Amount
+	for i, txOut := range commitTx.TxOut {
+		txo := commitAssets[i]
+		if txo.Value == 0 {
+			continue
+		}
+		if txo.AssetId != harnessAssetID {
+			t.Fatalf("commitment output carries %v, expected %v",
+				txo, harnessAssetID)
+		}
+
+		switch {
+		case txscript.IsPayToWitnessScriptHash(txOut.PkScript):
+			delayedAmt += txo.Value
+		case txscript.IsPayToWitnessPubKeyHash(txOut.PkScript):
+			directAmt += txo.Value
+		default:
+			t.Fatalf("unexpected colored commitment output: %v",
+				txOut.PkScript)
+		}
+	}
+	if delayedAmt != aliceAmt {
+		t.Fatalf("alice's delayed output carries %v, expected %v",
+			delayedAmt, aliceAmt)
+	}
+	if directAmt != bobAmt {
+		t.Fatalf("bob's output carries %v, expected %v", directAmt,
+			bobAmt)
+	}
+}
+
+// testAutoForceCloseNode tests that a node started with automatic force
+// closure enabled is fully operational.
+func testAutoForceCloseNode(net *networkHarness, t *testing.T) {
+	ctxb := context.Background()
+
+	node, err := newLightningNode(&net.rpcConfig,
+		[]string{"--autoforceclose", "--forceclosegrace=1m"})
+	if err != nil {
+		t.Fatalf("unable to create node: %v", err)
+	}
+	node.env = net.ColorService.Env()
+	defer node.shutdown()
+
+	if err := node.start(); err != nil {
+		t.Fatalf("unable to start node: %v", err)
+	}
+	if node.LightningClient == nil {
+		t.Fatalf("unable to connect to node")
+	}
+	if _, err := node.GetInfo(ctxb, &lnrpc.GetInfoRequest{}); err != nil {
+		t.Fatalf("node with auto force close isn't operational: %v",
+			err)
+	}
+
+	// The node must also be able to connect to a peer, which notifies
+	// the close arbiter.
+	aliceInfo, err := net.Alice.GetInfo(ctxb, &lnrpc.GetInfoRequest{})
+	if err != nil {
+		t.Fatalf("unable to get alice's info: %v", err)
+	}
+	req := &lnrpc.ConnectPeerRequest{
+		Addr: &lnrpc.LightningAddress{
+			PubKeyHash: aliceInfo.IdentityAddress,
+			Host:       net.Alice.p2pAddr,
+		},
+	}
+	if _, err := node.ConnectPeer(ctxb, req); err != nil {
+		t.Fatalf("unable to connect node to alice: %v", err)
+	}
+}
+
+var lndTestCases = []struct {
+	name string
+	test lndTestCase
+}{
+	{"basic funding flow", testBasicChannelFunding},
+	{"channel balance", testChannelBalance},
+	{"colored payments and cooperative close", testColoredCooperativeClose},
+	{"colored force closure", testColoredForceClosure},
+	{"channel force closure", testChannelForceClosure},
+	{"node with auto force close", testAutoForceCloseNode},
 }
 
 // TestLightningNetworkDaemon performs a series of integration tests amongst a
@@ -337,8 +575,11 @@ func TestLightningNetworkDaemon(t *testing.T) {
 
 	// First create an instance of the btcd's rpctest.Harness. This will be
 	// used to fund the wallets of the nodes within the test network and to
-	// drive blockchain related events within the network.
-	btcdHarness, err = rpctest.New(harnessNetParams, handlers, nil)
+	// drive blockchain related events within the network. A transaction
+	// index is maintained so the color service can look up confirmed
+	// transactions.
+	btcdArgs := []string{"--txindex"}
+	btcdHarness, err = rpctest.New(harnessNetParams, handlers, btcdArgs)
 	if err != nil {
 		t.Fatalf("unable to create mining node: %v", err)
 	}
@@ -362,10 +603,10 @@ func TestLightningNetworkDaemon(t *testing.T) {
 	}
 
 	t.Logf("Running %v integration tests", len(lndTestCases))
-	for testName, lnTest := range lndTestCases {
-		t.Logf("Executing test %v", testName)
+	for _, testCase := range lndTestCases {
+		t.Logf("Executing test %v", testCase.name)
 
-		currentTest = testName
-		lnTest(lightningNetwork, t)
+		currentTest = testCase.name
+		testCase.test(lightningNetwork, t)
 	}
 }
@@ -0,0 +1,117 @@
+package lndcc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// OutputMapping maps an output of a transaction passed to ColorifyTx to the
+// output replacing it within the colorified transaction.
+type OutputMapping struct {
+	// Index is the index of the output within both transactions, as
+	// ColorifyTx preserves the order of the outputs.
+	Index uint32
+
+	// PkScript is the script of the output.
+	PkScript []byte
+
+	// AssetAmount is the value of the original output, which is
+	// transferred by the output's instruction.
+	AssetAmount int64
+
+	// CarrierValue is the value in satoshis of the colorified output.
+	CarrierValue int64
+}
+
+// ColorifyDebug describes the colorification of a transaction, as carried
+// out by ColorifyTx.
+type ColorifyDebug struct {
+	// Instructions are the transfer instructions encoded within the
+	// OP_RETURN output.
+	Instructions []Instruction
+
+	// Payload is the encoded instructions, as embedded within the
+	// OP_RETURN output.
+	Payload []byte
+
+	// Outputs maps each of the original outputs to its colorified
+	// replacement.
+	Outputs []OutputMapping
+
+	// OpReturnIndex is the index of the OP_RETURN output within the
+	// colorified transaction.
+	OpReturnIndex uint32
+
+	// Tx is the colorified transaction.
+	Tx *wire.MsgTx
+}
+
+// DebugColorify colorifies the passed commitment, or close transaction as
+// ColorifyTx does, returning each step of the transformation rather than just
+// the resulting transaction. As both parties to a channel colorify their
+// transactions independently, comparing the result of each side allows one to
+// pinpoint why the txids of their commitment transactions differ. Nothing is
+// broadcast.
+func DebugColorify(tx *wire.MsgTx) (*ColorifyDebug, error) {
+	debug := &ColorifyDebug{}
+
+	encode := Encoder
+	coloredTx, err := colorifyTx(tx, false,
+		func(insts []Instruction) ([]byte, error) {
+			debug.Instructions = insts
+
+			payload, err := encode(insts)
+			debug.Payload = payload
+			return payload, err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	debug.Tx = coloredTx
+	debug.OpReturnIndex = uint32(len(coloredTx.TxOut) - 1)
+	debug.Outputs = make([]OutputMapping, len(tx.TxOut))
+	for i, txOut := range tx.TxOut {
+		debug.Outputs[i] = OutputMapping{
+			Index:        uint32(i),
+			PkScript:     txOut.PkScript,
+			AssetAmount:  txOut.Value,
+			CarrierValue: coloredTx.TxOut[i].Value,
+		}
+	}
+
+	return debug, nil
+}
+
+// String returns a human readable dump of the colorification, suitable for
+// comparison with that of the remote party.
+func (d *ColorifyDebug) String() string {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "txid: %v\n", d.Tx.TxSha())
+	fmt.Fprintf(&b, "instructions (%d):\n", len(d.Instructions))
+	for i, inst := range d.Instructions {
+		fmt.Fprintf(&b, "  %d: output=%d amount=%d skip=%v range=%v "+
+			"percent=%v\n", i, inst.Output, inst.Amount, inst.Skip,
+			inst.Range, inst.Percent)
+	}
+	fmt.Fprintf(&b, "outputs (%d):\n", len(d.Outputs))
+	for _, out := range d.Outputs {
+		fmt.Fprintf(&b, "  %d: asset_amount=%d carrier_value=%d "+
+			"pk_script=%x\n", out.Index, out.AssetAmount,
+			out.CarrierValue, out.PkScript)
+	}
+	fmt.Fprintf(&b, "op_return: index=%d payload=%s\n", d.OpReturnIndex,
+		hex.EncodeToString(d.Payload))
+
+	var rawTx bytes.Buffer
+	if err := d.Tx.Serialize(&rawTx); err == nil {
+		fmt.Fprintf(&b, "raw_tx: %x\n", rawTx.Bytes())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,170 @@
+//go:build gofuzz
+// +build gofuzz
+
+package lndcc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// fuzzInstructionSize is the length of a single instruction within the
+// fixed-width encoding used in place of the cc-encoding-api while fuzzing:
+// flags (1) || output (4) || amount (8).
+const fuzzInstructionSize = 13
+
+// fuzzEncodeInstructions encodes the passed instructions using a simple
+// fixed-width encoding.
+func fuzzEncodeInstructions(insts []Instruction) ([]byte, error) {
+	var b bytes.Buffer
+	for _, inst := range insts {
+		var flags byte
+		if inst.Skip {
+			flags |= 1 << 0
+		}
+		if inst.Range {
+			flags |= 1 << 1
+		}
+		if inst.Percent {
+			flags |= 1 << 2
+		}
+
+		var scratch [fuzzInstructionSize]byte
+		scratch[0] = flags
+		binary.BigEndian.PutUint32(scratch[1:5], inst.Output)
+		binary.BigEndian.PutUint64(scratch[5:], uint64(inst.Amount))
+		b.Write(scratch[:])
+	}
+
+	return b.Bytes(), nil
+}
+
+// fuzzDecodeInstructions is the inverse of fuzzEncodeInstructions.
+func fuzzDecodeInstructions(payload []byte) ([]Instruction, error) {
+	if len(payload)%fuzzInstructionSize != 0 {
+		return nil, fmt.Errorf("payload of %d bytes isn't a multiple "+
+			"of %d", len(payload), fuzzInstructionSize)
+	}
+
+	var insts []Instruction
+	for ; len(payload) != 0; payload = payload[fuzzInstructionSize:] {
+		insts = append(insts, Instruction{
+			Skip:    payload[0]&(1<<0) != 0,
+			Range:   payload[0]&(1<<1) != 0,
+			Percent: payload[0]&(1<<2) != 0,
+			Output:  binary.BigEndian.Uint32(payload[1:5]),
+			Amount:  int(binary.BigEndian.Uint64(payload[5:])),
+		})
+	}
+
+	return insts, nil
+}
+
+// Fuzz is the go-fuzz entry point for the colored coins transformation of a
+// transaction. The first byte of the input selects whether the transaction
+// is a funding transaction, while the remainder is split into outputs, each
+// of the form: value (8) || script length (1) || script. Every transformed
+// transaction is checked for value conservation, and that its OP_RETURN
+// payload parses back into the expected instructions.
+func Fuzz(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	isFunding := data[0]&1 == 1
+	data = data[1:]
+
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	for len(data) >= 9 {
+		value := int64(binary.BigEndian.Uint64(data[:8]))
+		scriptLen := int(data[8])
+		data = data[9:]
+		if scriptLen > len(data) {
+			scriptLen = len(data)
+		}
+
+		tx.AddTxOut(wire.NewTxOut(value, data[:scriptLen]))
+		data = data[scriptLen:]
+	}
+
+	coloredTx, err := colorifyTx(tx, isFunding, fuzzEncodeInstructions)
+	if err != nil {
+		// Negative output values are the only input rejected.
+		for _, txOut := range tx.TxOut {
+			if txOut.Value < 0 {
+				return 0
+			}
+		}
+		panic(fmt.Sprintf("unable to colorify tx: %v", err))
+	}
+
+	// Each output must be replaced by a dust output paying to the same
+	// script, followed by the OP_RETURN output.
+	if len(coloredTx.TxOut) != len(tx.TxOut)+1 {
+		panic(fmt.Sprintf("expected %d outputs, got %d",
+			len(tx.TxOut)+1, len(coloredTx.TxOut)))
+	}
+	dust := int64(dustAmount)
+	if isFunding {
+		dust *= 15
+	}
+	for i, txOut := range tx.TxOut {
+		coloredOut := coloredTx.TxOut[i]
+		if coloredOut.Value != dust {
+			panic(fmt.Sprintf("output %d has value %d, expected "+
+				"dust", i, coloredOut.Value))
+		}
+		if !bytes.Equal(coloredOut.PkScript, txOut.PkScript) {
+			panic(fmt.Sprintf("output %d script changed", i))
+		}
+	}
+
+	opReturn := coloredTx.TxOut[len(tx.TxOut)]
+	script := opReturn.PkScript
+	if opReturn.Value != 0 || len(script) == 0 ||
+		script[0] != txscript.OP_RETURN {
+		panic("last output isn't a zero valued OP_RETURN")
+	}
+	r := bytes.NewReader(script[1:])
+	payload, err := wire.ReadVarBytes(r, 0, math.MaxUint32, "payload")
+	if err != nil {
+		panic(fmt.Sprintf("unable to read payload: %v", err))
+	}
+	if r.Len() != 0 {
+		panic(fmt.Sprintf("%d trailing bytes after payload", r.Len()))
+	}
+
+	// The payload must parse back into exactly one instruction per
+	// output, which together transfer the full value of the original
+	// outputs.
+	insts, err := fuzzDecodeInstructions(payload)
+	if err != nil {
+		panic(fmt.Sprintf("unable to decode payload: %v", err))
+	}
+	if len(insts) != len(tx.TxOut) {
+		panic(fmt.Sprintf("expected %d instructions, got %d",
+			len(tx.TxOut), len(insts)))
+	}
+	var valueIn, valueOut int64
+	for i, inst := range insts {
+		if inst.Skip || inst.Range || inst.Percent ||
+			inst.Output != uint32(i) {
+			panic(fmt.Sprintf("unexpected instruction %d: %+v", i,
+				inst))
+		}
+
+		valueIn += tx.TxOut[i].Value
+		valueOut += int64(inst.Amount)
+	}
+	if valueIn != valueOut {
+		panic(fmt.Sprintf("value not conserved: %d in, %d out",
+			valueIn, valueOut))
+	}
+
+	return 1
+}
@@ -0,0 +1,280 @@
+package lndcc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// localTransferHeader is the header prefixed to each transfer payload
+	// encoded by the local kernel: the protocol identifier (2), version
+	// (1), and opcode (1).
+	localTransferHeader = "CC\x02\x15"
+
+	// localInstructionSize is the size of a single transfer instruction
+	// encoded by the local kernel: the output index (1), and the amount
+	// (7).
+	localInstructionSize = 8
+
+	// localMaxOutput is the largest output index an instruction encoded by
+	// the local kernel may reference.
+	localMaxOutput = 0x1f
+
+	// localMaxAmount is the largest amount an instruction encoded by the
+	// local kernel may carry.
+	localMaxAmount = 1<<56 - 1
+)
+
+// EncodeTransfer encodes the passed transfer instructions without the
+// cc-encoding-api, as a 4 byte header followed by an 8 byte entry for each
+// instruction: the output index (1), and the amount (7). This matches the
+// worst case size of the cc-encoding-api's encoding. Only plain transfers are
+// supported, so instructions making use of the skip, range or percent flags
+// are rejected.
+func EncodeTransfer(insts []Instruction) ([]byte, error) {
+	payload := make([]byte, 0,
+		len(localTransferHeader)+len(insts)*localInstructionSize)
+	payload = append(payload, localTransferHeader...)
+
+	for _, inst := range insts {
+		switch {
+		case inst.Skip || inst.Range || inst.Percent:
+			return nil, fmt.Errorf("unsupported instruction: %+v", inst)
+		case inst.Output > localMaxOutput:
+			return nil, fmt.Errorf("output %v out of range", inst.Output)
+		case inst.Amount < 0 || inst.Amount > localMaxAmount:
+			return nil, fmt.Errorf("amount %v out of range", inst.Amount)
+		}
+
+		var scratch [localInstructionSize]byte
+		binary.BigEndian.PutUint64(scratch[:], uint64(inst.Amount))
+		scratch[0] = byte(inst.Output)
+		payload = append(payload, scratch[:]...)
+	}
+
+	return payload, nil
+}
+
+// DecodeTransfer returns the transfer instructions encoded by EncodeTransfer
+// within the OP_RETURN output of the passed transaction. A transaction
+// without a valid transfer payload yields no instructions.
+func DecodeTransfer(tx *wire.MsgTx) []Instruction {
+	var payload []byte
+	for _, txOut := range tx.TxOut {
+		script := txOut.PkScript
+		if isOpReturn(script) && len(script) > 1 &&
+			int(script[1]) == len(script)-2 {
+
+			payload = script[2:]
+			break
+		}
+	}
+
+	header := len(localTransferHeader)
+	if len(payload) < header ||
+		string(payload[:header]) != localTransferHeader ||
+		(len(payload)-header)%localInstructionSize != 0 {
+
+		return nil
+	}
+
+	var insts []Instruction
+	for b := payload[header:]; len(b) != 0; b = b[localInstructionSize:] {
+		var scratch [localInstructionSize]byte
+		copy(scratch[1:], b[1:localInstructionSize])
+
+		insts = append(insts, Instruction{
+			Output: uint32(b[0]),
+			Amount: int(binary.BigEndian.Uint64(scratch[:])),
+		})
+	}
+
+	return insts
+}
+
+// isOpReturn returns true if the passed script is a null data script.
+func isOpReturn(script []byte) bool {
+	return len(script) > 0 && script[0] == txscript.OP_RETURN
+}
+
+// TxSource fetches transactions from the chain, including those confirmed
+// long ago. A btcd node backing a TxSource must maintain a transaction index.
+type TxSource interface {
+	// GetTransaction returns the full transaction identified by the
+	// passed transaction ID.
+	GetTransaction(txid *wire.ShaHash) (*wire.MsgTx, error)
+}
+
+// LocalKernel derives the color of outputs without the cc-txo-color service,
+// by replaying the transfers, encoded with EncodeTransfer, of all
+// transactions leading up to an output, starting from the outputs explicitly
+// issued to the kernel. As issuances are taken on trust, the kernel is only
+// suitable for test networks, allowing them to run without the colored coins
+// infrastructure.
+type LocalKernel struct {
+	sync.Mutex
+
+	// assetID is the asset carried by all outputs issued to the kernel.
+	assetID string
+
+	// chain is used to fetch the transactions spending colored outputs.
+	chain TxSource
+
+	// txos is the color of each output resolved so far. Outputs of
+	// resolved transactions which don't carry any asset map to an empty
+	// TxoData.
+	txos     map[wire.OutPoint]TxoData
+	resolved map[wire.ShaHash]struct{}
+}
+
+// NewLocalKernel creates a new local kernel issuing outputs of the passed
+// asset, which resolves transactions using the passed source.
+func NewLocalKernel(chain TxSource, assetID string) *LocalKernel {
+	return &LocalKernel{
+		assetID:  assetID,
+		chain:    chain,
+		txos:     make(map[wire.OutPoint]TxoData),
+		resolved: make(map[wire.ShaHash]struct{}),
+	}
+}
+
+// Issue marks the target output as carrying the passed amount of the
+// kernel's asset.
+func (k *LocalKernel) Issue(op wire.OutPoint, amt btcutil.Amount) {
+	k.Lock()
+	defer k.Unlock()
+
+	k.txos[op] = TxoData{
+		AssetId: k.assetID,
+		Value:   amt,
+	}
+}
+
+// TxoData returns the color of the target output.
+func (k *LocalKernel) TxoData(op wire.OutPoint) (*TxoData, error) {
+	k.Lock()
+	defer k.Unlock()
+
+	txo, err := k.txoData(op)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txo, nil
+}
+
+// txoData returns the color of the target output, resolving the transaction
+// which created it if needed. This method MUST be called with the mutex held.
+func (k *LocalKernel) txoData(op wire.OutPoint) (TxoData, error) {
+	if txo, ok := k.txos[op]; ok {
+		return txo, nil
+	}
+	if _, ok := k.resolved[op.Hash]; ok {
+		return TxoData{}, nil
+	}
+
+	if err := k.resolveTx(&op.Hash); err != nil {
+		return TxoData{}, err
+	}
+
+	return k.txos[op], nil
+}
+
+// resolveTx determines the color of each output of the target transaction by
+// applying the transfer instructions within its OP_RETURN output to the
+// assets carried by its inputs. As within the colored coins protocol, any
+// assets left over are transferred to the last output, while a transfer
+// spending more than the inputs carry, or mixing assets, burns them. Outputs
+// issued directly are never overwritten. This method MUST be called with the
+// mutex held.
+func (k *LocalKernel) resolveTx(txid *wire.ShaHash) error {
+	msgTx, err := k.chain.GetTransaction(txid)
+	if err != nil {
+		return err
+	}
+
+	var (
+		assetID  string
+		inputAmt btcutil.Amount
+		burn     bool
+	)
+	isCoinbase := len(msgTx.TxIn) == 1 &&
+		msgTx.TxIn[0].PreviousOutPoint.Index == math.MaxUint32
+	for _, txIn := range msgTx.TxIn {
+		if isCoinbase {
+			break
+		}
+
+		txo, err := k.txoData(txIn.PreviousOutPoint)
+		if err != nil {
+			return err
+		}
+		if txo.AssetId == "" {
+			continue
+		}
+
+		if assetID != "" && assetID != txo.AssetId {
+			burn = true
+		}
+		assetID = txo.AssetId
+		inputAmt += txo.Value
+	}
+
+	outputAmts := make([]btcutil.Amount, len(msgTx.TxOut))
+	lastOutput := -1
+	for i, txOut := range msgTx.TxOut {
+		if isOpReturn(txOut.PkScript) {
+			continue
+		}
+		lastOutput = i
+	}
+
+	var transferred btcutil.Amount
+	for _, inst := range DecodeTransfer(msgTx) {
+		if int(inst.Output) >= len(msgTx.TxOut) ||
+			isOpReturn(msgTx.TxOut[inst.Output].PkScript) {
+
+			burn = true
+			break
+		}
+
+		outputAmts[inst.Output] += btcutil.Amount(inst.Amount)
+		transferred += btcutil.Amount(inst.Amount)
+	}
+	switch {
+	case transferred > inputAmt:
+		burn = true
+	case lastOutput != -1:
+		outputAmts[lastOutput] += inputAmt - transferred
+	}
+
+	for i, amt := range outputAmts {
+		op := wire.OutPoint{Hash: *txid, Index: uint32(i)}
+		if _, ok := k.txos[op]; ok {
+			continue
+		}
+
+		var txo TxoData
+		if !burn && assetID != "" && amt != 0 {
+			txo = TxoData{AssetId: assetID, Value: amt}
+		}
+		k.txos[op] = txo
+	}
+	k.resolved[*txid] = struct{}{}
+
+	return nil
+}
+
+// UseLocalKernel switches the package to encode transfer instructions with
+// EncodeTransfer, and to derive the color of outputs from the passed kernel,
+// rather than relying on the external colored coins services.
+func UseLocalKernel(k *LocalKernel) {
+	Encoder = EncodeTransfer
+	txoSource = k.TxoData
+}
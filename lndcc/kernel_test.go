@@ -0,0 +1,160 @@
+package lndcc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// mockTxSource is a TxSource backed by a fixed set of transactions.
+type mockTxSource map[wire.ShaHash]*wire.MsgTx
+
+func (m mockTxSource) GetTransaction(txid *wire.ShaHash) (*wire.MsgTx, error) {
+	tx, ok := m[*txid]
+	if !ok {
+		return nil, fmt.Errorf("transaction %v not found", txid)
+	}
+
+	return tx, nil
+}
+
+// addTransferTx adds a transaction to the source spending the passed output,
+// with two outputs followed by an OP_RETURN output transferring the assets of
+// the input as instructed.
+func (m mockTxSource) addTransferTx(t *testing.T, prevOut wire.OutPoint,
+	insts []Instruction) *wire.ShaHash {
+
+	payload, err := EncodeTransfer(insts)
+	if err != nil {
+		t.Fatalf("unable to encode transfer: %v", err)
+	}
+	script := append([]byte{txscript.OP_RETURN, byte(len(payload))},
+		payload...)
+
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(&prevOut, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(600, []byte{0x00, 0x14}))
+	tx.AddTxOut(wire.NewTxOut(600, []byte{0x00, 0x20}))
+	tx.AddTxOut(wire.NewTxOut(0, script))
+
+	txid := tx.TxSha()
+	m[txid] = tx
+	return &txid
+}
+
+// TestLocalKernel tests that the local kernel resolves the color of outputs
+// by replaying the transfers since an issuance, transferring any leftover
+// assets to the last output, and burning the assets of an overspending
+// transfer.
+func TestLocalKernel(t *testing.T) {
+	const assetID = "La4aGUPuNKZyC393pS2Nb4RJdkR7Pj8T8aWLaY"
+
+	source := make(mockTxSource)
+	kernel := NewLocalKernel(source, assetID)
+
+	issued := wire.OutPoint{Hash: wire.ShaHash{0x01}}
+	kernel.Issue(issued, 1000)
+
+	transferTxid := source.addTransferTx(t, issued, []Instruction{
+		{Output: 0, Amount: 300},
+	})
+	burnTxid := source.addTransferTx(t,
+		wire.OutPoint{Hash: *transferTxid, Index: 0},
+		[]Instruction{{Output: 1, Amount: 500}},
+	)
+
+	tests := []struct {
+		op  wire.OutPoint
+		amt btcutil.Amount
+	}{
+		{issued, 1000},
+		{wire.OutPoint{Hash: *transferTxid, Index: 0}, 300},
+		{wire.OutPoint{Hash: *transferTxid, Index: 1}, 700},
+		{wire.OutPoint{Hash: *transferTxid, Index: 2}, 0},
+		{wire.OutPoint{Hash: *burnTxid, Index: 0}, 0},
+		{wire.OutPoint{Hash: *burnTxid, Index: 1}, 0},
+	}
+	for i, test := range tests {
+		txo, err := kernel.TxoData(test.op)
+		if err != nil {
+			t.Fatalf("test #%v: unable to resolve %v: %v", i, test.op,
+				err)
+		}
+
+		expectedAsset := assetID
+		if test.amt == 0 {
+			expectedAsset = ""
+		}
+		if txo.AssetId != expectedAsset || txo.Value != test.amt {
+			t.Fatalf("test #%v: expected %v of %q at %v, got %v of %q",
+				i, test.amt, expectedAsset, test.op, txo.Value,
+				txo.AssetId)
+		}
+	}
+
+	// An output of a transaction unknown to the source can't be resolved.
+	_, err := kernel.TxoData(wire.OutPoint{Hash: wire.ShaHash{0x02}})
+	if err == nil {
+		t.Fatalf("resolved output of unknown transaction")
+	}
+
+	// Once in use, the kernel backs the package's color lookups.
+	defer func(encoder func([]Instruction) ([]byte, error),
+		source func(wire.OutPoint) (*TxoData, error)) {
+
+		Encoder = encoder
+		txoSource = source
+	}(Encoder, txoSource)
+	UseLocalKernel(kernel)
+
+	txo, err := GetTxoData(wire.OutPoint{Hash: *transferTxid, Index: 1})
+	if err != nil {
+		t.Fatalf("unable to get txo data: %v", err)
+	}
+	if txo.Value != 700 {
+		t.Fatalf("expected 700 units, got %v", txo.Value)
+	}
+}
+
+// TestEncodeTransfer tests that transfer instructions round trip through
+// EncodeTransfer and DecodeTransfer, and that those the local kernel doesn't
+// support are rejected.
+func TestEncodeTransfer(t *testing.T) {
+	insts := []Instruction{
+		{Output: 0, Amount: 1},
+		{Output: localMaxOutput, Amount: localMaxAmount},
+	}
+	payload, err := EncodeTransfer(insts)
+	if err != nil {
+		t.Fatalf("unable to encode transfer: %v", err)
+	}
+
+	tx := wire.NewMsgTx()
+	tx.AddTxOut(wire.NewTxOut(0, append([]byte{txscript.OP_RETURN,
+		byte(len(payload))}, payload...)))
+	decoded := DecodeTransfer(tx)
+	if len(decoded) != len(insts) {
+		t.Fatalf("expected %v instructions, got %v", len(insts),
+			len(decoded))
+	}
+	for i := range insts {
+		if decoded[i] != insts[i] {
+			t.Fatalf("instruction #%v: expected %+v, got %+v", i,
+				insts[i], decoded[i])
+		}
+	}
+
+	unsupported := []Instruction{
+		{Output: 0, Amount: 1, Skip: true},
+		{Output: localMaxOutput + 1, Amount: 1},
+		{Output: 0, Amount: -1},
+	}
+	for _, inst := range unsupported {
+		if _, err := EncodeTransfer([]Instruction{inst}); err == nil {
+			t.Fatalf("encoded unsupported instruction %+v", inst)
+		}
+	}
+}
@@ -2,8 +2,13 @@ package lndcc
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/parnurzeal/gorequest"
 
@@ -15,6 +20,16 @@ import (
 var dustAmount = 546
 var ccEncodingUrl = os.Getenv("CC_ENCODING_URL")
 var ccTxoUrl = os.Getenv("CC_TXO_URL")
+var ccExplorerUrl = os.Getenv("CC_EXPLORER_URL")
+
+// ErrNoExplorer is returned when looking up an asset while no cc-explorer has
+// been configured via CC_EXPLORER_URL.
+var ErrNoExplorer = errors.New("no cc-explorer configured")
+
+// Encoder encodes the transfer instructions of a transaction into the payload
+// of its OP_RETURN output. It defaults to the cc-encoding-api, and may be
+// replaced in order to run without the service, or to inject faults into it.
+var Encoder = HTTPEncoder(ccEncodingUrl)
 
 // ColoredCoin transfer instruction
 type Instruction struct {
@@ -25,6 +40,13 @@ type Instruction struct {
 	Amount  int    `json:"amount"` // 64?
 }
 
+// AssetInfo is the issuance data of an asset, as reported by the cc-explorer.
+type AssetInfo struct {
+	AssetId      string `json:"assetId"`
+	IssuanceTxid string `json:"issuanceTxid"`
+	Divisibility int    `json:"divisibility"`
+}
+
 // ColoredCoin transaction output color data
 type TxoData struct {
 	AssetId string         `json:"assetId"`
@@ -35,22 +57,52 @@ func (d TxoData) String() string {
 	return fmt.Sprintf("%d of %s", d.Value, d.AssetId)
 }
 
+// FundingOutputValue returns the value in satoshis given to each output of a
+// funding transaction by ColorifyTx, sufficient to pay the fees and dust
+// outputs of the transactions spending it.
+func FundingOutputValue() btcutil.Amount {
+	return btcutil.Amount(dustAmount * 15)
+}
+
+// CarrierValue returns the value in satoshis given by ColorifyTx to each
+// output of a commitment, or closing transaction.
+func CarrierValue() btcutil.Amount {
+	return btcutil.Amount(dustAmount)
+}
+
 // Transform regular transactions into colored-coins-encoded ones,
 // by re-encoding the standard output values into OP_RETURN-embedded
 // instructions and replacing the actual output value with dust amounts
 // @FIXME currently assumes a single-input tx
 func ColorifyTx(tx *wire.MsgTx, isFunding bool) (*wire.MsgTx, error) {
+	return colorifyTx(tx, isFunding, Encoder)
+}
 
+// colorifyTx carries out ColorifyTx, encoding the transfer instructions with
+// the passed encoder. This allows the transformation itself to be exercised
+// without the cc-encoding-api.
+func colorifyTx(tx *wire.MsgTx, isFunding bool,
+	encode func([]Instruction) ([]byte, error)) (*wire.MsgTx, error) {
+
+	// Size the transaction and instructions up front, leaving room for the
+	// OP_RETURN output.
 	newTx := wire.NewMsgTx()
 	newTx.Version = tx.Version
+	newTx.TxIn = make([]*wire.TxIn, 0, len(tx.TxIn))
+	newTx.TxOut = make([]*wire.TxOut, 0, len(tx.TxOut)+1)
 
 	for _, txIn := range tx.TxIn {
 		newTx.AddTxIn(txIn)
 	}
 
-	var insts []Instruction
+	insts := make([]Instruction, 0, len(tx.TxOut))
 
 	for i, txOut := range tx.TxOut {
+		if txOut.Value < 0 {
+			return nil, fmt.Errorf("output %d has negative value %d",
+				i, txOut.Value)
+		}
+
 		// hijack the output value and re-encode it as a colored coin instruction
 		insts = append(insts, Instruction{
 			Skip: false, Range: false, Percent: false,
@@ -60,7 +112,8 @@ func ColorifyTx(tx *wire.MsgTx, isFunding bool) (*wire.MsgTx, error) {
 		if isFunding {
 			// make sure the funding output has enough funding for fees and output dust
 			// @TODO leftover is wasted, better to split everything that's available instead
-			newTx.AddTxOut(wire.NewTxOut(int64(dustAmount*15), txOut.PkScript))
+			newTx.AddTxOut(wire.NewTxOut(int64(FundingOutputValue()),
+				txOut.PkScript))
 		} else {
 			// use dust amounts for outputs of the commit/close txs
 			newTx.AddTxOut(wire.NewTxOut(int64(dustAmount), txOut.PkScript))
@@ -68,7 +121,7 @@ func ColorifyTx(tx *wire.MsgTx, isFunding bool) (*wire.MsgTx, error) {
 	}
 
 	// encode colored coin instructions
-	opReturn, err := encodeInstructions(insts)
+	opReturn, err := encode(insts)
 	if err != nil {
 		return nil, err
 	}
@@ -88,36 +141,124 @@ func ColorifyTx(tx *wire.MsgTx, isFunding bool) (*wire.MsgTx, error) {
 	return newTx, nil
 }
 
-// Encodes the transfer instructions via cc-encoding-api
-func encodeInstructions(insts []Instruction) ([]byte, error) {
-	_, body, errs := gorequest.New().
-		Post(fmt.Sprintf("%s/%s", ccEncodingUrl, "encode")).
-		Set("Content-Type", "application/json").
-		Send(insts).
-		EndBytes()
+// HTTPEncoder returns an encoder which encodes the transfer instructions via
+// the cc-encoding-api served at the passed URL.
+func HTTPEncoder(url string) func([]Instruction) ([]byte, error) {
+	return func(insts []Instruction) ([]byte, error) {
+		start := time.Now()
+		resp, body, errs := gorequest.New().
+			Post(fmt.Sprintf("%s/%s", url, "encode")).
+			Set("Content-Type", "application/json").
+			Set(SchemaVersionHeader, strconv.Itoa(SchemaVersion)).
+			Send(insts).
+			EndBytes()
 
-	if errs != nil {
-		return nil, errs[0]
+		err := checkStatus(resp)
+		switch {
+		case errs != nil:
+			err = errs[0]
+		case err != nil:
+		case len(body) == 0:
+			err = ErrEmptyResponse
+		default:
+			err = checkSchemaVersion(resp)
+		}
+		recordRequest("encode", start, err)
+		if err != nil {
+			return nil, err
+		}
+
+		return body, nil
 	}
+}
+
+// txoSource resolves the color of an output for GetTxoData. It defaults to
+// cc-txo-color, and is replaced by UseLocalKernel.
+var txoSource = httpTxoData
 
-	return body, nil
+// GetTxoData returns the color of the target output.
+func GetTxoData(out wire.OutPoint) (*TxoData, error) {
+	return txoSource(out)
 }
 
 // Get TXO color data via cc-txo-color
-func GetTxoData(out wire.OutPoint) (*TxoData, error) {
+func httpTxoData(out wire.OutPoint) (*TxoData, error) {
 	var txoData TxoData
 
-	_, _, errs := gorequest.New().
+	start := time.Now()
+	resp, body, errs := gorequest.New().
 		Get(fmt.Sprintf("%s/%s/%d", ccTxoUrl, out.Hash, out.Index)).
-		EndStruct(&txoData)
+		Set(SchemaVersionHeader, strconv.Itoa(SchemaVersion)).
+		EndBytes()
 
+	// The response is decoded strictly, as a change to the schema would
+	// otherwise decode as an uncolored output, hiding our assets from
+	// coin selection.
+	var err error
 	if errs != nil {
-		return nil, errs[0]
+		err = errs[0]
+	} else {
+		err = checkSchemaVersion(resp)
+	}
+	if err == nil {
+		err = decodeStrict(body, &txoData)
+	}
+	if err == nil {
+		err = txoData.validate()
+	}
+	recordRequest("txo", start, err)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch color of %v: %v", out,
+			err)
 	}
 
 	return &txoData, nil
 }
 
+// GetAssetInfo looks up the issuance data of the target asset via the
+// cc-explorer. As each cc-explorer indexes a single network, an asset issued
+// on a different network than the explorer's isn't found.
+func GetAssetInfo(assetId string) (*AssetInfo, error) {
+	if ccExplorerUrl == "" {
+		return nil, ErrNoExplorer
+	}
+
+	var assetInfo AssetInfo
+
+	start := time.Now()
+	resp, body, errs := gorequest.New().
+		Get(fmt.Sprintf("%s/api/getassetinfo", ccExplorerUrl)).
+		Query(url.Values{"assetId": {assetId}}.Encode()).
+		Set(SchemaVersionHeader, strconv.Itoa(SchemaVersion)).
+		EndBytes()
+
+	var err error
+	switch {
+	case resp != nil && resp.StatusCode != http.StatusOK:
+		err = fmt.Errorf("asset %v not found by cc-explorer (status %v)",
+			assetId, resp.StatusCode)
+	case errs != nil:
+		err = errs[0]
+	default:
+		err = checkSchemaVersion(resp)
+	}
+	if err == nil {
+		err = decodeStrict(body, &assetInfo)
+	}
+	if err == nil {
+		err = assetInfo.validate()
+	}
+	if err == nil && assetInfo.AssetId != assetId {
+		err = fmt.Errorf("asset %v not found by cc-explorer", assetId)
+	}
+	recordRequest("explorer", start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &assetInfo, nil
+}
+
 // unused, not needed for now (both sides independently re-construct the txs)
 // uses "fmt", "encoding/json" and "errors" (currently unimported)
 /*
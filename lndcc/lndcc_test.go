@@ -0,0 +1,325 @@
+package lndcc
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestColorifyTx tests that each output of a colorified transaction is
+// replaced by a dust output, with its value transferred by an instruction
+// within the OP_RETURN output.
+func TestColorifyTx(t *testing.T) {
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(5000, []byte{0x00, 0x14}))
+	tx.AddTxOut(wire.NewTxOut(0, []byte{0x00, 0x20}))
+
+	var insts []Instruction
+	encode := func(i []Instruction) ([]byte, error) {
+		insts = i
+		return []byte{0xcc, 0x01}, nil
+	}
+
+	coloredTx, err := colorifyTx(tx, false, encode)
+	if err != nil {
+		t.Fatalf("unable to colorify tx: %v", err)
+	}
+
+	expectedInsts := []Instruction{
+		{Output: 0, Amount: 5000},
+		{Output: 1, Amount: 0},
+	}
+	if !reflect.DeepEqual(insts, expectedInsts) {
+		t.Fatalf("expected instructions %v, got %v", expectedInsts,
+			insts)
+	}
+
+	if len(coloredTx.TxOut) != 3 {
+		t.Fatalf("expected 3 outputs, got %v", len(coloredTx.TxOut))
+	}
+	for i, txOut := range tx.TxOut {
+		if coloredTx.TxOut[i].Value != int64(dustAmount) {
+			t.Fatalf("output %v isn't dust: %v", i,
+				coloredTx.TxOut[i].Value)
+		}
+		if !bytes.Equal(coloredTx.TxOut[i].PkScript, txOut.PkScript) {
+			t.Fatalf("output %v script changed", i)
+		}
+	}
+	expectedScript := []byte{txscript.OP_RETURN, 0x02, 0xcc, 0x01}
+	if !bytes.Equal(coloredTx.TxOut[2].PkScript, expectedScript) {
+		t.Fatalf("expected OP_RETURN script %x, got %x",
+			expectedScript, coloredTx.TxOut[2].PkScript)
+	}
+
+	// A negative output value can't be transferred, so should be
+	// rejected.
+	tx.TxOut[1].Value = -1
+	if _, err := colorifyTx(tx, false, encode); err == nil {
+		t.Fatalf("negative output value accepted")
+	}
+}
+
+// TestGetAssetInfo tests that assets are looked up via the configured
+// cc-explorer, and that assets unknown to it aren't found.
+func TestGetAssetInfo(t *testing.T) {
+	const (
+		assetID      = "La3Ubh2cbnLM2a5X3Ceb9Q9TNQ1eJvkGr6sHW1"
+		issuanceTxid = "b1ca0a8c5bae8b3cb40e1bbb6dc6a5fd3e7a8e50d2b4d1b0e6a4f7e0b5b2c3d4"
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/getassetinfo" ||
+				r.URL.Query().Get("assetId") != assetID {
+
+				http.NotFound(w, r)
+				return
+			}
+
+			json.NewEncoder(w).Encode(&AssetInfo{
+				AssetId:      assetID,
+				IssuanceTxid: issuanceTxid,
+				Divisibility: 2,
+			})
+		},
+	))
+	defer server.Close()
+
+	defer func(url string) {
+		ccExplorerUrl = url
+	}(ccExplorerUrl)
+
+	ccExplorerUrl = ""
+	if _, err := GetAssetInfo(assetID); err != ErrNoExplorer {
+		t.Fatalf("expected ErrNoExplorer, got %v", err)
+	}
+
+	ccExplorerUrl = server.URL
+	info, err := GetAssetInfo(assetID)
+	if err != nil {
+		t.Fatalf("unable to get asset info: %v", err)
+	}
+	if info.IssuanceTxid != issuanceTxid || info.Divisibility != 2 {
+		t.Fatalf("unexpected asset info: %+v", info)
+	}
+
+	if _, err := GetAssetInfo(assetID + "x"); err == nil {
+		t.Fatalf("expected unknown asset not to be found")
+	}
+}
+
+// TestGetTxoDataStrict tests that responses of cc-txo-color which don't match
+// the expected schema are rejected, rather than decoded as uncolored outputs.
+func TestGetTxoDataStrict(t *testing.T) {
+	var (
+		body    string
+		version string
+	)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if version != "" {
+				w.Header().Set(SchemaVersionHeader, version)
+			}
+			w.Write([]byte(body))
+		},
+	))
+	defer server.Close()
+
+	defer func(url string) {
+		ccTxoUrl = url
+	}(ccTxoUrl)
+	ccTxoUrl = server.URL
+
+	testCases := []struct {
+		name    string
+		body    string
+		version string
+		valid   bool
+	}{
+		{
+			name:  "colored output",
+			body:  `{"assetId": "La3Ubh", "value": 100}`,
+			valid: true,
+		},
+		{
+			name:  "uncolored output",
+			body:  `{"assetId": "", "value": 0}`,
+			valid: true,
+		},
+		{
+			name:    "matching schema version",
+			body:    `{"assetId": "La3Ubh", "value": 100}`,
+			version: "1",
+			valid:   true,
+		},
+		{
+			name:    "unknown schema version",
+			body:    `{"assetId": "La3Ubh", "value": 100}`,
+			version: "2",
+		},
+		{
+			name: "renamed field",
+			body: `{"assetId": "La3Ubh", "amount": 100}`,
+		},
+		{
+			name: "unknown field",
+			body: `{"assetId": "La3Ubh", "value": 100, "divisible": 2}`,
+		},
+		{
+			name: "empty object",
+			body: `{}`,
+		},
+		{
+			name: "empty body",
+		},
+		{
+			name: "zero value",
+			body: `{"assetId": "La3Ubh", "value": 0}`,
+		},
+	}
+	for _, testCase := range testCases {
+		body, version = testCase.body, testCase.version
+
+		txo, err := httpTxoData(wire.OutPoint{})
+		switch {
+		case testCase.valid && err != nil:
+			t.Fatalf("%v: unable to get color data: %v",
+				testCase.name, err)
+		case !testCase.valid && err == nil:
+			t.Fatalf("%v: invalid response accepted as %v",
+				testCase.name, txo)
+		}
+	}
+}
+
+// TestDebugColorify tests that the colorification dumped by DebugColorify
+// matches the transaction produced by ColorifyTx.
+func TestDebugColorify(t *testing.T) {
+	defer func(encoder func([]Instruction) ([]byte, error)) {
+		Encoder = encoder
+	}(Encoder)
+	Encoder = EncodeTransfer
+
+	tx := wire.NewMsgTx()
+	tx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(5000, []byte{0x00, 0x14}))
+	tx.AddTxOut(wire.NewTxOut(300, []byte{0x00, 0x20}))
+
+	debug, err := DebugColorify(tx)
+	if err != nil {
+		t.Fatalf("unable to colorify tx: %v", err)
+	}
+	coloredTx, err := ColorifyTx(tx, false)
+	if err != nil {
+		t.Fatalf("unable to colorify tx: %v", err)
+	}
+
+	if debug.Tx.TxSha() != coloredTx.TxSha() {
+		t.Fatalf("expected txid %v, got %v", coloredTx.TxSha(),
+			debug.Tx.TxSha())
+	}
+	if len(debug.Instructions) != 2 || len(debug.Outputs) != 2 {
+		t.Fatalf("expected 2 instructions and outputs, got %v and %v",
+			len(debug.Instructions), len(debug.Outputs))
+	}
+	if debug.OpReturnIndex != 2 {
+		t.Fatalf("expected OP_RETURN at index 2, got %v",
+			debug.OpReturnIndex)
+	}
+	opReturn := coloredTx.TxOut[debug.OpReturnIndex].PkScript
+	if !bytes.Equal(opReturn[2:], debug.Payload) {
+		t.Fatalf("expected payload %x, got %x", opReturn[2:],
+			debug.Payload)
+	}
+	for i, out := range debug.Outputs {
+		if out.AssetAmount != tx.TxOut[i].Value ||
+			out.CarrierValue != int64(dustAmount) {
+
+			t.Fatalf("output %v mapped incorrectly: %+v", i, out)
+		}
+	}
+
+	if debug.String() == "" {
+		t.Fatalf("empty debug dump")
+	}
+}
+
+// TestHTTPEncoderTransient tests that failures of the encoding service which
+// may succeed once retried are reported as transient, while others aren't.
+func TestHTTPEncoderTransient(t *testing.T) {
+	var (
+		status int
+		body   string
+	)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		},
+	))
+	defer server.Close()
+
+	encode := HTTPEncoder(server.URL)
+
+	testCases := []struct {
+		name      string
+		status    int
+		body      string
+		valid     bool
+		transient bool
+	}{
+		{
+			name:   "encoded",
+			status: http.StatusOK,
+			body:   "4343",
+			valid:  true,
+		},
+		{
+			name:      "empty body",
+			status:    http.StatusOK,
+			transient: true,
+		},
+		{
+			name:      "service unavailable",
+			status:    http.StatusServiceUnavailable,
+			body:      "down for maintenance",
+			transient: true,
+		},
+		{
+			name:      "rate limited",
+			status:    http.StatusTooManyRequests,
+			body:      "slow down",
+			transient: true,
+		},
+		{
+			name:   "bad request",
+			status: http.StatusBadRequest,
+			body:   "invalid instructions",
+		},
+	}
+	for _, testCase := range testCases {
+		status, body = testCase.status, testCase.body
+
+		encoded, err := encode([]Instruction{{Output: 0, Amount: 1}})
+		switch {
+		case testCase.valid && err != nil:
+			t.Fatalf("%v: unable to encode: %v", testCase.name, err)
+		case !testCase.valid && err == nil:
+			t.Fatalf("%v: failure accepted as %x", testCase.name,
+				encoded)
+		}
+		if IsTransient(err) != testCase.transient {
+			t.Fatalf("%v: expected transient %v, got %v: %v",
+				testCase.name, testCase.transient,
+				IsTransient(err), err)
+		}
+	}
+}
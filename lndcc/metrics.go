@@ -0,0 +1,36 @@
+package lndcc
+
+import (
+	"time"
+
+	"github.com/lightningnetwork/lnd/metrics"
+)
+
+var (
+	// serviceLatency tracks the latency of requests to the colored coins
+	// services, labelled by the service called: "encode" for the
+	// cc-encoding-api, "txo" for cc-txo-color, or "explorer" for the
+	// cc-explorer.
+	serviceLatency = metrics.DefaultRegistry.NewHistogram(
+		"lndcc_service_request_duration_seconds",
+		"Latency of requests to the colored coins services.",
+		metrics.DefaultBuckets, "service",
+	)
+
+	// serviceErrors counts the requests to the colored coins services
+	// which failed, labelled as serviceLatency.
+	serviceErrors = metrics.DefaultRegistry.NewCounter(
+		"lndcc_service_request_errors_total",
+		"Number of failed requests to the colored coins services.",
+		"service",
+	)
+)
+
+// recordRequest records the latency of a request to the passed colored coins
+// service which started at the passed time, along with its failure, if any.
+func recordRequest(service string, start time.Time, err error) {
+	serviceLatency.ObserveSince(start, service)
+	if err != nil {
+		serviceErrors.Inc(service)
+	}
+}
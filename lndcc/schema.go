@@ -0,0 +1,170 @@
+package lndcc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SchemaVersionHeader is the HTTP header carrying the version of the
+	// response schema of the colored coins services. It's sent along with
+	// each request to signal the version we expect, and if present within
+	// a response, must match it.
+	SchemaVersionHeader = "X-CC-Schema-Version"
+
+	// SchemaVersion is the version of the response schema of the colored
+	// coins services we understand.
+	SchemaVersion = 1
+)
+
+var (
+	// ErrEmptyResponse is returned when a colored coins service responds
+	// with an empty body.
+	ErrEmptyResponse = errors.New("empty response from colored coins " +
+		"service")
+
+	// ErrSchemaMismatch is returned when the response of a colored coins
+	// service doesn't match the schema we expect, indicating the service
+	// has changed in an incompatible manner.
+	ErrSchemaMismatch = errors.New("colored coins service response " +
+		"doesn't match the expected schema")
+)
+
+// checkSchemaVersion ensures the schema version advertised within the passed
+// response, if any, is the version we understand. Services predating the
+// header are assumed to serve the initial version.
+func checkSchemaVersion(resp *http.Response) error {
+	if resp == nil {
+		return nil
+	}
+
+	header := resp.Header.Get(SchemaVersionHeader)
+	if header == "" {
+		return nil
+	}
+
+	version, err := strconv.Atoi(strings.TrimSpace(header))
+	if err != nil || version != SchemaVersion {
+		return fmt.Errorf("%v: service advertises schema version %q, "+
+			"expected %v", ErrSchemaMismatch, header, SchemaVersion)
+	}
+
+	return nil
+}
+
+// decodeStrict decodes the passed JSON object into the struct pointed to by
+// v. Unlike json.Unmarshal, each field of the struct must be present within
+// the object, and the object must not contain any fields unknown to the
+// struct. Otherwise, a renamed field would silently decode as its zero
+// value.
+func decodeStrict(body []byte, v interface{}) error {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || bytes.Equal(body, []byte("null")) {
+		return ErrEmptyResponse
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return fmt.Errorf("%v: %v", ErrSchemaMismatch, err)
+	}
+	if len(fields) == 0 {
+		return ErrEmptyResponse
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(v).Elem())
+
+	var unknown, missing []string
+	for name := range fields {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range known {
+		if _, ok := fields[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(unknown) != 0 || len(missing) != 0 {
+		sort.Strings(unknown)
+		sort.Strings(missing)
+		return fmt.Errorf("%v: unknown fields %v, missing fields %v",
+			ErrSchemaMismatch, unknown, missing)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("%v: %v", ErrSchemaMismatch, err)
+	}
+
+	return nil
+}
+
+// jsonFieldNames returns the set of JSON field names of the passed struct
+// type, as determined by the json tags of its exported fields.
+func jsonFieldNames(t reflect.Type) map[string]struct{} {
+	names := make(map[string]struct{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+		names[name] = struct{}{}
+	}
+
+	return names
+}
+
+// validate ensures the color data is sane. An uncolored output carries no
+// asset at all, while a colored output always carries a positive amount of
+// its asset.
+func (d *TxoData) validate() error {
+	switch {
+	case d.Value < 0:
+		return fmt.Errorf("%v: negative asset value %v",
+			ErrSchemaMismatch, d.Value)
+
+	case d.AssetId == "" && d.Value != 0:
+		return fmt.Errorf("%v: asset value %v without an asset",
+			ErrSchemaMismatch, d.Value)
+
+	case d.AssetId != "" && d.Value == 0:
+		return fmt.Errorf("%v: zero value of asset %v",
+			ErrSchemaMismatch, d.AssetId)
+	}
+
+	return nil
+}
+
+// validate ensures the asset's issuance data is populated.
+func (a *AssetInfo) validate() error {
+	switch {
+	case a.AssetId == "":
+		return fmt.Errorf("%v: empty asset id", ErrSchemaMismatch)
+
+	case a.IssuanceTxid == "":
+		return fmt.Errorf("%v: empty issuance txid of asset %v",
+			ErrSchemaMismatch, a.AssetId)
+
+	case a.Divisibility < 0:
+		return fmt.Errorf("%v: negative divisibility %v of asset %v",
+			ErrSchemaMismatch, a.Divisibility, a.AssetId)
+	}
+
+	return nil
+}
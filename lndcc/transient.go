@@ -0,0 +1,48 @@
+package lndcc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrServiceUnavailable is returned when a colored coins service responds
+// with a status signalling a transient failure, such as 503 Service
+// Unavailable, or 429 Too Many Requests.
+var ErrServiceUnavailable = errors.New("colored coins service temporarily " +
+	"unavailable")
+
+// checkStatus ensures the passed response carries a successful status. A
+// server error, or rate limiting yields ErrServiceUnavailable, as the request
+// may succeed once retried.
+func checkStatus(resp *http.Response) error {
+	switch {
+	case resp == nil:
+		return nil
+	case resp.StatusCode >= 500,
+		resp.StatusCode == http.StatusTooManyRequests:
+		return ErrServiceUnavailable
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("colored coins service responded with %v",
+			resp.Status)
+	}
+
+	return nil
+}
+
+// IsTransient returns true if the passed error, as returned by a request to
+// a colored coins service, is transient: the request may succeed if retried
+// unchanged. Network failures, empty responses, and unavailable services are
+// transient, while malformed, or incompatible responses are not.
+func IsTransient(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case err == ErrEmptyResponse, err == ErrServiceUnavailable:
+		return true
+	}
+
+	_, ok := err.(net.Error)
+	return ok
+}
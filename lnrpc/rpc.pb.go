@@ -45,6 +45,44 @@ It has these top-level messages:
 	RoutingTableLink
 	ShowRoutingTableRequest
 	ShowRoutingTableResponse
+	HeldHTLC
+	ListHeldHTLCsRequest
+	ListHeldHTLCsResponse
+	ResolveHTLCRequest
+	ResolveHTLCResponse
+	ForwardingHistoryRequest
+	ForwardingEvent
+	ForwardingHistoryResponse
+	EstimateTxRequest
+	EstimateTxResponse
+	KeysendRequest
+	KeysendResponse
+	MultiPartPaymentRequest
+	MultiPartPaymentResponse
+	RebalanceRequest
+	RebalanceResponse
+	RecoverChannelsRequest
+	RecoveredChannel
+	ChannelRecoveryIssue
+	RecoverChannelsResponse
+	PayBTCInvoiceRequest
+	PayBTCInvoiceResponse
+	ProbeRouteRequest
+	RouteHop
+	ProbeRouteResponse
+	AbortFundingRequest
+	AbortFundingResponse
+	ClosedChannelsRequest
+	ClosedChannel
+	ClosedChannelsResponse
+	LiquidityReportRequest
+	AssetLiquidity
+	LiquidityReportResponse
+	AuditLogRequest
+	AuditRecord
+	AuditLogResponse
+	FundingBundleRequest
+	FundingBundleResponse
 */
 package lnrpc
 
@@ -389,9 +427,10 @@ func (*ChannelCloseUpdate) ProtoMessage()               {}
 func (*ChannelCloseUpdate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 type CloseChannelRequest struct {
-	ChannelPoint *ChannelPoint `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
-	TimeLimit    int64         `protobuf:"varint,2,opt,name=time_limit,json=timeLimit" json:"time_limit,omitempty"`
-	Force        bool          `protobuf:"varint,3,opt,name=force" json:"force,omitempty"`
+	ChannelPoint    *ChannelPoint `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	TimeLimit       int64         `protobuf:"varint,2,opt,name=time_limit,json=timeLimit" json:"time_limit,omitempty"`
+	Force           bool          `protobuf:"varint,3,opt,name=force" json:"force,omitempty"`
+	DeliveryAddress string        `protobuf:"bytes,4,opt,name=delivery_address,json=deliveryAddress" json:"delivery_address,omitempty"`
 }
 
 func (m *CloseChannelRequest) Reset()                    { *m = CloseChannelRequest{} }
@@ -759,14 +798,15 @@ func (m *PendingChannelResponse) GetPendingChannels() []*PendingChannelResponse_
 }
 
 type PendingChannelResponse_PendingChannel struct {
-	PeerId        int32         `protobuf:"varint,1,opt,name=peer_id,json=peerId" json:"peer_id,omitempty"`
-	LightningId   string        `protobuf:"bytes,2,opt,name=lightning_id,json=lightningId" json:"lightning_id,omitempty"`
-	ChannelPoint  string        `protobuf:"bytes,3,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
-	Capacity      int64         `protobuf:"varint,4,opt,name=capacity" json:"capacity,omitempty"`
-	LocalBalance  int64         `protobuf:"varint,5,opt,name=local_balance,json=localBalance" json:"local_balance,omitempty"`
-	RemoteBalance int64         `protobuf:"varint,6,opt,name=remote_balance,json=remoteBalance" json:"remote_balance,omitempty"`
-	ClosingTxid   string        `protobuf:"bytes,7,opt,name=closing_txid,json=closingTxid" json:"closing_txid,omitempty"`
-	Status        ChannelStatus `protobuf:"varint,8,opt,name=status,enum=lnrpc.ChannelStatus" json:"status,omitempty"`
+	PeerId           int32         `protobuf:"varint,1,opt,name=peer_id,json=peerId" json:"peer_id,omitempty"`
+	LightningId      string        `protobuf:"bytes,2,opt,name=lightning_id,json=lightningId" json:"lightning_id,omitempty"`
+	ChannelPoint     string        `protobuf:"bytes,3,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	Capacity         int64         `protobuf:"varint,4,opt,name=capacity" json:"capacity,omitempty"`
+	LocalBalance     int64         `protobuf:"varint,5,opt,name=local_balance,json=localBalance" json:"local_balance,omitempty"`
+	RemoteBalance    int64         `protobuf:"varint,6,opt,name=remote_balance,json=remoteBalance" json:"remote_balance,omitempty"`
+	ClosingTxid      string        `protobuf:"bytes,7,opt,name=closing_txid,json=closingTxid" json:"closing_txid,omitempty"`
+	Status           ChannelStatus `protobuf:"varint,8,opt,name=status,enum=lnrpc.ChannelStatus" json:"status,omitempty"`
+	ReservationState string        `protobuf:"bytes,9,opt,name=reservation_state,json=reservationState" json:"reservation_state,omitempty"`
 }
 
 func (m *PendingChannelResponse_PendingChannel) Reset()         { *m = PendingChannelResponse_PendingChannel{} }
@@ -848,6 +888,519 @@ func (m *ShowRoutingTableResponse) GetChannels() []*RoutingTableLink {
 	return nil
 }
 
+type HeldHTLC struct {
+	ChannelPoint string `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	Index        uint64 `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+	PaymentHash  []byte `protobuf:"bytes,3,opt,name=payment_hash,json=paymentHash,proto3" json:"payment_hash,omitempty"`
+	AssetId      string `protobuf:"bytes,4,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Amount       int64  `protobuf:"varint,5,opt,name=amount" json:"amount,omitempty"`
+	Expiry       uint32 `protobuf:"varint,6,opt,name=expiry" json:"expiry,omitempty"`
+	Payload      []byte `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *HeldHTLC) Reset()                    { *m = HeldHTLC{} }
+func (m *HeldHTLC) String() string            { return proto.CompactTextString(m) }
+func (*HeldHTLC) ProtoMessage()               {}
+func (*HeldHTLC) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+type ListHeldHTLCsRequest struct {
+}
+
+func (m *ListHeldHTLCsRequest) Reset()                    { *m = ListHeldHTLCsRequest{} }
+func (m *ListHeldHTLCsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListHeldHTLCsRequest) ProtoMessage()               {}
+func (*ListHeldHTLCsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+type ListHeldHTLCsResponse struct {
+	Htlcs []*HeldHTLC `protobuf:"bytes,1,rep,name=htlcs" json:"htlcs,omitempty"`
+}
+
+func (m *ListHeldHTLCsResponse) Reset()                    { *m = ListHeldHTLCsResponse{} }
+func (m *ListHeldHTLCsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ListHeldHTLCsResponse) ProtoMessage()               {}
+func (*ListHeldHTLCsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *ListHeldHTLCsResponse) GetHtlcs() []*HeldHTLC {
+	if m != nil {
+		return m.Htlcs
+	}
+	return nil
+}
+
+type ResolveHTLCRequest struct {
+	ChannelPoint *ChannelPoint `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	Index        uint64        `protobuf:"varint,2,opt,name=index" json:"index,omitempty"`
+	Preimage     []byte        `protobuf:"bytes,3,opt,name=preimage,proto3" json:"preimage,omitempty"`
+	Fail         bool          `protobuf:"varint,4,opt,name=fail" json:"fail,omitempty"`
+}
+
+func (m *ResolveHTLCRequest) Reset()                    { *m = ResolveHTLCRequest{} }
+func (m *ResolveHTLCRequest) String() string            { return proto.CompactTextString(m) }
+func (*ResolveHTLCRequest) ProtoMessage()               {}
+func (*ResolveHTLCRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *ResolveHTLCRequest) GetChannelPoint() *ChannelPoint {
+	if m != nil {
+		return m.ChannelPoint
+	}
+	return nil
+}
+
+type ResolveHTLCResponse struct {
+}
+
+func (m *ResolveHTLCResponse) Reset()                    { *m = ResolveHTLCResponse{} }
+func (m *ResolveHTLCResponse) String() string            { return proto.CompactTextString(m) }
+func (*ResolveHTLCResponse) ProtoMessage()               {}
+func (*ResolveHTLCResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+type ForwardingHistoryRequest struct {
+	StartTime    int64  `protobuf:"varint,1,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	EndTime      int64  `protobuf:"varint,2,opt,name=end_time,json=endTime" json:"end_time,omitempty"`
+	AssetId      string `protobuf:"bytes,3,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	NumMaxEvents uint32 `protobuf:"varint,4,opt,name=num_max_events,json=numMaxEvents" json:"num_max_events,omitempty"`
+}
+
+func (m *ForwardingHistoryRequest) Reset()                    { *m = ForwardingHistoryRequest{} }
+func (m *ForwardingHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*ForwardingHistoryRequest) ProtoMessage()               {}
+func (*ForwardingHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+type ForwardingEvent struct {
+	Timestamp    int64  `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	ChanPointIn  string `protobuf:"bytes,2,opt,name=chan_point_in,json=chanPointIn" json:"chan_point_in,omitempty"`
+	ChanPointOut string `protobuf:"bytes,3,opt,name=chan_point_out,json=chanPointOut" json:"chan_point_out,omitempty"`
+	AssetIn      string `protobuf:"bytes,4,opt,name=asset_in,json=assetIn" json:"asset_in,omitempty"`
+	AssetOut     string `protobuf:"bytes,5,opt,name=asset_out,json=assetOut" json:"asset_out,omitempty"`
+	AmtIn        int64  `protobuf:"varint,6,opt,name=amt_in,json=amtIn" json:"amt_in,omitempty"`
+	AmtOut       int64  `protobuf:"varint,7,opt,name=amt_out,json=amtOut" json:"amt_out,omitempty"`
+	Fee          int64  `protobuf:"varint,8,opt,name=fee" json:"fee,omitempty"`
+}
+
+func (m *ForwardingEvent) Reset()                    { *m = ForwardingEvent{} }
+func (m *ForwardingEvent) String() string            { return proto.CompactTextString(m) }
+func (*ForwardingEvent) ProtoMessage()               {}
+func (*ForwardingEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+type ForwardingHistoryResponse struct {
+	ForwardingEvents []*ForwardingEvent `protobuf:"bytes,1,rep,name=forwarding_events,json=forwardingEvents" json:"forwarding_events,omitempty"`
+}
+
+func (m *ForwardingHistoryResponse) Reset()                    { *m = ForwardingHistoryResponse{} }
+func (m *ForwardingHistoryResponse) String() string            { return proto.CompactTextString(m) }
+func (*ForwardingHistoryResponse) ProtoMessage()               {}
+func (*ForwardingHistoryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *ForwardingHistoryResponse) GetForwardingEvents() []*ForwardingEvent {
+	if m != nil {
+		return m.ForwardingEvents
+	}
+	return nil
+}
+
+type EstimateTxRequest struct {
+	AssetAmount int64  `protobuf:"varint,1,opt,name=asset_amount,json=assetAmount" json:"asset_amount,omitempty"`
+	NumOutputs  uint32 `protobuf:"varint,2,opt,name=num_outputs,json=numOutputs" json:"num_outputs,omitempty"`
+	SatPerByte  uint64 `protobuf:"varint,3,opt,name=sat_per_byte,json=satPerByte" json:"sat_per_byte,omitempty"`
+}
+
+func (m *EstimateTxRequest) Reset()                    { *m = EstimateTxRequest{} }
+func (m *EstimateTxRequest) String() string            { return proto.CompactTextString(m) }
+func (*EstimateTxRequest) ProtoMessage()               {}
+func (*EstimateTxRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+type EstimateTxResponse struct {
+	Inputs     []string `protobuf:"bytes,1,rep,name=inputs" json:"inputs,omitempty"`
+	Vsize      int64    `protobuf:"varint,2,opt,name=vsize" json:"vsize,omitempty"`
+	FeeSat     int64    `protobuf:"varint,3,opt,name=fee_sat,json=feeSat" json:"fee_sat,omitempty"`
+	Change     int64    `protobuf:"varint,4,opt,name=change" json:"change,omitempty"`
+	FuelChange int64    `protobuf:"varint,5,opt,name=fuel_change,json=fuelChange" json:"fuel_change,omitempty"`
+}
+
+func (m *EstimateTxResponse) Reset()                    { *m = EstimateTxResponse{} }
+func (m *EstimateTxResponse) String() string            { return proto.CompactTextString(m) }
+func (*EstimateTxResponse) ProtoMessage()               {}
+func (*EstimateTxResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+type KeysendRequest struct {
+	DestPubkey []byte `protobuf:"bytes,1,opt,name=dest_pubkey,json=destPubkey,proto3" json:"dest_pubkey,omitempty"`
+	Amt        int64  `protobuf:"varint,2,opt,name=amt" json:"amt,omitempty"`
+}
+
+func (m *KeysendRequest) Reset()                    { *m = KeysendRequest{} }
+func (m *KeysendRequest) String() string            { return proto.CompactTextString(m) }
+func (*KeysendRequest) ProtoMessage()               {}
+func (*KeysendRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+type KeysendResponse struct {
+	PaymentPreimage []byte `protobuf:"bytes,1,opt,name=payment_preimage,json=paymentPreimage,proto3" json:"payment_preimage,omitempty"`
+	PaymentHash     []byte `protobuf:"bytes,2,opt,name=payment_hash,json=paymentHash,proto3" json:"payment_hash,omitempty"`
+}
+
+func (m *KeysendResponse) Reset()                    { *m = KeysendResponse{} }
+func (m *KeysendResponse) String() string            { return proto.CompactTextString(m) }
+func (*KeysendResponse) ProtoMessage()               {}
+func (*KeysendResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+type MultiPartPaymentRequest struct {
+	Dest        []byte `protobuf:"bytes,1,opt,name=dest,proto3" json:"dest,omitempty"`
+	AssetId     string `protobuf:"bytes,2,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Amt         int64  `protobuf:"varint,3,opt,name=amt" json:"amt,omitempty"`
+	PaymentHash []byte `protobuf:"bytes,4,opt,name=payment_hash,json=paymentHash,proto3" json:"payment_hash,omitempty"`
+}
+
+func (m *MultiPartPaymentRequest) Reset()                    { *m = MultiPartPaymentRequest{} }
+func (m *MultiPartPaymentRequest) String() string            { return proto.CompactTextString(m) }
+func (*MultiPartPaymentRequest) ProtoMessage()               {}
+func (*MultiPartPaymentRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+type MultiPartPaymentResponse struct {
+}
+
+func (m *MultiPartPaymentResponse) Reset()                    { *m = MultiPartPaymentResponse{} }
+func (m *MultiPartPaymentResponse) String() string            { return proto.CompactTextString(m) }
+func (*MultiPartPaymentResponse) ProtoMessage()               {}
+func (*MultiPartPaymentResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+type RebalanceRequest struct {
+	AssetId  string        `protobuf:"bytes,1,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Amt      int64         `protobuf:"varint,2,opt,name=amt" json:"amt,omitempty"`
+	FromChan *ChannelPoint `protobuf:"bytes,3,opt,name=from_chan,json=fromChan" json:"from_chan,omitempty"`
+	ToChan   *ChannelPoint `protobuf:"bytes,4,opt,name=to_chan,json=toChan" json:"to_chan,omitempty"`
+}
+
+func (m *RebalanceRequest) Reset()                    { *m = RebalanceRequest{} }
+func (m *RebalanceRequest) String() string            { return proto.CompactTextString(m) }
+func (*RebalanceRequest) ProtoMessage()               {}
+func (*RebalanceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+func (m *RebalanceRequest) GetFromChan() *ChannelPoint {
+	if m != nil {
+		return m.FromChan
+	}
+	return nil
+}
+
+func (m *RebalanceRequest) GetToChan() *ChannelPoint {
+	if m != nil {
+		return m.ToChan
+	}
+	return nil
+}
+
+type RebalanceResponse struct {
+}
+
+func (m *RebalanceResponse) Reset()                    { *m = RebalanceResponse{} }
+func (m *RebalanceResponse) String() string            { return proto.CompactTextString(m) }
+func (*RebalanceResponse) ProtoMessage()               {}
+func (*RebalanceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+type RecoverChannelsRequest struct {
+}
+
+func (m *RecoverChannelsRequest) Reset()                    { *m = RecoverChannelsRequest{} }
+func (m *RecoverChannelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*RecoverChannelsRequest) ProtoMessage()               {}
+func (*RecoverChannelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+type RecoveredChannel struct {
+	ChannelPoint string `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	SpentOffline bool   `protobuf:"varint,2,opt,name=spent_offline,json=spentOffline" json:"spent_offline,omitempty"`
+}
+
+func (m *RecoveredChannel) Reset()                    { *m = RecoveredChannel{} }
+func (m *RecoveredChannel) String() string            { return proto.CompactTextString(m) }
+func (*RecoveredChannel) ProtoMessage()               {}
+func (*RecoveredChannel) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+type ChannelRecoveryIssue struct {
+	ChannelPoint string `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	RemoteId     string `protobuf:"bytes,2,opt,name=remote_id,json=remoteId" json:"remote_id,omitempty"`
+	Error        string `protobuf:"bytes,3,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *ChannelRecoveryIssue) Reset()                    { *m = ChannelRecoveryIssue{} }
+func (m *ChannelRecoveryIssue) String() string            { return proto.CompactTextString(m) }
+func (*ChannelRecoveryIssue) ProtoMessage()               {}
+func (*ChannelRecoveryIssue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+type RecoverChannelsResponse struct {
+	Recovered   []*RecoveredChannel     `protobuf:"bytes,1,rep,name=recovered" json:"recovered,omitempty"`
+	NeedsAction []*ChannelRecoveryIssue `protobuf:"bytes,2,rep,name=needs_action,json=needsAction" json:"needs_action,omitempty"`
+}
+
+func (m *RecoverChannelsResponse) Reset()                    { *m = RecoverChannelsResponse{} }
+func (m *RecoverChannelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*RecoverChannelsResponse) ProtoMessage()               {}
+func (*RecoverChannelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+
+func (m *RecoverChannelsResponse) GetRecovered() []*RecoveredChannel {
+	if m != nil {
+		return m.Recovered
+	}
+	return nil
+}
+
+func (m *RecoverChannelsResponse) GetNeedsAction() []*ChannelRecoveryIssue {
+	if m != nil {
+		return m.NeedsAction
+	}
+	return nil
+}
+
+type PayBTCInvoiceRequest struct {
+	AssetId     string `protobuf:"bytes,1,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Dest        []byte `protobuf:"bytes,2,opt,name=dest,proto3" json:"dest,omitempty"`
+	PaymentHash []byte `protobuf:"bytes,3,opt,name=payment_hash,json=paymentHash,proto3" json:"payment_hash,omitempty"`
+	Amt         int64  `protobuf:"varint,4,opt,name=amt" json:"amt,omitempty"`
+}
+
+func (m *PayBTCInvoiceRequest) Reset()                    { *m = PayBTCInvoiceRequest{} }
+func (m *PayBTCInvoiceRequest) String() string            { return proto.CompactTextString(m) }
+func (*PayBTCInvoiceRequest) ProtoMessage()               {}
+func (*PayBTCInvoiceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
+
+type PayBTCInvoiceResponse struct {
+	ConversionNode    []byte `protobuf:"bytes,1,opt,name=conversion_node,json=conversionNode,proto3" json:"conversion_node,omitempty"`
+	AssetSent         int64  `protobuf:"varint,2,opt,name=asset_sent,json=assetSent" json:"asset_sent,omitempty"`
+	SlippageAllowance int64  `protobuf:"varint,3,opt,name=slippage_allowance,json=slippageAllowance" json:"slippage_allowance,omitempty"`
+	NumHops           uint32 `protobuf:"varint,4,opt,name=num_hops,json=numHops" json:"num_hops,omitempty"`
+}
+
+func (m *PayBTCInvoiceResponse) Reset()                    { *m = PayBTCInvoiceResponse{} }
+func (m *PayBTCInvoiceResponse) String() string            { return proto.CompactTextString(m) }
+func (*PayBTCInvoiceResponse) ProtoMessage()               {}
+func (*PayBTCInvoiceResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
+
+type ProbeRouteRequest struct {
+	Dest    []byte `protobuf:"bytes,1,opt,name=dest,proto3" json:"dest,omitempty"`
+	AssetId string `protobuf:"bytes,2,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Amt     int64  `protobuf:"varint,3,opt,name=amt" json:"amt,omitempty"`
+}
+
+func (m *ProbeRouteRequest) Reset()                    { *m = ProbeRouteRequest{} }
+func (m *ProbeRouteRequest) String() string            { return proto.CompactTextString(m) }
+func (*ProbeRouteRequest) ProtoMessage()               {}
+func (*ProbeRouteRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
+
+type RouteHop struct {
+	ChannelPoint string `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	NextNode     []byte `protobuf:"bytes,2,opt,name=next_node,json=nextNode,proto3" json:"next_node,omitempty"`
+	AssetId      string `protobuf:"bytes,3,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Amount       int64  `protobuf:"varint,4,opt,name=amount" json:"amount,omitempty"`
+}
+
+func (m *RouteHop) Reset()                    { *m = RouteHop{} }
+func (m *RouteHop) String() string            { return proto.CompactTextString(m) }
+func (*RouteHop) ProtoMessage()               {}
+func (*RouteHop) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+type ProbeRouteResponse struct {
+	Hops []*RouteHop `protobuf:"bytes,1,rep,name=hops" json:"hops,omitempty"`
+}
+
+func (m *ProbeRouteResponse) Reset()                    { *m = ProbeRouteResponse{} }
+func (m *ProbeRouteResponse) String() string            { return proto.CompactTextString(m) }
+func (*ProbeRouteResponse) ProtoMessage()               {}
+func (*ProbeRouteResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+func (m *ProbeRouteResponse) GetHops() []*RouteHop {
+	if m != nil {
+		return m.Hops
+	}
+	return nil
+}
+
+type AbortFundingRequest struct {
+	ChannelPoint *ChannelPoint `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+}
+
+func (m *AbortFundingRequest) Reset()                    { *m = AbortFundingRequest{} }
+func (m *AbortFundingRequest) String() string            { return proto.CompactTextString(m) }
+func (*AbortFundingRequest) ProtoMessage()               {}
+func (*AbortFundingRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *AbortFundingRequest) GetChannelPoint() *ChannelPoint {
+	if m != nil {
+		return m.ChannelPoint
+	}
+	return nil
+}
+
+type AbortFundingResponse struct {
+	ReplacementTxid []byte `protobuf:"bytes,1,opt,name=replacement_txid,json=replacementTxid,proto3" json:"replacement_txid,omitempty"`
+}
+
+func (m *AbortFundingResponse) Reset()                    { *m = AbortFundingResponse{} }
+func (m *AbortFundingResponse) String() string            { return proto.CompactTextString(m) }
+func (*AbortFundingResponse) ProtoMessage()               {}
+func (*AbortFundingResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
+
+type ClosedChannelsRequest struct {
+}
+
+func (m *ClosedChannelsRequest) Reset()                    { *m = ClosedChannelsRequest{} }
+func (m *ClosedChannelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ClosedChannelsRequest) ProtoMessage()               {}
+func (*ClosedChannelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
+
+type ClosedChannel struct {
+	ChannelPoint string `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	RemoteId     string `protobuf:"bytes,2,opt,name=remote_id,json=remoteId" json:"remote_id,omitempty"`
+	AssetId      string `protobuf:"bytes,3,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	Capacity     int64  `protobuf:"varint,4,opt,name=capacity" json:"capacity,omitempty"`
+	OurBalance   int64  `protobuf:"varint,5,opt,name=our_balance,json=ourBalance" json:"our_balance,omitempty"`
+	TheirBalance int64  `protobuf:"varint,6,opt,name=their_balance,json=theirBalance" json:"their_balance,omitempty"`
+	CloseType    string `protobuf:"bytes,7,opt,name=close_type,json=closeType" json:"close_type,omitempty"`
+	ClosingTxid  string `protobuf:"bytes,8,opt,name=closing_txid,json=closingTxid" json:"closing_txid,omitempty"`
+	CloseHeight  uint32 `protobuf:"varint,9,opt,name=close_height,json=closeHeight" json:"close_height,omitempty"`
+}
+
+func (m *ClosedChannel) Reset()                    { *m = ClosedChannel{} }
+func (m *ClosedChannel) String() string            { return proto.CompactTextString(m) }
+func (*ClosedChannel) ProtoMessage()               {}
+func (*ClosedChannel) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+type ClosedChannelsResponse struct {
+	Channels []*ClosedChannel `protobuf:"bytes,1,rep,name=channels" json:"channels,omitempty"`
+}
+
+func (m *ClosedChannelsResponse) Reset()                    { *m = ClosedChannelsResponse{} }
+func (m *ClosedChannelsResponse) String() string            { return proto.CompactTextString(m) }
+func (*ClosedChannelsResponse) ProtoMessage()               {}
+func (*ClosedChannelsResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *ClosedChannelsResponse) GetChannels() []*ClosedChannel {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+type LiquidityReportRequest struct {
+}
+
+func (m *LiquidityReportRequest) Reset()                    { *m = LiquidityReportRequest{} }
+func (m *LiquidityReportRequest) String() string            { return proto.CompactTextString(m) }
+func (*LiquidityReportRequest) ProtoMessage()               {}
+func (*LiquidityReportRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{66} }
+
+type AssetLiquidity struct {
+	LightningId     string `protobuf:"bytes,1,opt,name=lightning_id,json=lightningId" json:"lightning_id,omitempty"`
+	AssetId         string `protobuf:"bytes,2,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	NumChannels     int64  `protobuf:"varint,3,opt,name=num_channels,json=numChannels" json:"num_channels,omitempty"`
+	Capacity        int64  `protobuf:"varint,4,opt,name=capacity" json:"capacity,omitempty"`
+	LocalBalance    int64  `protobuf:"varint,5,opt,name=local_balance,json=localBalance" json:"local_balance,omitempty"`
+	RemoteBalance   int64  `protobuf:"varint,6,opt,name=remote_balance,json=remoteBalance" json:"remote_balance,omitempty"`
+	NumPendingHtlcs int64  `protobuf:"varint,7,opt,name=num_pending_htlcs,json=numPendingHtlcs" json:"num_pending_htlcs,omitempty"`
+	PendingIncoming int64  `protobuf:"varint,8,opt,name=pending_incoming,json=pendingIncoming" json:"pending_incoming,omitempty"`
+	PendingOutgoing int64  `protobuf:"varint,9,opt,name=pending_outgoing,json=pendingOutgoing" json:"pending_outgoing,omitempty"`
+}
+
+func (m *AssetLiquidity) Reset()                    { *m = AssetLiquidity{} }
+func (m *AssetLiquidity) String() string            { return proto.CompactTextString(m) }
+func (*AssetLiquidity) ProtoMessage()               {}
+func (*AssetLiquidity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
+
+type LiquidityReportResponse struct {
+	Liquidity []*AssetLiquidity `protobuf:"bytes,1,rep,name=liquidity" json:"liquidity,omitempty"`
+}
+
+func (m *LiquidityReportResponse) Reset()                    { *m = LiquidityReportResponse{} }
+func (m *LiquidityReportResponse) String() string            { return proto.CompactTextString(m) }
+func (*LiquidityReportResponse) ProtoMessage()               {}
+func (*LiquidityReportResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
+
+func (m *LiquidityReportResponse) GetLiquidity() []*AssetLiquidity {
+	if m != nil {
+		return m.Liquidity
+	}
+	return nil
+}
+
+type AuditLogRequest struct {
+	StartTime     int64         `protobuf:"varint,1,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	EndTime       int64         `protobuf:"varint,2,opt,name=end_time,json=endTime" json:"end_time,omitempty"`
+	AssetId       string        `protobuf:"bytes,3,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	ChannelPoint  *ChannelPoint `protobuf:"bytes,4,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	Purposes      []string      `protobuf:"bytes,5,rep,name=purposes" json:"purposes,omitempty"`
+	NumMaxRecords uint32        `protobuf:"varint,6,opt,name=num_max_records,json=numMaxRecords" json:"num_max_records,omitempty"`
+}
+
+func (m *AuditLogRequest) Reset()                    { *m = AuditLogRequest{} }
+func (m *AuditLogRequest) String() string            { return proto.CompactTextString(m) }
+func (*AuditLogRequest) ProtoMessage()               {}
+func (*AuditLogRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{69} }
+
+func (m *AuditLogRequest) GetChannelPoint() *ChannelPoint {
+	if m != nil {
+		return m.ChannelPoint
+	}
+	return nil
+}
+
+type AuditRecord struct {
+	Seq          uint64 `protobuf:"varint,1,opt,name=seq" json:"seq,omitempty"`
+	Timestamp    int64  `protobuf:"varint,2,opt,name=timestamp" json:"timestamp,omitempty"`
+	Purpose      string `protobuf:"bytes,3,opt,name=purpose" json:"purpose,omitempty"`
+	ChannelPoint string `protobuf:"bytes,4,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+	AssetId      string `protobuf:"bytes,5,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	RawTx        []byte `protobuf:"bytes,6,opt,name=raw_tx,json=rawTx,proto3" json:"raw_tx,omitempty"`
+	Instructions []byte `protobuf:"bytes,7,opt,name=instructions,proto3" json:"instructions,omitempty"`
+	Result       string `protobuf:"bytes,8,opt,name=result" json:"result,omitempty"`
+}
+
+func (m *AuditRecord) Reset()                    { *m = AuditRecord{} }
+func (m *AuditRecord) String() string            { return proto.CompactTextString(m) }
+func (*AuditRecord) ProtoMessage()               {}
+func (*AuditRecord) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{70} }
+
+type AuditLogResponse struct {
+	Records []*AuditRecord `protobuf:"bytes,1,rep,name=records" json:"records,omitempty"`
+}
+
+func (m *AuditLogResponse) Reset()                    { *m = AuditLogResponse{} }
+func (m *AuditLogResponse) String() string            { return proto.CompactTextString(m) }
+func (*AuditLogResponse) ProtoMessage()               {}
+func (*AuditLogResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{71} }
+
+func (m *AuditLogResponse) GetRecords() []*AuditRecord {
+	if m != nil {
+		return m.Records
+	}
+	return nil
+}
+
+type FundingBundleRequest struct {
+	ChannelPoint *ChannelPoint `protobuf:"bytes,1,opt,name=channel_point,json=channelPoint" json:"channel_point,omitempty"`
+}
+
+func (m *FundingBundleRequest) Reset()                    { *m = FundingBundleRequest{} }
+func (m *FundingBundleRequest) String() string            { return proto.CompactTextString(m) }
+func (*FundingBundleRequest) ProtoMessage()               {}
+func (*FundingBundleRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{72} }
+
+func (m *FundingBundleRequest) GetChannelPoint() *ChannelPoint {
+	if m != nil {
+		return m.ChannelPoint
+	}
+	return nil
+}
+
+type FundingBundleResponse struct {
+	AssetId       string `protobuf:"bytes,1,opt,name=asset_id,json=assetId" json:"asset_id,omitempty"`
+	AssetCapacity int64  `protobuf:"varint,2,opt,name=asset_capacity,json=assetCapacity" json:"asset_capacity,omitempty"`
+	LocalKey      string `protobuf:"bytes,3,opt,name=local_key,json=localKey" json:"local_key,omitempty"`
+	RemoteKey     string `protobuf:"bytes,4,opt,name=remote_key,json=remoteKey" json:"remote_key,omitempty"`
+	Bundle        []byte `protobuf:"bytes,5,opt,name=bundle,proto3" json:"bundle,omitempty"`
+}
+
+func (m *FundingBundleResponse) Reset()                    { *m = FundingBundleResponse{} }
+func (m *FundingBundleResponse) String() string            { return proto.CompactTextString(m) }
+func (*FundingBundleResponse) ProtoMessage()               {}
+func (*FundingBundleResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{73} }
+
 func init() {
 	proto.RegisterType((*SendRequest)(nil), "lnrpc.SendRequest")
 	proto.RegisterType((*SendResponse)(nil), "lnrpc.SendResponse")
@@ -886,6 +1439,44 @@ func init() {
 	proto.RegisterType((*RoutingTableLink)(nil), "lnrpc.RoutingTableLink")
 	proto.RegisterType((*ShowRoutingTableRequest)(nil), "lnrpc.ShowRoutingTableRequest")
 	proto.RegisterType((*ShowRoutingTableResponse)(nil), "lnrpc.ShowRoutingTableResponse")
+	proto.RegisterType((*HeldHTLC)(nil), "lnrpc.HeldHTLC")
+	proto.RegisterType((*ListHeldHTLCsRequest)(nil), "lnrpc.ListHeldHTLCsRequest")
+	proto.RegisterType((*ListHeldHTLCsResponse)(nil), "lnrpc.ListHeldHTLCsResponse")
+	proto.RegisterType((*ResolveHTLCRequest)(nil), "lnrpc.ResolveHTLCRequest")
+	proto.RegisterType((*ResolveHTLCResponse)(nil), "lnrpc.ResolveHTLCResponse")
+	proto.RegisterType((*ForwardingHistoryRequest)(nil), "lnrpc.ForwardingHistoryRequest")
+	proto.RegisterType((*ForwardingEvent)(nil), "lnrpc.ForwardingEvent")
+	proto.RegisterType((*ForwardingHistoryResponse)(nil), "lnrpc.ForwardingHistoryResponse")
+	proto.RegisterType((*EstimateTxRequest)(nil), "lnrpc.EstimateTxRequest")
+	proto.RegisterType((*EstimateTxResponse)(nil), "lnrpc.EstimateTxResponse")
+	proto.RegisterType((*KeysendRequest)(nil), "lnrpc.KeysendRequest")
+	proto.RegisterType((*KeysendResponse)(nil), "lnrpc.KeysendResponse")
+	proto.RegisterType((*MultiPartPaymentRequest)(nil), "lnrpc.MultiPartPaymentRequest")
+	proto.RegisterType((*MultiPartPaymentResponse)(nil), "lnrpc.MultiPartPaymentResponse")
+	proto.RegisterType((*RebalanceRequest)(nil), "lnrpc.RebalanceRequest")
+	proto.RegisterType((*RebalanceResponse)(nil), "lnrpc.RebalanceResponse")
+	proto.RegisterType((*RecoverChannelsRequest)(nil), "lnrpc.RecoverChannelsRequest")
+	proto.RegisterType((*RecoveredChannel)(nil), "lnrpc.RecoveredChannel")
+	proto.RegisterType((*ChannelRecoveryIssue)(nil), "lnrpc.ChannelRecoveryIssue")
+	proto.RegisterType((*RecoverChannelsResponse)(nil), "lnrpc.RecoverChannelsResponse")
+	proto.RegisterType((*PayBTCInvoiceRequest)(nil), "lnrpc.PayBTCInvoiceRequest")
+	proto.RegisterType((*PayBTCInvoiceResponse)(nil), "lnrpc.PayBTCInvoiceResponse")
+	proto.RegisterType((*ProbeRouteRequest)(nil), "lnrpc.ProbeRouteRequest")
+	proto.RegisterType((*RouteHop)(nil), "lnrpc.RouteHop")
+	proto.RegisterType((*ProbeRouteResponse)(nil), "lnrpc.ProbeRouteResponse")
+	proto.RegisterType((*AbortFundingRequest)(nil), "lnrpc.AbortFundingRequest")
+	proto.RegisterType((*AbortFundingResponse)(nil), "lnrpc.AbortFundingResponse")
+	proto.RegisterType((*ClosedChannelsRequest)(nil), "lnrpc.ClosedChannelsRequest")
+	proto.RegisterType((*ClosedChannel)(nil), "lnrpc.ClosedChannel")
+	proto.RegisterType((*ClosedChannelsResponse)(nil), "lnrpc.ClosedChannelsResponse")
+	proto.RegisterType((*LiquidityReportRequest)(nil), "lnrpc.LiquidityReportRequest")
+	proto.RegisterType((*AssetLiquidity)(nil), "lnrpc.AssetLiquidity")
+	proto.RegisterType((*LiquidityReportResponse)(nil), "lnrpc.LiquidityReportResponse")
+	proto.RegisterType((*AuditLogRequest)(nil), "lnrpc.AuditLogRequest")
+	proto.RegisterType((*AuditRecord)(nil), "lnrpc.AuditRecord")
+	proto.RegisterType((*AuditLogResponse)(nil), "lnrpc.AuditLogResponse")
+	proto.RegisterType((*FundingBundleRequest)(nil), "lnrpc.FundingBundleRequest")
+	proto.RegisterType((*FundingBundleResponse)(nil), "lnrpc.FundingBundleResponse")
 	proto.RegisterEnum("lnrpc.ChannelStatus", ChannelStatus_name, ChannelStatus_value)
 	proto.RegisterEnum("lnrpc.NewAddressRequest_AddressType", NewAddressRequest_AddressType_name, NewAddressRequest_AddressType_value)
 }
@@ -914,6 +1505,21 @@ type LightningClient interface {
 	PendingChannels(ctx context.Context, in *PendingChannelRequest, opts ...grpc.CallOption) (*PendingChannelResponse, error)
 	SendPayment(ctx context.Context, opts ...grpc.CallOption) (Lightning_SendPaymentClient, error)
 	ShowRoutingTable(ctx context.Context, in *ShowRoutingTableRequest, opts ...grpc.CallOption) (*ShowRoutingTableResponse, error)
+	ListHeldHTLCs(ctx context.Context, in *ListHeldHTLCsRequest, opts ...grpc.CallOption) (*ListHeldHTLCsResponse, error)
+	ResolveHTLC(ctx context.Context, in *ResolveHTLCRequest, opts ...grpc.CallOption) (*ResolveHTLCResponse, error)
+	ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error)
+	EstimateTx(ctx context.Context, in *EstimateTxRequest, opts ...grpc.CallOption) (*EstimateTxResponse, error)
+	SendKeysend(ctx context.Context, in *KeysendRequest, opts ...grpc.CallOption) (*KeysendResponse, error)
+	SendMultiPartPayment(ctx context.Context, in *MultiPartPaymentRequest, opts ...grpc.CallOption) (*MultiPartPaymentResponse, error)
+	Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalanceResponse, error)
+	RecoverChannels(ctx context.Context, in *RecoverChannelsRequest, opts ...grpc.CallOption) (*RecoverChannelsResponse, error)
+	PayBTCInvoice(ctx context.Context, in *PayBTCInvoiceRequest, opts ...grpc.CallOption) (*PayBTCInvoiceResponse, error)
+	ProbeRoute(ctx context.Context, in *ProbeRouteRequest, opts ...grpc.CallOption) (*ProbeRouteResponse, error)
+	AbortFunding(ctx context.Context, in *AbortFundingRequest, opts ...grpc.CallOption) (*AbortFundingResponse, error)
+	ClosedChannels(ctx context.Context, in *ClosedChannelsRequest, opts ...grpc.CallOption) (*ClosedChannelsResponse, error)
+	LiquidityReport(ctx context.Context, in *LiquidityReportRequest, opts ...grpc.CallOption) (*LiquidityReportResponse, error)
+	QueryAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error)
+	ExportFundingBundle(ctx context.Context, in *FundingBundleRequest, opts ...grpc.CallOption) (*FundingBundleResponse, error)
 }
 
 type lightningClient struct {
@@ -1109,6 +1715,141 @@ func (c *lightningClient) ShowRoutingTable(ctx context.Context, in *ShowRoutingT
 	return out, nil
 }
 
+func (c *lightningClient) ListHeldHTLCs(ctx context.Context, in *ListHeldHTLCsRequest, opts ...grpc.CallOption) (*ListHeldHTLCsResponse, error) {
+	out := new(ListHeldHTLCsResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ListHeldHTLCs", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ResolveHTLC(ctx context.Context, in *ResolveHTLCRequest, opts ...grpc.CallOption) (*ResolveHTLCResponse, error) {
+	out := new(ResolveHTLCResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ResolveHTLC", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ForwardingHistory(ctx context.Context, in *ForwardingHistoryRequest, opts ...grpc.CallOption) (*ForwardingHistoryResponse, error) {
+	out := new(ForwardingHistoryResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ForwardingHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) EstimateTx(ctx context.Context, in *EstimateTxRequest, opts ...grpc.CallOption) (*EstimateTxResponse, error) {
+	out := new(EstimateTxResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/EstimateTx", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) SendKeysend(ctx context.Context, in *KeysendRequest, opts ...grpc.CallOption) (*KeysendResponse, error) {
+	out := new(KeysendResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/SendKeysend", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) SendMultiPartPayment(ctx context.Context, in *MultiPartPaymentRequest, opts ...grpc.CallOption) (*MultiPartPaymentResponse, error) {
+	out := new(MultiPartPaymentResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/SendMultiPartPayment", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) Rebalance(ctx context.Context, in *RebalanceRequest, opts ...grpc.CallOption) (*RebalanceResponse, error) {
+	out := new(RebalanceResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/Rebalance", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) RecoverChannels(ctx context.Context, in *RecoverChannelsRequest, opts ...grpc.CallOption) (*RecoverChannelsResponse, error) {
+	out := new(RecoverChannelsResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/RecoverChannels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) PayBTCInvoice(ctx context.Context, in *PayBTCInvoiceRequest, opts ...grpc.CallOption) (*PayBTCInvoiceResponse, error) {
+	out := new(PayBTCInvoiceResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/PayBTCInvoice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ProbeRoute(ctx context.Context, in *ProbeRouteRequest, opts ...grpc.CallOption) (*ProbeRouteResponse, error) {
+	out := new(ProbeRouteResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ProbeRoute", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) AbortFunding(ctx context.Context, in *AbortFundingRequest, opts ...grpc.CallOption) (*AbortFundingResponse, error) {
+	out := new(AbortFundingResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/AbortFunding", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ClosedChannels(ctx context.Context, in *ClosedChannelsRequest, opts ...grpc.CallOption) (*ClosedChannelsResponse, error) {
+	out := new(ClosedChannelsResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ClosedChannels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) LiquidityReport(ctx context.Context, in *LiquidityReportRequest, opts ...grpc.CallOption) (*LiquidityReportResponse, error) {
+	out := new(LiquidityReportResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/LiquidityReport", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) QueryAuditLog(ctx context.Context, in *AuditLogRequest, opts ...grpc.CallOption) (*AuditLogResponse, error) {
+	out := new(AuditLogResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/QueryAuditLog", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lightningClient) ExportFundingBundle(ctx context.Context, in *FundingBundleRequest, opts ...grpc.CallOption) (*FundingBundleResponse, error) {
+	out := new(FundingBundleResponse)
+	err := grpc.Invoke(ctx, "/lnrpc.Lightning/ExportFundingBundle", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Lightning service
 
 type LightningServer interface {
@@ -1125,6 +1866,21 @@ type LightningServer interface {
 	PendingChannels(context.Context, *PendingChannelRequest) (*PendingChannelResponse, error)
 	SendPayment(Lightning_SendPaymentServer) error
 	ShowRoutingTable(context.Context, *ShowRoutingTableRequest) (*ShowRoutingTableResponse, error)
+	ListHeldHTLCs(context.Context, *ListHeldHTLCsRequest) (*ListHeldHTLCsResponse, error)
+	ResolveHTLC(context.Context, *ResolveHTLCRequest) (*ResolveHTLCResponse, error)
+	ForwardingHistory(context.Context, *ForwardingHistoryRequest) (*ForwardingHistoryResponse, error)
+	EstimateTx(context.Context, *EstimateTxRequest) (*EstimateTxResponse, error)
+	SendKeysend(context.Context, *KeysendRequest) (*KeysendResponse, error)
+	SendMultiPartPayment(context.Context, *MultiPartPaymentRequest) (*MultiPartPaymentResponse, error)
+	Rebalance(context.Context, *RebalanceRequest) (*RebalanceResponse, error)
+	RecoverChannels(context.Context, *RecoverChannelsRequest) (*RecoverChannelsResponse, error)
+	PayBTCInvoice(context.Context, *PayBTCInvoiceRequest) (*PayBTCInvoiceResponse, error)
+	ProbeRoute(context.Context, *ProbeRouteRequest) (*ProbeRouteResponse, error)
+	AbortFunding(context.Context, *AbortFundingRequest) (*AbortFundingResponse, error)
+	ClosedChannels(context.Context, *ClosedChannelsRequest) (*ClosedChannelsResponse, error)
+	LiquidityReport(context.Context, *LiquidityReportRequest) (*LiquidityReportResponse, error)
+	QueryAuditLog(context.Context, *AuditLogRequest) (*AuditLogResponse, error)
+	ExportFundingBundle(context.Context, *FundingBundleRequest) (*FundingBundleResponse, error)
 }
 
 func RegisterLightningServer(s *grpc.Server, srv LightningServer) {
@@ -1379,6 +2135,276 @@ func _Lightning_ShowRoutingTable_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Lightning_ListHeldHTLCs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListHeldHTLCsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ListHeldHTLCs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ListHeldHTLCs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ListHeldHTLCs(ctx, req.(*ListHeldHTLCsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ResolveHTLC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveHTLCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ResolveHTLC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ResolveHTLC",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ResolveHTLC(ctx, req.(*ResolveHTLCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ForwardingHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForwardingHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ForwardingHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ForwardingHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ForwardingHistory(ctx, req.(*ForwardingHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_EstimateTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).EstimateTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/EstimateTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).EstimateTx(ctx, req.(*EstimateTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_SendKeysend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(KeysendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SendKeysend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SendKeysend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SendKeysend(ctx, req.(*KeysendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_SendMultiPartPayment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiPartPaymentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).SendMultiPartPayment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/SendMultiPartPayment",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).SendMultiPartPayment(ctx, req.(*MultiPartPaymentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_Rebalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).Rebalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/Rebalance",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).Rebalance(ctx, req.(*RebalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_RecoverChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecoverChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).RecoverChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/RecoverChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).RecoverChannels(ctx, req.(*RecoverChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_PayBTCInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PayBTCInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).PayBTCInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/PayBTCInvoice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).PayBTCInvoice(ctx, req.(*PayBTCInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ProbeRoute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProbeRouteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ProbeRoute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ProbeRoute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ProbeRoute(ctx, req.(*ProbeRouteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_AbortFunding_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AbortFundingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).AbortFunding(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/AbortFunding",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).AbortFunding(ctx, req.(*AbortFundingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ClosedChannels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClosedChannelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ClosedChannels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ClosedChannels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ClosedChannels(ctx, req.(*ClosedChannelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_LiquidityReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LiquidityReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).LiquidityReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/LiquidityReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).LiquidityReport(ctx, req.(*LiquidityReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).QueryAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/QueryAuditLog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).QueryAuditLog(ctx, req.(*AuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Lightning_ExportFundingBundle_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FundingBundleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LightningServer).ExportFundingBundle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/lnrpc.Lightning/ExportFundingBundle",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LightningServer).ExportFundingBundle(ctx, req.(*FundingBundleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Lightning_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "lnrpc.Lightning",
 	HandlerType: (*LightningServer)(nil),
@@ -1423,6 +2449,66 @@ var _Lightning_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ShowRoutingTable",
 			Handler:    _Lightning_ShowRoutingTable_Handler,
 		},
+		{
+			MethodName: "ListHeldHTLCs",
+			Handler:    _Lightning_ListHeldHTLCs_Handler,
+		},
+		{
+			MethodName: "ResolveHTLC",
+			Handler:    _Lightning_ResolveHTLC_Handler,
+		},
+		{
+			MethodName: "ForwardingHistory",
+			Handler:    _Lightning_ForwardingHistory_Handler,
+		},
+		{
+			MethodName: "EstimateTx",
+			Handler:    _Lightning_EstimateTx_Handler,
+		},
+		{
+			MethodName: "SendKeysend",
+			Handler:    _Lightning_SendKeysend_Handler,
+		},
+		{
+			MethodName: "SendMultiPartPayment",
+			Handler:    _Lightning_SendMultiPartPayment_Handler,
+		},
+		{
+			MethodName: "Rebalance",
+			Handler:    _Lightning_Rebalance_Handler,
+		},
+		{
+			MethodName: "RecoverChannels",
+			Handler:    _Lightning_RecoverChannels_Handler,
+		},
+		{
+			MethodName: "PayBTCInvoice",
+			Handler:    _Lightning_PayBTCInvoice_Handler,
+		},
+		{
+			MethodName: "ProbeRoute",
+			Handler:    _Lightning_ProbeRoute_Handler,
+		},
+		{
+			MethodName: "AbortFunding",
+			Handler:    _Lightning_AbortFunding_Handler,
+		},
+		{
+			MethodName: "ClosedChannels",
+			Handler:    _Lightning_ClosedChannels_Handler,
+		},
+		{
+			MethodName: "LiquidityReport",
+			Handler:    _Lightning_LiquidityReport_Handler,
+		},
+		{
+			MethodName: "QueryAuditLog",
+			Handler:    _Lightning_QueryAuditLog_Handler,
+		},
+		{
+			MethodName: "ExportFundingBundle",
+			Handler:    _Lightning_ExportFundingBundle_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1448,122 +2534,229 @@ var _Lightning_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("rpc.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1863 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x58, 0x5f, 0x73, 0xd3, 0xca,
-	0x15, 0xbf, 0xfe, 0x17, 0xdb, 0x47, 0x8e, 0xe3, 0x6c, 0xfe, 0x39, 0xbe, 0xdc, 0x4b, 0x51, 0x69,
-	0x49, 0x0b, 0x93, 0x01, 0x33, 0xd3, 0x06, 0x98, 0x81, 0x49, 0x42, 0x20, 0x29, 0x26, 0x49, 0xe5,
-	0x30, 0x4c, 0x9f, 0x54, 0x59, 0xde, 0x60, 0x0d, 0xb2, 0xe4, 0x5a, 0x6b, 0x82, 0x79, 0xee, 0xb4,
-	0x5f, 0xa0, 0x8f, 0x1d, 0xa6, 0xcf, 0xfd, 0x06, 0xfd, 0x18, 0x7d, 0xea, 0x5b, 0x3f, 0x4b, 0xcf,
-	0xfe, 0x93, 0x25, 0xd9, 0x01, 0xa6, 0x73, 0x9f, 0xac, 0xfd, 0xed, 0xd9, 0xb3, 0xe7, 0xff, 0x39,
-	0x6b, 0xa8, 0x8e, 0x47, 0xee, 0xee, 0x68, 0x1c, 0xb2, 0x90, 0x94, 0xfc, 0x00, 0x17, 0x66, 0x04,
-	0x46, 0x97, 0x06, 0x7d, 0x8b, 0xfe, 0x69, 0x42, 0x23, 0x46, 0x08, 0x14, 0xfb, 0xf8, 0xdb, 0xcc,
-	0xfd, 0x2c, 0xb7, 0x53, 0xb3, 0xc4, 0x37, 0x69, 0x40, 0xc1, 0x19, 0xb2, 0x66, 0x1e, 0xa1, 0x82,
-	0xc5, 0x3f, 0xc9, 0x2d, 0xa8, 0x8d, 0x9c, 0xe9, 0x90, 0x06, 0xcc, 0x1e, 0x38, 0xd1, 0xa0, 0x59,
-	0x10, 0xd4, 0x86, 0xc2, 0x8e, 0x11, 0x22, 0xdf, 0x43, 0xf5, 0xd2, 0x89, 0x98, 0x1d, 0x21, 0xf3,
-	0x66, 0x11, 0xf7, 0x2b, 0x56, 0x85, 0x03, 0xfc, 0x32, 0xb3, 0x0e, 0x35, 0x79, 0x69, 0x34, 0x0a,
-	0x83, 0x88, 0x9a, 0x17, 0x50, 0x3b, 0x1c, 0x38, 0x41, 0x40, 0xfd, 0xf3, 0xd0, 0x0b, 0x04, 0xff,
-	0xcb, 0x49, 0xd0, 0xf7, 0x82, 0x77, 0x36, 0xfb, 0xe8, 0xf5, 0x95, 0x34, 0x86, 0xc2, 0x2e, 0x10,
-	0xe2, 0x24, 0xe1, 0x84, 0x8d, 0x26, 0xcc, 0xf6, 0x82, 0x3e, 0xfd, 0x28, 0xa4, 0x5b, 0xb6, 0x0c,
-	0x89, 0x9d, 0x70, 0xc8, 0x7c, 0x01, 0x8d, 0x8e, 0xf7, 0x6e, 0xc0, 0x02, 0x3c, 0xb3, 0xdf, 0xef,
-	0x8f, 0x69, 0x14, 0x91, 0x1f, 0x01, 0x46, 0x93, 0xde, 0x2b, 0x3a, 0xe5, 0x42, 0x0a, 0xbe, 0x55,
-	0x2b, 0x81, 0x70, 0xfd, 0x07, 0x61, 0x24, 0x95, 0xad, 0x5a, 0xe2, 0xdb, 0xfc, 0x47, 0x0e, 0x56,
-	0xb8, 0xb8, 0xaf, 0x9d, 0x60, 0xaa, 0xed, 0xd4, 0x81, 0x1a, 0x67, 0x79, 0x11, 0xee, 0x0f, 0xc3,
-	0x49, 0xc0, 0xed, 0x55, 0xd8, 0x31, 0xda, 0x3b, 0xbb, 0xc2, 0xa8, 0xbb, 0x19, 0xea, 0xdd, 0x24,
-	0xe9, 0x51, 0xc0, 0xc6, 0x53, 0xab, 0xe6, 0x24, 0xa0, 0xd6, 0x33, 0x58, 0x9d, 0x23, 0xe1, 0x66,
-	0x7f, 0x4f, 0xa7, 0x4a, 0x46, 0xfe, 0x49, 0xd6, 0xa1, 0xf4, 0xc1, 0xf1, 0x27, 0x54, 0xb9, 0x42,
-	0x2e, 0x1e, 0xe7, 0xf7, 0x72, 0xe6, 0x2f, 0xa1, 0x31, 0xbb, 0x53, 0x1a, 0x95, 0xab, 0x12, 0x1b,
-	0x0f, 0x55, 0xe1, 0xdf, 0xe6, 0x53, 0x49, 0x77, 0x88, 0x56, 0x8e, 0x12, 0x2e, 0xe7, 0xc2, 0x68,
-	0x3a, 0xfe, 0x4d, 0x36, 0x61, 0xc9, 0x91, 0x8a, 0xc9, 0xab, 0xd4, 0xca, 0xbc, 0x03, 0xab, 0x89,
-	0xf3, 0x5f, 0xb8, 0xe8, 0x73, 0x0e, 0x56, 0x4f, 0xe9, 0x95, 0x32, 0xbb, 0xbe, 0x6a, 0x0f, 0x29,
-	0xa7, 0x23, 0x2a, 0x28, 0xeb, 0xed, 0xdb, 0xca, 0x5a, 0x73, 0x74, 0xbb, 0x6a, 0x79, 0x81, 0xb4,
-	0x96, 0x38, 0x61, 0x9e, 0x81, 0x91, 0x00, 0xc9, 0x16, 0xac, 0xbd, 0x3d, 0xb9, 0x38, 0x3d, 0xea,
-	0x76, 0xed, 0xf3, 0x37, 0x07, 0xaf, 0x8e, 0xfe, 0x60, 0x1f, 0xef, 0x77, 0x8f, 0x1b, 0xdf, 0xa1,
-	0xe0, 0x04, 0xd1, 0x8b, 0xa3, 0xe7, 0x29, 0x3c, 0x47, 0x56, 0xc0, 0x48, 0x02, 0x79, 0x73, 0x17,
-	0x09, 0x13, 0xf7, 0x2a, 0x55, 0x9a, 0x50, 0x76, 0x24, 0xa4, 0xb4, 0xd1, 0x4b, 0x73, 0x1f, 0xc8,
-	0x61, 0x88, 0x11, 0xea, 0xb2, 0x73, 0x4a, 0xc7, 0x5a, 0xa1, 0xbb, 0x09, 0xdb, 0x19, 0xed, 0x2d,
-	0xa5, 0x50, 0x36, 0xea, 0xa4, 0x51, 0xf1, 0xca, 0xb5, 0x14, 0x0b, 0x75, 0xe7, 0x16, 0x94, 0x47,
-	0xb8, 0xb6, 0x95, 0x05, 0x4b, 0xd6, 0x12, 0x5f, 0x9e, 0xf4, 0xcd, 0x3f, 0x42, 0xf1, 0xf8, 0xa2,
-	0x73, 0x48, 0xea, 0x90, 0x57, 0x7b, 0x05, 0x0b, 0xbf, 0xae, 0x73, 0x0e, 0x4f, 0x39, 0x9e, 0x8d,
-	0xb6, 0x1f, 0xba, 0xef, 0x55, 0x4a, 0x56, 0x38, 0xd0, 0xc1, 0x35, 0x59, 0x83, 0x12, 0x0b, 0xed,
-	0x49, 0xa4, 0x72, 0xb1, 0xc8, 0xc2, 0x37, 0x91, 0xf9, 0xaf, 0x3c, 0x2c, 0xef, 0xbb, 0xcc, 0xfb,
-	0x40, 0x55, 0xfa, 0x71, 0x1e, 0x63, 0x3a, 0x0c, 0x19, 0xb5, 0x63, 0x87, 0x56, 0x24, 0x70, 0xd2,
-	0x27, 0x3f, 0x87, 0x65, 0x57, 0xd2, 0xd9, 0x23, 0x9e, 0xa7, 0x2a, 0x4b, 0x6a, 0x6e, 0x32, 0x77,
-	0x5b, 0x50, 0x71, 0x9d, 0x91, 0xe3, 0x7a, 0x6c, 0x2a, 0x84, 0x28, 0x58, 0xf1, 0x9a, 0x33, 0x40,
-	0xe1, 0x1c, 0xdf, 0xee, 0x39, 0xbe, 0x13, 0xb8, 0x54, 0x08, 0x53, 0xb0, 0x6a, 0x02, 0x3c, 0x90,
-	0x18, 0xf9, 0x05, 0xd4, 0x95, 0x08, 0x9a, 0xaa, 0x24, 0xa8, 0x96, 0x25, 0xaa, 0xc9, 0xee, 0xc2,
-	0xea, 0x04, 0xcd, 0xc7, 0x98, 0x4f, 0xfb, 0x76, 0x8f, 0x4a, 0xca, 0x25, 0x41, 0xd9, 0x88, 0x37,
-	0x0e, 0x24, 0x4e, 0xee, 0xc3, 0xf2, 0x88, 0xca, 0x82, 0x32, 0x60, 0xbe, 0x1b, 0x35, 0xcb, 0x22,
-	0x5f, 0x0d, 0xe5, 0x30, 0x6e, 0x66, 0xab, 0xa6, 0x28, 0x8e, 0x39, 0x01, 0xb9, 0x09, 0x46, 0x30,
-	0x19, 0xda, 0x93, 0x51, 0xdf, 0x61, 0x34, 0x6a, 0x56, 0x90, 0x71, 0xd1, 0x02, 0x84, 0xde, 0x48,
-	0xc4, 0xfc, 0x7b, 0x1e, 0x8a, 0xdc, 0x8f, 0xbc, 0x12, 0xf9, 0xda, 0xe1, 0x33, 0xab, 0x19, 0x31,
-	0x86, 0x86, 0x4b, 0xb8, 0x38, 0x9f, 0x74, 0x71, 0x32, 0xde, 0x0a, 0xa9, 0x78, 0x23, 0x3f, 0x00,
-	0xf4, 0xa6, 0x78, 0x0f, 0x2f, 0xa0, 0x4c, 0xd8, 0xa9, 0x68, 0x55, 0x05, 0x82, 0x09, 0xc8, 0x66,
-	0xdb, 0x63, 0xea, 0x7e, 0x10, 0x06, 0xd2, 0xdb, 0x16, 0x02, 0x64, 0x1b, 0x2a, 0x91, 0xc3, 0xe4,
-	0x59, 0x69, 0x93, 0x32, 0xae, 0xc5, 0x49, 0xb5, 0x25, 0xce, 0x95, 0xe3, 0x2d, 0x71, 0x0a, 0xa5,
-	0xf1, 0x82, 0x1e, 0xc6, 0x52, 0x5f, 0xe8, 0x5b, 0xb1, 0xf4, 0x12, 0xed, 0x57, 0x51, 0x4e, 0x8e,
-	0x9a, 0x55, 0x61, 0xba, 0x75, 0x65, 0xba, 0x54, 0xf8, 0x58, 0x31, 0x95, 0x49, 0x78, 0xf1, 0x8d,
-	0x44, 0xa4, 0xeb, 0xb4, 0x36, 0x7f, 0x03, 0xab, 0x09, 0x4c, 0x85, 0xff, 0x2d, 0x28, 0x71, 0x63,
-	0x44, 0xaa, 0x84, 0x6a, 0x97, 0x88, 0x14, 0x91, 0x3b, 0x66, 0x03, 0xea, 0x2f, 0x29, 0x16, 0xf5,
-	0xcb, 0x50, 0x73, 0xfa, 0x2f, 0x96, 0xe4, 0x18, 0x8a, 0x19, 0x7d, 0xd5, 0x0f, 0xbf, 0x82, 0x86,
-	0xd7, 0x47, 0x23, 0x60, 0x2c, 0xda, 0xda, 0xee, 0x32, 0x86, 0x57, 0x34, 0xae, 0x1b, 0xc5, 0x7d,
-	0x58, 0xe7, 0xfe, 0xd7, 0x51, 0x13, 0x6b, 0x5f, 0x10, 0x7d, 0x86, 0xe0, 0xde, 0xb9, 0xdc, 0x52,
-	0xaa, 0x47, 0x04, 0xd3, 0x9b, 0x9f, 0x70, 0x84, 0x41, 0x66, 0x07, 0x8a, 0xe2, 0xc0, 0x2a, 0x6e,
-	0xa5, 0x4c, 0x15, 0xf1, 0x54, 0x93, 0x37, 0x70, 0xe5, 0x4b, 0x82, 0xaa, 0x22, 0xd8, 0x72, 0x95,
-	0x3f, 0x89, 0x72, 0x73, 0xe9, 0x8d, 0x87, 0x0e, 0xf3, 0xc2, 0x40, 0x06, 0x1d, 0x3f, 0xd2, 0xe3,
-	0xd9, 0x6d, 0x47, 0x03, 0x47, 0x35, 0xc5, 0x8a, 0x00, 0xba, 0x03, 0x87, 0xeb, 0x2f, 0x37, 0x07,
-	0x94, 0xab, 0xac, 0x22, 0xcd, 0x10, 0xd8, 0xb1, 0x80, 0xc8, 0x6d, 0xa8, 0xf3, 0x2b, 0x5d, 0xe4,
-	0x1c, 0xd9, 0x3e, 0xbd, 0x64, 0x4a, 0x9d, 0x1a, 0xa2, 0xfc, 0xba, 0xa8, 0x83, 0x98, 0xf9, 0x1a,
-	0x56, 0x95, 0x90, 0x67, 0xa8, 0xbe, 0xba, 0x7a, 0x2f, 0x9b, 0xfb, 0xb2, 0xe4, 0xad, 0x29, 0x77,
-	0x25, 0xdb, 0x77, 0xba, 0x20, 0x98, 0xbf, 0x47, 0x55, 0xe4, 0xfa, 0xd0, 0x0f, 0x23, 0xaa, 0xf8,
-	0xa1, 0xb4, 0x2e, 0x2e, 0xb3, 0x2d, 0x5e, 0x61, 0xa2, 0xc5, 0x63, 0x38, 0x46, 0x13, 0xd7, 0xd5,
-	0x4e, 0xc2, 0x70, 0x54, 0x4b, 0xf3, 0xcf, 0x39, 0x2c, 0xa5, 0x9c, 0x99, 0x8e, 0xbb, 0xb8, 0xbf,
-	0xfc, 0x9f, 0x42, 0xf2, 0x7c, 0x62, 0xde, 0x90, 0xda, 0xbe, 0x37, 0xf4, 0x74, 0x5d, 0xad, 0x72,
-	0xa4, 0xc3, 0x01, 0xde, 0x79, 0x2f, 0xc3, 0x31, 0x16, 0x98, 0x82, 0x10, 0x44, 0x2e, 0xcc, 0xff,
-	0x60, 0x93, 0x13, 0x62, 0x74, 0x99, 0xc3, 0x26, 0x91, 0xd2, 0xec, 0x09, 0x0a, 0xc1, 0x41, 0x1d,
-	0x3b, 0x4a, 0x88, 0xf5, 0x38, 0xb0, 0x05, 0x2a, 0x89, 0x8f, 0xbf, 0xb3, 0x84, 0x19, 0xa8, 0x42,
-	0xc9, 0x33, 0x34, 0x4b, 0xc2, 0xef, 0x42, 0x12, 0xa3, 0xbd, 0xad, 0x15, 0x98, 0x0b, 0x09, 0xc1,
-	0x20, 0x81, 0x92, 0xc7, 0x00, 0x5c, 0x31, 0x5b, 0x70, 0x15, 0xe2, 0x26, 0x8e, 0xcf, 0xb9, 0x01,
-	0x8f, 0x57, 0x39, 0xb9, 0x80, 0x0e, 0x2a, 0xb0, 0x24, 0xeb, 0x9d, 0x89, 0x85, 0x3a, 0x25, 0x67,
-	0xaa, 0xc7, 0xd7, 0x54, 0x8f, 0xff, 0x6b, 0x1e, 0x08, 0x8f, 0x90, 0x8c, 0x13, 0x30, 0xc8, 0x98,
-	0x33, 0x7e, 0x47, 0x99, 0x9d, 0x6e, 0x6b, 0x35, 0x89, 0x9e, 0xcb, 0xca, 0x87, 0xf5, 0x55, 0x51,
-	0x05, 0x61, 0x5f, 0x4e, 0x34, 0x35, 0x0b, 0x24, 0x74, 0x8a, 0x08, 0x4f, 0x40, 0xd9, 0x2b, 0xf4,
-	0x24, 0xa8, 0x7a, 0x9e, 0xec, 0x29, 0x44, 0xec, 0xbd, 0x90, 0x5b, 0x72, 0x6a, 0x22, 0x6d, 0xd8,
-	0x50, 0x8d, 0x23, 0x73, 0x44, 0x76, 0x99, 0x35, 0xb9, 0x99, 0x3e, 0x73, 0x07, 0x56, 0xdc, 0x70,
-	0x38, 0xf4, 0xa2, 0x08, 0x8d, 0x67, 0x47, 0xde, 0x27, 0xdd, 0x6d, 0xea, 0x33, 0xb8, 0x8b, 0xa8,
-	0xce, 0x56, 0x91, 0x3a, 0xa2, 0xa4, 0xca, 0x6c, 0x15, 0x59, 0x63, 0xfe, 0x3b, 0x07, 0x0d, 0x6e,
-	0x89, 0x54, 0x1c, 0x3c, 0x02, 0x11, 0x62, 0xdf, 0x18, 0x06, 0x06, 0xa7, 0xfd, 0xc9, 0xa2, 0xe0,
-	0xb7, 0x20, 0xdc, 0x6a, 0x87, 0x78, 0xb9, 0x0a, 0x82, 0x66, 0x3a, 0x08, 0x66, 0xa9, 0x8d, 0x87,
-	0x45, 0xd9, 0xe6, 0x48, 0x22, 0x04, 0x8e, 0x60, 0x23, 0x5d, 0xe1, 0xb4, 0x7f, 0xef, 0xc1, 0x52,
-	0x24, 0xf4, 0x54, 0x63, 0xdc, 0x7a, 0x9a, 0xb1, 0xb4, 0x81, 0xa5, 0x68, 0xcc, 0xcf, 0x05, 0xd8,
-	0xcc, 0xf2, 0x51, 0x05, 0xfb, 0x2d, 0x34, 0xe6, 0xca, 0xab, 0x6c, 0x02, 0xf7, 0xd2, 0x46, 0xca,
-	0x1c, 0xcc, 0xc2, 0x2b, 0xa3, 0x74, 0x25, 0x6e, 0xfd, 0x33, 0x0f, 0xf5, 0x34, 0xcd, 0xb5, 0x43,
-	0xd6, 0x5c, 0xd7, 0xc8, 0xcf, 0x77, 0x8d, 0xb9, 0xb1, 0xa7, 0xf0, 0x95, 0xb1, 0xa7, 0xf8, 0xb5,
-	0xb1, 0xa7, 0xf4, 0x4d, 0x63, 0xcf, 0xd2, 0xa2, 0xb1, 0x27, 0x5b, 0x37, 0xcb, 0x52, 0xde, 0x64,
-	0xdd, 0x9c, 0x39, 0xa8, 0xf2, 0x0d, 0x0e, 0x7a, 0x04, 0xeb, 0x6f, 0x1d, 0xdf, 0xa7, 0x4c, 0xdd,
-	0xa0, 0xdd, 0x8c, 0x17, 0x5d, 0x79, 0x2c, 0xc0, 0x72, 0x6b, 0x87, 0x81, 0x2f, 0xdf, 0x21, 0x15,
-	0xcb, 0x50, 0xd8, 0x19, 0x42, 0xe6, 0x03, 0xd8, 0xc8, 0x1c, 0x9d, 0x8d, 0xd1, 0x5a, 0x09, 0x7e,
-	0x2c, 0x67, 0xe9, 0xa5, 0xb9, 0x05, 0x1b, 0x4a, 0x8c, 0xf4, 0x75, 0x66, 0x1b, 0x36, 0xb3, 0x1b,
-	0x8b, 0x99, 0x15, 0x66, 0xcc, 0xfe, 0x82, 0x69, 0x67, 0xe1, 0x8b, 0x8f, 0x2b, 0xee, 0xf4, 0x7c,
-	0xac, 0xd5, 0xc1, 0x7b, 0xfe, 0x6c, 0xf2, 0xfa, 0x0f, 0xf4, 0xb3, 0x09, 0x3f, 0x25, 0xd2, 0x56,
-	0x9e, 0xe5, 0x9f, 0xdc, 0x59, 0xfc, 0xa1, 0x98, 0x70, 0x66, 0xbc, 0xfe, 0xa2, 0x23, 0x71, 0xf2,
-	0xbe, 0x92, 0xcd, 0xb5, 0x24, 0xd4, 0x52, 0x2b, 0x73, 0x1b, 0xb6, 0xba, 0x83, 0xf0, 0x2a, 0x29,
-	0x8b, 0xd6, 0xeb, 0x0c, 0x9a, 0xf3, 0x5b, 0x4a, 0xb3, 0x87, 0x89, 0xa9, 0x4a, 0x06, 0xbe, 0x7e,
-	0x41, 0x64, 0xb5, 0x9a, 0x0d, 0x56, 0xbf, 0x6e, 0xc3, 0x72, 0xca, 0x91, 0xa4, 0x0c, 0x85, 0xfd,
-	0x4e, 0x07, 0xdf, 0x3e, 0x06, 0x94, 0xcf, 0xce, 0x8f, 0x4e, 0x4f, 0x4e, 0x5f, 0xe2, 0x83, 0x07,
-	0x17, 0x87, 0x9d, 0xb3, 0x2e, 0x5f, 0xe4, 0xdb, 0x7f, 0x2b, 0x43, 0x35, 0x7e, 0x94, 0x90, 0xdf,
-	0xc1, 0x72, 0xca, 0x6d, 0xe4, 0x7b, 0x75, 0xeb, 0xa2, 0x38, 0x68, 0xdd, 0x58, 0xbc, 0xa9, 0x54,
-	0x78, 0x0d, 0xf5, 0xb4, 0xdb, 0xc8, 0x8d, 0x74, 0xb4, 0x65, 0xb8, 0xfd, 0x70, 0xcd, 0xae, 0x62,
-	0xf7, 0x04, 0x2a, 0xfa, 0x1d, 0x4b, 0x36, 0x17, 0x3f, 0xa6, 0x5b, 0x5b, 0x73, 0xb8, 0x3a, 0xfc,
-	0x14, 0xaa, 0xf1, 0xe3, 0x94, 0x24, 0xa9, 0x92, 0xcf, 0xdd, 0x56, 0x73, 0x7e, 0x43, 0x9d, 0xdf,
-	0x07, 0x98, 0x3d, 0x09, 0x49, 0xf3, 0xba, 0xd7, 0x69, 0x6b, 0x7b, 0xc1, 0x8e, 0x62, 0xf1, 0x1c,
-	0x8c, 0xc4, 0x13, 0x8f, 0x24, 0x2a, 0x76, 0xe6, 0xe5, 0xd8, 0x6a, 0x2d, 0xda, 0x9a, 0x29, 0x12,
-	0xcf, 0xc9, 0x64, 0xf6, 0xa8, 0x4c, 0x4f, 0xd3, 0xb1, 0x22, 0xf3, 0x23, 0xf5, 0x1e, 0x94, 0xd5,
-	0x70, 0x4c, 0x36, 0x14, 0x51, 0x7a, 0x7e, 0x6e, 0x6d, 0x66, 0x61, 0x75, 0xf2, 0x10, 0x8c, 0x44,
-	0x47, 0x8f, 0xe5, 0x9f, 0xef, 0xf2, 0xb1, 0x17, 0xb2, 0x6d, 0xef, 0x7e, 0x8e, 0xbc, 0x80, 0x5a,
-	0x72, 0x38, 0x23, 0xb1, 0xaa, 0xf3, 0x13, 0x5b, 0xac, 0xc4, 0xdc, 0x18, 0x85, 0x7c, 0x4e, 0x61,
-	0x25, 0x3b, 0x63, 0xdf, 0xb8, 0xa6, 0x31, 0xa4, 0x83, 0xeb, 0x9a, 0x7e, 0xf3, 0x58, 0xfe, 0xd5,
-	0x75, 0x2e, 0xff, 0xa5, 0x22, 0x24, 0x11, 0x08, 0x9a, 0xc3, 0x5a, 0x0a, 0x93, 0xe7, 0x76, 0x72,
-	0x28, 0x4b, 0x17, 0x1a, 0xd9, 0x34, 0x26, 0x3f, 0x6a, 0xe2, 0xc5, 0xa9, 0xdf, 0xba, 0x79, 0xed,
-	0xbe, 0x64, 0xdc, 0x5b, 0x12, 0xff, 0xc4, 0x3d, 0xfc, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc2,
-	0xc3, 0x5e, 0x39, 0x96, 0x13, 0x00, 0x00,
+	// 3581 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xbc, 0x5a, 0xcb, 0x6f, 0x23, 0xc7,
+	0xd1, 0x5f, 0xbe, 0x44, 0xb2, 0x48, 0x8a, 0x54, 0x4b, 0xa2, 0xa8, 0xf1, 0x3e, 0x67, 0xfd, 0x58,
+	0xdb, 0xfb, 0x09, 0x6b, 0x19, 0x5f, 0xb2, 0xb6, 0x83, 0xb5, 0xb5, 0xb2, 0xd6, 0x92, 0x57, 0x2b,
+	0xc9, 0x23, 0x39, 0x46, 0x80, 0x00, 0xe3, 0x11, 0xa7, 0x29, 0x0d, 0x76, 0x38, 0x33, 0x9e, 0xee,
+	0x91, 0xc4, 0x05, 0x82, 0xdc, 0x92, 0x6b, 0x0e, 0xc9, 0x2d, 0x48, 0x72, 0xcd, 0x21, 0xc8, 0x21,
+	0xa7, 0xfc, 0x0d, 0x41, 0x0e, 0x39, 0xf9, 0x10, 0x20, 0xa7, 0x9c, 0x72, 0xcd, 0x21, 0xd7, 0xa0,
+	0x5f, 0xf3, 0x24, 0xb5, 0x42, 0xec, 0xe4, 0xc6, 0xfe, 0x55, 0x75, 0x4f, 0xd7, 0xa3, 0xab, 0xab,
+	0xaa, 0x09, 0xcd, 0x30, 0x18, 0xae, 0x05, 0xa1, 0x4f, 0x7d, 0x54, 0x73, 0xbd, 0x30, 0x18, 0xea,
+	0x04, 0x5a, 0x87, 0xd8, 0xb3, 0x0d, 0xfc, 0x55, 0x84, 0x09, 0x45, 0x08, 0xaa, 0x36, 0x26, 0x74,
+	0x50, 0xba, 0x5d, 0xba, 0xd7, 0x36, 0xf8, 0x6f, 0xd4, 0x83, 0x8a, 0x35, 0xa6, 0x83, 0xf2, 0xed,
+	0xd2, 0xbd, 0x8a, 0xc1, 0x7e, 0xa2, 0x3b, 0xd0, 0x0e, 0xac, 0xc9, 0x18, 0x7b, 0xd4, 0x3c, 0xb5,
+	0xc8, 0xe9, 0xa0, 0xc2, 0xb9, 0x5b, 0x12, 0xdb, 0xb6, 0xc8, 0x29, 0x7a, 0x05, 0x9a, 0x23, 0x8b,
+	0x50, 0x93, 0x60, 0xcf, 0x1e, 0x54, 0x6f, 0x97, 0xee, 0x35, 0x8c, 0x06, 0x03, 0xd8, 0xc7, 0xf4,
+	0x79, 0x68, 0x8b, 0x8f, 0x92, 0xc0, 0xf7, 0x08, 0xd6, 0x8f, 0xa0, 0xbd, 0x79, 0x6a, 0x79, 0x1e,
+	0x76, 0x0f, 0x7c, 0xc7, 0xe3, 0xeb, 0x8f, 0x22, 0xcf, 0x76, 0xbc, 0x13, 0x93, 0x5e, 0x38, 0xb6,
+	0xdc, 0x4d, 0x4b, 0x62, 0x47, 0x17, 0x8e, 0xcd, 0x58, 0xfc, 0x88, 0x06, 0x11, 0x35, 0x1d, 0xcf,
+	0xc6, 0x17, 0x7c, 0x77, 0x1d, 0xa3, 0x25, 0xb0, 0x1d, 0x06, 0xe9, 0x4f, 0xa0, 0xb7, 0xeb, 0x9c,
+	0x9c, 0x52, 0xcf, 0xf1, 0x4e, 0x36, 0x6c, 0x3b, 0xc4, 0x84, 0xa0, 0x9b, 0x00, 0x41, 0x74, 0xfc,
+	0x14, 0x4f, 0xd8, 0x26, 0xf9, 0xba, 0x4d, 0x23, 0x85, 0x30, 0xf9, 0x4f, 0x7d, 0x22, 0x84, 0x6d,
+	0x1a, 0xfc, 0xb7, 0xfe, 0x9b, 0x12, 0x74, 0xd9, 0x76, 0x9f, 0x59, 0xde, 0x44, 0xe9, 0x69, 0x17,
+	0xda, 0x6c, 0xc9, 0x23, 0x7f, 0x63, 0xec, 0x47, 0x1e, 0xd3, 0x57, 0xe5, 0x5e, 0x6b, 0xfd, 0xde,
+	0x1a, 0x57, 0xea, 0x5a, 0x8e, 0x7b, 0x2d, 0xcd, 0xba, 0xe5, 0xd1, 0x70, 0x62, 0xb4, 0xad, 0x14,
+	0xa4, 0x7d, 0x08, 0x0b, 0x05, 0x16, 0xa6, 0xf6, 0xe7, 0x78, 0x22, 0xf7, 0xc8, 0x7e, 0xa2, 0x25,
+	0xa8, 0x9d, 0x59, 0x6e, 0x84, 0xa5, 0x29, 0xc4, 0xe0, 0xfd, 0xf2, 0xc3, 0x92, 0xfe, 0x3a, 0xf4,
+	0x92, 0x6f, 0x0a, 0xa5, 0x32, 0x51, 0x62, 0xe5, 0x35, 0x0d, 0xfe, 0x5b, 0x7f, 0x24, 0xf8, 0x36,
+	0x7d, 0xc7, 0x23, 0x29, 0x93, 0xb3, 0xcd, 0x28, 0x3e, 0xf6, 0x1b, 0xf5, 0x61, 0xce, 0x12, 0x82,
+	0x89, 0x4f, 0xc9, 0x91, 0xfe, 0x06, 0x2c, 0xa4, 0xe6, 0x5f, 0xf2, 0xa1, 0x5f, 0x95, 0x60, 0x61,
+	0x0f, 0x9f, 0x4b, 0xb5, 0xab, 0x4f, 0x3d, 0x84, 0x2a, 0x9d, 0x04, 0x98, 0x73, 0xce, 0xaf, 0xbf,
+	0x2a, 0xb5, 0x55, 0xe0, 0x5b, 0x93, 0xc3, 0xa3, 0x49, 0x80, 0x0d, 0x3e, 0x43, 0xdf, 0x87, 0x56,
+	0x0a, 0x44, 0x2b, 0xb0, 0xf8, 0xc5, 0xce, 0xd1, 0xde, 0xd6, 0xe1, 0xa1, 0x79, 0xf0, 0xf9, 0xe3,
+	0xa7, 0x5b, 0x3f, 0x30, 0xb7, 0x37, 0x0e, 0xb7, 0x7b, 0xd7, 0x50, 0x1f, 0xd0, 0xde, 0xd6, 0xe1,
+	0xd1, 0xd6, 0xc7, 0x19, 0xbc, 0x84, 0xba, 0xd0, 0x4a, 0x03, 0x65, 0x7d, 0x0d, 0x50, 0xfa, 0xbb,
+	0x52, 0x94, 0x01, 0xd4, 0x2d, 0x01, 0x49, 0x69, 0xd4, 0x50, 0xdf, 0x00, 0xb4, 0xe9, 0x7b, 0x1e,
+	0x1e, 0xd2, 0x03, 0x8c, 0x43, 0x25, 0xd0, 0xdb, 0x29, 0xdd, 0xb5, 0xd6, 0x57, 0xa4, 0x40, 0x79,
+	0xaf, 0x13, 0x4a, 0xd5, 0xd7, 0x60, 0x31, 0xb3, 0x84, 0xfc, 0xe6, 0x0a, 0xd4, 0x03, 0x8c, 0x43,
+	0x53, 0x6a, 0xb0, 0x66, 0xcc, 0xb1, 0xe1, 0x8e, 0xad, 0x7f, 0x09, 0xd5, 0xed, 0xa3, 0xdd, 0x4d,
+	0x34, 0x0f, 0x65, 0x49, 0xab, 0x18, 0x65, 0xc7, 0x9e, 0x65, 0x1c, 0x76, 0xe4, 0xd8, 0x69, 0x34,
+	0x5d, 0x7f, 0xf8, 0x5c, 0x1e, 0xc9, 0x06, 0x03, 0x76, 0xfd, 0xe1, 0x73, 0xb4, 0x08, 0x35, 0xea,
+	0x9b, 0x11, 0x91, 0x67, 0xb1, 0x4a, 0xfd, 0xcf, 0x89, 0xfe, 0xc7, 0x32, 0x74, 0x36, 0x86, 0xd4,
+	0x39, 0xc3, 0xf2, 0xf8, 0xb1, 0x35, 0x42, 0x3c, 0xf6, 0x29, 0x36, 0x63, 0x83, 0x36, 0x04, 0xb0,
+	0x63, 0xa3, 0xbb, 0xd0, 0x19, 0x0a, 0x3e, 0x33, 0xf0, 0x1d, 0xf9, 0xfd, 0xa6, 0xd1, 0x1e, 0xa6,
+	0xcf, 0xae, 0x06, 0x8d, 0xa1, 0x15, 0x58, 0x43, 0x87, 0x4e, 0xf8, 0x26, 0x2a, 0x46, 0x3c, 0x66,
+	0x0b, 0xb8, 0xfe, 0xd0, 0x72, 0xcd, 0x63, 0xcb, 0xb5, 0xbc, 0x21, 0xe6, 0x9b, 0xa9, 0x18, 0x6d,
+	0x0e, 0x3e, 0x16, 0x18, 0x7a, 0x0d, 0xe6, 0xe5, 0x16, 0x14, 0x57, 0x8d, 0x73, 0x75, 0x04, 0xaa,
+	0xd8, 0xde, 0x86, 0x85, 0xc8, 0x23, 0x98, 0x52, 0x17, 0xdb, 0xe6, 0x31, 0x16, 0x9c, 0x73, 0x9c,
+	0xb3, 0x17, 0x13, 0x1e, 0x0b, 0x1c, 0x3d, 0x80, 0x4e, 0x80, 0x45, 0x40, 0x39, 0xa5, 0xee, 0x90,
+	0x0c, 0xea, 0xfc, 0xbc, 0xb6, 0xa4, 0xc1, 0x98, 0x9a, 0x8d, 0xb6, 0xe4, 0xd8, 0x66, 0x0c, 0xe8,
+	0x16, 0xb4, 0xbc, 0x68, 0x6c, 0x46, 0x81, 0x6d, 0x51, 0x4c, 0x06, 0x8d, 0xdb, 0xa5, 0x7b, 0x55,
+	0x03, 0xbc, 0x68, 0xfc, 0xb9, 0x40, 0xf4, 0x5f, 0x96, 0xa1, 0xca, 0xec, 0xc8, 0x22, 0x91, 0xab,
+	0x0c, 0x9e, 0x68, 0xad, 0x15, 0x63, 0x3b, 0x76, 0xda, 0xc4, 0xe5, 0xb4, 0x89, 0xd3, 0xfe, 0x56,
+	0xc9, 0xf8, 0x1b, 0xba, 0x01, 0x70, 0x3c, 0xa1, 0x98, 0xb0, 0x00, 0x4a, 0xb9, 0x9e, 0xaa, 0x46,
+	0x93, 0x23, 0x87, 0xd8, 0xa3, 0x09, 0x39, 0xc4, 0xc3, 0xb3, 0x41, 0x2d, 0x45, 0x36, 0xf0, 0xf0,
+	0x0c, 0xad, 0x42, 0x83, 0x58, 0x54, 0xcc, 0x15, 0x3a, 0xa9, 0x13, 0x8b, 0xf2, 0x99, 0x92, 0xc4,
+	0xe7, 0xd5, 0x63, 0x12, 0x9f, 0x35, 0x80, 0xba, 0xe3, 0x1d, 0xfb, 0x91, 0x67, 0x73, 0x79, 0x1b,
+	0x86, 0x1a, 0xa2, 0x07, 0xd0, 0x90, 0x46, 0x26, 0x83, 0x26, 0x57, 0xdd, 0x92, 0x54, 0x5d, 0xc6,
+	0x7d, 0x8c, 0x98, 0x4b, 0x47, 0x2c, 0xf8, 0x12, 0xee, 0xe9, 0xea, 0x58, 0xeb, 0xdf, 0x81, 0x85,
+	0x14, 0x26, 0xdd, 0xff, 0x0e, 0xd4, 0x98, 0x32, 0xc8, 0xa0, 0x94, 0x31, 0x09, 0x3f, 0x22, 0x82,
+	0xa2, 0xf7, 0x60, 0xfe, 0x13, 0x4c, 0x77, 0xbc, 0x91, 0xaf, 0x56, 0xfa, 0x5b, 0x09, 0xba, 0x31,
+	0x14, 0x2f, 0xf4, 0x52, 0x3b, 0xbc, 0x09, 0x3d, 0xc7, 0xc6, 0x1e, 0x75, 0xe8, 0xc4, 0x54, 0x7a,
+	0x17, 0x3e, 0xdc, 0x55, 0xb8, 0xba, 0x28, 0x1e, 0xc0, 0x12, 0xb3, 0xbf, 0xf2, 0x9a, 0x58, 0xfa,
+	0x0a, 0xbf, 0x67, 0x90, 0x17, 0x8d, 0x0f, 0x04, 0x49, 0x8a, 0x4e, 0xd0, 0x1a, 0x2c, 0xb2, 0x19,
+	0x16, 0x57, 0x48, 0x32, 0xa1, 0xca, 0x27, 0x2c, 0x78, 0xd1, 0x38, 0xa3, 0x2a, 0xc2, 0x8e, 0x9a,
+	0xf8, 0x02, 0x13, 0xbe, 0xc6, 0xb9, 0x1a, 0x7c, 0x59, 0x26, 0xf2, 0x0b, 0x1e, 0x6e, 0x46, 0x4e,
+	0x38, 0xb6, 0xa8, 0xe3, 0x7b, 0xc2, 0xe9, 0xd8, 0x94, 0x63, 0x76, 0xba, 0x4d, 0x72, 0x6a, 0xc9,
+	0x4b, 0xb1, 0xc1, 0x81, 0xc3, 0x53, 0x8b, 0xc9, 0x2f, 0x88, 0xa7, 0x98, 0x89, 0x2c, 0x3d, 0xad,
+	0xc5, 0xb1, 0x6d, 0x0e, 0xa1, 0x57, 0x61, 0x9e, 0x7d, 0x72, 0xe8, 0x7b, 0x23, 0x62, 0xba, 0x78,
+	0x44, 0xa5, 0x38, 0x6d, 0x2f, 0x1a, 0xb3, 0xcf, 0x91, 0x5d, 0x3c, 0xa2, 0xfa, 0x33, 0x58, 0x90,
+	0x9b, 0xdc, 0x0f, 0xb0, 0xfa, 0xf4, 0xc3, 0xfc, 0xd9, 0x17, 0x21, 0x6f, 0x51, 0x9a, 0x2b, 0x7d,
+	0x7d, 0x67, 0x03, 0x82, 0xfe, 0x19, 0x20, 0x49, 0xdd, 0x74, 0x7d, 0x82, 0xe5, 0x7a, 0x77, 0xa0,
+	0x3d, 0x74, 0x7d, 0x92, 0xbf, 0xe2, 0x25, 0xc6, 0xaf, 0xf8, 0x01, 0xd4, 0x49, 0x34, 0x1c, 0x2a,
+	0x23, 0x35, 0x0c, 0x35, 0xd4, 0x7f, 0x5f, 0x82, 0x45, 0xbe, 0x98, 0xf2, 0xbb, 0xf8, 0x7e, 0xf9,
+	0x0f, 0x37, 0xc9, 0xce, 0x13, 0x75, 0xc6, 0xd8, 0x74, 0x9d, 0xb1, 0xa3, 0xe2, 0x6a, 0x93, 0x21,
+	0xbb, 0x0c, 0x60, 0x37, 0xef, 0xc8, 0x0f, 0x87, 0x98, 0xeb, 0xab, 0x61, 0x88, 0x01, 0x73, 0x27,
+	0x1b, 0xbb, 0xce, 0x19, 0x0e, 0x13, 0x77, 0xaa, 0x0a, 0x77, 0x52, 0xb8, 0x74, 0x27, 0xfd, 0xeb,
+	0x12, 0x2c, 0xf0, 0x1d, 0x1f, 0x52, 0x8b, 0x46, 0x44, 0x2a, 0xe1, 0x03, 0xe8, 0x30, 0x81, 0xb1,
+	0x72, 0x33, 0xb9, 0xdf, 0xa5, 0xf8, 0x0c, 0x70, 0x54, 0x30, 0x6f, 0x5f, 0x33, 0xb8, 0xc6, 0xb0,
+	0x44, 0xd1, 0x87, 0xd0, 0x1e, 0xa6, 0x5c, 0x84, 0x6f, 0xba, 0xb5, 0xbe, 0xaa, 0x64, 0x2d, 0x78,
+	0x0f, 0x5f, 0x20, 0x85, 0xa2, 0xf7, 0x01, 0x98, 0x0e, 0x4c, 0xbe, 0xea, 0xa0, 0x92, 0x9d, 0x5e,
+	0xb0, 0xd8, 0xf6, 0x35, 0xa3, 0xc9, 0xd8, 0x39, 0xf4, 0xb8, 0x01, 0x73, 0x22, 0x34, 0xea, 0x77,
+	0xa1, 0x93, 0xd9, 0x67, 0x26, 0x1d, 0x68, 0xcb, 0x74, 0xe0, 0xa7, 0x65, 0x40, 0xcc, 0x99, 0x72,
+	0xf6, 0x7a, 0x15, 0xe6, 0xa9, 0x15, 0x9e, 0x60, 0x6a, 0x66, 0x6f, 0xc0, 0xb6, 0x40, 0x0f, 0x44,
+	0x90, 0xbc, 0x05, 0x2d, 0xc9, 0xe5, 0xf9, 0xb6, 0x48, 0x7e, 0xda, 0x06, 0x08, 0x68, 0xcf, 0xb7,
+	0x59, 0x74, 0x5f, 0x12, 0xd7, 0x8a, 0x4a, 0x1a, 0xe5, 0xf5, 0x28, 0xae, 0x1f, 0xc4, 0x69, 0x4f,
+	0x04, 0x49, 0x24, 0x58, 0x68, 0x1d, 0x96, 0xe5, 0x1d, 0x93, 0x9b, 0x22, 0x2e, 0xa4, 0x45, 0x41,
+	0xcc, 0xce, 0x79, 0x03, 0xba, 0x43, 0x7f, 0x3c, 0x76, 0x08, 0x71, 0x7c, 0xcf, 0x24, 0xce, 0x0b,
+	0x75, 0x31, 0xcd, 0x27, 0xf0, 0xa1, 0xf3, 0x02, 0xab, 0x83, 0xcd, 0x4f, 0xd9, 0x60, 0x2e, 0x3e,
+	0xd8, 0xfc, 0x80, 0xe9, 0x7f, 0x29, 0x41, 0x8f, 0x69, 0x22, 0xe3, 0x07, 0xef, 0x01, 0xf7, 0xc6,
+	0x2b, 0xba, 0x41, 0x8b, 0xf1, 0x7e, 0x6b, 0x5e, 0xf0, 0x5d, 0xe0, 0x66, 0x35, 0xfd, 0x00, 0x7b,
+	0xd2, 0x09, 0x06, 0x59, 0x27, 0x48, 0xa2, 0xc0, 0xf6, 0x35, 0x11, 0xe1, 0x19, 0x92, 0x72, 0x81,
+	0x2d, 0x58, 0xce, 0x06, 0x43, 0x65, 0xdf, 0xfb, 0x30, 0x47, 0xb8, 0x9c, 0x32, 0xe3, 0x5b, 0xca,
+	0x2e, 0x2c, 0x74, 0x60, 0x48, 0x1e, 0xfd, 0x4f, 0x15, 0xe8, 0xe7, 0xd7, 0x91, 0xb1, 0xfd, 0x0b,
+	0xe8, 0x15, 0x22, 0xb1, 0xb8, 0x2f, 0xee, 0x67, 0x95, 0x94, 0x9b, 0x98, 0x87, 0xbb, 0x41, 0x66,
+	0x4c, 0xb4, 0xaf, 0xcb, 0x30, 0x9f, 0xe5, 0x99, 0x99, 0x8f, 0x15, 0x2e, 0x98, 0x72, 0xf1, 0x82,
+	0x29, 0x64, 0x48, 0x95, 0x97, 0x64, 0x48, 0xd5, 0x97, 0x65, 0x48, 0xb5, 0x2b, 0x65, 0x48, 0x73,
+	0xd3, 0x32, 0xa4, 0x7c, 0x88, 0xad, 0x8b, 0xfd, 0xa6, 0x43, 0x6c, 0x62, 0xa0, 0xc6, 0xcb, 0x0d,
+	0xc4, 0x52, 0xae, 0x10, 0x13, 0x1c, 0x9e, 0x71, 0xcf, 0x31, 0x19, 0x8a, 0x07, 0x4d, 0xbe, 0x6a,
+	0x2f, 0x45, 0x60, 0xb3, 0xb0, 0xfe, 0x1e, 0x2c, 0x7d, 0x61, 0xb9, 0x2e, 0xa6, 0x72, 0x3b, 0xca,
+	0x27, 0xee, 0x40, 0xfb, 0xdc, 0xa1, 0x1e, 0x26, 0xc4, 0xf4, 0x3d, 0x57, 0xd4, 0x37, 0x0d, 0xa3,
+	0x25, 0xb1, 0x7d, 0xcf, 0x9d, 0xe8, 0xef, 0xc0, 0x72, 0x6e, 0x6a, 0x92, 0x9e, 0x2b, 0x89, 0xd9,
+	0xb4, 0x92, 0xa1, 0x86, 0xfa, 0x0a, 0x2c, 0xcb, 0x3d, 0x67, 0x3f, 0xa7, 0xaf, 0x43, 0x3f, 0x4f,
+	0x98, 0xbe, 0x58, 0x25, 0x59, 0xec, 0x27, 0x25, 0xe8, 0x19, 0x7e, 0x44, 0x99, 0x96, 0xac, 0x63,
+	0x17, 0xef, 0x3a, 0xde, 0x73, 0x56, 0x8e, 0x39, 0xf6, 0x3b, 0xaa, 0x1c, 0x73, 0xec, 0x77, 0x04,
+	0xb2, 0x2e, 0xdd, 0x80, 0xfd, 0x64, 0x96, 0x65, 0x05, 0x68, 0xca, 0xf2, 0xf1, 0xf8, 0x52, 0xab,
+	0xf7, 0x61, 0xee, 0x5c, 0x5c, 0xda, 0x35, 0x2e, 0x96, 0x1c, 0xe9, 0xab, 0xb0, 0x72, 0x78, 0xea,
+	0x9f, 0xa7, 0xf7, 0xa2, 0xe4, 0xda, 0x87, 0x41, 0x91, 0x24, 0x25, 0x7b, 0x17, 0x1a, 0xb9, 0x53,
+	0xa2, 0x2a, 0x93, 0xbc, 0x54, 0xa9, 0x84, 0xed, 0xcf, 0x25, 0x68, 0x6c, 0x63, 0xd7, 0xe6, 0x25,
+	0xc7, 0xdd, 0x69, 0x17, 0x69, 0xde, 0x8f, 0x97, 0xa0, 0x96, 0xd4, 0xde, 0x55, 0x43, 0x0c, 0xae,
+	0xd2, 0x1b, 0x58, 0x85, 0x86, 0x45, 0x08, 0xa6, 0xec, 0x10, 0x55, 0x65, 0xda, 0xcb, 0xc6, 0x3b,
+	0xe9, 0xda, 0xa6, 0x96, 0xa9, 0x6d, 0xfa, 0x30, 0x87, 0x2f, 0x02, 0x27, 0x9c, 0xc8, 0x80, 0x2a,
+	0x47, 0xcc, 0x88, 0x81, 0x35, 0x71, 0x7d, 0x4b, 0xb8, 0x77, 0xdb, 0x50, 0x43, 0xbd, 0x0f, 0x4b,
+	0x2c, 0xd9, 0x54, 0x22, 0xc5, 0x49, 0xe8, 0x23, 0x58, 0xce, 0xe1, 0x52, 0x6b, 0xaf, 0x41, 0x4d,
+	0xd4, 0x06, 0x42, 0x65, 0x5d, 0x55, 0x1b, 0x48, 0x46, 0x43, 0x50, 0xf5, 0x9f, 0x97, 0x00, 0x19,
+	0x98, 0xf8, 0xee, 0x19, 0xe6, 0xf0, 0x37, 0x4e, 0x3d, 0xa6, 0xab, 0x51, 0x83, 0x46, 0x10, 0x62,
+	0x67, 0x6c, 0x9d, 0x60, 0x55, 0xcb, 0xa9, 0x31, 0xbb, 0x61, 0x47, 0x96, 0xe3, 0xaa, 0x52, 0x8e,
+	0xfd, 0xd6, 0x97, 0x61, 0x31, 0xb3, 0x2b, 0xd9, 0x59, 0xf9, 0x45, 0x09, 0x06, 0x4f, 0xfc, 0xf0,
+	0xdc, 0x0a, 0x79, 0x69, 0xe3, 0x10, 0xea, 0x87, 0x71, 0x13, 0xe3, 0x06, 0x00, 0xa1, 0x56, 0x48,
+	0x4d, 0x96, 0xe8, 0xc8, 0x43, 0xd0, 0xe4, 0xc8, 0x91, 0x33, 0xc6, 0xcc, 0x4c, 0xd8, 0xb3, 0x05,
+	0x51, 0x64, 0x44, 0x75, 0xec, 0xd9, 0x8a, 0x14, 0x5b, 0xb0, 0x92, 0xb5, 0xa0, 0xcc, 0x31, 0xc7,
+	0xd6, 0x85, 0x89, 0xcf, 0xb0, 0x47, 0x55, 0x06, 0xcc, 0x72, 0xcc, 0x67, 0xd6, 0xc5, 0x16, 0xc7,
+	0xf4, 0x7f, 0x96, 0xa0, 0x9b, 0xec, 0x8b, 0x83, 0xe8, 0x3a, 0xf0, 0x8c, 0x8b, 0x50, 0x6b, 0x1c,
+	0xa8, 0xdd, 0xc4, 0x00, 0xd2, 0x85, 0x82, 0x85, 0x76, 0x4d, 0xc7, 0x53, 0xe1, 0x97, 0x5f, 0x86,
+	0x0c, 0xdb, 0xf1, 0xd8, 0xb7, 0x53, 0x3c, 0x7e, 0x94, 0x89, 0xbf, 0x9c, 0x69, 0x3f, 0xa2, 0xa9,
+	0xcd, 0x7b, 0x59, 0xf7, 0xf3, 0xd8, 0xd5, 0x2d, 0x48, 0x7e, 0x24, 0x3c, 0xb0, 0x69, 0x08, 0x5e,
+	0x36, 0x6f, 0x99, 0xf9, 0x26, 0x9f, 0x25, 0xc2, 0x6d, 0xcd, 0x1a, 0xb3, 0x39, 0x2b, 0x50, 0xb7,
+	0xc6, 0x62, 0x46, 0x5d, 0xf9, 0x2c, 0xe7, 0xef, 0x41, 0x65, 0x84, 0x31, 0x8f, 0xac, 0x15, 0x83,
+	0xfd, 0xd4, 0xbf, 0x84, 0xd5, 0x29, 0xc6, 0x90, 0xfe, 0xb7, 0x09, 0x0b, 0xa3, 0x98, 0xa8, 0x74,
+	0x27, 0x7c, 0xb1, 0x2f, 0xbd, 0x28, 0xa7, 0x31, 0xa3, 0x37, 0xca, 0x02, 0x44, 0x9f, 0xc0, 0xc2,
+	0x16, 0xa1, 0xce, 0xd8, 0xa2, 0xf8, 0xe8, 0x22, 0x15, 0x72, 0x85, 0x54, 0x96, 0x6a, 0x56, 0xb1,
+	0x1d, 0xb5, 0x38, 0x26, 0x93, 0x1b, 0x59, 0xee, 0x8a, 0xf6, 0x19, 0x91, 0xdd, 0x34, 0x56, 0xee,
+	0xee, 0x0b, 0x04, 0xdd, 0x86, 0x36, 0x2b, 0x1b, 0x03, 0x1c, 0x9a, 0xac, 0xcc, 0xe4, 0x8a, 0xad,
+	0x1a, 0x40, 0x2c, 0x7a, 0x80, 0xc3, 0xc7, 0x13, 0x8a, 0xf9, 0xc1, 0x48, 0x7f, 0x5b, 0x8a, 0xd5,
+	0x87, 0x39, 0xc7, 0x0b, 0x22, 0x29, 0x4b, 0xd3, 0x90, 0x23, 0xde, 0xcc, 0xe2, 0x49, 0x94, 0x6a,
+	0x66, 0xb1, 0x01, 0x53, 0xe6, 0x08, 0x63, 0x93, 0x58, 0x2a, 0x7b, 0x9b, 0x1b, 0x61, 0x7c, 0x68,
+	0xf1, 0x00, 0xc0, 0x8c, 0x78, 0xa2, 0x7a, 0x06, 0x72, 0xc4, 0x36, 0x3e, 0x8a, 0xb0, 0x6b, 0x4a,
+	0xa2, 0x88, 0x1a, 0xc0, 0xa0, 0x4d, 0x8e, 0xe8, 0x9b, 0x30, 0xff, 0x14, 0x4f, 0x48, 0xaa, 0xc7,
+	0x79, 0x0b, 0x5a, 0x36, 0x26, 0xd4, 0x0c, 0xa2, 0x63, 0xd5, 0x60, 0x6b, 0x1b, 0xc0, 0xa0, 0x03,
+	0x8e, 0x14, 0x1b, 0x9e, 0xba, 0x09, 0xdd, 0x78, 0x11, 0x29, 0xd7, 0x9b, 0xd0, 0x53, 0x71, 0x2e,
+	0x3e, 0xa8, 0x62, 0xa9, 0xae, 0xc4, 0x0f, 0x24, 0x5c, 0x08, 0x89, 0xe5, 0x42, 0x48, 0xd4, 0x7f,
+	0x04, 0x2b, 0xcf, 0x22, 0x97, 0x3a, 0x07, 0x56, 0x48, 0x0f, 0x04, 0x7e, 0x59, 0x4b, 0x36, 0x7d,
+	0xfe, 0xca, 0xd9, 0xf3, 0x27, 0x37, 0x5f, 0x99, 0xdd, 0xad, 0xad, 0x16, 0x3f, 0xaf, 0xc1, 0xa0,
+	0xf8, 0x79, 0x19, 0x42, 0x7e, 0xcd, 0x6e, 0x43, 0x7c, 0x9c, 0xbd, 0xc5, 0xd3, 0x1b, 0x28, 0x4d,
+	0xdd, 0x40, 0xa2, 0x3d, 0xf4, 0x00, 0x9a, 0xa3, 0xd0, 0x1f, 0x73, 0x1b, 0x0d, 0x2a, 0xb3, 0xe3,
+	0x62, 0x83, 0x71, 0x31, 0x04, 0xdd, 0x87, 0x3a, 0xf5, 0x05, 0x7f, 0x75, 0x36, 0xff, 0x1c, 0xf5,
+	0xd9, 0x58, 0x5f, 0x84, 0x85, 0xd4, 0x06, 0xe5, 0xb6, 0x07, 0xd0, 0x37, 0xf0, 0xd0, 0x3f, 0xc3,
+	0xa1, 0x9c, 0x13, 0xdf, 0x00, 0x3f, 0x84, 0x9e, 0xa4, 0x60, 0x5b, 0xd2, 0xae, 0x76, 0xe1, 0xdd,
+	0x85, 0x0e, 0x09, 0x98, 0x1a, 0xfd, 0xd1, 0xc8, 0x75, 0x3c, 0x2c, 0xcb, 0xd2, 0x36, 0x07, 0xf7,
+	0x05, 0xa6, 0x7b, 0xb0, 0x14, 0x27, 0xa1, 0xfc, 0x23, 0x93, 0x1d, 0x42, 0x22, 0x7c, 0xb5, 0x2f,
+	0x64, 0xda, 0x6f, 0xe5, 0x5c, 0xfb, 0x6d, 0x09, 0x6a, 0x38, 0x0c, 0xfd, 0x50, 0x06, 0x35, 0x31,
+	0xd0, 0x7f, 0x56, 0x82, 0x95, 0x82, 0xa0, 0xd2, 0x47, 0xff, 0x9f, 0x2d, 0x27, 0x25, 0xcd, 0x67,
+	0x02, 0x39, 0x0d, 0x18, 0x09, 0x27, 0x7a, 0x04, 0x6d, 0x0f, 0x63, 0x9b, 0xf0, 0x5e, 0x06, 0xaf,
+	0x29, 0xd8, 0xcc, 0x57, 0xb2, 0x26, 0xc8, 0x48, 0x67, 0xb4, 0xf8, 0x84, 0x0d, 0xce, 0xaf, 0xbf,
+	0x80, 0xa5, 0x03, 0x6b, 0xf2, 0xf8, 0x68, 0x73, 0xc7, 0x3b, 0xf3, 0x9d, 0x2b, 0x39, 0x8d, 0x72,
+	0xf2, 0x72, 0xca, 0xc9, 0xaf, 0x90, 0x49, 0x48, 0x5f, 0xab, 0x26, 0x27, 0xf5, 0xb7, 0x25, 0x58,
+	0xce, 0x7d, 0x5c, 0x2a, 0x83, 0xd7, 0x6f, 0xde, 0x19, 0x0e, 0x79, 0xfd, 0xc6, 0x4b, 0x49, 0x71,
+	0xa4, 0xe6, 0x13, 0x98, 0x97, 0x93, 0x37, 0x00, 0xc4, 0x36, 0x79, 0xfb, 0x4c, 0xf6, 0x02, 0x38,
+	0xc2, 0x1b, 0x68, 0xff, 0x07, 0x88, 0xb8, 0x4e, 0x10, 0x58, 0x27, 0xd8, 0xb4, 0x5c, 0xd7, 0x3f,
+	0xe7, 0x29, 0xa4, 0x38, 0x6f, 0x0b, 0x8a, 0xb2, 0xa1, 0x08, 0x4c, 0x68, 0x16, 0x59, 0x4f, 0xfd,
+	0x40, 0xdd, 0x84, 0x75, 0x2f, 0x1a, 0x6f, 0xfb, 0x01, 0xd1, 0x8f, 0x60, 0xe1, 0x20, 0xf4, 0x8f,
+	0x31, 0xcb, 0xca, 0xf0, 0xb7, 0x75, 0xdc, 0xf5, 0x1f, 0x43, 0x83, 0x2f, 0xb8, 0xed, 0x07, 0x57,
+	0x76, 0x3a, 0x0f, 0x5f, 0x64, 0xaa, 0xeb, 0x06, 0x03, 0xb8, 0x32, 0x2e, 0xb9, 0xe9, 0x93, 0x5c,
+	0xad, 0x9a, 0x79, 0x24, 0x78, 0x0f, 0x50, 0x5a, 0x2c, 0xa9, 0xfe, 0xbb, 0xec, 0x65, 0x25, 0xc8,
+	0x67, 0x57, 0x6a, 0xa7, 0x06, 0x27, 0xea, 0xfb, 0xb0, 0xb8, 0x71, 0xec, 0x87, 0x54, 0x56, 0xde,
+	0xdf, 0x38, 0xb9, 0xd2, 0x37, 0x60, 0x29, 0xbb, 0x60, 0x12, 0xbd, 0x43, 0x1c, 0xb8, 0xd6, 0x10,
+	0x73, 0xff, 0x4a, 0x35, 0x2c, 0xba, 0x29, 0x9c, 0xd5, 0x48, 0xbc, 0xb4, 0x70, 0x7d, 0x82, 0xed,
+	0x7c, 0x1c, 0xf9, 0x43, 0x19, 0x3a, 0x19, 0xca, 0xb7, 0x70, 0xc6, 0x2f, 0x51, 0xf7, 0x65, 0x05,
+	0xc4, 0x2d, 0x68, 0xf9, 0x51, 0x98, 0x2b, 0x1a, 0xc1, 0x8f, 0x42, 0x55, 0x0b, 0xde, 0x85, 0x0e,
+	0x3d, 0xc5, 0x4e, 0x98, 0xab, 0x18, 0xdb, 0x1c, 0x54, 0x4c, 0x37, 0x00, 0x44, 0x3b, 0x8a, 0x3f,
+	0xd2, 0x88, 0x72, 0xb1, 0xc9, 0x11, 0xfe, 0xe8, 0x92, 0xaf, 0x27, 0x1b, 0xc5, 0x7a, 0x52, 0xb2,
+	0x60, 0xd5, 0x83, 0x6c, 0x8a, 0x57, 0x39, 0x8e, 0x89, 0x1e, 0xa4, 0xfe, 0x29, 0xf4, 0xf3, 0xea,
+	0x94, 0x36, 0x79, 0x50, 0x28, 0x5b, 0xe2, 0x72, 0x34, 0x3d, 0x21, 0x55, 0xb3, 0x0c, 0xa0, 0xbf,
+	0xeb, 0x7c, 0x15, 0x39, 0xb6, 0x43, 0x27, 0x06, 0x0e, 0xfc, 0x50, 0x5d, 0x9a, 0xfa, 0x5f, 0xcb,
+	0x30, 0xbf, 0xc1, 0x14, 0x17, 0xd3, 0xaf, 0xd2, 0x1f, 0xbe, 0xe4, 0x9c, 0xdd, 0x81, 0x36, 0x6f,
+	0xea, 0xa4, 0xfb, 0xc0, 0x15, 0x83, 0x25, 0x4d, 0x4a, 0x8e, 0xff, 0x59, 0x5d, 0xff, 0x16, 0x2c,
+	0xa4, 0x5b, 0xd3, 0xea, 0x41, 0x83, 0x71, 0x76, 0x93, 0xbe, 0xb4, 0x78, 0xc6, 0x78, 0x33, 0x69,
+	0x9c, 0x38, 0xde, 0xd0, 0x1f, 0xb3, 0xee, 0x92, 0x48, 0x48, 0x55, 0x2b, 0x64, 0x47, 0xc2, 0x69,
+	0x56, 0x3f, 0xa2, 0x27, 0x3e, 0x63, 0x6d, 0x66, 0x58, 0xf7, 0x25, 0xac, 0xef, 0xc1, 0x4a, 0x41,
+	0xef, 0x71, 0xed, 0xd9, 0x74, 0x15, 0x49, 0x5a, 0x71, 0x59, 0x3d, 0x15, 0x64, 0xec, 0x61, 0x24,
+	0x7c, 0xfa, 0xdf, 0x4b, 0xd0, 0xdd, 0x88, 0x6c, 0x87, 0xee, 0xfa, 0x27, 0xff, 0xd5, 0xe2, 0xa4,
+	0x10, 0x48, 0xaa, 0x57, 0xad, 0xd2, 0x58, 0x3d, 0x16, 0x85, 0x81, 0x4f, 0x30, 0x6b, 0xd6, 0xb3,
+	0x44, 0x36, 0x1e, 0xa3, 0xd7, 0xa1, 0xab, 0x4a, 0x1e, 0x76, 0x89, 0x86, 0xb6, 0x6a, 0xfb, 0x75,
+	0x44, 0xcd, 0x63, 0x08, 0x50, 0xff, 0x47, 0x09, 0x5a, 0x5c, 0x4c, 0x01, 0xb0, 0xd8, 0x4d, 0xf0,
+	0x57, 0x5c, 0xb6, 0xaa, 0xc1, 0x7e, 0x66, 0x4b, 0xa0, 0x72, 0xbe, 0x04, 0x62, 0xc5, 0xae, 0xf8,
+	0xa6, 0x92, 0x4b, 0x0e, 0xd1, 0xdd, 0x69, 0x72, 0xe5, 0x03, 0x4f, 0x5a, 0x2f, 0xb5, 0xac, 0x5e,
+	0x96, 0x61, 0x2e, 0xb4, 0xce, 0x4d, 0x7a, 0xc1, 0x37, 0xde, 0x36, 0x6a, 0xa1, 0x75, 0x7e, 0x74,
+	0x81, 0x74, 0x68, 0x3b, 0x1e, 0xa1, 0x61, 0xc4, 0xef, 0x75, 0x22, 0x4b, 0xec, 0x0c, 0xc6, 0x6e,
+	0x81, 0x10, 0x93, 0xc8, 0xa5, 0x32, 0x1e, 0xc8, 0x91, 0xfe, 0x11, 0xf4, 0x12, 0x93, 0x4a, 0xe7,
+	0xb8, 0x0f, 0x75, 0xa5, 0x20, 0xe1, 0x1a, 0x48, 0xb9, 0x46, 0xa2, 0x15, 0x43, 0xb1, 0xe8, 0x07,
+	0xb0, 0x24, 0xc3, 0xf6, 0xe3, 0xc8, 0xb3, 0xe3, 0xd6, 0xc7, 0x37, 0xb8, 0x0d, 0x7e, 0x57, 0x82,
+	0xe5, 0xdc, 0x92, 0x72, 0x67, 0x97, 0xa4, 0x26, 0xaf, 0xc1, 0xbc, 0x20, 0xc5, 0x87, 0x5b, 0x18,
+	0xa6, 0xc3, 0xd1, 0x4d, 0x09, 0xb2, 0xb0, 0x2e, 0x4e, 0x38, 0xab, 0x29, 0x64, 0xf3, 0x87, 0x03,
+	0x4f, 0xf1, 0x84, 0x39, 0xb3, 0x3c, 0xd9, 0x8c, 0x2a, 0x8c, 0x23, 0x6f, 0x01, 0x46, 0xee, 0xc3,
+	0xdc, 0x31, 0xdf, 0x0f, 0xb7, 0x4b, 0xdb, 0x90, 0xa3, 0xb7, 0xd6, 0xa1, 0x93, 0xe9, 0xc4, 0xa1,
+	0x3a, 0x54, 0x36, 0x76, 0x77, 0x7b, 0xd7, 0x50, 0x0b, 0xea, 0xfb, 0x07, 0x5b, 0x7b, 0x3b, 0x7b,
+	0x9f, 0xf4, 0x4a, 0x6c, 0xb0, 0xb9, 0xbb, 0x7f, 0xc8, 0x06, 0xe5, 0xf5, 0x7f, 0x75, 0xa1, 0x19,
+	0x3f, 0x40, 0xa3, 0x4f, 0xa1, 0x93, 0x69, 0xa5, 0x21, 0x95, 0xc5, 0x4d, 0xeb, 0xcd, 0x69, 0xd7,
+	0xa7, 0x13, 0xa5, 0x8e, 0x9e, 0xc1, 0x7c, 0xb6, 0x95, 0x86, 0xae, 0x67, 0x55, 0x9e, 0x5b, 0xed,
+	0xc6, 0x0c, 0xaa, 0x5c, 0xee, 0x03, 0x68, 0xa8, 0xff, 0x2c, 0xa0, 0xfe, 0xf4, 0x3f, 0x4e, 0x68,
+	0x2b, 0x05, 0x5c, 0x4e, 0x7e, 0x04, 0xcd, 0xf8, 0x8f, 0x08, 0x28, 0xcd, 0x95, 0xfe, 0x6b, 0x83,
+	0x36, 0x28, 0x12, 0xe4, 0xfc, 0x0d, 0x80, 0xe4, 0xf9, 0x1f, 0x0d, 0x66, 0xfd, 0x13, 0x41, 0x5b,
+	0x9d, 0x42, 0x91, 0x4b, 0x7c, 0x0c, 0xad, 0xd4, 0x73, 0x3e, 0x4a, 0xb5, 0xdc, 0x73, 0xff, 0x12,
+	0xd0, 0xb4, 0x69, 0xa4, 0x44, 0x90, 0xf8, 0x4d, 0x14, 0x25, 0x7f, 0x20, 0xc8, 0xbe, 0x9c, 0x6a,
+	0x83, 0x22, 0x41, 0xce, 0x7f, 0x08, 0x75, 0xf9, 0x10, 0x8a, 0x54, 0x9c, 0xcd, 0xbe, 0x95, 0x6a,
+	0xfd, 0x3c, 0x1c, 0xf7, 0x1b, 0x5a, 0xa9, 0x27, 0x99, 0x78, 0xff, 0xc5, 0x67, 0x1a, 0x6d, 0x25,
+	0x45, 0x4a, 0xbf, 0x5b, 0x3c, 0x28, 0xa1, 0x27, 0xd0, 0x4e, 0x3f, 0xc4, 0x21, 0x2d, 0x7d, 0x63,
+	0xe7, 0x96, 0x19, 0xa4, 0x69, 0xb9, 0x75, 0xf6, 0xa0, 0x9b, 0x7f, 0x4f, 0xbd, 0x3e, 0xa3, 0xb3,
+	0x9f, 0x75, 0xae, 0x19, 0x0f, 0x06, 0xef, 0x8b, 0xbf, 0x35, 0xc9, 0x5a, 0x16, 0xa1, 0x94, 0x23,
+	0xa8, 0x15, 0x16, 0x33, 0x98, 0x98, 0x77, 0xaf, 0xf4, 0xa0, 0x84, 0x0e, 0xa1, 0x97, 0x6f, 0xad,
+	0xa2, 0x9b, 0x8a, 0x79, 0x7a, 0x3b, 0x56, 0xbb, 0x35, 0x93, 0x2e, 0x37, 0xf4, 0x29, 0x74, 0x32,
+	0x6d, 0xc7, 0xf8, 0x20, 0x4e, 0x6b, 0x52, 0x6a, 0xd7, 0xa7, 0x13, 0x13, 0xcf, 0x4b, 0xf5, 0xfa,
+	0x62, 0xcb, 0x15, 0xbb, 0x92, 0x9a, 0x36, 0x8d, 0x24, 0x57, 0xf9, 0x3e, 0x2c, 0x14, 0x9a, 0x51,
+	0xe8, 0x56, 0xa1, 0xd3, 0x94, 0xed, 0x19, 0x6a, 0xb7, 0x67, 0x33, 0x24, 0x47, 0x2b, 0x69, 0x03,
+	0xc5, 0x47, 0xab, 0xd0, 0x95, 0xd2, 0x56, 0xa7, 0x50, 0xe4, 0x12, 0xdf, 0x13, 0xd6, 0x93, 0x2d,
+	0x97, 0xd8, 0xb1, 0xb3, 0x7d, 0x1c, 0xad, 0x9f, 0x87, 0xe3, 0xc7, 0xa2, 0x25, 0x1e, 0x2f, 0x72,
+	0x0d, 0x8d, 0xd8, 0x86, 0x33, 0x1a, 0x2d, 0xda, 0xad, 0x99, 0xf4, 0xe4, 0xac, 0xc6, 0x7d, 0x06,
+	0x94, 0x14, 0xd2, 0xd9, 0xd6, 0x88, 0x36, 0x28, 0x12, 0xe4, 0xfc, 0x03, 0xe8, 0xe6, 0x2a, 0x75,
+	0x74, 0x23, 0x5b, 0x8e, 0xe7, 0x4a, 0x0c, 0xed, 0xe6, 0x2c, 0x72, 0xe2, 0x55, 0x99, 0x62, 0x37,
+	0xf6, 0xaa, 0x69, 0xf5, 0xb7, 0x76, 0x7d, 0x3a, 0x31, 0xb1, 0x5b, 0x52, 0xb6, 0xc5, 0x76, 0x2b,
+	0x14, 0xa8, 0xda, 0xea, 0x14, 0x8a, 0x5c, 0xe2, 0x13, 0x68, 0xa7, 0xab, 0xad, 0x38, 0x1a, 0x4c,
+	0xa9, 0xe9, 0xb4, 0x57, 0xa6, 0xd2, 0x52, 0x57, 0x4d, 0xa6, 0x48, 0x48, 0xae, 0x9a, 0x69, 0xa5,
+	0x98, 0x76, 0x63, 0x06, 0x35, 0x51, 0x7c, 0x2e, 0x5f, 0x8d, 0x15, 0x3f, 0xbd, 0x7e, 0xd0, 0x6e,
+	0xce, 0x22, 0xcb, 0x15, 0x3f, 0x82, 0xce, 0x67, 0x11, 0x7b, 0xdf, 0x97, 0x29, 0x4e, 0x7c, 0x83,
+	0xe5, 0xd2, 0x58, 0x6d, 0xa5, 0x80, 0xc7, 0x7b, 0x5a, 0xdc, 0xba, 0x08, 0x12, 0xd9, 0x45, 0x42,
+	0x12, 0x1b, 0x70, 0x5a, 0xe6, 0xa3, 0x5d, 0x9f, 0x4e, 0x14, 0x2b, 0x1e, 0xcf, 0xf1, 0x3f, 0x76,
+	0xbe, 0xfb, 0xef, 0x01, 0x00, 0xc0, 0x08, 0x70, 0x5d, 0xe5, 0x29, 0x00, 0x00,
 }
@@ -0,0 +1,62 @@
+package lnwallet
+
+import (
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// ValidateAsset ensures the asset the wallet operates on, as configured via
+// CC_ASSET_ID, exists on the network the wallet is operating on. The asset's
+// issuance is looked up via the cc-explorer configured by CC_EXPLORER_URL,
+// and the issuance transaction must be known to our chain backend. Otherwise,
+// the asset was most likely issued on a different network, such as testnet
+// while we operate on mainnet, so all channel reservations are refused from
+// then on. Validation is skipped if no cc-explorer has been configured.
+func (l *LightningWallet) ValidateAsset() error {
+	err := l.validateAsset(globallyActiveAssetId)
+
+	l.limboMtx.Lock()
+	l.assetErr = err
+	l.limboMtx.Unlock()
+
+	return err
+}
+
+// validateAsset carries out ValidateAsset for the passed asset.
+func (l *LightningWallet) validateAsset(assetID string) error {
+	// Channels of plain bitcoin don't require any validation.
+	if assetID == "" {
+		return nil
+	}
+
+	network := l.netParams.Name
+	info, err := lndcc.GetAssetInfo(assetID)
+	switch {
+	case err == lndcc.ErrNoExplorer:
+		walletLog.Warnf("CC_EXPLORER_URL not set, unable to validate "+
+			"asset %v exists on %v", assetID, network)
+		return nil
+
+	case err != nil:
+		return fmt.Errorf("unable to find asset %v on %v: %v", assetID,
+			network, err)
+	}
+
+	issuanceTxid, err := wire.NewShaHashFromStr(info.IssuanceTxid)
+	if err != nil {
+		return fmt.Errorf("invalid issuance txid %q for asset %v: %v",
+			info.IssuanceTxid, assetID, err)
+	}
+	if _, err := l.chainIO.GetTransaction(issuanceTxid); err != nil {
+		return fmt.Errorf("issuance tx %v of asset %v not found on %v, "+
+			"the asset was likely issued on a different network: %v",
+			issuanceTxid, assetID, network, err)
+	}
+
+	walletLog.Infof("Validated asset %v, issued in tx %v on %v", assetID,
+		issuanceTxid, network)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package lnwallet
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// PublishAuditedTransaction broadcasts the passed transaction, recording it
+// within the audit log along with the purpose it serves, the channel it
+// belongs to, the asset it carries, and the outcome of the broadcast. A
+// failure to record the transaction is logged, but doesn't prevent its
+// broadcast.
+func (l *LightningWallet) PublishAuditedTransaction(tx *wire.MsgTx,
+	purpose channeldb.TxPurpose, chanPoint wire.OutPoint,
+	assetID string) error {
+
+	publishErr := l.PublishTransaction(tx)
+
+	var rawTx bytes.Buffer
+	if err := tx.Serialize(&rawTx); err != nil {
+		walletLog.Errorf("unable to serialize %v tx %v for audit log: %v",
+			purpose, tx.TxSha(), err)
+		return publishErr
+	}
+
+	record := &channeldb.AuditRecord{
+		Timestamp:    time.Now(),
+		Purpose:      purpose,
+		ChanPoint:    chanPoint,
+		AssetID:      assetID,
+		RawTx:        rawTx.Bytes(),
+		Instructions: colorPayload(tx),
+	}
+	if publishErr != nil {
+		record.Result = publishErr.Error()
+	}
+
+	if err := l.ChannelDB.AddAuditRecord(record); err != nil {
+		walletLog.Errorf("unable to add %v tx %v to audit log: %v",
+			purpose, tx.TxSha(), err)
+	}
+
+	return publishErr
+}
+
+// colorPayload returns the payload of the first OP_RETURN output of the
+// passed transaction, which carries the encoded colored coins transfer
+// instructions, or nil if the transaction has no such output.
+func colorPayload(tx *wire.MsgTx) []byte {
+	for _, txOut := range tx.TxOut {
+		if txscript.GetScriptClass(txOut.PkScript) != txscript.NullDataTy {
+			continue
+		}
+
+		pushes, err := txscript.PushedData(txOut.PkScript)
+		if err != nil || len(pushes) == 0 {
+			return nil
+		}
+		return pushes[0]
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package lnwallet
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// batchVerify carries out numItems independent verifications, identified by
+// their index, concurrently across up to one goroutine per CPU. All
+// verifications are carried out even if one fails, so that the error
+// returned is always that of the lowest indexed failure, regardless of
+// scheduling.
+func batchVerify(numItems int, verify func(int) error) error {
+	numWorkers := runtime.NumCPU()
+	if numWorkers > numItems {
+		numWorkers = numItems
+	}
+
+	// There's nothing to be gained from spinning up goroutines for a
+	// single verification.
+	if numWorkers <= 1 {
+		for i := 0; i < numItems; i++ {
+			if err := verify(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, numItems)
+	indexes := make(chan int, numItems)
+	for i := 0; i < numItems; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				errs[index] = verify(index)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inputScriptVerification is a single input of a transaction whose
+// witness+sigScript combo is to be verified against the output it spends.
+type inputScriptVerification struct {
+	index    int
+	pkScript []byte
+	value    int64
+}
+
+// verifyInputScripts verifies the witness+sigScript combo of each of the
+// passed inputs of the target transaction, executing the scripts of all
+// inputs concurrently.
+func verifyInputScripts(tx *wire.MsgTx, hashCache *txscript.TxSigHashes,
+	inputs []*inputScriptVerification) error {
+
+	return batchVerify(len(inputs), func(i int) error {
+		input := inputs[i]
+		vm, err := txscript.NewEngine(input.pkScript, tx, input.index,
+			txscript.StandardVerifyFlags, nil, hashCache, input.value)
+		if err != nil {
+			return fmt.Errorf("cannot create script engine: %s", err)
+		}
+		if err := vm.Execute(); err != nil {
+			return fmt.Errorf("cannot validate transaction: %s", err)
+		}
+
+		return nil
+	})
+}
+
+// sigVerification is a single ECDSA signature to be verified against the
+// passed sighash and public key.
+type sigVerification struct {
+	sig    *btcec.Signature
+	hash   []byte
+	pubKey *btcec.PublicKey
+}
+
+// verifySigs verifies each of the passed signatures concurrently, returning
+// an error identifying the first signature which is invalid.
+func verifySigs(sigs []*sigVerification) error {
+	return batchVerify(len(sigs), func(i int) error {
+		if !sigs[i].sig.Verify(sigs[i].hash, sigs[i].pubKey) {
+			return fmt.Errorf("signature %v is invalid", i)
+		}
+		return nil
+	})
+}
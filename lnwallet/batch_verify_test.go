@@ -0,0 +1,75 @@
+package lnwallet
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// TestBatchVerify tests that every item of a batch is verified, and that the
+// error of the lowest indexed failure is returned.
+func TestBatchVerify(t *testing.T) {
+	for _, numItems := range []int{0, 1, 2, 100} {
+		var numVerified int32
+		err := batchVerify(numItems, func(i int) error {
+			atomic.AddInt32(&numVerified, 1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error verifying %v items: %v",
+				numItems, err)
+		}
+		if int(numVerified) != numItems {
+			t.Fatalf("verified %v of %v items", numVerified, numItems)
+		}
+	}
+
+	err := batchVerify(100, func(i int) error {
+		if i%10 == 7 {
+			return fmt.Errorf("item %v", i)
+		}
+		return nil
+	})
+	if err == nil || err.Error() != "item 7" {
+		t.Fatalf("expected failure of item 7, got %v", err)
+	}
+}
+
+// TestVerifySigs tests that a batch of signatures is only accepted if every
+// signature within it is valid.
+func TestVerifySigs(t *testing.T) {
+	const numSigs = 20
+
+	sigs := make([]*sigVerification, numSigs)
+	for i := range sigs {
+		privKey, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		hash := fastsha256.Sum256([]byte{byte(i)})
+		sig, err := privKey.Sign(hash[:])
+		if err != nil {
+			t.Fatalf("unable to sign: %v", err)
+		}
+
+		sigs[i] = &sigVerification{
+			sig:    sig,
+			hash:   hash[:],
+			pubKey: privKey.PubKey(),
+		}
+	}
+
+	if err := verifySigs(sigs); err != nil {
+		t.Fatalf("valid signatures rejected: %v", err)
+	}
+
+	// Swapping the keys of two signatures should invalidate both, with
+	// the lowest indexed one being reported.
+	sigs[3].pubKey, sigs[12].pubKey = sigs[12].pubKey, sigs[3].pubKey
+	if err := verifySigs(sigs); err == nil || err.Error() != "signature 3 is invalid" {
+		t.Fatalf("expected signature 3 to be invalid, got %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package btcwallet
+
+import (
+	"encoding/binary"
+
+	"github.com/roasbeef/btcwallet/waddrmgr"
+	base "github.com/roasbeef/btcwallet/wallet"
+	"github.com/roasbeef/btcwallet/walletdb"
+)
+
+// chanAccountKey stores the number of the account dedicated to channel
+// funds, once one has been configured.
+var chanAccountKey = []byte("ln-chan-account")
+
+// fetchChanAccount returns the number of the account previously dedicated to
+// channel funds, and whether one has been.
+func fetchChanAccount(ns walletdb.Namespace) (uint32, bool, error) {
+	var (
+		account uint32
+		found   bool
+	)
+	err := ns.View(func(tx walletdb.Tx) error {
+		accountBytes := tx.RootBucket().Get(chanAccountKey)
+		if accountBytes == nil {
+			return nil
+		}
+
+		account = binary.BigEndian.Uint32(accountBytes)
+		found = true
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return account, found, nil
+}
+
+// putChanAccount records the number of the account dedicated to channel
+// funds.
+func putChanAccount(ns walletdb.Namespace, account uint32) error {
+	return ns.Update(func(tx walletdb.Tx) error {
+		var accountBytes [4]byte
+		binary.BigEndian.PutUint32(accountBytes[:], account)
+
+		return tx.RootBucket().Put(chanAccountKey, accountBytes[:])
+	})
+}
+
+// migrateChanAccount returns the number of the account holding the keys,
+// change, and sweeps of channels, given the configured account name. If no
+// name is configured, then channel funds share the default account.
+//
+// Once an account has been dedicated to channel funds, it's recorded within
+// the ln namespace. Should the configured name later change, then the
+// recorded account is renamed rather than a fresh one created, so funds
+// already separated remain within a single account. Note that the funds of
+// channels opened before an account was configured remain within the default
+// account until the channels are closed, as their keys were derived from it.
+func migrateChanAccount(wallet *base.Wallet, ns walletdb.Namespace,
+	name string) (uint32, error) {
+
+	if name == "" {
+		return defaultAccount, nil
+	}
+
+	account, found, err := fetchChanAccount(ns)
+	if err != nil {
+		return 0, err
+	}
+	if found {
+		currentName, err := wallet.Manager.AccountName(account)
+		if err != nil {
+			return 0, err
+		}
+		if currentName != name {
+			err := wallet.RenameAccount(account, name)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		return account, nil
+	}
+
+	// No account has been dedicated yet, so either adopt an existing
+	// account of the configured name, or create it.
+	account, err = wallet.Manager.LookupAccount(name)
+	switch {
+	case waddrmgr.IsError(err, waddrmgr.ErrAccountNotFound):
+		account, err = wallet.NextAccount(name)
+		if err != nil {
+			return 0, err
+		}
+	case err != nil:
+		return 0, err
+	}
+
+	if err := putChanAccount(ns, account); err != nil {
+		return 0, err
+	}
+
+	return account, nil
+}
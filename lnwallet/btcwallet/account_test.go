@@ -0,0 +1,76 @@
+package btcwallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roasbeef/btcwallet/walletdb"
+)
+
+// TestChanAccount tests that channel funds share the default account unless
+// an account name is configured, and that the account dedicated to channel
+// funds persists once the database is reopened.
+func TestChanAccount(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "chanaccount")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	dbPath := filepath.Join(tempDir, "wallet.db")
+
+	openNamespace := func(create bool) (walletdb.DB, walletdb.Namespace) {
+		var db walletdb.DB
+		if create {
+			db, err = walletdb.Create("bdb", dbPath)
+		} else {
+			db, err = walletdb.Open("bdb", dbPath)
+		}
+		if err != nil {
+			t.Fatalf("unable to open db: %v", err)
+		}
+		ns, err := db.Namespace(lnNamespace)
+		if err != nil {
+			t.Fatalf("unable to open namespace: %v", err)
+		}
+		return db, ns
+	}
+
+	db, ns := openNamespace(true)
+
+	// Without a configured name, the default account is used, and
+	// nothing is recorded within the namespace.
+	account, err := migrateChanAccount(nil, ns, "")
+	if err != nil {
+		t.Fatalf("unable to migrate channel account: %v", err)
+	}
+	if account != defaultAccount {
+		t.Fatalf("expected default account %v, got %v", defaultAccount,
+			account)
+	}
+	_, found, err := fetchChanAccount(ns)
+	if err != nil {
+		t.Fatalf("unable to fetch channel account: %v", err)
+	}
+	if found {
+		t.Fatalf("channel account recorded without a configured name")
+	}
+
+	if err := putChanAccount(ns, 3); err != nil {
+		t.Fatalf("unable to record channel account: %v", err)
+	}
+	db.Close()
+
+	db, ns = openNamespace(false)
+	defer db.Close()
+
+	account, found, err = fetchChanAccount(ns)
+	if err != nil {
+		t.Fatalf("unable to fetch channel account: %v", err)
+	}
+	if !found || account != 3 {
+		t.Fatalf("expected channel account 3, got %v (found=%v)",
+			account, found)
+	}
+}
@@ -3,7 +3,10 @@ package btcwallet
 import (
 	"encoding/hex"
 
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/btcjson"
 	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
 )
 
 // GetCurrentHeight returns the current height of the known block within the
@@ -20,23 +23,50 @@ func (b *BtcWallet) GetCurrentHeight() (int32, error) {
 }
 
 // GetTxOut returns the original output referenced by the passed outpoint.
+// The mempool is taken into account, so outputs spent within it are
+// reported as spent, and outputs created within it as unconfirmed.
 //
 // This method is a part of the lnwallet.BlockChainIO interface.
 func (b *BtcWallet) GetUtxo(txid *wire.ShaHash, index uint32) (*wire.TxOut, error) {
-	txout, err := b.rpc.GetTxOut(txid, index, false)
+	txout, err := b.rpc.GetTxOut(txid, index, true)
 	if err != nil {
 		return nil, err
 	}
+	switch {
+	case txout != nil && txout.Confirmations == 0:
+		return nil, lnwallet.ErrOutputUnconfirmed
+	case txout != nil:
+		return txOutFromResult(txout)
+	}
+
+	// A spent, or nonexistent, output are both reported by gettxout as a
+	// null result. The output has only been spent if the transaction
+	// creating it is still known, within either the chain or the mempool.
+	tx, err := b.rpc.GetRawTransaction(txid)
+	if err != nil || int(index) >= len(tx.MsgTx().TxOut) {
+		return nil, lnwallet.ErrOutputNotFound
+	}
+
+	return nil, lnwallet.ErrOutputSpent
+}
 
+// txOutFromResult converts the result of a gettxout call into the output it
+// describes.
+func txOutFromResult(txout *btcjson.GetTxOutResult) (*wire.TxOut, error) {
 	pkScript, err := hex.DecodeString(txout.ScriptPubKey.Hex)
 	if err != nil {
 		return nil, err
 	}
 
+	// Sadly, gettxout returns the output value in BTC instead of
+	// satoshis, so it's converted back, rounding to the nearest satoshi.
+	value, err := btcutil.NewAmount(txout.Value)
+	if err != nil {
+		return nil, err
+	}
+
 	return &wire.TxOut{
-		// Sadly, gettxout returns the output value in BTC
-		// instead of satoshis.
-		Value:    int64(txout.Value) * 1e8,
+		Value:    int64(value),
 		PkScript: pkScript,
 	}, nil
 }
@@ -53,3 +83,23 @@ func (b *BtcWallet) GetTransaction(txid *wire.ShaHash) (*wire.MsgTx, error) {
 
 	return tx.MsgTx(), nil
 }
+
+// GetBlock returns the full block identified by the passed block hash.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (b *BtcWallet) GetBlock(blockHash *wire.ShaHash) (*wire.MsgBlock, error) {
+	block, err := b.rpc.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.MsgBlock(), nil
+}
+
+// GetBlockHash returns the hash of the block within the main chain at the
+// passed height.
+//
+// This method is a part of the lnwallet.BlockChainIO interface.
+func (b *BtcWallet) GetBlockHash(blockHeight int64) (*wire.ShaHash, error) {
+	return b.rpc.GetBlockHash(blockHeight)
+}
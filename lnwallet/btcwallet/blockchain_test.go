@@ -0,0 +1,90 @@
+package btcwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/btcjson"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestTxOutFromResult tests that the BTC denominated value returned by
+// gettxout is converted back to satoshis without truncating the fractional
+// part of the value.
+func TestTxOutFromResult(t *testing.T) {
+	testCases := []struct {
+		value    float64
+		expected int64
+	}{
+		{value: 0.00012345, expected: 12345},
+		{value: 0.1, expected: 10000000},
+		{value: 1.23456789, expected: 123456789},
+		{value: 50, expected: 5000000000},
+	}
+	for _, test := range testCases {
+		txOut, err := txOutFromResult(&btcjson.GetTxOutResult{
+			Value: test.value,
+			ScriptPubKey: btcjson.ScriptPubKeyResult{
+				Hex: "0014",
+			},
+		})
+		if err != nil {
+			t.Fatalf("unable to convert result: %v", err)
+		}
+		if txOut.Value != test.expected {
+			t.Fatalf("expected value of %v satoshis for %v BTC, "+
+				"got %v", test.expected, test.value, txOut.Value)
+		}
+		if !bytes.Equal(txOut.PkScript, []byte{0x00, 0x14}) {
+			t.Fatalf("unexpected pkScript %x", txOut.PkScript)
+		}
+	}
+}
+
+// TestRecoveredFundingFromResult tests that the funding output of a recovered
+// channel, as reported by gettxout, passes verification once converted, even
+// though its satoshi value is a fraction of a BTC.
+func TestRecoveredFundingFromResult(t *testing.T) {
+	const btcCapacity = 12345
+
+	var keys [2][]byte
+	for i := range keys {
+		priv, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		keys[i] = priv.PubKey().SerializeCompressed()
+	}
+	redeemScript, fundingOut, err := lnwallet.GenFundingPkScript(keys[0],
+		keys[1], btcCapacity)
+	if err != nil {
+		t.Fatalf("unable to create funding script: %v", err)
+	}
+
+	txOut, err := txOutFromResult(&btcjson.GetTxOutResult{
+		Value: 0.00012345,
+		ScriptPubKey: btcjson.ScriptPubKeyResult{
+			Hex: hex.EncodeToString(fundingOut.PkScript),
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to convert result: %v", err)
+	}
+
+	state := &channeldb.OpenChannel{
+		FundingOutpoint:     &wire.OutPoint{Index: 1},
+		FundingRedeemScript: redeemScript,
+		BtcCapacity:         btcCapacity,
+		AssetID:             "asset",
+		AssetCapacity:       300,
+	}
+	txoData := &lndcc.TxoData{AssetId: "asset", Value: 300}
+	if err := lnwallet.CheckRecoveredFunding(txOut, state, txoData); err != nil {
+		t.Fatalf("funding output rejected: %v", err)
+	}
+}
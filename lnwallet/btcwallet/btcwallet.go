@@ -1,6 +1,7 @@
 package btcwallet
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"math"
@@ -46,10 +47,19 @@ type BtcWallet struct {
 
 	netParams *chaincfg.Params
 
+	// chanAccount is the account holding the keys, change, and sweeps of
+	// channels. Unless a dedicated account has been configured, this is
+	// the default account.
+	chanAccount uint32
+
 	// utxoCache is a cache used to speed up repeated calls to
 	// FetchInputInfo.
 	utxoCache map[wire.OutPoint]*wire.TxOut
 	cacheMtx  sync.RWMutex
+
+	// dirLock is the exclusive lock held on the wallet's data directory
+	// until the wallet is stopped.
+	dirLock *dirLock
 }
 
 // A compile time check to ensure that BtcWallet implements the
@@ -59,9 +69,29 @@ var _ lnwallet.WalletController = (*BtcWallet)(nil)
 // New returns a new fully initialized instance of BtcWallet given a valid
 // confirguration struct.
 func New(cfg *Config) (*BtcWallet, error) {
-	// Ensure the wallet exists or create it when the create flag is set.
 	netDir := networkDir(cfg.DataDir, cfg.NetParams)
 
+	// Before the wallet's database is opened, ensure no other instance
+	// is running against it.
+	lock, err := lockDataDir(netDir)
+	if err != nil {
+		return nil, err
+	}
+
+	btcWallet, err := newBtcWallet(cfg, netDir)
+	if err != nil {
+		lock.release()
+		return nil, err
+	}
+	btcWallet.dirLock = lock
+
+	return btcWallet, nil
+}
+
+// newBtcWallet opens, or creates the wallet within the passed network
+// directory, and connects to the configured btcd node.
+func newBtcWallet(cfg *Config, netDir string) (*BtcWallet, error) {
+	// Ensure the wallet exists or create it when the create flag is set.
 	var pubPass []byte
 	if cfg.PublicPass == nil {
 		pubPass = defaultPubPassphrase
@@ -110,11 +140,32 @@ func New(cfg *Config) (*BtcWallet, error) {
 		return nil, err
 	}
 
+	chanAccount, err := migrateChanAccount(wallet, walletNamespace,
+		cfg.ChannelAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	// Outputs locked before the wallet was last closed are locked once
+	// again, as the funding transactions spending them may yet be
+	// broadcast.
+	if err := createLockedOutputsBucket(walletNamespace); err != nil {
+		return nil, err
+	}
+	lockedOutputs, err := fetchLockedOutputs(walletNamespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range lockedOutputs {
+		wallet.LockOutpoint(o)
+	}
+
 	return &BtcWallet{
 		wallet:      wallet,
 		rpc:         rpcc,
 		lnNamespace: walletNamespace,
 		netParams:   cfg.NetParams,
+		chanAccount: chanAccount,
 		utxoCache:   make(map[wire.OutPoint]*wire.TxOut),
 	}, nil
 }
@@ -151,7 +202,7 @@ func (b *BtcWallet) Stop() error {
 
 	b.rpc.Shutdown()
 
-	return nil
+	return b.dirLock.release()
 }
 
 // ConfirmedBalance returns the sum of all the wallet's unspent outputs that
@@ -191,6 +242,25 @@ func (b *BtcWallet) ConfirmedBalance(confs int32, witness bool) (btcutil.Amount,
 //
 // This is a part of the WalletController interface.
 func (b *BtcWallet) NewAddress(t lnwallet.AddressType, change bool) (btcutil.Address, error) {
+	return b.newAddress(defaultAccount, t, change)
+}
+
+// NewChannelAddress returns the next external or internal address for the
+// wallet, within the account holding the funds of channels, such as the
+// change of funding transactions, delivery addresses, and sweeps.
+//
+// This is a part of the WalletController interface.
+func (b *BtcWallet) NewChannelAddress(t lnwallet.AddressType,
+	change bool) (btcutil.Address, error) {
+
+	return b.newAddress(b.chanAccount, t, change)
+}
+
+// newAddress returns the next external or internal address of the passed
+// account.
+func (b *BtcWallet) newAddress(account uint32, t lnwallet.AddressType,
+	change bool) (btcutil.Address, error) {
+
 	var addrType waddrmgr.AddressType
 
 	switch t {
@@ -205,9 +275,9 @@ func (b *BtcWallet) NewAddress(t lnwallet.AddressType, change bool) (btcutil.Add
 	}
 
 	if change {
-		return b.wallet.NewAddress(defaultAccount, addrType)
+		return b.wallet.NewAddress(account, addrType)
 	} else {
-		return b.wallet.NewChangeAddress(defaultAccount, addrType)
+		return b.wallet.NewChangeAddress(account, addrType)
 	}
 }
 
@@ -233,7 +303,7 @@ func (b *BtcWallet) GetPrivKey(a btcutil.Address) (*btcec.PrivateKey, error) {
 //
 // This is a part of the WalletController interface.
 func (b *BtcWallet) NewRawKey() (*btcec.PublicKey, error) {
-	nextAddr, err := b.wallet.Manager.NextExternalAddresses(defaultAccount,
+	nextAddr, err := b.wallet.Manager.NextExternalAddresses(b.chanAccount,
 		1, waddrmgr.WitnessPubKey)
 	if err != nil {
 		return nil, err
@@ -266,7 +336,7 @@ func (b *BtcWallet) FetchRootKey() (*btcec.PrivateKey, error) {
 		// Otherwise, we need to generate a fresh address from the
 		// wallet, then stores it's hash160 within the database so we
 		// can look up the exact key later.
-		rootAddr, err := b.wallet.Manager.NextExternalAddresses(defaultAccount,
+		rootAddr, err := b.wallet.Manager.NextExternalAddresses(b.chanAccount,
 			1, waddrmgr.WitnessPubKey)
 		if err != nil {
 			return nil, err
@@ -314,19 +384,38 @@ func (b *BtcWallet) SendOutputs(outputs []*wire.TxOut) (*wire.ShaHash, error) {
 // LockOutpoint marks an outpoint as locked meaning it will no longer be deemed
 // as eligible for coin selection. Locking outputs are utilized in order to
 // avoid race conditions when selecting inputs for usage when funding a
-// channel.
+// channel. The lock is recorded within the ln namespace, so it persists
+// across restarts.
 //
 // This is a part of the WalletController interface.
-func (b *BtcWallet) LockOutpoint(o wire.OutPoint) {
+func (b *BtcWallet) LockOutpoint(o wire.OutPoint) error {
+	if err := putLockedOutput(b.lnNamespace, o); err != nil {
+		return err
+	}
+
 	b.wallet.LockOutpoint(o)
+	return nil
 }
 
 // UnlockOutpoint unlocks an previously locked output, marking it eligible for
 // coin seleciton.
 //
 // This is a part of the WalletController interface.
-func (b *BtcWallet) UnlockOutpoint(o wire.OutPoint) {
+func (b *BtcWallet) UnlockOutpoint(o wire.OutPoint) error {
+	if err := deleteLockedOutput(b.lnNamespace, o); err != nil {
+		return err
+	}
+
 	b.wallet.UnlockOutpoint(o)
+	return nil
+}
+
+// ListLockedOutpoints returns all currently locked outpoints, including those
+// locked before the wallet was last restarted.
+//
+// This is a part of the WalletController interface.
+func (b *BtcWallet) ListLockedOutpoints() ([]wire.OutPoint, error) {
+	return fetchLockedOutputs(b.lnNamespace)
 }
 
 // ListUnspentWitness returns a slice of all the unspent outputs the wallet
@@ -350,10 +439,19 @@ func (b *BtcWallet) ListUnspentWitness(minConfs int32) ([]*lnwallet.Utxo, error)
 			return nil, err
 		}
 
-		// TODO(roasbeef): this assumes all p2sh outputs returned by
-		// the wallet are nested p2sh...
-		if txscript.IsPayToWitnessPubKeyHash(pkScript) ||
-			txscript.IsPayToScriptHash(pkScript) {
+		// Legacy p2sh outputs can't be used to fund a channel as the
+		// resulting funding transaction would be malleable, so we
+		// only accept p2sh outputs which actually nest a witness
+		// program we control.
+		isWitnessOutput := txscript.IsPayToWitnessPubKeyHash(pkScript)
+		if !isWitnessOutput && txscript.IsPayToScriptHash(pkScript) {
+			isWitnessOutput, err = b.isNestedWitnessOutput(pkScript)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if isWitnessOutput {
 			txid, err := wire.NewShaHashFromStr(output.TxID)
 			if err != nil {
 				return nil, err
@@ -380,6 +478,76 @@ func (b *BtcWallet) ListUnspentWitness(minConfs int32) ([]*lnwallet.Utxo, error)
 	return witnessOutputs, nil
 }
 
+// IsSynced returns a boolean indicating if from the PoV of the wallet, it has
+// fully synced to the current best block in the main chain.
+//
+// This is a part of the WalletController interface.
+func (b *BtcWallet) IsSynced() (bool, error) {
+	// Grab the best chain state the wallet is currently aware of.
+	walletBestHash, walletBestHeight, err := b.BestBlock()
+	if err != nil {
+		return false, err
+	}
+
+	// Next, query the chain backend to grab the info about the tip of the
+	// main chain.
+	bestHash, bestHeight, err := b.rpc.GetBestBlock()
+	if err != nil {
+		return false, err
+	}
+
+	// If the wallet hasn't yet fully synced to the node's best chain tip,
+	// then we're not yet fully synced.
+	if walletBestHeight != bestHeight {
+		return false, nil
+	}
+
+	return walletBestHash.IsEqual(bestHash), nil
+}
+
+// BestBlock returns the hash and height of the block the wallet is currently
+// synced to.
+//
+// This is a part of the WalletController interface.
+func (b *BtcWallet) BestBlock() (*wire.ShaHash, int32, error) {
+	syncState := b.wallet.Manager.SyncedTo()
+	return &syncState.Hash, syncState.Height, nil
+}
+
+// isNestedWitnessOutput returns true if the passed p2sh output script
+// commits to a p2wkh witness program controlled by the wallet. The redeem
+// script is reconstructed from the matching address within the address
+// manager, then hashed and compared against the script hash of the output.
+func (b *BtcWallet) isNestedWitnessOutput(pkScript []byte) (bool, error) {
+	walletAddr, err := b.fetchOutputAddr(pkScript)
+	if err != nil {
+		// If the address isn't known to the address manager, then we
+		// have no way of determining the redeem script.
+		return false, nil
+	}
+
+	pka, ok := walletAddr.(waddrmgr.ManagedPubKeyAddress)
+	if !ok || !pka.IsNestedWitness() {
+		return false, nil
+	}
+
+	pubKeyHash := btcutil.Hash160(pka.PubKey().SerializeCompressed())
+	p2wkhAddr, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash,
+		b.netParams)
+	if err != nil {
+		return false, err
+	}
+	witnessProgram, err := txscript.PayToAddrScript(p2wkhAddr)
+	if err != nil {
+		return false, err
+	}
+
+	// The output script is of the form: OP_HASH160 <20-byte hash> OP_EQUAL,
+	// so the script hash lives at bytes [2:22].
+	redeemHash := btcutil.Hash160(witnessProgram)
+	return bytes.Equal(pkScript[2:22], redeemHash), nil
+}
+
 // PublishTransaction performs cursory validation (dust checks, etc), then
 // finally broadcasts the passed transaction to the Bitcoin network.
 func (b *BtcWallet) PublishTransaction(tx *wire.MsgTx) error {
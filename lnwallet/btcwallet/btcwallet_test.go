@@ -0,0 +1,157 @@
+package btcwallet
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/chaincfg"
+	"github.com/roasbeef/btcd/rpctest"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var testHdSeed = [32]byte{
+	0xb7, 0x94, 0x38, 0x5f, 0x2d, 0x1e, 0xf7, 0xab,
+	0x4d, 0x92, 0x73, 0xd1, 0x90, 0x63, 0x81, 0xb4,
+	0x4f, 0x2f, 0x6f, 0x25, 0x88, 0xa3, 0xef, 0xb9,
+	0x6a, 0x49, 0x18, 0x83, 0x31, 0x98, 0x47, 0x53,
+}
+
+// TestListUnspentWitnessLegacyP2SH tests that of the p2sh outputs controlled
+// by the wallet, only those nesting a witness program are eligible to fund a
+// channel, while legacy p2sh outputs are left out.
+func TestListUnspentWitnessLegacyP2SH(t *testing.T) {
+	netParams := &chaincfg.SimNetParams
+
+	miningNode, err := rpctest.New(netParams, nil, nil)
+	defer miningNode.TearDown()
+	if err != nil {
+		t.Fatalf("unable to create mining node: %v", err)
+	}
+	if err := miningNode.SetUp(true, 25); err != nil {
+		t.Fatalf("unable to set up mining node: %v", err)
+	}
+	rpcConfig := miningNode.RPCConfig()
+
+	tempTestDir, err := ioutil.TempDir("", "btcwallet")
+	if err != nil {
+		t.Fatalf("unable to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempTestDir)
+
+	wallet, err := New(&Config{
+		PrivatePass: []byte("private-test"),
+		HdSeed:      testHdSeed[:],
+		DataDir:     tempTestDir,
+		NetParams:   netParams,
+		RpcHost:     rpcConfig.Host,
+		RpcUser:     rpcConfig.User,
+		RpcPass:     rpcConfig.Pass,
+		CACert:      rpcConfig.Certificates,
+	})
+	if err != nil {
+		t.Fatalf("unable to create wallet: %v", err)
+	}
+	if err := wallet.Start(); err != nil {
+		t.Fatalf("unable to start wallet: %v", err)
+	}
+	defer wallet.Stop()
+
+	// Import a legacy 1-of-1 multi-sig redeem script into the wallet, so
+	// it controls a p2sh output which doesn't nest a witness program.
+	pubKey, err := wallet.NewRawKey()
+	if err != nil {
+		t.Fatalf("unable to create key: %v", err)
+	}
+	pubKeyAddr, err := btcutil.NewAddressPubKey(pubKey.SerializeCompressed(),
+		netParams)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	redeemScript, err := txscript.MultiSigScript(
+		[]*btcutil.AddressPubKey{pubKeyAddr}, 1)
+	if err != nil {
+		t.Fatalf("unable to create redeem script: %v", err)
+	}
+	bs := wallet.wallet.Manager.SyncedTo()
+	scriptAddr, err := wallet.wallet.Manager.ImportScript(redeemScript, &bs)
+	if err != nil {
+		t.Fatalf("unable to import script: %v", err)
+	}
+	err = wallet.rpc.NotifyReceived([]btcutil.Address{scriptAddr.Address()})
+	if err != nil {
+		t.Fatalf("unable to watch script address: %v", err)
+	}
+
+	nestedAddr, err := wallet.NewAddress(lnwallet.NestedWitnessPubKey, false)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+
+	// Pay both the nested witness address, and the legacy p2sh address
+	// within a single transaction.
+	var outputs []*wire.TxOut
+	for _, addr := range []btcutil.Address{nestedAddr, scriptAddr.Address()} {
+		script, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			t.Fatalf("unable to create output script: %v", err)
+		}
+		outputs = append(outputs, wire.NewTxOut(1e8, script))
+	}
+	txid, err := miningNode.CoinbaseSpend(outputs)
+	if err != nil {
+		t.Fatalf("unable to pay wallet: %v", err)
+	}
+	if _, err := miningNode.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block: %v", err)
+	}
+
+	// Wait until the wallet has picked up both outputs.
+	nestedOutPoint := wire.OutPoint{Hash: *txid, Index: 0}
+	legacyOutPoint := wire.OutPoint{Hash: *txid, Index: 1}
+	timeout := time.After(10 * time.Second)
+	for {
+		unspent, err := wallet.wallet.ListUnspent(1, math.MaxInt32, nil)
+		if err != nil {
+			t.Fatalf("unable to list unspent outputs: %v", err)
+		}
+		var numFound int
+		for _, output := range unspent {
+			if output.TxID == txid.String() {
+				numFound++
+			}
+		}
+		if numFound == 2 {
+			break
+		}
+
+		select {
+		case <-timeout:
+			t.Fatalf("wallet didn't detect the payments, found %v of 2",
+				numFound)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	utxos, err := wallet.ListUnspentWitness(1)
+	if err != nil {
+		t.Fatalf("unable to list unspent witness outputs: %v", err)
+	}
+	var nestedFound bool
+	for _, utxo := range utxos {
+		switch utxo.OutPoint {
+		case nestedOutPoint:
+			nestedFound = true
+		case legacyOutPoint:
+			t.Fatalf("legacy p2sh output listed as witness output")
+		}
+	}
+	if !nestedFound {
+		t.Fatalf("nested witness output not listed")
+	}
+}
@@ -69,6 +69,11 @@ type Config struct {
 	// CACert is the raw RPC cert for btcd.
 	CACert []byte
 
+	// ChannelAccount is the name of the account dedicated to the keys,
+	// change, and sweeps of channels, separating channel collateral from
+	// general funds. If empty, channel funds share the default account.
+	ChannelAccount string
+
 	PrivatePass []byte
 	PublicPass  []byte
 	HdSeed      []byte
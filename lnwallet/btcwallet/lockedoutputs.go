@@ -0,0 +1,94 @@
+package btcwallet
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcwallet/walletdb"
+)
+
+// lockedOutputsBucket is the bucket within the ln namespace storing each
+// outpoint locked for use within a funding transaction. btcwallet only tracks
+// locked outputs in memory, so they're recorded here, and locked once again
+// each time the wallet is opened.
+var lockedOutputsBucket = []byte("ln-locked-outputs")
+
+// outPointKeySize is the size of a serialized outpoint: the txid (32), and
+// the output index (4).
+const outPointKeySize = wire.HashSize + 4
+
+// outPointKey serializes the passed outpoint as a key within the
+// lockedOutputsBucket.
+func outPointKey(o wire.OutPoint) []byte {
+	var key [outPointKeySize]byte
+	copy(key[:], o.Hash[:])
+	binary.BigEndian.PutUint32(key[wire.HashSize:], o.Index)
+	return key[:]
+}
+
+// createLockedOutputsBucket creates the lockedOutputsBucket within the ln
+// namespace if it doesn't yet exist. Wallets created before outputs were
+// recorded as locked lack the bucket, so it's created each time the wallet
+// is opened.
+func createLockedOutputsBucket(ns walletdb.Namespace) error {
+	return ns.Update(func(tx walletdb.Tx) error {
+		_, err := tx.RootBucket().CreateBucketIfNotExists(
+			lockedOutputsBucket)
+		return err
+	})
+}
+
+// putLockedOutput records the passed outpoint as locked.
+func putLockedOutput(ns walletdb.Namespace, o wire.OutPoint) error {
+	return ns.Update(func(tx walletdb.Tx) error {
+		bucket, err := tx.RootBucket().CreateBucketIfNotExists(
+			lockedOutputsBucket)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(outPointKey(o), []byte{})
+	})
+}
+
+// deleteLockedOutput removes the passed outpoint from the set of locked
+// outputs. Removing an outpoint which isn't locked is a no-op.
+func deleteLockedOutput(ns walletdb.Namespace, o wire.OutPoint) error {
+	return ns.Update(func(tx walletdb.Tx) error {
+		bucket := tx.RootBucket().Bucket(lockedOutputsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Delete(outPointKey(o))
+	})
+}
+
+// fetchLockedOutputs returns all outpoints recorded as locked.
+func fetchLockedOutputs(ns walletdb.Namespace) ([]wire.OutPoint, error) {
+	var outPoints []wire.OutPoint
+	err := ns.View(func(tx walletdb.Tx) error {
+		bucket := tx.RootBucket().Bucket(lockedOutputsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			if len(k) != outPointKeySize {
+				return fmt.Errorf("invalid locked output key %x", k)
+			}
+
+			var o wire.OutPoint
+			copy(o.Hash[:], k[:wire.HashSize])
+			o.Index = binary.BigEndian.Uint32(k[wire.HashSize:])
+			outPoints = append(outPoints, o)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outPoints, nil
+}
@@ -0,0 +1,80 @@
+package btcwallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcwallet/walletdb"
+)
+
+// TestLockedOutputs tests that locked outputs recorded within the ln
+// namespace persist once the database is reopened, until they're removed.
+func TestLockedOutputs(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "lockedoutputs")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+	dbPath := filepath.Join(tempDir, "wallet.db")
+
+	openNamespace := func(create bool) (walletdb.DB, walletdb.Namespace) {
+		var db walletdb.DB
+		if create {
+			db, err = walletdb.Create("bdb", dbPath)
+		} else {
+			db, err = walletdb.Open("bdb", dbPath)
+		}
+		if err != nil {
+			t.Fatalf("unable to open db: %v", err)
+		}
+		ns, err := db.Namespace(lnNamespace)
+		if err != nil {
+			t.Fatalf("unable to open namespace: %v", err)
+		}
+		if err := createLockedOutputsBucket(ns); err != nil {
+			t.Fatalf("unable to create bucket: %v", err)
+		}
+		return db, ns
+	}
+
+	db, ns := openNamespace(true)
+
+	// Nothing is locked within a fresh namespace, and removing an
+	// outpoint which isn't locked is a no-op.
+	outPoints, err := fetchLockedOutputs(ns)
+	if err != nil {
+		t.Fatalf("unable to fetch locked outputs: %v", err)
+	}
+	if len(outPoints) != 0 {
+		t.Fatalf("expected no locked outputs, got %v", outPoints)
+	}
+	if err := deleteLockedOutput(ns, wire.OutPoint{}); err != nil {
+		t.Fatalf("unable to delete unlocked output: %v", err)
+	}
+
+	first := wire.OutPoint{Hash: wire.ShaHash{1}, Index: 0}
+	second := wire.OutPoint{Hash: wire.ShaHash{1}, Index: 1 << 20}
+	for _, o := range []wire.OutPoint{first, second} {
+		if err := putLockedOutput(ns, o); err != nil {
+			t.Fatalf("unable to lock output: %v", err)
+		}
+	}
+	if err := deleteLockedOutput(ns, first); err != nil {
+		t.Fatalf("unable to unlock output: %v", err)
+	}
+	db.Close()
+
+	db, ns = openNamespace(false)
+	defer db.Close()
+
+	outPoints, err = fetchLockedOutputs(ns)
+	if err != nil {
+		t.Fatalf("unable to fetch locked outputs: %v", err)
+	}
+	if len(outPoints) != 1 || outPoints[0] != second {
+		t.Fatalf("expected only %v locked, got %v", second, outPoints)
+	}
+}
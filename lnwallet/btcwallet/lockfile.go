@@ -0,0 +1,98 @@
+package btcwallet
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// lockFileName is the name of the lock file created within the wallet's
+// network directory.
+const lockFileName = "lnwallet.lock"
+
+var (
+	// ErrWalletInUse is returned when the wallet's data directory is
+	// locked by another running instance.
+	ErrWalletInUse = errors.New("wallet data directory is in use by " +
+		"another process")
+
+	// errLockHeld is returned by tryLockFile if the lock is held by
+	// another process.
+	errLockHeld = errors.New("lock held by another process")
+)
+
+// dirLock is an exclusive, cross-process lock on the wallet's data directory.
+// Running two instances against the same btcwallet database corrupts the
+// state of their channels, so the lock is held for the lifetime of the
+// wallet. As the lock is released by the OS once the holding process exits,
+// a stale lock file left behind by a crash doesn't prevent startup.
+type dirLock struct {
+	file *os.File
+}
+
+// lockDataDir acquires the lock on the passed wallet directory, creating the
+// directory if it doesn't yet exist. If another process holds the lock, then
+// ErrWalletInUse is returned, along with the ID of the holding process if
+// known.
+func lockDataDir(dir string) (*dirLock, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, lockFileName)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	switch err := tryLockFile(file); {
+	case err == errLockHeld:
+		file.Close()
+
+		// The holder records its process ID within the lock file,
+		// which we include within the error to aid the operator.
+		pid, _ := ioutil.ReadFile(path)
+		holder := strings.TrimSpace(string(pid))
+		if holder == "" {
+			return nil, fmt.Errorf("%v: %v", ErrWalletInUse, dir)
+		}
+		return nil, fmt.Errorf("%v: %v is locked by pid %v",
+			ErrWalletInUse, dir, holder)
+
+	case err != nil:
+		file.Close()
+		return nil, err
+	}
+
+	// With the lock acquired, record our process ID in place of any
+	// left behind by a prior holder.
+	if err := file.Truncate(0); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, err
+	}
+	pid := strconv.Itoa(os.Getpid()) + "\n"
+	if _, err := file.WriteAt([]byte(pid), 0); err != nil {
+		unlockFile(file)
+		file.Close()
+		return nil, err
+	}
+
+	return &dirLock{file: file}, nil
+}
+
+// release releases the lock on the wallet directory. The lock file itself is
+// left in place, as removing it could race with another process acquiring
+// the lock.
+func (d *dirLock) release() error {
+	if err := unlockFile(d.file); err != nil {
+		d.file.Close()
+		return err
+	}
+
+	return d.file.Close()
+}
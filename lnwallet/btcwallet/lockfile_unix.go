@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package btcwallet
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLockFile attempts to acquire an exclusive advisory lock on the passed
+// file without blocking, returning errLockHeld if another process holds it.
+func tryLockFile(file *os.File) error {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return errLockHeld
+	}
+
+	return err
+}
+
+// unlockFile releases the lock held on the passed file.
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
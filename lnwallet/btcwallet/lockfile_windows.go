@@ -0,0 +1,20 @@
+//go:build windows
+// +build windows
+
+package btcwallet
+
+import "os"
+
+// tryLockFile is a no-op on Windows, where advisory file locks aren't
+// available via the standard library. The btcwallet database is still
+// protected by the lock bolt itself holds while it's open.
+//
+// TODO: use LockFileEx once golang.org/x/sys is vendored.
+func tryLockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on Windows.
+func unlockFile(file *os.File) error {
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwallet/keychain"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
@@ -103,9 +104,17 @@ func (b *BtcWallet) SignOutputRaw(tx *wire.MsgTx, signDesc *lnwallet.SignDescrip
 		return nil, err
 	}
 
+	// If a tweak was specified, then the signature is to be generated
+	// under the private key derived from the fetched private key.
+	if signDesc.PrivateTweak != nil {
+		privKey = keychain.DeriveRevocationPrivKey(privKey,
+			signDesc.PrivateTweak)
+	}
+
 	amt := signDesc.Output.Value
-	sig, err := txscript.RawTxInWitnessSignature(tx, signDesc.SigHashes, 0,
-		amt, redeemScript, txscript.SigHashAll, privKey)
+	sig, err := txscript.RawTxInWitnessSignature(tx, signDesc.SigHashes,
+		signDesc.InputIndex, amt, redeemScript, txscript.SigHashAll,
+		privKey)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,151 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/lndcc"
+)
+
+// ErrFundingColorMismatch is returned when loading a channel whose funding
+// output doesn't carry the asset capacity recorded within the channel's
+// persisted state.
+var ErrFundingColorMismatch = errors.New("funding output color data " +
+	"doesn't match the channel state")
+
+// validateFundingOutput validates the persisted state of the channel against
+// the chain. If the funding output was spent while we were offline, and the
+// spending transaction is found within the chain, the channel is flagged,
+// and the spend is handed off to the close observer. Otherwise, the color
+// data of the funding output must match the asset capacity of the channel,
+// unless the funding transaction has yet to confirm.
+func (lc *LightningChannel) validateFundingOutput() error {
+	state := lc.channelState
+	fundingPoint := state.FundingOutpoint
+
+	_, err := lc.bio.GetUtxo(&fundingPoint.Hash, fundingPoint.Index)
+	switch {
+	case err == ErrOutputSpent:
+		// The output may have only been spent within the mempool, so
+		// the channel is only flagged once the spend is found within
+		// the chain. Otherwise, the close observer is notified of the
+		// spend as it confirms.
+		spend, err := lc.findFundingSpend()
+		switch {
+		case err != nil:
+			walletLog.Errorf("ChannelPoint(%v): unable to locate "+
+				"spend of funding output: %v", fundingPoint, err)
+			return nil
+
+		case spend == nil:
+			walletLog.Warnf("ChannelPoint(%v): funding output is "+
+				"spent, but the spending transaction wasn't "+
+				"found within the chain", fundingPoint)
+			return nil
+		}
+
+		walletLog.Warnf("ChannelPoint(%v): funding output was spent "+
+			"while offline", fundingPoint)
+
+		lc.FundingSpentOffline = true
+		lc.dispatchUnilateralClose(spend)
+		return nil
+
+	// The funding transaction of a zero-conf channel may still be within
+	// the mempool, so there's nothing to validate the channel against yet.
+	case err == ErrOutputUnconfirmed:
+		walletLog.Infof("ChannelPoint(%v): funding transaction is "+
+			"unconfirmed, skipping validation against the chain",
+			fundingPoint)
+		lc.FundingUnconfirmed = true
+		return nil
+
+	// The funding transaction is unknown, as it's been re-orged out, so
+	// there's nothing to validate the channel against.
+	case err == ErrOutputNotFound:
+		walletLog.Warnf("ChannelPoint(%v): funding transaction not "+
+			"found, skipping validation against the chain",
+			fundingPoint)
+		return nil
+
+	case err != nil:
+		walletLog.Warnf("ChannelPoint(%v): unable to fetch funding "+
+			"output, skipping validation against the chain: %v",
+			fundingPoint, err)
+		return nil
+	}
+
+	txoData, err := lndcc.GetTxoData(*fundingPoint)
+	if err != nil {
+		walletLog.Warnf("ChannelPoint(%v): unable to fetch color data "+
+			"of funding output, skipping its validation: %v",
+			fundingPoint, err)
+		return nil
+	}
+
+	if txoData.AssetId != state.AssetID ||
+		txoData.Value != state.AssetCapacity {
+
+		return fmt.Errorf("%v: ChannelPoint(%v) carries %v, expected "+
+			"%v of %v", ErrFundingColorMismatch, fundingPoint,
+			txoData, state.AssetCapacity, state.AssetID)
+	}
+
+	return nil
+}
+
+// findFundingSpend scans the most recent blocks of the main chain for the
+// transaction spending the funding output. Only as many blocks as the larger
+// of the channel's csv delays are scanned, as a revoked commitment confirmed
+// any deeper can no longer be punished. If the spend isn't found, nil is
+// returned.
+func (lc *LightningChannel) findFundingSpend() (*chainntnfs.SpendDetail, error) {
+	state := lc.channelState
+	fundingPoint := state.FundingOutpoint
+
+	maxDepth := state.LocalCsvDelay
+	if state.RemoteCsvDelay > maxDepth {
+		maxDepth = state.RemoteCsvDelay
+	}
+
+	bestHeight, err := lc.bio.GetCurrentHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	for depth := uint32(0); depth < maxDepth; depth++ {
+		height := bestHeight - int32(depth)
+		if height < 0 {
+			break
+		}
+
+		blockHash, err := lc.bio.GetBlockHash(int64(height))
+		if err != nil {
+			return nil, err
+		}
+		block, err := lc.bio.GetBlock(blockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range block.Transactions {
+			for i, txIn := range tx.TxIn {
+				if txIn.PreviousOutPoint != *fundingPoint {
+					continue
+				}
+
+				spenderHash := tx.TxSha()
+				return &chainntnfs.SpendDetail{
+					SpentOutPoint:     fundingPoint,
+					SpenderTxHash:     &spenderHash,
+					SpendingTx:        tx,
+					SpenderInputIndex: uint32(i),
+					SpendingHeight:    height,
+				}, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
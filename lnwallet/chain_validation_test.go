@@ -0,0 +1,96 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestFundingSpentOffline tests that a channel whose funding output was
+// spent while we were offline is flagged when loaded, and handed off to the
+// close observer along with the located spend, while a channel whose funding
+// output is merely missing is not.
+func TestFundingSpentOffline(t *testing.T) {
+	fundingPoint := &wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 1}
+
+	chain := &mockChainIO{
+		spentOutputs: map[wire.OutPoint]struct{}{
+			*fundingPoint: struct{}{},
+		},
+	}
+	for i := 0; i < 6; i++ {
+		chain.blocks = append(chain.blocks, newTestBlock(i+1, uint32(i)))
+	}
+
+	// Place the spend of the funding output within a recent block.
+	spendingTx := chain.blocks[4].Transactions[2]
+	spendingTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	spendingTx.AddTxIn(wire.NewTxIn(fundingPoint, nil, nil))
+
+	newChannel := func(csvDelay uint32) *LightningChannel {
+		return &LightningChannel{
+			bio: chain,
+			channelState: &channeldb.OpenChannel{
+				FundingOutpoint: fundingPoint,
+				RemoteCsvDelay:  csvDelay,
+			},
+			UnilateralCloseSignal: make(chan struct{}),
+		}
+	}
+
+	channel := newChannel(4)
+	if err := channel.validateFundingOutput(); err != nil {
+		t.Fatalf("unable to validate funding output: %v", err)
+	}
+	if !channel.FundingSpentOffline {
+		t.Fatalf("channel not flagged as spent offline")
+	}
+	select {
+	case <-channel.UnilateralCloseSignal:
+	default:
+		t.Fatalf("close observer not signalled")
+	}
+
+	spend := channel.closeSpend
+	switch {
+	case spend == nil:
+		t.Fatalf("spend of funding output not located")
+
+	case *spend.SpenderTxHash != spendingTx.TxSha():
+		t.Fatalf("expected spending txid %v, got %v",
+			spendingTx.TxSha(), spend.SpenderTxHash)
+
+	case spend.SpendingHeight != 4 || spend.SpenderInputIndex != 1:
+		t.Fatalf("spend located at height %v, input %v, expected "+
+			"height 4, input 1", spend.SpendingHeight,
+			spend.SpenderInputIndex)
+	}
+
+	// If the spend is buried deeper than the csv delay, it can't be
+	// located, so the channel isn't flagged, as it may have only been
+	// spent within the mempool.
+	channel = newChannel(1)
+	if err := channel.validateFundingOutput(); err != nil {
+		t.Fatalf("unable to validate funding output: %v", err)
+	}
+	if channel.FundingSpentOffline {
+		t.Fatalf("channel flagged without a located spend")
+	}
+	select {
+	case <-channel.UnilateralCloseSignal:
+		t.Fatalf("close observer signalled without a located spend")
+	default:
+	}
+
+	// Likewise, a funding transaction which isn't known, such as one
+	// re-orged out of the chain, isn't mistaken for a spend.
+	delete(chain.spentOutputs, *fundingPoint)
+	channel = newChannel(4)
+	if err := channel.validateFundingOutput(); err != nil {
+		t.Fatalf("unable to validate funding output: %v", err)
+	}
+	if channel.FundingSpentOffline {
+		t.Fatalf("channel with unknown funding output flagged")
+	}
+}
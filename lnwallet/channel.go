@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"container/list"
 	"fmt"
-	"log"
+	"math"
 	"sync"
+	"sync/atomic"
 
 	"github.com/btcsuite/fastsha256"
-	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lndcc"
@@ -18,7 +18,6 @@ import (
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
-	"github.com/roasbeef/btcutil/txsort"
 )
 
 var zeroHash wire.ShaHash
@@ -27,6 +26,64 @@ var (
 	ErrChanClosing = fmt.Errorf("channel is being closed, operation disallowed")
 	ErrNoWindow    = fmt.Errorf("unable to sign new commitment, the current" +
 		" revocation window is exhausted")
+
+	// ErrChanReorged is returned when an HTLC is added to a channel whose
+	// funding transaction has been re-org'd out, and has yet to be
+	// re-confirmed.
+	ErrChanReorged = fmt.Errorf("funding transaction has been re-org'd " +
+		"out, no new HTLCs may be added")
+
+	// ErrDuplicatePaymentHash is returned when an HTLC is added to an
+	// update log which already contains an active HTLC with the same
+	// payment hash. As settling an HTLC reveals the preimage, permitting
+	// duplicates would allow the second HTLC to be claimed by anyone along
+	// the route, so they're rejected outright.
+	ErrDuplicatePaymentHash = fmt.Errorf("an active HTLC with the same " +
+		"payment hash already exists")
+
+	// ErrLogIndexExhausted is returned when an update log has exhausted
+	// the indexes which can be referenced on the wire, and no further
+	// updates can be added to it.
+	ErrLogIndexExhausted = fmt.Errorf("update log index exhausted")
+
+	// ErrReplayedHTLC is returned when the remote party retransmits an
+	// update which has already been applied to the update log. This
+	// commonly occurs after a reconnection, and the update should simply
+	// be ignored.
+	ErrReplayedHTLC = fmt.Errorf("update has already been received")
+
+	// ErrMaxHTLCsExceeded is returned when an HTLC is added while the
+	// projected commitment transaction already holds the maximum number of
+	// HTLC outputs which can be covered by the colored coins instructions.
+	ErrMaxHTLCsExceeded = fmt.Errorf("commitment transaction cannot " +
+		"hold any additional HTLCs")
+
+	// ErrCarrierBudgetExceeded is returned when an HTLC is added while the
+	// satoshis carried by the outputs of the projected commitment
+	// transaction would exceed the value of the funding output. Each
+	// output of a colorified commitment carries a dust amount of
+	// satoshis, so a commitment exceeding the budget couldn't be funded.
+	ErrCarrierBudgetExceeded = fmt.Errorf("commitment transaction " +
+		"outputs would exceed the funding output's satoshi budget")
+
+	// ErrCommitmentNonStandard is returned when a commitment transaction
+	// would exceed the standard transaction weight, or its colored coins
+	// instructions would exceed the OP_RETURN payload limit. Such a
+	// commitment transaction wouldn't be relayed, so it can't be signed.
+	ErrCommitmentNonStandard = fmt.Errorf("commitment transaction would " +
+		"be non-standard")
+
+	// ErrRevocationWindowEdge is returned when extending our revocation
+	// window would allow the remote party to extend our commitment chain
+	// by more than InitialRevocationWindow commitments.
+	ErrRevocationWindowEdge = fmt.Errorf("revocation window already " +
+		"extended to its maximum size")
+
+	// ErrRevocationWindowFull is returned when the remote party attempts
+	// to extend its revocation window beyond MaxRevocationWindow
+	// revocations.
+	ErrRevocationWindowFull = fmt.Errorf("remote revocation window " +
+		"exceeds maximum size")
 )
 
 const (
@@ -42,6 +99,67 @@ const (
 	// extend the other's commitment chain non-interactively, and also
 	// serves as a flow control mechanism to a degree.
 	InitialRevocationWindow = 4
+
+	// DefaultMaxRevocationWindow is the default number of revocations we
+	// hold for the remote party's commitment chain, counting both those
+	// we've used and those within its revocation window. This bounds the
+	// memory a remote party can consume by repeatedly extending its
+	// revocation window.
+	DefaultMaxRevocationWindow = 2 * InitialRevocationWindow
+
+	// maxLogIndex is the largest index an update log entry may be
+	// assigned. Log indexes are referenced on the wire as an
+	// lnwire.HTLCKey, which is a signed 64-bit integer.
+	maxLogIndex = math.MaxInt64
+
+	// chanReserveDivisor determines the channel reserve, the portion of
+	// the channel's capacity which each party must keep within the channel
+	// as their settled balance, so there's always something at stake if
+	// they broadcast a revoked state. Following the BOLT recommendation,
+	// the reserve is 1% of the channel's capacity.
+	chanReserveDivisor = 100
+
+	// maxStandardTxWeight is the maximum weight of a transaction which
+	// will be relayed by default.
+	maxStandardTxWeight = 400000
+
+	// commitWitnessWeight is the weight of the 2-of-2 multi-sig witness
+	// spending the funding output, including the segwit marker and flag:
+	// the item count (1), the empty item (1), two signatures (2*74), and
+	// the redeem script (1+71).
+	commitWitnessWeight = 2 + 1 + 1 + 2*74 + 1 + 71
+
+	// maxInstructionPayload is the largest colored coins instruction
+	// payload a commitment transaction's OP_RETURN output may carry.
+	// lndcc.ColorifyTx prefixes the payload with a single length byte,
+	// which is only a valid direct push for payloads of up to 75 bytes,
+	// slightly below the standard 80 byte limit.
+	maxInstructionPayload = txscript.OP_DATA_75
+
+	// ccTransferHeaderSize is the size of the header of an encoded colored
+	// coins transfer payload: the protocol identifier (2), version (1),
+	// and opcode (1).
+	ccTransferHeaderSize = 4
+
+	// ccMaxInstructionSize is the worst case size of an encoded colored
+	// coins transfer instruction: the flags and output index (1), and the
+	// amount (7).
+	ccMaxInstructionSize = 8
+
+	// maxCommitHTLCs is the maximum number of HTLCs permitted on a
+	// commitment transaction, such that in the worst case, the
+	// instructions for all HTLC outputs along with both balance outputs
+	// fit within the OP_RETURN payload.
+	maxCommitHTLCs = (maxInstructionPayload-ccTransferHeaderSize)/
+		ccMaxInstructionSize - 2
+
+	// anchorSize is the value in satoshis of each anchor output attached to
+	// a commitment transaction.
+	anchorSize = btcutil.Amount(330)
+
+	// anchorCsvDelay is the relative delay after which an anchor output
+	// may be swept by anyone.
+	anchorCsvDelay = 16
 )
 
 // channelState is an enum like type which represents the current state of a
@@ -74,6 +192,12 @@ const (
 	// channelPendingPayment indicates that there a currently outstanding
 	// HTLC's within the channel.
 	channelPendingPayment
+
+	// channelReorged indicates that the funding transaction of an open
+	// channel has been disconnected from the main chain as the result of a
+	// re-org. The channel will transition back to channelOpen once the
+	// funding transaction has been re-confirmed.
+	channelReorged
 )
 
 // PaymentHash represents the sha256 of a random value. This hash is used to
@@ -112,13 +236,13 @@ type PaymentDescriptor struct {
 	// Index is the log entry number that his HTLC update has within the
 	// log. Depending on if IsIncoming is true, this is either an entry the
 	// remote party added, or one that we added locally.
-	Index uint32
+	Index uint64
 
 	// ParentIndex is the index of the log entry that this HTLC update
 	// settles or times out. If IsIncoming is false, then this refers to an
 	// index within our local log, otherwise this refers to an entry int he
 	// remote peer's log.
-	ParentIndex uint32
+	ParentIndex uint64
 
 	// Payload is an opaque blob which is used to complete multi-hop routing.
 	Payload []byte
@@ -165,9 +289,8 @@ type commitment struct {
 	// new commitment sent to the remote party includes an index in the
 	// shared log which details which of their updates we're including in
 	// this new commitment.
-	// TODO(roasbeef): also make uint64?
-	ourMessageIndex   uint32
-	theirMessageIndex uint32
+	ourMessageIndex   uint64
+	theirMessageIndex uint64
 
 	// txn is the commitment transaction generated by including any HTLC
 	// updates whose index are below the two indexes listed above. If this
@@ -190,6 +313,16 @@ type commitment struct {
 	// commitment.
 	outgoingHTLCs []*PaymentDescriptor
 	incomingHTLCs []*PaymentDescriptor
+
+	// htlcOutputs is the script metadata of the output of each HTLC
+	// within the commitment transaction.
+	htlcOutputs []*htlcOutput
+
+	// satCost is the total value in satoshis carried by the outputs of
+	// the commitment transaction: the carrier amount of each balance and
+	// HTLC output, and the anchors. The remainder of the funding output's
+	// value pays the fee.
+	satCost btcutil.Amount
 }
 
 // toChannelDelta converts the target commitment into a format suitable to be
@@ -204,6 +337,20 @@ func (c *commitment) toChannelDelta() (*channeldb.ChannelDelta, error) {
 		Htlcs:         make([]*channeldb.HTLC, 0, numHtlcs),
 	}
 
+	outputs := make(map[*PaymentDescriptor]*htlcOutput, len(c.htlcOutputs))
+	for _, output := range c.htlcOutputs {
+		outputs[output.htlc] = output
+	}
+	withOutput := func(h *channeldb.HTLC, htlc *PaymentDescriptor) {
+		h.OutputIndex = -1
+		if output, ok := outputs[htlc]; ok {
+			h.OutputIndex = output.outputIndex
+			h.WitnessScript = output.witnessScript
+			h.ScriptHash = fastsha256.Sum256(output.witnessScript)
+		}
+		delta.Htlcs = append(delta.Htlcs, h)
+	}
+
 	for _, htlc := range c.outgoingHTLCs {
 		h := &channeldb.HTLC{
 			Incoming:        false,
@@ -212,7 +359,7 @@ func (c *commitment) toChannelDelta() (*channeldb.ChannelDelta, error) {
 			RefundTimeout:   htlc.Timeout,
 			RevocationDelay: 0,
 		}
-		delta.Htlcs = append(delta.Htlcs, h)
+		withOutput(h, htlc)
 	}
 	for _, htlc := range c.incomingHTLCs {
 		h := &channeldb.HTLC{
@@ -222,7 +369,7 @@ func (c *commitment) toChannelDelta() (*channeldb.ChannelDelta, error) {
 			RefundTimeout:   htlc.Timeout,
 			RevocationDelay: 0,
 		}
-		delta.Htlcs = append(delta.Htlcs, h)
+		withOutput(h, htlc)
 	}
 
 	return delta, nil
@@ -281,6 +428,18 @@ func (s *commitmentChain) tail() *commitment {
 	return s.commitments.Front().Value.(*commitment)
 }
 
+// nextTail returns the commitment which will become the tail of the chain
+// once the tail is advanced. If the chain only has a single commitment, then
+// nil is returned.
+func (s *commitmentChain) nextTail() *commitment {
+	next := s.commitments.Front().Next()
+	if next == nil {
+		return nil
+	}
+
+	return next.Value.(*commitment)
+}
+
 // LightningChannel implements the state machine which corresponds to the
 // current commitment protocol wire spec. The state machine implemented allows
 // for asynchronous fully desynchronized, batched+pipelined updates to
@@ -321,11 +480,18 @@ type LightningChannel struct {
 
 	sync.RWMutex
 
-	ourLogCounter   uint32
-	theirLogCounter uint32
+	ourLogCounter   uint64
+	theirLogCounter uint64
 
-	status   channelState
-	Capacity btcutil.Amount
+	status channelState
+
+	// Capacity is the total capacity of the channel, denominated in the
+	// channel's asset, while BtcCapacity is the value in satoshis of the
+	// funding output. The former bounds the balances of the channel,
+	// while the latter is committed to by the sighash of each
+	// transaction spending the funding output.
+	Capacity    btcutil.Amount
+	BtcCapacity btcutil.Amount
 
 	// currentHeight is the current height of our local commitment chain.
 	// This is also the same as the number of updates to the channel we've
@@ -355,6 +521,30 @@ type LightningChannel struct {
 	// commitment chain.
 	revocationWindow []*lnwire.CommitRevocation
 
+	// MaxRevocationWindow is the maximum number of revocations held for
+	// the remote party's commitment chain, counting both usedRevocations
+	// and the revocationWindow. A revocation extending the window beyond
+	// this limit is rejected. It defaults to DefaultMaxRevocationWindow,
+	// and may be modified before any revocations are received.
+	MaxRevocationWindow int
+
+	// ClosePolicy determines the outputs of the cooperative close
+	// transaction. It defaults to DefaultCloseOutputPolicy, and MUST match
+	// the policy applied by the remote party.
+	ClosePolicy CloseOutputPolicy
+
+	// lostRevocation holds the revocation key+hash of the remote party's
+	// current commitment, as reported upon reconnection, if the
+	// revocation of their prior commitment was lost along with the prior
+	// connection. Once that revocation is retransmitted, this key+hash
+	// become their current revocation key+hash.
+	lostRevocation *lnwire.CommitRevocation
+
+	// retransmissions is the queue of outbound commitment signatures and
+	// revocations which haven't yet been acknowledged by the remote party,
+	// in the order they were sent. The queue is mirrored on disk.
+	retransmissions []*retransmission
+
 	// remoteCommitChain is the remote node's commitment chain. Any new
 	// commitments we initiate are added to the tip of this chain.
 	remoteCommitChain *commitmentChain
@@ -378,8 +568,21 @@ type LightningChannel struct {
 
 	// logIndex is an index into the above log. This index is used to
 	// remove Add state updates, once a timeout/settle is received.
-	ourLogIndex   map[uint32]*list.Element
-	theirLogIndex map[uint32]*list.Element
+	ourLogIndex   map[uint64]*list.Element
+	theirLogIndex map[uint64]*list.Element
+
+	// lastRevoked is the most recently revoked remote commitment, as
+	// recorded upon receipt of the remote party's revocation.
+	lastRevoked *channeldb.RevokedCommitment
+
+	// htlcScriptCache and commitSigHashes cache the HTLC scripts and the
+	// sighash midstate of recently constructed commitments, as both are
+	// largely the same across commitments. As the local and remote
+	// commitments may be constructed concurrently, the script cache is
+	// guarded by htlcScriptMtx.
+	htlcScriptMtx   sync.Mutex
+	htlcScriptCache htlcScriptCache
+	commitSigHashes commitSigHashCache
 
 	LocalDeliveryScript  []byte
 	RemoteDeliveryScript []byte
@@ -398,6 +601,33 @@ type LightningChannel struct {
 	// their version of the commitment transaction on-chain.
 	UnilateralCloseSignal chan struct{}
 
+	// closeSpend is the spend of the funding output by the remote party,
+	// set before the UnilateralCloseSignal is closed.
+	closeSpend *chainntnfs.SpendDetail
+
+	// FundingSpentOffline indicates that the funding output was found to
+	// be spent when the channel was loaded, meaning the channel was closed
+	// on-chain while we were offline. If set, the UnilateralCloseSignal
+	// will already have been closed.
+	FundingSpentOffline bool
+
+	// FundingUnconfirmed indicates that the funding transaction had yet
+	// to confirm when the channel was loaded, as is the case for
+	// zero-conf channels. The channel is then not validated against the
+	// chain.
+	FundingUnconfirmed bool
+
+	// CommitOutputSpends is a channel which is sent upon once an output
+	// on one of our commitment transactions which we may need to sweep
+	// (our delayed output, or an HTLC output) is spent on-chain. The
+	// utxo nursery and breach arbiter consume from this channel.
+	CommitOutputSpends chan *CommitOutputSpend
+
+	// cancelCommitSpends is closed to cancel the spend notifications
+	// registered for the outputs of our prior commitment once it's been
+	// revoked.
+	cancelCommitSpends chan struct{}
+
 	started  int32
 	shutdown int32
 
@@ -423,17 +653,30 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		remoteCommitChain:     newCommitmentChain(state.NumUpdates),
 		localCommitChain:      newCommitmentChain(state.NumUpdates),
 		channelState:          state,
+		status:                channelOpen,
 		revocationWindowEdge:  state.NumUpdates,
+		MaxRevocationWindow:   DefaultMaxRevocationWindow,
+		ClosePolicy:           DefaultCloseOutputPolicy(),
 		ourUpdateLog:          list.New(),
 		theirUpdateLog:        list.New(),
-		ourLogIndex:           make(map[uint32]*list.Element),
-		theirLogIndex:         make(map[uint32]*list.Element),
-		Capacity:              state.Capacity,
+		ourLogIndex:           make(map[uint64]*list.Element),
+		theirLogIndex:         make(map[uint64]*list.Element),
+		htlcScriptCache:       make(htlcScriptCache),
+		Capacity:              state.AssetCapacity,
+		BtcCapacity:           state.BtcCapacity,
 		LocalDeliveryScript:   state.OurDeliveryScript,
 		RemoteDeliveryScript:  state.TheirDeliveryScript,
 		FundingRedeemScript:   state.FundingRedeemScript,
 		ForceCloseSignal:      make(chan struct{}),
 		UnilateralCloseSignal: make(chan struct{}),
+		CommitOutputSpends:    make(chan *CommitOutputSpend, MaxPendingPayments+1),
+		quit:                  make(chan struct{}),
+	}
+
+	// Channels created before the satoshi capacity was tracked apart from
+	// the asset capacity had their commitments signed over the latter.
+	if lc.BtcCapacity == 0 {
+		lc.BtcCapacity = state.AssetCapacity
 	}
 
 	// Initialize both of our chains the current un-revoked commitment for
@@ -456,6 +699,12 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		lc.restoreStateLogs()
 	}
 
+	// Restore any messages which the remote party hasn't yet acknowledged,
+	// so they can be retransmitted once the connection is reestablished.
+	if err := lc.loadRetransmissions(); err != nil {
+		return nil, err
+	}
+
 	// Create the sign descriptor which we'll be using very frequently to
 	// request a signature for the 2-of-2 multi-sig from the signer in
 	// order to complete channel state transitions.
@@ -470,7 +719,7 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		RedeemScript: lc.channelState.FundingRedeemScript,
 		Output: &wire.TxOut{
 			PkScript: lc.fundingP2WSH,
-			Value:    int64(lc.channelState.Capacity),
+			Value:    int64(lc.BtcCapacity),
 		},
 		HashType:   txscript.SigHashAll,
 		InputIndex: 0,
@@ -478,9 +727,11 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 
 	// Register for a notification to be dispatched if the funding outpoint
 	// has been spent. This indicates that either us or the remote party
-	// has broadcasted a commitment transaction on-chain.
+	// has broadcasted a commitment transaction on-chain. We watch the
+	// mempool, as if the remote party broadcasts a revoked commitment,
+	// we'd like to start preparing the penalty transaction immediately.
 	fundingOut := &lc.fundingTxIn.PreviousOutPoint
-	channelCloseNtfn, err := lc.channelEvents.RegisterSpendNtfn(fundingOut)
+	channelCloseNtfn, err := lc.channelEvents.RegisterMempoolSpendNtfn(fundingOut)
 	if err != nil {
 		return nil, err
 	}
@@ -492,25 +743,177 @@ func NewLightningChannel(signer Signer, bio BlockChainIO,
 		// If the daemon is shutting down, then this notification channel
 		// will be closed, so check the second read-value to avoid a false
 		// positive.
-		if _, ok := <-channelCloseNtfn.Spend; !ok {
+		spend, ok := <-channelCloseNtfn.Spend
+		if !ok {
 			return
 		}
 
-		// If the channel's doesn't already indicate that a commitment
-		// transaction has been broadcast on-chain, then this means the
-		// remote party broadcasted their commitment transaction.
 		// TODO(roasbeef): wait for a conf?
-		lc.Lock()
-		if lc.status != channelDispute {
-			close(lc.UnilateralCloseSignal)
-			lc.status = channelDispute
-		}
-		lc.Unlock()
+		lc.dispatchUnilateralClose(spend)
 	}()
 
+	// As the notification above only catches spends from here on, ensure
+	// the funding output wasn't spent while we were offline, and still
+	// carries the asset capacity of the channel. Without a BlockChainIO,
+	// we've no view of the chain to validate against.
+	if bio != nil {
+		if err := lc.validateFundingOutput(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Our current commitment may be broadcast at any point, so watch each
+	// of its outputs we may need to sweep for a spend.
+	if state.OurCommitTx != nil {
+		current := &commitment{
+			height: lc.currentHeight,
+			txn:    state.OurCommitTx,
+		}
+		if err := lc.registerCommitOutputSpends(current); err != nil {
+			return nil, err
+		}
+	}
+
 	return lc, nil
 }
 
+// Stop cancels all spend notifications registered for the outputs of our
+// commitment transactions, then waits until the goroutines awaiting them have
+// exited. Once stopped, spends are no longer sent over the
+// CommitOutputSpends channel.
+func (lc *LightningChannel) Stop() {
+	if !atomic.CompareAndSwapInt32(&lc.shutdown, 0, 1) {
+		return
+	}
+
+	close(lc.quit)
+	lc.wg.Wait()
+}
+
+// CommitOutputSpend details the spend of an output on one of our local
+// commitment transactions.
+type CommitOutputSpend struct {
+	// OutPoint is the commitment output which has been spent.
+	OutPoint wire.OutPoint
+
+	// CommitHeight is the height of the commitment which created the
+	// spent output.
+	CommitHeight uint64
+
+	// IsHTLC denotes if the spent output was an HTLC output rather than
+	// our delayed output.
+	IsHTLC bool
+
+	// SpendDetail holds the details of the spending transaction.
+	SpendDetail *chainntnfs.SpendDetail
+}
+
+// registerCommitOutputSpends registers a spend notification for our delayed
+// output, along with each HTLC output found on the passed local commitment.
+// Once any of these outputs are spent, the details of the spend are sent
+// over the CommitOutputSpends channel. The output paying to the remote
+// party, the anchors, and the colored coins OP_RETURN output are skipped. As
+// only the latest commitment may be broadcast by us, the notifications
+// registered for the prior commitment are cancelled.
+func (lc *LightningChannel) registerCommitOutputSpends(commit *commitment) error {
+	// In order to distinguish our delayed output from the HTLC outputs,
+	// we re-derive the to-self script for this commitment height.
+	revocation, err := lc.channelState.LocalElkrem.AtIndex(commit.height)
+	if err != nil {
+		return err
+	}
+	revocationKey := DeriveRevocationPubkey(lc.channelState.TheirCommitKey,
+		revocation[:])
+	selfScript, err := commitScriptToSelf(lc.channelState.LocalCsvDelay,
+		lc.channelState.OurCommitKey, revocationKey)
+	if err != nil {
+		return err
+	}
+	selfPkScript, err := witnessScriptHash(selfScript)
+	if err != nil {
+		return err
+	}
+
+	// The anchor outputs are p2wsh as well, but are swept by the anchor
+	// sweep rather than the nursery, so they aren't watched.
+	var anchorPkScripts [][]byte
+	for _, key := range []*btcec.PublicKey{lc.channelState.OurCommitKey,
+		lc.channelState.TheirCommitKey} {
+
+		script, err := anchorScript(key)
+		if err != nil {
+			return err
+		}
+		pkScript, err := witnessScriptHash(script)
+		if err != nil {
+			return err
+		}
+		anchorPkScripts = append(anchorPkScripts, pkScript)
+	}
+
+	if lc.cancelCommitSpends != nil {
+		close(lc.cancelCommitSpends)
+	}
+	cancel := make(chan struct{})
+	lc.cancelCommitSpends = cancel
+
+	commitTxid := commit.txn.TxSha()
+	for i, txOut := range commit.txn.TxOut {
+		// Besides the anchors, only our delayed output, and the HTLC
+		// outputs are p2wsh.
+		if !txscript.IsPayToWitnessScriptHash(txOut.PkScript) ||
+			bytes.Equal(txOut.PkScript, anchorPkScripts[0]) ||
+			bytes.Equal(txOut.PkScript, anchorPkScripts[1]) {
+			continue
+		}
+
+		op := wire.OutPoint{
+			Hash:  commitTxid,
+			Index: uint32(i),
+		}
+		spendNtfn, err := lc.channelEvents.RegisterSpendNtfn(&op)
+		if err != nil {
+			return err
+		}
+
+		spend := &CommitOutputSpend{
+			OutPoint:     op,
+			CommitHeight: commit.height,
+			IsHTLC:       !bytes.Equal(txOut.PkScript, selfPkScript),
+		}
+		lc.wg.Add(1)
+		go func() {
+			defer lc.wg.Done()
+
+			// If the daemon is shutting down, then the
+			// notification channel will be closed. Otherwise, once
+			// the commitment has been revoked, or the channel
+			// stopped, the registration is dropped by the
+			// notifier.
+			select {
+			case detail, ok := <-spendNtfn.Spend:
+				if !ok {
+					return
+				}
+				spend.SpendDetail = detail
+			case <-cancel:
+				spendNtfn.Cancel()
+				return
+			case <-lc.quit:
+				spendNtfn.Cancel()
+				return
+			}
+
+			select {
+			case lc.CommitOutputSpends <- spend:
+			case <-lc.quit:
+			}
+		}()
+	}
+
+	return nil
+}
+
 // restoreStateLogs runs through the current locked-in HTLC's from the point of
 // view of the channel and insert corresponding log entries (both local and
 // remote) for each HTLC read from disk. This method is required sync the
@@ -521,7 +924,7 @@ func (lc *LightningChannel) restoreStateLogs() error {
 		pastHeight = lc.currentHeight - 1
 	}
 
-	var ourCounter, theirCounter uint32
+	var ourCounter, theirCounter uint64
 	for _, htlc := range lc.channelState.Htlcs {
 		// TODO(roasbeef): set isForwarded to false for all? need to
 		// persist state w.r.t to if forwarded or not, or can
@@ -567,7 +970,7 @@ type htlcView struct {
 // fetchHTLCView returns all the candidate HTLC updates which should be
 // considered for inclusion within a commitment based on the passed HTLC log
 // indexes.
-func (lc *LightningChannel) fetchHTLCView(theirLogIndex, ourLogIndex uint32) *htlcView {
+func (lc *LightningChannel) fetchHTLCView(theirLogIndex, ourLogIndex uint64) *htlcView {
 	var ourHTLCs []*PaymentDescriptor
 	for e := lc.ourUpdateLog.Front(); e != nil; e = e.Next() {
 		htlc := e.Value.(*PaymentDescriptor)
@@ -605,7 +1008,7 @@ func (lc *LightningChannel) fetchHTLCView(theirLogIndex, ourLogIndex uint32) *ht
 // commitment updates. A fully populated commitment is returned which reflects
 // the proper balances for both sides at this point in the commitment chain.
 func (lc *LightningChannel) fetchCommitmentView(remoteChain bool,
-	ourLogIndex, theirLogIndex uint32, revocationKey *btcec.PublicKey,
+	ourLogIndex, theirLogIndex uint64, revocationKey *btcec.PublicKey,
 	revocationHash [32]byte) (*commitment, error) {
 
 	var commitChain *commitmentChain
@@ -654,35 +1057,56 @@ func (lc *LightningChannel) fetchCommitmentView(remoteChain bool,
 	}
 
 	// Generate a new commitment transaction with all the latest
-	// unsettled/un-timed out HTLC's.
+	// unsettled/un-timed out HTLC's. The uncolored transaction only serves
+	// as the template for the colorified commitment, so it's drawn from a
+	// pool, sized to fit both balances along with every active HTLC.
 	ourCommitTx := !remoteChain
-	commitTx, err := CreateCommitTx(lc.fundingTxIn, selfKey, remoteKey,
+	numHTLCs := len(filteredHTLCView.ourUpdates) +
+		len(filteredHTLCView.theirUpdates)
+	templateTx := fetchCommitTemplate(2 + numHTLCs)
+	defer releaseCommitTemplate(templateTx)
+
+	err := createCommitTx(templateTx, lc.fundingTxIn, selfKey, remoteKey,
 		revocationKey, delay, delayBalance, p2wkhBalance)
 	if err != nil {
 		return nil, err
 	}
+	htlcOutputs := make([]*htlcOutput, 0, numHTLCs)
 	for _, htlc := range filteredHTLCView.ourUpdates {
-		if err := lc.addHTLC(commitTx, ourCommitTx, htlc,
-			revocationHash, delay, false); err != nil {
+		output, err := lc.addHTLC(templateTx, ourCommitTx, htlc,
+			revocationHash, delay, false)
+		if err != nil {
 			return nil, err
 		}
+		htlcOutputs = append(htlcOutputs, output)
 	}
 	for _, htlc := range filteredHTLCView.theirUpdates {
-		if err := lc.addHTLC(commitTx, ourCommitTx, htlc,
-			revocationHash, delay, true); err != nil {
+		output, err := lc.addHTLC(templateTx, ourCommitTx, htlc,
+			revocationHash, delay, true)
+		if err != nil {
 			return nil, err
 		}
+		htlcOutputs = append(htlcOutputs, output)
 	}
 
 	// Sort the transactions according to the agreed upon cannonical
-	// ordering. This lets us skip sending the entire transaction over,
-	// instead we'll just send signatures.
-	txsort.InPlaceSort(commitTx)
-
-	commitTx, err = lndcc.ColorifyTx(commitTx, false)
+	// ordering, then colorify it. This lets us skip sending the entire
+	// transaction over, instead we'll just send signatures.
+	commitTx, err := colorifyCanonical(templateTx, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := addCommitAnchors(commitTx, selfKey, remoteKey); err != nil {
+		return nil, err
+	}
+	if err := checkCommitStandard(commitTx); err != nil {
+		return nil, err
+	}
+	satCost, err := lc.checkCarrierBudget(commitTx)
 	if err != nil {
 		return nil, err
 	}
+	locateHTLCOutputs(commitTx, htlcOutputs)
 
 	return &commitment{
 		txn:               commitTx,
@@ -693,6 +1117,8 @@ func (lc *LightningChannel) fetchCommitmentView(remoteChain bool,
 		theirBalance:      theirBalance,
 		outgoingHTLCs:     filteredHTLCView.ourUpdates,
 		incomingHTLCs:     filteredHTLCView.theirUpdates,
+		htlcOutputs:       htlcOutputs,
+		satCost:           satCost,
 	}, nil
 }
 
@@ -710,8 +1136,8 @@ func (lc *LightningChannel) evaluateHTLCView(view *htlcView, ourBalance,
 	// keep track of which entries we need to skip when creating the final
 	// htlc view. We skip an entry whenever we find a settle or a timeout
 	// modifying an entry.
-	skipUs := make(map[uint32]struct{})
-	skipThem := make(map[uint32]struct{})
+	skipUs := make(map[uint64]struct{})
+	skipThem := make(map[uint64]struct{})
 
 	// First we run through non-add entries in both logs, populating the
 	// skip sets and mutating the current chain state (crediting balances, etc) to
@@ -847,18 +1273,61 @@ func processRemoveEntry(htlc *PaymentDescriptor, ourBalance,
 	*removeHeight = nextHeight
 }
 
+// uncommittedHeights returns the commitment heights, within the local or
+// remote chain, of each HTLC log entry which hasn't yet been included within a
+// commitment of that chain. Evaluating an HTLC view sets these heights before
+// the commitment itself has been constructed, so should the construction or
+// validation of the commitment fail, they must be reset via resetHeights in
+// order to leave the logs as they were prior to the attempt.
+func (lc *LightningChannel) uncommittedHeights(remoteChain bool) []*uint64 {
+	var heights []*uint64
+	for _, updateLog := range []*list.List{lc.ourUpdateLog, lc.theirUpdateLog} {
+		for e := updateLog.Front(); e != nil; e = e.Next() {
+			pd := e.Value.(*PaymentDescriptor)
+
+			var height *uint64
+			switch {
+			case pd.EntryType == Add && remoteChain:
+				height = &pd.addCommitHeightRemote
+			case pd.EntryType == Add:
+				height = &pd.addCommitHeightLocal
+			case remoteChain:
+				height = &pd.removeCommitHeightRemote
+			default:
+				height = &pd.removeCommitHeightLocal
+			}
+
+			if *height == 0 {
+				heights = append(heights, height)
+			}
+		}
+	}
+
+	return heights
+}
+
+// resetHeights resets each of the passed commitment heights, as returned by
+// uncommittedHeights.
+func resetHeights(heights []*uint64) {
+	for _, height := range heights {
+		*height = 0
+	}
+}
+
 // SignNextCommitment signs a new commitment which includes any previous
 // unsettled HTLCs, any new HTLCs, and any modifications to prior HTLCs
 // committed in previous commitment updates. Signing a new commitment
 // decrements the available revocation window by 1. After a successful method
 // call, the remote party's commitment chain is extended by a new commitment
 // which includes all updates to the HTLC log prior to this method invocation.
-func (lc *LightningChannel) SignNextCommitment() ([]byte, uint32, error) {
+// Transient failures of the color encoder are retried while constructing the
+// commitment, and the revocation window is left untouched should signing
+// ultimately fail, allowing the caller to simply try again.
+func (lc *LightningChannel) SignNextCommitment() ([]byte, uint64, error) {
 	// Ensure that we have enough unused revocation hashes given to us by the
 	// remote party. If the set is empty, then we're unable to create a new
 	// state unless they first revoke a prior commitment transaction.
-	if len(lc.revocationWindow) == 0 ||
-		len(lc.usedRevocations) == InitialRevocationWindow {
+	if !lc.canSignCommitment() {
 		return nil, 0, ErrNoWindow
 	}
 
@@ -866,6 +1335,38 @@ func (lc *LightningChannel) SignNextCommitment() ([]byte, uint32, error) {
 	// transaction, if no errors occur then this revocation tuple will be
 	// moved to the used set.
 	nextRevocation := lc.revocationWindow[0]
+
+	// Constructing the new commitment updates the addition height of any
+	// new HTLC log entries. These heights are reset should we fail to sign
+	// the new commitment, as the remote chain won't be extended.
+	uncommitted := lc.uncommittedHeights(true)
+	newCommitView, sig, err := lc.signRemoteCommitment(nextRevocation)
+	if err != nil {
+		resetHeights(uncommitted)
+		return nil, 0, err
+	}
+
+	lc.extendRemoteChain(newCommitView)
+
+	// Strip off the sighash flag on the signature in order to send it over
+	// the wire.
+	return sig, lc.theirLogCounter, nil
+}
+
+// canSignCommitment returns true if the remote party has left us a revocation
+// within our revocation window, allowing us to sign a new commitment for them.
+func (lc *LightningChannel) canSignCommitment() bool {
+	return len(lc.revocationWindow) != 0 &&
+		len(lc.usedRevocations) != InitialRevocationWindow
+}
+
+// signRemoteCommitment constructs the remote party's next commitment using
+// the passed revocation from our revocation window, and signs it. Neither the
+// remote commitment chain nor the revocation window are modified, allowing
+// the caller to discard the commitment should the signing fail.
+func (lc *LightningChannel) signRemoteCommitment(
+	nextRevocation *lnwire.CommitRevocation) (*commitment, []byte, error) {
+
 	remoteRevocationKey := nextRevocation.NextRevocationKey
 	remoteRevocationHash := nextRevocation.NextRevocationHash
 
@@ -877,7 +1378,7 @@ func (lc *LightningChannel) SignNextCommitment() ([]byte, uint32, error) {
 	newCommitView, err := lc.fetchCommitmentView(true, lc.ourLogCounter,
 		lc.theirLogCounter, remoteRevocationKey, remoteRevocationHash)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
 	walletLog.Tracef("ChannelPoint(%v): extending remote chain to height %v",
@@ -885,31 +1386,30 @@ func (lc *LightningChannel) SignNextCommitment() ([]byte, uint32, error) {
 	walletLog.Tracef("ChannelPoint(%v): remote chain: our_balance=%v, "+
 		"their_balance=%v, commit_tx: %v", lc.channelState.ChanID,
 		newCommitView.ourBalance, newCommitView.theirBalance,
-		newLogClosure(func() string {
-			return spew.Sdump(newCommitView.txn)
-		}))
+		newTxLogClosure(newCommitView.txn, lc.channelState.AssetID))
 
 	// Sign their version of the new commitment transaction.
-	lc.signDesc.SigHashes = txscript.NewTxSigHashes(newCommitView.txn)
+	lc.signDesc.SigHashes = lc.commitSigHashes.sigHashes(newCommitView.txn)
 	sig, err := lc.signer.SignOutputRaw(newCommitView.txn, lc.signDesc)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, err
 	}
 
-	// Extend the remote commitment chain by one with the addition of our
-	// latest commitment update.
+	return newCommitView, sig, nil
+}
+
+// extendRemoteChain extends the remote commitment chain by one with the
+// addition of a commitment signed via signRemoteCommitment, consuming the
+// revocation at the front of our revocation window.
+func (lc *LightningChannel) extendRemoteChain(newCommitView *commitment) {
 	lc.remoteCommitChain.addCommitment(newCommitView)
 
 	// Move the now used revocation hash from the unused set to the used set.
 	// We only do this at the end, as we know at this point the procedure will
 	// succeed without any errors.
-	lc.usedRevocations = append(lc.usedRevocations, nextRevocation)
+	lc.usedRevocations = append(lc.usedRevocations, lc.revocationWindow[0])
 	lc.revocationWindow[0] = nil // Avoid a GC leak.
 	lc.revocationWindow = lc.revocationWindow[1:]
-
-	// Strip off the sighash flag on the signature in order to send it over
-	// the wire.
-	return sig, lc.theirLogCounter, nil
 }
 
 // ReceiveNewCommitment processs a signature for a new commitment state sent by
@@ -921,7 +1421,30 @@ func (lc *LightningChannel) SignNextCommitment() ([]byte, uint32, error) {
 // state, then this newly added commitment becomes our current accepted channel
 // state.
 func (lc *LightningChannel) ReceiveNewCommitment(rawSig []byte,
-	ourLogIndex uint32) error {
+	ourLogIndex uint64) error {
+
+	// Should the new commitment turn out to be invalid, the commitment
+	// heights set while constructing it are reset.
+	uncommitted := lc.uncommittedHeights(false)
+	localCommitmentView, err := lc.verifyLocalCommitment(rawSig, ourLogIndex)
+	if err != nil {
+		resetHeights(uncommitted)
+		return err
+	}
+
+	// The signature checks out, so we can now add the new commitment to
+	// our local commitment chain.
+	lc.localCommitChain.addCommitment(localCommitmentView)
+
+	return nil
+}
+
+// verifyLocalCommitment constructs our next local commitment, including all
+// the entries we know of in their HTLC log, and up to ourLogIndex in our HTLC
+// log, and verifies the remote party's signature for it. The local commitment
+// chain isn't modified.
+func (lc *LightningChannel) verifyLocalCommitment(rawSig []byte,
+	ourLogIndex uint64) (*commitment, error) {
 
 	theirCommitKey := lc.channelState.TheirCommitKey
 	theirMultiSigKey := lc.channelState.TheirMultiSigKey
@@ -933,7 +1456,7 @@ func (lc *LightningChannel) ReceiveNewCommitment(rawSig []byte,
 	nextHeight := lc.currentHeight + 1
 	revocation, err := lc.channelState.LocalElkrem.AtIndex(nextHeight)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	revocationKey := DeriveRevocationPubkey(theirCommitKey, revocation[:])
 	revocationHash := fastsha256.Sum256(revocation[:])
@@ -944,7 +1467,7 @@ func (lc *LightningChannel) ReceiveNewCommitment(rawSig []byte,
 	localCommitmentView, err := lc.fetchCommitmentView(false, ourLogIndex,
 		lc.theirLogCounter, revocationKey, revocationHash)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	walletLog.Tracef("ChannelPoint(%v): extending local chain to height %v",
@@ -952,38 +1475,96 @@ func (lc *LightningChannel) ReceiveNewCommitment(rawSig []byte,
 	walletLog.Tracef("ChannelPoint(%v): local chain: our_balance=%v, "+
 		"their_balance=%v, commit_tx: %v", lc.channelState.ChanID,
 		localCommitmentView.ourBalance, localCommitmentView.theirBalance,
-		newLogClosure(func() string {
-			return spew.Sdump(localCommitmentView.txn)
-		}))
+		newTxLogClosure(localCommitmentView.txn, lc.channelState.AssetID))
 
 	// Construct the sighash of the commitment transaction corresponding to
 	// this newly proposed state update.
 	localCommitTx := localCommitmentView.txn
 	multiSigScript := lc.channelState.FundingRedeemScript
-	hashCache := txscript.NewTxSigHashes(localCommitTx)
+	hashCache := lc.commitSigHashes.sigHashes(localCommitTx)
 	sigHash, err := txscript.CalcWitnessSigHash(multiSigScript, hashCache,
-		txscript.SigHashAll, localCommitTx, 0, int64(lc.channelState.Capacity))
+		txscript.SigHashAll, localCommitTx, 0, int64(lc.BtcCapacity))
 	if err != nil {
-		// TODO(roasbeef): fetchview has already mutated the htlc's...
-		//  * need to either roll-back, or make pure
-		return err
+		return nil, err
 	}
 
 	// Ensure that the newly constructed commitment state has a valid
 	// signature.
 	sig, err := btcec.ParseSignature(rawSig, btcec.S256())
 	if err != nil {
-		return err
-	} else if !sig.Verify(sigHash, theirMultiSigKey) {
-		return fmt.Errorf("invalid commitment signature")
+		return nil, err
+	}
+	sigs := []*sigVerification{
+		{sig: sig, hash: sigHash, pubKey: theirMultiSigKey},
+	}
+	if err := verifySigs(sigs); err != nil {
+		lc.logCommitmentDivergence(localCommitmentView)
+		return nil, fmt.Errorf("invalid commitment signature")
 	}
 
-	// The signature checks out, so we can now add the new commitment to
-	// our local commitment chain.
 	localCommitmentView.sig = rawSig
-	lc.localCommitChain.addCommitment(localCommitmentView)
+	return localCommitmentView, nil
+}
 
-	return nil
+// ReceiveAndSignCommitment processes a signature for a new local commitment
+// sent by the remote party, and signs a new commitment for the remote party in
+// response, as ReceiveNewCommitment followed by SignNextCommitment would.
+// However, both commitments are constructed and colorified concurrently, as
+// neither depends on the other, roughly halving the latency of responding to
+// the remote party when the color encoder is the bottleneck.
+//
+// Should either commitment fail to be constructed, or the signature be
+// invalid, neither commitment chain is extended. If we lack a revocation from
+// the remote party to sign a new commitment with, only the received
+// commitment is processed, and a nil signature is returned.
+func (lc *LightningChannel) ReceiveAndSignCommitment(rawSig []byte,
+	ourLogIndex uint64) ([]byte, uint64, error) {
+
+	if !lc.canSignCommitment() {
+		if err := lc.ReceiveNewCommitment(rawSig, ourLogIndex); err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, nil
+	}
+	nextRevocation := lc.revocationWindow[0]
+
+	// Evaluating the local and remote views only modifies the commitment
+	// heights of the HTLC log entries for the respective chain, so both
+	// commitments may safely be constructed at once.
+	localUncommitted := lc.uncommittedHeights(false)
+	remoteUncommitted := lc.uncommittedHeights(true)
+
+	var (
+		remoteCommitView *commitment
+		sig              []byte
+		signErr          error
+		wg               sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		remoteCommitView, sig, signErr = lc.signRemoteCommitment(
+			nextRevocation)
+	}()
+
+	localCommitView, verifyErr := lc.verifyLocalCommitment(rawSig,
+		ourLogIndex)
+	wg.Wait()
+
+	if verifyErr != nil || signErr != nil {
+		resetHeights(localUncommitted)
+		resetHeights(remoteUncommitted)
+
+		if verifyErr != nil {
+			return nil, 0, verifyErr
+		}
+		return nil, 0, signErr
+	}
+
+	lc.localCommitChain.addCommitment(localCommitView)
+	lc.extendRemoteChain(remoteCommitView)
+
+	return sig, lc.theirLogCounter, nil
 }
 
 // PendingUpdates returns a boolean value reflecting if there are any pending
@@ -1017,8 +1598,8 @@ func (lc *LightningChannel) RevokeCurrentCommitment() (*lnwire.CommitRevocation,
 
 	// Along with this revocation, we'll also send an additional extension
 	// to our revocation window to the remote party.
-	lc.revocationWindowEdge++
-	revocationEdge, err := lc.channelState.LocalElkrem.AtIndex(lc.revocationWindowEdge)
+	nextWindowEdge := lc.revocationWindowEdge + 1
+	revocationEdge, err := lc.channelState.LocalElkrem.AtIndex(nextWindowEdge)
 	if err != nil {
 		return nil, err
 	}
@@ -1026,31 +1607,47 @@ func (lc *LightningChannel) RevokeCurrentCommitment() (*lnwire.CommitRevocation,
 		revocationEdge[:])
 	revocationMsg.NextRevocationHash = fastsha256.Sum256(revocationEdge[:])
 
+	// Once our current commitment is revoked, the next commitment within
+	// our chain becomes our broadcastable state.
+	newTail := lc.localCommitChain.nextTail()
+	if newTail == nil {
+		return nil, fmt.Errorf("no pending commitment to revoke to")
+	}
+
 	walletLog.Tracef("ChannelPoint(%v): revoking height=%v, now at height=%v, window_edge=%v",
 		lc.channelState.ChanID, lc.localCommitChain.tail().height,
-		lc.currentHeight+1, lc.revocationWindowEdge)
-
-	// Advance our tail, as we've revoked our previous state.
-	lc.localCommitChain.advanceTail()
-	lc.currentHeight++
+		lc.currentHeight+1, nextWindowEdge)
 
-	// Additionally, generate a channel delta for this state transition for
-	// persistent storage.
+	// Generate a channel delta for this state transition, then persist the
+	// new state. The database write is the commit point of the state
+	// transition, so the in-memory state is only advanced once the new
+	// state has been durably recorded.
 	// TODO(roasbeef): update sent/received.
-	tail := lc.localCommitChain.tail()
-	delta, err := tail.toChannelDelta()
+	delta, err := newTail.toChannelDelta()
 	if err != nil {
 		return nil, err
 	}
-	err = lc.channelState.UpdateCommitment(tail.txn, tail.sig, delta)
+	err = lc.channelState.UpdateCommitment(newTail.txn, newTail.sig, delta)
 	if err != nil {
 		return nil, err
 	}
 
+	// Advance our tail, as we've revoked our previous state.
+	lc.revocationWindowEdge = nextWindowEdge
+	lc.localCommitChain.advanceTail()
+	lc.currentHeight++
+	tail := lc.localCommitChain.tail()
+
 	walletLog.Tracef("ChannelPoint(%v): state transition accepted: "+
 		"our_balance=%v, their_balance=%v", lc.channelState.ChanID,
 		tail.ourBalance, tail.theirBalance)
 
+	// Now that this commitment is our current broadcastable state, watch
+	// each of the outputs we may need to sweep for a spend.
+	if err := lc.registerCommitOutputSpends(tail); err != nil {
+		return nil, err
+	}
+
 	revocationMsg.ChannelPoint = lc.channelState.ChanID
 	return revocationMsg, nil
 }
@@ -1065,7 +1662,14 @@ func (lc *LightningChannel) RevokeCurrentCommitment() (*lnwire.CommitRevocation,
 func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) ([]*PaymentDescriptor, error) {
 	// The revocation has a nil (zero) pre-image, then this should simply be
 	// added to the end of the revocation window for the remote node.
+	// The window is bounded, so the remote party can't have us hold an
+	// unbounded number of revocations.
 	if bytes.Equal(zeroHash[:], revMsg.Revocation[:]) {
+		numRevocations := len(lc.revocationWindow) + len(lc.usedRevocations)
+		if numRevocations >= lc.MaxRevocationWindow {
+			return nil, ErrRevocationWindowFull
+		}
+
 		lc.revocationWindow = append(lc.revocationWindow, revMsg)
 		return nil, nil
 	}
@@ -1075,9 +1679,14 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 	pendingRevocation := wire.ShaHash(revMsg.Revocation)
 
 	// Ensure the new pre-image fits in properly within the elkrem receiver
-	// tree. If this fails, then all other checks are skipped.
+	// tree. If this fails, then all other checks are skipped. The
+	// pre-image is added to a copy of the receiver, which only replaces
+	// the receiver of the channel once the revocation has been committed.
 	// TODO(rosbeef): abstract into func
-	remoteElkrem := lc.channelState.RemoteElkrem
+	remoteElkrem, err := copyRevocationStore(lc.channelState.RemoteElkrem)
+	if err != nil {
+		return nil, err
+	}
 	if err := remoteElkrem.AddNext(&pendingRevocation); err != nil {
 		return nil, err
 	}
@@ -1100,12 +1709,79 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 		}
 	}
 
-	// Advance the head of the revocation queue now that this revocation has
-	// been verified. Additionally, extend the end of our unused revocation
-	// queue with the newly extended revocation window update.
-	nextRevocation := lc.usedRevocations[0]
+	// Generate a channel delta for the revoked state, along with the data
+	// needed to sweep the now revoked commitment should the remote party
+	// ever broadcast it.
+	revokedCommit := lc.remoteCommitChain.tail()
+	delta, err := revokedCommit.toChannelDelta()
+	if err != nil {
+		return nil, err
+	}
+	var revoked *channeldb.RevokedCommitment
+	if revokedCommit.txn != nil {
+		revoked, err = lc.newRevokedCommitment(revokedCommit,
+			currentRevocationKey,
+			lc.channelState.TheirCurrentRevocationHash,
+			pendingRevocation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Rotate the current revocation key+hash for the remote party to the
+	// head of the revocation queue now that this revocation has been
+	// verified.
+	prevRevocation := lc.channelState.TheirCurrentRevocation
+	prevRevocationHash := lc.channelState.TheirCurrentRevocationHash
+	var nextRevocation *lnwire.CommitRevocation
+	switch {
+	// If a revocation was lost along with the prior connection, then this
+	// must be its retransmission, as the elkrem receiver only accepts
+	// revocations in order. The next key+hash were given to us when the
+	// connection was reestablished.
+	case lc.lostRevocation != nil:
+		nextRevocation = lc.lostRevocation
+	case len(lc.usedRevocations) == 0:
+		return nil, fmt.Errorf("received revocation without a pending " +
+			"commitment to revoke")
+	default:
+		nextRevocation = lc.usedRevocations[0]
+	}
 	lc.channelState.TheirCurrentRevocation = nextRevocation.NextRevocationKey
 	lc.channelState.TheirCurrentRevocationHash = nextRevocation.NextRevocationHash
+
+	// At this point, the revocation has been accepted, so we persist the
+	// elkrem receiver state, the delta of the revoked state, and the
+	// revoked commitment within a single database transaction. This write
+	// is the commit point of the state transition, so if it fails, the
+	// rotation above, and the swap of the elkrem receiver are rolled back.
+	prevElkrem := lc.channelState.RemoteElkrem
+	lc.channelState.RemoteElkrem = remoteElkrem
+	if err := lc.channelState.RecordRemoteRevocation(delta, revoked); err != nil {
+		lc.channelState.RemoteElkrem = prevElkrem
+		lc.channelState.TheirCurrentRevocation = prevRevocation
+		lc.channelState.TheirCurrentRevocationHash = prevRevocationHash
+		return nil, err
+	}
+	if revoked != nil {
+		lc.lastRevoked = revoked
+	}
+
+	// A retransmitted revocation was sent prior to the reconnection, so
+	// the window extension it carries has since been superseded by the
+	// remote party's initial revocation window. As their commitment chain
+	// has been rebuilt since then, it isn't advanced either.
+	if nextRevocation == lc.lostRevocation {
+		walletLog.Debugf("ChannelPoint(%v): received retransmitted "+
+			"revocation", lc.channelState.ChanID)
+
+		lc.lostRevocation = nil
+		return nil, nil
+	}
+
+	// With the state transition committed, advance the head of the
+	// revocation queue, and extend the end of our unused revocation queue
+	// with the newly extended revocation window update.
 	lc.usedRevocations[0] = nil // Prevent GC leak.
 	lc.usedRevocations = lc.usedRevocations[1:]
 	lc.revocationWindow = append(lc.revocationWindow, revMsg)
@@ -1115,22 +1791,19 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 		lc.remoteCommitChain.tail().height,
 		lc.remoteCommitChain.tail().height+1)
 
-	// At this point, the revocation has been accepted, and we've rotated
-	// the current revocation key+hash for the remote party. Therefore we
-	// sync now to ensure the elkrem receiver state is consistent with the
-	// current commitment height.
-	tail := lc.remoteCommitChain.tail()
-	delta, err := tail.toChannelDelta()
+	// Since they revoked the current lowest height in their commitment
+	// chain, we can advance their chain by a single commitment. Any
+	// signatures for commitments up to the new tail no longer need to be
+	// retransmitted.
+	lc.remoteCommitChain.advanceTail()
+	remoteTail := lc.remoteCommitChain.tail().height
+	err = lc.ackRetransmissions(func(r *retransmission) bool {
+		_, ok := r.msg.(*lnwire.CommitSignature)
+		return ok && r.height <= remoteTail
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err := lc.channelState.AppendToRevocationLog(delta); err != nil {
-		return nil, err
-	}
-
-	// Since they revoked the current lowest height in their commitment
-	// chain, we can advance their chain by a single commitment.
-	lc.remoteCommitChain.advanceTail()
 
 	remoteChainTail := lc.remoteCommitChain.tail().height
 	localChainTail := lc.localCommitChain.tail().height
@@ -1173,63 +1846,231 @@ func (lc *LightningChannel) ReceiveRevocation(revMsg *lnwire.CommitRevocation) (
 	return htlcsToForward, nil
 }
 
-// compactLogs performs garbage collection within the log removing HTLC's which
-// have been removed from the point-of-view of the tail of both chains. The
-// entries which timeout/settle HTLC's are also removed.
-func (lc *LightningChannel) compactLogs(ourLog, theirLog *list.List,
-	localChainTail, remoteChainTail uint64) {
+// LastRevokedCommitment returns the data required to punish a broadcast of
+// the most recently revoked remote commitment. If the remote party hasn't yet
+// revoked a commitment, then nil is returned.
+func (lc *LightningChannel) LastRevokedCommitment() *channeldb.RevokedCommitment {
+	return lc.lastRevoked
+}
 
-	compactLog := func(logA, logB *list.List, indexB, indexA map[uint32]*list.Element) {
-		var nextA *list.Element
-		for e := logA.Front(); e != nil; e = nextA {
-			nextA = e.Next()
+// dispatchUnilateralClose records the spend of the funding output, and
+// signals the close observer over the UnilateralCloseSignal. If the channel
+// doesn't already indicate that a commitment transaction has been broadcast
+// on-chain, then this means the remote party broadcasted their commitment
+// transaction.
+func (lc *LightningChannel) dispatchUnilateralClose(spend *chainntnfs.SpendDetail) {
+	lc.Lock()
+	defer lc.Unlock()
 
-			htlc := e.Value.(*PaymentDescriptor)
-			if htlc.EntryType == Add {
-				continue
-			}
+	if lc.status != channelDispute {
+		lc.closeSpend = spend
+		close(lc.UnilateralCloseSignal)
+		lc.status = channelDispute
+	}
+}
 
-			// If the HTLC hasn't yet been removed from either
-			// chain, the skip it.
-			if htlc.removeCommitHeightRemote == 0 ||
-				htlc.removeCommitHeightLocal == 0 {
-				continue
-			}
+// UnilateralCloseSummary returns the txid of the transaction with which the
+// remote party spent the funding output, along with whether it's one of
+// their revoked commitment transactions. A nil txid is returned if the
+// UnilateralCloseSignal hasn't yet been closed, or if the funding output was
+// spent while we were offline, and the spending transaction couldn't be
+// located.
+func (lc *LightningChannel) UnilateralCloseSummary() (*wire.ShaHash, bool, error) {
+	lc.RLock()
+	spend := lc.closeSpend
+	lc.RUnlock()
 
-			// Otherwise if the height of the tail of both chains
-			// is at least the height in which the HTLC was
-			// removed, then evict the settle/timeout entry along
-			// with the original add entry.
-			if remoteChainTail >= htlc.removeCommitHeightRemote &&
-				localChainTail >= htlc.removeCommitHeightLocal {
-				parentLink := indexB[htlc.ParentIndex]
-				parentIndex := parentLink.Value.(*PaymentDescriptor).Index
-				logB.Remove(parentLink)
+	if spend == nil {
+		return nil, false, nil
+	}
 
-				logA.Remove(e)
+	closeTxid := spend.SpendingTx.TxSha()
+	_, err := lc.channelState.FetchRevokedCommitmentByTxid(&closeTxid)
+	switch {
+	case err == channeldb.ErrRevokedCommitNotFound:
+		return &closeTxid, false, nil
+	case err != nil:
+		return nil, false, err
+	}
 
-				delete(indexB, parentIndex)
-				delete(indexA, htlc.Index)
-			}
+	return &closeTxid, true, nil
+}
 
-		}
+// CloseHeight returns the height at which the remote party's spend of the
+// funding output confirmed. Zero is returned if the spend was detected
+// within the mempool, or if the UnilateralCloseSignal hasn't yet been
+// closed.
+func (lc *LightningChannel) CloseHeight() uint32 {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	if lc.closeSpend == nil || lc.closeSpend.SpendingHeight < 0 {
+		return 0
 	}
+	return uint32(lc.closeSpend.SpendingHeight)
+}
 
-	compactLog(ourLog, theirLog, lc.theirLogIndex, lc.ourLogIndex)
-	compactLog(theirLog, ourLog, lc.ourLogIndex, lc.theirLogIndex)
+// LocalCommitKey returns our commitment key within the channel. This key is
+// required to sign for the revocation clauses of a revoked remote
+// commitment.
+func (lc *LightningChannel) LocalCommitKey() *btcec.PublicKey {
+	return lc.channelState.OurCommitKey
 }
 
-// ExtendRevocationWindow extends our revocation window by a single revocation,
-// increasing the number of new commitment updates the remote party can
-// initiate without our cooperation.
+// RemoteCsvDelay returns the relative delay in blocks of the remote party's
+// output within its commitment transactions.
+func (lc *LightningChannel) RemoteCsvDelay() uint32 {
+	return lc.channelState.RemoteCsvDelay
+}
+
+// newRevokedCommitment assembles all the information required to construct a
+// justice transaction for the passed, now revoked, remote commitment. The
+// witness script and colored amount of each output we'd be able to sweep are
+// recovered by re-deriving the scripts used to construct the commitment.
+func (lc *LightningChannel) newRevokedCommitment(commit *commitment,
+	revocationKey *btcec.PublicKey, revocationHash [32]byte,
+	revocationPreimage wire.ShaHash) (*channeldb.RevokedCommitment, error) {
+
+	revoked := &channeldb.RevokedCommitment{
+		UpdateNum:          commit.height,
+		CommitTxid:         commit.txn.TxSha(),
+		RevocationPreimage: revocationPreimage,
+		RevocationKey:      revocationKey,
+	}
+
+	// Build an index from output script to the witness script, and
+	// colored amount of each output we're able to sweep. First the
+	// remote party's delayed output.
+	type revokableScript struct {
+		witnessScript []byte
+		assetAmt      btcutil.Amount
+		isHTLC        bool
+		isIncoming    bool
+	}
+	scripts := make(map[string]*revokableScript)
+
+	delay := lc.channelState.RemoteCsvDelay
+	theirScript, err := commitScriptToSelf(delay,
+		lc.channelState.TheirCommitKey, revocationKey)
+	if err != nil {
+		return nil, err
+	}
+	theirPkScript, err := witnessScriptHash(theirScript)
+	if err != nil {
+		return nil, err
+	}
+	scripts[string(theirPkScript)] = &revokableScript{
+		witnessScript: theirScript,
+		assetAmt:      commit.theirBalance,
+	}
+
+	// Next, each of the HTLC's present within the commitment.
+	addHtlcScripts := func(htlcs []*PaymentDescriptor, isIncoming bool) error {
+		for _, htlc := range htlcs {
+			htlcScript, htlcPkScript, err := lc.htlcScripts(false,
+				htlc, revocationHash, delay, isIncoming)
+			if err != nil {
+				return err
+			}
+
+			scripts[string(htlcPkScript)] = &revokableScript{
+				witnessScript: htlcScript,
+				assetAmt:      htlc.Amount,
+				isHTLC:        true,
+				isIncoming:    isIncoming,
+			}
+		}
+		return nil
+	}
+	if err := addHtlcScripts(commit.outgoingHTLCs, false); err != nil {
+		return nil, err
+	}
+	if err := addHtlcScripts(commit.incomingHTLCs, true); err != nil {
+		return nil, err
+	}
+
+	for i, txOut := range commit.txn.TxOut {
+		script, ok := scripts[string(txOut.PkScript)]
+		if !ok {
+			continue
+		}
+
+		revoked.Outputs = append(revoked.Outputs, &channeldb.RevokedOutput{
+			Index:         uint32(i),
+			Value:         btcutil.Amount(txOut.Value),
+			AssetAmt:      script.assetAmt,
+			PkScript:      txOut.PkScript,
+			WitnessScript: script.witnessScript,
+			IsHTLC:        script.isHTLC,
+			IsIncoming:    script.isIncoming,
+		})
+	}
+
+	return revoked, nil
+}
+
+// compactLogs performs garbage collection within the log removing HTLC's which
+// have been removed from the point-of-view of the tail of both chains. The
+// entries which timeout/settle HTLC's are also removed.
+func (lc *LightningChannel) compactLogs(ourLog, theirLog *list.List,
+	localChainTail, remoteChainTail uint64) {
+
+	compactLog := func(logA, logB *list.List, indexB, indexA map[uint64]*list.Element) {
+		var nextA *list.Element
+		for e := logA.Front(); e != nil; e = nextA {
+			nextA = e.Next()
+
+			htlc := e.Value.(*PaymentDescriptor)
+			if htlc.EntryType == Add {
+				continue
+			}
+
+			// If the HTLC hasn't yet been removed from either
+			// chain, the skip it.
+			if htlc.removeCommitHeightRemote == 0 ||
+				htlc.removeCommitHeightLocal == 0 {
+				continue
+			}
+
+			// Otherwise if the height of the tail of both chains
+			// is at least the height in which the HTLC was
+			// removed, then evict the settle/timeout entry along
+			// with the original add entry.
+			if remoteChainTail >= htlc.removeCommitHeightRemote &&
+				localChainTail >= htlc.removeCommitHeightLocal {
+				parentLink := indexB[htlc.ParentIndex]
+				parentIndex := parentLink.Value.(*PaymentDescriptor).Index
+				logB.Remove(parentLink)
+
+				logA.Remove(e)
+
+				delete(indexB, parentIndex)
+				delete(indexA, htlc.Index)
+			}
+
+		}
+	}
+
+	compactLog(ourLog, theirLog, lc.theirLogIndex, lc.ourLogIndex)
+	compactLog(theirLog, ourLog, lc.ourLogIndex, lc.theirLogIndex)
+}
+
+// ExtendRevocationWindow extends our revocation window by a single revocation,
+// increasing the number of new commitment updates the remote party can
+// initiate without our cooperation. If the window already spans
+// InitialRevocationWindow commitments, then ErrRevocationWindowEdge is
+// returned.
 func (lc *LightningChannel) ExtendRevocationWindow() (*lnwire.CommitRevocation, error) {
-	/// TODO(roasbeef): error if window edge differs from tail by more than
-	// InitialRevocationWindow
+	// The edge of our window may never be more than
+	// InitialRevocationWindow commitments beyond our current commitment.
+	nextHeight := lc.revocationWindowEdge + 1
+	if nextHeight > lc.currentHeight+InitialRevocationWindow {
+		return nil, ErrRevocationWindowEdge
+	}
 
 	revMsg := &lnwire.CommitRevocation{}
 	revMsg.ChannelPoint = lc.channelState.ChanID
 
-	nextHeight := lc.revocationWindowEdge + 1
 	revocation, err := lc.channelState.LocalElkrem.AtIndex(nextHeight)
 	if err != nil {
 		return nil, err
@@ -1245,48 +2086,176 @@ func (lc *LightningChannel) ExtendRevocationWindow() (*lnwire.CommitRevocation,
 	return revMsg, nil
 }
 
+// hasActiveHTLC returns true if the passed update log contains an HTLC with
+// the target payment hash which hasn't yet been removed by a settle or timeout
+// entry within the opposing log.
+func hasActiveHTLC(addLog, removeLog *list.List, rHash PaymentHash) bool {
+	for e := addLog.Front(); e != nil; e = e.Next() {
+		htlc := e.Value.(*PaymentDescriptor)
+		if htlc.EntryType != Add || htlc.RHash != rHash {
+			continue
+		}
+
+		removed := false
+		for r := removeLog.Front(); r != nil; r = r.Next() {
+			entry := r.Value.(*PaymentDescriptor)
+			if entry.EntryType != Add && entry.ParentIndex == htlc.Index {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			return true
+		}
+	}
+
+	return false
+}
+
 // AddHTLC adds an HTLC to the state machine's local update log. This method
-// should be called when preparing to send an outgoing HTLC.
-// TODO(roasbeef): check for duplicates below? edge case during restart w/ HTLC
-// persistence
-func (lc *LightningChannel) AddHTLC(htlc *lnwire.HTLCAddRequest) uint32 {
+// should be called when preparing to send an outgoing HTLC. The ID of the
+// passed HTLC is set to its index within the log, allowing the remote party to
+// detect any retransmissions. If an outgoing HTLC with the same payment hash
+// is still active, then ErrDuplicatePaymentHash is returned and the log is
+// left unmodified.
+func (lc *LightningChannel) AddHTLC(htlc *lnwire.HTLCAddRequest) (uint64, error) {
+	if lc.isReorged() {
+		return 0, ErrChanReorged
+	}
+
+	rHash := PaymentHash(htlc.RedemptionHashes[0])
+	if hasActiveHTLC(lc.ourUpdateLog, lc.theirUpdateLog, rHash) {
+		return 0, ErrDuplicatePaymentHash
+	}
+	if lc.ourLogCounter >= maxLogIndex {
+		return 0, ErrLogIndexExhausted
+	}
+	if lc.numActiveHTLCs() >= maxCommitHTLCs {
+		return 0, ErrMaxHTLCsExceeded
+	}
+	if commitSatCost(lc.numActiveHTLCs()+1) > lc.BtcCapacity {
+		return 0, ErrCarrierBudgetExceeded
+	}
+
 	pd := &PaymentDescriptor{
 		EntryType: Add,
-		RHash:     PaymentHash(htlc.RedemptionHashes[0]),
+		RHash:     rHash,
 		Timeout:   htlc.Expiry,
 		Amount:    btcutil.Amount(htlc.Amount),
 		Index:     lc.ourLogCounter,
+		Payload:   htlc.OnionBlob,
 	}
 
 	lc.ourLogIndex[pd.Index] = lc.ourUpdateLog.PushBack(pd)
 	lc.ourLogCounter++
 
-	return pd.Index
+	htlc.ID = pd.Index
+
+	return pd.Index, nil
+}
+
+// setReorged transitions the channel into the channelReorged state once its
+// funding transaction has been re-org'd out, and back into the channelOpen
+// state once it's been re-confirmed. A channel in any other state, such as
+// one being closed, is left as is. True is returned if the status changed.
+func (lc *LightningChannel) setReorged(reorged bool) bool {
+	lc.Lock()
+	defer lc.Unlock()
+
+	switch {
+	case reorged && lc.status == channelOpen:
+		lc.status = channelReorged
+	case !reorged && lc.status == channelReorged:
+		lc.status = channelOpen
+	default:
+		return false
+	}
+
+	return true
+}
+
+// isReorged returns true if the funding transaction of the channel has been
+// re-org'd out, and has yet to be re-confirmed.
+func (lc *LightningChannel) isReorged() bool {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return lc.status == channelReorged
 }
 
 // ReceiveHTLC adds an HTLC to the state machine's remote update log. This
 // method should be called in response to receiving a new HTLC from the remote
-// party.
-func (lc *LightningChannel) ReceiveHTLC(htlc *lnwire.HTLCAddRequest) uint32 {
+// party. If the HTLC's ID shows it has already been added to the log, then
+// ErrReplayedHTLC is returned along with its index, and the log is left
+// unmodified. Similarly, if an incoming HTLC with the same payment hash is
+// still active, then ErrDuplicatePaymentHash is returned.
+func (lc *LightningChannel) ReceiveHTLC(htlc *lnwire.HTLCAddRequest) (uint64, error) {
+	if lc.isReorged() {
+		return 0, ErrChanReorged
+	}
+	if lc.theirLogCounter >= maxLogIndex {
+		return 0, ErrLogIndexExhausted
+	}
+
+	rHash := PaymentHash(htlc.RedemptionHashes[0])
+	amt := btcutil.Amount(htlc.Amount)
+
+	// As the remote party assigns IDs sequentially, an ID below our
+	// counter indicates a retransmission. If the original entry is still
+	// within the log, it must match the retransmitted HTLC exactly. If it
+	// has already been compacted, then the HTLC has been fully resolved.
+	switch {
+	case htlc.ID < lc.theirLogCounter:
+		if e, ok := lc.theirLogIndex[htlc.ID]; ok {
+			pd := e.Value.(*PaymentDescriptor)
+			if pd.RHash != rHash || pd.Amount != amt ||
+				pd.Timeout != htlc.Expiry {
+				return 0, fmt.Errorf("retransmitted htlc %v "+
+					"doesn't match log entry", htlc.ID)
+			}
+		}
+
+		return htlc.ID, ErrReplayedHTLC
+
+	case htlc.ID > lc.theirLogCounter:
+		return 0, fmt.Errorf("htlc id %v skips ahead of expected id %v",
+			htlc.ID, lc.theirLogCounter)
+	}
+
+	if hasActiveHTLC(lc.theirUpdateLog, lc.ourUpdateLog, rHash) {
+		return 0, ErrDuplicatePaymentHash
+	}
+	if lc.numActiveHTLCs() >= maxCommitHTLCs {
+		return 0, ErrMaxHTLCsExceeded
+	}
+	if commitSatCost(lc.numActiveHTLCs()+1) > lc.BtcCapacity {
+		return 0, ErrCarrierBudgetExceeded
+	}
+
 	pd := &PaymentDescriptor{
 		EntryType: Add,
-		RHash:     PaymentHash(htlc.RedemptionHashes[0]),
+		RHash:     rHash,
 		Timeout:   htlc.Expiry,
-		Amount:    btcutil.Amount(htlc.Amount),
+		Amount:    amt,
 		Index:     lc.theirLogCounter,
+		Payload:   htlc.OnionBlob,
 	}
 
 	lc.theirLogIndex[pd.Index] = lc.theirUpdateLog.PushBack(pd)
 	lc.theirLogCounter++
 
-	return pd.Index
+	return pd.Index, nil
 }
 
 // SettleHTLC attempst to settle an existing outstanding received HTLC. The
 // remote log index of the HTLC settled is returned in order to facilitate
 // creating the corresponding wire message. In the case the supplied pre-image
 // is invalid, an error is returned.
-func (lc *LightningChannel) SettleHTLC(preimage [32]byte) (uint32, error) {
+func (lc *LightningChannel) SettleHTLC(preimage [32]byte) (uint64, error) {
+	if lc.ourLogCounter >= maxLogIndex {
+		return 0, ErrLogIndexExhausted
+	}
+
 	var targetHTLC *list.Element
 
 	// TODO(roasbeef): optimize
@@ -1309,6 +2278,15 @@ func (lc *LightningChannel) SettleHTLC(preimage [32]byte) (uint32, error) {
 
 	parentPd := targetHTLC.Value.(*PaymentDescriptor)
 
+	// Before revealing the preimage to the remote party, persist it so
+	// we're able to claim the HTLC output on-chain in the case of a force
+	// close.
+	err := lc.channelState.Db.AddPreimage(preimage, parentPd.Timeout)
+	if err != nil {
+		parentPd.settled = false
+		return 0, err
+	}
+
 	// TODO(roasbeef): maybe make the log entries an interface?
 	pd := &PaymentDescriptor{
 		Amount:      parentPd.Amount,
@@ -1326,11 +2304,22 @@ func (lc *LightningChannel) SettleHTLC(preimage [32]byte) (uint32, error) {
 // ReceiveHTLCSettle attempts to settle an existing outgoing HTLC indexed by an
 // index into the local log. If the specified index doesn't exist within the
 // log, and error is returned. Similarly if the preimage is invalid w.r.t to
-// the referenced of then a distinct error is returned.
-func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, logIndex uint32) error {
+// the referenced of then a distinct error is returned. If the HTLC has already
+// been settled, then ErrReplayedHTLC is returned, and the log is left
+// unmodified.
+func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, logIndex uint64) error {
+	if lc.theirLogCounter >= maxLogIndex {
+		return ErrLogIndexExhausted
+	}
+
 	paymentHash := fastsha256.Sum256(preimage[:])
 	addEntry, ok := lc.ourLogIndex[logIndex]
 	if !ok {
+		// If the index was previously assigned, then the HTLC has
+		// already been settled, and compacted from the log.
+		if logIndex < lc.ourLogCounter {
+			return ErrReplayedHTLC
+		}
 		return fmt.Errorf("non existant log entry")
 	}
 
@@ -1339,6 +2328,19 @@ func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, logIndex uint32
 		return fmt.Errorf("invalid payment hash")
 	}
 
+	for e := lc.theirUpdateLog.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*PaymentDescriptor)
+		if entry.EntryType == Settle && entry.ParentIndex == logIndex {
+			return ErrReplayedHTLC
+		}
+	}
+
+	// Persist the preimage we've just learned, as it may be needed to
+	// claim a corresponding incoming HTLC on-chain.
+	if err := lc.channelState.Db.AddPreimage(preimage, htlc.Timeout); err != nil {
+		return err
+	}
+
 	pd := &PaymentDescriptor{
 		Amount:      htlc.Amount,
 		ParentIndex: htlc.Index,
@@ -1352,8 +2354,75 @@ func (lc *LightningChannel) ReceiveHTLCSettle(preimage [32]byte, logIndex uint32
 	return nil
 }
 
-// TimeoutHTLC...
-func (lc *LightningChannel) TimeoutHTLC() error {
+// TimeoutHTLC attempts to fail back an existing outstanding received HTLC,
+// indexed by its index within the remote log. Once the timeout entry is
+// committed, the value of the HTLC is returned to the remote party. If the
+// HTLC has already been settled or timed out, an error is returned.
+func (lc *LightningChannel) TimeoutHTLC(logIndex uint64) error {
+	if lc.ourLogCounter >= maxLogIndex {
+		return ErrLogIndexExhausted
+	}
+
+	addEntry, ok := lc.theirLogIndex[logIndex]
+	if !ok {
+		return fmt.Errorf("non existant log entry")
+	}
+
+	htlc := addEntry.Value.(*PaymentDescriptor)
+	if htlc.settled {
+		return fmt.Errorf("htlc %v has already been removed", logIndex)
+	}
+	htlc.settled = true
+
+	pd := &PaymentDescriptor{
+		Amount:      htlc.Amount,
+		Index:       lc.ourLogCounter,
+		ParentIndex: htlc.Index,
+		EntryType:   Timeout,
+	}
+
+	lc.ourUpdateLog.PushBack(pd)
+	lc.ourLogCounter++
+
+	return nil
+}
+
+// ReceiveHTLCTimeout attempts to remove an existing outgoing HTLC indexed by
+// an index into the local log, which the remote party has failed back. If the
+// specified index doesn't exist within the log, an error is returned. If the
+// HTLC has already been removed, then ErrReplayedHTLC is returned, and the
+// log is left unmodified.
+func (lc *LightningChannel) ReceiveHTLCTimeout(logIndex uint64) error {
+	if lc.theirLogCounter >= maxLogIndex {
+		return ErrLogIndexExhausted
+	}
+
+	addEntry, ok := lc.ourLogIndex[logIndex]
+	if !ok {
+		if logIndex < lc.ourLogCounter {
+			return ErrReplayedHTLC
+		}
+		return fmt.Errorf("non existant log entry")
+	}
+
+	for e := lc.theirUpdateLog.Front(); e != nil; e = e.Next() {
+		entry := e.Value.(*PaymentDescriptor)
+		if entry.EntryType != Add && entry.ParentIndex == logIndex {
+			return ErrReplayedHTLC
+		}
+	}
+
+	htlc := addEntry.Value.(*PaymentDescriptor)
+	pd := &PaymentDescriptor{
+		Amount:      htlc.Amount,
+		ParentIndex: htlc.Index,
+		Index:       lc.theirLogCounter,
+		EntryType:   Timeout,
+	}
+
+	lc.theirUpdateLog.PushBack(pd)
+	lc.theirLogCounter++
+
 	return nil
 }
 
@@ -1364,13 +2433,83 @@ func (lc *LightningChannel) ChannelPoint() *wire.OutPoint {
 	return lc.channelState.ChanID
 }
 
+// htlcOutput is the script metadata of an HTLC's output within a commitment
+// transaction. It's recorded as the output is created, so the output can
+// later be swept, or handed to a watchtower, without re-deriving its scripts.
+type htlcOutput struct {
+	// htlc is the HTLC the output pays to.
+	htlc *PaymentDescriptor
+
+	// isIncoming denotes whether we're the receiver of the HTLC.
+	isIncoming bool
+
+	// witnessScript is the witness script of the output, and pkScript is
+	// the p2wsh output script paying to it.
+	witnessScript []byte
+	pkScript      []byte
+
+	// outputIndex is the index of the output within the final commitment
+	// transaction, or -1 if it's yet to be located.
+	outputIndex int32
+}
+
 // addHTLC adds a new HTLC to the passed commitment transaction. One of four
 // full scripts will be generated for the HTLC output depending on if the HTLC
 // is incoming and if it's being applied to our commitment transaction or that
-// of the remote node's.
+// of the remote node's. The script metadata of the created output is
+// returned, though its output index remains unknown until the commitment
+// transaction is final, at which point it's located by locateHTLCOutputs.
 func (lc *LightningChannel) addHTLC(commitTx *wire.MsgTx, ourCommit bool,
 	paymentDesc *PaymentDescriptor, revocation [32]byte, delay uint32,
-	isIncoming bool) error {
+	isIncoming bool) (*htlcOutput, error) {
+
+	// Fetch the P2WSH public key script for the output itself, generating
+	// the redeem script it pays to unless it's been cached.
+	witnessScript, htlcP2WSH, err := lc.htlcScripts(ourCommit, paymentDesc,
+		revocation, delay, isIncoming)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the new HTLC outputs to the respective commitment transactions.
+	amountPending := int64(paymentDesc.Amount)
+	commitTx.AddTxOut(wire.NewTxOut(amountPending, htlcP2WSH))
+
+	return &htlcOutput{
+		htlc:          paymentDesc,
+		isIncoming:    isIncoming,
+		witnessScript: witnessScript,
+		pkScript:      htlcP2WSH,
+		outputIndex:   -1,
+	}, nil
+}
+
+// locateHTLCOutputs records the index of each HTLC output within the passed
+// final commitment transaction. Outputs are matched by their output script,
+// with HTLCs sharing an identical script assigned distinct outputs.
+func locateHTLCOutputs(commitTx *wire.MsgTx, outputs []*htlcOutput) {
+	claimed := make(map[int]struct{}, len(outputs))
+	for _, output := range outputs {
+		for i, txOut := range commitTx.TxOut {
+			if _, ok := claimed[i]; ok {
+				continue
+			}
+			if !bytes.Equal(txOut.PkScript, output.pkScript) {
+				continue
+			}
+
+			claimed[i] = struct{}{}
+			output.outputIndex = int32(i)
+			break
+		}
+	}
+}
+
+// genHtlcScript generates the witness script for an HTLC output on either our
+// commitment transaction or the remote party's.
+func (lc *LightningChannel) genHtlcScript(ourCommit bool,
+	paymentDesc *PaymentDescriptor, revocation [32]byte, delay uint32,
+	isIncoming bool) ([]byte, error) {
 
 	localKey := lc.channelState.OurCommitKey
 	remoteKey := lc.channelState.TheirCommitKey
@@ -1409,21 +2548,10 @@ func (lc *LightningChannel) addHTLC(commitTx *wire.MsgTx, ourCommit bool,
 			remoteKey, revocation[:], rHash[:])
 	}
 	if err != nil {
-		return err
-	}
-
-	// Now that we have the redeem scripts, create the P2WSH public key
-	// script for the output itself.
-	htlcP2WSH, err := witnessScriptHash(pkScript)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Add the new HTLC outputs to the respective commitment transactions.
-	amountPending := int64(paymentDesc.Amount)
-	commitTx.AddTxOut(wire.NewTxOut(amountPending, htlcP2WSH))
-
-	return nil
+	return pkScript, nil
 }
 
 // ForceCloseSummary describes the final commitment state before the channel is
@@ -1449,6 +2577,19 @@ type ForceCloseSummary struct {
 	// SelfOutputSignDesc is a fully populated sign descriptor capable of
 	// generating a valid signature to swee the self output.
 	SelfOutputSignDesc *SignDescriptor
+
+	// SelfOutputAssetAmt is the amount of the channel's asset assigned to
+	// the self output, which must be transferred by the transaction
+	// sweeping it.
+	SelfOutputAssetAmt btcutil.Amount
+
+	// AnchorOutpoint is our anchor output within the close tx, which can
+	// be spent immediately in order to fee bump the close tx via CPFP.
+	AnchorOutpoint wire.OutPoint
+
+	// AnchorSignDesc is a fully populated sign descriptor capable of
+	// generating a valid signature to spend our anchor output.
+	AnchorSignDesc *SignDescriptor
 }
 
 // ForceClose executes a unilateral closure of the transaction at the current
@@ -1492,22 +2633,6 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 		theirKey, theirSig)
 	commitTx.TxIn[0].Witness = witness
 
-	// Locate the output index of the delayed commitment output back to us.
-	// We'll return the details of this output to the caller so they can
-	// sweep it once it's mature.
-	// TODO(roasbeef): also return HTLC info, assumes only p2wsh is commit
-	// tx
-	var delayIndex uint32
-	var delayScript []byte
-	for i, txOut := range commitTx.TxOut {
-		if !txscript.IsPayToWitnessScriptHash(txOut.PkScript) {
-			continue
-		}
-
-		delayIndex = uint32(i)
-		delayScript = txOut.PkScript
-	}
-
 	csvTimeout := lc.channelState.LocalCsvDelay
 	selfKey := lc.channelState.OurCommitKey
 
@@ -1525,17 +2650,56 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 	if err != nil {
 		return nil, err
 	}
+	delayScript, err := witnessScriptHash(selfScript)
+	if err != nil {
+		return nil, err
+	}
+
+	// Locate the output index of the delayed commitment output back to us.
+	// We'll return the details of this output to the caller so they can
+	// sweep it once it's mature. As both the anchors, and any HTLC outputs
+	// are also p2wsh, the output is located by its exact script.
+	// TODO(roasbeef): also return HTLC info
+	foundDelay, delayIndex := FindScriptOutputIndex(commitTx, delayScript)
+	var delayValue int64
+	if foundDelay {
+		delayValue = commitTx.TxOut[delayIndex].Value
+	}
+
+	// Similarly, locate our anchor output so the caller is able to fee
+	// bump the commitment transaction if it fails to confirm in a timely
+	// manner.
+	anchorWitnessScript, err := anchorScript(selfKey)
+	if err != nil {
+		return nil, err
+	}
+	anchorPkScript, err := witnessScriptHash(anchorWitnessScript)
+	if err != nil {
+		return nil, err
+	}
+	_, anchorIndex := FindScriptOutputIndex(commitTx, anchorPkScript)
+	anchorSignDesc := &SignDescriptor{
+		PubKey:       selfKey,
+		RedeemScript: anchorWitnessScript,
+		Output: &wire.TxOut{
+			PkScript: anchorPkScript,
+			Value:    int64(anchorSize),
+		},
+		HashType: txscript.SigHashAll,
+	}
 
 	// With the necessary information gatehred above, create a new sign
 	// descriptor which is capable of generating the signature the caller
 	// needs to sweep this output. The hash cache, and input index are not
 	// set as the caller will decide these values once sweeping the output.
+	// @CC: the output carries a dust amount of satoshis, which is what
+	// the signature commits to, while our balance is its asset amount.
 	selfSignDesc := &SignDescriptor{
 		PubKey:       selfKey,
 		RedeemScript: selfScript,
 		Output: &wire.TxOut{
 			PkScript: delayScript,
-			Value:    int64(lc.channelState.OurBalance),
+			Value:    delayValue,
 		},
 		HashType: txscript.SigHashAll,
 	}
@@ -1554,6 +2718,12 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 		},
 		SelfOutputMaturity: csvTimeout,
 		SelfOutputSignDesc: selfSignDesc,
+		SelfOutputAssetAmt: lc.channelState.OurBalance,
+		AnchorOutpoint: wire.OutPoint{
+			Hash:  commitTx.TxSha(),
+			Index: anchorIndex,
+		},
+		AnchorSignDesc: anchorSignDesc,
 	}, nil
 }
 
@@ -1568,9 +2738,17 @@ func (lc *LightningChannel) ForceClose() (*ForceCloseSummary, error) {
 // of an unresponsive remote party, the initiator can either choose to execute
 // a force closure, or backoff for a period of time, and retry the cooperative
 // closure.
+//
+// If deliveryScript is non-nil, then our settled balance is sent to it rather
+// than to the delivery script negotiated during the funding workflow, allowing
+// the asset to be sent straight to an exchange deposit address, or a cold
+// wallet. The remote party must be sent the same script in order to arrive at
+// the same closing transaction.
 // TODO(roasbeef): caller should initiate signal to reject all incoming HTLCs,
 // settle any inflight.
-func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error) {
+func (lc *LightningChannel) InitCooperativeClose(
+	deliveryScript []byte) ([]byte, *wire.ShaHash, error) {
+
 	lc.Lock()
 	defer lc.Unlock()
 
@@ -1580,17 +2758,28 @@ func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error
 		return nil, nil, ErrChanClosing
 	}
 
-	// Otherwise, indicate in the channel status that a channel closure has
-	// been initiated.
-	lc.status = channelClosing
+	ourScript := lc.channelState.OurDeliveryScript
+	if deliveryScript != nil {
+		if err := ValidateDeliveryScript(deliveryScript); err != nil {
+			return nil, nil, err
+		}
+		ourScript = deliveryScript
+	}
 
 	// TODO(roasbeef): assumes initiator pays fees
-	closeTx := CreateCooperativeCloseTx(lc.fundingTxIn,
+	closeTx, err := CreateCooperativeCloseTx(lc.fundingTxIn, lc.BtcCapacity,
 		lc.channelState.OurBalance, lc.channelState.TheirBalance,
-		lc.channelState.OurDeliveryScript, lc.channelState.TheirDeliveryScript,
-		true)
+		ourScript, lc.channelState.TheirDeliveryScript, true,
+		&lc.ClosePolicy)
+	if err != nil {
+		return nil, nil, err
+	}
 	closeTxSha := closeTx.TxSha()
 
+	// Otherwise, indicate in the channel status that a channel closure has
+	// been initiated.
+	lc.status = channelClosing
+
 	// Finally, sign the completed cooperative closure transaction. As the
 	// initiator we'll simply send our signature over the the remote party,
 	// using the generated txid to be notified once the closure transaction
@@ -1610,9 +2799,15 @@ func (lc *LightningChannel) InitCooperativeClose() ([]byte, *wire.ShaHash, error
 // transaction is returned. It is the duty of the responding node to broadcast
 // a signed+valid closure transaction to the network.
 //
+// If the remote party has overridden their delivery script for the closure,
+// then it should be passed as remoteDeliveryScript. Otherwise, it should be
+// nil, and the delivery script negotiated during the funding workflow is used.
+//
 // NOTE: The passed remote sig is expected to the a fully complete signature
 // including the proper sighash byte.
-func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.MsgTx, error) {
+func (lc *LightningChannel) CompleteCooperativeClose(remoteSig,
+	remoteDeliveryScript []byte) (*wire.MsgTx, error) {
+
 	lc.Lock()
 	defer lc.Unlock()
 
@@ -1622,15 +2817,26 @@ func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.Ms
 		return nil, ErrChanClosing
 	}
 
-	lc.status = channelClosed
+	theirScript := lc.channelState.TheirDeliveryScript
+	if remoteDeliveryScript != nil {
+		if err := ValidateDeliveryScript(remoteDeliveryScript); err != nil {
+			return nil, err
+		}
+		theirScript = remoteDeliveryScript
+	}
 
 	// Create the transaction used to return the current settled balance
 	// on this active channel back to both parties. In this current model,
 	// the initiator pays full fees for the cooperative close transaction.
-	closeTx := CreateCooperativeCloseTx(lc.fundingTxIn,
+	closeTx, err := CreateCooperativeCloseTx(lc.fundingTxIn, lc.BtcCapacity,
 		lc.channelState.OurBalance, lc.channelState.TheirBalance,
-		lc.channelState.OurDeliveryScript, lc.channelState.TheirDeliveryScript,
-		false)
+		lc.channelState.OurDeliveryScript, theirScript, false,
+		&lc.ClosePolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.status = channelClosed
 
 	// With the transaction created, we can finally generate our half of
 	// the 2-of-2 multi-sig needed to redeem the funding output.
@@ -1654,7 +2860,7 @@ func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.Ms
 	// properly met, and that the remote peer supplied a valid signature.
 	vm, err := txscript.NewEngine(lc.fundingP2WSH, closeTx, 0,
 		txscript.StandardVerifyFlags, nil, hashCache,
-		int64(lc.channelState.Capacity))
+		int64(lc.BtcCapacity))
 	if err != nil {
 		return nil, err
 	}
@@ -1667,9 +2873,73 @@ func (lc *LightningChannel) CompleteCooperativeClose(remoteSig []byte) (*wire.Ms
 
 // DeleteState deletes all state concerning the channel from the underlying
 // database, only leaving a small summary describing meta-data of the
-// channel's lifetime.
-func (lc *LightningChannel) DeleteState() error {
-	return lc.channelState.CloseChannel()
+// channel's lifetime. The summary records how the channel was closed, the
+// closing txid, and the height at which the closing transaction confirmed.
+func (lc *LightningChannel) DeleteState(closeType channeldb.ClosureType,
+	closingTxid *wire.ShaHash, closeHeight uint32) error {
+
+	lc.stateMtx.RLock()
+	summary := &channeldb.ChannelCloseSummary{
+		ChanPoint:    *lc.channelState.ChanID,
+		RemoteID:     lc.channelState.TheirLNID,
+		AssetID:      lc.channelState.AssetID,
+		Capacity:     lc.channelState.AssetCapacity,
+		OurBalance:   lc.channelState.OurBalance,
+		TheirBalance: lc.channelState.TheirBalance,
+		CloseType:    closeType,
+		CloseHeight:  closeHeight,
+	}
+	lc.stateMtx.RUnlock()
+
+	if closingTxid != nil {
+		summary.ClosingTXID = *closingTxid
+	}
+
+	return lc.channelState.CloseChannel(summary)
+}
+
+// AvailableBalance returns the amount we're able to send within new outgoing
+// HTLCs. Our balance is taken as the lowest across all unrevoked commitments
+// in both commitment chains, as any of them may still be broadcast. From this,
+// outgoing HTLCs which have been added to the update log, but not yet covered
+// by a commitment, and the channel reserve are subtracted. The payment
+// dispatcher should consult this before attempting to send, as an HTLC
+// exceeding this amount would only fail once a new commitment is signed.
+func (lc *LightningChannel) AvailableBalance() btcutil.Amount {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	balance := lc.channelState.OurBalance
+	for _, chain := range []*commitmentChain{lc.localCommitChain,
+		lc.remoteCommitChain} {
+
+		for e := chain.commitments.Front(); e != nil; e = e.Next() {
+			commit := e.Value.(*commitment)
+			if commit.ourBalance < balance {
+				balance = commit.ourBalance
+			}
+		}
+	}
+
+	// Any outgoing HTLCs which haven't yet been included within the latest
+	// remote commitment have yet to be debited from our balance.
+	var pendingIndex uint64
+	if lc.remoteCommitChain.commitments.Len() != 0 {
+		pendingIndex = lc.remoteCommitChain.tip().ourMessageIndex
+	}
+	for e := lc.ourUpdateLog.Front(); e != nil; e = e.Next() {
+		htlc := e.Value.(*PaymentDescriptor)
+		if htlc.EntryType == Add && htlc.Index >= pendingIndex {
+			balance -= htlc.Amount
+		}
+	}
+
+	balance -= lc.channelState.AssetCapacity / chanReserveDivisor
+	if balance < 0 {
+		return 0
+	}
+
+	return balance
 }
 
 // StateSnapshot returns a snapshot of the current fully committed state within
@@ -1690,6 +2960,22 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 	revokeKey *btcec.PublicKey, csvTimeout uint32, amountToSelf,
 	amountToThem btcutil.Amount) (*wire.MsgTx, error) {
 
+	commitTx := wire.NewMsgTx()
+	err := createCommitTx(commitTx, fundingOutput, selfKey, theirKey,
+		revokeKey, csvTimeout, amountToSelf, amountToThem)
+	if err != nil {
+		return nil, err
+	}
+
+	return commitTx, nil
+}
+
+// createCommitTx carries out CreateCommitTx, populating the passed empty
+// transaction rather than allocating a new one.
+func createCommitTx(commitTx *wire.MsgTx, fundingOutput *wire.TxIn, selfKey,
+	theirKey *btcec.PublicKey, revokeKey *btcec.PublicKey, csvTimeout uint32,
+	amountToSelf, amountToThem btcutil.Amount) error {
+
 	// First, we create the script for the delayed "pay-to-self" output.
 	// This output has 2 main redemption clauses: either we can redeem the
 	// output after a relative block delay, or the remote node can claim
@@ -1698,24 +2984,23 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 	ourRedeemScript, err := commitScriptToSelf(csvTimeout, selfKey,
 		revokeKey)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	payToUsScriptHash, err := witnessScriptHash(ourRedeemScript)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Next, we create the script paying to them. This is just a regular
 	// P2WKH output, without any added CSV delay.
 	theirWitnessKeyHash, err := commitScriptUnencumbered(theirKey)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Now that both output scripts have been created, we can finally create
-	// the transaction itself. We use a transaction version of 2 since CSV
-	// will fail unless the tx version is >= 2.
-	commitTx := wire.NewMsgTx()
+	// Now that both output scripts have been created, we can finally
+	// populate the transaction itself. We use a transaction version of 2
+	// since CSV will fail unless the tx version is >= 2.
 	commitTx.Version = 2
 	commitTx.AddTxIn(fundingOutput)
 
@@ -1727,7 +3012,159 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 		commitTx.AddTxOut(wire.NewTxOut(int64(amountToThem), theirWitnessKeyHash))
 	}
 
-	return commitTx, nil
+	return nil
+}
+
+// commitTxPool recycles the uncolored commitment transactions constructed
+// within fetchCommitmentView. These only serve as templates for the colorified
+// commitments, so they're no longer needed once those have been created.
+var commitTxPool = sync.Pool{
+	New: func() interface{} {
+		return wire.NewMsgTx()
+	},
+}
+
+// fetchCommitTemplate returns an empty transaction from the commitTxPool,
+// with room for at least numOutputs outputs.
+func fetchCommitTemplate(numOutputs int) *wire.MsgTx {
+	commitTx := commitTxPool.Get().(*wire.MsgTx)
+	if cap(commitTx.TxOut) < numOutputs {
+		commitTx.TxOut = make([]*wire.TxOut, 0, numOutputs)
+	}
+
+	return commitTx
+}
+
+// releaseCommitTemplate resets the passed transaction obtained via
+// fetchCommitTemplate, and returns it to the commitTxPool. The transaction
+// MUST NOT be referenced once released.
+func releaseCommitTemplate(commitTx *wire.MsgTx) {
+	// Drop the references to the inputs and outputs, so they don't
+	// outlive the transaction while it sits within the pool.
+	for i := range commitTx.TxIn {
+		commitTx.TxIn[i] = nil
+	}
+	for i := range commitTx.TxOut {
+		commitTx.TxOut[i] = nil
+	}
+	commitTx.TxIn = commitTx.TxIn[:0]
+	commitTx.TxOut = commitTx.TxOut[:0]
+	commitTx.Version = wire.TxVersion
+	commitTx.LockTime = 0
+
+	commitTxPool.Put(commitTx)
+}
+
+// addCommitAnchors attaches an anchor output for each party to a colorified
+// commitment transaction, allowing either side to fee bump the commitment
+// transaction via CPFP once broadcast. The anchors are added after the
+// transaction has been colorified so they aren't assigned any of the
+// channel's colored coins, and are sorted by their public key script so both
+// parties arrive at the same transaction.
+func addCommitAnchors(commitTx *wire.MsgTx, selfKey,
+	theirKey *btcec.PublicKey) error {
+
+	anchors := make([]*wire.TxOut, 0, 2)
+	for _, key := range []*btcec.PublicKey{selfKey, theirKey} {
+		script, err := anchorScript(key)
+		if err != nil {
+			return err
+		}
+		pkScript, err := witnessScriptHash(script)
+		if err != nil {
+			return err
+		}
+
+		anchors = append(anchors, wire.NewTxOut(int64(anchorSize),
+			pkScript))
+	}
+
+	if bytes.Compare(anchors[0].PkScript, anchors[1].PkScript) > 0 {
+		anchors[0], anchors[1] = anchors[1], anchors[0]
+	}
+	commitTx.TxOut = append(commitTx.TxOut, anchors...)
+
+	return nil
+}
+
+// checkCommitStandard ensures the passed colorified commitment transaction
+// will be relayed once signed: its projected weight must be within the
+// standard limit, and its colored coins instructions must fit within the
+// OP_RETURN payload limit. ErrCommitmentNonStandard is returned otherwise.
+func checkCommitStandard(commitTx *wire.MsgTx) error {
+	weight := commitTx.SerializeSize()*4 + commitWitnessWeight
+	if weight > maxStandardTxWeight {
+		walletLog.Warnf("Commitment transaction weight %v exceeds %v",
+			weight, maxStandardTxWeight)
+		return ErrCommitmentNonStandard
+	}
+
+	for _, txOut := range commitTx.TxOut {
+		script := txOut.PkScript
+		if len(script) == 0 || script[0] != txscript.OP_RETURN {
+			continue
+		}
+
+		// The script consists of the OP_RETURN, the length prefix,
+		// and the payload itself.
+		payloadSize := len(script) - 2
+		if payloadSize > maxInstructionPayload {
+			walletLog.Warnf("Commitment instruction payload of %v "+
+				"bytes exceeds %v", payloadSize,
+				maxInstructionPayload)
+			return ErrCommitmentNonStandard
+		}
+	}
+
+	return nil
+}
+
+// commitSatCost returns the worst case value in satoshis carried by the
+// outputs of a commitment transaction holding the passed number of HTLC
+// outputs: a carrier amount for both balance outputs, and each HTLC output,
+// along with both anchors. The OP_RETURN output carries nothing.
+func commitSatCost(numHTLCs int) btcutil.Amount {
+	numCarriers := btcutil.Amount(2 + numHTLCs)
+	return numCarriers*lndcc.CarrierValue() + 2*anchorSize
+}
+
+// checkCarrierBudget returns the total value in satoshis carried by the
+// outputs of the passed colorified commitment transaction, ensuring it
+// doesn't exceed the value of the funding output. ErrCarrierBudgetExceeded
+// is returned otherwise, as such a commitment could never be broadcast.
+func (lc *LightningChannel) checkCarrierBudget(
+	commitTx *wire.MsgTx) (btcutil.Amount, error) {
+
+	var satCost btcutil.Amount
+	for _, txOut := range commitTx.TxOut {
+		satCost += btcutil.Amount(txOut.Value)
+	}
+
+	if satCost > lc.BtcCapacity {
+		walletLog.Warnf("ChannelPoint(%v): commitment outputs carry "+
+			"%v, exceeding funding output value of %v",
+			lc.channelState.ChanID, satCost, lc.BtcCapacity)
+		return 0, ErrCarrierBudgetExceeded
+	}
+
+	return satCost, nil
+}
+
+// numActiveHTLCs returns the number of HTLCs across both update logs which
+// have yet to be settled or timed out.
+func (lc *LightningChannel) numActiveHTLCs() int {
+	var numHTLCs int
+	for _, updateLog := range []*list.List{lc.ourUpdateLog, lc.theirUpdateLog} {
+		for e := updateLog.Front(); e != nil; e = e.Next() {
+			if e.Value.(*PaymentDescriptor).EntryType == Add {
+				numHTLCs++
+			} else {
+				numHTLCs--
+			}
+		}
+	}
+
+	return numHTLCs
 }
 
 // CreateCooperativeCloseTx creates a transaction which if signed by both
@@ -1735,11 +3172,13 @@ func CreateCommitTx(fundingOutput *wire.TxIn, selfKey, theirKey *btcec.PublicKey
 // of the closure transaction is modified by a boolean indicating if the party
 // constructing the channel is the initiator of the closure. Currently it is
 // expected that the initiator pays the transaction fees for the closing
-// transaction in full.
+// transaction in full. The value of the colored outputs, and the folding of
+// sub-threshold balances are determined by the passed policy, with the
+// outputs paid out of the satoshis carried by the funding output.
 func CreateCooperativeCloseTx(fundingTxIn *wire.TxIn,
-	ourBalance, theirBalance btcutil.Amount,
+	fundingValue, ourBalance, theirBalance btcutil.Amount,
 	ourDeliveryScript, theirDeliveryScript []byte,
-	initiator bool) *wire.MsgTx {
+	initiator bool, policy *CloseOutputPolicy) (*wire.MsgTx, error) {
 
 	// Construct the transaction to perform a cooperative closure of the
 	// channel. In the event that one side doesn't have any settled funds
@@ -1758,8 +3197,12 @@ func CreateCooperativeCloseTx(fundingTxIn *wire.TxIn,
 		theirBalance -= 5000
 	}*/
 
-	// TODO(roasbeef): dust check...
-	//  * although upper layers should prevent
+	// Rather than give a party a colored output carrying a tiny asset
+	// balance, fold it into the counterparty's output as the policy
+	// dictates, compensating the party in satoshis.
+	ourBalance, theirBalance, ourComp, theirComp := policy.foldBalances(
+		ourBalance, theirBalance)
+
 	if ourBalance != 0 {
 		closeTx.AddTxOut(&wire.TxOut{
 			PkScript: ourDeliveryScript,
@@ -1773,13 +3216,28 @@ func CreateCooperativeCloseTx(fundingTxIn *wire.TxIn,
 		})
 	}
 
-	txsort.InPlaceSort(closeTx)
-
-	closeTx, err := lndcc.ColorifyTx(closeTx, false)
+	closeTx, err := colorifyCanonical(closeTx, false)
 	if err != nil {
-		// nadav @TODO return (error, MsgTx) and propagate errors
-		log.Fatal("unable to colorify: %v", err)
+		return nil, err
 	}
 
-	return closeTx
+	// Each output preceding the OP_RETURN output carries a balance, and
+	// is given the policy's carrier amount in place of the dust amount
+	// assigned when colorified.
+	for _, txOut := range closeTx.TxOut[:len(closeTx.TxOut)-1] {
+		txOut.Value = int64(policy.CarrierAmount)
+	}
+	addCompensationOutput(closeTx, ourComp, theirComp, ourDeliveryScript,
+		theirDeliveryScript)
+
+	var totalOut btcutil.Amount
+	for _, txOut := range closeTx.TxOut {
+		totalOut += btcutil.Amount(txOut.Value)
+	}
+	if totalOut > fundingValue {
+		return nil, fmt.Errorf("close outputs totalling %v exceed the "+
+			"funding output's value of %v", totalOut, fundingValue)
+	}
+
+	return closeTx, nil
 }
@@ -0,0 +1,213 @@
+package lnwallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// colorEncoder selects the color encoder used within the benchmarks below.
+// The native encoder runs in-process, isolating the cost of the channel state
+// machine itself, while the http encoder goes through the cc-encoding-api at
+// CC_ENCODING_URL, or a local stand-in for it if unset. For example:
+//
+//	go test -run=^$ -bench=Commitment -colorencoder=http -cpuprofile=cpu.out
+var colorEncoder = flag.String("colorencoder", "native",
+	"color encoder to benchmark commitments with: native or http")
+
+// benchHTLCCounts are the numbers of active HTLCs the commitments within the
+// benchmarks carry, up to the maximum a commitment may carry.
+var benchHTLCCounts = []int{0, 2, 4, maxCommitHTLCs}
+
+// encodeTestInstructions encodes the passed instructions as a 4 byte header
+// followed by an 8 byte entry for each instruction: the output index (1), and
+// the amount (7). This matches the worst case size of the cc-encoding-api's
+// encoding.
+func encodeTestInstructions(insts []lndcc.Instruction) ([]byte, error) {
+	payload := []byte("CC\x02\x15")
+	for _, inst := range insts {
+		var scratch [ccMaxInstructionSize]byte
+		binary.BigEndian.PutUint64(scratch[:], uint64(inst.Amount))
+		scratch[0] = byte(inst.Output)
+		payload = append(payload, scratch[:]...)
+	}
+
+	return payload, nil
+}
+
+// setBenchEncoder swaps in the color encoder selected by the colorencoder
+// flag, returning a function which restores the prior encoder.
+func setBenchEncoder(b *testing.B) func() {
+	prevEncoder := lndcc.Encoder
+	restore := func() {
+		lndcc.Encoder = prevEncoder
+	}
+
+	switch *colorEncoder {
+	case "native":
+		lndcc.Encoder = encodeTestInstructions
+		return restore
+
+	case "http":
+		if url := os.Getenv("CC_ENCODING_URL"); url != "" {
+			lndcc.Encoder = lndcc.HTTPEncoder(url)
+			return restore
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(
+			func(w http.ResponseWriter, r *http.Request) {
+				var insts []lndcc.Instruction
+				err := json.NewDecoder(r.Body).Decode(&insts)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				payload, _ := encodeTestInstructions(insts)
+				w.Write(payload)
+			},
+		))
+		lndcc.Encoder = lndcc.HTTPEncoder(server.URL)
+		return func() {
+			server.Close()
+			restore()
+		}
+
+	default:
+		b.Fatalf("unknown color encoder: %v", *colorEncoder)
+		return nil
+	}
+}
+
+// createBenchChannels creates a pair of test channels with numHTLCs HTLCs
+// added by Alice locked into the commitments of both sides.
+func createBenchChannels(b *testing.B, numHTLCs int) (*LightningChannel,
+	*LightningChannel, func()) {
+
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		b.Fatalf("unable to create test channels: %v", err)
+	}
+
+	for i := 0; i < numHTLCs; i++ {
+		preimage := bytes.Repeat([]byte{byte(i)}, 32)
+		htlc := &lnwire.HTLCAddRequest{
+			ID:               uint64(i),
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage)},
+			Amount:           lnwire.CreditsAmount(1e6),
+			Expiry:           uint32(5),
+		}
+		if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+			b.Fatalf("unable to add htlc: %v", err)
+		}
+		if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+			b.Fatalf("unable to receive htlc: %v", err)
+		}
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		b.Fatalf("unable to complete state transition: %v", err)
+	}
+
+	return aliceChannel, bobChannel, cleanUp
+}
+
+// completeTransition completes the state transition initiated by Alice's
+// signature once Bob has received it.
+func completeTransition(b *testing.B, aliceChannel, bobChannel *LightningChannel) {
+	bobSig, aliceIndex, err := bobChannel.SignNextCommitment()
+	if err != nil {
+		b.Fatalf("bob unable to sign commitment: %v", err)
+	}
+	bobRevocation, err := bobChannel.RevokeCurrentCommitment()
+	if err != nil {
+		b.Fatalf("bob unable to revoke commitment: %v", err)
+	}
+	if err := aliceChannel.ReceiveNewCommitment(bobSig, aliceIndex); err != nil {
+		b.Fatalf("alice unable to receive commitment: %v", err)
+	}
+	aliceRevocation, err := aliceChannel.RevokeCurrentCommitment()
+	if err != nil {
+		b.Fatalf("alice unable to revoke commitment: %v", err)
+	}
+	if _, err := aliceChannel.ReceiveRevocation(bobRevocation); err != nil {
+		b.Fatalf("alice unable to receive revocation: %v", err)
+	}
+	if _, err := bobChannel.ReceiveRevocation(aliceRevocation); err != nil {
+		b.Fatalf("bob unable to receive revocation: %v", err)
+	}
+}
+
+// BenchmarkSignNextCommitment measures the time it takes to construct and
+// sign a new commitment for the remote party.
+func BenchmarkSignNextCommitment(b *testing.B) {
+	defer setBenchEncoder(b)()
+
+	for _, numHTLCs := range benchHTLCCounts {
+		b.Run(fmt.Sprintf("htlcs=%v", numHTLCs), func(b *testing.B) {
+			aliceChannel, bobChannel, cleanUp := createBenchChannels(b,
+				numHTLCs)
+			defer cleanUp()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				aliceSig, bobIndex, err := aliceChannel.SignNextCommitment()
+				if err != nil {
+					b.Fatalf("unable to sign commitment: %v", err)
+				}
+
+				b.StopTimer()
+				err = bobChannel.ReceiveNewCommitment(aliceSig, bobIndex)
+				if err != nil {
+					b.Fatalf("unable to receive commitment: %v", err)
+				}
+				completeTransition(b, aliceChannel, bobChannel)
+				b.StartTimer()
+			}
+		})
+	}
+}
+
+// BenchmarkReceiveNewCommitment measures the time it takes to construct a new
+// local commitment and verify the remote party's signature for it.
+func BenchmarkReceiveNewCommitment(b *testing.B) {
+	defer setBenchEncoder(b)()
+
+	for _, numHTLCs := range benchHTLCCounts {
+		b.Run(fmt.Sprintf("htlcs=%v", numHTLCs), func(b *testing.B) {
+			aliceChannel, bobChannel, cleanUp := createBenchChannels(b,
+				numHTLCs)
+			defer cleanUp()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				aliceSig, bobIndex, err := aliceChannel.SignNextCommitment()
+				if err != nil {
+					b.Fatalf("unable to sign commitment: %v", err)
+				}
+				b.StartTimer()
+
+				err = bobChannel.ReceiveNewCommitment(aliceSig, bobIndex)
+				if err != nil {
+					b.Fatalf("unable to receive commitment: %v", err)
+				}
+
+				b.StopTimer()
+				completeTransition(b, aliceChannel, bobChannel)
+				b.StartTimer()
+			}
+		})
+	}
+}
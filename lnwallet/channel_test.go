@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
+	"reflect"
+	"sync/atomic"
 	"testing"
 
 	"github.com/btcsuite/fastsha256"
@@ -12,6 +15,7 @@ import (
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/elkrem"
+	"github.com/lightningnetwork/lnd/lndcc"
 	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/chaincfg"
@@ -61,6 +65,10 @@ func (m *mockSigner) SignOutputRaw(tx *wire.MsgTx, signDesc *SignDescriptor) ([]
 	redeemScript := signDesc.RedeemScript
 	privKey := m.key
 
+	if signDesc.PrivateTweak != nil {
+		privKey = DeriveRevocationPrivKey(privKey, signDesc.PrivateTweak)
+	}
+
 	sig, err := txscript.RawTxInWitnessSignature(tx, signDesc.SigHashes,
 		signDesc.InputIndex, amt, redeemScript, txscript.SigHashAll, privKey)
 	if err != nil {
@@ -92,7 +100,14 @@ func (m *mockNotfier) Stop() error {
 }
 func (m *mockNotfier) RegisterSpendNtfn(outpoint *wire.OutPoint) (*chainntnfs.SpendEvent, error) {
 	return &chainntnfs.SpendEvent{
-		Spend: make(chan *chainntnfs.SpendDetail),
+		Spend:  make(chan *chainntnfs.SpendDetail),
+		Cancel: func() {},
+	}, nil
+}
+func (m *mockNotfier) RegisterMempoolSpendNtfn(outpoint *wire.OutPoint) (*chainntnfs.SpendEvent, error) {
+	return &chainntnfs.SpendEvent{
+		Spend:  make(chan *chainntnfs.SpendDetail),
+		Cancel: func() {},
 	}, nil
 }
 
@@ -233,7 +248,8 @@ func createTestChannels(revocationWindow int) (*LightningChannel, *LightningChan
 		ChanID:                 prevOut,
 		OurCommitKey:           aliceKeyPub,
 		TheirCommitKey:         bobKeyPub,
-		Capacity:               channelCapacity,
+		AssetCapacity:          channelCapacity,
+		BtcCapacity:            channelCapacity,
 		OurBalance:             channelBal,
 		TheirBalance:           channelBal,
 		OurCommitTx:            aliceCommitTx,
@@ -253,7 +269,8 @@ func createTestChannels(revocationWindow int) (*LightningChannel, *LightningChan
 		ChanID:                 prevOut,
 		OurCommitKey:           bobKeyPub,
 		TheirCommitKey:         aliceKeyPub,
-		Capacity:               channelCapacity,
+		AssetCapacity:          channelCapacity,
+		BtcCapacity:            channelCapacity,
 		OurBalance:             channelBal,
 		TheirBalance:           channelBal,
 		OurCommitTx:            bobCommitTx,
@@ -566,12 +583,12 @@ func TestCooperativeChannelClosure(t *testing.T) {
 	defer cleanUp()
 
 	// First we test the channel initiator requesting a cooperative close.
-	sig, txid, err := aliceChannel.InitCooperativeClose()
+	sig, txid, err := aliceChannel.InitCooperativeClose(nil)
 	if err != nil {
 		t.Fatalf("unable to initiate alice cooperative close: %v", err)
 	}
 	finalSig := append(sig, byte(txscript.SigHashAll))
-	closeTx, err := bobChannel.CompleteCooperativeClose(finalSig)
+	closeTx, err := bobChannel.CompleteCooperativeClose(finalSig, nil)
 	if err != nil {
 		t.Fatalf("unable to complete alice cooperative close: %v", err)
 	}
@@ -586,12 +603,12 @@ func TestCooperativeChannelClosure(t *testing.T) {
 
 	// Next we test the channel recipient requesting a cooperative closure.
 	// First we test the channel initiator requesting a cooperative close.
-	sig, txid, err = bobChannel.InitCooperativeClose()
+	sig, txid, err = bobChannel.InitCooperativeClose(nil)
 	if err != nil {
 		t.Fatalf("unable to initiate bob cooperative close: %v", err)
 	}
 	finalSig = append(sig, byte(txscript.SigHashAll))
-	closeTx, err = aliceChannel.CompleteCooperativeClose(finalSig)
+	closeTx, err = aliceChannel.CompleteCooperativeClose(finalSig, nil)
 	if err != nil {
 		t.Fatalf("unable to complete bob cooperative close: %v", err)
 	}
@@ -600,6 +617,45 @@ func TestCooperativeChannelClosure(t *testing.T) {
 		t.Fatalf("bob's closure transactions don't match: %x vs %x",
 			aliceCloseSha[:], txid[:])
 	}
+
+	aliceChannel.status = channelOpen
+	bobChannel.status = channelOpen
+
+	// A delivery script of a non-standard type is rejected as an override
+	// by either party.
+	opReturn := []byte{txscript.OP_RETURN}
+	_, _, err = aliceChannel.InitCooperativeClose(opReturn)
+	if err != ErrInvalidDeliveryScript {
+		t.Fatalf("expected ErrInvalidDeliveryScript, got %v", err)
+	}
+	_, err = bobChannel.CompleteCooperativeClose(finalSig, opReturn)
+	if err != ErrInvalidDeliveryScript {
+		t.Fatalf("expected ErrInvalidDeliveryScript, got %v", err)
+	}
+
+	// Finally, Alice overrides her delivery script, which Bob is made
+	// aware of, so both arrive at the same closure transaction paying
+	// Alice's balance to the new script.
+	overrideScript := append([]byte{txscript.OP_0, txscript.OP_DATA_20},
+		bytes.Repeat([]byte{1}, 20)...)
+	sig, txid, err = aliceChannel.InitCooperativeClose(overrideScript)
+	if err != nil {
+		t.Fatalf("unable to initiate alice cooperative close: %v", err)
+	}
+	finalSig = append(sig, byte(txscript.SigHashAll))
+	closeTx, err = bobChannel.CompleteCooperativeClose(finalSig,
+		overrideScript)
+	if err != nil {
+		t.Fatalf("unable to complete alice cooperative close: %v", err)
+	}
+	bobCloseSha = closeTx.TxSha()
+	if !bobCloseSha.IsEqual(txid) {
+		t.Fatalf("alice's transactions doesn't match: %x vs %x",
+			bobCloseSha[:], txid[:])
+	}
+	if found, _ := FindScriptOutputIndex(closeTx, overrideScript); !found {
+		t.Fatalf("alice's balance not sent to override script")
+	}
 }
 
 func TestStateUpdatePersistence(t *testing.T) {
@@ -625,12 +681,16 @@ func TestStateUpdatePersistence(t *testing.T) {
 	const numHtlcs = 4
 
 	// Alice adds 3 HTLC's to the update log, while Bob adds a single HTLC.
-	var alicePreimage [32]byte
-	copy(alicePreimage[:], bytes.Repeat([]byte{0xaa}, 32))
+	// Each of Alice's HTLC's uses a distinct preimage, as duplicate
+	// payment hashes are rejected.
+	var alicePreimages [3][32]byte
+	for i := range alicePreimages {
+		copy(alicePreimages[i][:], bytes.Repeat([]byte{0xaa - byte(i)}, 32))
+	}
 	var bobPreimage [32]byte
 	copy(bobPreimage[:], bytes.Repeat([]byte{0xbb}, 32))
 	for i := 0; i < 3; i++ {
-		rHash := fastsha256.Sum256(alicePreimage[:])
+		rHash := fastsha256.Sum256(alicePreimages[i][:])
 		h := &lnwire.HTLCAddRequest{
 			RedemptionHashes: [][32]byte{rHash},
 			Amount:           lnwire.CreditsAmount(1000),
@@ -750,11 +810,12 @@ func TestStateUpdatePersistence(t *testing.T) {
 	// Now settle all the HTLC's, then force a state update. The state
 	// update should suceed as both sides have identical.
 	for i := 0; i < 3; i++ {
-		settleIndex, err := bobChannelNew.SettleHTLC(alicePreimage)
+		settleIndex, err := bobChannelNew.SettleHTLC(alicePreimages[i])
 		if err != nil {
 			t.Fatalf("unable to settle htlc: %v", err)
 		}
-		err = aliceChannelNew.ReceiveHTLCSettle(alicePreimage, settleIndex)
+		err = aliceChannelNew.ReceiveHTLCSettle(alicePreimages[i],
+			settleIndex)
 		if err != nil {
 			t.Fatalf("unable to settle htlc: %v", err)
 		}
@@ -785,3 +846,967 @@ func TestStateUpdatePersistence(t *testing.T) {
 			bobBalance)
 	}
 }
+
+// TestAvailableBalance tests that the balance available for new outgoing
+// HTLCs accounts for the channel reserve, HTLCs which have yet to be
+// committed, and HTLCs which are locked into the commitment chains.
+func TestAvailableBalance(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	reserve := aliceChannel.channelState.AssetCapacity / chanReserveDivisor
+	initialBalance := aliceChannel.channelState.OurBalance - reserve
+	if balance := aliceChannel.AvailableBalance(); balance != initialBalance {
+		t.Fatalf("expected available balance of %v, got %v",
+			initialBalance, balance)
+	}
+
+	paymentHash := fastsha256.Sum256(bytes.Repeat([]byte{2}, 32))
+	htlcAmt := btcutil.Amount(1e8)
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{paymentHash},
+		Amount:           lnwire.CreditsAmount(htlcAmt),
+		Expiry:           uint32(5),
+	}
+
+	// Once an HTLC has been added to the log, it should be debited from
+	// the available balance, even before it's covered by a commitment.
+	aliceChannel.AddHTLC(htlc)
+	bobChannel.ReceiveHTLC(htlc)
+	expectedBalance := initialBalance - htlcAmt
+	if balance := aliceChannel.AvailableBalance(); balance != expectedBalance {
+		t.Fatalf("expected available balance of %v, got %v",
+			expectedBalance, balance)
+	}
+
+	// After the HTLC has been locked into both commitment chains, it
+	// shouldn't be debited a second time.
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+	if balance := aliceChannel.AvailableBalance(); balance != expectedBalance {
+		t.Fatalf("expected available balance of %v, got %v",
+			expectedBalance, balance)
+	}
+
+	// Bob's available balance shouldn't be affected by the HTLC he
+	// received.
+	bobBalance := bobChannel.channelState.OurBalance - reserve
+	if balance := bobChannel.AvailableBalance(); balance != bobBalance {
+		t.Fatalf("expected available balance of %v, got %v",
+			bobBalance, balance)
+	}
+}
+
+// TestDuplicatePaymentHash tests that an HTLC reusing the payment hash of an
+// active HTLC is rejected, while the payment hash may be reused once the
+// original HTLC has been settled.
+func TestDuplicatePaymentHash(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	var preimage [32]byte
+	copy(preimage[:], bytes.Repeat([]byte{3}, 32))
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage[:])},
+		Amount:           lnwire.CreditsAmount(1000),
+		Expiry:           uint32(5),
+	}
+
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+
+	// A second HTLC with the same payment hash should be rejected by both
+	// sides, leaving the update logs untouched.
+	if _, err := aliceChannel.AddHTLC(htlc); err != ErrDuplicatePaymentHash {
+		t.Fatalf("expected ErrDuplicatePaymentHash, got %v", err)
+	}
+	dupHTLC := *htlc
+	dupHTLC.ID = 1
+	if _, err := bobChannel.ReceiveHTLC(&dupHTLC); err != ErrDuplicatePaymentHash {
+		t.Fatalf("expected ErrDuplicatePaymentHash, got %v", err)
+	}
+	if aliceChannel.ourUpdateLog.Len() != 1 {
+		t.Fatalf("expected 1 log entry, got %v",
+			aliceChannel.ourUpdateLog.Len())
+	}
+	if bobChannel.theirUpdateLog.Len() != 1 {
+		t.Fatalf("expected 1 log entry, got %v",
+			bobChannel.theirUpdateLog.Len())
+	}
+
+	// Once the original HTLC has been settled, the payment hash is no
+	// longer active.
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to lock in htlc: %v", err)
+	}
+	settleIndex, err := bobChannel.SettleHTLC(preimage)
+	if err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveHTLCSettle(preimage, settleIndex); err != nil {
+		t.Fatalf("unable to receive settle: %v", err)
+	}
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc after settle: %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc after settle: %v", err)
+	}
+}
+
+// TestLogIndexExhausted tests that updates are rejected once an update log
+// has exhausted the indexes which can be referenced on the wire, rather than
+// wrapping around to reuse the indexes of existing entries.
+func TestLogIndexExhausted(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{fastsha256.Sum256([]byte("exhausted"))},
+		Amount:           lnwire.CreditsAmount(1000),
+		Expiry:           uint32(5),
+	}
+
+	// An index beyond the range of a uint32 should be handed out without
+	// issue.
+	aliceChannel.ourLogCounter = math.MaxUint32 + 1
+	bobChannel.theirLogCounter = math.MaxUint32 + 1
+	aliceIndex, err := aliceChannel.AddHTLC(htlc)
+	if err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	bobIndex, err := bobChannel.ReceiveHTLC(htlc)
+	if err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+	if aliceIndex != math.MaxUint32+1 || bobIndex != aliceIndex {
+		t.Fatalf("unexpected log indexes: alice=%v, bob=%v", aliceIndex,
+			bobIndex)
+	}
+
+	// Once the final index has been handed out, further updates should
+	// be rejected.
+	aliceChannel.ourLogCounter = maxLogIndex
+	bobChannel.theirLogCounter = maxLogIndex
+	htlc.RedemptionHashes[0] = fastsha256.Sum256([]byte("exhausted2"))
+	if _, err := aliceChannel.AddHTLC(htlc); err != ErrLogIndexExhausted {
+		t.Fatalf("expected ErrLogIndexExhausted, got %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != ErrLogIndexExhausted {
+		t.Fatalf("expected ErrLogIndexExhausted, got %v", err)
+	}
+}
+
+// TestReceiveHTLCReplay tests that HTLCs, and settles retransmitted by the
+// remote party after a reconnection are ignored, rather than being applied to
+// the update log a second time.
+func TestReceiveHTLCReplay(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	var preimage [32]byte
+	copy(preimage[:], bytes.Repeat([]byte{4}, 32))
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage[:])},
+		Amount:           lnwire.CreditsAmount(1000),
+		Expiry:           uint32(5),
+	}
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	index, err := bobChannel.ReceiveHTLC(htlc)
+	if err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+
+	// Retransmitting the HTLC should leave Bob's log untouched.
+	replayIndex, err := bobChannel.ReceiveHTLC(htlc)
+	if err != ErrReplayedHTLC {
+		t.Fatalf("expected ErrReplayedHTLC, got %v", err)
+	}
+	if replayIndex != index {
+		t.Fatalf("expected replayed index %v, got %v", index,
+			replayIndex)
+	}
+	if bobChannel.theirUpdateLog.Len() != 1 {
+		t.Fatalf("expected 1 log entry, got %v",
+			bobChannel.theirUpdateLog.Len())
+	}
+
+	// A retransmission which conflicts with the original HTLC, or an HTLC
+	// which skips ahead should be rejected.
+	conflicting := *htlc
+	conflicting.Amount++
+	if _, err := bobChannel.ReceiveHTLC(&conflicting); err == nil ||
+		err == ErrReplayedHTLC {
+		t.Fatalf("conflicting retransmission should be rejected")
+	}
+	skipping := *htlc
+	skipping.ID = 5
+	if _, err := bobChannel.ReceiveHTLC(&skipping); err == nil {
+		t.Fatalf("htlc skipping ahead should be rejected")
+	}
+
+	// Similarly, a retransmitted settle should be ignored.
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to lock in htlc: %v", err)
+	}
+	settleIndex, err := bobChannel.SettleHTLC(preimage)
+	if err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveHTLCSettle(preimage, settleIndex); err != nil {
+		t.Fatalf("unable to receive settle: %v", err)
+	}
+	err = aliceChannel.ReceiveHTLCSettle(preimage, settleIndex)
+	if err != ErrReplayedHTLC {
+		t.Fatalf("expected ErrReplayedHTLC, got %v", err)
+	}
+
+	// Both sides should have persisted the preimage of the settled HTLC.
+	for _, channel := range []*LightningChannel{aliceChannel, bobChannel} {
+		stored, _, err := channel.channelState.Db.LookupPreimage(
+			fastsha256.Sum256(preimage[:]))
+		if err != nil {
+			t.Fatalf("unable to lookup preimage: %v", err)
+		}
+		if stored != preimage {
+			t.Fatalf("expected preimage %x, got %x", preimage, stored)
+		}
+	}
+	if aliceChannel.theirUpdateLog.Len() != 1 {
+		t.Fatalf("expected 1 log entry, got %v",
+			aliceChannel.theirUpdateLog.Len())
+	}
+}
+
+// TestCommitmentSizeGuard tests that HTLCs are rejected once the projected
+// commitment transaction holds the maximum number of HTLCs, and that
+// commitment transactions whose instruction payload exceeds the OP_RETURN
+// limit are deemed non-standard.
+func TestCommitmentSizeGuard(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	for i := 0; i <= maxCommitHTLCs; i++ {
+		htlc := &lnwire.HTLCAddRequest{
+			ID:               uint64(i),
+			RedemptionHashes: [][32]byte{fastsha256.Sum256([]byte{byte(i)})},
+			Amount:           lnwire.CreditsAmount(1000),
+			Expiry:           uint32(5),
+		}
+
+		_, aliceErr := aliceChannel.AddHTLC(htlc)
+		_, bobErr := bobChannel.ReceiveHTLC(htlc)
+		if i < maxCommitHTLCs && (aliceErr != nil || bobErr != nil) {
+			t.Fatalf("unable to add htlc #%v: %v, %v", i, aliceErr,
+				bobErr)
+		}
+		if i == maxCommitHTLCs && (aliceErr != ErrMaxHTLCsExceeded ||
+			bobErr != ErrMaxHTLCsExceeded) {
+			t.Fatalf("expected ErrMaxHTLCsExceeded, got %v, %v",
+				aliceErr, bobErr)
+		}
+	}
+
+	commitTx := wire.NewMsgTx()
+	commitTx.AddTxIn(aliceChannel.fundingTxIn)
+	payload := make([]byte, maxInstructionPayload)
+	opReturn := append([]byte{txscript.OP_RETURN, byte(len(payload))},
+		payload...)
+	commitTx.AddTxOut(wire.NewTxOut(0, opReturn))
+	if err := checkCommitStandard(commitTx); err != nil {
+		t.Fatalf("commitment should be standard: %v", err)
+	}
+
+	commitTx.TxOut[0].PkScript = append(opReturn, 0)
+	commitTx.TxOut[0].PkScript[1]++
+	if err := checkCommitStandard(commitTx); err != ErrCommitmentNonStandard {
+		t.Fatalf("expected ErrCommitmentNonStandard, got %v", err)
+	}
+}
+
+// TestHTLCTimeout tests that an incoming HTLC failed back by the receiver is
+// removed from both commitment chains, returning its value to the sender.
+func TestHTLCTimeout(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	aliceBalance := aliceChannel.channelState.OurBalance
+	bobBalance := bobChannel.channelState.OurBalance
+
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{fastsha256.Sum256(bytes.Repeat(
+			[]byte{5}, 32))},
+		Amount: lnwire.CreditsAmount(1000),
+		Expiry: uint32(5),
+	}
+	addIndex, err := aliceChannel.AddHTLC(htlc)
+	if err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	receiveIndex, err := bobChannel.ReceiveHTLC(htlc)
+	if err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to lock in htlc: %v", err)
+	}
+
+	// Bob fails the HTLC back to Alice. The HTLC can only be removed once.
+	if err := bobChannel.TimeoutHTLC(receiveIndex); err != nil {
+		t.Fatalf("unable to timeout htlc: %v", err)
+	}
+	if err := bobChannel.TimeoutHTLC(receiveIndex); err == nil {
+		t.Fatalf("htlc should only be timed out once")
+	}
+	if err := aliceChannel.ReceiveHTLCTimeout(addIndex); err != nil {
+		t.Fatalf("unable to receive timeout: %v", err)
+	}
+	err = aliceChannel.ReceiveHTLCTimeout(addIndex)
+	if err != ErrReplayedHTLC {
+		t.Fatalf("expected ErrReplayedHTLC, got %v", err)
+	}
+
+	if err := forceStateTransition(bobChannel, aliceChannel); err != nil {
+		t.Fatalf("unable to remove htlc: %v", err)
+	}
+
+	// With the HTLC removed, both balances should be restored to their
+	// values before the HTLC was added.
+	if aliceChannel.channelState.OurBalance != aliceBalance {
+		t.Fatalf("alice's balance is %v, expected %v",
+			aliceChannel.channelState.OurBalance, aliceBalance)
+	}
+	if bobChannel.channelState.OurBalance != bobBalance {
+		t.Fatalf("bob's balance is %v, expected %v",
+			bobChannel.channelState.OurBalance, bobBalance)
+	}
+	if len(aliceChannel.channelState.Htlcs) != 0 {
+		t.Fatalf("alice's commitment still has %v htlcs",
+			len(aliceChannel.channelState.Htlcs))
+	}
+}
+
+// TestRevocationWindowBounds tests that neither side's revocation window can
+// be extended beyond its bounds.
+func TestRevocationWindowBounds(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// Alice may extend her window by a single revocation more, after
+	// which it spans InitialRevocationWindow commitments.
+	rev, err := aliceChannel.ExtendRevocationWindow()
+	if err != nil {
+		t.Fatalf("unable to extend revocation window: %v", err)
+	}
+	if _, err := bobChannel.ReceiveRevocation(rev); err != nil {
+		t.Fatalf("unable to receive revocation: %v", err)
+	}
+	if _, err := aliceChannel.ExtendRevocationWindow(); err != ErrRevocationWindowEdge {
+		t.Fatalf("expected ErrRevocationWindowEdge, got %v", err)
+	}
+
+	// Each revocation Alice sends extends her window by one, so it should
+	// remain at its maximum size after a state transition.
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state transition: %v", err)
+	}
+	if _, err := aliceChannel.ExtendRevocationWindow(); err != ErrRevocationWindowEdge {
+		t.Fatalf("expected ErrRevocationWindowEdge, got %v", err)
+	}
+
+	// A remote party which extends its window beyond our limit should be
+	// rejected.
+	bobChannel.MaxRevocationWindow = len(bobChannel.revocationWindow) +
+		len(bobChannel.usedRevocations)
+	if _, err := bobChannel.ReceiveRevocation(rev); err != ErrRevocationWindowFull {
+		t.Fatalf("expected ErrRevocationWindowFull, got %v", err)
+	}
+}
+
+// TestCommitTemplateReuse tests that commitment templates returned to the
+// pool are handed out again empty, and that releasing a template doesn't
+// affect the colorified commitment created from it.
+func TestCommitTemplateReuse(t *testing.T) {
+	_, pub := btcec.PrivKeyFromBytes(btcec.S256(), testWalletPrivKey)
+	fundingTxIn := wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil)
+
+	templateTx := fetchCommitTemplate(4)
+	if cap(templateTx.TxOut) < 4 {
+		t.Fatalf("template has room for %v outputs, expected 4",
+			cap(templateTx.TxOut))
+	}
+	err := createCommitTx(templateTx, fundingTxIn, pub, pub, pub, 5,
+		btcutil.Amount(1e8), btcutil.Amount(2e8))
+	if err != nil {
+		t.Fatalf("unable to create commitment: %v", err)
+	}
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = encodeTestInstructions
+
+	commitTx, err := lndcc.ColorifyTx(templateTx, false)
+	if err != nil {
+		t.Fatalf("unable to colorify commitment: %v", err)
+	}
+	expectedTx := commitTx.Copy()
+	releaseCommitTemplate(templateTx)
+
+	if !reflect.DeepEqual(commitTx, expectedTx) {
+		t.Fatalf("commitment modified by the release of its template: "+
+			"%v", spew.Sdump(commitTx))
+	}
+
+	reusedTx := fetchCommitTemplate(2)
+	if len(reusedTx.TxIn) != 0 || len(reusedTx.TxOut) != 0 ||
+		reusedTx.Version != wire.TxVersion || reusedTx.LockTime != 0 {
+		t.Fatalf("template not reset: %v", spew.Sdump(reusedTx))
+	}
+	releaseCommitTemplate(reusedTx)
+}
+
+// TestReceiveAndSignCommitment tests that constructing a new local commitment
+// and a new remote commitment concurrently yields the same result as doing so
+// serially, and that a failure to construct either commitment leaves both
+// commitment chains untouched.
+func TestReceiveAndSignCommitment(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+
+	// Each commitment is encoded once. The first encoding belongs to
+	// Alice's commitment, while the latter two belong to the two
+	// commitments Bob constructs at once, so fail whichever of Bob's
+	// commitments is encoded last.
+	var numEncodings int32
+	lndcc.Encoder = func(insts []lndcc.Instruction) ([]byte, error) {
+		if atomic.AddInt32(&numEncodings, 1) == 3 {
+			return nil, errInjectedFault
+		}
+		return encodeTestInstructions(insts)
+	}
+
+	for i := 0; i < 2; i++ {
+		preimage := bytes.Repeat([]byte{byte(i)}, 32)
+		htlc := &lnwire.HTLCAddRequest{
+			ID:               uint64(i),
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage)},
+			Amount:           lnwire.CreditsAmount(1e6),
+			Expiry:           uint32(5),
+		}
+		if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+			t.Fatalf("unable to add htlc: %v", err)
+		}
+		if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+			t.Fatalf("unable to receive htlc: %v", err)
+		}
+	}
+
+	aliceSig, bobIndex, err := aliceChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("alice unable to sign commitment: %v", err)
+	}
+
+	localHeight := bobChannel.localCommitChain.tip().height
+	remoteHeight := bobChannel.remoteCommitChain.tip().height
+	windowSize := len(bobChannel.revocationWindow)
+
+	_, _, err = bobChannel.ReceiveAndSignCommitment(aliceSig, bobIndex)
+	if err != errInjectedFault {
+		t.Fatalf("expected injected fault, got %v", err)
+	}
+	if bobChannel.localCommitChain.tip().height != localHeight ||
+		bobChannel.remoteCommitChain.tip().height != remoteHeight ||
+		len(bobChannel.revocationWindow) != windowSize {
+		t.Fatalf("commitment chains extended despite failure")
+	}
+
+	// Once retried, Bob should accept Alice's commitment, and sign one
+	// which Alice accepts in turn.
+	bobSig, aliceIndex, err := bobChannel.ReceiveAndSignCommitment(aliceSig,
+		bobIndex)
+	if err != nil {
+		t.Fatalf("bob unable to receive and sign commitment: %v", err)
+	}
+	bobRevocation, err := bobChannel.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("bob unable to revoke commitment: %v", err)
+	}
+	if err := aliceChannel.ReceiveNewCommitment(bobSig, aliceIndex); err != nil {
+		t.Fatalf("alice unable to receive commitment: %v", err)
+	}
+	aliceRevocation, err := aliceChannel.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("alice unable to revoke commitment: %v", err)
+	}
+	if _, err := aliceChannel.ReceiveRevocation(bobRevocation); err != nil {
+		t.Fatalf("alice unable to receive revocation: %v", err)
+	}
+	if _, err := bobChannel.ReceiveRevocation(aliceRevocation); err != nil {
+		t.Fatalf("bob unable to receive revocation: %v", err)
+	}
+
+	if err := assertChannelsInSync(aliceChannel, bobChannel); err != nil {
+		t.Fatal(err)
+	}
+	if len(bobChannel.remoteCommitChain.tip().outgoingHTLCs) != 0 ||
+		len(bobChannel.remoteCommitChain.tip().incomingHTLCs) != 2 {
+		t.Fatalf("expected 2 htlcs within alice's commitment")
+	}
+}
+
+// TestBtcCapacity tests that the sighash of each transaction spending the
+// funding output commits to the funding output's value in satoshis, rather
+// than the channel's asset capacity, falling back to the latter for channels
+// created before the two were tracked apart.
+func TestBtcCapacity(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// Re-create both channels with a funding output carrying far less
+	// than the channel's capacity, as is the case once colorified.
+	const fundingValue = btcutil.Amount(8190)
+	reopen := func(lc *LightningChannel,
+		btcCapacity btcutil.Amount) *LightningChannel {
+
+		lc.channelState.BtcCapacity = btcCapacity
+		newChannel, err := NewLightningChannel(lc.signer, nil,
+			&mockNotfier{}, lc.channelState)
+		if err != nil {
+			t.Fatalf("unable to create channel: %v", err)
+		}
+		return newChannel
+	}
+	aliceChannel = reopen(aliceChannel, fundingValue)
+	bobChannel = reopen(bobChannel, fundingValue)
+	if err := initRevocationWindows(aliceChannel, bobChannel, 3); err != nil {
+		t.Fatalf("unable to init revocation windows: %v", err)
+	}
+
+	if aliceChannel.Capacity != aliceChannel.channelState.AssetCapacity {
+		t.Fatalf("capacity %v doesn't match asset capacity %v",
+			aliceChannel.Capacity, aliceChannel.channelState.AssetCapacity)
+	}
+	if aliceChannel.signDesc.Output.Value != int64(fundingValue) {
+		t.Fatalf("sign descriptor commits to %v, expected %v",
+			aliceChannel.signDesc.Output.Value, fundingValue)
+	}
+
+	// Both sides sign and verify over the same funding value, so a full
+	// state transition should succeed.
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state transition: %v", err)
+	}
+
+	// A channel without a recorded BtcCapacity falls back to its asset
+	// capacity.
+	legacyChannel := reopen(aliceChannel, 0)
+	if legacyChannel.BtcCapacity != legacyChannel.channelState.AssetCapacity {
+		t.Fatalf("legacy btc capacity %v doesn't match asset capacity %v",
+			legacyChannel.BtcCapacity,
+			legacyChannel.channelState.AssetCapacity)
+	}
+}
+
+// TestHTLCOutputMetadata tests that the channel delta of a commitment records
+// the index, and witness script of each HTLC's output within the final
+// colorified commitment transaction, including HTLCs sharing a payment hash.
+func TestHTLCOutputMetadata(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = encodeTestInstructions
+
+	rHash := fastsha256.Sum256(bytes.Repeat([]byte{1}, 32))
+	for i := 0; i < 2; i++ {
+		htlc := &lnwire.HTLCAddRequest{
+			ID:               uint64(i),
+			RedemptionHashes: [][32]byte{rHash},
+			Amount:           lnwire.CreditsAmount(1e6),
+			Expiry:           uint32(5),
+		}
+		if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+			t.Fatalf("unable to add htlc: %v", err)
+		}
+		if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+			t.Fatalf("unable to receive htlc: %v", err)
+		}
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	for _, c := range []*commitment{
+		aliceChannel.localCommitChain.tip(),
+		bobChannel.localCommitChain.tip(),
+	} {
+		delta, err := c.toChannelDelta()
+		if err != nil {
+			t.Fatalf("unable to create channel delta: %v", err)
+		}
+		if len(delta.Htlcs) != 2 {
+			t.Fatalf("expected 2 htlcs, got %v", len(delta.Htlcs))
+		}
+
+		indexes := make(map[int32]struct{})
+		for _, htlc := range delta.Htlcs {
+			if htlc.OutputIndex < 0 ||
+				int(htlc.OutputIndex) >= len(c.txn.TxOut) {
+				t.Fatalf("invalid output index %v", htlc.OutputIndex)
+			}
+			indexes[htlc.OutputIndex] = struct{}{}
+
+			pkScript, err := witnessScriptHash(htlc.WitnessScript)
+			if err != nil {
+				t.Fatalf("unable to hash witness script: %v", err)
+			}
+			txOut := c.txn.TxOut[htlc.OutputIndex]
+			if !bytes.Equal(txOut.PkScript, pkScript) {
+				t.Fatalf("output %v doesn't pay to the htlc's "+
+					"witness script", htlc.OutputIndex)
+			}
+			if htlc.ScriptHash != fastsha256.Sum256(htlc.WitnessScript) {
+				t.Fatalf("script hash doesn't match witness script")
+			}
+		}
+		if len(indexes) != 2 {
+			t.Fatalf("htlcs assigned the same output: %v", indexes)
+		}
+	}
+}
+
+// TestCarrierBudget tests that HTLCs are rejected once the satoshis carried by
+// the outputs of the projected commitment transaction would exceed the value
+// of the funding output, and that the satoshi cost of each commitment is
+// tracked.
+func TestCarrierBudget(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// Re-create both channels with a funding output only able to fund a
+	// commitment holding a single HTLC.
+	budget := commitSatCost(1)
+	reopen := func(lc *LightningChannel) *LightningChannel {
+		lc.channelState.BtcCapacity = budget
+		newChannel, err := NewLightningChannel(lc.signer, nil,
+			&mockNotfier{}, lc.channelState)
+		if err != nil {
+			t.Fatalf("unable to create channel: %v", err)
+		}
+		return newChannel
+	}
+	aliceChannel = reopen(aliceChannel)
+	bobChannel = reopen(bobChannel)
+	if err := initRevocationWindows(aliceChannel, bobChannel, 3); err != nil {
+		t.Fatalf("unable to init revocation windows: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		htlc := &lnwire.HTLCAddRequest{
+			ID:               uint64(i),
+			RedemptionHashes: [][32]byte{fastsha256.Sum256([]byte{byte(i)})},
+			Amount:           lnwire.CreditsAmount(1000),
+			Expiry:           uint32(5),
+		}
+
+		_, aliceErr := aliceChannel.AddHTLC(htlc)
+		_, bobErr := bobChannel.ReceiveHTLC(htlc)
+		if i == 0 && (aliceErr != nil || bobErr != nil) {
+			t.Fatalf("unable to add htlc #%v: %v, %v", i, aliceErr,
+				bobErr)
+		}
+		if i == 1 && (aliceErr != ErrCarrierBudgetExceeded ||
+			bobErr != ErrCarrierBudgetExceeded) {
+			t.Fatalf("expected ErrCarrierBudgetExceeded, got %v, %v",
+				aliceErr, bobErr)
+		}
+	}
+
+	// The commitment holding the single HTLC exhausts the budget exactly.
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state transition: %v", err)
+	}
+	for _, c := range []*commitment{aliceChannel.localCommitChain.tip(),
+		bobChannel.localCommitChain.tip()} {
+
+		if c.satCost != budget {
+			t.Fatalf("expected commitment sat cost %v, got %v",
+				budget, c.satCost)
+		}
+	}
+
+	// A commitment whose outputs carry more than the funding output is
+	// rejected outright.
+	commitTx := wire.NewMsgTx()
+	commitTx.AddTxIn(aliceChannel.fundingTxIn)
+	commitTx.AddTxOut(wire.NewTxOut(int64(budget)+1, nil))
+	if _, err := aliceChannel.checkCarrierBudget(commitTx); err !=
+		ErrCarrierBudgetExceeded {
+		t.Fatalf("expected ErrCarrierBudgetExceeded, got %v", err)
+	}
+}
+
+// TestFundingReorg tests that an open channel rejects new HTLCs while its
+// funding transaction is re-org'd out, until it's been re-confirmed, and that
+// the re-org of a channel being closed leaves its status as is.
+func TestFundingReorg(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// dispatch delivers a single confirmation event to the goroutine
+	// watching the funding transaction of the passed channel, returning
+	// once the event has been handled.
+	dispatch := func(channel *LightningChannel, negativeConf bool) {
+		wallet := &LightningWallet{quit: make(chan struct{})}
+		confNtfn := &chainntnfs.ConfirmationEvent{
+			Confirmed:    make(chan int32),
+			NegativeConf: make(chan int32),
+		}
+
+		wallet.wg.Add(1)
+		go wallet.watchFundingReorgs(channel, confNtfn, &zeroHash)
+		if negativeConf {
+			confNtfn.NegativeConf <- 1
+		} else {
+			confNtfn.Confirmed <- 1
+		}
+		close(wallet.quit)
+		wallet.wg.Wait()
+	}
+
+	newHTLC := func(i byte) *lnwire.HTLCAddRequest {
+		return &lnwire.HTLCAddRequest{
+			RedemptionHashes: [][32]byte{
+				fastsha256.Sum256(bytes.Repeat([]byte{i}, 32)),
+			},
+			Amount: lnwire.CreditsAmount(1000),
+			Expiry: uint32(5),
+		}
+	}
+
+	dispatch(aliceChannel, true)
+	dispatch(bobChannel, true)
+	htlc := newHTLC(1)
+	if _, err := aliceChannel.AddHTLC(htlc); err != ErrChanReorged {
+		t.Fatalf("expected ErrChanReorged, got %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != ErrChanReorged {
+		t.Fatalf("expected ErrChanReorged, got %v", err)
+	}
+
+	// Once re-confirmed, HTLCs may be added once again.
+	dispatch(aliceChannel, false)
+	dispatch(bobChannel, false)
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+
+	// A channel which is being closed remains so across a re-org, and
+	// its re-confirmation.
+	if _, _, err := aliceChannel.InitCooperativeClose(nil); err != nil {
+		t.Fatalf("unable to init cooperative close: %v", err)
+	}
+	dispatch(aliceChannel, true)
+	if aliceChannel.status != channelClosing {
+		t.Fatalf("expected channelClosing, got %v", aliceChannel.status)
+	}
+	dispatch(aliceChannel, false)
+	if aliceChannel.status != channelClosing {
+		t.Fatalf("expected channelClosing, got %v", aliceChannel.status)
+	}
+}
+
+// TestReceiveRevocationCommitFailure tests that a revocation whose commit to
+// the database fails leaves the elkrem receiver of the channel untouched, so
+// the same revocation is accepted once retried.
+func TestReceiveRevocationCommitFailure(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = encodeTestInstructions
+
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{
+			fastsha256.Sum256(bytes.Repeat([]byte{1}, 32)),
+		},
+		Amount: lnwire.CreditsAmount(1e6),
+		Expiry: uint32(5),
+	}
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+
+	aliceSig, bobIndex, err := aliceChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("alice unable to sign commitment: %v", err)
+	}
+	if err := bobChannel.ReceiveNewCommitment(aliceSig, bobIndex); err != nil {
+		t.Fatalf("bob unable to receive commitment: %v", err)
+	}
+	bobSig, aliceIndex, err := bobChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("bob unable to sign commitment: %v", err)
+	}
+	bobRevocation, err := bobChannel.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("bob unable to revoke commitment: %v", err)
+	}
+	if err := aliceChannel.ReceiveNewCommitment(bobSig, aliceIndex); err != nil {
+		t.Fatalf("alice unable to receive commitment: %v", err)
+	}
+	aliceRevocation, err := aliceChannel.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("alice unable to revoke commitment: %v", err)
+	}
+
+	// Swap Alice's database for a closed one, so the commit of Bob's
+	// revocation fails.
+	closedPath, err := ioutil.TempDir("", "closeddb")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(closedPath)
+	closedDB, err := channeldb.Open(closedPath, &chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("unable to open db: %v", err)
+	}
+	closedDB.Close()
+
+	aliceDB := aliceChannel.channelState.Db
+	remoteElkrem := aliceChannel.channelState.RemoteElkrem
+	upTo := remoteElkrem.UpTo()
+
+	aliceChannel.channelState.Db = closedDB
+	if _, err := aliceChannel.ReceiveRevocation(bobRevocation); err == nil {
+		t.Fatalf("revocation accepted despite failed commit")
+	}
+	if aliceChannel.channelState.RemoteElkrem != remoteElkrem ||
+		remoteElkrem.UpTo() != upTo {
+
+		t.Fatalf("elkrem receiver modified despite failed commit")
+	}
+
+	// Once the database is back, the same revocation is accepted.
+	aliceChannel.channelState.Db = aliceDB
+	if _, err := aliceChannel.ReceiveRevocation(bobRevocation); err != nil {
+		t.Fatalf("alice unable to receive revocation: %v", err)
+	}
+	if _, err := bobChannel.ReceiveRevocation(aliceRevocation); err != nil {
+		t.Fatalf("bob unable to receive revocation: %v", err)
+	}
+	if aliceChannel.channelState.RemoteElkrem.UpTo() != upTo+1 {
+		t.Fatalf("revocation not added to elkrem receiver")
+	}
+
+	if err := assertChannelsInSync(aliceChannel, bobChannel); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestDeleteStateSummary tests that the summary left behind once a channel's
+// state has been deleted records the asset of the channel itself, along with
+// the height at which the closing transaction confirmed.
+func TestDeleteStateSummary(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(1)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+	defer aliceChannel.Stop()
+	defer bobChannel.Stop()
+
+	// The channel's asset differs from the one currently active within
+	// the wallet.
+	aliceChannel.channelState.AssetID = "channel-asset"
+
+	closingTxid := wire.ShaHash{1}
+	err = aliceChannel.DeleteState(channeldb.CooperativeClose,
+		&closingTxid, 100)
+	if err != nil {
+		t.Fatalf("unable to delete channel state: %v", err)
+	}
+
+	closedChans, err := aliceChannel.channelState.Db.ListClosedChannels()
+	if err != nil {
+		t.Fatalf("unable to fetch closed channels: %v", err)
+	}
+	if len(closedChans) != 1 {
+		t.Fatalf("expected a single closed channel, instead have %v",
+			len(closedChans))
+	}
+	summary := closedChans[0]
+	if summary.AssetID != "channel-asset" {
+		t.Fatalf("summary records asset %v, expected channel-asset",
+			summary.AssetID)
+	}
+	if summary.CloseHeight != 100 {
+		t.Fatalf("summary records close height %v, expected 100",
+			summary.CloseHeight)
+	}
+	if summary.ClosingTXID != closingTxid {
+		t.Fatalf("summary records closing txid %v, expected %v",
+			summary.ClosingTXID, closingTxid)
+	}
+}
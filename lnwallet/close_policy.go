@@ -0,0 +1,142 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// DefaultCloseCarrierAmount is the default value in satoshis given to each
+// colored output of a cooperative close transaction. This matches the value
+// given to the colored outputs of commitment transactions.
+const DefaultCloseCarrierAmount = 546
+
+// ErrInvalidDeliveryScript is returned when the delivery script overriding
+// that negotiated during the funding workflow isn't of a standard type.
+var ErrInvalidDeliveryScript = errors.New("delivery script must be p2pkh, " +
+	"p2sh, p2wkh or p2wsh")
+
+// ValidateDeliveryScript returns ErrInvalidDeliveryScript unless the passed
+// script is one of the standard output scripts to which the settled balance
+// of a cooperatively closed channel may be sent. Other scripts may render the
+// close transaction non-standard, or the asset unspendable.
+func ValidateDeliveryScript(script []byte) error {
+	switch {
+	case txscript.IsPayToWitnessPubKeyHash(script):
+	case txscript.IsPayToWitnessScriptHash(script):
+	case txscript.IsPayToScriptHash(script):
+	case txscript.GetScriptClass(script) == txscript.PubKeyHashTy:
+	default:
+		return ErrInvalidDeliveryScript
+	}
+
+	return nil
+}
+
+// CloseOutputPolicy determines the outputs of a cooperative close
+// transaction. As both parties construct the close transaction independently,
+// they MUST apply the same policy, or the cooperative closure will fail,
+// leaving the channel to be force closed.
+type CloseOutputPolicy struct {
+	// CarrierAmount is the value in satoshis given to each colored output
+	// of the close transaction, carrying a party's asset balance. It
+	// should be comfortably above the relay dust limit, so the outputs
+	// remain relayable once the close transaction pays fees.
+	CarrierAmount btcutil.Amount
+
+	// FoldThreshold is the asset balance below which a party's balance
+	// is folded into the larger balance of the counterparty, rather than
+	// given a colored output of its own. A FoldThreshold of zero disables
+	// folding.
+	FoldThreshold btcutil.Amount
+
+	// FoldCompensationRate is the number of satoshis paid to a party for
+	// each unit of asset folded into the counterparty's balance, within
+	// an uncolored output. Compensation below the CarrierAmount would
+	// itself be dust, and so is forgone.
+	FoldCompensationRate btcutil.Amount
+}
+
+// DefaultCloseOutputPolicy returns the policy applied to the cooperative
+// closure of channels unless otherwise configured, which never folds either
+// party's balance.
+func DefaultCloseOutputPolicy() CloseOutputPolicy {
+	return CloseOutputPolicy{
+		CarrierAmount: DefaultCloseCarrierAmount,
+	}
+}
+
+// Validate returns an error if the policy would produce unrelayable outputs.
+func (p *CloseOutputPolicy) Validate() error {
+	switch {
+	case p.CarrierAmount < DefaultCloseCarrierAmount:
+		return fmt.Errorf("close carrier amount of %v is below the "+
+			"dust limit of %v", p.CarrierAmount,
+			btcutil.Amount(DefaultCloseCarrierAmount))
+	case p.FoldThreshold < 0:
+		return fmt.Errorf("fold threshold cannot be negative")
+	case p.FoldCompensationRate < 0:
+		return fmt.Errorf("fold compensation rate cannot be negative")
+	}
+
+	return nil
+}
+
+// foldBalances applies the policy's folding rule to the settled balances of
+// both parties. A balance below the FoldThreshold is folded into the other
+// balance if the latter is strictly larger. The rule is symmetric, so both
+// parties arrive at the same close transaction from their own perspective.
+// The compensation owed to either party for a folded balance is returned
+// along with the resulting balances.
+func (p *CloseOutputPolicy) foldBalances(ourBalance,
+	theirBalance btcutil.Amount) (btcutil.Amount, btcutil.Amount,
+	btcutil.Amount, btcutil.Amount) {
+
+	var ourComp, theirComp btcutil.Amount
+	switch {
+	case ourBalance > 0 && ourBalance < p.FoldThreshold &&
+		ourBalance < theirBalance:
+
+		ourComp = p.compensation(ourBalance)
+		theirBalance += ourBalance
+		ourBalance = 0
+
+	case theirBalance > 0 && theirBalance < p.FoldThreshold &&
+		theirBalance < ourBalance:
+
+		theirComp = p.compensation(theirBalance)
+		ourBalance += theirBalance
+		theirBalance = 0
+	}
+
+	return ourBalance, theirBalance, ourComp, theirComp
+}
+
+// compensation returns the satoshis owed for folding the passed asset amount
+// into the counterparty's balance, or zero if they'd be dust.
+func (p *CloseOutputPolicy) compensation(folded btcutil.Amount) btcutil.Amount {
+	comp := folded * p.FoldCompensationRate
+	if comp < p.CarrierAmount {
+		return 0
+	}
+
+	return comp
+}
+
+// addCompensationOutput appends the uncolored output paying the compensation
+// owed for a folded balance, if any, to the colorified close transaction. As
+// the output follows the OP_RETURN output, it isn't assigned any asset. At
+// most one party's balance is ever folded, so at most one output is added.
+func addCompensationOutput(closeTx *wire.MsgTx, ourComp, theirComp btcutil.Amount,
+	ourScript, theirScript []byte) {
+
+	switch {
+	case ourComp != 0:
+		closeTx.AddTxOut(wire.NewTxOut(int64(ourComp), ourScript))
+	case theirComp != 0:
+		closeTx.AddTxOut(wire.NewTxOut(int64(theirComp), theirScript))
+	}
+}
@@ -0,0 +1,120 @@
+package lnwallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestCloseOutputPolicyFolding tests that sub-threshold balances are folded
+// into the larger balance of the counterparty, and that both parties arrive
+// at the same result from their own perspective.
+func TestCloseOutputPolicyFolding(t *testing.T) {
+	policy := &CloseOutputPolicy{
+		CarrierAmount:        1000,
+		FoldThreshold:        100,
+		FoldCompensationRate: 20,
+	}
+
+	tests := []struct {
+		ours, theirs       btcutil.Amount
+		newOurs, newTheirs btcutil.Amount
+		ourComp            btcutil.Amount
+	}{
+		// Both balances are above the threshold, so neither is folded.
+		{ours: 500, theirs: 600, newOurs: 500, newTheirs: 600},
+
+		// Our balance is below the threshold, and is folded into theirs,
+		// with compensation above the carrier amount.
+		{ours: 60, theirs: 600, newOurs: 0, newTheirs: 660, ourComp: 1200},
+
+		// A folded balance whose compensation would be dust is forgone.
+		{ours: 10, theirs: 600, newOurs: 0, newTheirs: 610},
+
+		// Equal balances below the threshold are never folded, as
+		// neither party's balance is larger.
+		{ours: 50, theirs: 50, newOurs: 50, newTheirs: 50},
+
+		// An empty balance is left as is.
+		{ours: 0, theirs: 600, newOurs: 0, newTheirs: 600},
+	}
+
+	for i, test := range tests {
+		ours, theirs, ourComp, theirComp := policy.foldBalances(
+			test.ours, test.theirs)
+		if ours != test.newOurs || theirs != test.newTheirs ||
+			ourComp != test.ourComp || theirComp != 0 {
+
+			t.Fatalf("test #%v: expected (%v, %v, %v, 0), got "+
+				"(%v, %v, %v, %v)", i, test.newOurs,
+				test.newTheirs, test.ourComp, ours, theirs,
+				ourComp, theirComp)
+		}
+
+		// From the counterparty's perspective, the result is mirrored.
+		theirs, ours, theirComp, ourComp = policy.foldBalances(
+			test.theirs, test.ours)
+		if ours != test.newOurs || theirs != test.newTheirs ||
+			ourComp != test.ourComp || theirComp != 0 {
+
+			t.Fatalf("test #%v: mirrored result doesn't match", i)
+		}
+	}
+}
+
+// TestCooperativeCloseTxPolicy tests that the cooperative close transaction
+// gives each colored output the policy's carrier amount, pays compensation
+// for a folded balance within an uncolored output following the OP_RETURN
+// output, and never spends more than the funding output carries.
+func TestCooperativeCloseTxPolicy(t *testing.T) {
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = encodeTestInstructions
+
+	fundingTxIn := wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil)
+	ourScript := bytes.Repeat([]byte{1}, 22)
+	theirScript := bytes.Repeat([]byte{2}, 22)
+	policy := &CloseOutputPolicy{
+		CarrierAmount:        1000,
+		FoldThreshold:        100,
+		FoldCompensationRate: 20,
+	}
+
+	closeTx, err := CreateCooperativeCloseTx(fundingTxIn, 8190, 60, 600,
+		ourScript, theirScript, true, policy)
+	if err != nil {
+		t.Fatalf("unable to create close tx: %v", err)
+	}
+
+	// Only their colored output, the OP_RETURN output, and our
+	// compensation should remain.
+	if len(closeTx.TxOut) != 3 {
+		t.Fatalf("expected 3 outputs, got %v", len(closeTx.TxOut))
+	}
+	if !bytes.Equal(closeTx.TxOut[0].PkScript, theirScript) ||
+		closeTx.TxOut[0].Value != 1000 {
+
+		t.Fatalf("unexpected colored output: %v", closeTx.TxOut[0])
+	}
+	if closeTx.TxOut[1].PkScript[0] != txscript.OP_RETURN {
+		t.Fatalf("expected OP_RETURN output to follow colored output")
+	}
+	if !bytes.Equal(closeTx.TxOut[2].PkScript, ourScript) ||
+		closeTx.TxOut[2].Value != 1200 {
+
+		t.Fatalf("unexpected compensation output: %v", closeTx.TxOut[2])
+	}
+
+	// The same balances can't be paid out of a funding output carrying
+	// fewer satoshis than the outputs require.
+	_, err = CreateCooperativeCloseTx(fundingTxIn, 2000, 60, 600,
+		ourScript, theirScript, true, policy)
+	if err == nil {
+		t.Fatalf("close tx exceeding the funding value was created")
+	}
+}
@@ -0,0 +1,295 @@
+package lnwallet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// errInjectedFault is returned by the faultyEncoder in place of a response
+// from the cc-encoding-api.
+var errInjectedFault = errors.New("injected color encoder fault")
+
+// faultyEncoder encodes transfer instructions deterministically, in place of
+// the cc-encoding-api, while failing or stalling at random in order to inject
+// faults into the construction of commitment transactions.
+type faultyEncoder struct {
+	sync.Mutex
+
+	rng       *rand.Rand
+	failRate  float64
+	numFaults int
+}
+
+// encode encodes the passed instructions with encodeTestInstructions, unless
+// a fault is injected.
+func (f *faultyEncoder) encode(insts []lndcc.Instruction) ([]byte, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	switch r := f.rng.Float64(); {
+	case r < f.failRate:
+		f.numFaults++
+		return nil, errInjectedFault
+	case r < 2*f.failRate:
+		time.Sleep(time.Millisecond)
+	}
+
+	return encodeTestInstructions(insts)
+}
+
+// faultyHTLC is an HTLC added within TestColorEncoderFaultInjection.
+type faultyHTLC struct {
+	sender   int
+	index    uint64
+	preimage [32]byte
+	lockedIn bool
+}
+
+// TestColorEncoderFaultInjection tests that failures of the color encoder
+// while constructing new commitments never cause the state of two channel
+// endpoints to diverge. Random sequences of adds, settles, and state
+// transitions initiated by either side are carried out while the encoder
+// fails or stalls at random, with each failed signing or verification of a
+// commitment being retried. After every state transition, the commitment
+// chains of both sides must match.
+func TestColorEncoderFaultInjection(t *testing.T) {
+	const (
+		numSeeds = 10
+		numSteps = 100
+	)
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+
+	var numFaults int
+	for seed := int64(0); seed < numSeeds; seed++ {
+		numFaults += testColorEncoderFaultInjection(t, seed, numSteps)
+	}
+
+	if numFaults == 0 {
+		t.Fatalf("no faults were injected")
+	}
+}
+
+func testColorEncoderFaultInjection(t *testing.T, seed int64, numSteps int) int {
+	encoder := &faultyEncoder{
+		rng:      rand.New(rand.NewSource(seed)),
+		failRate: 0.25,
+	}
+	lndcc.Encoder = encoder.encode
+
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	channels := []*LightningChannel{aliceChannel, bobChannel}
+	rng := rand.New(rand.NewSource(seed))
+	var htlcs []*faultyHTLC
+	var numPreimages uint64
+
+	addHTLC := func(sender int) {
+		h := &faultyHTLC{sender: sender}
+		binary.BigEndian.PutUint64(h.preimage[:8], uint64(seed))
+		binary.BigEndian.PutUint64(h.preimage[8:16], numPreimages)
+		numPreimages++
+
+		from, to := channels[sender], channels[1-sender]
+		req := &lnwire.HTLCAddRequest{
+			ID:               to.theirLogCounter,
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(h.preimage[:])},
+			Amount:           lnwire.CreditsAmount(rng.Int63n(1e6) + 1),
+			Expiry:           10,
+		}
+
+		h.index, err = from.AddHTLC(req)
+		if err == ErrMaxHTLCsExceeded {
+			return
+		} else if err != nil {
+			t.Fatalf("seed %v: unable to add htlc: %v", seed, err)
+		}
+		if _, err := to.ReceiveHTLC(req); err != nil {
+			t.Fatalf("seed %v: unable to receive htlc: %v", seed, err)
+		}
+
+		htlcs = append(htlcs, h)
+	}
+
+	settleHTLC := func() {
+		var candidates []int
+		for i, h := range htlcs {
+			if h.lockedIn {
+				candidates = append(candidates, i)
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+		i := candidates[rng.Intn(len(candidates))]
+		h := htlcs[i]
+
+		from, to := channels[h.sender], channels[1-h.sender]
+		if _, err := to.SettleHTLC(h.preimage); err != nil {
+			t.Fatalf("seed %v: unable to settle htlc: %v", seed, err)
+		}
+		if err := from.ReceiveHTLCSettle(h.preimage, h.index); err != nil {
+			t.Fatalf("seed %v: unable to receive settle: %v", seed, err)
+		}
+
+		htlcs = append(htlcs[:i], htlcs[i+1:]...)
+	}
+
+	transition := func(initiator int) {
+		chanA, chanB := channels[initiator], channels[1-initiator]
+		if err := faultTolerantTransition(chanA, chanB); err != nil {
+			t.Fatalf("seed %v: unable to complete state transition: %v",
+				seed, err)
+		}
+		if err := assertChannelsInSync(aliceChannel, bobChannel); err != nil {
+			t.Fatalf("seed %v: %v", seed, err)
+		}
+
+		for _, h := range htlcs {
+			h.lockedIn = true
+		}
+	}
+
+	for i := 0; i < numSteps; i++ {
+		switch rng.Intn(4) {
+		case 0:
+			addHTLC(0)
+		case 1:
+			addHTLC(1)
+		case 2:
+			settleHTLC()
+		case 3:
+			transition(rng.Intn(2))
+		}
+	}
+
+	return encoder.numFaults
+}
+
+// retryFaults executes the passed function until it returns an error other
+// than an injected fault, up to a bounded number of attempts.
+func retryFaults(f func() error) error {
+	const maxAttempts = 100
+
+	for i := 0; i < maxAttempts; i++ {
+		if err := f(); err != errInjectedFault {
+			return err
+		}
+	}
+
+	return fmt.Errorf("still failing after %v attempts", maxAttempts)
+}
+
+// faultTolerantTransition carries out a full state transition initiated by
+// chanA, like forceStateTransition, retrying each signing or verification of
+// a commitment which fails due to an injected fault.
+func faultTolerantTransition(chanA, chanB *LightningChannel) error {
+	var (
+		sig      []byte
+		logIndex uint64
+	)
+	signNext := func(c *LightningChannel) func() error {
+		return func() error {
+			var err error
+			sig, logIndex, err = c.SignNextCommitment()
+			return err
+		}
+	}
+	receiveNext := func(c *LightningChannel) func() error {
+		return func() error {
+			return c.ReceiveNewCommitment(sig, logIndex)
+		}
+	}
+
+	if err := retryFaults(signNext(chanA)); err != nil {
+		return fmt.Errorf("unable to sign commitment: %v", err)
+	}
+	if err := retryFaults(receiveNext(chanB)); err != nil {
+		return fmt.Errorf("unable to receive commitment: %v", err)
+	}
+	if err := retryFaults(signNext(chanB)); err != nil {
+		return fmt.Errorf("unable to sign commitment: %v", err)
+	}
+	bRevocation, err := chanB.RevokeCurrentCommitment()
+	if err != nil {
+		return err
+	}
+
+	if err := retryFaults(receiveNext(chanA)); err != nil {
+		return fmt.Errorf("unable to receive commitment: %v", err)
+	}
+	aRevocation, err := chanA.RevokeCurrentCommitment()
+	if err != nil {
+		return err
+	}
+
+	if _, err := chanA.ReceiveRevocation(bRevocation); err != nil {
+		return err
+	}
+	if _, err := chanB.ReceiveRevocation(aRevocation); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// assertChannelsInSync returns an error if the tip of either side's local
+// commitment chain doesn't match the tip of the other side's remote
+// commitment chain.
+func assertChannelsInSync(alice, bob *LightningChannel) error {
+	pairs := []struct {
+		name          string
+		local, remote *commitment
+	}{
+		{
+			name:   "alice",
+			local:  alice.localCommitChain.tip(),
+			remote: bob.remoteCommitChain.tip(),
+		},
+		{
+			name:   "bob",
+			local:  bob.localCommitChain.tip(),
+			remote: alice.remoteCommitChain.tip(),
+		},
+	}
+
+	for _, pair := range pairs {
+		local, remote := pair.local, pair.remote
+		switch {
+		case local.height != remote.height:
+			return fmt.Errorf("%v's commitment at height %v, remote "+
+				"view at height %v", pair.name, local.height,
+				remote.height)
+
+		case local.ourBalance != remote.theirBalance ||
+			local.theirBalance != remote.ourBalance:
+			return fmt.Errorf("%v's commitment balances (ours=%v, "+
+				"theirs=%v) don't match remote view (ours=%v, "+
+				"theirs=%v)", pair.name, local.ourBalance,
+				local.theirBalance, remote.theirBalance,
+				remote.ourBalance)
+
+		case local.txn.TxSha() != remote.txn.TxSha():
+			return fmt.Errorf("%v's commitment %v doesn't match "+
+				"remote view %v", pair.name, local.txn.TxSha(),
+				remote.txn.TxSha())
+		}
+	}
+
+	return nil
+}
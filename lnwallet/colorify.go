@@ -0,0 +1,67 @@
+package lnwallet
+
+import (
+	"time"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil/txsort"
+)
+
+var (
+	// colorifyAttempts is the number of times a transaction is colorified
+	// while the encoder fails transiently, before the failure is returned.
+	colorifyAttempts = 3
+
+	// colorifyBackoff is the delay preceding the first retry of a
+	// transiently failed colorification. The delay doubles after each
+	// subsequent failure.
+	colorifyBackoff = 100 * time.Millisecond
+)
+
+// colorifyWithRetry colorifies the passed transaction via lndcc.ColorifyTx,
+// retrying should the encoder fail transiently, as reported by
+// lndcc.IsTransient. Colorifying only reads the template transaction, so each
+// attempt starts afresh, and a failed attempt leaves nothing behind. Any other
+// failure, or the last transient one, is returned to the caller.
+func colorifyWithRetry(tx *wire.MsgTx, isFunding bool) (*wire.MsgTx, error) {
+	backoff := colorifyBackoff
+
+	var err error
+	for i := 1; ; i++ {
+		var coloredTx *wire.MsgTx
+		coloredTx, err = lndcc.ColorifyTx(tx, isFunding)
+		if err == nil {
+			return coloredTx, nil
+		}
+		if !lndcc.IsTransient(err) || i >= colorifyAttempts {
+			break
+		}
+
+		walletLog.Warnf("Unable to colorify tx %v (attempt %v/%v), "+
+			"retrying in %v: %v", tx.TxSha(), i, colorifyAttempts,
+			backoff, err)
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+
+	return nil, err
+}
+
+// colorifyCanonical constructs a colored transaction from the passed template
+// via the canonical pipeline shared by funding, commitment, and close
+// transactions. The template, whose output values are the asset amounts of
+// each output, is first sorted in place according to BIP-69. Only then is
+// each output assigned the instruction transferring its amount, at its sorted
+// index, as the transaction is colorified. This ensures the instructions
+// never point at the positions of the outputs prior to sorting, and that both
+// parties arrive at the same transaction. Any outputs added once colorified,
+// such as anchors or fuel change, must follow the OP_RETURN output, so that
+// they neither shift the outputs the instructions point at, nor are assigned
+// any asset.
+func colorifyCanonical(template *wire.MsgTx, isFunding bool) (*wire.MsgTx, error) {
+	txsort.InPlaceSort(template)
+
+	return colorifyWithRetry(template, isFunding)
+}
@@ -0,0 +1,192 @@
+package lnwallet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestSignCommitmentTransientEncoderFailure tests that a transient failure
+// of the color encoder is retried while signing a new commitment, and that
+// the revocation window is only consumed once a commitment has been signed.
+func TestSignCommitmentTransientEncoderFailure(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error),
+		backoff time.Duration) {
+
+		lndcc.Encoder = encoder
+		colorifyBackoff = backoff
+	}(lndcc.Encoder, colorifyBackoff)
+	colorifyBackoff = 0
+
+	// The encoder fails transiently the given number of times before
+	// recovering.
+	var numFailures int
+	lndcc.Encoder = func(insts []lndcc.Instruction) ([]byte, error) {
+		if numFailures > 0 {
+			numFailures--
+			return nil, lndcc.ErrServiceUnavailable
+		}
+		return encodeTestInstructions(insts)
+	}
+
+	windowSize := len(aliceChannel.revocationWindow)
+	usedSize := len(aliceChannel.usedRevocations)
+
+	// Should the encoder fail on each attempt, signing fails, leaving the
+	// revocation window untouched.
+	numFailures = colorifyAttempts
+	if _, _, err := aliceChannel.SignNextCommitment(); err == nil {
+		t.Fatalf("commitment signed despite encoder failure")
+	}
+	if len(aliceChannel.revocationWindow) != windowSize ||
+		len(aliceChannel.usedRevocations) != usedSize {
+		t.Fatalf("revocation window modified by failed signing: "+
+			"window=%v, used=%v", len(aliceChannel.revocationWindow),
+			len(aliceChannel.usedRevocations))
+	}
+
+	// Should the encoder recover before the attempts are exhausted, the
+	// commitment is signed, consuming a single revocation.
+	numFailures = colorifyAttempts - 1
+	sig, logIndex, err := aliceChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("unable to sign commitment: %v", err)
+	}
+	if numFailures != 0 {
+		t.Fatalf("encoder failures not retried")
+	}
+	if len(aliceChannel.revocationWindow) != windowSize-1 ||
+		len(aliceChannel.usedRevocations) != usedSize+1 {
+		t.Fatalf("expected a single revocation consumed: window=%v, "+
+			"used=%v", len(aliceChannel.revocationWindow),
+			len(aliceChannel.usedRevocations))
+	}
+
+	// The retried commitment must be accepted by the remote party.
+	if err := bobChannel.ReceiveNewCommitment(sig, logIndex); err != nil {
+		t.Fatalf("unable to receive commitment: %v", err)
+	}
+	if aliceChannel.remoteCommitChain.tip().txn.TxSha() !=
+		bobChannel.localCommitChain.tip().txn.TxSha() {
+		t.Fatalf("commitment chains diverged after retry")
+	}
+}
+
+// TestColorifyCanonical tests that the instructions of a colorified
+// transaction point at the outputs of the sorted template, rather than at
+// their positions prior to sorting.
+func TestColorifyCanonical(t *testing.T) {
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+
+	var insts []lndcc.Instruction
+	lndcc.Encoder = func(i []lndcc.Instruction) ([]byte, error) {
+		insts = i
+		return encodeTestInstructions(i)
+	}
+
+	// Each output is given a distinct amount and script, in the reverse
+	// of the canonical order.
+	amounts := map[string]int{"c": 300, "b": 200, "a": 100}
+	for _, isFunding := range []bool{false, true} {
+		template := wire.NewMsgTx()
+		template.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+		for _, script := range []string{"c", "b", "a"} {
+			template.AddTxOut(wire.NewTxOut(int64(amounts[script]),
+				[]byte(script)))
+		}
+
+		coloredTx, err := colorifyCanonical(template, isFunding)
+		if err != nil {
+			t.Fatalf("unable to colorify tx: %v", err)
+		}
+		if len(insts) != len(template.TxOut) {
+			t.Fatalf("expected %v instructions, got %v",
+				len(template.TxOut), len(insts))
+		}
+		for _, inst := range insts {
+			script := string(coloredTx.TxOut[inst.Output].PkScript)
+			if amounts[script] != inst.Amount {
+				t.Fatalf("instruction transfers %v to output %v "+
+					"paying to %q, expected %v", inst.Amount,
+					inst.Output, script, amounts[script])
+			}
+		}
+	}
+}
+
+// TestCommitmentInstructionIndexes tests that the instruction transferring
+// the amount of each HTLC within a new commitment points at the HTLC's output
+// within the final, sorted commitment transaction.
+func TestCommitmentInstructionIndexes(t *testing.T) {
+	aliceChannel, _, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+
+	var insts []lndcc.Instruction
+	lndcc.Encoder = func(i []lndcc.Instruction) ([]byte, error) {
+		insts = i
+		return encodeTestInstructions(i)
+	}
+
+	// The HTLCs are added in the reverse of the canonical order of their
+	// outputs.
+	for i, amt := range []lnwire.CreditsAmount{3e8, 1e8} {
+		htlc := &lnwire.HTLCAddRequest{
+			RedemptionHashes: [][32]byte{{byte(i)}},
+			Amount:           amt,
+			Expiry:           uint32(5),
+		}
+		if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+			t.Fatalf("unable to add htlc: %v", err)
+		}
+	}
+	if _, _, err := aliceChannel.SignNextCommitment(); err != nil {
+		t.Fatalf("unable to sign commitment: %v", err)
+	}
+
+	commit := aliceChannel.remoteCommitChain.tip()
+	if len(commit.htlcOutputs) != 2 {
+		t.Fatalf("expected 2 htlc outputs, got %v",
+			len(commit.htlcOutputs))
+	}
+	for _, output := range commit.htlcOutputs {
+		if output.outputIndex < 0 {
+			t.Fatalf("htlc output %x not located", output.pkScript)
+		}
+
+		var found bool
+		for _, inst := range insts {
+			if inst.Output != uint32(output.outputIndex) {
+				continue
+			}
+			if btcutil.Amount(inst.Amount) != output.htlc.Amount {
+				t.Fatalf("instruction transfers %v to output %v, "+
+					"expected %v", inst.Amount, inst.Output,
+					output.htlc.Amount)
+			}
+			found = true
+		}
+		if !found {
+			t.Fatalf("no instruction for htlc output %v",
+				output.outputIndex)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package lnwallet
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcutil"
+)
+
+// htlcAmounts returns the amounts of the passed HTLCs, keyed by their
+// payment hash.
+func htlcAmounts(htlcs []*PaymentDescriptor) map[PaymentHash]btcutil.Amount {
+	amounts := make(map[PaymentHash]btcutil.Amount, len(htlcs))
+	for _, htlc := range htlcs {
+		amounts[htlc.RHash] += htlc.Amount
+	}
+
+	return amounts
+}
+
+// diffHTLCs appends the differences between the passed sets of HTLC amounts
+// to diffs, labelling each by the passed direction.
+func diffHTLCs(diffs []string, direction string, ours,
+	theirs map[PaymentHash]btcutil.Amount) []string {
+
+	// The HTLCs are diffed in order of their payment hash, so the
+	// differences are logged deterministically.
+	keys := make([]string, 0, len(ours)+len(theirs))
+	for hash := range ours {
+		keys = append(keys, string(hash[:]))
+	}
+	for hash := range theirs {
+		if _, ok := ours[hash]; !ok {
+			keys = append(keys, string(hash[:]))
+		}
+	}
+	sort.Strings(keys)
+
+	hashes := make([]PaymentHash, len(keys))
+	for i, key := range keys {
+		copy(hashes[i][:], key)
+	}
+
+	for _, hash := range hashes {
+		ourAmt, ourOk := ours[hash]
+		theirAmt, theirOk := theirs[hash]
+		switch {
+		case !theirOk:
+			diffs = append(diffs, fmt.Sprintf("%v htlc %x: ours=%v, "+
+				"peer=missing", direction, hash[:], ourAmt))
+		case !ourOk:
+			diffs = append(diffs, fmt.Sprintf("%v htlc %x: "+
+				"ours=missing, peer=%v", direction, hash[:],
+				theirAmt))
+		case ourAmt != theirAmt:
+			diffs = append(diffs, fmt.Sprintf("%v htlc %x: ours=%v, "+
+				"peer=%v", direction, hash[:], ourAmt, theirAmt))
+		}
+	}
+
+	return diffs
+}
+
+// outputAssetAmounts returns the asset amounts transferred to the outputs of
+// the passed commitment by the instructions within its OP_RETURN output,
+// sorted in ascending order. As the local and remote commitments order their
+// outputs differently, only the sorted amounts are comparable. If the
+// instructions can't be decoded, nil is returned.
+func outputAssetAmounts(c *commitment) []int {
+	if c.txn == nil {
+		return nil
+	}
+
+	insts := lndcc.DecodeTransfer(c.txn)
+	if insts == nil {
+		return nil
+	}
+
+	amounts := make([]int, 0, len(insts))
+	for _, inst := range insts {
+		amounts = append(amounts, inst.Amount)
+	}
+	sort.Ints(amounts)
+
+	return amounts
+}
+
+// diffCommitments returns the differences between our local commitment, and
+// the peer's view of the channel as presumed from their latest commitment.
+// Each difference is a human readable line naming the diverging field, along
+// with its value within each commitment. Both commitments are expressed from
+// our point of view, so a channel in sync yields no differences, other than
+// those caused by updates still in flight.
+func diffCommitments(ours, theirs *commitment) []string {
+	var diffs []string
+
+	if ours.ourBalance != theirs.ourBalance {
+		diffs = append(diffs, fmt.Sprintf("our balance: ours=%v, "+
+			"peer=%v", ours.ourBalance, theirs.ourBalance))
+	}
+	if ours.theirBalance != theirs.theirBalance {
+		diffs = append(diffs, fmt.Sprintf("their balance: ours=%v, "+
+			"peer=%v", ours.theirBalance, theirs.theirBalance))
+	}
+
+	diffs = diffHTLCs(diffs, "outgoing", htlcAmounts(ours.outgoingHTLCs),
+		htlcAmounts(theirs.outgoingHTLCs))
+	diffs = diffHTLCs(diffs, "incoming", htlcAmounts(ours.incomingHTLCs),
+		htlcAmounts(theirs.incomingHTLCs))
+
+	ourAmounts := outputAssetAmounts(ours)
+	theirAmounts := outputAssetAmounts(theirs)
+	if ourAmounts == nil || theirAmounts == nil {
+		diffs = append(diffs, "output asset amounts: instructions "+
+			"undecodable")
+		return diffs
+	}
+	if !reflect.DeepEqual(ourAmounts, theirAmounts) {
+		diffs = append(diffs, fmt.Sprintf("output asset amounts: "+
+			"ours=%v, peer=%v", ourAmounts, theirAmounts))
+	}
+
+	return diffs
+}
+
+// logCommitmentDivergence logs the differences between the local commitment
+// we constructed, for which the remote party's signature turned out to be
+// invalid, and the remote party's latest commitment, in order to pinpoint
+// where the views of both parties diverged.
+func (lc *LightningChannel) logCommitmentDivergence(local *commitment) {
+	remote := lc.remoteCommitChain.tip()
+	if remote == nil {
+		return
+	}
+
+	chanPoint := lc.channelState.ChanID
+	diffs := diffCommitments(local, remote)
+
+	walletLog.Errorf("ChannelPoint(%v): invalid signature for local "+
+		"commitment at height %v (our_index=%v, their_index=%v), "+
+		"compared to remote commitment at height %v (our_index=%v, "+
+		"their_index=%v): %v differences", chanPoint, local.height,
+		local.ourMessageIndex, local.theirMessageIndex, remote.height,
+		remote.ourMessageIndex, remote.theirMessageIndex, len(diffs))
+	for _, diff := range diffs {
+		walletLog.Errorf("ChannelPoint(%v): %v", chanPoint, diff)
+	}
+	walletLog.Debugf("ChannelPoint(%v): local commitment instructions: "+
+		"%v", chanPoint, newLogClosure(func() string {
+		return fmt.Sprintf("%+v", lndcc.DecodeTransfer(local.txn))
+	}))
+}
@@ -0,0 +1,66 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestDiffCommitments tests that the differences between two commitments are
+// reported per balance, HTLC, and output asset amount.
+func TestDiffCommitments(t *testing.T) {
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = lndcc.EncodeTransfer
+
+	newCommitTx := func(amounts ...int) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		for _, amt := range amounts {
+			tx.AddTxOut(wire.NewTxOut(int64(amt), nil))
+		}
+		coloredTx, err := lndcc.ColorifyTx(tx, false)
+		if err != nil {
+			t.Fatalf("unable to colorify tx: %v", err)
+		}
+		return coloredTx
+	}
+
+	ours := &commitment{
+		txn:          newCommitTx(600, 400, 100),
+		ourBalance:   600,
+		theirBalance: 400,
+		outgoingHTLCs: []*PaymentDescriptor{
+			{RHash: PaymentHash{0x01}, Amount: 100},
+		},
+	}
+
+	// A commitment matching our own, with its outputs in another order,
+	// yields no differences.
+	theirs := &commitment{
+		txn:           newCommitTx(400, 100, 600),
+		ourBalance:    ours.ourBalance,
+		theirBalance:  ours.theirBalance,
+		outgoingHTLCs: ours.outgoingHTLCs,
+	}
+	if diffs := diffCommitments(ours, theirs); len(diffs) != 0 {
+		t.Fatalf("expected no differences, got %v", diffs)
+	}
+
+	// The peer's commitment is missing our HTLC, and instead includes an
+	// incoming HTLC, along with different balances.
+	theirs = &commitment{
+		txn:          newCommitTx(650, 300, 50),
+		ourBalance:   650,
+		theirBalance: 300,
+		incomingHTLCs: []*PaymentDescriptor{
+			{RHash: PaymentHash{0x02}, Amount: 50},
+		},
+	}
+	diffs := diffCommitments(ours, theirs)
+	if len(diffs) != 5 {
+		t.Fatalf("expected 5 differences, got %v: %v", len(diffs),
+			diffs)
+	}
+}
@@ -0,0 +1,221 @@
+package lnwallet
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// mockSpendNotifier is a mockNotfier which records each spend notification
+// registered, allowing the spend of the target outpoint to be triggered.
+type mockSpendNotifier struct {
+	mockNotfier
+
+	sync.Mutex
+	spends map[wire.OutPoint]chan *chainntnfs.SpendDetail
+}
+
+func newMockSpendNotifier() *mockSpendNotifier {
+	return &mockSpendNotifier{
+		spends: make(map[wire.OutPoint]chan *chainntnfs.SpendDetail),
+	}
+}
+
+func (m *mockSpendNotifier) RegisterSpendNtfn(
+	outpoint *wire.OutPoint) (*chainntnfs.SpendEvent, error) {
+
+	m.Lock()
+	defer m.Unlock()
+
+	spend := make(chan *chainntnfs.SpendDetail, 1)
+	m.spends[*outpoint] = spend
+	return &chainntnfs.SpendEvent{
+		Spend: spend,
+		Cancel: func() {
+			m.Lock()
+			defer m.Unlock()
+
+			if m.spends[*outpoint] == spend {
+				delete(m.spends, *outpoint)
+			}
+		},
+	}, nil
+}
+
+// spend dispatches the spend of the passed outpoint by the passed txid,
+// returning false if no notification was registered for it.
+func (m *mockSpendNotifier) spend(op wire.OutPoint, txid wire.ShaHash) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	spend, ok := m.spends[op]
+	if !ok {
+		return false
+	}
+	spend <- &chainntnfs.SpendDetail{
+		SpentOutPoint: &op,
+		SpenderTxHash: &txid,
+	}
+	return true
+}
+
+// registered returns true if a spend notification has been registered for
+// the passed outpoint.
+func (m *mockSpendNotifier) registered(op wire.OutPoint) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	_, ok := m.spends[op]
+	return ok
+}
+
+// commitWitnessOutputs returns the outpoints of the p2wsh outputs of the
+// passed commitment transaction.
+func commitWitnessOutputs(commitTx *wire.MsgTx) []wire.OutPoint {
+	var outPoints []wire.OutPoint
+	for i, txOut := range commitTx.TxOut {
+		if len(txOut.PkScript) == 34 && txOut.PkScript[0] == 0 {
+			outPoints = append(outPoints, wire.OutPoint{
+				Hash:  commitTx.TxSha(),
+				Index: uint32(i),
+			})
+		}
+	}
+	return outPoints
+}
+
+// TestCommitOutputSpends tests that the spends of the outputs of our current
+// commitment are sent over the CommitOutputSpends channel, both for a channel
+// loaded from disk, and after each state transition, while the notifications
+// registered for revoked commitments are cancelled.
+func TestCommitOutputSpends(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = encodeTestInstructions
+
+	// Re-load both channels from disk, watching Alice's commitment
+	// outputs through a notifier allowing us to trigger their spend.
+	notifier := newMockSpendNotifier()
+	aliceChannel, err = NewLightningChannel(aliceChannel.signer, nil,
+		notifier, aliceChannel.channelState)
+	if err != nil {
+		t.Fatalf("unable to load channel: %v", err)
+	}
+	defer aliceChannel.Stop()
+	bobChannel, err = NewLightningChannel(bobChannel.signer, nil,
+		&mockNotfier{}, bobChannel.channelState)
+	if err != nil {
+		t.Fatalf("unable to load channel: %v", err)
+	}
+	defer bobChannel.Stop()
+	if err := initRevocationWindows(aliceChannel, bobChannel, 3); err != nil {
+		t.Fatalf("unable to init revocation windows: %v", err)
+	}
+
+	// Only our delayed output is watched on the initial commitment. Its
+	// spend is only triggered once the commitment has been revoked below.
+	initialCommit := aliceChannel.channelState.OurCommitTx
+	initialOutputs := commitWitnessOutputs(initialCommit)
+	if len(initialOutputs) != 1 {
+		t.Fatalf("expected 1 watched output, got %v", len(initialOutputs))
+	}
+	if !notifier.registered(initialOutputs[0]) {
+		t.Fatalf("delayed output of loaded channel not watched")
+	}
+
+	// Add an HTLC, so the next commitment carries both our delayed
+	// output, and an HTLC output.
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{
+			fastsha256.Sum256(bytes.Repeat([]byte{1}, 32)),
+		},
+		Amount: lnwire.CreditsAmount(1e6),
+		Expiry: uint32(5),
+	}
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state update: %v", err)
+	}
+
+	// The anchors are p2wsh as well, but aren't watched, leaving our
+	// delayed output, and the HTLC output.
+	tail := aliceChannel.localCommitChain.tail()
+	var numWatched, numHTLCs int
+	for _, op := range commitWitnessOutputs(tail.txn) {
+		if !notifier.spend(op, wire.ShaHash{2}) {
+			continue
+		}
+		numWatched++
+
+		var spend *CommitOutputSpend
+		select {
+		case spend = <-aliceChannel.CommitOutputSpends:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("spend of %v not dispatched", op)
+		}
+		if spend.OutPoint != op || spend.CommitHeight != tail.height {
+			t.Fatalf("unexpected spend of %v at height %v",
+				spend.OutPoint, spend.CommitHeight)
+		}
+		if spend.SpendDetail == nil {
+			t.Fatalf("spend missing its details")
+		}
+		if spend.IsHTLC {
+			numHTLCs++
+		}
+	}
+	if numWatched != 2 || numHTLCs != 1 {
+		t.Fatalf("expected delayed and htlc outputs watched, got %v "+
+			"watched with %v htlc outputs", numWatched, numHTLCs)
+	}
+
+	// The registrations of the now revoked initial commitment have been
+	// dropped by the notifier, so a late spend of its outputs isn't
+	// dispatched.
+	timeout := time.After(5 * time.Second)
+	for notifier.registered(initialOutputs[0]) {
+		select {
+		case <-timeout:
+			t.Fatalf("registration of revoked commitment output " +
+				"not cancelled")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	select {
+	case spend := <-aliceChannel.CommitOutputSpends:
+		t.Fatalf("spend of revoked commitment output %v dispatched",
+			spend.OutPoint)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Once stopped, all goroutines awaiting spends must exit.
+	stopped := make(chan struct{})
+	go func() {
+		aliceChannel.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("channel didn't stop")
+	}
+}
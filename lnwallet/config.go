@@ -1,8 +1,133 @@
 package lnwallet
 
-// Config..
+import (
+	"fmt"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// DefaultMinCsvDelay is the default minimum CSV delay the wallet will
+	// accept for a channel. A shorter delay may not give us enough time
+	// to notice, and punish a breach of the channel.
+	DefaultMinCsvDelay = 4
+
+	// DefaultMaxCsvDelay is the default maximum CSV delay the wallet will
+	// accept for a channel. A longer delay would lock up our assets for
+	// an unreasonable period of time after a unilateral close. This
+	// corresponds to roughly two weeks' worth of blocks.
+	DefaultMaxCsvDelay = 2016
+
+	// DefaultAssetDustLimit is the default smallest asset amount for which
+	// we'll create an output within our commitment transaction.
+	DefaultAssetDustLimit = 1
+
+	// DefaultMaxRemoteAssetDustLimit is the default largest asset dust
+	// limit we'll accept from the remote party.
+	DefaultMaxRemoteAssetDustLimit = 1000
+
+	// DefaultCarrierSatBudget is the default number of satoshis we're
+	// willing to spend on dust carrier outputs and fees within a channel.
+	// This matches the value of the carrier output of a colored funding
+	// transaction.
+	DefaultCarrierSatBudget = 546 * 15
+
+	// DefaultMinCarrierSatBudget is the default smallest carrier budget
+	// we'll accept from the remote party. A smaller budget isn't enough to
+	// fund both dust carrier outputs of a commitment transaction.
+	DefaultMinCarrierSatBudget = 546 * 2
+
+	// DefaultMaxChanCapacity is the suggested largest capacity, in units
+	// of the channel's asset, of any channel we'll open or accept. Much
+	// like the bound placed on bitcoin channels, this limits our exposure
+	// within a single channel until the bound is deliberately lifted. The
+	// DefaultConfig leaves the capacity unbounded.
+	DefaultMaxChanCapacity = 1<<24 - 1
+)
+
+// Config houses the policy parameters the LightningWallet enforces on all
+// channel reservations.
 type Config struct {
-	// default csv time
+	// MinCsvDelay is the smallest CSV delay the wallet will accept for
+	// either side of a channel.
+	MinCsvDelay uint32
+
+	// MaxCsvDelay is the largest CSV delay the wallet will accept for
+	// either side of a channel.
+	MaxCsvDelay uint32
+
+	// AssetDustLimit is the smallest asset amount for which we'll create
+	// an output within our commitment transaction.
+	AssetDustLimit btcutil.Amount
+
+	// MaxRemoteAssetDustLimit is the largest asset dust limit we'll accept
+	// from the remote party.
+	MaxRemoteAssetDustLimit btcutil.Amount
+
+	// MaxHTLCAssetValue is the largest asset amount we'll accept within a
+	// single HTLC. If zero, then HTLCs are only limited by the capacity of
+	// the channel.
+	MaxHTLCAssetValue btcutil.Amount
+
+	// CarrierSatBudget is the number of satoshis we're willing to spend
+	// on dust carrier outputs and fees within a channel.
+	CarrierSatBudget btcutil.Amount
+
+	// MinCarrierSatBudget is the smallest carrier budget we'll accept from
+	// the remote party.
+	MinCarrierSatBudget btcutil.Amount
+
+	// MaxChanCapacity is the largest capacity, in units of the channel's
+	// asset, of any channel we'll open or accept, unless overridden for
+	// the asset within AssetMaxChanCapacity. If zero, then the capacity of
+	// channels is unbounded.
+	MaxChanCapacity btcutil.Amount
+
+	// AssetMaxChanCapacity overrides the MaxChanCapacity of channels
+	// denominated in each asset it holds, keyed by asset ID. This allows
+	// tightly capping our exposure to illiquid assets, while permitting
+	// large channels for others. A zero capacity lifts the bound for the
+	// asset.
+	AssetMaxChanCapacity map[string]btcutil.Amount
+
+	// AllowedAssets, if non-empty, is the set of asset IDs we're willing
+	// to hold within our channels, and route. Channels, and HTLCs of any
+	// other asset are refused.
+	AllowedAssets map[string]struct{}
+
+	// DeniedAssets is the set of asset IDs we refuse to hold within our
+	// channels, or route, even if present within AllowedAssets.
+	DeniedAssets map[string]struct{}
+
+	// LowFuelThreshold is the balance of the wallet's uncolored outputs
+	// below which we warn that we're running low on the fuel needed to
+	// pay for carrier outputs and fees.
+	LowFuelThreshold btcutil.Amount
+
+	// LowFuelHandler, if non-nil, is called with the remaining fuel each
+	// time fuel is spent while the balance is below the LowFuelThreshold.
+	// It's called from the wallet's request handler, so it MUST NOT
+	// block.
+	LowFuelHandler func(status *FuelStatus)
+
+	// ChannelAcceptor, if non-nil, is consulted before accepting any
+	// inbound single funder channel, allowing the channel to be
+	// programmatically rejected.
+	ChannelAcceptor ChannelAcceptor
+
+	// ReadOnly, if true, prevents the wallet from signing, or broadcasting
+	// any transaction, while still serving balance queries, channel
+	// snapshots, and watching the chain. This is useful while
+	// investigating a suspected breach, or when running an auditor.
+	ReadOnly bool
+
+	// ShaChain, if true, proposes, and accepts deriving the revocation
+	// secrets of new channels from a BOLT shachain rather than an elkrem.
+	// The shachain is only used if supported by both parties.
+	ShaChain bool
+
+	// TODO(roasbeef): additional policy parameters
 	// default cltv time
 	// default wait for funding time
 	// default wait for closure time
@@ -13,3 +138,171 @@ type Config struct {
 	// possible secret derivation functions
 	//
 }
+
+// ChannelAcceptRequest details an inbound channel proposed by a remote peer.
+type ChannelAcceptRequest struct {
+	// PeerID is the identity of the peer proposing the channel.
+	PeerID [wire.HashSize]byte
+
+	// Capacity is the proposed capacity of the channel, denominated in
+	// units of the channel's asset.
+	Capacity btcutil.Amount
+
+	// AssetID is the identifier of the asset the channel is to be
+	// denominated in.
+	AssetID string
+
+	// CsvDelay is the CSV delay the peer requires for the pay-to-self
+	// output within their commitment transaction.
+	CsvDelay uint32
+}
+
+// ChannelAcceptor is consulted by the wallet before accepting an inbound
+// channel, allowing operators to implement custom acceptance policies.
+type ChannelAcceptor interface {
+	// AcceptChannel returns a non-nil error if the proposed channel
+	// should be rejected. The error is returned to the caller of the
+	// funding workflow.
+	AcceptChannel(req *ChannelAcceptRequest) error
+}
+
+// ChannelAcceptorFunc is an adapter allowing an ordinary function to be used
+// as a ChannelAcceptor.
+type ChannelAcceptorFunc func(req *ChannelAcceptRequest) error
+
+// AcceptChannel calls f(req).
+//
+// This is a part of the ChannelAcceptor interface.
+func (f ChannelAcceptorFunc) AcceptChannel(req *ChannelAcceptRequest) error {
+	return f(req)
+}
+
+// DefaultConfig returns a Config populated with the default policy
+// parameters.
+func DefaultConfig() *Config {
+	return &Config{
+		MinCsvDelay: DefaultMinCsvDelay,
+		MaxCsvDelay: DefaultMaxCsvDelay,
+
+		AssetDustLimit:          DefaultAssetDustLimit,
+		MaxRemoteAssetDustLimit: DefaultMaxRemoteAssetDustLimit,
+		CarrierSatBudget:        DefaultCarrierSatBudget,
+		MinCarrierSatBudget:     DefaultMinCarrierSatBudget,
+		LowFuelThreshold:        DefaultLowFuelThreshold,
+	}
+}
+
+// maxChanCapacity returns the largest capacity of a channel denominated in
+// the passed asset, or zero if the capacity is unbounded.
+func (c *Config) maxChanCapacity(assetID string) btcutil.Amount {
+	if maxCapacity, ok := c.AssetMaxChanCapacity[assetID]; ok {
+		return maxCapacity
+	}
+
+	return c.MaxChanCapacity
+}
+
+// validateCapacity returns ErrChanTooLarge if the passed capacity of a
+// channel denominated in the target asset exceeds the bound set within the
+// config.
+func (c *Config) validateCapacity(assetID string,
+	capacity btcutil.Amount) error {
+
+	maxCapacity := c.maxChanCapacity(assetID)
+	if maxCapacity != 0 && capacity > maxCapacity {
+		walletLog.Warnf("Rejecting channel of capacity %v for asset "+
+			"%q, exceeds maximum of %v", capacity, assetID,
+			maxCapacity)
+		return ErrChanTooLarge
+	}
+
+	return nil
+}
+
+// AssetAllowed returns true if the passed asset may be held within our
+// channels, and routed. Denied assets are never allowed, while all others are
+// allowed unless a set of allowed assets is configured which omits them.
+func (c *Config) AssetAllowed(assetID string) bool {
+	if _, ok := c.DeniedAssets[assetID]; ok {
+		return false
+	}
+	if len(c.AllowedAssets) == 0 {
+		return true
+	}
+
+	_, ok := c.AllowedAssets[assetID]
+	return ok
+}
+
+// validateAssetAllowed returns ErrAssetNotAllowed if the config refuses
+// channels of the passed asset.
+func (c *Config) validateAssetAllowed(assetID string) error {
+	if !c.AssetAllowed(assetID) {
+		walletLog.Warnf("Rejecting channel for asset %q, not allowed "+
+			"by policy", assetID)
+		return ErrAssetNotAllowed
+	}
+
+	return nil
+}
+
+// validateCsvDelay returns ErrCsvDelayOutOfBounds if the passed CSV delay
+// falls outside the bounds set within the config.
+func (c *Config) validateCsvDelay(csvDelay uint32) error {
+	if csvDelay < c.MinCsvDelay || csvDelay > c.MaxCsvDelay {
+		walletLog.Warnf("Rejecting csv delay of %v, not within "+
+			"[%v, %v]", csvDelay, c.MinCsvDelay, c.MaxCsvDelay)
+		return ErrCsvDelayOutOfBounds
+	}
+
+	return nil
+}
+
+// assetParams returns the asset specific parameters we'll propose for a
+// channel of the passed capacity.
+func (c *Config) assetParams(capacity btcutil.Amount) AssetParams {
+	maxHTLC := c.MaxHTLCAssetValue
+	if maxHTLC == 0 || maxHTLC > capacity {
+		maxHTLC = capacity
+	}
+
+	return AssetParams{
+		AssetID:          globallyActiveAssetId,
+		DustLimit:        c.AssetDustLimit,
+		MaxHTLCValue:     maxHTLC,
+		CarrierSatBudget: c.CarrierSatBudget,
+	}
+}
+
+// validateAssetParams ensures the asset specific parameters proposed by the
+// remote party are compatible with our own, and within the bounds set within
+// the config.
+func (c *Config) validateAssetParams(ours, theirs *AssetParams,
+	capacity btcutil.Amount) error {
+
+	switch {
+	case theirs.AssetID != ours.AssetID:
+		walletLog.Warnf("Rejecting channel for asset %q, only %q is "+
+			"supported", theirs.AssetID, ours.AssetID)
+		return ErrAssetMismatch
+
+	case theirs.DustLimit < 0 || theirs.DustLimit > c.MaxRemoteAssetDustLimit:
+		return fmt.Errorf("asset dust limit of %v not within [0, %v]",
+			theirs.DustLimit, c.MaxRemoteAssetDustLimit)
+
+	case theirs.MaxHTLCValue <= theirs.DustLimit:
+		return fmt.Errorf("max htlc asset value of %v must exceed "+
+			"asset dust limit of %v", theirs.MaxHTLCValue,
+			theirs.DustLimit)
+
+	case theirs.MaxHTLCValue > capacity:
+		return fmt.Errorf("max htlc asset value of %v exceeds channel "+
+			"capacity of %v", theirs.MaxHTLCValue, capacity)
+
+	case theirs.CarrierSatBudget < c.MinCarrierSatBudget:
+		return fmt.Errorf("carrier budget of %v is below minimum of %v",
+			theirs.CarrierSatBudget, c.MinCarrierSatBudget)
+	}
+
+	return nil
+}
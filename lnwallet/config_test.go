@@ -0,0 +1,162 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/roasbeef/btcutil"
+)
+
+// TestValidateAssetParams tests that the asset specific channel parameters
+// proposed by a remote party are properly validated against our own.
+func TestValidateAssetParams(t *testing.T) {
+	cfg := DefaultConfig()
+	capacity := btcutil.Amount(10000)
+	ours := cfg.assetParams(capacity)
+
+	if ours.MaxHTLCValue != capacity {
+		t.Fatalf("max htlc value should default to capacity, "+
+			"instead got %v", ours.MaxHTLCValue)
+	}
+
+	validParams := func() AssetParams {
+		return AssetParams{
+			AssetID:          ours.AssetID,
+			DustLimit:        10,
+			MaxHTLCValue:     5000,
+			CarrierSatBudget: DefaultCarrierSatBudget,
+		}
+	}
+
+	theirs := validParams()
+	if err := cfg.validateAssetParams(&ours, &theirs, capacity); err != nil {
+		t.Fatalf("valid params rejected: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(*AssetParams)
+	}{
+		{
+			name: "different asset",
+			mutate: func(p *AssetParams) {
+				p.AssetID = ours.AssetID + "x"
+			},
+		},
+		{
+			name: "dust limit too large",
+			mutate: func(p *AssetParams) {
+				p.DustLimit = cfg.MaxRemoteAssetDustLimit + 1
+			},
+		},
+		{
+			name: "max htlc below dust",
+			mutate: func(p *AssetParams) {
+				p.MaxHTLCValue = p.DustLimit
+			},
+		},
+		{
+			name: "max htlc above capacity",
+			mutate: func(p *AssetParams) {
+				p.MaxHTLCValue = capacity + 1
+			},
+		},
+		{
+			name: "carrier budget too small",
+			mutate: func(p *AssetParams) {
+				p.CarrierSatBudget = cfg.MinCarrierSatBudget - 1
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		theirs := validParams()
+		testCase.mutate(&theirs)
+
+		err := cfg.validateAssetParams(&ours, &theirs, capacity)
+		if err == nil {
+			t.Fatalf("%v: invalid params accepted", testCase.name)
+		}
+	}
+
+	// Finally, a CSV delay outside of the configured bounds should be
+	// rejected.
+	if err := cfg.validateCsvDelay(DefaultMinCsvDelay - 1); err != ErrCsvDelayOutOfBounds {
+		t.Fatalf("expected ErrCsvDelayOutOfBounds, got %v", err)
+	}
+	if err := cfg.validateCsvDelay(DefaultMaxCsvDelay); err != nil {
+		t.Fatalf("valid csv delay rejected: %v", err)
+	}
+}
+
+// TestValidateCapacity tests that the capacity of a channel is bounded by the
+// maximum of its asset, falling back to the default maximum for assets
+// without one.
+func TestValidateCapacity(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.validateCapacity("illiquid", 1<<40); err != nil {
+		t.Fatalf("default config should leave capacity unbounded: %v",
+			err)
+	}
+
+	cfg.MaxChanCapacity = 1000
+	cfg.AssetMaxChanCapacity = map[string]btcutil.Amount{
+		"illiquid": 10,
+		"ours":     0,
+	}
+
+	testCases := []struct {
+		assetID  string
+		capacity btcutil.Amount
+		valid    bool
+	}{
+		{assetID: "other", capacity: 1000, valid: true},
+		{assetID: "other", capacity: 1001},
+		{assetID: "illiquid", capacity: 10, valid: true},
+		{assetID: "illiquid", capacity: 11},
+		{assetID: "ours", capacity: 1 << 40, valid: true},
+	}
+	for _, testCase := range testCases {
+		err := cfg.validateCapacity(testCase.assetID, testCase.capacity)
+		switch {
+		case testCase.valid && err != nil:
+			t.Fatalf("capacity %v of %v rejected: %v",
+				testCase.capacity, testCase.assetID, err)
+		case !testCase.valid && err != ErrChanTooLarge:
+			t.Fatalf("capacity %v of %v: expected ErrChanTooLarge, "+
+				"got %v", testCase.capacity, testCase.assetID, err)
+		}
+	}
+}
+
+// TestAssetAllowed tests that denied assets are never allowed, and that a
+// non-empty allowlist restricts the assets allowed to its members.
+func TestAssetAllowed(t *testing.T) {
+	cfg := DefaultConfig()
+	if !cfg.AssetAllowed("any") {
+		t.Fatalf("default config should allow all assets")
+	}
+
+	cfg.DeniedAssets = map[string]struct{}{"scam": {}}
+	if cfg.AssetAllowed("scam") || !cfg.AssetAllowed("any") {
+		t.Fatalf("denylist should only reject denied assets")
+	}
+
+	cfg.AllowedAssets = map[string]struct{}{"ours": {}, "scam": {}}
+	testCases := []struct {
+		assetID string
+		allowed bool
+	}{
+		{assetID: "ours", allowed: true},
+		{assetID: "any"},
+		{assetID: "scam"},
+	}
+	for _, testCase := range testCases {
+		err := cfg.validateAssetAllowed(testCase.assetID)
+		switch {
+		case testCase.allowed && err != nil:
+			t.Fatalf("asset %v rejected: %v", testCase.assetID, err)
+		case !testCase.allowed && err != ErrAssetNotAllowed:
+			t.Fatalf("asset %v: expected ErrAssetNotAllowed, got %v",
+				testCase.assetID, err)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package lnwallet
+
+import (
+	"fmt"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcutil"
+)
+
+// ContributionRejectReason describes why the remote party's contribution to
+// a pending channel was rejected.
+type ContributionRejectReason uint8
+
+const (
+	// RejectInvalidKey indicates one of the contribution's keys is
+	// missing, not on the curve, or re-uses one of our own keys.
+	RejectInvalidKey ContributionRejectReason = iota
+
+	// RejectCsvDelay indicates the CSV delay required by the remote party
+	// is outside our configured bounds.
+	RejectCsvDelay
+
+	// RejectChangeOutput indicates one of the contribution's change
+	// outputs is either valueless, or pays to a non-standard script.
+	RejectChangeOutput
+
+	// RejectFundingAmount indicates the amount contributed doesn't match
+	// the negotiated capacity of the channel.
+	RejectFundingAmount
+
+	// RejectDeliveryAddress indicates the contribution's delivery address
+	// is missing, or not of a standard type.
+	RejectDeliveryAddress
+
+	// RejectAssetParams indicates the contribution's asset specific
+	// parameters are incompatible with our own.
+	RejectAssetParams
+
+	// RejectFeatures indicates the version of the colored channel
+	// protocol proposed, or selected, by the remote party is incompatible
+	// with our own.
+	RejectFeatures
+
+	// RejectRemoteInput indicates one of the inputs contributed to the
+	// funding transaction isn't an unspent output, or carries another
+	// asset, or that the inputs carry too little of the asset, or fuel,
+	// to cover the contribution.
+	RejectRemoteInput
+)
+
+// String returns a human readable description of the reject reason.
+func (r ContributionRejectReason) String() string {
+	switch r {
+	case RejectInvalidKey:
+		return "invalid key"
+	case RejectCsvDelay:
+		return "csv delay out of bounds"
+	case RejectChangeOutput:
+		return "invalid change output"
+	case RejectFundingAmount:
+		return "funding amount mismatch"
+	case RejectDeliveryAddress:
+		return "invalid delivery address"
+	case RejectAssetParams:
+		return "incompatible asset params"
+	case RejectFeatures:
+		return "incompatible features"
+	case RejectRemoteInput:
+		return "invalid remote input"
+	default:
+		return fmt.Sprintf("unknown reason %d", uint8(r))
+	}
+}
+
+// ContributionError is returned when the remote party's contribution to a
+// pending channel is rejected. The Reason allows the caller to report the
+// rejection to the remote party, while Err holds the underlying error, such
+// as ErrCsvDelayOutOfBounds or ErrAssetMismatch.
+type ContributionError struct {
+	Reason ContributionRejectReason
+	Err    error
+}
+
+// Error returns the string representation of the rejection.
+//
+// NOTE: Part of the error interface.
+func (e *ContributionError) Error() string {
+	return fmt.Sprintf("contribution rejected (%v): %v", e.Reason, e.Err)
+}
+
+// rejectContribution wraps the passed error within a ContributionError of
+// the target reason.
+func rejectContribution(reason ContributionRejectReason,
+	err error) *ContributionError {
+
+	return &ContributionError{Reason: reason, Err: err}
+}
+
+// validateContribution ensures the contribution of the remote party to the
+// passed reservation is sane, before it's recorded within the reservation:
+// each key must be valid and distinct from our own, the CSV delay and asset
+// parameters within our bounds, the change outputs and delivery address
+// standard, and the amount contributed must make up the remainder of the
+// negotiated capacity beyond our own contribution. Any violation is returned
+// as a ContributionError.
+//
+// NOTE: The reservation's mutex MUST be held.
+func (c *Config) validateContribution(res *ChannelReservation,
+	theirs *ChannelContribution, singleFunder bool) error {
+
+	ours := res.ourContribution
+	capacity := res.partialState.AssetCapacity
+
+	err := validateRemoteKey("multi-sig", theirs.MultiSigKey,
+		res.partialState.OurMultiSigKey)
+	if err != nil {
+		return err
+	}
+	err = validateRemoteKey("commitment", theirs.CommitKey, ours.CommitKey)
+	if err != nil {
+		return err
+	}
+
+	// The initiator of a single funder channel doesn't yet know our
+	// commitment key, so its revocation key is only sent along with its
+	// signature.
+	if !singleFunder {
+		err := validateRemoteKey("revocation", theirs.RevocationKey,
+			ours.RevocationKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := c.validateCsvDelay(theirs.CsvDelay); err != nil {
+		return rejectContribution(RejectCsvDelay, err)
+	}
+
+	for i, changeOutput := range theirs.ChangeOutputs {
+		if changeOutput == nil || changeOutput.Value <= 0 {
+			return rejectContribution(RejectChangeOutput,
+				fmt.Errorf("change output %v has no value", i))
+		}
+		if err := ValidateDeliveryScript(changeOutput.PkScript); err != nil {
+			return rejectContribution(RejectChangeOutput,
+				fmt.Errorf("change output %v: %v", i, err))
+		}
+	}
+	for i, changeOutput := range theirs.FuelChangeOutputs {
+		if changeOutput == nil || changeOutput.Value <= 0 {
+			return rejectContribution(RejectChangeOutput,
+				fmt.Errorf("fuel change output %v has no "+
+					"value", i))
+		}
+		if err := ValidateDeliveryScript(changeOutput.PkScript); err != nil {
+			return rejectContribution(RejectChangeOutput,
+				fmt.Errorf("fuel change output %v: %v", i, err))
+		}
+	}
+
+	if theirs.DeliveryAddress == nil {
+		return rejectContribution(RejectDeliveryAddress,
+			fmt.Errorf("no delivery address"))
+	}
+	deliveryScript, err := txscript.PayToAddrScript(theirs.DeliveryAddress)
+	if err != nil {
+		return rejectContribution(RejectDeliveryAddress, err)
+	}
+	if err := ValidateDeliveryScript(deliveryScript); err != nil {
+		return rejectContribution(RejectDeliveryAddress, err)
+	}
+
+	if err := validateFundingAmount(ours.FundingAmount,
+		theirs.FundingAmount, capacity); err != nil {
+		return rejectContribution(RejectFundingAmount, err)
+	}
+
+	err = c.validateAssetParams(&ours.AssetParams, &theirs.AssetParams,
+		capacity)
+	if err != nil {
+		return rejectContribution(RejectAssetParams, err)
+	}
+
+	return nil
+}
+
+// validateRemoteKey ensures a key sent by the remote party is present, lies
+// on the curve, and isn't a copy of our corresponding key.
+func validateRemoteKey(name string, key, ourKey *btcec.PublicKey) error {
+	var err error
+	switch {
+	case key == nil || key.X == nil || key.Y == nil:
+		err = fmt.Errorf("%v key is missing", name)
+
+	case !btcec.S256().IsOnCurve(key.X, key.Y):
+		err = fmt.Errorf("%v key is not on the curve", name)
+
+	case ourKey != nil && key.IsEqual(ourKey):
+		err = fmt.Errorf("%v key re-uses our own key", name)
+
+	default:
+		return nil
+	}
+
+	return rejectContribution(RejectInvalidKey, err)
+}
+
+// validateFundingAmount ensures the amounts contributed by both parties add
+// up to the negotiated capacity of the channel. As contributions needn't be
+// balanced, either may be zero.
+func validateFundingAmount(ourAmt, theirAmt, capacity btcutil.Amount) error {
+	switch {
+	case theirAmt < 0 || theirAmt > capacity:
+		return fmt.Errorf("funding amount of %v not within [0, %v]",
+			theirAmt, capacity)
+
+	case ourAmt+theirAmt != capacity:
+		return fmt.Errorf("combined funding amount of %v doesn't "+
+			"match capacity of %v", ourAmt+theirAmt, capacity)
+	}
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package lnwallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/chaincfg"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestValidateContribution tests that the remote party's contribution to a
+// pending channel is rejected, with the proper reason, whenever it's
+// malformed or inconsistent with the reservation.
+func TestValidateContribution(t *testing.T) {
+	cfg := DefaultConfig()
+	capacity := btcutil.Amount(10000)
+
+	newKey := func() *btcec.PublicKey {
+		priv, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		return priv.PubKey()
+	}
+
+	ourMultiSigKey := newKey()
+	ourCommitKey := newKey()
+	res := &ChannelReservation{
+		ourContribution: &ChannelContribution{
+			CommitKey:   ourCommitKey,
+			AssetParams: cfg.assetParams(capacity),
+		},
+		partialState: &channeldb.OpenChannel{
+			AssetCapacity:  capacity,
+			OurMultiSigKey: ourMultiSigKey,
+		},
+	}
+
+	theirKey := newKey()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(theirKey.SerializeCompressed()),
+		&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	changeScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create change script: %v", err)
+	}
+
+	validContribution := func() *ChannelContribution {
+		return &ChannelContribution{
+			FundingAmount:   capacity,
+			ChangeOutputs:   []*wire.TxOut{wire.NewTxOut(10, changeScript)},
+			MultiSigKey:     theirKey,
+			CommitKey:       theirKey,
+			RevocationKey:   newKey(),
+			DeliveryAddress: addr,
+			CsvDelay:        cfg.MinCsvDelay,
+			AssetParams: AssetParams{
+				AssetID:          res.ourContribution.AssetParams.AssetID,
+				DustLimit:        10,
+				MaxHTLCValue:     5000,
+				CarrierSatBudget: DefaultCarrierSatBudget,
+			},
+		}
+	}
+
+	for _, singleFunder := range []bool{true, false} {
+		err := cfg.validateContribution(res, validContribution(),
+			singleFunder)
+		if err != nil {
+			t.Fatalf("valid contribution rejected: %v", err)
+		}
+	}
+
+	// The initiator of a single funder channel doesn't send a revocation
+	// key along with its contribution.
+	noRevocation := validContribution()
+	noRevocation.RevocationKey = nil
+	if err := cfg.validateContribution(res, noRevocation, true); err != nil {
+		t.Fatalf("single funder contribution rejected: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		singleFunder bool
+		mutate       func(*ChannelContribution)
+		reason       ContributionRejectReason
+	}{
+		{
+			name: "missing multi-sig key",
+			mutate: func(c *ChannelContribution) {
+				c.MultiSigKey = nil
+			},
+			reason: RejectInvalidKey,
+		},
+		{
+			name: "key not on curve",
+			mutate: func(c *ChannelContribution) {
+				c.CommitKey = &btcec.PublicKey{
+					Curve: btcec.S256(),
+					X:     big.NewInt(1),
+					Y:     big.NewInt(1),
+				}
+			},
+			reason: RejectInvalidKey,
+		},
+		{
+			name: "re-used multi-sig key",
+			mutate: func(c *ChannelContribution) {
+				c.MultiSigKey = ourMultiSigKey
+			},
+			reason: RejectInvalidKey,
+		},
+		{
+			name: "missing revocation key",
+			mutate: func(c *ChannelContribution) {
+				c.RevocationKey = nil
+			},
+			reason: RejectInvalidKey,
+		},
+		{
+			name: "csv delay too large",
+			mutate: func(c *ChannelContribution) {
+				c.CsvDelay = cfg.MaxCsvDelay + 1
+			},
+			reason: RejectCsvDelay,
+		},
+		{
+			name: "valueless change output",
+			mutate: func(c *ChannelContribution) {
+				c.ChangeOutputs[0].Value = 0
+			},
+			reason: RejectChangeOutput,
+		},
+		{
+			name: "non-standard change script",
+			mutate: func(c *ChannelContribution) {
+				c.ChangeOutputs[0].PkScript = []byte{txscript.OP_TRUE}
+			},
+			reason: RejectChangeOutput,
+		},
+		{
+			name: "missing delivery address",
+			mutate: func(c *ChannelContribution) {
+				c.DeliveryAddress = nil
+			},
+			reason: RejectDeliveryAddress,
+		},
+		{
+			name: "funding amount above capacity",
+			mutate: func(c *ChannelContribution) {
+				c.FundingAmount = capacity + 1
+			},
+			reason: RejectFundingAmount,
+		},
+		{
+			name: "combined amount short of capacity",
+			mutate: func(c *ChannelContribution) {
+				c.FundingAmount = capacity - 1
+			},
+			reason: RejectFundingAmount,
+		},
+		{
+			name:         "single funder short of capacity",
+			singleFunder: true,
+			mutate: func(c *ChannelContribution) {
+				c.FundingAmount = capacity - 1
+			},
+			reason: RejectFundingAmount,
+		},
+		{
+			name: "different asset",
+			mutate: func(c *ChannelContribution) {
+				c.AssetParams.AssetID += "x"
+			},
+			reason: RejectAssetParams,
+		},
+	}
+	for _, testCase := range testCases {
+		theirs := validContribution()
+		testCase.mutate(theirs)
+
+		err := cfg.validateContribution(res, theirs,
+			testCase.singleFunder)
+		rejectErr, ok := err.(*ContributionError)
+		if !ok {
+			t.Fatalf("%v: expected ContributionError, got %v",
+				testCase.name, err)
+		}
+		if rejectErr.Reason != testCase.reason {
+			t.Fatalf("%v: expected reason %v, got %v",
+				testCase.name, testCase.reason, rejectErr.Reason)
+		}
+	}
+
+	// The underlying sentinel errors should be preserved, allowing the
+	// caller to tell them apart.
+	theirs := validContribution()
+	theirs.CsvDelay = cfg.MinCsvDelay - 1
+	err = cfg.validateContribution(res, theirs, false)
+	if rejectErr, ok := err.(*ContributionError); !ok ||
+		rejectErr.Err != ErrCsvDelayOutOfBounds {
+		t.Fatalf("expected ErrCsvDelayOutOfBounds, got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package lnwallet
+
+import (
+	"errors"
+
+	"github.com/lightningnetwork/lnd/metrics"
+)
+
+const (
+	// reserveQueueSize is the number of new reservation requests which
+	// may be queued for the request handler. Once full, further requests
+	// are refused with ErrWalletBusy, rather than blocking the caller.
+	reserveQueueSize = 20
+
+	// workflowQueueSize is the number of requests advancing, or
+	// cancelling existing reservations which may be queued for the
+	// request handler, per request type. As these requests complete
+	// resources already held by reservations, they're never refused, and
+	// callers block once the queue is full.
+	workflowQueueSize = 100
+
+	// controlQueueSize is the number of inspection, and fuel requests
+	// which may be queued for the request handler, per request type.
+	controlQueueSize = 10
+)
+
+// ErrWalletBusy is returned when a request is refused as the queue for
+// requests of its type is full.
+var ErrWalletBusy = errors.New("wallet request queue is full")
+
+// queueOverflows counts the requests refused as the queue for requests of
+// their type was full, labelled by the queue.
+var queueOverflows = metrics.DefaultRegistry.NewCounter(
+	"lnwallet_request_queue_overflows_total",
+	"Number of wallet requests refused as their queue was full.",
+	"queue",
+)
+
+// requestQueues houses a distinct, typed queue for each type of request
+// serviced by the wallet's request handler. Typing each queue ensures a
+// request can only be sent if the request handler services it, and sizing
+// each queue independently allows backpressure to be applied to new
+// reservations without stalling the workflows of existing ones.
+type requestQueues struct {
+	reserve            chan *initFundingReserveMsg
+	cancel             chan *fundingReserveCancelMsg
+	abort              chan *fundingAbortMsg
+	contribution       chan *addContributionMsg
+	singleContribution chan *addSingleContributionMsg
+	counterPartySigs   chan *addCounterPartySigsMsg
+	singleFunderSigs   chan *addSingleFunderSigsMsg
+	channelOpen        chan *channelOpenMsg
+	inspect            chan *inspectWalletMsg
+	addFuel            chan *addFuelMsg
+}
+
+// newRequestQueues creates the request queues with their configured buffer
+// sizes.
+func newRequestQueues() requestQueues {
+	return requestQueues{
+		reserve:            make(chan *initFundingReserveMsg, reserveQueueSize),
+		cancel:             make(chan *fundingReserveCancelMsg, workflowQueueSize),
+		abort:              make(chan *fundingAbortMsg, workflowQueueSize),
+		contribution:       make(chan *addContributionMsg, workflowQueueSize),
+		singleContribution: make(chan *addSingleContributionMsg, workflowQueueSize),
+		counterPartySigs:   make(chan *addCounterPartySigsMsg, workflowQueueSize),
+		singleFunderSigs:   make(chan *addSingleFunderSigsMsg, workflowQueueSize),
+		channelOpen:        make(chan *channelOpenMsg, workflowQueueSize),
+		inspect:            make(chan *inspectWalletMsg, controlQueueSize),
+		addFuel:            make(chan *addFuelMsg, controlQueueSize),
+	}
+}
+
+// requestHandler is the primary goroutine(s) resposible for handling, and
+// dispatching relies to all messages.
+func (l *LightningWallet) requestHandler() {
+	q := &l.queues
+
+out:
+	for {
+		select {
+		case msg := <-q.reserve:
+			if !l.refuseReadOnly(msg) {
+				l.handleFundingReserveRequest(msg)
+			}
+		case msg := <-q.cancel:
+			l.handleFundingCancelRequest(msg)
+		case msg := <-q.abort:
+			if !l.refuseReadOnly(msg) {
+				l.handleFundingAbort(msg)
+			}
+		case msg := <-q.singleContribution:
+			if !l.refuseReadOnly(msg) {
+				l.handleSingleContribution(msg)
+			}
+		case msg := <-q.contribution:
+			if !l.refuseReadOnly(msg) {
+				l.handleContributionMsg(msg)
+			}
+		case msg := <-q.singleFunderSigs:
+			if !l.refuseReadOnly(msg) {
+				l.handleSingleFunderSigs(msg)
+			}
+		case msg := <-q.counterPartySigs:
+			if !l.refuseReadOnly(msg) {
+				l.handleFundingCounterPartySigs(msg)
+			}
+		case msg := <-q.channelOpen:
+			if !l.refuseReadOnly(msg) {
+				l.handleChannelOpen(msg)
+			}
+		case msg := <-q.inspect:
+			l.handleInspect(msg)
+		case msg := <-q.addFuel:
+			if !l.refuseReadOnly(msg) {
+				l.handleAddFuel(msg)
+			}
+		case <-l.quit:
+			// TODO: do some clean up
+			break out
+		}
+	}
+
+	l.wg.Done()
+}
@@ -0,0 +1,55 @@
+package lnwallet
+
+import "testing"
+
+// TestReserveQueueOverflow tests that new reservations are refused once the
+// queue of reservation requests is full, rather than blocking the caller.
+func TestReserveQueueOverflow(t *testing.T) {
+	wallet := &LightningWallet{
+		queues: newRequestQueues(),
+	}
+	for i := 0; i < reserveQueueSize; i++ {
+		wallet.queues.reserve <- &initFundingReserveMsg{}
+	}
+
+	_, err := wallet.InitChannelReservation(1000, 1000, [32]byte{}, 1, 4)
+	if err != ErrWalletBusy {
+		t.Fatalf("expected ErrWalletBusy, got %v", err)
+	}
+}
+
+// TestReadOnlyWallet tests that a read-only wallet refuses to reserve
+// channels, or broadcast transactions, while still being inspectable.
+func TestReadOnlyWallet(t *testing.T) {
+	wallet := &LightningWallet{
+		cfg:          &Config{ReadOnly: true},
+		queues:       newRequestQueues(),
+		fundingLimbo: make(map[uint64]*ChannelReservation),
+		quit:         make(chan struct{}),
+	}
+	wallet.wg.Add(1)
+	go wallet.requestHandler()
+	defer func() {
+		close(wallet.quit)
+		wallet.wg.Wait()
+	}()
+
+	_, err := wallet.InitChannelReservation(1000, 1000, [32]byte{}, 1, 4)
+	if err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if err := wallet.PublishTransaction(nil); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if _, err := wallet.SendOutputs(nil); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+	if _, err := (readOnlySigner{}).SignOutputRaw(nil, nil); err != ErrReadOnly {
+		t.Fatalf("expected ErrReadOnly, got %v", err)
+	}
+
+	// Inspecting the wallet doesn't mutate it, so it's still serviced.
+	if len(wallet.ActiveReservations()) != 0 {
+		t.Fatalf("read-only wallet holds reservations")
+	}
+}
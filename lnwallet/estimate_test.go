@@ -0,0 +1,107 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// mockCoinSource is a WalletController whose unspent outputs are fixed.
+type mockCoinSource struct {
+	WalletController
+
+	coins []*Utxo
+}
+
+func (m *mockCoinSource) ListUnspentWitness(confirms int32) ([]*Utxo, error) {
+	return m.coins, nil
+}
+
+// TestEstimateTx tests that the asset amount of an estimated transaction is
+// paid by outputs of the active asset, while the satoshis of its outputs,
+// and its fee are paid by fuel where the colored outputs fall short.
+func TestEstimateTx(t *testing.T) {
+	defer func(assetID string) {
+		globallyActiveAssetId = assetID
+	}(globallyActiveAssetId)
+	globallyActiveAssetId = "asset"
+
+	coins := []*Utxo{
+		{
+			Value:     1000,
+			ColorData: &lndcc.TxoData{AssetId: "asset", Value: 100},
+			OutPoint:  wire.OutPoint{Index: 0},
+		},
+		{
+			Value:     1000,
+			ColorData: &lndcc.TxoData{AssetId: "other", Value: 500},
+			OutPoint:  wire.OutPoint{Index: 1},
+		},
+		{
+			Value:     1000,
+			ColorData: &lndcc.TxoData{AssetId: "asset", Value: 50},
+			OutPoint:  wire.OutPoint{Index: 2},
+		},
+		{
+			Value:     100000,
+			ColorData: &lndcc.TxoData{},
+			OutPoint:  wire.OutPoint{Index: 3},
+		},
+	}
+	wallet := &LightningWallet{
+		WalletController: &mockCoinSource{coins: coins},
+	}
+
+	const feeRate = 10
+	carrierAmt := lndcc.FundingOutputValue()
+	outputs := []*wire.TxOut{wire.NewTxOut(int64(carrierAmt), nil)}
+
+	// The asset amount is paid by both outputs of the asset, leaving 30
+	// units of change. As they carry too few satoshis for the output,
+	// change output, and fee, fuel is added.
+	estimate, err := wallet.EstimateTx(outputs, 120, feeRate)
+	if err != nil {
+		t.Fatalf("unable to estimate tx: %v", err)
+	}
+	if len(estimate.Inputs) != 3 || estimate.Inputs[0].Index != 0 ||
+		estimate.Inputs[1].Index != 2 || estimate.Inputs[2].Index != 3 {
+
+		t.Fatalf("expected outputs 0 and 2 with fuel from output 3, "+
+			"got %v", estimate.Inputs)
+	}
+	if estimate.Change != 30 {
+		t.Fatalf("expected asset change of 30, got %v", estimate.Change)
+	}
+
+	// Along with the output itself, the estimate accounts for both the
+	// asset change, and the fuel change outputs.
+	vsize := estimateVSize(3, 3)
+	fee := btcutil.Amount(vsize * feeRate)
+	fuelChange := 2000 + 100000 - 2*carrierAmt - fee
+	if estimate.VSize != vsize || estimate.Fee != fee ||
+		estimate.FuelChange != fuelChange {
+
+		t.Fatalf("expected vsize %v, fee %v, and fuel change %v, got "+
+			"%v, %v, and %v", vsize, fee, fuelChange, estimate.VSize,
+			estimate.Fee, estimate.FuelChange)
+	}
+
+	// Beyond the wallet's outputs of the asset, estimation fails.
+	if _, err := wallet.EstimateTx(outputs, 200, feeRate); err != ErrInsufficientFunds {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+// TestEstimateVSize tests that the segwit marker and flag are discounted
+// along with the rest of the witness data.
+func TestEstimateVSize(t *testing.T) {
+	// A transaction spending a single p2wkh output to a single p2wsh
+	// output, along with the colored coins output, has a base size of
+	// 10 + 41 + 43 + 92 = 186 bytes, and a witness of 2 + 109 = 111
+	// bytes, which adds 28 vbytes.
+	if vsize := estimateVSize(1, 1); vsize != 214 {
+		t.Fatalf("expected vsize of 214, got %v", vsize)
+	}
+}
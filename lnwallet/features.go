@@ -0,0 +1,157 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/elkrem"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/shachain"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// ErrIncompatibleFeatures is returned when the version of the colored channel
+// protocol proposed, or selected, by the remote party can't be reconciled
+// with our own.
+var ErrIncompatibleFeatures = errors.New("incompatible colored channel " +
+	"protocol features")
+
+// SupportedFeatures returns the latest version of the colored channel
+// protocol we support, which is proposed by the initiator of a channel. As
+// each version of the instruction encoding supersedes the previous, all prior
+// versions are supported as well.
+func SupportedFeatures() channeldb.ChannelFeatures {
+	return channeldb.ChannelFeatures{
+		InstructionVersion: 0,
+		DustPolicy:         channeldb.DustPolicyFixedCarrier,
+		SecondLevelHTLCs:   false,
+	}
+}
+
+// newRevocationProducer returns the producer of the revocation secrets of
+// our commitments, derived from the passed root, using the scheme selected
+// by the channel features.
+func newRevocationProducer(features *channeldb.ChannelFeatures,
+	root wire.ShaHash) shachain.Producer {
+
+	if features.ShaChain {
+		return shachain.NewRevocationProducer(root)
+	}
+
+	return elkrem.NewElkremSender(root)
+}
+
+// newRevocationStore returns an empty store for the revocation secrets
+// revealed by the remote party, using the scheme selected by the channel
+// features.
+func newRevocationStore(features *channeldb.ChannelFeatures) shachain.Store {
+	if features.ShaChain {
+		return shachain.NewRevocationStore()
+	}
+
+	return &elkrem.ElkremReceiver{}
+}
+
+// copyRevocationStore returns a deep copy of the passed store, allowing a
+// secret to be added without modifying the store until the new state has
+// been committed.
+func copyRevocationStore(store shachain.Store) (shachain.Store, error) {
+	storeBytes, err := store.ToBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	switch store.(type) {
+	case *shachain.RevocationStore:
+		return shachain.RevocationStoreFromBytes(storeBytes)
+	case *elkrem.ElkremReceiver:
+		return elkrem.ElkremReceiverFromBytes(storeBytes)
+	default:
+		return nil, fmt.Errorf("unknown revocation store %T", store)
+	}
+}
+
+// negotiateFeatures selects the version of the colored channel protocol to
+// be used by a channel, given the features we support, and those proposed by
+// the initiator. The latest instruction encoding supported by both parties
+// is selected, along with the optional features supported by both. The dust
+// policy isn't negotiable, so the proposed policy must match our own.
+func negotiateFeatures(ours,
+	theirs *channeldb.ChannelFeatures) (channeldb.ChannelFeatures, error) {
+
+	if theirs.DustPolicy != ours.DustPolicy {
+		return channeldb.ChannelFeatures{}, fmt.Errorf("%v: dust "+
+			"policy %v unsupported", ErrIncompatibleFeatures,
+			theirs.DustPolicy)
+	}
+
+	features := channeldb.ChannelFeatures{
+		InstructionVersion: ours.InstructionVersion,
+		DustPolicy:         ours.DustPolicy,
+		SecondLevelHTLCs:   ours.SecondLevelHTLCs && theirs.SecondLevelHTLCs,
+		ShaChain:           ours.ShaChain && theirs.ShaChain,
+	}
+	if theirs.InstructionVersion < features.InstructionVersion {
+		features.InstructionVersion = theirs.InstructionVersion
+	}
+
+	return features, nil
+}
+
+// verifySelectedFeatures ensures the features selected by the responder of a
+// channel are within those we proposed as the initiator.
+func verifySelectedFeatures(proposed,
+	selected *channeldb.ChannelFeatures) error {
+
+	switch {
+	case selected.InstructionVersion > proposed.InstructionVersion:
+		return fmt.Errorf("%v: instruction version %v exceeds "+
+			"proposed version %v", ErrIncompatibleFeatures,
+			selected.InstructionVersion, proposed.InstructionVersion)
+
+	case selected.DustPolicy != proposed.DustPolicy:
+		return fmt.Errorf("%v: dust policy %v doesn't match proposed "+
+			"policy %v", ErrIncompatibleFeatures,
+			selected.DustPolicy, proposed.DustPolicy)
+
+	case selected.SecondLevelHTLCs && !proposed.SecondLevelHTLCs:
+		return fmt.Errorf("%v: second-level HTLCs weren't proposed",
+			ErrIncompatibleFeatures)
+
+	case selected.ShaChain && !proposed.ShaChain:
+		return fmt.Errorf("%v: shachain wasn't proposed",
+			ErrIncompatibleFeatures)
+	}
+
+	return nil
+}
+
+// FeaturesFromWire returns the channel features encoded within the fields of
+// a SingleFundingRequest or SingleFundingResponse. Unknown feature flags are
+// ignored.
+func FeaturesFromWire(instructionVersion, dustPolicy,
+	featureFlags uint8) channeldb.ChannelFeatures {
+
+	return channeldb.ChannelFeatures{
+		InstructionVersion: instructionVersion,
+		DustPolicy:         channeldb.DustPolicy(dustPolicy),
+		SecondLevelHTLCs: featureFlags&
+			lnwire.FeatureSecondLevelHTLCs != 0,
+		ShaChain: featureFlags&lnwire.FeatureShaChain != 0,
+	}
+}
+
+// WireFeatureFlags returns the feature flags signalling the optional features
+// within the passed channel features.
+func WireFeatureFlags(features *channeldb.ChannelFeatures) uint8 {
+	var flags uint8
+	if features.SecondLevelHTLCs {
+		flags |= lnwire.FeatureSecondLevelHTLCs
+	}
+	if features.ShaChain {
+		flags |= lnwire.FeatureShaChain
+	}
+
+	return flags
+}
@@ -0,0 +1,142 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/shachain"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestNegotiateFeatures tests that the responder selects the latest version
+// of the colored channel protocol supported by both parties, and that the
+// initiator only accepts selections within its proposal.
+func TestNegotiateFeatures(t *testing.T) {
+	ours := channeldb.ChannelFeatures{
+		InstructionVersion: 2,
+		SecondLevelHTLCs:   true,
+	}
+
+	testCases := []struct {
+		name     string
+		theirs   channeldb.ChannelFeatures
+		selected channeldb.ChannelFeatures
+		err      bool
+	}{
+		{
+			name:     "identical features",
+			theirs:   ours,
+			selected: ours,
+		},
+		{
+			name: "older initiator",
+			theirs: channeldb.ChannelFeatures{
+				InstructionVersion: 1,
+			},
+			selected: channeldb.ChannelFeatures{
+				InstructionVersion: 1,
+			},
+		},
+		{
+			name: "newer initiator",
+			theirs: channeldb.ChannelFeatures{
+				InstructionVersion: 3,
+				SecondLevelHTLCs:   true,
+			},
+			selected: ours,
+		},
+		{
+			name: "shachain initiator",
+			theirs: channeldb.ChannelFeatures{
+				InstructionVersion: 2,
+				SecondLevelHTLCs:   true,
+				ShaChain:           true,
+			},
+			selected: ours,
+		},
+		{
+			name: "unknown dust policy",
+			theirs: channeldb.ChannelFeatures{
+				InstructionVersion: 2,
+				DustPolicy:         1,
+			},
+			err: true,
+		},
+	}
+	for _, testCase := range testCases {
+		selected, err := negotiateFeatures(&ours, &testCase.theirs)
+		if testCase.err {
+			if err == nil {
+				t.Fatalf("%v: expected negotiation to fail",
+					testCase.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: unable to negotiate features: %v",
+				testCase.name, err)
+		}
+		if selected != testCase.selected {
+			t.Fatalf("%v: expected %+v, got %+v", testCase.name,
+				testCase.selected, selected)
+		}
+
+		// The initiator should accept the selection, and it should
+		// survive the trip over the wire.
+		if err := verifySelectedFeatures(&testCase.theirs,
+			&selected); err != nil {
+			t.Fatalf("%v: selection rejected: %v", testCase.name,
+				err)
+		}
+		wireFeatures := FeaturesFromWire(selected.InstructionVersion,
+			uint8(selected.DustPolicy), WireFeatureFlags(&selected))
+		if wireFeatures != selected {
+			t.Fatalf("%v: expected %+v over the wire, got %+v",
+				testCase.name, selected, wireFeatures)
+		}
+	}
+
+	// A responder selecting features beyond our proposal is rejected.
+	proposed := channeldb.ChannelFeatures{InstructionVersion: 1}
+	for _, selected := range []channeldb.ChannelFeatures{
+		{InstructionVersion: 2},
+		{InstructionVersion: 1, DustPolicy: 1},
+		{InstructionVersion: 1, SecondLevelHTLCs: true},
+		{InstructionVersion: 1, ShaChain: true},
+	} {
+		if err := verifySelectedFeatures(&proposed, &selected); err == nil {
+			t.Fatalf("selection %+v accepted", selected)
+		}
+	}
+}
+
+// TestShaChainNegotiation tests that the shachain is only selected when
+// supported by both parties, and that the revocation state of the channel
+// follows the selection.
+func TestShaChainNegotiation(t *testing.T) {
+	ours := channeldb.ChannelFeatures{ShaChain: true}
+	for _, theirShaChain := range []bool{false, true} {
+		theirs := channeldb.ChannelFeatures{ShaChain: theirShaChain}
+		selected, err := negotiateFeatures(&ours, &theirs)
+		if err != nil {
+			t.Fatalf("unable to negotiate features: %v", err)
+		}
+		if selected.ShaChain != theirShaChain {
+			t.Fatalf("expected shachain %v, got %v", theirShaChain,
+				selected.ShaChain)
+		}
+
+		producer := newRevocationProducer(&selected, wire.ShaHash{})
+		_, isShaChain := producer.(*shachain.RevocationProducer)
+		if isShaChain != theirShaChain {
+			t.Fatalf("expected shachain producer %v, got %T",
+				theirShaChain, producer)
+		}
+		store := newRevocationStore(&selected)
+		_, isShaChain = store.(*shachain.RevocationStore)
+		if isShaChain != theirShaChain {
+			t.Fatalf("expected shachain store %v, got %T",
+				theirShaChain, store)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// DefaultLowFuelThreshold is the default fuel balance below which the
+	// wallet warns that it's running low on fuel. This is enough to fund
+	// the carrier outputs of a handful of funding transactions.
+	DefaultLowFuelThreshold = 546 * 15 * 5
+
+	// fuelChangeDustLimit is the smallest fuel change output we'll
+	// create. Any smaller amount left over by our fuel inputs is instead
+	// given to the miners.
+	fuelChangeDustLimit = 546
+)
+
+// ErrInsufficientFuel is returned when the wallet's uncolored outputs carry
+// too few satoshis to pay for the carrier outputs and fees of a transaction.
+var ErrInsufficientFuel = errors.New("not enough uncolored outputs to " +
+	"pay for carrier outputs and fees")
+
+// The fuel sub-account is the set of the wallet's uncolored outputs. Colored
+// transactions spend plain bitcoin: each colored output is given a dust
+// carrier amount, and the transaction must pay fees. As coin selection only
+// picks outputs carrying the channel's asset, the satoshis those outputs carry
+// may not suffice, in which case uncolored outputs are selected to fuel the
+// transaction. Any fuel left over is returned to the wallet within an
+// uncolored output following the OP_RETURN output.
+
+// isFuel returns true if the passed output carries no asset, and may be spent
+// to fuel colored transactions.
+func isFuel(coin *Utxo) bool {
+	return coin.ColorData == nil || coin.ColorData.AssetId == ""
+}
+
+// FuelStatus describes the satoshis available to fuel colored transactions.
+type FuelStatus struct {
+	// Balance is the total value of the wallet's confirmed, unlocked,
+	// uncolored outputs.
+	Balance btcutil.Amount
+
+	// NumOutputs is the number of outputs making up the Balance.
+	NumOutputs int
+
+	// Threshold is the balance below which the fuel is considered low.
+	Threshold btcutil.Amount
+
+	// Low is true if the Balance has fallen below the Threshold.
+	Low bool
+}
+
+// fuelStatus tallies the fuel outputs within the passed coins, skipping any
+// in the exclude set.
+func (c *Config) fuelStatus(coins []*Utxo,
+	exclude map[wire.OutPoint]struct{}) *FuelStatus {
+
+	status := &FuelStatus{
+		Threshold: c.LowFuelThreshold,
+	}
+	for _, coin := range coins {
+		if _, ok := exclude[coin.OutPoint]; ok || !isFuel(coin) {
+			continue
+		}
+
+		status.Balance += coin.Value
+		status.NumOutputs++
+	}
+	status.Low = status.Balance < status.Threshold
+
+	return status
+}
+
+// FuelBalance returns the satoshis currently available to fuel colored
+// transactions.
+func (l *LightningWallet) FuelBalance() (*FuelStatus, error) {
+	l.coinSelectMtx.Lock()
+	defer l.coinSelectMtx.Unlock()
+
+	coins, err := l.ListUnspentWitness(1)
+	if err != nil {
+		return nil, err
+	}
+
+	return l.cfg.fuelStatus(coins, nil), nil
+}
+
+// checkFuel warns, and notifies the configured LowFuelHandler if the fuel
+// left over after spending the selected outputs has fallen below the low fuel
+// threshold.
+func (l *LightningWallet) checkFuel(coins []*Utxo, selected []*wire.OutPoint) {
+	spent := make(map[wire.OutPoint]struct{}, len(selected))
+	for _, outPoint := range selected {
+		spent[*outPoint] = struct{}{}
+	}
+
+	status := l.cfg.fuelStatus(coins, spent)
+	if !status.Low {
+		return
+	}
+
+	walletLog.Warnf("Fuel balance of %v across %v outputs is below "+
+		"threshold of %v, colored transactions may soon be unfundable",
+		status.Balance, status.NumOutputs, status.Threshold)
+
+	if l.cfg.LowFuelHandler != nil {
+		l.cfg.LowFuelHandler(status)
+	}
+}
+
+// fuelSelect selects fuel outputs so that, along with the satoshis already
+// carried by the transaction's numInputs inputs, the outputs of the
+// transaction totaling required satoshis, and its fee at the passed fee rate,
+// are paid for. The fee accounts for the fuel inputs, numOutputs outputs
+// along with the OP_RETURN output, and a fuel change output. The selected
+// outpoints are returned along with the fuel change, which is zero if it
+// would be dust.
+func fuelSelect(feeRate uint64, required, carried btcutil.Amount,
+	numInputs, numOutputs int,
+	coins []*Utxo) ([]*wire.OutPoint, btcutil.Amount, error) {
+
+	var selected []*wire.OutPoint
+	i := 0
+	for {
+		vsize := estimateVSize(numInputs+len(selected), numOutputs+1)
+		needed := required + btcutil.Amount(uint64(vsize)*feeRate)
+		if carried >= needed {
+			change := carried - needed
+			if change < fuelChangeDustLimit {
+				change = 0
+			}
+
+			return selected, change, nil
+		}
+
+		// Skip over any outputs carrying an asset, as they can't be
+		// spent without also transferring it.
+		for i < len(coins) && !isFuel(coins[i]) {
+			i++
+		}
+		if i == len(coins) {
+			return nil, 0, ErrInsufficientFuel
+		}
+
+		coin := coins[i]
+		selected = append(selected, &wire.OutPoint{
+			Hash:  coin.Hash,
+			Index: coin.Index,
+		})
+		carried += coin.Value
+		i++
+	}
+}
+
+// addFuelMsg is sent to the wallet's request handler in order to fuel a
+// transaction spending outputs external to the wallet.
+type addFuelMsg struct {
+	tx      *wire.MsgTx
+	amt     btcutil.Amount
+	feeRate uint64
+
+	err chan error
+}
+
+// AddFuel adds fuel inputs to the passed transaction, paying amt satoshis
+// beyond those carried by its existing inputs, along with the fee for the
+// entire transaction at the passed fee rate. The fuel inputs are appended to
+// the transaction's inputs, and the fuel change output, if any, to its
+// outputs. As the change output follows any OP_RETURN output, it's never
+// assigned an asset. This allows transactions which sweep colored outputs,
+// the dust carriers of which carry too few satoshis to pay fees, to be
+// broadcast.
+//
+// The fuel inputs remain locked until the wallet is restarted, and must be
+// signed for using SignFuel once the transaction is otherwise final.
+func (l *LightningWallet) AddFuel(tx *wire.MsgTx, amt btcutil.Amount,
+	feeRate uint64) error {
+
+	req := &addFuelMsg{
+		tx:      tx,
+		amt:     amt,
+		feeRate: feeRate,
+		err:     make(chan error, 1),
+	}
+
+	select {
+	case l.queues.addFuel <- req:
+	case <-l.quit:
+		return fmt.Errorf("wallet shutting down")
+	}
+
+	select {
+	case err := <-req.err:
+		return err
+	case <-l.quit:
+		return fmt.Errorf("wallet shutting down")
+	}
+}
+
+// handleAddFuel selects, and locks fuel outputs to fund the transaction
+// within the passed request.
+func (l *LightningWallet) handleAddFuel(req *addFuelMsg) {
+	l.coinSelectMtx.Lock()
+	defer l.coinSelectMtx.Unlock()
+
+	coins, err := l.ListUnspentWitness(1)
+	if err != nil {
+		req.err <- err
+		return
+	}
+
+	// The satoshis carried by the existing inputs are already accounted
+	// for by the requested amount, so only their size is considered. Any
+	// OP_RETURN output is counted twice, erring on the side of a higher
+	// fee.
+	tx := req.tx
+	fuel, change, err := fuelSelect(req.feeRate, req.amt, 0, len(tx.TxIn),
+		len(tx.TxOut), coins)
+	if err != nil {
+		req.err <- err
+		return
+	}
+
+	var changeScript []byte
+	if change != 0 {
+		changeAddr, err := l.NewChannelAddress(WitnessPubKey, true)
+		if err != nil {
+			req.err <- err
+			return
+		}
+		changeScript, err = txscript.PayToAddrScript(changeAddr)
+		if err != nil {
+			req.err <- err
+			return
+		}
+	}
+
+	// Lock all of the fuel before adding any of it, so the transaction is
+	// left untouched should a lock fail.
+	for i, outPoint := range fuel {
+		if err := l.lockOutpoint(*outPoint); err != nil {
+			for _, lockedOutPoint := range fuel[:i] {
+				l.unlockOutpoint(*lockedOutPoint)
+			}
+			req.err <- err
+			return
+		}
+	}
+	for _, outPoint := range fuel {
+		tx.AddTxIn(wire.NewTxIn(outPoint, nil, nil))
+	}
+	if change != 0 {
+		tx.AddTxOut(wire.NewTxOut(int64(change), changeScript))
+	}
+
+	walletLog.Debugf("Fueled tx with %v inputs, %v of fuel change",
+		len(fuel), change)
+
+	l.checkFuel(coins, fuel)
+
+	req.err <- nil
+}
+
+// SignFuel generates a valid witness for each input of the passed
+// transaction which spends one of the wallet's outputs, such as those added
+// by AddFuel. Inputs spending outputs external to the wallet are left as is.
+func (l *LightningWallet) SignFuel(tx *wire.MsgTx,
+	hashCache *txscript.TxSigHashes) error {
+
+	signDesc := SignDescriptor{
+		HashType:  txscript.SigHashAll,
+		SigHashes: hashCache,
+	}
+	for i, txIn := range tx.TxIn {
+		info, err := l.FetchInputInfo(&txIn.PreviousOutPoint)
+		if err == ErrNotMine {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		signDesc.Output = info
+		signDesc.InputIndex = i
+
+		inputScript, err := l.Signer.ComputeInputScript(tx, &signDesc)
+		if err != nil {
+			return err
+		}
+
+		txIn.SignatureScript = inputScript.ScriptSig
+		txIn.Witness = inputScript.Witness
+	}
+
+	return nil
+}
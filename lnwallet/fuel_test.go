@@ -0,0 +1,91 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestFuelSelect tests that fuel outputs are only selected once the satoshis
+// carried by a transaction's inputs fall short of its carrier outputs and
+// fee, that colored outputs are never selected as fuel, and that dust fuel
+// change is given to the miners.
+func TestFuelSelect(t *testing.T) {
+	coins := []*Utxo{
+		{
+			Value:     10000,
+			ColorData: &lndcc.TxoData{AssetId: "asset", Value: 100},
+			OutPoint:  wire.OutPoint{Index: 0},
+		},
+		{
+			Value:     5000,
+			ColorData: &lndcc.TxoData{},
+			OutPoint:  wire.OutPoint{Index: 1},
+		},
+		{
+			Value:    20000,
+			OutPoint: wire.OutPoint{Index: 2},
+		},
+	}
+
+	const feeRate = 10
+	fee := func(numInputs, numOutputs int) btcutil.Amount {
+		return btcutil.Amount(estimateVSize(numInputs, numOutputs) * feeRate)
+	}
+
+	// If the inputs already carry enough satoshis, then no fuel is
+	// selected, with the excess returned as change.
+	carried := 10000 + fee(1, 2)
+	fuel, change, err := fuelSelect(feeRate, 8190, carried, 1, 1, coins)
+	if err != nil {
+		t.Fatalf("unable to select fuel: %v", err)
+	}
+	if len(fuel) != 0 || change != 10000-8190 {
+		t.Fatalf("expected no fuel with change of %v, got %v inputs "+
+			"with change of %v", 10000-8190, len(fuel), change)
+	}
+
+	// Falling short of the carrier outputs, the first uncolored output is
+	// selected, skipping over the colored one. As the excess is dust, no
+	// change is returned.
+	fuel, change, err = fuelSelect(feeRate, 8190, 3290+fee(2, 2), 1, 1,
+		coins)
+	if err != nil {
+		t.Fatalf("unable to select fuel: %v", err)
+	}
+	if len(fuel) != 1 || fuel[0].Index != 1 || change != 0 {
+		t.Fatalf("expected fuel from output 1 without change, got "+
+			"%v inputs with change of %v", len(fuel), change)
+	}
+
+	// A larger shortfall requires both uncolored outputs.
+	fuel, _, err = fuelSelect(feeRate, 8190*2, 0, 1, 2, coins)
+	if err != nil {
+		t.Fatalf("unable to select fuel: %v", err)
+	}
+	if len(fuel) != 2 || fuel[0].Index != 1 || fuel[1].Index != 2 {
+		t.Fatalf("expected fuel from outputs 1 and 2, got %v", fuel)
+	}
+
+	// Beyond the wallet's uncolored outputs, selection fails.
+	_, _, err = fuelSelect(feeRate, 30000, 0, 1, 1, coins)
+	if err != ErrInsufficientFuel {
+		t.Fatalf("expected ErrInsufficientFuel, got %v", err)
+	}
+
+	// The fuel balance only counts the uncolored outputs which aren't
+	// excluded.
+	cfg := &Config{LowFuelThreshold: 10000}
+	status := cfg.fuelStatus(coins, map[wire.OutPoint]struct{}{
+		{Index: 2}: {},
+	})
+	if status.Balance != 5000 || status.NumOutputs != 1 || !status.Low {
+		t.Fatalf("unexpected fuel status: %+v", status)
+	}
+	status = cfg.fuelStatus(coins, nil)
+	if status.Balance != 25000 || status.NumOutputs != 2 || status.Low {
+		t.Fatalf("unexpected fuel status: %+v", status)
+	}
+}
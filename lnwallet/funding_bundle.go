@@ -0,0 +1,336 @@
+package lnwallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// maxBundleInstructions is the largest number of transfer instructions we'll
+// accept within a FundingBundle, bounding the memory used while decoding.
+const maxBundleInstructions = 256
+
+// ErrInvalidFundingBundle is returned when the contents of a FundingBundle
+// are inconsistent with its funding transaction.
+var ErrInvalidFundingBundle = errors.New("funding bundle is inconsistent " +
+	"with its funding transaction")
+
+// FundingBundle is a self-contained, verifiable record of the funding
+// transaction of a channel, generated as the channel opens. It's meant to be
+// handed to the issuer of the channel's asset, allowing them to whitelist, or
+// track the channels holding their asset without trusting us: the SpvProof
+// proves the transaction's inclusion within the main chain, and the funding
+// output is checked to be the 2-of-2 multi-sig of the channel parties' keys.
+type FundingBundle struct {
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// FundingTx is the colorified funding transaction.
+	FundingTx *wire.MsgTx
+
+	// Proof proves the inclusion of FundingTx within the main chain.
+	Proof SpvProof
+
+	// AssetID is the asset held by the channel.
+	AssetID string
+
+	// AssetCapacity is the amount of the asset held by the channel.
+	AssetCapacity btcutil.Amount
+
+	// Instructions are the transfer instructions encoded within the
+	// OP_RETURN output of FundingTx. They're only present if the payload
+	// may be decoded locally, see lndcc.DecodeTransfer.
+	Instructions []lndcc.Instruction
+
+	// LocalKey and RemoteKey are the multi-sig keys of the channel
+	// parties, from our point of view.
+	LocalKey  *btcec.PublicKey
+	RemoteKey *btcec.PublicKey
+}
+
+// FundingBundleInfo is the exported view of a FundingBundle, summarizing the
+// bundle alongside its serialization.
+type FundingBundleInfo struct {
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// AssetID and AssetCapacity are the asset, and amount of it held by
+	// the channel.
+	AssetID       string
+	AssetCapacity btcutil.Amount
+
+	// Instructions are the transfer instructions of the funding
+	// transaction, if they may be decoded locally.
+	Instructions []lndcc.Instruction
+
+	// LocalKey and RemoteKey are the hex encoded multi-sig keys of the
+	// channel parties.
+	LocalKey  string
+	RemoteKey string
+
+	// Bundle is the hex encoded serialization of the FundingBundle, to be
+	// decoded, and verified by the issuer via FundingBundle.Verify.
+	Bundle string
+}
+
+// Info returns the exported view of the FundingBundle.
+func (b *FundingBundle) Info() (*FundingBundleInfo, error) {
+	var raw bytes.Buffer
+	if err := b.Encode(&raw); err != nil {
+		return nil, err
+	}
+
+	return &FundingBundleInfo{
+		ChanPoint:     b.ChanPoint,
+		AssetID:       b.AssetID,
+		AssetCapacity: b.AssetCapacity,
+		Instructions:  b.Instructions,
+		LocalKey:      hex.EncodeToString(b.LocalKey.SerializeCompressed()),
+		RemoteKey:     hex.EncodeToString(b.RemoteKey.SerializeCompressed()),
+		Bundle:        hex.EncodeToString(raw.Bytes()),
+	}, nil
+}
+
+// Verify ensures the FundingBundle is consistent with its funding
+// transaction, and that the transaction has been included within a block of
+// our main chain, as reported by the passed BlockChainIO, which has been
+// buried under at least numConfs blocks.
+func (b *FundingBundle) Verify(bio BlockChainIO, numConfs uint32) error {
+	txid := b.FundingTx.TxSha()
+	if txid != b.ChanPoint.Hash {
+		return fmt.Errorf("%v: txid %v doesn't match channel point %v",
+			ErrInvalidFundingBundle, txid, b.ChanPoint)
+	}
+	if b.ChanPoint.Index >= uint32(len(b.FundingTx.TxOut)) {
+		return fmt.Errorf("%v: funding output %v not found",
+			ErrInvalidFundingBundle, b.ChanPoint)
+	}
+
+	// The funding output must pay to the 2-of-2 multi-sig of the keys
+	// within the bundle.
+	redeemScript, err := genMultiSigScript(
+		b.LocalKey.SerializeCompressed(),
+		b.RemoteKey.SerializeCompressed())
+	if err != nil {
+		return err
+	}
+	pkScript, err := witnessScriptHash(redeemScript)
+	if err != nil {
+		return err
+	}
+	fundingOutput := b.FundingTx.TxOut[b.ChanPoint.Index]
+	if !bytes.Equal(fundingOutput.PkScript, pkScript) {
+		return fmt.Errorf("%v: funding output doesn't pay to the "+
+			"multi-sig of the channel keys", ErrInvalidFundingBundle)
+	}
+
+	if !reflect.DeepEqual(b.Instructions,
+		lndcc.DecodeTransfer(b.FundingTx)) {
+
+		return fmt.Errorf("%v: instructions don't match the funding "+
+			"transaction's payload", ErrInvalidFundingBundle)
+	}
+
+	return b.Proof.Verify(bio, &txid, numConfs)
+}
+
+// Encode serializes the FundingBundle into the passed io.Writer.
+func (b *FundingBundle) Encode(w io.Writer) error {
+	var scratch [8]byte
+	if _, err := w.Write(b.ChanPoint.Hash[:]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(scratch[:4], b.ChanPoint.Index)
+	if _, err := w.Write(scratch[:4]); err != nil {
+		return err
+	}
+
+	if err := b.FundingTx.Serialize(w); err != nil {
+		return err
+	}
+	if err := b.Proof.Encode(w); err != nil {
+		return err
+	}
+	if err := wire.WriteVarString(w, 0, b.AssetID); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint64(scratch[:], uint64(b.AssetCapacity))
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(b.Instructions))); err != nil {
+		return err
+	}
+	for _, inst := range b.Instructions {
+		var flags byte
+		if inst.Skip {
+			flags |= 1
+		}
+		if inst.Range {
+			flags |= 2
+		}
+		if inst.Percent {
+			flags |= 4
+		}
+		if _, err := w.Write([]byte{flags}); err != nil {
+			return err
+		}
+
+		binary.BigEndian.PutUint32(scratch[:4], inst.Output)
+		if _, err := w.Write(scratch[:4]); err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint64(scratch[:], uint64(inst.Amount))
+		if _, err := w.Write(scratch[:]); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Write(b.LocalKey.SerializeCompressed()); err != nil {
+		return err
+	}
+	_, err := w.Write(b.RemoteKey.SerializeCompressed())
+	return err
+}
+
+// Decode deserializes a FundingBundle from the passed io.Reader.
+func (b *FundingBundle) Decode(r io.Reader) error {
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, b.ChanPoint.Hash[:]); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return err
+	}
+	b.ChanPoint.Index = binary.BigEndian.Uint32(scratch[:4])
+
+	b.FundingTx = wire.NewMsgTx()
+	if err := b.FundingTx.Deserialize(r); err != nil {
+		return err
+	}
+	if err := b.Proof.Decode(r); err != nil {
+		return err
+	}
+
+	var err error
+	b.AssetID, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	b.AssetCapacity = btcutil.Amount(binary.BigEndian.Uint64(scratch[:]))
+
+	numInsts, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	if numInsts > maxBundleInstructions {
+		return fmt.Errorf("%v instructions exceeds max of %v", numInsts,
+			maxBundleInstructions)
+	}
+	b.Instructions = nil
+	for i := uint64(0); i < numInsts; i++ {
+		var flags [1]byte
+		if _, err := io.ReadFull(r, flags[:]); err != nil {
+			return err
+		}
+		inst := lndcc.Instruction{
+			Skip:    flags[0]&1 != 0,
+			Range:   flags[0]&2 != 0,
+			Percent: flags[0]&4 != 0,
+		}
+
+		if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+			return err
+		}
+		inst.Output = binary.BigEndian.Uint32(scratch[:4])
+		if _, err := io.ReadFull(r, scratch[:]); err != nil {
+			return err
+		}
+		inst.Amount = int(binary.BigEndian.Uint64(scratch[:]))
+
+		b.Instructions = append(b.Instructions, inst)
+	}
+
+	var key [33]byte
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		return err
+	}
+	b.LocalKey, err = btcec.ParsePubKey(key[:], btcec.S256())
+	if err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, key[:]); err != nil {
+		return err
+	}
+	b.RemoteKey, err = btcec.ParsePubKey(key[:], btcec.S256())
+	return err
+}
+
+// FundingBundle generates the FundingBundle of the reservation's funding
+// transaction, fetching the block which included it from the chain.
+//
+// NOTE: This method will only succeed once the funding transaction has been
+// confirmed. On the responder's side of a single funder workflow, the block
+// is only known once the initiator's funding proof has been verified.
+func (r *ChannelReservation) FundingBundle() (*FundingBundle, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	fundingPoint := r.partialState.FundingOutpoint
+	if fundingPoint == nil || r.fundingHeight == 0 {
+		return nil, fmt.Errorf("funding transaction not yet confirmed")
+	}
+
+	bio := r.wallet.chainIO
+	blockHash, err := bio.GetBlockHash(int64(r.fundingHeight))
+	if err != nil {
+		return nil, err
+	}
+	block, err := bio.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var fundingTx *wire.MsgTx
+	for _, tx := range block.Transactions {
+		if tx.TxSha() == fundingPoint.Hash {
+			fundingTx = tx
+			break
+		}
+	}
+	if fundingTx == nil {
+		return nil, fmt.Errorf("funding tx %v not found within block %v",
+			fundingPoint.Hash, blockHash)
+	}
+
+	proof, err := NewSpvProof(block, r.fundingHeight, &fundingPoint.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FundingBundle{
+		ChanPoint:     *fundingPoint,
+		FundingTx:     fundingTx,
+		Proof:         *proof,
+		AssetID:       r.partialState.AssetID,
+		AssetCapacity: r.partialState.AssetCapacity,
+		Instructions:  lndcc.DecodeTransfer(fundingTx),
+		LocalKey:      r.partialState.OurMultiSigKey,
+		RemoteKey:     r.partialState.TheirMultiSigKey,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package lnwallet
+
+import (
+	"bytes"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestFundingBundle tests that a FundingBundle survives a round trip through
+// its serialization, verifies against the chain including its funding
+// transaction, and is rejected once tampered with.
+func TestFundingBundle(t *testing.T) {
+	localPriv, _ := btcec.NewPrivateKey(btcec.S256())
+	remotePriv, _ := btcec.NewPrivateKey(btcec.S256())
+	localKey, remoteKey := localPriv.PubKey(), remotePriv.PubKey()
+
+	_, fundingOutput, err := GenFundingPkScript(
+		localKey.SerializeCompressed(), remoteKey.SerializeCompressed(),
+		1000)
+	if err != nil {
+		t.Fatalf("unable to generate funding script: %v", err)
+	}
+
+	insts := []lndcc.Instruction{{Output: 0, Amount: 1000}}
+	payload, err := lndcc.EncodeTransfer(insts)
+	if err != nil {
+		t.Fatalf("unable to encode instructions: %v", err)
+	}
+	opReturn := append([]byte{0x6a, byte(len(payload))}, payload...)
+
+	fundingTx := wire.NewMsgTx()
+	fundingTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 7}, nil, nil))
+	fundingTx.AddTxOut(wire.NewTxOut(5460, fundingOutput.PkScript))
+	fundingTx.AddTxOut(wire.NewTxOut(0, opReturn))
+	txid := fundingTx.TxSha()
+
+	// The funding transaction is the sole transaction of the second
+	// block, so its txid is the block's merkle root.
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{MerkleRoot: txid},
+	}
+	block.AddTransaction(fundingTx)
+	chain := &mockChainIO{
+		blocks: []*wire.MsgBlock{newTestBlock(1, 0), block},
+	}
+
+	proof, err := NewSpvProof(block, 1, &txid)
+	if err != nil {
+		t.Fatalf("unable to create proof: %v", err)
+	}
+	bundle := &FundingBundle{
+		ChanPoint:     wire.OutPoint{Hash: txid, Index: 0},
+		FundingTx:     fundingTx,
+		Proof:         *proof,
+		AssetID:       "La4szjzKfJyHQ75qgDEnbzp4qY8GQeDR5Z7h2W",
+		AssetCapacity: 1000,
+		Instructions:  lndcc.DecodeTransfer(fundingTx),
+		LocalKey:      localKey,
+		RemoteKey:     remoteKey,
+	}
+	if !reflect.DeepEqual(bundle.Instructions, insts) {
+		t.Fatalf("expected instructions %v, got %v", insts,
+			bundle.Instructions)
+	}
+
+	var b bytes.Buffer
+	if err := bundle.Encode(&b); err != nil {
+		t.Fatalf("unable to encode bundle: %v", err)
+	}
+	decoded := &FundingBundle{}
+	if err := decoded.Decode(&b); err != nil {
+		t.Fatalf("unable to decode bundle: %v", err)
+	}
+	if !reflect.DeepEqual(bundle, decoded) {
+		t.Fatalf("bundle doesn't match after decoding: expected %v, "+
+			"got %v", bundle, decoded)
+	}
+
+	if err := decoded.Verify(chain, 1); err != nil {
+		t.Fatalf("valid bundle rejected: %v", err)
+	}
+
+	// The exported view carries the same serialization.
+	info, err := bundle.Info()
+	if err != nil {
+		t.Fatalf("unable to export bundle: %v", err)
+	}
+	var raw bytes.Buffer
+	bundle.Encode(&raw)
+	if info.Bundle != hex.EncodeToString(raw.Bytes()) {
+		t.Fatalf("exported bundle doesn't match its serialization")
+	}
+	if err := decoded.Verify(chain, 2); err != ErrSpvProofInsufficientConfs {
+		t.Fatalf("expected ErrSpvProofInsufficientConfs, got %v", err)
+	}
+
+	// Each tampered copy of the bundle must be rejected.
+	otherPriv, _ := btcec.NewPrivateKey(btcec.S256())
+	tamperings := []func(b *FundingBundle){
+		func(b *FundingBundle) { b.ChanPoint.Index = 1 },
+		func(b *FundingBundle) { b.ChanPoint.Index = 2 },
+		func(b *FundingBundle) { b.RemoteKey = otherPriv.PubKey() },
+		func(b *FundingBundle) { b.Instructions[0].Amount = 1 },
+		func(b *FundingBundle) { b.Proof.TxIndex = 1 },
+	}
+	for i, tamper := range tamperings {
+		var raw bytes.Buffer
+		if err := bundle.Encode(&raw); err != nil {
+			t.Fatalf("unable to encode bundle: %v", err)
+		}
+		tampered := &FundingBundle{}
+		if err := tampered.Decode(&raw); err != nil {
+			t.Fatalf("unable to decode bundle: %v", err)
+		}
+
+		tamper(tampered)
+		if err := tampered.Verify(chain, 1); err == nil {
+			t.Fatalf("tampering #%v: bundle accepted", i)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package lnwallet
+
+import (
+	"bytes"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// FundingChange describes one of our change outputs within the funding
+// transaction of a channel. The funds it holds are spendable again once the
+// funding transaction confirms.
+type FundingChange struct {
+	// OutPoint is the outpoint of the change output.
+	OutPoint wire.OutPoint
+
+	// Amount is the value of the change output. For colored change it's
+	// denominated in units of the asset, rather than the satoshis of the
+	// output's dust carrier.
+	Amount btcutil.Amount
+
+	// AssetID is the identifier of the asset carried by the change output.
+	// An empty AssetID denotes uncolored fuel change.
+	AssetID string
+}
+
+// FundingChange returns our change outputs within the final funding
+// transaction, along with our fuel change if it was returned to us. Nil is
+// returned if we didn't contribute any inputs, or the funding transaction
+// isn't yet known, as is the case for the responder to a single funder
+// workflow.
+func (r *ChannelReservation) FundingChange() []*FundingChange {
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.fundingTx == nil {
+		return nil
+	}
+
+	txid := r.fundingTx.TxSha()
+	var changes []*FundingChange
+	findChange := func(changeOutput *wire.TxOut, amt btcutil.Amount,
+		assetID string) {
+
+		for i, txOut := range r.fundingTx.TxOut {
+			if !bytes.Equal(txOut.PkScript, changeOutput.PkScript) {
+				continue
+			}
+
+			changes = append(changes, &FundingChange{
+				OutPoint: wire.OutPoint{Hash: txid, Index: uint32(i)},
+				Amount:   amt,
+				AssetID:  assetID,
+			})
+			return
+		}
+	}
+
+	// The values of our colored change outputs were replaced by dust
+	// carrier amounts once the funding transaction was colorified, so
+	// their asset amounts are taken from our contribution instead.
+	for _, changeOutput := range r.ourContribution.ChangeOutputs {
+		findChange(changeOutput, btcutil.Amount(changeOutput.Value),
+			r.partialState.AssetID)
+	}
+	if r.fuelChange != nil {
+		findChange(r.fuelChange, btcutil.Amount(r.fuelChange.Value), "")
+	}
+
+	return changes
+}
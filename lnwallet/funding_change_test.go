@@ -0,0 +1,70 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestFundingChange tests that our colored and fuel change outputs are
+// located within the final funding transaction, with the asset amount of the
+// colored change taken from our contribution.
+func TestFundingChange(t *testing.T) {
+	colorChange := wire.NewTxOut(40, []byte{0x00, 0x14, 0x01})
+	fuelChange := wire.NewTxOut(2000, []byte{0x00, 0x14, 0x02})
+
+	res := &ChannelReservation{
+		ourContribution: &ChannelContribution{
+			ChangeOutputs: []*wire.TxOut{colorChange},
+		},
+		partialState: &channeldb.OpenChannel{AssetID: "asset"},
+		fuelChange:   fuelChange,
+	}
+
+	// Without a funding transaction, there's no change to locate.
+	if changes := res.FundingChange(); changes != nil {
+		t.Fatalf("expected no change, got %v", changes)
+	}
+
+	// Once colorified, the colored change carries a dust amount, and the
+	// fuel change follows the OP_RETURN output.
+	fundingTx := wire.NewMsgTx()
+	fundingTx.AddTxOut(wire.NewTxOut(600, []byte{0x00, 0x20, 0x03}))
+	fundingTx.AddTxOut(wire.NewTxOut(600, colorChange.PkScript))
+	fundingTx.AddTxOut(wire.NewTxOut(0, []byte{0x6a}))
+	fundingTx.AddTxOut(fuelChange)
+	res.fundingTx = fundingTx
+
+	changes := res.FundingChange()
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 change outputs, got %v", len(changes))
+	}
+
+	txid := fundingTx.TxSha()
+	expected := []FundingChange{
+		{
+			OutPoint: wire.OutPoint{Hash: txid, Index: 1},
+			Amount:   40,
+			AssetID:  "asset",
+		},
+		{
+			OutPoint: wire.OutPoint{Hash: txid, Index: 3},
+			Amount:   2000,
+		},
+	}
+	for i, change := range changes {
+		if *change != expected[i] {
+			t.Fatalf("change #%v: expected %+v, got %+v", i,
+				expected[i], *change)
+		}
+	}
+
+	// If our fuel change was instead paid as fee, only the colored change
+	// is located.
+	fundingTx.TxOut = fundingTx.TxOut[:3]
+	changes = res.FundingChange()
+	if len(changes) != 1 || changes[0].AssetID != "asset" {
+		t.Fatalf("expected only colored change, got %v", changes)
+	}
+}
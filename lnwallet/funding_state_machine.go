@@ -0,0 +1,458 @@
+package lnwallet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// ErrUnexpectedFundingStep is returned when a step of the funding
+	// workflow is executed out of order.
+	ErrUnexpectedFundingStep = errors.New("funding step executed out " +
+		"of order")
+
+	// ErrUnknownReservation is returned when attempting to resume the
+	// funding workflow of a reservation which is no longer within the
+	// wallet's funding limbo.
+	ErrUnknownReservation = errors.New("reservation not found within " +
+		"the funding limbo")
+)
+
+// FundingRole denotes which side of a single funder workflow the local node
+// is on.
+type FundingRole uint8
+
+const (
+	// FundingInitiator indicates we initiated the channel, and are
+	// constructing the funding transaction.
+	FundingInitiator FundingRole = iota
+
+	// FundingResponder indicates we're responding to a channel initiated
+	// by the remote node.
+	FundingResponder
+)
+
+// String returns a human readable version of the FundingRole.
+func (r FundingRole) String() string {
+	switch r {
+	case FundingInitiator:
+		return "Initiator"
+	case FundingResponder:
+		return "Responder"
+	default:
+		return "Unknown"
+	}
+}
+
+// FundingStep is an enum like structure describing the next step of the
+// funding workflow expected by a FundingStateMachine.
+type FundingStep uint8
+
+const (
+	// FundingStepContribution indicates the state machine is awaiting
+	// the counterparty's contribution to the channel.
+	FundingStepContribution FundingStep = iota
+
+	// FundingStepSignatures indicates the state machine is awaiting the
+	// counterparty's signatures for the funding transaction, and our
+	// version of the commitment transaction.
+	FundingStepSignatures
+
+	// FundingStepOpen indicates all signatures have been exchanged, and
+	// the state machine is awaiting the opening of the channel.
+	FundingStepOpen
+
+	// FundingStepComplete indicates the channel is open. This is a
+	// terminal step.
+	FundingStepComplete
+
+	// FundingStepFailed indicates the funding workflow failed, or was
+	// cancelled. This is a terminal step.
+	FundingStepFailed
+)
+
+// String returns a human readable version of the FundingStep.
+func (s FundingStep) String() string {
+	switch s {
+	case FundingStepContribution:
+		return "Contribution"
+	case FundingStepSignatures:
+		return "Signatures"
+	case FundingStepOpen:
+		return "Open"
+	case FundingStepComplete:
+		return "Complete"
+	case FundingStepFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// FundingSigs houses the signatures presented by the counterparty during the
+// signature step of the funding workflow. The initiator is presented with
+// the responder's signature for its commitment transaction, along with the
+// signatures for any funding inputs of the responder. The responder is
+// instead presented with the completed funding outpoint, the initiator's
+// revocation key, and the initiator's signature for its commitment
+// transaction.
+type FundingSigs struct {
+	// FundingInputScripts are the counterparty's signatures for their
+	// inputs to the funding transaction, in BIP-69 order. Only used by the
+	// initiator.
+	FundingInputScripts []*InputScript
+
+	// CommitSig is the counterparty's signature for our version of the
+	// commitment transaction.
+	CommitSig []byte
+
+	// RevocationKey is the revocation key to be used within the
+	// initiator's initial commitment transaction. Only used by the
+	// responder.
+	RevocationKey *btcec.PublicKey
+
+	// FundingOutpoint is the outpoint of the funding transaction
+	// constructed by the initiator. Only used by the responder.
+	FundingOutpoint *wire.OutPoint
+
+	// FundingValue is the value in satoshis of the funding output. Only
+	// used by the responder.
+	FundingValue btcutil.Amount
+}
+
+// FundingState is a serializable snapshot of a FundingStateMachine. As the
+// resources of a reservation are held within the wallet's funding limbo, the
+// snapshot merely records the progress of the workflow, allowing it to be
+// resumed by another peer transport via ResumeFundingStateMachine, for as
+// long as the wallet remains running.
+type FundingState struct {
+	// ReservationID is the ID of the reservation backing the workflow.
+	ReservationID uint64
+
+	// NodeID is the ID of the counterparty of the reservation.
+	NodeID [32]byte
+
+	// Role is our side of the workflow.
+	Role FundingRole
+
+	// Step is the next step expected by the workflow.
+	Step FundingStep
+
+	// FundingOutpoint is the outpoint of the funding transaction, or nil
+	// if it's yet to be constructed.
+	FundingOutpoint *wire.OutPoint
+}
+
+// Encode serializes the FundingState to the passed io.Writer.
+func (f *FundingState) Encode(w io.Writer) error {
+	var scratch [8]byte
+	binary.BigEndian.PutUint64(scratch[:], f.ReservationID)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(f.NodeID[:]); err != nil {
+		return err
+	}
+
+	var hasOutpoint byte
+	if f.FundingOutpoint != nil {
+		hasOutpoint = 1
+	}
+	if _, err := w.Write([]byte{byte(f.Role), byte(f.Step),
+		hasOutpoint}); err != nil {
+		return err
+	}
+	if f.FundingOutpoint == nil {
+		return nil
+	}
+
+	if _, err := w.Write(f.FundingOutpoint.Hash[:]); err != nil {
+		return err
+	}
+	binary.BigEndian.PutUint32(scratch[:4], f.FundingOutpoint.Index)
+	_, err := w.Write(scratch[:4])
+	return err
+}
+
+// Decode deserializes a FundingState from the passed io.Reader.
+func (f *FundingState) Decode(r io.Reader) error {
+	var scratch [8]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	f.ReservationID = binary.BigEndian.Uint64(scratch[:])
+
+	if _, err := io.ReadFull(r, f.NodeID[:]); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:3]); err != nil {
+		return err
+	}
+	f.Role = FundingRole(scratch[0])
+	f.Step = FundingStep(scratch[1])
+	if f.Role > FundingResponder || f.Step > FundingStepFailed {
+		return fmt.Errorf("invalid funding state: role %v, step %v",
+			scratch[0], scratch[1])
+	}
+
+	f.FundingOutpoint = nil
+	if scratch[2] == 0 {
+		return nil
+	}
+
+	var outpoint wire.OutPoint
+	if _, err := io.ReadFull(r, outpoint.Hash[:]); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, scratch[:4]); err != nil {
+		return err
+	}
+	outpoint.Index = binary.BigEndian.Uint32(scratch[:4])
+	f.FundingOutpoint = &outpoint
+
+	return nil
+}
+
+// FundingStateMachine drives a single funder channel reservation through the
+// funding workflow via explicit steps, independent of the transport used to
+// exchange the funding messages with the counterparty. Each step dispatches
+// the corresponding request to the wallet, and is only accepted in the order
+// dictated by the workflow:
+//  1. StepContribution, once the counterparty's contribution is received.
+//  2. StepSignatures, once the counterparty's signatures are received.
+//  3. StepOpen, which returns the open channel.
+//
+// A failed step, or a call to Cancel, moves the state machine to the terminal
+// FundingStepFailed step.
+type FundingStateMachine struct {
+	sync.Mutex
+
+	role        FundingRole
+	step        FundingStep
+	reservation *ChannelReservation
+
+	// opening is true while StepOpen is waiting for the channel to open.
+	opening bool
+}
+
+// NewFundingStateMachine creates a new funding state machine for the passed
+// reservation, as obtained via InitChannelReservation, awaiting the
+// counterparty's contribution.
+func NewFundingStateMachine(reservation *ChannelReservation,
+	role FundingRole) *FundingStateMachine {
+
+	return &FundingStateMachine{
+		role:        role,
+		step:        FundingStepContribution,
+		reservation: reservation,
+	}
+}
+
+// ResumeFundingStateMachine recreates the funding state machine recorded
+// within the passed snapshot. The reservation backing the workflow must still
+// be within the wallet's funding limbo.
+func (l *LightningWallet) ResumeFundingStateMachine(
+	state *FundingState) (*FundingStateMachine, error) {
+
+	l.limboMtx.RLock()
+	reservation, ok := l.fundingLimbo[state.ReservationID]
+	l.limboMtx.RUnlock()
+	if !ok {
+		return nil, ErrUnknownReservation
+	}
+
+	reservation.RLock()
+	nodeID := reservation.partialState.TheirLNID
+	reservation.RUnlock()
+	if nodeID != state.NodeID {
+		return nil, fmt.Errorf("reservation %v belongs to node %x, not "+
+			"%x", state.ReservationID, nodeID[:], state.NodeID[:])
+	}
+
+	if reservation.State() == ReservationFailed {
+		return nil, fmt.Errorf("reservation %v has failed",
+			state.ReservationID)
+	}
+
+	return &FundingStateMachine{
+		role:        state.Role,
+		step:        state.Step,
+		reservation: reservation,
+	}, nil
+}
+
+// Reservation returns the reservation backing the state machine.
+func (f *FundingStateMachine) Reservation() *ChannelReservation {
+	return f.reservation
+}
+
+// Step returns the next step expected by the state machine.
+func (f *FundingStateMachine) Step() FundingStep {
+	f.Lock()
+	defer f.Unlock()
+	return f.step
+}
+
+// Snapshot returns a serializable snapshot of the state machine.
+func (f *FundingStateMachine) Snapshot() *FundingState {
+	f.Lock()
+	defer f.Unlock()
+
+	res := f.reservation
+	res.RLock()
+	defer res.RUnlock()
+
+	state := &FundingState{
+		ReservationID: res.reservationID,
+		NodeID:        res.partialState.TheirLNID,
+		Role:          f.role,
+		Step:          f.step,
+	}
+	if res.partialState.FundingOutpoint != nil {
+		outpoint := *res.partialState.FundingOutpoint
+		state.FundingOutpoint = &outpoint
+	}
+
+	return state
+}
+
+// expectStep ensures the state machine is awaiting the passed step. The
+// state machine's mutex MUST be held.
+func (f *FundingStateMachine) expectStep(step FundingStep) error {
+	if f.step != step {
+		return fmt.Errorf("%v: expected step %v, state machine is at "+
+			"step %v", ErrUnexpectedFundingStep, step, f.step)
+	}
+
+	return nil
+}
+
+// advance moves the state machine to the next step if the passed error is
+// nil, otherwise to the failed step. The passed error is returned. The state
+// machine's mutex MUST be held.
+func (f *FundingStateMachine) advance(next FundingStep, err error) error {
+	if err != nil {
+		f.step = FundingStepFailed
+		return err
+	}
+
+	f.step = next
+	return nil
+}
+
+// StepContribution processes the counterparty's contribution to the channel.
+// For the initiator, our signatures for the funding transaction, and the
+// responder's commitment transaction, are available via the reservation's
+// OurSignatures method once this step completes.
+func (f *FundingStateMachine) StepContribution(theirs *ChannelContribution) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.expectStep(FundingStepContribution); err != nil {
+		return err
+	}
+
+	var err error
+	switch f.role {
+	case FundingInitiator:
+		err = f.reservation.ProcessContribution(theirs)
+	case FundingResponder:
+		err = f.reservation.ProcessSingleContribution(theirs)
+	}
+
+	return f.advance(FundingStepSignatures, err)
+}
+
+// StepSignatures verifies the counterparty's signatures. For the initiator,
+// the funding transaction is broadcast once this step completes. For the
+// responder, our signature for the initiator's commitment transaction is
+// available via the reservation's OurSignatures method once this step
+// completes.
+func (f *FundingStateMachine) StepSignatures(sigs *FundingSigs) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.expectStep(FundingStepSignatures); err != nil {
+		return err
+	}
+
+	var err error
+	switch f.role {
+	case FundingInitiator:
+		err = f.reservation.CompleteReservation(
+			sigs.FundingInputScripts, sigs.CommitSig)
+	case FundingResponder:
+		err = f.reservation.CompleteReservationSingle(sigs.RevocationKey,
+			sigs.FundingOutpoint, sigs.FundingValue, sigs.CommitSig)
+	}
+
+	return f.advance(FundingStepOpen, err)
+}
+
+// StepOpen completes the funding workflow, returning the open channel. For
+// the initiator, this blocks until the funding transaction reaches the
+// required number of confirmations. For the responder, this should only be
+// called once the initiator deems the channel open.
+func (f *FundingStateMachine) StepOpen() (*LightningChannel, error) {
+	f.Lock()
+	if err := f.expectStep(FundingStepOpen); err != nil {
+		f.Unlock()
+		return nil, err
+	}
+	if f.opening {
+		f.Unlock()
+		return nil, fmt.Errorf("%v: channel is already being opened",
+			ErrUnexpectedFundingStep)
+	}
+	f.opening = true
+	f.Unlock()
+
+	// The mutex isn't held while waiting for the channel to open, as
+	// the funding transaction may take some time to confirm.
+	var (
+		channel *LightningChannel
+		err     error
+	)
+	switch f.role {
+	case FundingInitiator:
+		// A nil channel is dispatched if the funding transaction was
+		// replaced via AbortFunding.
+		channel = <-f.reservation.DispatchChan()
+		if channel == nil {
+			err = fmt.Errorf("funding of reservation %v was aborted",
+				f.reservation.reservationID)
+		}
+	case FundingResponder:
+		channel, err = f.reservation.FinalizeReservation()
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	f.opening = false
+	return channel, f.advance(FundingStepComplete, err)
+}
+
+// Cancel abandons the funding workflow, releasing the resources held by the
+// reservation.
+func (f *FundingStateMachine) Cancel() error {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.step == FundingStepComplete {
+		return fmt.Errorf("%v: channel is already open",
+			ErrUnexpectedFundingStep)
+	}
+
+	f.step = FundingStepFailed
+	return f.reservation.Cancel()
+}
@@ -0,0 +1,122 @@
+package lnwallet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestFundingStateEncoding tests that a FundingState survives a round trip
+// through its serialization, both with and without a funding outpoint.
+func TestFundingStateEncoding(t *testing.T) {
+	states := []*FundingState{
+		{
+			ReservationID: 7,
+			NodeID:        [32]byte{0x01, 0x02},
+			Role:          FundingResponder,
+			Step:          FundingStepContribution,
+		},
+		{
+			ReservationID: 1 << 40,
+			NodeID:        [32]byte{0xff},
+			Role:          FundingInitiator,
+			Step:          FundingStepOpen,
+			FundingOutpoint: &wire.OutPoint{
+				Hash:  wire.ShaHash{0xaa, 0xbb},
+				Index: 3,
+			},
+		},
+	}
+	for _, state := range states {
+		var b bytes.Buffer
+		if err := state.Encode(&b); err != nil {
+			t.Fatalf("unable to encode state: %v", err)
+		}
+
+		decoded := &FundingState{}
+		if err := decoded.Decode(&b); err != nil {
+			t.Fatalf("unable to decode state: %v", err)
+		}
+		if !reflect.DeepEqual(state, decoded) {
+			t.Fatalf("state mismatch: expected %+v, got %+v", state,
+				decoded)
+		}
+	}
+
+	// An unknown step should be rejected.
+	var b bytes.Buffer
+	if err := (&FundingState{Step: FundingStepFailed + 1}).Encode(&b); err != nil {
+		t.Fatalf("unable to encode state: %v", err)
+	}
+	if err := (&FundingState{}).Decode(&b); err == nil {
+		t.Fatalf("invalid step accepted")
+	}
+}
+
+// TestFundingStateMachineResume tests that a funding state machine can be
+// resumed from a snapshot while its reservation remains within the funding
+// limbo, and that steps executed out of order are rejected without
+// contacting the wallet.
+func TestFundingStateMachineResume(t *testing.T) {
+	nodeID := [32]byte{0x05}
+	res := &ChannelReservation{
+		reservationID: 3,
+		partialState: &channeldb.OpenChannel{
+			TheirLNID: nodeID,
+		},
+		stateMachine: reservationStateMachine{
+			state:   ReservationContributed,
+			clients: make(map[uint64]chan ReservationState),
+		},
+	}
+	wallet := &LightningWallet{
+		fundingLimbo: map[uint64]*ChannelReservation{
+			res.reservationID: res,
+		},
+	}
+
+	fsm := NewFundingStateMachine(res, FundingResponder)
+	fsm.step = FundingStepSignatures
+	snapshot := fsm.Snapshot()
+
+	resumed, err := wallet.ResumeFundingStateMachine(snapshot)
+	if err != nil {
+		t.Fatalf("unable to resume state machine: %v", err)
+	}
+	if resumed.Reservation() != res {
+		t.Fatalf("resumed state machine has wrong reservation")
+	}
+	if resumed.Step() != FundingStepSignatures {
+		t.Fatalf("expected step %v, got %v", FundingStepSignatures,
+			resumed.Step())
+	}
+
+	// The contribution has already been processed, so attempting to
+	// process it again should fail, without failing the workflow.
+	if err := resumed.StepContribution(&ChannelContribution{}); err == nil {
+		t.Fatalf("out of order step accepted")
+	}
+	if _, err := resumed.StepOpen(); err == nil {
+		t.Fatalf("out of order step accepted")
+	}
+	if resumed.Step() != FundingStepSignatures {
+		t.Fatalf("out of order step altered the state machine")
+	}
+
+	// A snapshot for another node, or an unknown reservation, can't be
+	// resumed.
+	otherNode := *snapshot
+	otherNode.NodeID = [32]byte{0x06}
+	if _, err := wallet.ResumeFundingStateMachine(&otherNode); err == nil {
+		t.Fatalf("snapshot for another node resumed")
+	}
+	unknown := *snapshot
+	unknown.ReservationID++
+	_, err = wallet.ResumeFundingStateMachine(&unknown)
+	if err != ErrUnknownReservation {
+		t.Fatalf("expected ErrUnknownReservation, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package lnwallet
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcutil"
+)
+
+// htlcModel is the expected state of a single HTLC within
+// TestHTLCViewBalanceConservation.
+type htlcModel struct {
+	incoming bool
+	index    uint64
+	amt      btcutil.Amount
+	preimage [32]byte
+
+	// addedLocal and addedRemote are set once the addition of the HTLC
+	// has been included within a commitment of the respective chain. The
+	// HTLC may only be removed once it's been added to both chains.
+	addedLocal  bool
+	addedRemote bool
+
+	// removeHeightLocal and removeHeightRemote are the heights of the
+	// first commitments of each chain which include the removal of the
+	// HTLC, or zero if it hasn't yet been included.
+	removed            bool
+	removeHeightLocal  uint64
+	removeHeightRemote uint64
+}
+
+// chainModel is the expected state of a single commitment chain within
+// TestHTLCViewBalanceConservation.
+type chainModel struct {
+	height       uint64
+	tail         uint64
+	ourBalance   btcutil.Amount
+	theirBalance btcutil.Amount
+}
+
+// TestHTLCViewBalanceConservation tests the evaluation of the HTLC update logs
+// against random sequences of adds, settles and timeouts initiated by either
+// side, interleaved with new commitments on both chains and log compactions.
+// At every commitment height, the balances of both sides along with the value
+// of all HTLCs in flight must add up to the capacity of the channel, and a
+// log compaction must never remove an HTLC which hasn't been removed within
+// both chains.
+func TestHTLCViewBalanceConservation(t *testing.T) {
+	const (
+		numSeeds = 20
+		numSteps = 300
+	)
+
+	for seed := int64(0); seed < numSeeds; seed++ {
+		testHTLCViewBalanceConservation(t, seed, numSteps)
+	}
+}
+
+func testHTLCViewBalanceConservation(t *testing.T, seed int64, numSteps int) {
+	aliceChannel, _, cleanUp, err := createTestChannels(1)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	lc := aliceChannel
+	capacity := lc.Capacity
+	local := &chainModel{
+		ourBalance:   lc.channelState.OurBalance,
+		theirBalance: lc.channelState.TheirBalance,
+	}
+	remote := &chainModel{
+		ourBalance:   lc.channelState.OurBalance,
+		theirBalance: lc.channelState.TheirBalance,
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	var htlcs []*htlcModel
+	var numPreimages uint64
+
+	addHTLC := func(incoming bool) {
+		h := &htlcModel{
+			incoming: incoming,
+			amt:      btcutil.Amount(rng.Int63n(100000) + 1),
+		}
+		binary.BigEndian.PutUint64(h.preimage[:8], uint64(seed))
+		binary.BigEndian.PutUint64(h.preimage[8:16], numPreimages)
+		numPreimages++
+
+		req := &lnwire.HTLCAddRequest{
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(h.preimage[:])},
+			Amount:           lnwire.CreditsAmount(h.amt),
+			Expiry:           10,
+		}
+
+		var err error
+		if incoming {
+			req.ID = lc.theirLogCounter
+			h.index, err = lc.ReceiveHTLC(req)
+		} else {
+			h.index, err = lc.AddHTLC(req)
+		}
+		if err == ErrMaxHTLCsExceeded {
+			return
+		} else if err != nil {
+			t.Fatalf("seed %v: unable to add htlc: %v", seed, err)
+		}
+
+		htlcs = append(htlcs, h)
+	}
+
+	// Only HTLCs locked into both chains may be removed.
+	removeHTLC := func() {
+		var candidates []*htlcModel
+		for _, h := range htlcs {
+			if h.addedLocal && h.addedRemote && !h.removed {
+				candidates = append(candidates, h)
+			}
+		}
+		if len(candidates) == 0 {
+			return
+		}
+		h := candidates[rng.Intn(len(candidates))]
+		settle := rng.Intn(2) == 0
+
+		var err error
+		switch {
+		case h.incoming && settle:
+			_, err = lc.SettleHTLC(h.preimage)
+		case h.incoming:
+			err = lc.TimeoutHTLC(h.index)
+		case settle:
+			err = lc.ReceiveHTLCSettle(h.preimage, h.index)
+		default:
+			err = lc.ReceiveHTLCTimeout(h.index)
+		}
+		if err != nil {
+			t.Fatalf("seed %v: unable to remove htlc: %v", seed, err)
+		}
+
+		h.removed = true
+	}
+
+	commit := func(chain *chainModel, remoteChain bool) {
+		chain.height++
+		ourBalance, theirBalance := chain.ourBalance, chain.theirBalance
+
+		view := lc.fetchHTLCView(lc.theirLogCounter, lc.ourLogCounter)
+		filtered := lc.evaluateHTLCView(view, &ourBalance, &theirBalance,
+			chain.height, remoteChain)
+
+		var inFlight btcutil.Amount
+		active := make(map[htlcKey]struct{})
+		for _, pd := range filtered.ourUpdates {
+			inFlight += pd.Amount
+			active[htlcKey{false, pd.Index}] = struct{}{}
+		}
+		for _, pd := range filtered.theirUpdates {
+			inFlight += pd.Amount
+			active[htlcKey{true, pd.Index}] = struct{}{}
+		}
+
+		if ourBalance < 0 || theirBalance < 0 {
+			t.Fatalf("seed %v, height %v: negative balance: "+
+				"ours=%v, theirs=%v", seed, chain.height,
+				ourBalance, theirBalance)
+		}
+		if ourBalance+theirBalance+inFlight != capacity {
+			t.Fatalf("seed %v, height %v: value not conserved: "+
+				"ours=%v + theirs=%v + in flight=%v != %v", seed,
+				chain.height, ourBalance, theirBalance, inFlight,
+				capacity)
+		}
+		chain.ourBalance, chain.theirBalance = ourBalance, theirBalance
+
+		// Every update so far is included within the new commitment,
+		// so it must hold exactly those HTLCs not yet removed.
+		for _, h := range htlcs {
+			_, ok := active[htlcKey{h.incoming, h.index}]
+			if ok == h.removed {
+				t.Fatalf("seed %v, height %v: htlc %+v active=%v",
+					seed, chain.height, h, ok)
+			}
+
+			if remoteChain {
+				h.addedRemote = true
+				if h.removed && h.removeHeightRemote == 0 {
+					h.removeHeightRemote = chain.height
+				}
+			} else {
+				h.addedLocal = true
+				if h.removed && h.removeHeightLocal == 0 {
+					h.removeHeightLocal = chain.height
+				}
+			}
+		}
+		if len(active) != len(filtered.ourUpdates)+len(filtered.theirUpdates) {
+			t.Fatalf("seed %v, height %v: duplicate htlcs within "+
+				"view", seed, chain.height)
+		}
+	}
+
+	// Compaction takes place once the remote party revokes, which may lag
+	// behind the tip of either chain.
+	compact := func() {
+		local.tail += uint64(rng.Int63n(int64(local.height-local.tail) + 1))
+		remote.tail += uint64(rng.Int63n(int64(remote.height-remote.tail) + 1))
+		lc.compactLogs(lc.ourUpdateLog, lc.theirUpdateLog, local.tail,
+			remote.tail)
+
+		var remaining []*htlcModel
+		for _, h := range htlcs {
+			logIndex := lc.ourLogIndex
+			if h.incoming {
+				logIndex = lc.theirLogIndex
+			}
+			_, inLog := logIndex[h.index]
+
+			resolved := h.removeHeightLocal != 0 &&
+				h.removeHeightRemote != 0 &&
+				h.removeHeightLocal <= local.tail &&
+				h.removeHeightRemote <= remote.tail
+			if inLog == resolved {
+				t.Fatalf("seed %v: htlc %+v in log=%v after "+
+					"compacting to local=%v, remote=%v", seed,
+					h, inLog, local.tail, remote.tail)
+			}
+
+			if !resolved {
+				remaining = append(remaining, h)
+			}
+		}
+		htlcs = remaining
+
+		var numActive int
+		for _, h := range htlcs {
+			if !h.removed {
+				numActive++
+			}
+		}
+		if lc.numActiveHTLCs() != numActive {
+			t.Fatalf("seed %v: expected %v active htlcs, log has %v",
+				seed, numActive, lc.numActiveHTLCs())
+		}
+	}
+
+	for i := 0; i < numSteps; i++ {
+		switch rng.Intn(6) {
+		case 0:
+			addHTLC(false)
+		case 1:
+			addHTLC(true)
+		case 2:
+			removeHTLC()
+		case 3:
+			commit(local, false)
+		case 4:
+			commit(remote, true)
+		case 5:
+			compact()
+		}
+	}
+}
+
+// htlcKey identifies an HTLC by the log it was added to, and its index within
+// that log.
+type htlcKey struct {
+	incoming bool
+	index    uint64
+}
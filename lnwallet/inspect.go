@@ -0,0 +1,478 @@
+package lnwallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// ReservationInfo is a read-only view of a channel reservation held within
+// the wallet's funding limbo.
+type ReservationInfo struct {
+	// ID is the wallet's identifier for the reservation.
+	ID uint64
+
+	// State is the reservation's current state within the funding
+	// workflow.
+	State ReservationState
+
+	// Age is the time elapsed since the reservation was created.
+	Age time.Duration
+
+	// AssetID is the asset the channel under construction is denominated
+	// in.
+	AssetID string
+
+	// Capacity is the total capacity of the channel under construction.
+	Capacity btcutil.Amount
+
+	// OurFundingAmt and TheirFundingAmt are the amounts contributed by
+	// each side, the latter being zero until their contribution has been
+	// processed.
+	OurFundingAmt   btcutil.Amount
+	TheirFundingAmt btcutil.Amount
+
+	// Inputs are the outpoints we've locked to fund the channel.
+	Inputs []wire.OutPoint
+
+	// FundingOutpoint is the funding output of the channel, or nil if the
+	// funding transaction hasn't yet been assembled.
+	FundingOutpoint *wire.OutPoint
+}
+
+// SweepInfo is a read-only view of a time-locked output awaiting its sweep
+// back into the wallet.
+type SweepInfo struct {
+	// OutPoint is the output to be swept.
+	OutPoint wire.OutPoint
+
+	// Amount is the value of the output.
+	Amount btcutil.Amount
+
+	// MaturityHeight is the height at which the output may be swept, or
+	// zero if the transaction creating it hasn't yet confirmed.
+	MaturityHeight uint32
+}
+
+// ChannelLogStats summarizes the in-memory state of a channel's HTLC update
+// logs and commitment chains.
+type ChannelLogStats struct {
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	// AssetID is the asset the channel is denominated in.
+	AssetID string
+
+	// OurLogLen and TheirLogLen are the number of entries within each
+	// update log, while OurLogCounter and TheirLogCounter are the indexes
+	// to be assigned to the next entry of each log.
+	OurLogLen       int
+	TheirLogLen     int
+	OurLogCounter   uint64
+	TheirLogCounter uint64
+
+	// NumActiveHTLCs is the number of HTLCs added, yet not removed,
+	// within either log.
+	NumActiveHTLCs int
+
+	// LocalCommitHeight and RemoteCommitHeight are the heights of the tip
+	// of each commitment chain, while LocalChainLen and RemoteChainLen
+	// are the number of unrevoked commitments within each chain.
+	LocalCommitHeight  uint64
+	RemoteCommitHeight uint64
+	LocalChainLen      int
+	RemoteChainLen     int
+
+	// RevocationWindowEdge is the edge of the revocation window we've
+	// extended to the remote party.
+	RevocationWindowEdge uint64
+}
+
+// LogStats returns a summary of the channel's update logs and commitment
+// chains.
+func (lc *LightningChannel) LogStats() *ChannelLogStats {
+	lc.RLock()
+	defer lc.RUnlock()
+
+	return &ChannelLogStats{
+		ChanPoint:            *lc.channelState.ChanID,
+		AssetID:              lc.channelState.AssetID,
+		OurLogLen:            lc.ourUpdateLog.Len(),
+		TheirLogLen:          lc.theirUpdateLog.Len(),
+		OurLogCounter:        lc.ourLogCounter,
+		TheirLogCounter:      lc.theirLogCounter,
+		NumActiveHTLCs:       lc.numActiveHTLCs(),
+		LocalCommitHeight:    lc.localCommitChain.tip().height,
+		RemoteCommitHeight:   lc.remoteCommitChain.tip().height,
+		LocalChainLen:        lc.localCommitChain.commitments.Len(),
+		RemoteChainLen:       lc.remoteCommitChain.commitments.Len(),
+		RevocationWindowEdge: lc.revocationWindowEdge,
+	}
+}
+
+// ChannelLogEntry is an entry within the HTLC update logs of one of the active
+// channels.
+type ChannelLogEntry struct {
+	// ChanPoint is the funding outpoint of the channel.
+	ChanPoint wire.OutPoint
+
+	*LogEntry
+}
+
+// inspectWalletMsg is sent to the wallet's request handler in order to
+// obtain a consistent view of its volatile funding state.
+type inspectWalletMsg struct {
+	resp chan *walletInspection
+}
+
+// walletInspection is a snapshot of the wallet's volatile funding state.
+type walletInspection struct {
+	reservations []*ReservationInfo
+	locked       []wire.OutPoint
+}
+
+// handleInspect snapshots the reservations within the funding limbo, and the
+// locked outpoints. As both are only modified by the request handler, the
+// snapshot is consistent.
+func (l *LightningWallet) handleInspect(req *inspectWalletMsg) {
+	l.limboMtx.RLock()
+	reservations := make([]*ReservationInfo, 0, len(l.fundingLimbo))
+	for _, res := range l.fundingLimbo {
+		reservations = append(reservations, res.info())
+	}
+	l.limboMtx.RUnlock()
+
+	locked := make([]wire.OutPoint, 0, len(l.lockedOutPoints))
+	for outPoint := range l.lockedOutPoints {
+		locked = append(locked, outPoint)
+	}
+
+	req.resp <- &walletInspection{
+		reservations: reservations,
+		locked:       locked,
+	}
+}
+
+// inspect obtains a snapshot of the wallet's volatile funding state from the
+// request handler.
+func (l *LightningWallet) inspect() (*walletInspection, error) {
+	req := &inspectWalletMsg{
+		resp: make(chan *walletInspection, 1),
+	}
+
+	select {
+	case l.queues.inspect <- req:
+	case <-l.quit:
+		return nil, fmt.Errorf("wallet shutting down")
+	}
+
+	select {
+	case resp := <-req.resp:
+		return resp, nil
+	case <-l.quit:
+		return nil, fmt.Errorf("wallet shutting down")
+	}
+}
+
+// info returns a read-only view of the reservation.
+func (r *ChannelReservation) info() *ReservationInfo {
+	sm := &r.stateMachine
+	sm.Lock()
+	state, started := sm.state, sm.started
+	sm.Unlock()
+
+	r.RLock()
+	defer r.RUnlock()
+
+	info := &ReservationInfo{
+		ID:       r.reservationID,
+		State:    state,
+		Age:      time.Since(started),
+		AssetID:  r.partialState.AssetID,
+		Capacity: r.partialState.AssetCapacity,
+	}
+	if r.ourContribution != nil {
+		info.OurFundingAmt = r.ourContribution.FundingAmount
+		for _, txIn := range r.ourContribution.Inputs {
+			info.Inputs = append(info.Inputs, txIn.PreviousOutPoint)
+		}
+	}
+	if r.theirContribution != nil {
+		info.TheirFundingAmt = r.theirContribution.FundingAmount
+	}
+	if r.partialState.FundingOutpoint != nil {
+		fundingOutpoint := *r.partialState.FundingOutpoint
+		info.FundingOutpoint = &fundingOutpoint
+	}
+
+	return info
+}
+
+// InspectorConfig houses the sources of the state exposed by an Inspector.
+type InspectorConfig struct {
+	// Wallet is the wallet the funding state of which is inspected.
+	Wallet *LightningWallet
+
+	// ActiveChannels returns all currently active channels. If nil, then
+	// no channel log statistics are reported.
+	ActiveChannels func() []*LightningChannel
+
+	// PendingSweeps returns all time-locked outputs awaiting their sweep
+	// into the wallet. If nil, then no sweeps are reported.
+	PendingSweeps func() []*SweepInfo
+}
+
+// Inspector is a read-only query service over the volatile, in-memory state
+// of the wallet and its channels, allowing operators to inspect it at
+// runtime. No method of the Inspector modifies any state.
+type Inspector struct {
+	cfg *InspectorConfig
+}
+
+// NewInspector creates a new Inspector over the passed sources.
+func NewInspector(cfg *InspectorConfig) *Inspector {
+	return &Inspector{cfg: cfg}
+}
+
+// ListReservations returns all reservations currently within the wallet's
+// funding limbo, ordered by their ID.
+func (i *Inspector) ListReservations() ([]*ReservationInfo, error) {
+	snapshot, err := i.cfg.Wallet.inspect()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(reservationsByID(snapshot.reservations))
+
+	return snapshot.reservations, nil
+}
+
+// ListLockedOutpoints returns all outpoints currently locked by reservations
+// within the wallet's funding limbo, along with those left locked by
+// reservations lost to a restart.
+func (i *Inspector) ListLockedOutpoints() ([]wire.OutPoint, error) {
+	snapshot, err := i.cfg.Wallet.inspect()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Sort(outPointSlice(snapshot.locked))
+
+	return snapshot.locked, nil
+}
+
+// PendingSweeps returns all time-locked outputs awaiting their sweep into the
+// wallet.
+func (i *Inspector) PendingSweeps() []*SweepInfo {
+	if i.cfg.PendingSweeps == nil {
+		return nil
+	}
+
+	sweeps := i.cfg.PendingSweeps()
+	sort.Sort(sweepsByOutPoint(sweeps))
+
+	return sweeps
+}
+
+// FuelBalance returns the satoshis available to fuel colored transactions,
+// and whether they've fallen below the low fuel threshold.
+func (i *Inspector) FuelBalance() (*FuelStatus, error) {
+	return i.cfg.Wallet.FuelBalance()
+}
+
+// FundingBundles returns the proof bundles of the funding transactions of our
+// channels, generated as each opened, ordered by their funding outpoint.
+func (i *Inspector) FundingBundles() ([]*FundingBundleInfo, error) {
+	rawBundles, err := i.cfg.Wallet.ChannelDB.FetchAllFundingBundles()
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*FundingBundleInfo, 0, len(rawBundles))
+	for _, rawBundle := range rawBundles {
+		bundle := &FundingBundle{}
+		if err := bundle.Decode(bytes.NewReader(rawBundle)); err != nil {
+			return nil, fmt.Errorf("unable to decode funding "+
+				"bundle: %v", err)
+		}
+
+		info, err := bundle.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// ChannelLogStats returns a summary of the update logs and commitment chains
+// of each active channel.
+func (i *Inspector) ChannelLogStats() []*ChannelLogStats {
+	if i.cfg.ActiveChannels == nil {
+		return nil
+	}
+
+	channels := i.cfg.ActiveChannels()
+	stats := make([]*ChannelLogStats, 0, len(channels))
+	for _, channel := range channels {
+		stats = append(stats, channel.LogStats())
+	}
+	sort.Sort(statsByChanPoint(stats))
+
+	return stats
+}
+
+// serveChannelLogs streams the entries of the HTLC update logs of each active
+// channel as a sequence of JSON objects. The channels may be restricted to
+// those of a single asset with the "asset" query parameter, and the entries
+// to those with a log index of at least the "since" query parameter. Each
+// channel's logs are iterated, rather than copied, so the channel's lock
+// isn't held while the response is written.
+func (i *Inspector) serveChannelLogs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	assetID := query.Get("asset")
+
+	var sinceIndex uint64
+	if since := query.Get("since"); since != "" {
+		var err error
+		sinceIndex, err = strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid since index: %v", err),
+				http.StatusBadRequest)
+			return
+		}
+	}
+
+	var channels []*LightningChannel
+	if i.cfg.ActiveChannels != nil {
+		channels = i.cfg.ActiveChannels()
+	}
+	sort.Sort(channelsByChanPoint(channels))
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	for _, channel := range channels {
+		chanPoint := *channel.ChannelPoint()
+
+		it := channel.DumpLog(assetID, sinceIndex)
+		for entry, ok := it.Next(); ok; entry, ok = it.Next() {
+			err := enc.Encode(&ChannelLogEntry{chanPoint, entry})
+			if err != nil {
+				// The client has gone away.
+				return
+			}
+		}
+	}
+}
+
+// ServeHTTP serves the state exposed by the Inspector as JSON, with each
+// query available at the path of the same name relative to the handler's
+// mount point: reservations, lockedoutpoints, sweeps, fuel, channels,
+// fundingbundles, and logs. Only GET requests are accepted.
+func (i *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		resp interface{}
+		err  error
+	)
+	path := r.URL.Path
+	switch path[strings.LastIndex(path, "/")+1:] {
+	case "reservations":
+		resp, err = i.ListReservations()
+	case "lockedoutpoints":
+		resp, err = i.ListLockedOutpoints()
+	case "sweeps":
+		resp = i.PendingSweeps()
+	case "fuel":
+		resp, err = i.FuelBalance()
+	case "channels":
+		resp = i.ChannelLogStats()
+	case "fundingbundles":
+		resp, err = i.FundingBundles()
+	case "logs":
+		i.serveChannelLogs(w, r)
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	b, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// reservationsByID sorts reservations by their ID.
+type reservationsByID []*ReservationInfo
+
+func (r reservationsByID) Len() int           { return len(r) }
+func (r reservationsByID) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r reservationsByID) Less(i, j int) bool { return r[i].ID < r[j].ID }
+
+// outPointSlice sorts outpoints by their txid, then their index.
+type outPointSlice []wire.OutPoint
+
+func (o outPointSlice) Len() int      { return len(o) }
+func (o outPointSlice) Swap(i, j int) { o[i], o[j] = o[j], o[i] }
+func (o outPointSlice) Less(i, j int) bool {
+	return outPointLess(&o[i], &o[j])
+}
+
+// sweepsByOutPoint sorts sweeps by the outpoint being swept.
+type sweepsByOutPoint []*SweepInfo
+
+func (s sweepsByOutPoint) Len() int      { return len(s) }
+func (s sweepsByOutPoint) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s sweepsByOutPoint) Less(i, j int) bool {
+	return outPointLess(&s[i].OutPoint, &s[j].OutPoint)
+}
+
+// statsByChanPoint sorts channel log statistics by the channel's funding
+// outpoint.
+type statsByChanPoint []*ChannelLogStats
+
+func (s statsByChanPoint) Len() int      { return len(s) }
+func (s statsByChanPoint) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s statsByChanPoint) Less(i, j int) bool {
+	return outPointLess(&s[i].ChanPoint, &s[j].ChanPoint)
+}
+
+// channelsByChanPoint sorts channels by their funding outpoint.
+type channelsByChanPoint []*LightningChannel
+
+func (c channelsByChanPoint) Len() int      { return len(c) }
+func (c channelsByChanPoint) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c channelsByChanPoint) Less(i, j int) bool {
+	return outPointLess(c[i].ChannelPoint(), c[j].ChannelPoint())
+}
+
+// outPointLess orders outpoints by their txid, then their index.
+func outPointLess(a, b *wire.OutPoint) bool {
+	if c := bytes.Compare(a.Hash[:], b.Hash[:]); c != 0 {
+		return c < 0
+	}
+
+	return a.Index < b.Index
+}
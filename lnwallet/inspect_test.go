@@ -0,0 +1,139 @@
+package lnwallet
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestInspector tests that the channel log statistics and pending sweeps
+// reported by an Inspector reflect the state of their sources, and are
+// served over http.
+func TestInspector(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	htlc := &lnwire.HTLCAddRequest{
+		RedemptionHashes: [][32]byte{fastsha256.Sum256([]byte{1})},
+		Amount:           lnwire.CreditsAmount(1e6),
+		Expiry:           uint32(5),
+	}
+	if _, err := aliceChannel.AddHTLC(htlc); err != nil {
+		t.Fatalf("unable to add htlc: %v", err)
+	}
+	if _, err := bobChannel.ReceiveHTLC(htlc); err != nil {
+		t.Fatalf("unable to receive htlc: %v", err)
+	}
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state transition: %v", err)
+	}
+
+	sweeps := []*SweepInfo{
+		{OutPoint: wire.OutPoint{Hash: wire.ShaHash{2}}, Amount: 600},
+		{OutPoint: wire.OutPoint{Hash: wire.ShaHash{1}}, Amount: 700,
+			MaturityHeight: 144},
+	}
+	inspector := NewInspector(&InspectorConfig{
+		ActiveChannels: func() []*LightningChannel {
+			return []*LightningChannel{aliceChannel}
+		},
+		PendingSweeps: func() []*SweepInfo {
+			return sweeps
+		},
+	})
+
+	stats := inspector.ChannelLogStats()
+	if len(stats) != 1 {
+		t.Fatalf("expected stats of 1 channel, got %v", len(stats))
+	}
+	if stats[0].ChanPoint != *aliceChannel.ChannelPoint() ||
+		stats[0].OurLogLen != 1 || stats[0].OurLogCounter != 1 ||
+		stats[0].TheirLogLen != 0 || stats[0].NumActiveHTLCs != 1 ||
+		stats[0].LocalCommitHeight != 1 ||
+		stats[0].RemoteCommitHeight != 1 {
+
+		t.Fatalf("unexpected channel log stats: %+v", stats[0])
+	}
+
+	// The sweeps should be ordered by their outpoint.
+	pending := inspector.PendingSweeps()
+	if len(pending) != 2 || pending[0].Amount != 700 ||
+		pending[1].Amount != 600 {
+
+		t.Fatalf("unexpected pending sweeps: %v", pending)
+	}
+
+	server := httptest.NewServer(inspector)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/debug/lnwallet/channels")
+	if err != nil {
+		t.Fatalf("unable to query channels: %v", err)
+	}
+	var served []*ChannelLogStats
+	err = json.NewDecoder(resp.Body).Decode(&served)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("unable to decode channels: %v", err)
+	}
+	if len(served) != 1 || *served[0] != *stats[0] {
+		t.Fatalf("expected %+v to be served, got %+v", stats[0], served)
+	}
+
+	// The logs should be streamed entry by entry, restricted to those
+	// with a log index of at least the since index.
+	resp, err = http.Get(server.URL + "/debug/lnwallet/logs?since=0")
+	if err != nil {
+		t.Fatalf("unable to query logs: %v", err)
+	}
+	var entry ChannelLogEntry
+	err = json.NewDecoder(resp.Body).Decode(&entry)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("unable to decode log entry: %v", err)
+	}
+	if entry.ChanPoint != *aliceChannel.ChannelPoint() ||
+		entry.LogEntry == nil || entry.Incoming || entry.Index != 0 {
+
+		t.Fatalf("unexpected log entry: %+v", entry)
+	}
+
+	resp, err = http.Get(server.URL + "/debug/lnwallet/logs?since=1")
+	if err != nil {
+		t.Fatalf("unable to query logs: %v", err)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&entry)
+	resp.Body.Close()
+	if err != io.EOF {
+		t.Fatalf("expected no log entries, got %v", err)
+	}
+
+	resp, err = http.Get(server.URL + "/debug/lnwallet/unknown")
+	if err != nil {
+		t.Fatalf("unable to query: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status %v, got %v", http.StatusNotFound,
+			resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+"/debug/lnwallet/sweeps", "", nil)
+	if err != nil {
+		t.Fatalf("unable to query: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("expected status %v, got %v",
+			http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
@@ -16,6 +16,21 @@ import (
 // to spend a specifid output.
 var ErrNotMine = errors.New("the passed output doesn't belong to the wallet")
 
+// ErrOutputSpent is returned by a BlockChainIO instance when the requested
+// output has already been spent.
+var ErrOutputSpent = errors.New("target output has been spent")
+
+// ErrOutputNotFound is returned by a BlockChainIO instance when the
+// transaction creating the requested output isn't known, as it never
+// existed, or was re-orged out of the main chain and dropped from the
+// mempool.
+var ErrOutputNotFound = errors.New("target output not found")
+
+// ErrOutputUnconfirmed is returned by a BlockChainIO instance when the
+// requested output exists, but the transaction creating it has yet to
+// confirm, as is the case for the funding output of a zero-conf channel.
+var ErrOutputUnconfirmed = errors.New("target output is unconfirmed")
+
 // AddressType is a enum-like type which denotes the possible address types
 // WalletController supports.
 type AddressType uint8
@@ -71,6 +86,14 @@ type WalletController interface {
 	// p2wkh, p2wsh, etc.
 	NewAddress(addrType AddressType, change bool) (btcutil.Address, error)
 
+	// NewChannelAddress returns the next external or internal address
+	// for the wallet just as NewAddress, but within the account holding
+	// the funds of channels, such as the change of funding transactions,
+	// delivery addresses, and sweeps. Wallets without a dedicated account
+	// may return an address of their general account.
+	NewChannelAddress(addrType AddressType,
+		change bool) (btcutil.Address, error)
+
 	// GetPrivKey retrives the underlying private key associated with the
 	// passed address. If the wallet is unable to locate this private key
 	// due to the address not being under control of the wallet, then an
@@ -106,17 +129,31 @@ type WalletController interface {
 	// LockOutpoint marks an outpoint as locked meaning it will no longer
 	// be deemed as eligible for coin selection. Locking outputs are
 	// utilized in order to avoid race conditions when selecting inputs for
-	// usage when funding a channel.
-	LockOutpoint(o wire.OutPoint)
+	// usage when funding a channel. Locks MUST persist across restarts,
+	// as the funding transaction spending a locked output may be broadcast
+	// by the remote party at any point.
+	LockOutpoint(o wire.OutPoint) error
 
 	// UnlockOutpoint unlocks an previously locked output, marking it
 	// eligible for coin seleciton.
-	UnlockOutpoint(o wire.OutPoint)
+	UnlockOutpoint(o wire.OutPoint) error
+
+	// ListLockedOutpoints returns all currently locked outpoints,
+	// including those locked before the last restart.
+	ListLockedOutpoints() ([]wire.OutPoint, error)
 
 	// PublishTransaction performs cursory validation (dust checks, etc),
 	// then finally broadcasts the passed transaction to the Bitcoin network.
 	PublishTransaction(tx *wire.MsgTx) error
 
+	// IsSynced returns a boolean indicating if from the PoV of the wallet,
+	// it has fully synced to the current best block in the main chain.
+	IsSynced() (bool, error)
+
+	// BestBlock returns the hash and height of the block the wallet is
+	// currently synced to.
+	BestBlock() (*wire.ShaHash, int32, error)
+
 	// Start initializes the wallet, making any neccessary connections,
 	// starting up required goroutines etc.
 	Start() error
@@ -139,12 +176,23 @@ type BlockChainIO interface {
 	GetCurrentHeight() (int32, error)
 
 	// GetTxOut returns the original output referenced by the passed
-	// outpoint.
+	// outpoint. If the output has already been spent, ErrOutputSpent is
+	// returned, while ErrOutputNotFound is returned if the transaction
+	// creating it isn't known, and ErrOutputUnconfirmed if it's yet to
+	// confirm.
 	GetUtxo(txid *wire.ShaHash, index uint32) (*wire.TxOut, error)
 
 	// GetTransaction returns the full transaction identified by the passed
 	// transaction ID.
 	GetTransaction(txid *wire.ShaHash) (*wire.MsgTx, error)
+
+	// GetBlock returns the full block identified by the passed block
+	// hash.
+	GetBlock(blockHash *wire.ShaHash) (*wire.MsgBlock, error)
+
+	// GetBlockHash returns the hash of the block within the main chain at
+	// the passed height.
+	GetBlockHash(blockHeight int64) (*wire.ShaHash, error)
 }
 
 // SignDescriptor houses the necessary information required to succesfully sign
@@ -156,6 +204,13 @@ type SignDescriptor struct {
 	// key corresponding to this public key.
 	PubKey *btcec.PublicKey
 
+	// PrivateTweak is an optional scalar which, if non-nil, is added to
+	// the private key corresponding to PubKey before signing. This allows
+	// the Signer to generate signatures for keys derived from one of its
+	// own keys, such as the revocation key of a revoked commitment
+	// transaction which is derived via DeriveRevocationPrivKey.
+	PrivateTweak []byte
+
 	// RedeemScript is the full script required to properly redeem the
 	// output. This field will only be populated if a p2wsh or a p2sh
 	// output is being signed.
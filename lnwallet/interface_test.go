@@ -14,6 +14,7 @@ import (
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/chainntnfs/btcdnotify"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwallet/btcwallet"
 	"github.com/roasbeef/btcd/chaincfg"
@@ -102,6 +103,7 @@ type bobNode struct {
 	revocation      [32]byte
 	delay           uint32
 	id              [wire.HashSize]byte
+	assetParams     lnwallet.AssetParams
 
 	availableOutputs []*wire.TxIn
 	changeOutputs    []*wire.TxOut
@@ -121,6 +123,7 @@ func (b *bobNode) Contribution(aliceCommitKey *btcec.PublicKey) *lnwallet.Channe
 		DeliveryAddress: b.deliveryAddress,
 		RevocationKey:   revokeKey,
 		CsvDelay:        b.delay,
+		AssetParams:     b.assetParams,
 	}
 }
 
@@ -135,6 +138,7 @@ func (b *bobNode) SingleContribution(aliceCommitKey *btcec.PublicKey) *lnwallet.
 		DeliveryAddress: b.deliveryAddress,
 		RevocationKey:   revokeKey,
 		CsvDelay:        b.delay,
+		AssetParams:     b.assetParams,
 	}
 }
 
@@ -250,6 +254,12 @@ func newBobNode(miner *rpctest.Harness, amt btcutil.Amount) (*bobNode, error) {
 		delay:            5,
 		availableOutputs: []*wire.TxIn{bobTxIn},
 		changeOutputs:    []*wire.TxOut{bobChangeOutput},
+		assetParams: lnwallet.AssetParams{
+			AssetID:          os.Getenv("CC_ASSET_ID"),
+			DustLimit:        lnwallet.DefaultAssetDustLimit,
+			MaxHTLCValue:     1000,
+			CarrierSatBudget: lnwallet.DefaultCarrierSatBudget,
+		},
 	}, nil
 }
 
@@ -321,8 +331,8 @@ func createTestWallet(tempTestDir string, miningNode *rpctest.Harness,
 		return nil, err
 	}
 
-	wallet, err := lnwallet.NewLightningWallet(cdb, notifier, wc, signer,
-		bio, netParams)
+	wallet, err := lnwallet.NewLightningWallet(nil, cdb, notifier, wc,
+		signer, bio, netParams)
 	if err != nil {
 		return nil, err
 	}
@@ -430,9 +440,9 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 		t.Fatalf("bob's revocaiton key not found")
 	}
 
-	// TODO(roasbeef): account for current hard-coded commit fee,
-	// need to remove bob all together
-	chanCapacity := int64(10e8 + 5000)
+	// The commitment transactions spend the colorified funding output,
+	// which carries the channel's asset rather than its capacity.
+	fundingValue := int64(lndcc.FundingOutputValue())
 	// Alice responds with her output, change addr, multi-sig key and signatures.
 	// Bob then responds with his signatures.
 	bobsSigs, err := bobNode.signFundingTx(fundingTx)
@@ -442,7 +452,7 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 	commitSig, err := bobNode.signCommitTx(
 		chanReservation.LocalCommitTx(),
 		chanReservation.FundingRedeemScript(),
-		chanCapacity)
+		fundingValue)
 	if err != nil {
 		t.Fatalf("bob is unable to sign alice's commit tx: %v", err)
 	}
@@ -470,7 +480,7 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 
 	// Now that the channel is open, execute a cooperative closure of the
 	// now open channel.
-	aliceCloseSig, _, err := lnc.InitCooperativeClose()
+	aliceCloseSig, _, err := lnc.InitCooperativeClose(nil)
 	if err != nil {
 		t.Fatalf("unable to init cooperative closure: %v", err)
 	}
@@ -482,11 +492,15 @@ func testDualFundingReservationWorkflow(miner *rpctest.Harness, wallet *lnwallet
 	redeemScript := lnc.FundingRedeemScript
 	fundingOut := lnc.ChannelPoint()
 	fundingTxIn := wire.NewTxIn(fundingOut, nil, nil)
-	bobCloseTx := lnwallet.CreateCooperativeCloseTx(fundingTxIn,
-		chanInfo.RemoteBalance, chanInfo.LocalBalance,
+	bobCloseTx, err := lnwallet.CreateCooperativeCloseTx(fundingTxIn,
+		lnc.BtcCapacity, chanInfo.RemoteBalance, chanInfo.LocalBalance,
 		lnc.RemoteDeliveryScript, lnc.LocalDeliveryScript,
-		false)
-	bobSig, err := bobNode.signCommitTx(bobCloseTx, redeemScript, int64(lnc.Capacity))
+		false, &lnc.ClosePolicy)
+	if err != nil {
+		t.Fatalf("unable to create bob's closing tx: %v", err)
+	}
+	bobSig, err := bobNode.signCommitTx(bobCloseTx, redeemScript,
+		int64(lnc.BtcCapacity))
 	if err != nil {
 		t.Fatalf("unable to generate bob's signature for closing tx: %v", err)
 	}
@@ -593,6 +607,27 @@ func testCancelNonExistantReservation(miner *rpctest.Harness,
 	}
 }
 
+func testFundingReservationCsvBounds(miner *rpctest.Harness,
+	wallet *lnwallet.LightningWallet, t *testing.T) {
+
+	// A reservation requiring a CSV delay below the wallet's minimum
+	// should be rejected, as we may not have enough time to punish a
+	// breach.
+	_, err := wallet.InitChannelReservation(0, 0, testHdSeed, numReqConfs,
+		lnwallet.DefaultMinCsvDelay-1)
+	if err != lnwallet.ErrCsvDelayOutOfBounds {
+		t.Fatalf("expected ErrCsvDelayOutOfBounds, got %v", err)
+	}
+
+	// Similarly, a delay above the wallet's maximum should be rejected, as
+	// our funds would be locked up for too long.
+	_, err = wallet.InitChannelReservation(0, 0, testHdSeed, numReqConfs,
+		lnwallet.DefaultMaxCsvDelay+1)
+	if err != lnwallet.ErrCsvDelayOutOfBounds {
+		t.Fatalf("expected ErrCsvDelayOutOfBounds, got %v", err)
+	}
+}
+
 func testSingleFunderReservationWorkflowInitiator(miner *rpctest.Harness,
 	lnwallet *lnwallet.LightningWallet, t *testing.T) {
 
@@ -693,9 +728,7 @@ func testSingleFunderReservationWorkflowInitiator(miner *rpctest.Harness,
 	bobCommitSig, err := bobNode.signCommitTx(
 		chanReservation.LocalCommitTx(),
 		chanReservation.FundingRedeemScript(),
-		// TODO(roasbeef): account for current hard-coded fee, need to
-		// remove bobNode entirely
-		int64(fundingAmt)+5000)
+		int64(lndcc.FundingOutputValue()))
 	if err != nil {
 		t.Fatalf("bob is unable to sign alice's commit tx: %v", err)
 	}
@@ -843,8 +876,7 @@ func testSingleFunderReservationWorkflowResponder(miner *rpctest.Harness,
 	}
 	txsort.InPlaceSort(aliceCommitTx)
 	bobCommitSig, err := bobNode.signCommitTx(aliceCommitTx,
-		// TODO(roasbeef): account for hard-coded fee, remove bob node
-		fundingRedeemScript, int64(capacity)+5000)
+		fundingRedeemScript, int64(lndcc.FundingOutputValue()))
 	if err != nil {
 		t.Fatalf("unable to sign alice's commit tx: %v", err)
 	}
@@ -852,7 +884,8 @@ func testSingleFunderReservationWorkflowResponder(miner *rpctest.Harness,
 	// With this stage complete, Alice can now complete the reservation.
 	bobRevokeKey := bobContribution.RevocationKey
 	if err := chanReservation.CompleteReservationSingle(bobRevokeKey,
-		fundingOutpoint, bobCommitSig); err != nil {
+		fundingOutpoint, lndcc.FundingOutputValue(),
+		bobCommitSig); err != nil {
 		t.Fatalf("unable to complete reservation: %v", err)
 	}
 
@@ -872,6 +905,56 @@ func testSingleFunderReservationWorkflowResponder(miner *rpctest.Harness,
 	// TODO(roasbeef): bob verify alice's sig
 }
 
+// waitForWalletSync blocks until the wallet has synced to the miner's best
+// block.
+func waitForWalletSync(miner *rpctest.Harness,
+	w *lnwallet.LightningWallet) error {
+
+	timeout := time.After(10 * time.Second)
+	for {
+		synced, err := w.IsSynced()
+		if err != nil {
+			return err
+		}
+		if synced {
+			return nil
+		}
+
+		select {
+		case <-timeout:
+			return fmt.Errorf("wallet not synced to main chain")
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func testWalletSyncState(miner *rpctest.Harness,
+	wallet *lnwallet.LightningWallet, t *testing.T) {
+
+	// Once the miner extends the main chain, the wallet should catch up,
+	// reporting the miner's best block as its own.
+	if _, err := miner.Node.Generate(1); err != nil {
+		t.Fatalf("unable to generate block: %v", err)
+	}
+	if err := waitForWalletSync(miner, wallet); err != nil {
+		t.Fatalf("unable to sync wallet: %v", err)
+	}
+
+	bestHash, bestHeight, err := miner.Node.GetBestBlock()
+	if err != nil {
+		t.Fatalf("unable to query best block: %v", err)
+	}
+	walletHash, walletHeight, err := wallet.BestBlock()
+	if err != nil {
+		t.Fatalf("unable to query wallet best block: %v", err)
+	}
+	if walletHeight != bestHeight || !walletHash.IsEqual(bestHash) {
+		t.Fatalf("wallet synced to %v at height %v, expected %v at "+
+			"height %v", walletHash, walletHeight, bestHash,
+			bestHeight)
+	}
+}
+
 func testFundingReservationInvalidCounterpartySigs(miner *rpctest.Harness, lnwallet *lnwallet.LightningWallet, t *testing.T) {
 }
 
@@ -884,7 +967,9 @@ var walletTests = []func(miner *rpctest.Harness, w *lnwallet.LightningWallet, te
 	testSingleFunderReservationWorkflowResponder,
 	testFundingTransactionLockedOutputs,
 	testFundingCancellationNotEnoughFunds,
+	testFundingReservationCsvBounds,
 	testFundingReservationInvalidCounterpartySigs,
+	testWalletSyncState,
 }
 
 type testLnWallet struct {
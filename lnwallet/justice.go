@@ -0,0 +1,157 @@
+package lnwallet
+
+import (
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// justiceSequence is the sequence number of each input of a justice
+// transaction. It signals opt-in replace-by-fee as per BIP 125, allowing a
+// justice transaction to be replaced by one paying a higher fee should it
+// fail to confirm before the breaching party's delayed output matures.
+const justiceSequence = wire.MaxTxInSequenceNum - 2
+
+// JusticeTx is a fully signed transaction which sweeps all the outputs of a
+// revoked remote commitment transaction, along with the colored coins
+// transfer instructions embedded within it.
+type JusticeTx struct {
+	// Tx is the fully signed justice transaction.
+	Tx *wire.MsgTx
+
+	// AssetAmt is the total amount of the channel's asset swept by the
+	// justice transaction.
+	AssetAmt btcutil.Amount
+
+	// Fee is the fee in satoshis paid by the justice transaction.
+	Fee btcutil.Amount
+
+	// Instructions is the set of colored coins transfer instructions
+	// encoded within the OP_RETURN output of the justice transaction.
+	Instructions []lndcc.Instruction
+}
+
+// CreateJusticeTx creates a fully signed transaction sweeping all the outputs
+// of the passed revoked commitment to sweepPkScript. The colored amounts of
+// the revoked outputs are transferred to the sweep output, while the bitcoin
+// carried by the dust carrier outputs pays for the transaction's fees.
+// commitKey is our commitment key within the channel: the revocation key of
+// the revoked state is derived from it, and it directly signs for the
+// revocation clause of each HTLC.
+//
+// If feeRate, in sat/vbyte, is non-zero, then only the fee at that rate is
+// paid, with the carrier satoshis left over returned to sweepPkScript within
+// an uncolored output following the OP_RETURN output. Otherwise, or if the
+// left over satoshis would be dust, then all the carrier satoshis are paid as
+// fees. Each input signals replace-by-fee, so a justice transaction may be
+// replaced by another paying a higher fee.
+func CreateJusticeTx(signer Signer, commitKey *btcec.PublicKey,
+	revoked *channeldb.RevokedCommitment, sweepPkScript []byte,
+	feeRate uint64) (*JusticeTx, error) {
+
+	if len(revoked.Outputs) == 0 {
+		return nil, fmt.Errorf("revoked commitment %v has no sweepable "+
+			"outputs", revoked.CommitTxid)
+	}
+
+	// First, assemble the sweep transaction spending each revoked output,
+	// with a single output paying the total asset amount to the sweep
+	// script. The witness of each revoked output carries its witness
+	// script, and the revocation pre-image for HTLCs, on top of what the
+	// witness of a p2wkh output would.
+	inputs := make([]*SweepInput, 0, len(revoked.Outputs))
+	for _, output := range revoked.Outputs {
+		inputs = append(inputs, &SweepInput{
+			OutPoint: wire.OutPoint{
+				Hash:  revoked.CommitTxid,
+				Index: output.Index,
+			},
+			Value:       output.Value,
+			AssetAmt:    output.AssetAmt,
+			Sequence:    justiceSequence,
+			WitnessSize: len(output.WitnessScript) + 32,
+		})
+	}
+	sweep, err := BuildColoredSweep(inputs, sweepPkScript, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	if sweep.Fee <= 0 {
+		return nil, fmt.Errorf("revoked outputs of %v carry %v, "+
+			"insufficient to pay fees for sweep", revoked.CommitTxid,
+			sweep.CarrierAmt)
+	}
+	justiceTx := sweep.Tx
+
+	// With the final transaction assembled, generate a valid witness for
+	// each of the inputs.
+	hashCache := txscript.NewTxSigHashes(justiceTx)
+	for i, output := range revoked.Outputs {
+		signDesc := &SignDescriptor{
+			PubKey:       commitKey,
+			RedeemScript: output.WitnessScript,
+			Output: &wire.TxOut{
+				Value:    int64(output.Value),
+				PkScript: output.PkScript,
+			},
+			HashType:   txscript.SigHashAll,
+			SigHashes:  hashCache,
+			InputIndex: i,
+		}
+
+		// The delayed output of the remote party is signed for using
+		// the revocation key, while the revocation clause of the HTLC
+		// scripts requires our commitment key along with the
+		// revocation pre-image.
+		if !output.IsHTLC {
+			signDesc.PrivateTweak = revoked.RevocationPreimage[:]
+		}
+
+		sig, err := signer.SignOutputRaw(justiceTx, signDesc)
+		if err != nil {
+			return nil, err
+		}
+		sig = append(sig, byte(txscript.SigHashAll))
+
+		justiceTx.TxIn[i].Witness = justiceWitness(output, sig,
+			revoked.RevocationPreimage[:])
+	}
+
+	return &JusticeTx{
+		Tx:           justiceTx,
+		AssetAmt:     sweep.AssetAmt,
+		Fee:          sweep.Fee,
+		Instructions: sweep.Instructions,
+	}, nil
+}
+
+// justiceWitness returns the witness which satisfies the revocation clause of
+// the passed revoked output given a valid signature.
+func justiceWitness(output *channeldb.RevokedOutput, sig,
+	revokePreimage []byte) wire.TxWitness {
+
+	// The remote party's delayed output only requires a signature under
+	// the revocation key, with a 1 forcing execution of the revocation
+	// clause.
+	if !output.IsHTLC {
+		return wire.TxWitness{sig, []byte{1}, output.WitnessScript}
+	}
+
+	// On the remote commitment, HTLC's paying to us use the sender's
+	// version of the HTLC script, while HTLC's we've offered use the
+	// receiver's version. Each requires a distinct selector in order to
+	// enter the revocation clause.
+	selector := []byte{0}
+	if output.IsIncoming {
+		selector = []byte{1}
+	}
+
+	return wire.TxWitness{
+		sig, revokePreimage, []byte{1}, selector, output.WitnessScript,
+	}
+}
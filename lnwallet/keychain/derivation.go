@@ -0,0 +1,122 @@
+// Package keychain houses the key tweaks, and secret derivations used to
+// construct, and revoke the commitment transactions of a channel. They're
+// shared by the wallet, a remote signer, and the watchtower, each of which
+// must arrive at identical keys, and scripts for the same channel state.
+package keychain
+
+import (
+	"crypto/sha256"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// WitnessScriptHash generates a pay-to-witness-script-hash public key script
+// paying to a version 0 witness program paying to the passed redeem script.
+func WitnessScriptHash(redeemScript []byte) ([]byte, error) {
+	bldr := txscript.NewScriptBuilder()
+
+	bldr.AddOp(txscript.OP_0)
+	scriptHash := fastsha256.Sum256(redeemScript)
+	bldr.AddData(scriptHash[:])
+	return bldr.Script()
+}
+
+// DeriveRevocationPubkey derives the revocation public key given the
+// counter-party's commitment key, and revocation pre-image derived via a
+// pseudo-random-function. In the event that we (for some reason) broadcast a
+// revoked commitment transaction, then if the other party knows the revocation
+// pre-image, then they'll be able to derive the corresponding private key to
+// this private key by exploting the homomorphism in the elliptic curve group:
+//    * https://en.wikipedia.org/wiki/Group_homomorphism#Homomorphisms_of_abelian_groups
+//
+// The derivation is performed as follows:
+//
+//   revokeKey := commitKey + revokePoint
+//             := G*k + G*h
+//             := G * (k+h)
+//
+// Therefore, once we divulge the revocation pre-image, the remote peer is able to
+// compute the proper private key for the revokeKey by computing:
+//   revokePriv := commitPriv + revokePreimge mod N
+//
+// Where N is the order of the sub-group.
+func DeriveRevocationPubkey(commitPubKey *btcec.PublicKey,
+	revokePreimage []byte) *btcec.PublicKey {
+
+	// First we need to convert the revocation hash into a point on the
+	// elliptic curve.
+	revokePointX, revokePointY := btcec.S256().ScalarBaseMult(revokePreimage)
+
+	// Now that we have the revocation point, we add this to their commitment
+	// public key in order to obtain the revocation public key.
+	revokeX, revokeY := btcec.S256().Add(commitPubKey.X, commitPubKey.Y,
+		revokePointX, revokePointY)
+	return &btcec.PublicKey{X: revokeX, Y: revokeY}
+}
+
+// DeriveRevocationPrivKey derives the revocation private key given a node's
+// commitment private key, and the pre-image to a previously seen revocation
+// hash. Using this derived private key, a node is able to claim the output
+// within the commitment transaction of a node in the case that they broadcast
+// a previously revoked commitment transaction.
+//
+// The private key is derived as follwos:
+//   revokePriv := commitPriv + revokePreimage mod N
+//
+// Where N is the order of the sub-group.
+func DeriveRevocationPrivKey(commitPrivKey *btcec.PrivateKey,
+	revokePreimage []byte) *btcec.PrivateKey {
+
+	// Convert the revocation pre-image into a scalar value so we can
+	// manipulate it within the curve's defined finite field.
+	revokeScalar := new(big.Int).SetBytes(revokePreimage)
+
+	// To derive the revocation private key, we simply add the revocation
+	// pre-image to the commitment private key.
+	//
+	// This works since:
+	//  P = G*a + G*b
+	//    = G*(a+b)
+	//    = G*p
+	revokePriv := revokeScalar.Add(revokeScalar, commitPrivKey.D)
+	revokePriv = revokePriv.Mod(revokePriv, btcec.S256().N)
+
+	privRevoke, _ := btcec.PrivKeyFromBytes(btcec.S256(), revokePriv.Bytes())
+	return privRevoke
+}
+
+// DeriveElkremRoot derives an elkrem root unique to a channel given the
+// private key for our public key in the 2-of-2 multi-sig, and the remote
+// node's multi-sig public key. The root is derived using the HKDF[1][2]
+// instantiated with sha-256. The secret data used is our multi-sig private
+// key, with the salt being the remote node's public key.
+//
+// The same root seeds a BOLT shachain, when selected in place of the elkrem
+// by a channel.
+//
+// [1]: https://eprint.iacr.org/2010/264.pdf
+// [2]: https://tools.ietf.org/html/rfc5869
+func DeriveElkremRoot(elkremDerivationRoot *btcec.PrivateKey,
+	localMultiSigKey *btcec.PublicKey,
+	remoteMultiSigKey *btcec.PublicKey) wire.ShaHash {
+
+	secret := elkremDerivationRoot.Serialize()
+	salt := localMultiSigKey.SerializeCompressed()
+	info := remoteMultiSigKey.SerializeCompressed()
+
+	rootReader := hkdf.New(sha256.New, secret, salt, info)
+
+	// It's safe to ignore the error her as we know for sure that we won't
+	// be draining the HKDF past its available entropy horizon.
+	// TODO(roasbeef): revisit...
+	var elkremRoot wire.ShaHash
+	rootReader.Read(elkremRoot[:])
+
+	return elkremRoot
+}
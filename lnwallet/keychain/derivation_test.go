@@ -0,0 +1,100 @@
+package keychain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/roasbeef/btcd/btcec"
+)
+
+// The keys of the test vectors are each the sha256 of a fixed string, allowing
+// the vectors to be reproduced by other implementations.
+var (
+	commitPrivKey, commitPubKey = keyFromString("commit")
+	revokePreimage              = sha256.Sum256([]byte("revoke"))
+
+	rootPrivKey, _ = keyFromString("root")
+	_, localKey    = keyFromString("local")
+	_, remoteKey   = keyFromString("remote")
+)
+
+func keyFromString(s string) (*btcec.PrivateKey, *btcec.PublicKey) {
+	k := sha256.Sum256([]byte(s))
+	return btcec.PrivKeyFromBytes(btcec.S256(), k[:])
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("unable to decode hex: %v", err)
+	}
+	return b
+}
+
+// TestRevocationKeyVectors tests the derivation of the revocation key pair
+// against fixed vectors, and that the derived private key matches the derived
+// public key.
+func TestRevocationKeyVectors(t *testing.T) {
+	expectedCommitPub := mustDecode(t, "023440fb5d3771c4ffcfe92fce4107a1"+
+		"c7e80b0f924e6e1e150e295f98b7043617")
+	if !bytes.Equal(commitPubKey.SerializeCompressed(), expectedCommitPub) {
+		t.Fatalf("commitment key mismatch: expected %x, got %x",
+			expectedCommitPub, commitPubKey.SerializeCompressed())
+	}
+
+	revokePub := DeriveRevocationPubkey(commitPubKey, revokePreimage[:])
+	expectedPub := mustDecode(t, "033293413bf5152baae7ff1f666a8826a5"+
+		"98dad1a131cfffb12f946660d1298217")
+	if !bytes.Equal(revokePub.SerializeCompressed(), expectedPub) {
+		t.Fatalf("revocation public key mismatch: expected %x, got %x",
+			expectedPub, revokePub.SerializeCompressed())
+	}
+
+	revokePriv := DeriveRevocationPrivKey(commitPrivKey, revokePreimage[:])
+	expectedPriv := mustDecode(t, "0c808fb586df7e1e8427f9e3c3e0fb6e17"+
+		"6ad3020da33f9c983d11df527cd4fe")
+	if !bytes.Equal(revokePriv.Serialize(), expectedPriv) {
+		t.Fatalf("revocation private key mismatch: expected %x, got %x",
+			expectedPriv, revokePriv.Serialize())
+	}
+
+	if !revokePriv.PubKey().IsEqual(revokePub) {
+		t.Fatalf("revocation private key doesn't match public key")
+	}
+}
+
+// TestElkremRootVector tests the derivation of a channel's elkrem root against
+// a fixed vector.
+func TestElkremRootVector(t *testing.T) {
+	root := DeriveElkremRoot(rootPrivKey, localKey, remoteKey)
+	expected := mustDecode(t, "1a6a0be5f1a2199c4442dde7deccfdf0081d2a8d"+
+		"e9e2a441e094e6ddcf1bcda9")
+	if !bytes.Equal(root[:], expected) {
+		t.Fatalf("elkrem root mismatch: expected %x, got %x", expected,
+			root[:])
+	}
+
+	// Swapping the multi-sig keys must yield a distinct root, so each
+	// party derives its own.
+	swapped := DeriveElkremRoot(rootPrivKey, remoteKey, localKey)
+	if swapped == root {
+		t.Fatalf("elkrem root independent of key order")
+	}
+}
+
+// TestWitnessScriptHashVector tests that the p2wsh script is a version 0
+// witness program of the sha256 of the redeem script.
+func TestWitnessScriptHashVector(t *testing.T) {
+	pkScript, err := WitnessScriptHash([]byte{0x51})
+	if err != nil {
+		t.Fatalf("unable to generate p2wsh script: %v", err)
+	}
+	expected := mustDecode(t, "00204ae81572f06e1b88fd5ced7a1a000945432e"+
+		"83e1551e6f721ee9c00b8cc33260")
+	if !bytes.Equal(pkScript, expected) {
+		t.Fatalf("p2wsh script mismatch: expected %x, got %x", expected,
+			pkScript)
+	}
+}
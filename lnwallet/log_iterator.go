@@ -0,0 +1,155 @@
+package lnwallet
+
+import (
+	"container/list"
+
+	"github.com/roasbeef/btcutil"
+)
+
+// LogEntry is a snapshot of a single entry within either of the channel's
+// HTLC update logs, as returned by a LogIterator.
+type LogEntry struct {
+	// Incoming is true if the entry belongs to the remote party's update
+	// log, and false if it belongs to ours.
+	Incoming bool
+
+	// EntryType denotes whether the entry adds, settles, or times out an
+	// HTLC.
+	EntryType updateType
+
+	// Index is the index of the entry within its update log, while
+	// ParentIndex is the index of the HTLC a settle or timeout removes,
+	// within the opposite update log.
+	Index       uint64
+	ParentIndex uint64
+
+	RHash   PaymentHash
+	Timeout uint32
+	Amount  btcutil.Amount
+
+	// AddHeightLocal and AddHeightRemote are the heights of the first
+	// commitments of the local and remote chains which include an added
+	// HTLC, or zero if it hasn't yet been included.
+	AddHeightLocal  uint64
+	AddHeightRemote uint64
+
+	// RemoveHeightLocal and RemoveHeightRemote are the heights of the
+	// first commitments of the local and remote chains which include a
+	// settle or timeout, or zero if it hasn't yet been included.
+	RemoveHeightLocal  uint64
+	RemoveHeightRemote uint64
+}
+
+// LogIterator iterates over the entries of a channel's HTLC update logs, our
+// log followed by the remote party's, in order of their log index. Rather
+// than copying the logs up front, the channel's lock is only held while
+// fetching each entry, so the logs may be modified between calls to Next.
+// Entries added during the iteration are returned, while entries compacted
+// away before being reached are skipped.
+type LogIterator struct {
+	lc         *LightningChannel
+	sinceIndex uint64
+
+	// incoming is true once the iteration has moved on to the remote
+	// party's log. Within the current log, nextIndex is the lowest index
+	// the next entry may have, and elem is the element holding the entry
+	// last returned.
+	incoming  bool
+	nextIndex uint64
+	elem      *list.Element
+	done      bool
+}
+
+// DumpLog returns an iterator over all entries within the channel's HTLC
+// update logs with a log index of at least sinceIndex. If assetID is
+// non-empty and the channel doesn't carry that asset, the returned iterator
+// is empty, allowing the logs of all channels of a particular asset to be
+// inspected by calling DumpLog on each channel.
+func (lc *LightningChannel) DumpLog(assetID string, sinceIndex uint64) *LogIterator {
+	lc.stateMtx.RLock()
+	chanAssetID := lc.channelState.AssetID
+	lc.stateMtx.RUnlock()
+
+	return &LogIterator{
+		lc:         lc,
+		sinceIndex: sinceIndex,
+		nextIndex:  sinceIndex,
+		done:       assetID != "" && assetID != chanAssetID,
+	}
+}
+
+// Next returns the next log entry, or false if the iteration is complete.
+func (it *LogIterator) Next() (*LogEntry, bool) {
+	if it.done {
+		return nil, false
+	}
+
+	it.lc.RLock()
+	defer it.lc.RUnlock()
+
+	for {
+		if e := it.nextElement(); e != nil {
+			pd := e.Value.(*PaymentDescriptor)
+			it.elem = e
+			it.nextIndex = pd.Index + 1
+
+			return newLogEntry(pd, it.incoming), true
+		}
+
+		// Our log has been exhausted, so continue with the remote
+		// party's log starting from the same index.
+		if it.incoming {
+			it.done = true
+			return nil, false
+		}
+		it.incoming = true
+		it.elem = nil
+		it.nextIndex = it.sinceIndex
+	}
+}
+
+// nextElement returns the element of the current update log holding the
+// entry with the lowest index of at least nextIndex, or nil if there's no
+// such entry. The element last returned is used as a cursor into the log,
+// unless it's since been compacted away, in which case the log is scanned
+// from its front. This method MUST be called with the channel's lock held.
+func (it *LogIterator) nextElement() *list.Element {
+	updateLog, logIndex := it.lc.ourUpdateLog, it.lc.ourLogIndex
+	if it.incoming {
+		updateLog, logIndex = it.lc.theirUpdateLog, it.lc.theirLogIndex
+	}
+
+	e := updateLog.Front()
+	if it.elem != nil {
+		pd := it.elem.Value.(*PaymentDescriptor)
+		if logIndex[pd.Index] == it.elem {
+			e = it.elem.Next()
+		}
+	}
+
+	for ; e != nil; e = e.Next() {
+		if e.Value.(*PaymentDescriptor).Index >= it.nextIndex {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// newLogEntry returns a snapshot of the passed log entry. This function MUST
+// be called with the channel's lock held.
+func newLogEntry(pd *PaymentDescriptor, incoming bool) *LogEntry {
+	return &LogEntry{
+		Incoming:           incoming,
+		EntryType:          pd.EntryType,
+		Index:              pd.Index,
+		ParentIndex:        pd.ParentIndex,
+		RHash:              pd.RHash,
+		Timeout:            pd.Timeout,
+		Amount:             pd.Amount,
+		AddHeightLocal:     pd.addCommitHeightLocal,
+		AddHeightRemote:    pd.addCommitHeightRemote,
+		RemoveHeightLocal:  pd.removeCommitHeightLocal,
+		RemoveHeightRemote: pd.removeCommitHeightRemote,
+	}
+}
@@ -0,0 +1,110 @@
+package lnwallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// TestDumpLog tests that a log iterator returns the entries of both update
+// logs from the requested index onwards, and that modifications to the logs
+// during the iteration are reflected within the remainder of the iteration.
+func TestDumpLog(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	var preimages [][]byte
+	addHTLC := func(from, to *LightningChannel) {
+		preimage := bytes.Repeat([]byte{byte(len(preimages))}, 32)
+		preimages = append(preimages, preimage)
+		htlc := &lnwire.HTLCAddRequest{
+			ID:               to.theirLogCounter,
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage)},
+			Amount:           lnwire.CreditsAmount(1e6),
+			Expiry:           uint32(5),
+		}
+		if _, err := from.AddHTLC(htlc); err != nil {
+			t.Fatalf("unable to add htlc: %v", err)
+		}
+		if _, err := to.ReceiveHTLC(htlc); err != nil {
+			t.Fatalf("unable to receive htlc: %v", err)
+		}
+	}
+	assertNext := func(it *LogIterator, incoming bool, index uint64) {
+		entry, ok := it.Next()
+		if !ok {
+			t.Fatalf("expected entry %v (incoming=%v), iteration "+
+				"complete", index, incoming)
+		}
+		if entry.Incoming != incoming || entry.Index != index {
+			t.Fatalf("expected entry %v (incoming=%v), got %v "+
+				"(incoming=%v)", index, incoming, entry.Index,
+				entry.Incoming)
+		}
+	}
+
+	// Alice adds three HTLCs, while Bob adds two.
+	for i := 0; i < 3; i++ {
+		addHTLC(aliceChannel, bobChannel)
+	}
+	for i := 0; i < 2; i++ {
+		addHTLC(bobChannel, aliceChannel)
+	}
+
+	// Only entries from the requested index onwards should be returned.
+	it := aliceChannel.DumpLog("", 1)
+	assertNext(it, false, 1)
+	assertNext(it, false, 2)
+	assertNext(it, true, 1)
+	if _, ok := it.Next(); ok {
+		t.Fatalf("expected iteration to be complete")
+	}
+
+	// An iteration of a different asset's logs should be empty.
+	if _, ok := aliceChannel.DumpLog("other", 0).Next(); ok {
+		t.Fatalf("expected empty iteration for another asset")
+	}
+
+	// Once Alice's first HTLC is settled and compacted away, an iteration
+	// which already returned it should continue from the next entry, and
+	// pick up entries added since.
+	it = aliceChannel.DumpLog("", 0)
+	assertNext(it, false, 0)
+	if err := forceStateTransition(aliceChannel, bobChannel); err != nil {
+		t.Fatalf("unable to complete state transition: %v", err)
+	}
+	var preimage [32]byte
+	copy(preimage[:], preimages[0])
+	if _, err := bobChannel.SettleHTLC(preimage); err != nil {
+		t.Fatalf("unable to settle htlc: %v", err)
+	}
+	if err := aliceChannel.ReceiveHTLCSettle(preimage, 0); err != nil {
+		t.Fatalf("unable to receive settle: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		err := forceStateTransition(aliceChannel, bobChannel)
+		if err != nil {
+			t.Fatalf("unable to complete state transition: %v", err)
+		}
+	}
+	if _, ok := aliceChannel.ourLogIndex[0]; ok {
+		t.Fatalf("settled htlc not compacted away")
+	}
+	addHTLC(aliceChannel, bobChannel)
+
+	assertNext(it, false, 1)
+	assertNext(it, false, 2)
+	assertNext(it, false, 3)
+	assertNext(it, true, 0)
+	assertNext(it, true, 1)
+
+	// Bob's settle was compacted away along with the HTLC it settled.
+	if _, ok := it.Next(); ok {
+		t.Fatalf("expected iteration to be complete")
+	}
+}
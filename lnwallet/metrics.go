@@ -0,0 +1,23 @@
+package lnwallet
+
+import "github.com/lightningnetwork/lnd/metrics"
+
+var (
+	// reservationDuration tracks the time taken by channel reservations
+	// to reach a terminal state, labelled by that state: either "Open"
+	// or "Failed".
+	reservationDuration = metrics.DefaultRegistry.NewHistogram(
+		"lnwallet_reservation_duration_seconds",
+		"Time from the creation of a channel reservation until the "+
+			"channel is open, or the reservation failed.",
+		[]float64{1, 5, 15, 60, 300, 900, 3600, 4 * 3600, 24 * 3600},
+		"state",
+	)
+
+	// broadcastFailures counts the transactions the wallet failed to
+	// broadcast.
+	broadcastFailures = metrics.DefaultRegistry.NewCounter(
+		"lnwallet_broadcast_failures_total",
+		"Number of transactions which failed to be broadcast.",
+	)
+)
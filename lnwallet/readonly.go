@@ -0,0 +1,88 @@
+package lnwallet
+
+import (
+	"errors"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// ErrReadOnly is returned by every operation which would sign, or broadcast a
+// transaction while the wallet is in read-only mode.
+var ErrReadOnly = errors.New("wallet is in read-only mode")
+
+// readOnlySigner is a Signer which refuses to produce any signature. It
+// replaces the wallet's Signer in read-only mode, so channels are unable to
+// advance their state, or sign a closing transaction.
+type readOnlySigner struct{}
+
+// SignOutputRaw always returns ErrReadOnly.
+//
+// This is a part of the Signer interface.
+func (readOnlySigner) SignOutputRaw(tx *wire.MsgTx,
+	signDesc *SignDescriptor) ([]byte, error) {
+
+	return nil, ErrReadOnly
+}
+
+// ComputeInputScript always returns ErrReadOnly.
+//
+// This is a part of the Signer interface.
+func (readOnlySigner) ComputeInputScript(tx *wire.MsgTx,
+	signDesc *SignDescriptor) (*InputScript, error) {
+
+	return nil, ErrReadOnly
+}
+
+// ReadOnly returns true if the wallet refuses to sign, or broadcast any
+// transaction.
+func (l *LightningWallet) ReadOnly() bool {
+	return l.cfg.ReadOnly
+}
+
+// SendOutputs funds, signs, and broadcasts a transaction paying out to the
+// passed outputs via the underlying WalletController, unless the wallet is in
+// read-only mode.
+func (l *LightningWallet) SendOutputs(outputs []*wire.TxOut) (*wire.ShaHash,
+	error) {
+
+	if l.cfg.ReadOnly {
+		return nil, ErrReadOnly
+	}
+
+	return l.WalletController.SendOutputs(outputs)
+}
+
+// refuseReadOnly replies to the passed request with ErrReadOnly if the wallet
+// is in read-only mode, and the request could lead to a transaction being
+// signed or broadcast, returning true if the request was refused. Requests
+// which only release resources, or inspect the wallet are always serviced.
+func (l *LightningWallet) refuseReadOnly(msg interface{}) bool {
+	if !l.cfg.ReadOnly {
+		return false
+	}
+
+	switch req := msg.(type) {
+	case *initFundingReserveMsg:
+		req.err <- ErrReadOnly
+		req.resp <- nil
+	case *fundingAbortMsg:
+		req.err <- ErrReadOnly
+		req.resp <- nil
+	case *addContributionMsg:
+		req.err <- ErrReadOnly
+	case *addSingleContributionMsg:
+		req.err <- ErrReadOnly
+	case *addCounterPartySigsMsg:
+		req.err <- ErrReadOnly
+	case *addSingleFunderSigsMsg:
+		req.err <- ErrReadOnly
+	case *channelOpenMsg:
+		req.err <- ErrReadOnly
+	case *addFuelMsg:
+		req.err <- ErrReadOnly
+	default:
+		return false
+	}
+
+	return true
+}
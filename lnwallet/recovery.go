@@ -0,0 +1,173 @@
+package lnwallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// ErrFundingOutputMismatch is returned when recovering a channel whose
+// funding output doesn't pay the channel's capacity to its multi-sig script.
+var ErrFundingOutputMismatch = errors.New("funding output doesn't match " +
+	"the channel state")
+
+// ChannelRecoveryIssue details a channel which couldn't be recovered, and
+// which requires manual action before it's usable again.
+type ChannelRecoveryIssue struct {
+	// ChanPoint is the outpoint of the channel's funding output.
+	ChanPoint wire.OutPoint
+
+	// RemoteID is the lightning ID of the channel's counterparty.
+	RemoteID wire.ShaHash
+
+	// Err is the reason the channel couldn't be recovered.
+	Err error
+}
+
+// RecoveryReport summarizes the outcome of recovering all the channels
+// persisted within the channel database.
+type RecoveryReport struct {
+	// Channels are the channels which were recovered. Channels whose
+	// funding output was spent while we were offline are included, flagged
+	// by FundingSpentOffline, having already been handed off to the close
+	// observer.
+	Channels []*LightningChannel
+
+	// NeedsAction are the channels which couldn't be recovered.
+	NeedsAction []*ChannelRecoveryIssue
+}
+
+// RecoverChannels reconstructs every open channel persisted within the
+// channel database against the chain. The funding output of each channel is
+// verified to still pay the channel's capacity to its multi-sig script, and
+// to carry its asset capacity as reported by the colored coins service.
+// Verified channels are then rebuilt as LightningChannels, re-registering all
+// of their chain notifications. Channels which fail verification, or can't be
+// rebuilt, aren't returned, being reported as needing manual action instead.
+//
+// NOTE: The backing wallet must be fully synced, as otherwise funding outputs
+// may appear to be missing or unspent.
+func (l *LightningWallet) RecoverChannels() (*RecoveryReport, error) {
+	synced, err := l.IsSynced()
+	if err != nil {
+		return nil, err
+	}
+	if !synced {
+		return nil, ErrWalletNotSynced
+	}
+
+	channels, err := l.ChannelDB.FetchAllChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RecoveryReport{}
+	for _, state := range channels {
+		chanPoint := *state.ChanID
+
+		lnChan, err := l.recoverChannel(state)
+		if err != nil {
+			walletLog.Errorf("ChannelPoint(%v): unable to recover "+
+				"channel: %v", chanPoint, err)
+
+			report.NeedsAction = append(report.NeedsAction,
+				&ChannelRecoveryIssue{
+					ChanPoint: chanPoint,
+					RemoteID:  wire.ShaHash(state.TheirLNID),
+					Err:       err,
+				})
+			continue
+		}
+
+		if lnChan.FundingSpentOffline {
+			walletLog.Infof("ChannelPoint(%v): recovered channel "+
+				"was closed while offline", chanPoint)
+		}
+		report.Channels = append(report.Channels, lnChan)
+	}
+
+	walletLog.Infof("Recovered %v of %v channels, %v need manual action",
+		len(report.Channels), len(channels), len(report.NeedsAction))
+
+	return report, nil
+}
+
+// recoverChannel verifies the funding output of the passed channel, then
+// rebuilds the channel.
+func (l *LightningWallet) recoverChannel(
+	state *channeldb.OpenChannel) (*LightningChannel, error) {
+
+	if err := l.verifyFundingOutput(state); err != nil {
+		return nil, err
+	}
+
+	return NewLightningChannel(l.Signer, l.chainIO, l.chainNotifier, state)
+}
+
+// verifyFundingOutput verifies the funding output of the passed channel
+// against the chain. Unlike the validation performed when a channel is
+// loaded, a funding output which can't be fetched, or whose color data is
+// unavailable, fails verification. A funding output spent while we were
+// offline passes, as the channel is then handed off to the close observer
+// once rebuilt.
+func (l *LightningWallet) verifyFundingOutput(state *channeldb.OpenChannel) error {
+	fundingPoint := state.FundingOutpoint
+
+	txOut, err := l.chainIO.GetUtxo(&fundingPoint.Hash, fundingPoint.Index)
+	switch {
+	case err == ErrOutputSpent:
+		return nil
+
+	case err != nil:
+		return fmt.Errorf("unable to fetch funding output %v: %v",
+			fundingPoint, err)
+	}
+
+	txoData, err := lndcc.GetTxoData(*fundingPoint)
+	if err != nil {
+		return fmt.Errorf("unable to fetch color data of funding "+
+			"output %v: %v", fundingPoint, err)
+	}
+
+	return CheckRecoveredFunding(txOut, state, txoData)
+}
+
+// CheckRecoveredFunding checks that the passed funding output, as fetched
+// from the chain backend, funds the channel described by state, carrying the
+// color data reported for it. ErrFundingOutputMismatch, or
+// ErrFundingColorMismatch is returned otherwise.
+func CheckRecoveredFunding(txOut *wire.TxOut, state *channeldb.OpenChannel,
+	txoData *lndcc.TxoData) error {
+
+	fundingPoint := state.FundingOutpoint
+
+	p2wsh, err := witnessScriptHash(state.FundingRedeemScript)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(txOut.PkScript, p2wsh) {
+		return fmt.Errorf("%v: funding output %v doesn't pay to the "+
+			"channel's multi-sig script", ErrFundingOutputMismatch,
+			fundingPoint)
+	}
+	if btcutil.Amount(txOut.Value) != state.BtcCapacity {
+		return fmt.Errorf("%v: funding output %v carries %v, expected "+
+			"%v", ErrFundingOutputMismatch, fundingPoint,
+			btcutil.Amount(txOut.Value), state.BtcCapacity)
+	}
+
+	if txoData.AssetId != state.AssetID ||
+		txoData.Value != state.AssetCapacity {
+
+		return fmt.Errorf("%v: ChannelPoint(%v) carries %v, expected "+
+			"%v of %v", ErrFundingColorMismatch, fundingPoint,
+			txoData, state.AssetCapacity, state.AssetID)
+	}
+
+	return nil
+}
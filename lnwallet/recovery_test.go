@@ -0,0 +1,85 @@
+package lnwallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestCheckRecoveredFunding tests that the funding output of a recovered
+// channel is only accepted if it pays the channel's satoshi capacity to the
+// multi-sig script, and carries the channel's asset capacity.
+func TestCheckRecoveredFunding(t *testing.T) {
+	redeemScript := []byte{1, 2, 3}
+	p2wsh, err := witnessScriptHash(redeemScript)
+	if err != nil {
+		t.Fatalf("unable to create p2wsh: %v", err)
+	}
+
+	txOut := wire.NewTxOut(5000, p2wsh)
+	newState := func() *channeldb.OpenChannel {
+		return &channeldb.OpenChannel{
+			FundingOutpoint:     &wire.OutPoint{Index: 1},
+			FundingRedeemScript: redeemScript,
+			BtcCapacity:         5000,
+			AssetID:             "assetA",
+			AssetCapacity:       300,
+		}
+	}
+	txoData := &lndcc.TxoData{AssetId: "assetA", Value: 300}
+
+	if err := CheckRecoveredFunding(txOut, newState(), txoData); err != nil {
+		t.Fatalf("valid funding output rejected: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*channeldb.OpenChannel)
+		txoData *lndcc.TxoData
+		err     error
+	}{
+		{
+			name: "wrong script",
+			mutate: func(s *channeldb.OpenChannel) {
+				s.FundingRedeemScript = []byte{4}
+			},
+			err: ErrFundingOutputMismatch,
+		},
+		{
+			name: "wrong satoshi capacity",
+			mutate: func(s *channeldb.OpenChannel) {
+				s.BtcCapacity = 6000
+			},
+			err: ErrFundingOutputMismatch,
+		},
+		{
+			name:    "wrong asset",
+			txoData: &lndcc.TxoData{AssetId: "assetB", Value: 300},
+			err:     ErrFundingColorMismatch,
+		},
+		{
+			name:    "wrong asset capacity",
+			txoData: &lndcc.TxoData{AssetId: "assetA", Value: 200},
+			err:     ErrFundingColorMismatch,
+		},
+	}
+	for _, test := range tests {
+		state := newState()
+		if test.mutate != nil {
+			test.mutate(state)
+		}
+		data := txoData
+		if test.txoData != nil {
+			data = test.txoData
+		}
+
+		err := CheckRecoveredFunding(txOut, state, data)
+		if err == nil || !strings.HasPrefix(err.Error(), test.err.Error()) {
+			t.Fatalf("%v: expected %v, got %v", test.name, test.err,
+				err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package lnwallet
 
 import (
 	"sync"
+	"time"
 
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/roasbeef/btcd/btcec"
@@ -16,8 +17,10 @@ import (
 // funding transactions, and finally a signature for the other party's version
 // of the commitment transaction.
 type ChannelContribution struct {
-	// FundingOutpoint is the amount of funds contributed to the funding
-	// transaction.
+	// FundingAmount is the amount of funds contributed to the funding
+	// output, denominated in the channel's asset. The initial balance of
+	// the channel's initiator is its FundingAmount less the commitment
+	// fee.
 	FundingAmount btcutil.Amount
 
 	// Inputs to the funding transaction.
@@ -28,6 +31,12 @@ type ChannelContribution struct {
 	// channel capacity.
 	ChangeOutputs []*wire.TxOut
 
+	// FuelChangeOutputs are uncolored outputs returning the satoshis left
+	// over by any fuel inputs. Unlike ChangeOutputs, their values are
+	// denominated in satoshis, and they follow the OP_RETURN output of the
+	// funding transaction, so they're never assigned any asset.
+	FuelChangeOutputs []*wire.TxOut
+
 	// MultiSigKey is the the key to be used for the funding transaction's
 	// P2SH multi-sig 2-of-2 output.
 	// TODO(roasbeef): replace with CDP
@@ -48,6 +57,38 @@ type ChannelContribution struct {
 	// CsvDelay The delay (in blocks) to be used for the pay-to-self output
 	// in this party's version of the commitment transaction.
 	CsvDelay uint32
+
+	// AssetParams are the colored coins specific parameters this party
+	// requires for the channel.
+	AssetParams AssetParams
+
+	// Features is the version of the colored channel protocol proposed by
+	// the initiator, or in the case of the responder, the version selected
+	// from the initiator's proposal.
+	Features channeldb.ChannelFeatures
+}
+
+// AssetParams houses the colored coins specific channel parameters each party
+// proposes during the reservation workflow. Both sides validate the
+// parameters of the other before anything is signed, ensuring mismatched
+// color expectations are caught before any funds are committed.
+type AssetParams struct {
+	// AssetID is the identifier of the colored asset the channel is to be
+	// denominated in. Both parties MUST propose the same asset.
+	AssetID string
+
+	// DustLimit is the smallest asset amount for which this party will
+	// create an output within their commitment transaction.
+	DustLimit btcutil.Amount
+
+	// MaxHTLCValue is the largest asset amount this party will accept
+	// within a single HTLC.
+	MaxHTLCValue btcutil.Amount
+
+	// CarrierSatBudget is the number of satoshis this party is willing to
+	// spend on the dust carrier outputs, and fees of the transactions
+	// transferring the channel's assets.
+	CarrierSatBudget btcutil.Amount
 }
 
 // InputScripts represents any script inputs required to redeem a previous
@@ -96,6 +137,11 @@ type ChannelReservation struct {
 	// fundingTx is the funding transaction for this pending channel.
 	fundingTx *wire.MsgTx
 
+	// fuelChange is the uncolored output returning any fuel left over by
+	// our funding inputs, or nil if there's none. It's added to the
+	// funding transaction after it's been colorified.
+	fuelChange *wire.TxOut
+
 	// For CLTV it is nLockTime, for CSV it's nSequence, for segwit it's
 	// not needed
 	fundingLockTime uint32
@@ -122,11 +168,25 @@ type ChannelReservation struct {
 	// channel should be considered open.
 	numConfsToOpen uint16
 
+	// fundingHeight is the height of the block which included the funding
+	// transaction, once it has reached numConfsToOpen confirmations.
+	fundingHeight uint32
+
 	// A channel which will be sent on once the channel is considered
 	// 'open'. A channel is open once the funding transaction has reached
 	// a sufficient number of confirmations.
 	chanOpen chan *LightningChannel
 
+	// fundingAborted is closed once the broadcast funding transaction has
+	// been double spent via AbortFunding, signalling that the wallet
+	// should stop waiting for it to confirm.
+	fundingAborted chan struct{}
+	abortOnce      sync.Once
+
+	// stateMachine tracks the reservation's progress through the funding
+	// workflow.
+	stateMachine reservationStateMachine
+
 	wallet *LightningWallet
 }
 
@@ -134,44 +194,59 @@ type ChannelReservation struct {
 // used only internally by lnwallet. In order to concurrent safety, the creation
 // of all channel reservations should be carried out via the
 // lnwallet.InitChannelReservation interface.
+//
+// The capacity is the total value of the funding output, of which we
+// contribute fundingAmt, with the remote party expected to contribute the
+// remainder. Contributions needn't be balanced: either party may contribute
+// nothing at all. A non-zero fundingAmt marks us as the initiator of the
+// channel.
 func NewChannelReservation(capacity, fundingAmt btcutil.Amount, minFeeRate btcutil.Amount,
 	wallet *LightningWallet, id uint64, numConfs uint16) *ChannelReservation {
-	var ourBalance btcutil.Amount
-	var theirBalance btcutil.Amount
 
-	if fundingAmt == 0 {
-		ourBalance = 0
-		theirBalance = capacity - commitFee
-	} else {
-		// TODO(roasbeef): need to rework fee structure in general and
-		// also when we "unlock" dual funder within the daemon
-		if capacity == fundingAmt+commitFee { // Single funder
-			ourBalance = capacity - commitFee
-		} else {
-			ourBalance = fundingAmt - commitFee
-		}
-		theirBalance = capacity - fundingAmt - commitFee
-	}
+	ourFundingAmt := fundingAmt
+	theirFundingAmt := capacity - fundingAmt
+	ourBalance, theirBalance := initialBalances(ourFundingAmt,
+		theirFundingAmt, fundingAmt != 0)
 
 	return &ChannelReservation{
 		ourContribution: &ChannelContribution{
-			FundingAmount: ourBalance,
+			FundingAmount: ourFundingAmt,
 		},
 		theirContribution: &ChannelContribution{
-			FundingAmount: theirBalance,
+			FundingAmount: theirFundingAmt,
 		},
 		partialState: &channeldb.OpenChannel{
-			Capacity:     capacity,
-			OurBalance:   ourBalance,
-			TheirBalance: theirBalance,
-			MinFeePerKb:  minFeeRate,
-			Db:           wallet.ChannelDB,
+			AssetCapacity: capacity,
+			OurBalance:    ourBalance,
+			TheirBalance:  theirBalance,
+			MinFeePerKb:   minFeeRate,
+			Db:            wallet.ChannelDB,
 		},
 		numConfsToOpen: numConfs,
 		reservationID:  id,
 		chanOpen:       make(chan *LightningChannel, 1),
-		wallet:         wallet,
+		fundingAborted: make(chan struct{}),
+		stateMachine: reservationStateMachine{
+			state:   ReservationInitialized,
+			started: time.Now(),
+			clients: make(map[uint64]chan ReservationState),
+		},
+		wallet: wallet,
+	}
+}
+
+// initialBalances returns the settled balances of both parties within the
+// initial commitment transactions of a channel, given the amount each
+// contributed to the funding output. The commitment fee is paid by the
+// initiator of the channel, out of its own contribution.
+func initialBalances(ourFundingAmt, theirFundingAmt btcutil.Amount,
+	weInitiated bool) (btcutil.Amount, btcutil.Amount) {
+
+	if weInitiated {
+		return ourFundingAmt - commitFee, theirFundingAmt
 	}
+
+	return ourFundingAmt, theirFundingAmt - commitFee
 }
 
 // OurContribution returns the wallet's fully populated contribution to the
@@ -195,13 +270,19 @@ func (r *ChannelReservation) OurContribution() *ChannelContribution {
 func (r *ChannelReservation) ProcessContribution(theirContribution *ChannelContribution) error {
 	errChan := make(chan error, 1)
 
-	r.wallet.msgChan <- &addContributionMsg{
+	r.wallet.queues.contribution <- &addContributionMsg{
 		pendingFundingID: r.reservationID,
 		contribution:     theirContribution,
 		err:              errChan,
 	}
 
-	return <-errChan
+	if err := <-errChan; err != nil {
+		r.setState(ReservationFailed)
+		return err
+	}
+
+	r.setState(ReservationContributed)
+	return nil
 }
 
 // ProcessSingleContribution verifies, and records the initiator's contribution
@@ -211,13 +292,19 @@ func (r *ChannelReservation) ProcessContribution(theirContribution *ChannelContr
 func (r *ChannelReservation) ProcessSingleContribution(theirContribution *ChannelContribution) error {
 	errChan := make(chan error, 1)
 
-	r.wallet.msgChan <- &addSingleContributionMsg{
+	r.wallet.queues.singleContribution <- &addSingleContributionMsg{
 		pendingFundingID: r.reservationID,
 		contribution:     theirContribution,
 		err:              errChan,
 	}
 
-	return <-errChan
+	if err := <-errChan; err != nil {
+		r.setState(ReservationFailed)
+		return err
+	}
+
+	r.setState(ReservationContributed)
+	return nil
 }
 
 // TheirContribution returns the counterparty's pending contribution to the
@@ -262,19 +349,27 @@ func (r *ChannelReservation) CompleteReservation(fundingInputScripts []*InputScr
 	// TODO(roasbeef): add flag for watch or not?
 	errChan := make(chan error, 1)
 
-	r.wallet.msgChan <- &addCounterPartySigsMsg{
+	r.wallet.queues.counterPartySigs <- &addCounterPartySigsMsg{
 		pendingFundingID:         r.reservationID,
 		theirFundingInputScripts: fundingInputScripts,
 		theirCommitmentSig:       commitmentSig,
 		err:                      errChan,
 	}
 
-	return <-errChan
+	// On success, the wallet will have already progressed the
+	// reservation to the broadcast state.
+	if err := <-errChan; err != nil {
+		r.setState(ReservationFailed)
+		return err
+	}
+
+	return nil
 }
 
 // CompleteReservationSingle finalizes the pending single funder channel
 // reservation. Using the funding outpoint of the constructed funding transaction,
-// and the initiator's signature for our version of the commitment transaction,
+// the value of the funding output, and the initiator's signature for our
+// version of the commitment transaction,
 // we are able to verify the correctness of our committment transaction as
 // crafted by the initiator. Once this method returns, our signature for the
 // initiator's version of the commitment transaction is available via
@@ -282,18 +377,26 @@ func (r *ChannelReservation) CompleteReservation(fundingInputScripts []*InputScr
 // response to a single funder channel, only a commitment signature will be
 // populated.
 func (r *ChannelReservation) CompleteReservationSingle(revocationKey *btcec.PublicKey,
-	fundingPoint *wire.OutPoint, commitSig []byte) error {
+	fundingPoint *wire.OutPoint, fundingValue btcutil.Amount,
+	commitSig []byte) error {
 	errChan := make(chan error, 1)
 
-	r.wallet.msgChan <- &addSingleFunderSigsMsg{
+	r.wallet.queues.singleFunderSigs <- &addSingleFunderSigsMsg{
 		pendingFundingID:   r.reservationID,
 		revokeKey:          revocationKey,
 		fundingOutpoint:    fundingPoint,
+		fundingValue:       fundingValue,
 		theirCommitmentSig: commitSig,
 		err:                errChan,
 	}
 
-	return <-errChan
+	if err := <-errChan; err != nil {
+		r.setState(ReservationFailed)
+		return err
+	}
+
+	r.setState(ReservationSigned)
+	return nil
 }
 
 // OurSignatures returns the counterparty's signatures to all inputs to the
@@ -331,6 +434,18 @@ func (r *ChannelReservation) FundingRedeemScript() []byte {
 	return r.partialState.FundingRedeemScript
 }
 
+// FundingValue returns the value in satoshis of the funding output.
+//
+// NOTE: The value returned will only be set once the .ProcesContribution()
+// method is called in the case of the initiator of a single funder workflow,
+// and after the .CompleteReservationSingle() method is called in the case of
+// a responder to a single funder workflow.
+func (r *ChannelReservation) FundingValue() btcutil.Amount {
+	r.RLock()
+	defer r.RUnlock()
+	return r.partialState.BtcCapacity
+}
+
 // LocalCommitTx returns the commitment transaction for the local node involved
 // in this funding reservation.
 func (r *ChannelReservation) LocalCommitTx() *wire.MsgTx {
@@ -359,12 +474,17 @@ func (r *ChannelReservation) FundingOutpoint() *wire.OutPoint {
 // utilize the now freed resources.
 func (r *ChannelReservation) Cancel() error {
 	errChan := make(chan error, 1)
-	r.wallet.msgChan <- &fundingReserveCancelMsg{
+	r.wallet.queues.cancel <- &fundingReserveCancelMsg{
 		pendingFundingID: r.reservationID,
 		err:              errChan,
 	}
 
-	return <-errChan
+	if err := <-errChan; err != nil {
+		return err
+	}
+
+	r.setState(ReservationFailed)
+	return nil
 }
 
 // DispatchChan returns a channel which will be sent on once the funding
@@ -373,11 +493,44 @@ func (r *ChannelReservation) Cancel() error {
 // LightningChannel instance is returned, allowing for channel updates.
 //
 // NOTE: If this method is called before .CompleteReservation(), it will block
-// indefinitely.
+// indefinitely. If the funding transaction is replaced via AbortFunding, then
+// nil is sent instead.
 func (r *ChannelReservation) DispatchChan() <-chan *LightningChannel {
 	return r.chanOpen
 }
 
+// AbortFunding cancels a channel whose funding transaction has been broadcast,
+// but has yet to confirm, by double spending the inputs of the funding
+// transaction back to the wallet with a higher fee. This should be used in
+// the scenario that the counterparty disappears after the funding transaction
+// has been broadcast, in order to recover the colored coins which would
+// otherwise be locked within the 2-of-2 funding output indefinitely. The
+// replacement transaction is returned upon success.
+//
+// NOTE: Only channels whose funding transaction spends solely wallet
+// controlled inputs can be aborted.
+func (r *ChannelReservation) AbortFunding() (*wire.MsgTx, error) {
+	errChan := make(chan error, 1)
+	respChan := make(chan *wire.MsgTx, 1)
+	r.wallet.queues.abort <- &fundingAbortMsg{
+		reservation: r,
+		err:         errChan,
+		resp:        respChan,
+	}
+
+	return <-respChan, <-errChan
+}
+
+// abortFunding signals that the funding transaction has been replaced, and
+// marks the reservation as failed.
+func (r *ChannelReservation) abortFunding() {
+	r.abortOnce.Do(func() {
+		close(r.fundingAborted)
+	})
+
+	r.setState(ReservationFailed)
+}
+
 // FinalizeReservation completes the pending reservation, returning an active
 // open LightningChannel. This method should be called after the responder to
 // the single funder workflow receives and verifies a proof from the initiator
@@ -387,10 +540,17 @@ func (r *ChannelReservation) DispatchChan() <-chan *LightningChannel {
 // responder to an initiated single funder workflow.
 func (r *ChannelReservation) FinalizeReservation() (*LightningChannel, error) {
 	errChan := make(chan error, 1)
-	r.wallet.msgChan <- &channelOpenMsg{
+	r.wallet.queues.channelOpen <- &channelOpenMsg{
 		pendingFundingID: r.reservationID,
 		err:              errChan,
 	}
 
-	return <-r.chanOpen, <-errChan
+	channel, err := <-r.chanOpen, <-errChan
+	if err != nil || channel == nil {
+		r.setState(ReservationFailed)
+	} else {
+		r.setState(ReservationOpen)
+	}
+
+	return channel, err
 }
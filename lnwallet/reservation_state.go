@@ -0,0 +1,178 @@
+package lnwallet
+
+import (
+	"sync"
+	"time"
+)
+
+// ReservationState is an enum like structure describing how far a channel
+// reservation has progressed through the funding workflow.
+type ReservationState uint8
+
+const (
+	// ReservationInitialized indicates the wallet has allocated the
+	// resources for the reservation, but the counterparty's contribution
+	// has yet to be processed.
+	ReservationInitialized ReservationState = iota
+
+	// ReservationContributed indicates both parties' contributions have
+	// been exchanged, and processed.
+	ReservationContributed
+
+	// ReservationSigned indicates all signatures for the funding
+	// transaction, and both commitment transactions have been exchanged,
+	// and verified.
+	ReservationSigned
+
+	// ReservationBroadcast indicates the funding transaction has been
+	// broadcast.
+	ReservationBroadcast
+
+	// ReservationConfirming indicates the funding transaction is waiting
+	// to reach the required number of confirmations.
+	ReservationConfirming
+
+	// ReservationOpen indicates the channel is fully open. This is a
+	// terminal state.
+	ReservationOpen
+
+	// ReservationFailed indicates the funding workflow failed, or the
+	// reservation was cancelled. This is a terminal state.
+	ReservationFailed
+
+	// numReservationStates is the total number of reservation states.
+	numReservationStates
+)
+
+// String returns a human readable version of the ReservationState.
+func (s ReservationState) String() string {
+	switch s {
+	case ReservationInitialized:
+		return "Initialized"
+	case ReservationContributed:
+		return "Contributed"
+	case ReservationSigned:
+		return "Signed"
+	case ReservationBroadcast:
+		return "Broadcast"
+	case ReservationConfirming:
+		return "Confirming"
+	case ReservationOpen:
+		return "Open"
+	case ReservationFailed:
+		return "Failed"
+	default:
+		return "Unknown"
+	}
+}
+
+// isTerminal returns true if no further state transitions can follow the
+// state.
+func (s ReservationState) isTerminal() bool {
+	return s == ReservationOpen || s == ReservationFailed
+}
+
+// ReservationStateSubscription delivers each state transition of a channel
+// reservation. The Updates channel is closed once the reservation reaches a
+// terminal state, or the subscription is cancelled.
+type ReservationStateSubscription struct {
+	// Updates is sent upon each time the reservation transitions to a new
+	// state.
+	Updates <-chan ReservationState
+
+	// Cancel removes the subscription, closing the Updates channel.
+	Cancel func()
+}
+
+// reservationStateMachine tracks the current state of a channel reservation,
+// dispatching each transition to all active subscribers. A distinct mutex from
+// the reservation's is used, as transitions occur while the reservation's
+// mutex is held.
+type reservationStateMachine struct {
+	sync.Mutex
+
+	state   ReservationState
+	started time.Time
+
+	clients      map[uint64]chan ReservationState
+	nextClientID uint64
+}
+
+// State returns the current state of the reservation within the funding
+// workflow.
+func (r *ChannelReservation) State() ReservationState {
+	r.stateMachine.Lock()
+	defer r.stateMachine.Unlock()
+
+	return r.stateMachine.state
+}
+
+// SubscribeState returns a subscription which is sent upon each time the
+// reservation transitions to a new state. If the reservation has already
+// reached a terminal state, then the returned Updates channel is already
+// closed.
+func (r *ChannelReservation) SubscribeState() *ReservationStateSubscription {
+	sm := &r.stateMachine
+	sm.Lock()
+	defer sm.Unlock()
+
+	// Each state can only be entered once, so a buffer large enough to
+	// hold every state ensures dispatching never blocks, nor drops an
+	// update.
+	updates := make(chan ReservationState, numReservationStates)
+	if sm.state.isTerminal() {
+		close(updates)
+		return &ReservationStateSubscription{
+			Updates: updates,
+			Cancel:  func() {},
+		}
+	}
+
+	clientID := sm.nextClientID
+	sm.nextClientID++
+	sm.clients[clientID] = updates
+
+	return &ReservationStateSubscription{
+		Updates: updates,
+		Cancel: func() {
+			sm.Lock()
+			defer sm.Unlock()
+
+			if c, ok := sm.clients[clientID]; ok {
+				delete(sm.clients, clientID)
+				close(c)
+			}
+		},
+	}
+}
+
+// setState transitions the reservation to the passed state, notifying all
+// subscribers. The workflow only ever progresses forward, so transitions to a
+// prior state, or any transition after a terminal state has been reached, are
+// ignored.
+func (r *ChannelReservation) setState(state ReservationState) {
+	sm := &r.stateMachine
+	sm.Lock()
+	defer sm.Unlock()
+
+	if state <= sm.state || sm.state.isTerminal() {
+		return
+	}
+
+	walletLog.Debugf("ChannelReservation(%v) transitioning from %v to %v",
+		r.reservationID, sm.state, state)
+
+	sm.state = state
+	if state.isTerminal() {
+		reservationDuration.ObserveSince(sm.started, state.String())
+	}
+
+	for clientID, client := range sm.clients {
+		client <- state
+
+		if state.isTerminal() {
+			delete(sm.clients, clientID)
+			close(client)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package lnwallet
+
+import "testing"
+
+// TestReservationStateSubscription tests that subscribers to a reservation's
+// state receive each forward transition, and that the subscription is closed
+// once the reservation reaches a terminal state.
+func TestReservationStateSubscription(t *testing.T) {
+	res := &ChannelReservation{
+		stateMachine: reservationStateMachine{
+			state:   ReservationInitialized,
+			clients: make(map[uint64]chan ReservationState),
+		},
+	}
+
+	sub := res.SubscribeState()
+	cancelledSub := res.SubscribeState()
+	cancelledSub.Cancel()
+	if _, ok := <-cancelledSub.Updates; ok {
+		t.Fatalf("cancelled subscription should be closed")
+	}
+
+	res.setState(ReservationContributed)
+	res.setState(ReservationSigned)
+
+	// A transition to a prior state should be ignored.
+	res.setState(ReservationContributed)
+	if res.State() != ReservationSigned {
+		t.Fatalf("expected state %v, instead have %v",
+			ReservationSigned, res.State())
+	}
+
+	res.setState(ReservationBroadcast)
+	res.setState(ReservationConfirming)
+	res.setState(ReservationOpen)
+
+	// Once the reservation is open, a failure should no longer be
+	// possible.
+	res.setState(ReservationFailed)
+
+	expectedStates := []ReservationState{
+		ReservationContributed,
+		ReservationSigned,
+		ReservationBroadcast,
+		ReservationConfirming,
+		ReservationOpen,
+	}
+	for _, expected := range expectedStates {
+		state, ok := <-sub.Updates
+		if !ok {
+			t.Fatalf("subscription closed early")
+		}
+		if state != expected {
+			t.Fatalf("expected state %v, instead have %v", expected,
+				state)
+		}
+	}
+	if _, ok := <-sub.Updates; ok {
+		t.Fatalf("subscription should be closed after terminal state")
+	}
+
+	// Subscribing after the reservation has reached a terminal state
+	// should return a closed subscription.
+	if _, ok := <-res.SubscribeState().Updates; ok {
+		t.Fatalf("subscription should be closed after terminal state")
+	}
+}
+
+// TestReservationAbortFunding tests that aborting a reservation's funding
+// signals any goroutine waiting on the funding transaction, and fails the
+// reservation, even if aborted more than once.
+func TestReservationAbortFunding(t *testing.T) {
+	res := &ChannelReservation{
+		fundingAborted: make(chan struct{}),
+		stateMachine: reservationStateMachine{
+			state:   ReservationBroadcast,
+			clients: make(map[uint64]chan ReservationState),
+		},
+	}
+
+	res.abortFunding()
+	res.abortFunding()
+
+	select {
+	case <-res.fundingAborted:
+	default:
+		t.Fatalf("funding abort wasn't signalled")
+	}
+	if res.State() != ReservationFailed {
+		t.Fatalf("expected state %v, instead have %v",
+			ReservationFailed, res.State())
+	}
+}
@@ -0,0 +1,74 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/roasbeef/btcutil"
+)
+
+// TestReservationBalances tests that the contributions of both parties to a
+// reservation, and their initial balances, properly reflect asymmetric
+// channels, with the commitment fee paid by the initiator.
+func TestReservationBalances(t *testing.T) {
+	const capacity = btcutil.Amount(1000)
+
+	testCases := []struct {
+		name       string
+		fundingAmt btcutil.Amount
+
+		ourFunding, theirFunding btcutil.Amount
+		ourBalance, theirBalance btcutil.Amount
+	}{
+		{
+			name:         "single funder initiator",
+			fundingAmt:   capacity,
+			ourFunding:   capacity,
+			ourBalance:   capacity - commitFee,
+			theirBalance: 0,
+		},
+		{
+			name:         "single funder responder",
+			theirFunding: capacity,
+			theirBalance: capacity - commitFee,
+		},
+		{
+			name:         "asymmetric dual funder",
+			fundingAmt:   300,
+			ourFunding:   300,
+			theirFunding: 700,
+			ourBalance:   300 - commitFee,
+			theirBalance: 700,
+		},
+	}
+	for _, testCase := range testCases {
+		res := NewChannelReservation(capacity, testCase.fundingAmt, 0,
+			&LightningWallet{}, 0, 1)
+
+		switch {
+		case res.ourContribution.FundingAmount != testCase.ourFunding:
+			t.Fatalf("%v: expected our contribution of %v, got %v",
+				testCase.name, testCase.ourFunding,
+				res.ourContribution.FundingAmount)
+
+		case res.theirContribution.FundingAmount != testCase.theirFunding:
+			t.Fatalf("%v: expected their contribution of %v, got %v",
+				testCase.name, testCase.theirFunding,
+				res.theirContribution.FundingAmount)
+
+		case res.partialState.OurBalance != testCase.ourBalance:
+			t.Fatalf("%v: expected our balance of %v, got %v",
+				testCase.name, testCase.ourBalance,
+				res.partialState.OurBalance)
+
+		case res.partialState.TheirBalance != testCase.theirBalance:
+			t.Fatalf("%v: expected their balance of %v, got %v",
+				testCase.name, testCase.theirBalance,
+				res.partialState.TheirBalance)
+
+		case res.partialState.AssetCapacity != capacity:
+			t.Fatalf("%v: expected capacity of %v, got %v",
+				testCase.name, capacity,
+				res.partialState.AssetCapacity)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package lnwallet
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// retransmission is an outbound CommitSignature or CommitRevocation which
+// hasn't yet been acknowledged by the remote party. Until acknowledged, the
+// message is persisted within the channel's retransmission queue, so it can
+// be sent again should the connection be lost before it was received.
+type retransmission struct {
+	// seq is the sequence number the message was assigned within the
+	// channel's persistent retransmission queue.
+	seq uint64
+
+	// height is the commitment height the message pertains to. For a
+	// CommitSignature this is the height of the signed remote commitment,
+	// while for a CommitRevocation it's the height of the revoked local
+	// commitment.
+	height uint64
+
+	msg lnwire.Message
+}
+
+// loadRetransmissions restores the channel's retransmission queue from disk.
+func (lc *LightningChannel) loadRetransmissions() error {
+	queue, err := lc.channelState.FetchRetransmissions()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range queue {
+		_, msg, _, err := lnwire.ReadMessage(bytes.NewReader(entry.Msg),
+			0, 0)
+		if err != nil {
+			return err
+		}
+
+		lc.retransmissions = append(lc.retransmissions, &retransmission{
+			seq:    entry.Seq,
+			height: entry.Height,
+			msg:    msg,
+		})
+	}
+
+	return nil
+}
+
+// TrackRetransmission records an outbound CommitSignature or CommitRevocation
+// within the channel's persistent retransmission queue. It should be called
+// directly after the message is generated, before it's sent to the remote
+// party. The message remains queued until the remote party acknowledges it,
+// either by revoking the signed commitment, or by reporting its receipt
+// during the reestablishment of a connection.
+func (lc *LightningChannel) TrackRetransmission(msg lnwire.Message) error {
+	var height uint64
+	switch msg.(type) {
+	case *lnwire.CommitSignature:
+		height = lc.remoteCommitChain.tip().height
+
+	// Once our local commitment has been revoked, the remote party can
+	// only recover a single missing revocation, so a new revocation
+	// supersedes any older ones still queued.
+	case *lnwire.CommitRevocation:
+		height = lc.currentHeight - 1
+		err := lc.ackRetransmissions(func(r *retransmission) bool {
+			_, ok := r.msg.(*lnwire.CommitRevocation)
+			return ok
+		})
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unable to retransmit %T", msg)
+	}
+
+	var b bytes.Buffer
+	if _, err := lnwire.WriteMessage(&b, msg, 0, 0); err != nil {
+		return err
+	}
+	seq, err := lc.channelState.AddRetransmission(height, b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	lc.retransmissions = append(lc.retransmissions, &retransmission{
+		seq:    seq,
+		height: height,
+		msg:    msg,
+	})
+
+	return nil
+}
+
+// ackRetransmissions removes each queued message for which the passed
+// predicate returns true from the channel's retransmission queue.
+func (lc *LightningChannel) ackRetransmissions(acked func(*retransmission) bool) error {
+	var pending []*retransmission
+	for _, r := range lc.retransmissions {
+		if !acked(r) {
+			pending = append(pending, r)
+			continue
+		}
+
+		if err := lc.channelState.AckRetransmission(r.seq); err != nil {
+			return err
+		}
+	}
+
+	lc.retransmissions = pending
+	return nil
+}
+
+// numRemoteRevocations returns the number of revocations we've received for
+// the remote party's prior commitment transactions.
+func (lc *LightningChannel) numRemoteRevocations() uint64 {
+	remoteElkrem := lc.channelState.RemoteElkrem
+	if _, err := remoteElkrem.AtIndex(0); err != nil {
+		return 0
+	}
+
+	return remoteElkrem.UpTo() + 1
+}
+
+// ReestablishMsg returns the CommitReestablish message which should be sent
+// to the remote party as soon as a connection is established, before any
+// other state update messages.
+func (lc *LightningChannel) ReestablishMsg() (*lnwire.CommitReestablish, error) {
+	revocation, err := lc.channelState.LocalElkrem.AtIndex(lc.currentHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	theirCommitKey := lc.channelState.TheirCommitKey
+
+	msg := lnwire.NewCommitReestablish()
+	msg.ChannelPoint = lc.channelState.ChanID
+	msg.LocalHeight = lc.currentHeight
+	msg.RemoteRevocations = lc.numRemoteRevocations()
+	msg.RevocationKey = DeriveRevocationPubkey(theirCommitKey, revocation[:])
+	msg.RevocationHash = fastsha256.Sum256(revocation[:])
+
+	return msg, nil
+}
+
+// ProcessReestablish processes the CommitReestablish message sent by the
+// remote party upon connection. Any queued messages the remote party has
+// received are acknowledged, while the remaining ones are returned in the
+// order they must be retransmitted. A CommitSignature whose commitment is no
+// longer within our view of the remote commitment chain is stale, as the
+// updates it covered weren't locked in, so it's dropped rather than
+// retransmitted.
+func (lc *LightningChannel) ProcessReestablish(msg *lnwire.CommitReestablish) ([]lnwire.Message, error) {
+	// If the remote party has revoked a commitment which we never received
+	// the revocation for, then its retransmission must rotate in the
+	// revocation key of their current commitment. As our queue of used
+	// revocations doesn't survive a restart, we take the key from the
+	// message.
+	numRevocations := lc.numRemoteRevocations()
+	switch {
+	case msg.LocalHeight == numRevocations+1:
+		lc.lostRevocation = &lnwire.CommitRevocation{
+			ChannelPoint:       lc.channelState.ChanID,
+			NextRevocationKey:  msg.RevocationKey,
+			NextRevocationHash: msg.RevocationHash,
+		}
+
+	case msg.LocalHeight > numRevocations+1:
+		return nil, fmt.Errorf("remote party is at height %v, yet "+
+			"only %v revocations were received", msg.LocalHeight,
+			numRevocations)
+	}
+
+	remoteTip := lc.remoteCommitChain.tip().height
+	var resend []lnwire.Message
+	err := lc.ackRetransmissions(func(r *retransmission) bool {
+		switch r.msg.(type) {
+		case *lnwire.CommitSignature:
+			if r.height <= msg.LocalHeight || r.height > remoteTip {
+				return true
+			}
+		case *lnwire.CommitRevocation:
+			if r.height < msg.RemoteRevocations {
+				return true
+			}
+		}
+
+		resend = append(resend, r.msg)
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	walletLog.Debugf("ChannelPoint(%v): reestablished at local_height=%v, "+
+		"remote_revocations=%v, retransmitting %v messages",
+		lc.channelState.ChanID, msg.LocalHeight, msg.RemoteRevocations,
+		len(resend))
+
+	return resend, nil
+}
@@ -0,0 +1,148 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestRevocationRetransmission tests that a revocation lost along with a
+// connection is retransmitted once the connection is reestablished, allowing
+// both sides to resume updating the channel.
+func TestRevocationRetransmission(t *testing.T) {
+	aliceChannel, bobChannel, cleanUp, err := createTestChannels(3)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	if err := aliceChannel.channelState.FullSync(); err != nil {
+		t.Fatalf("unable to sync alice's channel: %v", err)
+	}
+	if err := bobChannel.channelState.FullSync(); err != nil {
+		t.Fatalf("unable to sync bob's channel: %v", err)
+	}
+
+	// Alice signs a new commitment for Bob, who accepts it and revokes his
+	// prior commitment. The revocation never reaches Alice however.
+	rawSig, logIndex, err := aliceChannel.SignNextCommitment()
+	if err != nil {
+		t.Fatalf("unable to sign commitment: %v", err)
+	}
+	sig, err := btcec.ParseSignature(rawSig, btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to parse sig: %v", err)
+	}
+	commitSig := &lnwire.CommitSignature{
+		ChannelPoint: aliceChannel.ChannelPoint(),
+		CommitSig:    sig,
+		LogIndex:     logIndex,
+	}
+	if err := aliceChannel.TrackRetransmission(commitSig); err != nil {
+		t.Fatalf("unable to track sig: %v", err)
+	}
+	if err := bobChannel.ReceiveNewCommitment(rawSig, logIndex); err != nil {
+		t.Fatalf("bob unable to receive commitment: %v", err)
+	}
+	revocation, err := bobChannel.RevokeCurrentCommitment()
+	if err != nil {
+		t.Fatalf("bob unable to revoke commitment: %v", err)
+	}
+	if err := bobChannel.TrackRetransmission(revocation); err != nil {
+		t.Fatalf("unable to track revocation: %v", err)
+	}
+
+	// Reload both channels from disk to simulate the reconnection.
+	id := wire.ShaHash(testHdSeed)
+	aliceChannels, err := aliceChannel.channelState.Db.FetchOpenChannels(&id)
+	if err != nil {
+		t.Fatalf("unable to fetch channel: %v", err)
+	}
+	bobChannels, err := bobChannel.channelState.Db.FetchOpenChannels(&id)
+	if err != nil {
+		t.Fatalf("unable to fetch channel: %v", err)
+	}
+	notifier := aliceChannel.channelEvents
+	aliceChannelNew, err := NewLightningChannel(aliceChannel.signer, nil,
+		notifier, aliceChannels[0])
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+	bobChannelNew, err := NewLightningChannel(bobChannel.signer, nil,
+		notifier, bobChannels[0])
+	if err != nil {
+		t.Fatalf("unable to create new channel: %v", err)
+	}
+	if len(aliceChannelNew.retransmissions) != 1 ||
+		len(bobChannelNew.retransmissions) != 1 {
+		t.Fatalf("retransmission queues not restored")
+	}
+	if err := initRevocationWindows(aliceChannelNew, bobChannelNew, 3); err != nil {
+		t.Fatalf("unable to init revocation windows: %v", err)
+	}
+
+	aliceReestablish, err := aliceChannelNew.ReestablishMsg()
+	if err != nil {
+		t.Fatalf("unable to create reestablish msg: %v", err)
+	}
+	bobReestablish, err := bobChannelNew.ReestablishMsg()
+	if err != nil {
+		t.Fatalf("unable to create reestablish msg: %v", err)
+	}
+
+	// Bob received Alice's signature, so she has nothing to retransmit,
+	// while Bob should retransmit his revocation.
+	aliceResend, err := aliceChannelNew.ProcessReestablish(bobReestablish)
+	if err != nil {
+		t.Fatalf("alice unable to process reestablish: %v", err)
+	}
+	if len(aliceResend) != 0 {
+		t.Fatalf("alice shouldn't retransmit, instead got %v msgs",
+			len(aliceResend))
+	}
+	bobResend, err := bobChannelNew.ProcessReestablish(aliceReestablish)
+	if err != nil {
+		t.Fatalf("bob unable to process reestablish: %v", err)
+	}
+	if len(bobResend) != 1 {
+		t.Fatalf("expected bob to retransmit 1 msg, instead got %v",
+			len(bobResend))
+	}
+	resentRevocation, ok := bobResend[0].(*lnwire.CommitRevocation)
+	if !ok {
+		t.Fatalf("expected revocation, got %T", bobResend[0])
+	}
+	if _, err := aliceChannelNew.ReceiveRevocation(resentRevocation); err != nil {
+		t.Fatalf("alice unable to receive revocation: %v", err)
+	}
+
+	// With the revocation received, both sides should be able to carry
+	// out a new state transition.
+	if err := forceStateTransition(aliceChannelNew, bobChannelNew); err != nil {
+		t.Fatalf("unable to complete state transition: %v", err)
+	}
+
+	// Once reconnected again, Alice reports the revocation as received,
+	// emptying Bob's queue.
+	aliceReestablish, err = aliceChannelNew.ReestablishMsg()
+	if err != nil {
+		t.Fatalf("unable to create reestablish msg: %v", err)
+	}
+	bobResend, err = bobChannelNew.ProcessReestablish(aliceReestablish)
+	if err != nil {
+		t.Fatalf("bob unable to process reestablish: %v", err)
+	}
+	if len(bobResend) != 0 || len(bobChannelNew.retransmissions) != 0 {
+		t.Fatalf("bob's queue should be empty")
+	}
+	queue, err := bobChannelNew.channelState.FetchRetransmissions()
+	if err != nil {
+		t.Fatalf("unable to fetch retransmissions: %v", err)
+	}
+	if len(queue) != 0 {
+		t.Fatalf("bob's persisted queue should be empty, has %v entries",
+			len(queue))
+	}
+}
@@ -0,0 +1,127 @@
+package lnwallet
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/roasbeef/btcutil"
+)
+
+// RoundingPolicy determines how a fractional amount of an asset, such as one
+// resulting from a proportional fee, or the conversion between two assets, is
+// rounded to a whole number of the asset's base units. An asset's base unit
+// is the smallest amount representable given its divisibility, so anything
+// below it can't be transferred on-chain.
+type RoundingPolicy uint8
+
+const (
+	// RoundDown rounds each amount down to a whole number of base units,
+	// with the remainder kept by the sender.
+	RoundDown RoundingPolicy = iota
+
+	// AccumulateRemainder rounds each amount down to a whole number of
+	// base units, but accumulates the remainders. Once the accumulated
+	// remainder adds up to a whole base unit, it's added to the next
+	// amount rounded, so the amounts rounded add up to the exact total
+	// over time.
+	AccumulateRemainder
+)
+
+// String returns the name of the rounding policy, as accepted by
+// ParseRoundingPolicy.
+func (p RoundingPolicy) String() string {
+	switch p {
+	case RoundDown:
+		return "rounddown"
+	case AccumulateRemainder:
+		return "accumulate"
+	default:
+		return fmt.Sprintf("RoundingPolicy(%d)", uint8(p))
+	}
+}
+
+// ParseRoundingPolicy returns the rounding policy of the passed name.
+func ParseRoundingPolicy(name string) (RoundingPolicy, error) {
+	switch name {
+	case "rounddown":
+		return RoundDown, nil
+	case "accumulate":
+		return AccumulateRemainder, nil
+	default:
+		return 0, fmt.Errorf("unknown rounding policy %q, must be one "+
+			"of rounddown, accumulate", name)
+	}
+}
+
+// DivisibilityScale returns the factor which converts an amount of base units
+// of an asset with fromDivisibility decimal places, into base units of an
+// asset with toDivisibility decimal places, given their whole units are of
+// equal worth.
+func DivisibilityScale(fromDivisibility, toDivisibility int) *big.Rat {
+	shift := toDivisibility - fromDivisibility
+	if shift < 0 {
+		shift = -shift
+	}
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(shift)), nil)
+
+	if toDivisibility < fromDivisibility {
+		return new(big.Rat).SetFrac(big.NewInt(1), pow)
+	}
+	return new(big.Rat).SetInt(pow)
+}
+
+// AmountRounder rounds fractional amounts of a single asset to whole base
+// units according to its RoundingPolicy. An AmountRounder is safe for
+// concurrent use, though the remainder accumulated is shared by all callers.
+type AmountRounder struct {
+	sync.Mutex
+
+	policy RoundingPolicy
+
+	// remainder is the accumulated fractional amount not yet rounded
+	// into an amount. It's always below a single base unit.
+	remainder *big.Rat
+}
+
+// NewAmountRounder creates a new AmountRounder following the passed policy.
+func NewAmountRounder(policy RoundingPolicy) *AmountRounder {
+	return &AmountRounder{
+		policy:    policy,
+		remainder: new(big.Rat),
+	}
+}
+
+// Round returns the passed fractional amount, in base units of the asset,
+// rounded according to the rounder's policy. Negative amounts are rounded to
+// zero.
+func (r *AmountRounder) Round(amt *big.Rat) btcutil.Amount {
+	if amt.Sign() <= 0 {
+		return 0
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	exact := new(big.Rat).Set(amt)
+	if r.policy == AccumulateRemainder {
+		exact.Add(exact, r.remainder)
+	}
+
+	whole := new(big.Int).Quo(exact.Num(), exact.Denom())
+	if r.policy == AccumulateRemainder {
+		r.remainder.Sub(exact, new(big.Rat).SetInt(whole))
+	}
+
+	return btcutil.Amount(whole.Int64())
+}
+
+// Remainder returns the fractional amount, in base units of the asset,
+// accumulated by the rounder which has yet to be rounded into an amount.
+// Rounders following the RoundDown policy never accumulate a remainder.
+func (r *AmountRounder) Remainder() *big.Rat {
+	r.Lock()
+	defer r.Unlock()
+
+	return new(big.Rat).Set(r.remainder)
+}
@@ -0,0 +1,91 @@
+package lnwallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/roasbeef/btcutil"
+)
+
+// TestAmountRounder tests that fractional amounts are rounded down under
+// both rounding policies, with the remainders only accumulated, and later
+// paid out, under the accumulate policy.
+func TestAmountRounder(t *testing.T) {
+	for _, name := range []string{"rounddown", "accumulate"} {
+		policy, err := ParseRoundingPolicy(name)
+		if err != nil {
+			t.Fatalf("unable to parse policy %v: %v", name, err)
+		}
+		if policy.String() != name {
+			t.Fatalf("policy %v parsed as %v", name, policy)
+		}
+	}
+	if _, err := ParseRoundingPolicy("roundup"); err == nil {
+		t.Fatalf("unknown policy parsed")
+	}
+
+	// Each amount is two and two fifths of a base unit.
+	amt := big.NewRat(12, 5)
+
+	testCases := []struct {
+		policy   RoundingPolicy
+		expected []btcutil.Amount
+	}{
+		{RoundDown, []btcutil.Amount{2, 2, 2, 2, 2}},
+		{AccumulateRemainder, []btcutil.Amount{2, 2, 3, 2, 3}},
+	}
+	for _, testCase := range testCases {
+		rounder := NewAmountRounder(testCase.policy)
+		var total btcutil.Amount
+		for i, expected := range testCase.expected {
+			rounded := rounder.Round(amt)
+			if rounded != expected {
+				t.Fatalf("%v #%v: expected %v, got %v",
+					testCase.policy, i, expected, rounded)
+			}
+			total += rounded
+		}
+
+		// After five amounts of 2.4 base units, the accumulated
+		// amounts add up to the exact total of 12.
+		remainder := rounder.Remainder()
+		switch testCase.policy {
+		case RoundDown:
+			if remainder.Sign() != 0 {
+				t.Fatalf("remainder %v accumulated", remainder)
+			}
+		case AccumulateRemainder:
+			if total != 12 || remainder.Sign() != 0 {
+				t.Fatalf("expected total of 12 without "+
+					"remainder, got %v with %v", total,
+					remainder)
+			}
+		}
+	}
+
+	rounded := NewAmountRounder(RoundDown).Round(big.NewRat(-1, 2))
+	if rounded != 0 {
+		t.Fatalf("negative amount rounded to %v", rounded)
+	}
+}
+
+// TestDivisibilityScale tests the conversion of base units between assets of
+// differing divisibility.
+func TestDivisibilityScale(t *testing.T) {
+	testCases := []struct {
+		from, to int
+		expected *big.Rat
+	}{
+		{2, 2, big.NewRat(1, 1)},
+		{2, 5, big.NewRat(1000, 1)},
+		{8, 0, big.NewRat(1, 100000000)},
+	}
+	for _, testCase := range testCases {
+		scale := DivisibilityScale(testCase.from, testCase.to)
+		if scale.Cmp(testCase.expected) != 0 {
+			t.Fatalf("scale from %v to %v decimal places: expected "+
+				"%v, got %v", testCase.from, testCase.to,
+				testCase.expected, scale)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package lnwallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// maxHTLCScriptCacheSize is the number of HTLC scripts cached by a channel
+// before the cache is flushed. This is enough to hold the scripts of every
+// commitment in flight within both commitment chains.
+const maxHTLCScriptCacheSize = 2 * (DefaultMaxRevocationWindow + 1) *
+	maxCommitHTLCs
+
+// htlcScriptKey identifies an HTLC script by all inputs to its generation
+// which vary across HTLCs and commitments. The commitment keys of both sides
+// remain fixed throughout the lifetime of the channel.
+type htlcScriptKey struct {
+	ourCommit  bool
+	isIncoming bool
+	revocation [32]byte
+	rHash      PaymentHash
+	timeout    uint32
+	delay      uint32
+}
+
+// cachedHTLCScripts is a cached HTLC witness script, along with the p2wsh
+// output script paying to it.
+type cachedHTLCScripts struct {
+	witnessScript []byte
+	pkScript      []byte
+}
+
+// htlcScriptCache caches the scripts of the HTLC outputs of recently
+// constructed commitments. The scripts of a commitment are generated again
+// should its construction be retried, and once it's revoked in order to
+// recognize the outputs we're able to sweep.
+type htlcScriptCache map[htlcScriptKey]*cachedHTLCScripts
+
+// htlcScripts returns the witness script of an HTLC output on either our
+// commitment transaction or the remote party's, along with the p2wsh output
+// script paying to it. The returned scripts MUST NOT be modified.
+func (lc *LightningChannel) htlcScripts(ourCommit bool,
+	paymentDesc *PaymentDescriptor, revocation [32]byte, delay uint32,
+	isIncoming bool) ([]byte, []byte, error) {
+
+	key := htlcScriptKey{
+		ourCommit:  ourCommit,
+		isIncoming: isIncoming,
+		revocation: revocation,
+		rHash:      paymentDesc.RHash,
+		timeout:    paymentDesc.Timeout,
+		delay:      delay,
+	}
+	lc.htlcScriptMtx.Lock()
+	defer lc.htlcScriptMtx.Unlock()
+
+	if scripts, ok := lc.htlcScriptCache[key]; ok {
+		return scripts.witnessScript, scripts.pkScript, nil
+	}
+
+	witnessScript, err := lc.genHtlcScript(ourCommit, paymentDesc,
+		revocation, delay, isIncoming)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkScript, err := witnessScriptHash(witnessScript)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(lc.htlcScriptCache) >= maxHTLCScriptCacheSize {
+		lc.htlcScriptCache = make(htlcScriptCache)
+	}
+	lc.htlcScriptCache[key] = &cachedHTLCScripts{
+		witnessScript: witnessScript,
+		pkScript:      pkScript,
+	}
+
+	return witnessScript, pkScript, nil
+}
+
+// commitSigHashCache caches the portion of the BIP 143 sighash midstate which
+// remains the same across the commitment transactions of a channel. As each
+// commitment spends the funding output alone, the hashes of the previous
+// outpoints and sequence numbers only need to be computed once, leaving only
+// the hash of the outputs to be computed for each new commitment.
+type commitSigHashCache struct {
+	sync.Mutex
+
+	valid        bool
+	prevOut      wire.OutPoint
+	sequence     uint32
+	hashPrevOuts wire.ShaHash
+	hashSequence wire.ShaHash
+}
+
+// sigHashes returns the sighash midstate of the passed commitment
+// transaction. Transactions which don't spend the same single input as the
+// cached midstate have their midstate computed from scratch, which is then
+// cached in turn.
+func (c *commitSigHashCache) sigHashes(commitTx *wire.MsgTx) *txscript.TxSigHashes {
+	if len(commitTx.TxIn) != 1 {
+		return txscript.NewTxSigHashes(commitTx)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	txIn := commitTx.TxIn[0]
+	if !c.valid || txIn.PreviousOutPoint != c.prevOut ||
+		txIn.Sequence != c.sequence {
+
+		hashes := txscript.NewTxSigHashes(commitTx)
+
+		c.valid = true
+		c.prevOut = txIn.PreviousOutPoint
+		c.sequence = txIn.Sequence
+		c.hashPrevOuts = hashes.HashPrevOuts
+		c.hashSequence = hashes.HashSequence
+
+		return hashes
+	}
+
+	return &txscript.TxSigHashes{
+		HashPrevOuts: c.hashPrevOuts,
+		HashSequence: c.hashSequence,
+		HashOutputs:  calcHashOutputs(commitTx),
+	}
+}
+
+// calcHashOutputs computes the BIP 143 hash of all the outputs of the passed
+// transaction.
+func calcHashOutputs(tx *wire.MsgTx) wire.ShaHash {
+	var b bytes.Buffer
+	for _, txOut := range tx.TxOut {
+		var value [8]byte
+		binary.LittleEndian.PutUint64(value[:], uint64(txOut.Value))
+		b.Write(value[:])
+		wire.WriteVarBytes(&b, 0, txOut.PkScript)
+	}
+
+	hash := fastsha256.Sum256(b.Bytes())
+	return wire.ShaHash(fastsha256.Sum256(hash[:]))
+}
@@ -0,0 +1,85 @@
+package lnwallet
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestCommitSigHashCache tests that the sighash midstate returned by the
+// cache always matches the one computed from scratch, both for successive
+// commitments spending the same input, and once the input changes.
+func TestCommitSigHashCache(t *testing.T) {
+	_, pub := btcec.PrivKeyFromBytes(btcec.S256(), testWalletPrivKey)
+
+	fundingTxIn := wire.NewTxIn(&wire.OutPoint{Index: 0}, nil, nil)
+	otherTxIn := wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil)
+
+	var cache commitSigHashCache
+	for i, txIn := range []*wire.TxIn{fundingTxIn, fundingTxIn, otherTxIn,
+		otherTxIn, fundingTxIn} {
+
+		amt := btcutil.Amount(1e8 + i)
+		commitTx, err := CreateCommitTx(txIn, pub, pub, pub, 5, amt, amt)
+		if err != nil {
+			t.Fatalf("unable to create commitment: %v", err)
+		}
+
+		cached := cache.sigHashes(commitTx)
+		expected := txscript.NewTxSigHashes(commitTx)
+		if *cached != *expected {
+			t.Fatalf("commitment #%v: cached sighashes %v don't "+
+				"match %v", i, cached, expected)
+		}
+	}
+}
+
+// TestHTLCScriptCache tests that the HTLC scripts returned by the cache match
+// those generated from scratch.
+func TestHTLCScriptCache(t *testing.T) {
+	aliceChannel, _, cleanUp, err := createTestChannels(1)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	htlc := &PaymentDescriptor{
+		RHash:   PaymentHash{1},
+		Timeout: 10,
+	}
+	for _, revocation := range [][32]byte{{1}, {2}, {1}} {
+		for _, ourCommit := range []bool{true, false} {
+			witnessScript, pkScript, err := aliceChannel.htlcScripts(
+				ourCommit, htlc, revocation, 5, true)
+			if err != nil {
+				t.Fatalf("unable to fetch htlc scripts: %v", err)
+			}
+
+			expectedScript, err := aliceChannel.genHtlcScript(
+				ourCommit, htlc, revocation, 5, true)
+			if err != nil {
+				t.Fatalf("unable to gen htlc script: %v", err)
+			}
+			expectedPkScript, err := witnessScriptHash(expectedScript)
+			if err != nil {
+				t.Fatalf("unable to gen p2wsh script: %v", err)
+			}
+
+			if !bytes.Equal(witnessScript, expectedScript) ||
+				!bytes.Equal(pkScript, expectedPkScript) {
+				t.Fatalf("cached scripts don't match for "+
+					"revocation=%x, our_commit=%v",
+					revocation[:1], ourCommit)
+			}
+		}
+	}
+
+	if len(aliceChannel.htlcScriptCache) != 4 {
+		t.Fatalf("expected 4 cached scripts, found %v",
+			len(aliceChannel.htlcScriptCache))
+	}
+}
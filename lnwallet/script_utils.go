@@ -2,13 +2,9 @@ package lnwallet
 
 import (
 	"bytes"
-	"crypto/sha256"
 	"fmt"
-	"math/big"
 
-	"golang.org/x/crypto/hkdf"
-
-	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/lnwallet/keychain"
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
@@ -26,12 +22,7 @@ var (
 // witnessScriptHash generates a pay-to-witness-script-hash public key script
 // paying to a version 0 witness program paying to the passed redeem script.
 func witnessScriptHash(redeemScript []byte) ([]byte, error) {
-	bldr := txscript.NewScriptBuilder()
-
-	bldr.AddOp(txscript.OP_0)
-	scriptHash := fastsha256.Sum256(redeemScript)
-	bldr.AddData(scriptHash[:])
-	return bldr.Script()
+	return keychain.WitnessScriptHash(redeemScript)
 }
 
 // genMultiSigScript generates the non-p2sh'd multisig script for 2 of 2
@@ -668,93 +659,96 @@ func commitSpendNoDelay(commitScript []byte, outputAmt btcutil.Amount,
 	return wire.TxWitness(witness), nil
 }
 
-// DeriveRevocationPubkey derives the revocation public key given the
-// counter-party's commitment key, and revocation pre-image derived via a
-// pseudo-random-function. In the event that we (for some reason) broadcast a
-// revoked commitment transaction, then if the other party knows the revocation
-// pre-image, then they'll be able to derive the corresponding private key to
-// this private key by exploting the homomorphism in the elliptic curve group:
-//    * https://en.wikipedia.org/wiki/Group_homomorphism#Homomorphisms_of_abelian_groups
-//
-// The derivation is performed as follows:
+// anchorScript constructs the witness script for an anchor output attached to
+// the commitment transaction. Each party has a dedicated anchor which can be
+// spent immediately using their key, allowing either side to fee bump a
+// broadcast commitment transaction via CPFP. Once the anchor has matured by
+// anchorCsvDelay blocks, anyone may sweep it, ensuring the dust created by
+// unused anchors is eventually cleaned up from the UTXO set.
 //
-//   revokeKey := commitKey + revokePoint
-//             := G*k + G*h
-//             := G * (k+h)
-//
-// Therefore, once we divulge the revocation pre-image, the remote peer is able to
-// compute the proper private key for the revokeKey by computing:
-//   revokePriv := commitPriv + revokePreimge mod N
+// Possible Input Scripts:
+//     OWNER:  <sig>
+//     ANYONE: <emptyvector> (after anchorCsvDelay)
 //
-// Where N is the order of the sub-group.
+// Output Script:
+//     <key> OP_CHECKSIG OP_IFDUP
+//     OP_NOTIF
+//         <anchorCsvDelay> OP_CHECKSEQUENCEVERIFY
+//     OP_ENDIF
+func anchorScript(key *btcec.PublicKey) ([]byte, error) {
+	builder := txscript.NewScriptBuilder()
+
+	// If a valid signature from the owner of the anchor is presented, then
+	// the true result is duplicated and the spend succeeds immediately.
+	builder.AddData(key.SerializeCompressed())
+	builder.AddOp(txscript.OP_CHECKSIG)
+	builder.AddOp(txscript.OP_IFDUP)
+
+	// Otherwise, anyone may sweep the anchor once the relative delay has
+	// passed.
+	builder.AddOp(txscript.OP_NOTIF)
+	builder.AddInt64(anchorCsvDelay)
+	builder.AddOp(OP_CHECKSEQUENCEVERIFY)
+	builder.AddOp(txscript.OP_ENDIF)
+
+	return builder.Script()
+}
+
+// CommitSpendAnchor constructs a valid witness allowing the owner of an anchor
+// output to spend it immediately, in order to fee bump the commitment
+// transaction via CPFP.
+func CommitSpendAnchor(signer Signer, signDesc *SignDescriptor,
+	sweepTx *wire.MsgTx) (wire.TxWitness, error) {
+
+	sweepSig, err := signer.SignOutputRaw(sweepTx, signDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	witnessStack := wire.TxWitness(make([][]byte, 2))
+	witnessStack[0] = append(sweepSig, byte(txscript.SigHashAll))
+	witnessStack[1] = signDesc.RedeemScript
+
+	return witnessStack, nil
+}
+
+// CommitSpendAnchorAnyone constructs a witness allowing any party to sweep an
+// anchor output once it has matured. The input spending the anchor must have
+// its sequence number set to reflect anchorCsvDelay.
+func CommitSpendAnchorAnyone(anchorScript []byte) wire.TxWitness {
+	witnessStack := wire.TxWitness(make([][]byte, 2))
+	witnessStack[0] = nil
+	witnessStack[1] = anchorScript
+
+	return witnessStack
+}
+
+// DeriveRevocationPubkey derives the revocation public key given the
+// counter-party's commitment key, and revocation pre-image. See
+// keychain.DeriveRevocationPubkey for the details of the derivation.
 func DeriveRevocationPubkey(commitPubKey *btcec.PublicKey,
 	revokePreimage []byte) *btcec.PublicKey {
 
-	// First we need to convert the revocation hash into a point on the
-	// elliptic curve.
-	revokePointX, revokePointY := btcec.S256().ScalarBaseMult(revokePreimage)
-
-	// Now that we have the revocation point, we add this to their commitment
-	// public key in order to obtain the revocation public key.
-	revokeX, revokeY := btcec.S256().Add(commitPubKey.X, commitPubKey.Y,
-		revokePointX, revokePointY)
-	return &btcec.PublicKey{X: revokeX, Y: revokeY}
+	return keychain.DeriveRevocationPubkey(commitPubKey, revokePreimage)
 }
 
 // DeriveRevocationPrivKey derives the revocation private key given a node's
 // commitment private key, and the pre-image to a previously seen revocation
-// hash. Using this derived private key, a node is able to claim the output
-// within the commitment transaction of a node in the case that they broadcast
-// a previously revoked commitment transaction.
-//
-// The private key is derived as follwos:
-//   revokePriv := commitPriv + revokePreimage mod N
-//
-// Where N is the order of the sub-group.
+// hash. See keychain.DeriveRevocationPrivKey for the details of the
+// derivation.
 func DeriveRevocationPrivKey(commitPrivKey *btcec.PrivateKey,
 	revokePreimage []byte) *btcec.PrivateKey {
 
-	// Convert the revocation pre-image into a scalar value so we can
-	// manipulate it within the curve's defined finite field.
-	revokeScalar := new(big.Int).SetBytes(revokePreimage)
-
-	// To derive the revocation private key, we simply add the revocation
-	// pre-image to the commitment private key.
-	//
-	// This works since:
-	//  P = G*a + G*b
-	//    = G*(a+b)
-	//    = G*p
-	revokePriv := revokeScalar.Add(revokeScalar, commitPrivKey.D)
-	revokePriv = revokePriv.Mod(revokePriv, btcec.S256().N)
-
-	privRevoke, _ := btcec.PrivKeyFromBytes(btcec.S256(), revokePriv.Bytes())
-	return privRevoke
+	return keychain.DeriveRevocationPrivKey(commitPrivKey, revokePreimage)
 }
 
 // deriveElkremRoot derives an elkrem root unique to a channel given the
 // private key for our public key in the 2-of-2 multi-sig, and the remote
-// node's multi-sig public key. The root is derived using the HKDF[1][2]
-// instantiated with sha-256. The secret data used is our multi-sig private
-// key, with the salt being the remote node's public key.
-//
-// [1]: https://eprint.iacr.org/2010/264.pdf
-// [2]: https://tools.ietf.org/html/rfc5869
+// node's multi-sig public key, via keychain.DeriveElkremRoot.
 func deriveElkremRoot(elkremDerivationRoot *btcec.PrivateKey,
 	localMultiSigKey *btcec.PublicKey,
 	remoteMultiSigKey *btcec.PublicKey) wire.ShaHash {
 
-	secret := elkremDerivationRoot.Serialize()
-	salt := localMultiSigKey.SerializeCompressed()
-	info := remoteMultiSigKey.SerializeCompressed()
-
-	rootReader := hkdf.New(sha256.New, secret, salt, info)
-
-	// It's safe to ignore the error her as we know for sure that we won't
-	// be draining the HKDF past its available entropy horizon.
-	// TODO(roasbeef): revisit...
-	var elkremRoot wire.ShaHash
-	rootReader.Read(elkremRoot[:])
-
-	return elkremRoot
+	return keychain.DeriveElkremRoot(elkremDerivationRoot, localMultiSigKey,
+		remoteMultiSigKey)
 }
@@ -149,6 +149,111 @@ func TestCommitmentSpendValidation(t *testing.T) {
 	}
 }
 
+// TestAnchorSpendValidation tests the spendability of the anchor outputs
+// attached to a commitment transaction.
+//
+// The following spending cases are covered by this test:
+//   * Alice's immediate spend of her own anchor output.
+//   * A third party's spend of Alice's anchor output once it has matured.
+//   * A third party's premature spend of Alice's anchor output, which should
+//     be rejected.
+func TestAnchorSpendValidation(t *testing.T) {
+	aliceKeyPriv, aliceKeyPub := btcec.PrivKeyFromBytes(btcec.S256(),
+		testWalletPrivKey)
+	_, bobKeyPub := btcec.PrivKeyFromBytes(btcec.S256(), bobsPrivKey)
+
+	commitTx := wire.NewMsgTx()
+	commitTx.Version = 2
+	commitTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: testHdSeed}, nil, nil))
+	if err := addCommitAnchors(commitTx, aliceKeyPub, bobKeyPub); err != nil {
+		t.Fatalf("unable to add anchors: %v", err)
+	}
+
+	// Both parties must arrive at the same anchor ordering regardless of
+	// whose commitment transaction is being constructed.
+	bobCommitTx := wire.NewMsgTx()
+	if err := addCommitAnchors(bobCommitTx, bobKeyPub, aliceKeyPub); err != nil {
+		t.Fatalf("unable to add anchors: %v", err)
+	}
+	for i := range commitTx.TxOut {
+		if !bytes.Equal(commitTx.TxOut[i].PkScript,
+			bobCommitTx.TxOut[i].PkScript) {
+			t.Fatalf("anchor #%v differs between commitments", i)
+		}
+	}
+
+	aliceAnchorScript, err := anchorScript(aliceKeyPub)
+	if err != nil {
+		t.Fatalf("unable to create anchor script: %v", err)
+	}
+	aliceAnchorPkScript, err := witnessScriptHash(aliceAnchorScript)
+	if err != nil {
+		t.Fatalf("unable to create anchor pkscript: %v", err)
+	}
+	found, anchorIndex := FindScriptOutputIndex(commitTx,
+		aliceAnchorPkScript)
+	if !found {
+		t.Fatalf("alice's anchor not found within commitment")
+	}
+
+	targetOutput, err := commitScriptUnencumbered(aliceKeyPub)
+	if err != nil {
+		t.Fatalf("unable to create target output: %v", err)
+	}
+	sweepTx := wire.NewMsgTx()
+	sweepTx.Version = 2
+	sweepTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+		Hash:  commitTx.TxSha(),
+		Index: anchorIndex,
+	}, nil, nil))
+	sweepTx.AddTxOut(&wire.TxOut{
+		PkScript: targetOutput,
+		Value:    int64(anchorSize) / 2,
+	})
+
+	checkSpend := func(witness wire.TxWitness) error {
+		sweepTx.TxIn[0].Witness = witness
+		vm, err := txscript.NewEngine(aliceAnchorPkScript, sweepTx, 0,
+			txscript.StandardVerifyFlags, nil, nil,
+			int64(anchorSize))
+		if err != nil {
+			t.Fatalf("unable to create engine: %v", err)
+		}
+		return vm.Execute()
+	}
+
+	// First, Alice should be able to spend her anchor immediately.
+	signDesc := &SignDescriptor{
+		RedeemScript: aliceAnchorScript,
+		SigHashes:    txscript.NewTxSigHashes(sweepTx),
+		Output: &wire.TxOut{
+			Value: int64(anchorSize),
+		},
+		HashType:   txscript.SigHashAll,
+		InputIndex: 0,
+	}
+	aliceSpend, err := CommitSpendAnchor(&mockSigner{aliceKeyPriv},
+		signDesc, sweepTx)
+	if err != nil {
+		t.Fatalf("unable to generate anchor spend witness: %v", err)
+	}
+	if err := checkSpend(aliceSpend); err != nil {
+		t.Fatalf("alice's anchor spend is invalid: %v", err)
+	}
+
+	// Without a signature, the anchor can't be spent until it's matured.
+	anyoneSpend := CommitSpendAnchorAnyone(aliceAnchorScript)
+	if err := checkSpend(anyoneSpend); err == nil {
+		t.Fatalf("premature anchor sweep should be invalid")
+	}
+
+	// Once the anchor has matured, anyone should be able to sweep it.
+	sweepTx.TxIn[0].Sequence = lockTimeToSequence(false, anchorCsvDelay)
+	if err := checkSpend(anyoneSpend); err != nil {
+		t.Fatalf("matured anchor sweep is invalid: %v", err)
+	}
+}
+
 // TestRevocationKeyDerivation tests that given a public key, and a revocation
 // hash, the homomorphic revocation public and private key derivation work
 // properly.
@@ -0,0 +1,292 @@
+package lnwallet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// maxMerkleBranchLen is the largest number of hashes we'll accept within the
+// merkle branch of an SpvProof. This bounds the number of transactions within
+// the proven block to 2^32, well beyond what's possible.
+const maxMerkleBranchLen = 32
+
+var (
+	// ErrInvalidSpvProof is returned when the merkle branch of an SpvProof
+	// doesn't commit to the target transaction within the block header.
+	ErrInvalidSpvProof = errors.New("spv proof doesn't commit to the " +
+		"transaction")
+
+	// ErrSpvProofStaleHeader is returned when the block header of an
+	// SpvProof isn't part of our main chain.
+	ErrSpvProofStaleHeader = errors.New("spv proof block header isn't " +
+		"within the main chain")
+
+	// ErrSpvProofInsufficientConfs is returned when the block proven by an
+	// SpvProof hasn't yet been buried under enough blocks.
+	ErrSpvProofInsufficientConfs = errors.New("spv proof block has too " +
+		"few confirmations")
+)
+
+// SpvProof proves the inclusion of a transaction within a block of the main
+// chain. It consists of the header of the block, along with the merkle branch
+// connecting the transaction to the merkle root committed to within the
+// header. As the verifier checks the header against its own view of the
+// chain, it need not trust the party presenting the proof, nor download the
+// entire block.
+type SpvProof struct {
+	// BlockHeight is the height of the block including the transaction.
+	BlockHeight uint32
+
+	// Header is the header of the block including the transaction.
+	Header wire.BlockHeader
+
+	// TxIndex is the index of the transaction within the block.
+	TxIndex uint32
+
+	// MerkleBranch is the list of sibling hashes from the transaction up
+	// to, but excluding, the merkle root.
+	MerkleBranch []wire.ShaHash
+}
+
+// hashMerkleBranches returns the hash of the merkle tree node with the passed
+// children.
+func hashMerkleBranches(left, right *wire.ShaHash) wire.ShaHash {
+	var sha [wire.HashSize * 2]byte
+	copy(sha[:wire.HashSize], left[:])
+	copy(sha[wire.HashSize:], right[:])
+
+	return wire.DoubleSha256SH(sha[:])
+}
+
+// NewSpvProof creates an SpvProof of the inclusion of the target transaction
+// within the passed block, found at the passed height.
+func NewSpvProof(block *wire.MsgBlock, height uint32,
+	txid *wire.ShaHash) (*SpvProof, error) {
+
+	level := make([]wire.ShaHash, len(block.Transactions))
+	txIndex := -1
+	for i, tx := range block.Transactions {
+		level[i] = tx.TxSha()
+		if level[i] == *txid {
+			txIndex = i
+		}
+	}
+	if txIndex == -1 {
+		return nil, fmt.Errorf("tx %v not found within block %v", txid,
+			block.BlockSha())
+	}
+
+	proof := &SpvProof{
+		BlockHeight: height,
+		Header:      block.Header,
+		TxIndex:     uint32(txIndex),
+	}
+
+	// Walk up the tree a level at a time, recording the sibling of the
+	// node on the path to the root. A node lacking a sibling is hashed
+	// with itself.
+	for index := txIndex; len(level) > 1; index /= 2 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		proof.MerkleBranch = append(proof.MerkleBranch, level[index^1])
+
+		next := make([]wire.ShaHash, len(level)/2)
+		for i := range next {
+			next[i] = hashMerkleBranches(&level[2*i], &level[2*i+1])
+		}
+		level = next
+	}
+
+	return proof, nil
+}
+
+// Verify ensures the SpvProof proves the inclusion of the target transaction
+// within a block of our main chain, as reported by the passed BlockChainIO,
+// which has been buried under at least numConfs blocks, counting itself.
+func (p *SpvProof) Verify(bio BlockChainIO, txid *wire.ShaHash,
+	numConfs uint32) error {
+
+	// First, connect the transaction to the merkle root. A right child is
+	// never a duplicate of its left sibling, as only a final left child
+	// is hashed with itself. Rejecting such branches guards against the
+	// ambiguity of CVE-2012-2459.
+	hash := *txid
+	index := p.TxIndex
+	for _, sibling := range p.MerkleBranch {
+		sibling := sibling
+		if index&1 == 0 {
+			hash = hashMerkleBranches(&hash, &sibling)
+		} else {
+			if sibling == hash {
+				return ErrInvalidSpvProof
+			}
+			hash = hashMerkleBranches(&sibling, &hash)
+		}
+		index >>= 1
+	}
+	if index != 0 || hash != p.Header.MerkleRoot {
+		return ErrInvalidSpvProof
+	}
+
+	// With the transaction committed to by the header, ensure the header
+	// is part of our main chain, rather than taking the peer's word for it.
+	mainHash, err := bio.GetBlockHash(int64(p.BlockHeight))
+	if err != nil {
+		return err
+	}
+	if p.Header.BlockSha() != *mainHash {
+		return ErrSpvProofStaleHeader
+	}
+
+	bestHeight, err := bio.GetCurrentHeight()
+	if err != nil {
+		return err
+	}
+	if bestHeight < int32(p.BlockHeight) ||
+		uint32(bestHeight)-p.BlockHeight+1 < numConfs {
+
+		return ErrSpvProofInsufficientConfs
+	}
+
+	return nil
+}
+
+// Encode serializes the SpvProof into the passed io.Writer.
+func (p *SpvProof) Encode(w io.Writer) error {
+	var scratch [4]byte
+	binary.BigEndian.PutUint32(scratch[:], p.BlockHeight)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if err := p.Header.Serialize(w); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint32(scratch[:], p.TxIndex)
+	if _, err := w.Write(scratch[:]); err != nil {
+		return err
+	}
+
+	if err := wire.WriteVarInt(w, 0, uint64(len(p.MerkleBranch))); err != nil {
+		return err
+	}
+	for _, hash := range p.MerkleBranch {
+		if _, err := w.Write(hash[:]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Decode deserializes an SpvProof from the passed io.Reader.
+func (p *SpvProof) Decode(r io.Reader) error {
+	var scratch [4]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	p.BlockHeight = binary.BigEndian.Uint32(scratch[:])
+
+	if err := p.Header.Deserialize(r); err != nil {
+		return err
+	}
+
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	p.TxIndex = binary.BigEndian.Uint32(scratch[:])
+
+	branchLen, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	if branchLen > maxMerkleBranchLen {
+		return fmt.Errorf("merkle branch of %v hashes exceeds max of %v",
+			branchLen, maxMerkleBranchLen)
+	}
+	p.MerkleBranch = nil
+	for i := uint64(0); i < branchLen; i++ {
+		var hash wire.ShaHash
+		if _, err := io.ReadFull(r, hash[:]); err != nil {
+			return err
+		}
+		p.MerkleBranch = append(p.MerkleBranch, hash)
+	}
+
+	return nil
+}
+
+// FundingProof returns the serialized SpvProof of the inclusion of the
+// confirmed funding transaction within the main chain, to be presented to
+// the responder of a single funder workflow.
+//
+// NOTE: This method will only succeed once the channel has been dispatched
+// over the DispatchChan.
+func (r *ChannelReservation) FundingProof() ([]byte, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	if r.fundingTx == nil || r.fundingHeight == 0 {
+		return nil, fmt.Errorf("funding transaction not yet confirmed")
+	}
+
+	bio := r.wallet.chainIO
+	blockHash, err := bio.GetBlockHash(int64(r.fundingHeight))
+	if err != nil {
+		return nil, err
+	}
+	block, err := bio.GetBlock(blockHash)
+	if err != nil {
+		return nil, err
+	}
+
+	txid := r.fundingTx.TxSha()
+	proof, err := NewSpvProof(block, r.fundingHeight, &txid)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := proof.Encode(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// VerifyFundingProof verifies the serialized SpvProof presented by the
+// initiator of a single funder workflow, ensuring the funding transaction has
+// been included within the main chain, and buried under the number of
+// confirmations required to open the channel. Once verified, the height of
+// the block proven is recorded, allowing the reservation's FundingBundle to
+// be generated.
+func (r *ChannelReservation) VerifyFundingProof(rawProof []byte) error {
+	r.Lock()
+	defer r.Unlock()
+
+	fundingPoint := r.partialState.FundingOutpoint
+	if fundingPoint == nil {
+		return fmt.Errorf("funding outpoint not yet known")
+	}
+
+	proof := &SpvProof{}
+	if err := proof.Decode(bytes.NewReader(rawProof)); err != nil {
+		return fmt.Errorf("unable to decode spv proof: %v", err)
+	}
+
+	err := proof.Verify(r.wallet.chainIO, &fundingPoint.Hash,
+		uint32(r.numConfsToOpen))
+	if err != nil {
+		return err
+	}
+
+	r.fundingHeight = proof.BlockHeight
+	return nil
+}
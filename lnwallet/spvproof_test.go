@@ -0,0 +1,131 @@
+package lnwallet
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// newTestBlock creates a block with the passed number of distinct
+// transactions, committing to them within its merkle root.
+func newTestBlock(numTxns int, nonce uint32) *wire.MsgBlock {
+	block := &wire.MsgBlock{
+		Header: wire.BlockHeader{Nonce: nonce},
+	}
+
+	level := make([]wire.ShaHash, numTxns)
+	for i := 0; i < numTxns; i++ {
+		tx := wire.NewMsgTx()
+		tx.LockTime = nonce<<16 | uint32(i)
+		block.AddTransaction(tx)
+		level[i] = tx.TxSha()
+	}
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([]wire.ShaHash, len(level)/2)
+		for i := range next {
+			next[i] = hashMerkleBranches(&level[2*i], &level[2*i+1])
+		}
+		level = next
+	}
+	block.Header.MerkleRoot = level[0]
+
+	return block
+}
+
+// TestSpvProof tests that an SpvProof may be created, and verified for each
+// transaction within blocks of varying sizes, and that proofs for blocks
+// outside the main chain, or with too few confirmations, are rejected.
+func TestSpvProof(t *testing.T) {
+	chain := &mockChainIO{}
+	for i := 0; i < 8; i++ {
+		chain.blocks = append(chain.blocks, newTestBlock(i+1, uint32(i)))
+	}
+
+	for height, block := range chain.blocks {
+		for _, tx := range block.Transactions {
+			txid := tx.TxSha()
+			proof, err := NewSpvProof(block, uint32(height), &txid)
+			if err != nil {
+				t.Fatalf("unable to create proof: %v", err)
+			}
+
+			// The proof should survive a round trip through its
+			// serialization.
+			var b bytes.Buffer
+			if err := proof.Encode(&b); err != nil {
+				t.Fatalf("unable to encode proof: %v", err)
+			}
+			decoded := &SpvProof{}
+			if err := decoded.Decode(&b); err != nil {
+				t.Fatalf("unable to decode proof: %v", err)
+			}
+			if !reflect.DeepEqual(proof, decoded) {
+				t.Fatalf("proof doesn't match after decoding: "+
+					"expected %v, got %v", proof, decoded)
+			}
+
+			numConfs := uint32(len(chain.blocks) - height)
+			if err := decoded.Verify(chain, &txid, numConfs); err != nil {
+				t.Fatalf("height %v: valid proof rejected: %v",
+					height, err)
+			}
+
+			err = decoded.Verify(chain, &txid, numConfs+1)
+			if err != ErrSpvProofInsufficientConfs {
+				t.Fatalf("expected ErrSpvProofInsufficientConfs, "+
+					"got %v", err)
+			}
+		}
+	}
+
+	// A proof for a transaction it doesn't commit to should be rejected.
+	block := chain.blocks[5]
+	txid := block.Transactions[2].TxSha()
+	proof, err := NewSpvProof(block, 5, &txid)
+	if err != nil {
+		t.Fatalf("unable to create proof: %v", err)
+	}
+	otherTxid := block.Transactions[3].TxSha()
+	if err := proof.Verify(chain, &otherTxid, 1); err != ErrInvalidSpvProof {
+		t.Fatalf("expected ErrInvalidSpvProof, got %v", err)
+	}
+
+	// Neither should a proof claiming a height the block isn't found at.
+	proof.BlockHeight = 4
+	if err := proof.Verify(chain, &txid, 1); err != ErrSpvProofStaleHeader {
+		t.Fatalf("expected ErrSpvProofStaleHeader, got %v", err)
+	}
+
+	// Nor a proof for a block outside the main chain.
+	staleBlock := newTestBlock(3, 100)
+	txid = staleBlock.Transactions[0].TxSha()
+	proof, err = NewSpvProof(staleBlock, 2, &txid)
+	if err != nil {
+		t.Fatalf("unable to create proof: %v", err)
+	}
+	if err := proof.Verify(chain, &txid, 1); err != ErrSpvProofStaleHeader {
+		t.Fatalf("expected ErrSpvProofStaleHeader, got %v", err)
+	}
+
+	// Finally, the final transaction of a block with an odd number of
+	// transactions is hashed with itself, but a branch presenting a
+	// right child as the duplicate of its sibling should be rejected.
+	block = chain.blocks[2]
+	txid = block.Transactions[2].TxSha()
+	proof, err = NewSpvProof(block, 2, &txid)
+	if err != nil {
+		t.Fatalf("unable to create proof: %v", err)
+	}
+	if err := proof.Verify(chain, &txid, 1); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	proof.TxIndex = 3
+	if err := proof.Verify(chain, &txid, 1); err != ErrInvalidSpvProof {
+		t.Fatalf("expected ErrInvalidSpvProof, got %v", err)
+	}
+}
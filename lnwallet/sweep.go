@@ -0,0 +1,143 @@
+package lnwallet
+
+import (
+	"errors"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// ErrNoSweepInputs is returned when a sweep transaction is requested without
+// any outputs to sweep.
+var ErrNoSweepInputs = errors.New("no outputs to sweep")
+
+// SweepInput is a single output of a commitment transaction to be swept
+// after the channel has been closed on-chain, along with the amount of the
+// channel's asset assigned to it.
+type SweepInput struct {
+	// OutPoint is the output being swept.
+	OutPoint wire.OutPoint
+
+	// Value is the amount of satoshis carried by the output. For colored
+	// commitments, this is typically the dust carrier amount.
+	Value btcutil.Amount
+
+	// AssetAmt is the amount of the channel's asset assigned to the
+	// output by the transfer instructions of the transaction creating it.
+	AssetAmt btcutil.Amount
+
+	// Sequence is the sequence number of the input spending the output.
+	Sequence uint32
+
+	// WitnessSize is the size in bytes of the witness spending the
+	// output, beyond that of the witness spending a p2wkh output. It's
+	// used to estimate the fee of the sweep transaction.
+	WitnessSize int
+}
+
+// ColoredSweep is an unsigned transaction sweeping a set of outputs into a
+// single output. The asset amounts assigned to the swept outputs are
+// re-encoded as a transfer instruction to the sweep output, as spending a
+// colored output without doing so burns its asset.
+type ColoredSweep struct {
+	// Tx is the colorified sweep transaction. The sweep output is its
+	// first output, followed by the OP_RETURN output, and the carrier
+	// satoshis left over, if any.
+	Tx *wire.MsgTx
+
+	// AssetAmt is the total amount of the channel's asset swept.
+	AssetAmt btcutil.Amount
+
+	// CarrierAmt is the total amount of satoshis carried by the swept
+	// outputs.
+	CarrierAmt btcutil.Amount
+
+	// Fee is the fee in satoshis paid by the sweep transaction out of the
+	// carrier satoshis. If negative, then the swept outputs carry too few
+	// satoshis to fund the dust carrier of the sweep output, and the
+	// transaction must be fueled.
+	Fee btcutil.Amount
+
+	// MinFee is the fee in satoshis due at the fee rate the sweep
+	// transaction was built for.
+	MinFee btcutil.Amount
+
+	// Instructions is the set of colored coins transfer instructions
+	// encoded within the OP_RETURN output of the sweep transaction.
+	Instructions []lndcc.Instruction
+}
+
+// BuildColoredSweep builds an unsigned transaction sweeping the passed
+// outputs to sweepPkScript. The total asset amount of the swept outputs is
+// transferred to the sweep output, which is itself given a dust carrier
+// amount, while the remaining carrier satoshis pay for the transaction's
+// fees. The inputs of the transaction are in the order of the passed outputs,
+// so each may be signed for by its index.
+//
+// If feeRate, in sat/vbyte, is non-zero, then only the fee at that rate is
+// paid, with the carrier satoshis left over returned to sweepPkScript within
+// an uncolored output following the OP_RETURN output. Otherwise, or if the
+// left over satoshis would be dust, then all the carrier satoshis are paid as
+// fees.
+func BuildColoredSweep(inputs []*SweepInput, sweepPkScript []byte,
+	feeRate uint64) (*ColoredSweep, error) {
+
+	if len(inputs) == 0 {
+		return nil, ErrNoSweepInputs
+	}
+
+	// The sweep transaction is version 2, as the inputs spending
+	// time-locked outputs must enforce their relative lock-time.
+	sweepTx := wire.NewMsgTx()
+	sweepTx.Version = 2
+
+	var carrierAmt, assetAmt btcutil.Amount
+	for _, input := range inputs {
+		sweepTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: input.OutPoint,
+			Sequence:         input.Sequence,
+		})
+
+		carrierAmt += input.Value
+		assetAmt += input.AssetAmt
+	}
+	sweepTx.AddTxOut(wire.NewTxOut(int64(assetAmt), sweepPkScript))
+
+	// @CC: re-encode the sweep output's value as a transfer instruction,
+	// replacing the value of the output itself with a dust amount.
+	coloredTx, err := colorifyWithRetry(sweepTx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	vsize := estimateVSize(len(coloredTx.TxIn), len(coloredTx.TxOut))
+	for _, input := range inputs {
+		vsize += (input.WitnessSize + 3) / 4
+	}
+
+	sweep := &ColoredSweep{
+		Tx:         coloredTx,
+		AssetAmt:   assetAmt,
+		CarrierAmt: carrierAmt,
+		Fee:        carrierAmt - btcutil.Amount(coloredTx.TxOut[0].Value),
+		MinFee:     btcutil.Amount(uint64(vsize) * feeRate),
+		Instructions: []lndcc.Instruction{
+			{Output: 0, Amount: int(assetAmt)},
+		},
+	}
+
+	// If a fee rate was given, then return the carrier satoshis in excess
+	// of the fee. As the output follows the OP_RETURN output, it isn't
+	// assigned any asset.
+	if feeRate != 0 {
+		change := sweep.Fee - sweep.MinFee
+		if change >= fuelChangeDustLimit {
+			coloredTx.AddTxOut(wire.NewTxOut(int64(change),
+				sweepPkScript))
+			sweep.Fee -= change
+		}
+	}
+
+	return sweep, nil
+}
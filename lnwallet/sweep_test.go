@@ -0,0 +1,110 @@
+package lnwallet
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestBuildColoredSweep tests that a sweep transaction transfers the total
+// asset amount of the swept outputs to the sweep output, returning the excess
+// carrier satoshis only when a fee rate is given.
+func TestBuildColoredSweep(t *testing.T) {
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = lndcc.EncodeTransfer
+
+	sweepPkScript := make([]byte, 22)
+	inputs := []*SweepInput{
+		{
+			OutPoint: wire.OutPoint{Index: 2},
+			Value:    5000,
+			AssetAmt: 700,
+			Sequence: 144,
+		},
+		{
+			OutPoint:    wire.OutPoint{Index: 0},
+			Value:       5000,
+			AssetAmt:    300,
+			Sequence:    wire.MaxTxInSequenceNum,
+			WitnessSize: 100,
+		},
+	}
+
+	_, err := BuildColoredSweep(nil, sweepPkScript, 0)
+	if err != ErrNoSweepInputs {
+		t.Fatalf("expected ErrNoSweepInputs, got %v", err)
+	}
+
+	sweep, err := BuildColoredSweep(inputs, sweepPkScript, 0)
+	if err != nil {
+		t.Fatalf("unable to build sweep: %v", err)
+	}
+	if sweep.Tx.Version != 2 {
+		t.Fatalf("expected version 2, got %v", sweep.Tx.Version)
+	}
+	for i, input := range inputs {
+		txIn := sweep.Tx.TxIn[i]
+		if txIn.PreviousOutPoint != input.OutPoint ||
+			txIn.Sequence != input.Sequence {
+			t.Fatalf("input %v doesn't spend %v", i, input.OutPoint)
+		}
+	}
+	if len(sweep.Tx.TxOut) != 2 {
+		t.Fatalf("expected 2 outputs, got %v", len(sweep.Tx.TxOut))
+	}
+	if sweep.AssetAmt != 1000 || sweep.CarrierAmt != 10000 {
+		t.Fatalf("sweep of %v carried by %v, expected 1000 carried by "+
+			"10000", sweep.AssetAmt, sweep.CarrierAmt)
+	}
+	dustAmt := btcutil.Amount(sweep.Tx.TxOut[0].Value)
+	if sweep.Fee != sweep.CarrierAmt-dustAmt {
+		t.Fatalf("fee %v doesn't pay all the carrier satoshis", sweep.Fee)
+	}
+
+	insts := lndcc.DecodeTransfer(sweep.Tx)
+	expectedInsts := []lndcc.Instruction{{Output: 0, Amount: 1000}}
+	if !reflect.DeepEqual(insts, expectedInsts) ||
+		!reflect.DeepEqual(sweep.Instructions, expectedInsts) {
+		t.Fatalf("expected instructions %v, got %v encoding %v",
+			expectedInsts, sweep.Instructions, insts)
+	}
+
+	// With a fee rate, the carrier satoshis in excess of the fee are
+	// returned within an uncolored output following the OP_RETURN output.
+	sweep, err = BuildColoredSweep(inputs, sweepPkScript, 10)
+	if err != nil {
+		t.Fatalf("unable to build sweep: %v", err)
+	}
+	if len(sweep.Tx.TxOut) != 3 {
+		t.Fatalf("expected 3 outputs, got %v", len(sweep.Tx.TxOut))
+	}
+	opReturn := sweep.Tx.TxOut[1].PkScript
+	if txscript.GetScriptClass(opReturn) != txscript.NullDataTy {
+		t.Fatalf("change output doesn't follow the OP_RETURN output")
+	}
+	if sweep.Fee != sweep.MinFee {
+		t.Fatalf("expected fee %v, got %v", sweep.MinFee, sweep.Fee)
+	}
+	change := btcutil.Amount(sweep.Tx.TxOut[2].Value)
+	if dustAmt+change+sweep.Fee != sweep.CarrierAmt {
+		t.Fatalf("outputs and fee don't add up to the carrier amount")
+	}
+
+	// Outputs carrying less than the sweep output's dust carrier leave a
+	// negative fee, which must be made up for with fuel.
+	sweep, err = BuildColoredSweep([]*SweepInput{{Value: 100}},
+		sweepPkScript, 10)
+	if err != nil {
+		t.Fatalf("unable to build sweep: %v", err)
+	}
+	if sweep.Fee >= 0 || len(sweep.Tx.TxOut) != 2 {
+		t.Fatalf("expected negative fee without change, got %v with "+
+			"%v outputs", sweep.Fee, len(sweep.Tx.TxOut))
+	}
+}
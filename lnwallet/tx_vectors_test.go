@@ -0,0 +1,288 @@
+package lnwallet
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// txVectorsFile holds the golden test vectors for the commitment and close
+// transactions. Both parties to a channel construct these transactions
+// independently, so any change to the resulting transactions breaks
+// compatibility with peers running prior versions, and must be deliberate.
+const txVectorsFile = "testdata/tx_vectors.json"
+
+// updateTxVectors rewrites the expected transactions within the golden test
+// vectors with those currently constructed. This should only be used along
+// with a deliberate change to the protocol:
+//
+//	go test -run=TestTxVectors -updatetxvectors
+var updateTxVectors = flag.Bool("updatetxvectors", false,
+	"rewrite the expected transactions within "+txVectorsFile)
+
+// htlcVector is an active HTLC within a commitment test vector.
+type htlcVector struct {
+	Incoming    bool   `json:"incoming"`
+	Amount      int64  `json:"amount"`
+	PaymentHash string `json:"payment_hash"`
+	Timeout     uint32 `json:"timeout"`
+}
+
+// commitVector is a golden test vector for a colorified commitment
+// transaction, from the point of view of the local node. The balances are
+// those prior to adding the HTLCs.
+type commitVector struct {
+	Name            string       `json:"name"`
+	RemoteChain     bool         `json:"remote_chain"`
+	FundingTxid     string       `json:"funding_txid"`
+	FundingIndex    uint32       `json:"funding_index"`
+	LocalCommitKey  string       `json:"local_commit_key"`
+	RemoteCommitKey string       `json:"remote_commit_key"`
+	RevocationKey   string       `json:"revocation_key"`
+	RevocationHash  string       `json:"revocation_hash"`
+	LocalCsvDelay   uint32       `json:"local_csv_delay"`
+	RemoteCsvDelay  uint32       `json:"remote_csv_delay"`
+	LocalBalance    int64        `json:"local_balance"`
+	RemoteBalance   int64        `json:"remote_balance"`
+	HTLCs           []htlcVector `json:"htlcs"`
+	ExpectedTx      string       `json:"expected_tx"`
+	ExpectedPayload string       `json:"expected_payload"`
+}
+
+// closeVector is a golden test vector for a colorified cooperative close
+// transaction.
+type closeVector struct {
+	Name                 string `json:"name"`
+	FundingTxid          string `json:"funding_txid"`
+	FundingIndex         uint32 `json:"funding_index"`
+	FundingValue         int64  `json:"funding_value"`
+	LocalBalance         int64  `json:"local_balance"`
+	RemoteBalance        int64  `json:"remote_balance"`
+	LocalScript          string `json:"local_script"`
+	RemoteScript         string `json:"remote_script"`
+	Initiator            bool   `json:"initiator"`
+	CarrierAmount        int64  `json:"carrier_amount"`
+	FoldThreshold        int64  `json:"fold_threshold"`
+	FoldCompensationRate int64  `json:"fold_compensation_rate"`
+	ExpectedTx           string `json:"expected_tx"`
+	ExpectedPayload      string `json:"expected_payload"`
+}
+
+// txVectors is the set of golden test vectors within txVectorsFile.
+type txVectors struct {
+	Commitments []*commitVector `json:"commitments"`
+	Closes      []*closeVector  `json:"closes"`
+}
+
+// decodeHex decodes the passed hex string, failing the test if it's invalid.
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+	return b
+}
+
+// decodePubKey decodes the passed hex encoded public key, failing the test if
+// it's invalid.
+func decodePubKey(t *testing.T, s string) *btcec.PublicKey {
+	key, err := btcec.ParsePubKey(decodeHex(t, s), btcec.S256())
+	if err != nil {
+		t.Fatalf("invalid public key %q: %v", s, err)
+	}
+	return key
+}
+
+// vectorFundingTxIn returns the input spending the funding output of a test
+// vector.
+func vectorFundingTxIn(t *testing.T, txid string, index uint32) *wire.TxIn {
+	hash, err := wire.NewShaHashFromStr(txid)
+	if err != nil {
+		t.Fatalf("invalid funding txid %q: %v", txid, err)
+	}
+	return wire.NewTxIn(wire.NewOutPoint(hash, index), nil, nil)
+}
+
+// serializeVectorTx returns the hex encoded transaction, along with the hex
+// encoded colored coins payload embedded within its OP_RETURN output.
+func serializeVectorTx(t *testing.T, tx *wire.MsgTx) (string, string) {
+	var b bytes.Buffer
+	if err := tx.Serialize(&b); err != nil {
+		t.Fatalf("unable to serialize tx: %v", err)
+	}
+
+	var payload []byte
+	for _, txOut := range tx.TxOut {
+		script := txOut.PkScript
+		if len(script) > 1 && script[0] == txscript.OP_RETURN {
+			payload = script[2:]
+			break
+		}
+	}
+
+	return hex.EncodeToString(b.Bytes()), hex.EncodeToString(payload)
+}
+
+// newVectorChannel creates a channel in the state described by the passed
+// commitment test vector, with the vector's HTLCs added to the update logs
+// of either side.
+func newVectorChannel(t *testing.T, vector *commitVector) *LightningChannel {
+	state := &channeldb.OpenChannel{
+		OurCommitKey:   decodePubKey(t, vector.LocalCommitKey),
+		TheirCommitKey: decodePubKey(t, vector.RemoteCommitKey),
+		LocalCsvDelay:  vector.LocalCsvDelay,
+		RemoteCsvDelay: vector.RemoteCsvDelay,
+		OurBalance:     btcutil.Amount(vector.LocalBalance),
+		TheirBalance:   btcutil.Amount(vector.RemoteBalance),
+	}
+
+	lc := &LightningChannel{
+		channelState:      state,
+		remoteCommitChain: newCommitmentChain(0),
+		localCommitChain:  newCommitmentChain(0),
+		ourUpdateLog:      list.New(),
+		theirUpdateLog:    list.New(),
+		ourLogIndex:       make(map[uint64]*list.Element),
+		theirLogIndex:     make(map[uint64]*list.Element),
+		htlcScriptCache:   make(htlcScriptCache),
+		fundingTxIn: vectorFundingTxIn(t, vector.FundingTxid,
+			vector.FundingIndex),
+	}
+	initialCommitment := &commitment{
+		ourBalance:   state.OurBalance,
+		theirBalance: state.TheirBalance,
+	}
+	lc.localCommitChain.addCommitment(initialCommitment)
+	lc.remoteCommitChain.addCommitment(initialCommitment)
+
+	for _, htlc := range vector.HTLCs {
+		pd := &PaymentDescriptor{
+			EntryType: Add,
+			Amount:    btcutil.Amount(htlc.Amount),
+			Timeout:   htlc.Timeout,
+		}
+		copy(pd.RHash[:], decodeHex(t, htlc.PaymentHash))
+
+		if htlc.Incoming {
+			pd.Index = uint64(lc.theirUpdateLog.Len())
+			lc.theirLogIndex[pd.Index] = lc.theirUpdateLog.PushBack(pd)
+		} else {
+			pd.Index = uint64(lc.ourUpdateLog.Len())
+			lc.ourLogIndex[pd.Index] = lc.ourUpdateLog.PushBack(pd)
+		}
+	}
+
+	return lc
+}
+
+// TestTxVectors tests that the commitment and cooperative close transactions
+// constructed, along with the colored coins instructions embedded within
+// them, match the golden test vectors byte for byte. A mismatch indicates a
+// change which would break compatibility with existing peers. The vectors are
+// colorified with the local kernel's encoding of the instructions.
+func TestTxVectors(t *testing.T) {
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = lndcc.EncodeTransfer
+
+	rawVectors, err := ioutil.ReadFile(txVectorsFile)
+	if err != nil {
+		t.Fatalf("unable to read test vectors: %v", err)
+	}
+	var vectors txVectors
+	if err := json.Unmarshal(rawVectors, &vectors); err != nil {
+		t.Fatalf("unable to decode test vectors: %v", err)
+	}
+
+	for _, vector := range vectors.Commitments {
+		lc := newVectorChannel(t, vector)
+
+		var revocationHash [32]byte
+		copy(revocationHash[:], decodeHex(t, vector.RevocationHash))
+		commit, err := lc.fetchCommitmentView(vector.RemoteChain,
+			uint64(lc.ourUpdateLog.Len()),
+			uint64(lc.theirUpdateLog.Len()),
+			decodePubKey(t, vector.RevocationKey), revocationHash)
+		if err != nil {
+			t.Fatalf("%v: unable to create commitment: %v",
+				vector.Name, err)
+		}
+
+		txHex, payloadHex := serializeVectorTx(t, commit.txn)
+		if *updateTxVectors {
+			vector.ExpectedTx = txHex
+			vector.ExpectedPayload = payloadHex
+			continue
+		}
+		if payloadHex != vector.ExpectedPayload {
+			t.Fatalf("%v: instruction payload mismatch: expected "+
+				"%v, got %v", vector.Name, vector.ExpectedPayload,
+				payloadHex)
+		}
+		if txHex != vector.ExpectedTx {
+			t.Fatalf("%v: commitment tx mismatch: expected %v, "+
+				"got %v", vector.Name, vector.ExpectedTx, txHex)
+		}
+	}
+
+	for _, vector := range vectors.Closes {
+		policy := &CloseOutputPolicy{
+			CarrierAmount:        btcutil.Amount(vector.CarrierAmount),
+			FoldThreshold:        btcutil.Amount(vector.FoldThreshold),
+			FoldCompensationRate: btcutil.Amount(vector.FoldCompensationRate),
+		}
+		closeTx, err := CreateCooperativeCloseTx(
+			vectorFundingTxIn(t, vector.FundingTxid,
+				vector.FundingIndex),
+			btcutil.Amount(vector.FundingValue),
+			btcutil.Amount(vector.LocalBalance),
+			btcutil.Amount(vector.RemoteBalance),
+			decodeHex(t, vector.LocalScript),
+			decodeHex(t, vector.RemoteScript),
+			vector.Initiator, policy)
+		if err != nil {
+			t.Fatalf("%v: unable to create close tx: %v",
+				vector.Name, err)
+		}
+
+		txHex, payloadHex := serializeVectorTx(t, closeTx)
+		if *updateTxVectors {
+			vector.ExpectedTx = txHex
+			vector.ExpectedPayload = payloadHex
+			continue
+		}
+		if payloadHex != vector.ExpectedPayload {
+			t.Fatalf("%v: instruction payload mismatch: expected "+
+				"%v, got %v", vector.Name, vector.ExpectedPayload,
+				payloadHex)
+		}
+		if txHex != vector.ExpectedTx {
+			t.Fatalf("%v: close tx mismatch: expected %v, got %v",
+				vector.Name, vector.ExpectedTx, txHex)
+		}
+	}
+
+	if *updateTxVectors {
+		rawVectors, err := json.MarshalIndent(&vectors, "", "\t")
+		if err != nil {
+			t.Fatalf("unable to encode test vectors: %v", err)
+		}
+		rawVectors = append(rawVectors, '\n')
+		err = ioutil.WriteFile(txVectorsFile, rawVectors, 0644)
+		if err != nil {
+			t.Fatalf("unable to write test vectors: %v", err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package lnwallet
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// txDumps is non-zero if raw transactions are to be dumped in full within the
+// log. Otherwise, transactions are logged as a single line summary, as
+// dumping a commitment transaction carrying many HTLCs is expensive.
+var txDumps int32
+
+// EnableTxDumps enables, or disables full dumps of raw transactions within
+// the log.
+func EnableTxDumps(enable bool) {
+	var dumps int32
+	if enable {
+		dumps = 1
+	}
+	atomic.StoreInt32(&txDumps, dumps)
+}
+
+// TxDumpsEnabled returns true if raw transactions are to be dumped in full
+// within the log.
+func TxDumpsEnabled() bool {
+	return atomic.LoadInt32(&txDumps) != 0
+}
+
+// TxSummary returns a single line summary of the passed transaction: its
+// txid, number of outputs, total satoshis, and the total amount of the passed
+// asset assigned by its transfer instructions. If the instructions can't be
+// decoded, then the asset total is reported as unknown.
+func TxSummary(tx *wire.MsgTx, assetID string) string {
+	var sats btcutil.Amount
+	for _, txOut := range tx.TxOut {
+		sats += btcutil.Amount(txOut.Value)
+	}
+
+	assets := "unknown"
+	if insts := lndcc.DecodeTransfer(tx); insts != nil {
+		var total btcutil.Amount
+		for _, inst := range insts {
+			total += btcutil.Amount(inst.Amount)
+		}
+		assets = fmt.Sprintf("%v", int64(total))
+	}
+	if assetID != "" {
+		assets = fmt.Sprintf("%v of %q", assets, assetID)
+	}
+
+	return fmt.Sprintf("txid=%v, n_outputs=%v, sats=%v, assets=%v",
+		tx.TxSha(), len(tx.TxOut), sats, assets)
+}
+
+// newTxLogClosure returns a log closure which dumps the passed transaction in
+// full if transaction dumps are enabled, and otherwise summarizes it. Either
+// way, the transaction is only formatted once the log level warrants it.
+func newTxLogClosure(tx *wire.MsgTx, assetID string) logClosure {
+	return newLogClosure(func() string {
+		if TxDumpsEnabled() {
+			return spew.Sdump(tx)
+		}
+		return TxSummary(tx, assetID)
+	})
+}
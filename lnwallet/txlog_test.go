@@ -0,0 +1,55 @@
+package lnwallet
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestTxSummary tests that transactions are summarized on a single line,
+// including the asset total assigned by their transfer instructions, unless
+// transaction dumps are enabled.
+func TestTxSummary(t *testing.T) {
+	template := wire.NewMsgTx()
+	template.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	template.AddTxOut(wire.NewTxOut(100, []byte{1}))
+	template.AddTxOut(wire.NewTxOut(200, []byte{2}))
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+	lndcc.Encoder = lndcc.EncodeTransfer
+
+	tx, err := lndcc.ColorifyTx(template, false)
+	if err != nil {
+		t.Fatalf("unable to colorify tx: %v", err)
+	}
+
+	summary := TxSummary(tx, "assetA")
+	expected := fmt.Sprintf("txid=%v, n_outputs=3, sats=%v, "+
+		"assets=300 of \"assetA\"", tx.TxSha(), 2*lndcc.CarrierValue())
+	if summary != expected {
+		t.Fatalf("expected summary %q, got %q", expected, summary)
+	}
+	if strings.Contains(summary, "\n") {
+		t.Fatalf("summary spans several lines: %q", summary)
+	}
+
+	// Without decodable instructions, the asset total is unknown.
+	summary = TxSummary(template, "")
+	if !strings.HasSuffix(summary, "assets=unknown") {
+		t.Fatalf("expected unknown asset total, got %q", summary)
+	}
+
+	defer EnableTxDumps(false)
+	if newTxLogClosure(tx, "assetA").String() != TxSummary(tx, "assetA") {
+		t.Fatalf("transaction dumped with dumps disabled")
+	}
+	EnableTxDumps(true)
+	if !strings.Contains(newTxLogClosure(tx, "assetA").String(), "TxOut") {
+		t.Fatalf("transaction not dumped with dumps enabled")
+	}
+}
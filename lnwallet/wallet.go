@@ -8,10 +8,8 @@ import (
 	"sync"
 	"sync/atomic"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
-	"github.com/lightningnetwork/lnd/elkrem"
 	"github.com/lightningnetwork/lnd/lndcc"
 	"github.com/roasbeef/btcd/chaincfg"
 	"github.com/roasbeef/btcutil/hdkeychain"
@@ -20,14 +18,9 @@ import (
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
-	"github.com/roasbeef/btcutil/txsort"
 )
 
 const (
-	// The size of the buffered queue of requests to the wallet from the
-	// outside word.
-	msgBufferSize = 100
-
 	// elkremRootIndex is the top level HD key index from which secrets
 	// used to generate elkrem roots should be derived from.
 	elkremRootIndex = hdkeychain.HardenedKeyStart + 1
@@ -41,6 +34,17 @@ const (
 
 	// @CC: disable fees for PoC simplification
 	commitFee = 0
+
+	// fundingInputSequence is the sequence number used for all of our
+	// inputs to a funding transaction. This signals opt-in replace-by-fee
+	// (BIP 125), allowing a funding transaction to be double spent in
+	// order to cancel a channel whose peer disappeared after broadcast.
+	fundingInputSequence = wire.MaxTxInSequenceNum - 2
+
+	// fundingAbortFeeBump is the number of satoshis by which the fee of a
+	// transaction cancelling a channel exceeds that of the funding
+	// transaction it replaces.
+	fundingAbortFeeBump = 10000
 )
 
 var (
@@ -48,6 +52,46 @@ var (
 	ErrInsufficientFunds = errors.New("not enough available outputs to " +
 		"create funding transaction")
 
+	// ErrWalletNotSynced is returned when a funding workflow is attempted
+	// before the backing wallet has fully synced to the main chain. Colored
+	// outputs selected from a stale view of the chain may already be
+	// spent.
+	ErrWalletNotSynced = errors.New("wallet is still syncing to the " +
+		"main chain")
+
+	// ErrCsvDelayOutOfBounds is returned when either side of a channel
+	// reservation proposes a CSV delay outside the bounds set within the
+	// wallet's config.
+	ErrCsvDelayOutOfBounds = errors.New("csv delay is outside the " +
+		"accepted bounds")
+
+	// ErrAssetMismatch is returned when the remote party proposes a
+	// channel denominated in an asset other than the one we operate on.
+	ErrAssetMismatch = errors.New("remote party proposed a channel for " +
+		"a different asset")
+
+	// ErrChanTooLarge is returned when either side of a channel
+	// reservation proposes a capacity beyond the maximum set within the
+	// wallet's config for the channel's asset.
+	ErrChanTooLarge = errors.New("channel capacity exceeds the maximum " +
+		"accepted for the asset")
+
+	// ErrAssetNotAllowed is returned when either side of a channel
+	// reservation proposes a channel denominated in an asset refused by
+	// the wallet's config.
+	ErrAssetNotAllowed = errors.New("asset not allowed by policy")
+
+	// ErrChannelRejected is returned when an inbound channel is rejected
+	// by the wallet's ChannelAcceptor.
+	ErrChannelRejected = errors.New("channel rejected by acceptance " +
+		"policy")
+
+	// ErrCannotAbortFunding is returned when an attempt is made to abort
+	// a reservation whose funding transaction either hasn't yet been
+	// broadcast, or has already confirmed.
+	ErrCannotAbortFunding = errors.New("funding transaction can only be " +
+		"aborted after broadcast, and before confirmation")
+
 	// Namespace bucket keys.
 	lightningNamespaceKey = []byte("ln-wallet")
 	waddrmgrNamespaceKey  = []byte("waddrmgr")
@@ -67,8 +111,9 @@ var (
 // selected will be 'locked', making them unavailable, for any other pending
 // reservations. Therefore, all channels in reservation limbo will be periodically
 // after a timeout period in order to avoid "exhaustion" attacks.
-// NOTE: The workflow currently assumes fully balanced symmetric channels.
-// Meaning both parties must encumber the same amount of funds.
+// NOTE: Channels needn't be balanced. The remote party contributes whatever
+// portion of the capacity our fundingAmount leaves over, which may be all or
+// none of it.
 // TODO(roasbeef): zombie reservation sweeper goroutine.
 type initFundingReserveMsg struct {
 	// The number of confirmations required before the channel is considered
@@ -79,7 +124,8 @@ type initFundingReserveMsg struct {
 	fundingAmount btcutil.Amount
 
 	// The total capacity of the channel which includes the amount of funds
-	// the remote party contributes (if any).
+	// the remote party contributes (if any). The commitment fee is paid
+	// out of the initiator's contribution.
 	capacity btcutil.Amount
 
 	// The minimum accepted satoshis/KB fee for the funding transaction. In
@@ -118,6 +164,17 @@ type fundingReserveCancelMsg struct {
 	err chan error // Buffered
 }
 
+// fundingAbortMsg is a message requesting that the funding transaction of a
+// reservation which has already been broadcast, but not yet confirmed, be
+// double spent in order to cancel the channel.
+type fundingAbortMsg struct {
+	reservation *ChannelReservation
+
+	// NOTE: In order to avoid deadlocks, these channels MUST be buffered.
+	err  chan error       // Buffered
+	resp chan *wire.MsgTx // Buffered
+}
+
 // addContributionMsg represents a message executing the second phase of the
 // channel reservation workflow. This message carries the counterparty's
 // "contribution" to the payment channel. In the case that this message is
@@ -186,6 +243,10 @@ type addSingleFunderSigsMsg struct {
 	// transaction as assembled by the workflow initiator.
 	fundingOutpoint *wire.OutPoint
 
+	// fundingValue is the value in satoshis of the funding output, which
+	// is committed to by the sighash of both commitment transactions.
+	fundingValue btcutil.Amount
+
 	// revokeKey is the revocation public key derived by the remote node to
 	// be used within the initial version of the commitment transaction we
 	// construct for them.
@@ -268,8 +329,9 @@ type LightningWallet struct {
 	// key. This rootKey is used to derive all LN specific secrets.
 	rootKey *hdkeychain.ExtendedKey
 
-	// All messages to the wallet are to be sent accross this channel.
-	msgChan chan interface{}
+	// All requests to the wallet are to be sent accross the queue for
+	// their type.
+	queues requestQueues
 
 	// Incomplete payment channels are stored in the map below. An intent
 	// to create a payment channel is tracked as a "reservation" within
@@ -285,11 +347,21 @@ type LightningWallet struct {
 
 	// lockedOutPoints is a set of the currently locked outpoint. This
 	// information is kept in order to provide an easy way to unlock all
-	// the currently locked outpoints.
+	// the currently locked outpoints. On startup, it's populated with the
+	// outpoints left locked by the WalletController before the restart.
 	lockedOutPoints map[wire.OutPoint]struct{}
 
 	netParams *chaincfg.Params
 
+	// assetErr is the error the validation of the asset we operate on
+	// failed with, if any. While set, all channel reservations are
+	// refused. It's protected by the limboMtx.
+	assetErr error
+
+	// cfg houses the policy parameters enforced on all channel
+	// reservations.
+	cfg *Config
+
 	started  int32
 	shutdown int32
 	quit     chan struct{}
@@ -304,12 +376,20 @@ type LightningWallet struct {
 // setup is executed.
 //
 // NOTE: The passed channeldb, and ChainNotifier should already be fully
-// initialized/started before being passed as a function arugment.
-func NewLightningWallet(cdb *channeldb.DB, notifier chainntnfs.ChainNotifier,
-	wallet WalletController, signer Signer, bio BlockChainIO,
+// initialized/started before being passed as a function arugment. If the
+// passed config is nil, then the default config is used.
+func NewLightningWallet(cfg *Config, cdb *channeldb.DB,
+	notifier chainntnfs.ChainNotifier, wallet WalletController,
+	signer Signer, bio BlockChainIO,
 	netParams *chaincfg.Params) (*LightningWallet, error) {
 
-	// TODO(roasbeef): need a another wallet level config
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.MinCsvDelay > cfg.MaxCsvDelay {
+		return nil, fmt.Errorf("min csv delay of %v exceeds max csv "+
+			"delay of %v", cfg.MinCsvDelay, cfg.MaxCsvDelay)
+	}
 
 	// Fetch the root derivation key from the wallet's HD chain. We'll use
 	// this to generate specific Lightning related secrets on the fly.
@@ -325,14 +405,21 @@ func NewLightningWallet(cdb *channeldb.DB, notifier chainntnfs.ChainNotifier,
 		return nil, err
 	}
 
+	// In read-only mode, the wallet is unable to produce any signature,
+	// whether it's requested by the wallet itself, or by a channel.
+	if cfg.ReadOnly {
+		signer = readOnlySigner{}
+	}
+
 	return &LightningWallet{
+		cfg:              cfg,
 		rootKey:          rootMasterKey,
 		chainNotifier:    notifier,
 		Signer:           signer,
 		WalletController: wallet,
 		chainIO:          bio,
 		ChannelDB:        cdb,
-		msgChan:          make(chan interface{}, msgBufferSize),
+		queues:           newRequestQueues(),
 		nextFundingID:    0,
 		fundingLimbo:     make(map[uint64]*ChannelReservation),
 		lockedOutPoints:  make(map[wire.OutPoint]struct{}),
@@ -353,6 +440,12 @@ func (l *LightningWallet) Startup() error {
 		return err
 	}
 
+	// Track the outpoints locked before the restart, so they're released
+	// along with those locked from here on.
+	if err := l.restoreLockedOutpoints(); err != nil {
+		return err
+	}
+
 	l.wg.Add(1)
 	// TODO(roasbeef): multiple request handlers?
 	go l.requestHandler()
@@ -377,6 +470,28 @@ func (l *LightningWallet) Shutdown() error {
 	return nil
 }
 
+// PublishTransaction broadcasts the passed transaction via the underlying
+// WalletController, counting any failure to do so within the wallet's
+// metrics. ErrReadOnly is returned if the wallet is in read-only mode.
+func (l *LightningWallet) PublishTransaction(tx *wire.MsgTx) error {
+	if l.cfg.ReadOnly {
+		return ErrReadOnly
+	}
+
+	if err := l.WalletController.PublishTransaction(tx); err != nil {
+		broadcastFailures.Inc()
+		return err
+	}
+
+	return nil
+}
+
+// AssetAllowed returns true if the wallet's config allows the passed asset to
+// be held within our channels, and routed.
+func (l *LightningWallet) AssetAllowed(assetID string) bool {
+	return l.cfg.AssetAllowed(assetID)
+}
+
 // LockOutpoints returns a list of all currently locked outpoint.
 func (l *LightningWallet) LockedOutpoints() []*wire.OutPoint {
 	outPoints := make([]*wire.OutPoint, 0, len(l.lockedOutPoints))
@@ -394,11 +509,72 @@ func (l *LightningWallet) ResetReservations() {
 	l.fundingLimbo = make(map[uint64]*ChannelReservation)
 
 	for outpoint := range l.lockedOutPoints {
-		l.UnlockOutpoint(outpoint)
+		l.unlockOutpoint(outpoint)
 	}
 	l.lockedOutPoints = make(map[wire.OutPoint]struct{})
 }
 
+// lockOutpoint locks the passed outpoint within the WalletController, which
+// persists the lock across restarts, and tracks it so it can later be
+// released.
+func (l *LightningWallet) lockOutpoint(o wire.OutPoint) error {
+	if err := l.LockOutpoint(o); err != nil {
+		return err
+	}
+
+	l.lockedOutPoints[o] = struct{}{}
+	return nil
+}
+
+// unlockOutpoint releases an outpoint locked by lockOutpoint. If the lock
+// can't be released within the WalletController, then the failure is only
+// logged, as the lock is tracked once again after a restart.
+func (l *LightningWallet) unlockOutpoint(o wire.OutPoint) {
+	delete(l.lockedOutPoints, o)
+
+	if err := l.UnlockOutpoint(o); err != nil {
+		walletLog.Errorf("unable to unlock outpoint %v: %v", o, err)
+	}
+}
+
+// restoreLockedOutpoints tracks the outpoints left locked by the
+// WalletController before the restart. As the reservations which locked them
+// were lost, each remains locked until the reservations are reset, unless the
+// outpoint has since been spent, in which case its lock is released right
+// away.
+func (l *LightningWallet) restoreLockedOutpoints() error {
+	outPoints, err := l.ListLockedOutpoints()
+	if err != nil {
+		return err
+	}
+
+	var numSpent int
+	for _, o := range outPoints {
+		_, err := l.chainIO.GetUtxo(&o.Hash, o.Index)
+		switch {
+		case err == ErrOutputSpent:
+			l.unlockOutpoint(o)
+			numSpent++
+			continue
+
+		// If the output can't be looked up, then we err on the side of
+		// caution, and keep it locked.
+		case err != nil:
+			walletLog.Warnf("unable to look up locked outpoint %v: "+
+				"%v", o, err)
+		}
+
+		l.lockedOutPoints[o] = struct{}{}
+	}
+
+	if len(outPoints) != 0 {
+		walletLog.Infof("Restored %v locked outpoints, released %v "+
+			"since spent", len(outPoints)-numSpent, numSpent)
+	}
+
+	return nil
+}
+
 // ActiveReservations returns a slice of all the currently active
 // (non-cancalled) reservations.
 func (l *LightningWallet) ActiveReservations() []*ChannelReservation {
@@ -421,38 +597,6 @@ func (l *LightningWallet) GetIdentitykey() (*btcec.PrivateKey, error) {
 	return identityKey.ECPrivKey()
 }
 
-// requestHandler is the primary goroutine(s) resposible for handling, and
-// dispatching relies to all messages.
-func (l *LightningWallet) requestHandler() {
-out:
-	for {
-		select {
-		case m := <-l.msgChan:
-			switch msg := m.(type) {
-			case *initFundingReserveMsg:
-				l.handleFundingReserveRequest(msg)
-			case *fundingReserveCancelMsg:
-				l.handleFundingCancelRequest(msg)
-			case *addSingleContributionMsg:
-				l.handleSingleContribution(msg)
-			case *addContributionMsg:
-				l.handleContributionMsg(msg)
-			case *addSingleFunderSigsMsg:
-				l.handleSingleFunderSigs(msg)
-			case *addCounterPartySigsMsg:
-				l.handleFundingCounterPartySigs(msg)
-			case *channelOpenMsg:
-				l.handleChannelOpen(msg)
-			}
-		case <-l.quit:
-			// TODO: do some clean up
-			break out
-		}
-	}
-
-	l.wg.Done()
-}
-
 // InitChannelReservation kicks off the 3-step workflow required to succesfully
 // open a payment channel with a remote node. As part of the funding
 // reservation, the inputs selected for the funding transaction are 'locked'.
@@ -477,7 +621,7 @@ func (l *LightningWallet) InitChannelReservation(capacity,
 	errChan := make(chan error, 1)
 	respChan := make(chan *ChannelReservation, 1)
 
-	l.msgChan <- &initFundingReserveMsg{
+	req := &initFundingReserveMsg{
 		capacity:      capacity,
 		numConfs:      numConfs,
 		fundingAmount: ourFundAmt,
@@ -487,15 +631,74 @@ func (l *LightningWallet) InitChannelReservation(capacity,
 		resp:          respChan,
 	}
 
+	// New reservations are refused rather than queued indefinitely once
+	// the wallet falls behind, applying backpressure to the peers
+	// initiating them.
+	select {
+	case l.queues.reserve <- req:
+	default:
+		queueOverflows.Inc("reserve")
+		return nil, ErrWalletBusy
+	}
+
 	return <-respChan, <-errChan
 }
 
 // handleFundingReserveRequest processes a message intending to create, and
 // validate a funding reservation request.
 func (l *LightningWallet) handleFundingReserveRequest(req *initFundingReserveMsg) {
+	// Before we start any funding workflows, ensure that the backing
+	// wallet is fully synced. Otherwise, we may select colored outputs
+	// which have already been spent within the main chain.
+	synced, err := l.IsSynced()
+	if err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+	if !synced {
+		req.err <- ErrWalletNotSynced
+		req.resp <- nil
+		return
+	}
+
+	// Refuse to open any channels for an asset which doesn't exist on the
+	// network we're operating on.
+	l.limboMtx.RLock()
+	assetErr := l.assetErr
+	l.limboMtx.RUnlock()
+	if assetErr != nil {
+		req.err <- assetErr
+		req.resp <- nil
+		return
+	}
+
+	// Ensure the CSV delay we'll use for our commitment transaction is
+	// within the bounds of our policy. If we're the responder, then this
+	// is the delay proposed by the remote party.
+	if err := l.cfg.validateCsvDelay(req.csvDelay); err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+
+	// Similarly, ensure we're willing to hold the channel's asset, and
+	// that the capacity of the channel is within the bounds of our policy
+	// for the asset, whichever side proposed it.
+	if err := l.cfg.validateAssetAllowed(globallyActiveAssetId); err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+	err = l.cfg.validateCapacity(globallyActiveAssetId, req.capacity)
+	if err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+
 	id := atomic.AddUint64(&l.nextFundingID, 1)
-	totalCapacity := req.capacity + commitFee
-	reservation := NewChannelReservation(totalCapacity, req.fundingAmount,
+	reservation := NewChannelReservation(req.capacity, req.fundingAmount,
 		req.minFeeRate, l, id, req.numConfs)
 
 	// Grab the mutex on the ChannelReservation to ensure thead-safety
@@ -506,6 +709,10 @@ func (l *LightningWallet) handleFundingReserveRequest(req *initFundingReserveMsg
 	ourContribution := reservation.ourContribution
 	ourContribution.CsvDelay = req.csvDelay
 	reservation.partialState.LocalCsvDelay = req.csvDelay
+	ourContribution.AssetParams = l.cfg.assetParams(req.capacity)
+	reservation.partialState.AssetID = ourContribution.AssetParams.AssetID
+	ourContribution.Features = SupportedFeatures()
+	ourContribution.Features.ShaChain = l.cfg.ShaChain
 
 	// If we're on the receiving end of a single funder channel then we
 	// don't need to perform any coin selection. Otherwise, attempt to
@@ -514,13 +721,14 @@ func (l *LightningWallet) handleFundingReserveRequest(req *initFundingReserveMsg
 		// TODO(roasbeef): consult model for proper fee rate on funding
 		// tx
 		feeRate := uint64(10)
-		amt := req.fundingAmount + commitFee
-		err := l.selectCoinsAndChange(feeRate, amt, ourContribution)
+		fuelChange, err := l.selectCoinsAndChange(feeRate,
+			req.fundingAmount, ourContribution)
 		if err != nil {
 			req.err <- err
 			req.resp <- nil
 			return
 		}
+		reservation.fuelChange = fuelChange
 	}
 
 	// Grab two fresh keys from our HD chain, one will be used for the
@@ -545,7 +753,7 @@ func (l *LightningWallet) handleFundingReserveRequest(req *initFundingReserveMsg
 
 	// Generate a fresh address to be used in the case of a cooperative
 	// channel close.
-	deliveryAddress, err := l.NewAddress(WitnessPubKey, false)
+	deliveryAddress, err := l.NewChannelAddress(WitnessPubKey, false)
 	if err != nil {
 		req.err <- err
 		req.resp <- nil
@@ -596,8 +804,7 @@ func (l *LightningWallet) handleFundingCancelRequest(req *fundingReserveCancelMs
 	// Mark all previously locked outpoints as usuable for future funding
 	// requests.
 	for _, unusedInput := range pendingReservation.ourContribution.Inputs {
-		delete(l.lockedOutPoints, unusedInput.PreviousOutPoint)
-		l.UnlockOutpoint(unusedInput.PreviousOutPoint)
+		l.unlockOutpoint(unusedInput.PreviousOutPoint)
 	}
 
 	// TODO(roasbeef): is it even worth it to keep track of unsed keys?
@@ -610,6 +817,241 @@ func (l *LightningWallet) handleFundingCancelRequest(req *fundingReserveCancelMs
 	req.err <- nil
 }
 
+// handleFundingAbort cancels a channel whose funding transaction has been
+// broadcast, but not yet confirmed, by double spending our funding inputs
+// back to ourselves with a higher fee. This allows us to recover the colored
+// coins committed to a channel whose peer disappeared after broadcast,
+// rather than leaving them locked within the 2-of-2 output forever.
+//
+// NOTE: As the replacement must pay a higher fee than the funding
+// transaction, only channels we've funded entirely can be aborted.
+func (l *LightningWallet) handleFundingAbort(req *fundingAbortMsg) {
+	res := req.reservation
+
+	state := res.State()
+	if state != ReservationBroadcast && state != ReservationConfirming {
+		req.err <- ErrCannotAbortFunding
+		req.resp <- nil
+		return
+	}
+
+	res.Lock()
+	defer res.Unlock()
+
+	fundingTx := res.fundingTx
+	chanPoint := res.partialState.FundingOutpoint
+
+	// Tally up the satoshis, and colored coins carried by each of our
+	// inputs to the funding transaction. If any input isn't ours, then we
+	// can't determine the fee the replacement must pay.
+	abortTx := wire.NewMsgTx()
+	abortTx.Version = fundingTx.Version
+	inputs := make([]*wire.TxOut, len(fundingTx.TxIn))
+	var inputSats, inputAssets btcutil.Amount
+	for i, txIn := range fundingTx.TxIn {
+		prevOut := txIn.PreviousOutPoint
+
+		info, err := l.FetchInputInfo(&prevOut)
+		if err != nil {
+			req.err <- fmt.Errorf("unable to abort channel, "+
+				"input %v: %v", prevOut, err)
+			req.resp <- nil
+			return
+		}
+		inputs[i] = info
+		inputSats += btcutil.Amount(info.Value)
+
+		txoData, err := lndcc.GetTxoData(prevOut)
+		if err != nil {
+			req.err <- err
+			req.resp <- nil
+			return
+		}
+		if txoData.AssetId != res.partialState.AssetID &&
+			txoData.AssetId != "" {
+
+			req.err <- fmt.Errorf("input %v carries %v, expected "+
+				"asset %v", prevOut, txoData,
+				res.partialState.AssetID)
+			req.resp <- nil
+			return
+		}
+		inputAssets += txoData.Value
+
+		abortTx.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: prevOut,
+			Sequence:         fundingInputSequence,
+		})
+	}
+
+	var fundingOutputSats btcutil.Amount
+	for _, txOut := range fundingTx.TxOut {
+		fundingOutputSats += btcutil.Amount(txOut.Value)
+	}
+	abortFee := inputSats - fundingOutputSats + fundingAbortFeeBump
+
+	// Send all of the colored coins back to a fresh address of ours. The
+	// value of the output is first set to the asset amount in order to
+	// colorify the transaction, then replaced with the satoshis left over
+	// after paying the increased fee.
+	sweepAddr, err := l.NewChannelAddress(WitnessPubKey, false)
+	if err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+	sweepScript, err := txscript.PayToAddrScript(sweepAddr)
+	if err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+	abortTx.AddTxOut(wire.NewTxOut(int64(inputAssets), sweepScript))
+
+	abortTx, err = lndcc.ColorifyTx(abortTx, false)
+	if err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+	sweepValue := inputSats - abortFee
+	if sweepValue < btcutil.Amount(abortTx.TxOut[0].Value) {
+		req.err <- fmt.Errorf("funding inputs carry %v, insufficient "+
+			"to pay abort fee of %v", inputSats, abortFee)
+		req.resp <- nil
+		return
+	}
+	abortTx.TxOut[0].Value = int64(sweepValue)
+
+	// With the transaction complete, sign each of our inputs.
+	signDesc := SignDescriptor{
+		HashType:  txscript.SigHashAll,
+		SigHashes: txscript.NewTxSigHashes(abortTx),
+	}
+	for i, txIn := range abortTx.TxIn {
+		signDesc.Output = inputs[i]
+		signDesc.InputIndex = i
+
+		inputScript, err := l.Signer.ComputeInputScript(abortTx, &signDesc)
+		if err != nil {
+			req.err <- err
+			req.resp <- nil
+			return
+		}
+
+		txIn.SignatureScript = inputScript.ScriptSig
+		txIn.Witness = inputScript.Witness
+	}
+
+	walletLog.Infof("Aborting ChannelPoint(%v), replacing funding tx with "+
+		"%v", chanPoint, abortTx.TxSha())
+
+	err = l.PublishAuditedTransaction(abortTx, channeldb.TxFundingAbort,
+		*chanPoint, res.partialState.AssetID)
+	if err != nil {
+		req.err <- err
+		req.resp <- nil
+		return
+	}
+
+	// Now that the funding transaction has been replaced, stop waiting
+	// for it to confirm, and mark the pending channel as closed.
+	res.abortFunding()
+
+	summary := &channeldb.ChannelCloseSummary{
+		ChanPoint:   *chanPoint,
+		RemoteID:    res.partialState.TheirLNID,
+		AssetID:     res.partialState.AssetID,
+		Capacity:    res.partialState.AssetCapacity,
+		OurBalance:  res.partialState.OurBalance,
+		CloseType:   channeldb.FundingCanceled,
+		ClosingTXID: abortTx.TxSha(),
+	}
+	if err := res.partialState.CloseChannel(summary); err != nil {
+		walletLog.Errorf("unable to mark ChannelPoint(%v) as closed: %v",
+			chanPoint, err)
+	}
+
+	for _, txIn := range abortTx.TxIn {
+		l.unlockOutpoint(txIn.PreviousOutPoint)
+	}
+
+	req.err <- nil
+	req.resp <- abortTx
+}
+
+// verifyRemoteInputs ensures that each input within the remote party's
+// contribution references an output which exists within the chain, and is
+// unspent. Additionally, the color data of each input is looked up to ensure
+// it carries either the channel's asset, or no asset at all as fuel. In
+// total, the inputs of the asset must carry enough of it to cover the remote
+// party's funding amount along with its change outputs, while the fuel
+// inputs must carry enough satoshis to cover its fuel change outputs. A
+// ContributionError with the RejectRemoteInput reason is returned on
+// failure.
+func (l *LightningWallet) verifyRemoteInputs(contribution *ChannelContribution,
+	assetID string) error {
+
+	// The change outputs are denominated in the asset, while the fuel
+	// change outputs are denominated in satoshis, so each is checked
+	// against the inputs of its own kind.
+	requiredAsset := contribution.FundingAmount
+	for _, changeOutput := range contribution.ChangeOutputs {
+		requiredAsset += btcutil.Amount(changeOutput.Value)
+	}
+	var requiredFuel btcutil.Amount
+	for _, changeOutput := range contribution.FuelChangeOutputs {
+		requiredFuel += btcutil.Amount(changeOutput.Value)
+	}
+
+	var totalAsset, totalFuel btcutil.Amount
+	for _, txIn := range contribution.Inputs {
+		prevOut := txIn.PreviousOutPoint
+
+		utxo, err := l.chainIO.GetUtxo(&prevOut.Hash, prevOut.Index)
+		if err != nil || utxo == nil {
+			return rejectContribution(RejectRemoteInput,
+				fmt.Errorf("%v is not an unspent output", prevOut))
+		}
+
+		txoData, err := lndcc.GetTxoData(prevOut)
+		if err != nil {
+			return rejectContribution(RejectRemoteInput,
+				fmt.Errorf("unable to fetch color data for %v: %v",
+					prevOut, err))
+		}
+
+		switch txoData.AssetId {
+		case assetID:
+			totalAsset += txoData.Value
+
+		// Uncolored inputs are accepted as fuel, paying for the
+		// carrier outputs, fees, and fuel change of the funding
+		// transaction.
+		case "":
+			totalFuel += btcutil.Amount(utxo.Value)
+
+		default:
+			return rejectContribution(RejectRemoteInput,
+				fmt.Errorf("%v carries %v, expected asset %v",
+					prevOut, txoData, assetID))
+		}
+	}
+
+	if totalAsset < requiredAsset {
+		return rejectContribution(RejectRemoteInput,
+			fmt.Errorf("inputs carry %v of %v, %v required",
+				totalAsset, assetID, requiredAsset))
+	}
+	if totalFuel < requiredFuel {
+		return rejectContribution(RejectRemoteInput,
+			fmt.Errorf("fuel inputs carry %v, %v of fuel change "+
+				"required", totalFuel, requiredFuel))
+	}
+
+	return nil
+}
+
 // handleFundingCounterPartyFunds processes the second workflow step for the
 // lifetime of a channel reservation. Upon completion, the reservation will
 // carry a completed funding transaction (minus the counterparty's input
@@ -628,6 +1070,35 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	pendingReservation.Lock()
 	defer pendingReservation.Unlock()
 
+	// Before accepting their contribution, ensure it's sane and
+	// compatible with our own, rejecting it before it's recorded within
+	// the reservation otherwise.
+	err := l.cfg.validateContribution(pendingReservation, req.contribution,
+		false)
+	if err != nil {
+		req.err <- err
+		return
+	}
+
+	// The responder selects the version of the colored channel protocol
+	// from our proposal, which is then recorded within the channel.
+	err = verifySelectedFeatures(&pendingReservation.ourContribution.Features,
+		&req.contribution.Features)
+	if err != nil {
+		req.err <- rejectContribution(RejectFeatures, err)
+		return
+	}
+	pendingReservation.partialState.Features = req.contribution.Features
+
+	// Before signing anything, verify that every input they've
+	// contributed is an unspent output carrying enough of the channel's
+	// asset to cover their side of the channel, and any change.
+	if err := l.verifyRemoteInputs(req.contribution,
+		pendingReservation.partialState.AssetID); err != nil {
+		req.err <- err
+		return
+	}
+
 	// Create a blank, fresh transaction. Soon to be a complete funding
 	// transaction which will allow opening a lightning channel.
 	pendingReservation.fundingTx = wire.NewMsgTx()
@@ -657,7 +1128,7 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 
 	// Finally, add the 2-of-2 multi-sig output which will set up the lightning
 	// channel.
-	channelCapacity := int64(pendingReservation.partialState.Capacity)
+	channelCapacity := int64(pendingReservation.partialState.AssetCapacity)
 	redeemScript, multiSigOut, err := GenFundingPkScript(ourKey.SerializeCompressed(),
 		theirKey.SerializeCompressed(), channelCapacity)
 	if err != nil {
@@ -666,17 +1137,32 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	}
 	pendingReservation.partialState.FundingRedeemScript = redeemScript
 
-	// Sort the transaction. Since both side agree to a cannonical
-	// ordering, by sorting we no longer need to send the entire
+	// Sort, then colorify the transaction. Since both side agree to a
+	// cannonical ordering, by sorting we no longer need to send the entire
 	// transaction. Only signatures will be exchanged.
 	fundingTx.AddTxOut(multiSigOut)
-	txsort.InPlaceSort(fundingTx)
-
-	fundingTx, err = lndcc.ColorifyTx(fundingTx, true)
+	fundingTx, err = colorifyCanonical(fundingTx, true)
 	if err != nil {
 		req.err <- err
 		return
 	}
+
+	// Return any fuel left over by our inputs within an uncolored output
+	// following the OP_RETURN output. The remote party isn't aware of our
+	// fuel change, so if they've contributed inputs of their own, and
+	// will therefore assemble the funding transaction themselves, the
+	// excess is instead given to the miners.
+	fuelChange := pendingReservation.fuelChange
+	switch {
+	case fuelChange != nil && len(theirContribution.Inputs) == 0:
+		fundingTx.AddTxOut(fuelChange)
+	case fuelChange != nil:
+		walletLog.Warnf("Unable to return fuel change of %v within "+
+			"dual funded channel, paying it as fee", fuelChange.Value)
+	}
+	for _, theirFuelChange := range theirContribution.FuelChangeOutputs {
+		fundingTx.AddTxOut(theirFuelChange)
+	}
 	pendingReservation.fundingTx = fundingTx
 
 	// Next, sign all inputs that are ours, collecting the signatures in
@@ -720,11 +1206,18 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	fundingOutpoint := wire.NewOutPoint(&fundingTxID, multiSigIndex)
 	pendingReservation.partialState.FundingOutpoint = fundingOutpoint
 
+	// The colorified funding output no longer carries the channel's
+	// capacity in satoshis, only the asset, so record the value it's
+	// actually been given. This is the amount committed to by the sighash
+	// of each transaction spending it.
+	fundingValue := fundingTx.TxOut[multiSigIndex].Value
+	pendingReservation.partialState.BtcCapacity = btcutil.Amount(fundingValue)
+
 	// Initialize an empty sha-chain for them, tracking the current pending
 	// revocation hash (we don't yet know the pre-image so we can't add it
 	// to the chain).
-	e := &elkrem.ElkremReceiver{}
-	pendingReservation.partialState.RemoteElkrem = e
+	features := &pendingReservation.partialState.Features
+	pendingReservation.partialState.RemoteElkrem = newRevocationStore(features)
 	pendingReservation.partialState.TheirCurrentRevocation = theirContribution.RevocationKey
 
 	masterElkremRoot, err := l.deriveMasterElkremRoot()
@@ -738,7 +1231,7 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	// we'll first create our elkrem root, then grab the first pre-iamge
 	// from it.
 	elkremRoot := deriveElkremRoot(masterElkremRoot, ourKey, theirKey)
-	elkremSender := elkrem.NewElkremSender(elkremRoot)
+	elkremSender := newRevocationProducer(features, elkremRoot)
 	pendingReservation.partialState.LocalElkrem = elkremSender
 	firstPreimage, err := elkremSender.AtIndex(0)
 	if err != nil {
@@ -755,8 +1248,8 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	// With the funding tx complete, create both commitment transactions.
 	// TODO(roasbeef): much cleanup + de-duplication
 	pendingReservation.fundingLockTime = theirContribution.CsvDelay
-	ourBalance := ourContribution.FundingAmount
-	theirBalance := theirContribution.FundingAmount
+	ourBalance := pendingReservation.partialState.OurBalance
+	theirBalance := pendingReservation.partialState.TheirBalance
 	ourCommitKey := ourContribution.CommitKey
 	ourCommitTx, err := CreateCommitTx(fundingTxIn, ourCommitKey, theirCommitKey,
 		ourRevokeKey, ourContribution.CsvDelay,
@@ -774,17 +1267,24 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	}
 
 	// Sort both transactions according to the agreed upon cannonical
-	// ordering. This lets us skip sending the entire transaction over,
-	// instead we'll just send signatures.
-	txsort.InPlaceSort(ourCommitTx)
-	txsort.InPlaceSort(theirCommitTx)
-
-	ourCommitTx, err = lndcc.ColorifyTx(ourCommitTx, false)
+	// ordering, then colorify them. This lets us skip sending the entire
+	// transaction over, instead we'll just send signatures.
+	ourCommitTx, err = colorifyCanonical(ourCommitTx, false)
+	if err != nil {
+		req.err <- err
+		return
+	}
+	err = addCommitAnchors(ourCommitTx, ourCommitKey, theirCommitKey)
 	if err != nil {
 		req.err <- err
 		return
 	}
-	theirCommitTx, err = lndcc.ColorifyTx(theirCommitTx, false)
+	theirCommitTx, err = colorifyCanonical(theirCommitTx, false)
+	if err != nil {
+		req.err <- err
+		return
+	}
+	err = addCommitAnchors(theirCommitTx, theirCommitKey, ourCommitKey)
 	if err != nil {
 		req.err <- err
 		return
@@ -810,10 +1310,13 @@ func (l *LightningWallet) handleContributionMsg(req *addContributionMsg) {
 	signDesc = SignDescriptor{
 		RedeemScript: redeemScript,
 		PubKey:       ourKey,
-		Output:       multiSigOut,
-		HashType:     txscript.SigHashAll,
-		SigHashes:    txscript.NewTxSigHashes(theirCommitTx),
-		InputIndex:   0,
+		Output: &wire.TxOut{
+			PkScript: multiSigOut.PkScript,
+			Value:    fundingValue,
+		},
+		HashType:   txscript.SigHashAll,
+		SigHashes:  txscript.NewTxSigHashes(theirCommitTx),
+		InputIndex: 0,
 	}
 	sigTheirCommit, err := l.Signer.SignOutputRaw(theirCommitTx, &signDesc)
 	if err != nil {
@@ -842,6 +1345,48 @@ func (l *LightningWallet) handleSingleContribution(req *addSingleContributionMsg
 	pendingReservation.Lock()
 	defer pendingReservation.Unlock()
 
+	// Before accepting their contribution, ensure it's sane and
+	// compatible with our own, rejecting it before it's recorded within
+	// the reservation otherwise.
+	err := l.cfg.validateContribution(pendingReservation, req.contribution,
+		true)
+	if err != nil {
+		req.err <- err
+		return
+	}
+
+	// Select the version of the colored channel protocol from the
+	// initiator's proposal. Our contribution carries the selection back
+	// to the initiator.
+	features, err := negotiateFeatures(
+		&pendingReservation.ourContribution.Features,
+		&req.contribution.Features)
+	if err != nil {
+		req.err <- rejectContribution(RejectFeatures, err)
+		return
+	}
+	pendingReservation.ourContribution.Features = features
+	pendingReservation.partialState.Features = features
+	theirParams := &req.contribution.AssetParams
+	capacity := pendingReservation.partialState.AssetCapacity
+
+	// If an acceptance policy has been configured, then give it the
+	// final say on whether we should accept this inbound channel.
+	if l.cfg.ChannelAcceptor != nil {
+		acceptReq := &ChannelAcceptRequest{
+			PeerID:   pendingReservation.partialState.TheirLNID,
+			Capacity: capacity,
+			AssetID:  theirParams.AssetID,
+			CsvDelay: req.contribution.CsvDelay,
+		}
+		if err := l.cfg.ChannelAcceptor.AcceptChannel(acceptReq); err != nil {
+			walletLog.Infof("Inbound channel from %x rejected: %v",
+				acceptReq.PeerID[:], err)
+			req.err <- fmt.Errorf("%v: %v", ErrChannelRejected, err)
+			return
+		}
+	}
+
 	// Simply record the counterparty's contribution into the pending
 	// reservation data as they'll be solely funding the channel entirely.
 	pendingReservation.theirContribution = req.contribution
@@ -852,7 +1397,7 @@ func (l *LightningWallet) handleSingleContribution(req *addSingleContributionMsg
 	// TODO(roasbeef): switch to proper pubkey derivation
 	ourKey := pendingReservation.partialState.OurMultiSigKey
 	theirKey := theirContribution.MultiSigKey
-	channelCapacity := int64(pendingReservation.partialState.Capacity)
+	channelCapacity := int64(pendingReservation.partialState.AssetCapacity)
 	redeemScript, _, err := GenFundingPkScript(ourKey.SerializeCompressed(),
 		theirKey.SerializeCompressed(), channelCapacity)
 	if err != nil {
@@ -870,7 +1415,7 @@ func (l *LightningWallet) handleSingleContribution(req *addSingleContributionMsg
 	// Now that we know their commitment key, we can create the revocation
 	// key for our version of the initial commitment transaction.
 	elkremRoot := deriveElkremRoot(masterElkremRoot, ourKey, theirKey)
-	elkremSender := elkrem.NewElkremSender(elkremRoot)
+	elkremSender := newRevocationProducer(&features, elkremRoot)
 	firstPreimage, err := elkremSender.AtIndex(0)
 	if err != nil {
 		req.err <- err
@@ -883,7 +1428,7 @@ func (l *LightningWallet) handleSingleContribution(req *addSingleContributionMsg
 	// Initialize an empty sha-chain for them, tracking the current pending
 	// revocation hash (we don't yet know the pre-image so we can't add it
 	// to the chain).
-	remoteElkrem := &elkrem.ElkremReceiver{}
+	remoteElkrem := newRevocationStore(&features)
 	pendingReservation.partialState.RemoteElkrem = remoteElkrem
 
 	// Record the counterpaty's remaining contributions to the channel,
@@ -929,7 +1474,7 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 	inputScripts := msg.theirFundingInputScripts
 	fundingTx := pendingReservation.fundingTx
 	sigIndex := 0
-	fundingHashCache := txscript.NewTxSigHashes(fundingTx)
+	var inputs []*inputScriptVerification
 	for i, txin := range fundingTx.TxIn {
 		if len(inputScripts) != 0 && len(txin.Witness) == 0 {
 			// Attach the input scripts so we can verify it below.
@@ -945,24 +1490,25 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 				return
 			}
 
-			// Ensure that the witness+sigScript combo is valid.
-			vm, err := txscript.NewEngine(output.PkScript,
-				fundingTx, i, txscript.StandardVerifyFlags, nil,
-				fundingHashCache, output.Value)
-			if err != nil {
-				// TODO(roasbeef): cancel at this stage if invalid sigs?
-				msg.err <- fmt.Errorf("cannot create script engine: %s", err)
-				return
-			}
-			if err = vm.Execute(); err != nil {
-				msg.err <- fmt.Errorf("cannot validate transaction: %s", err)
-				return
-			}
-
+			inputs = append(inputs, &inputScriptVerification{
+				index:    i,
+				pkScript: output.PkScript,
+				value:    output.Value,
+			})
 			sigIndex++
 		}
 	}
 
+	// Ensure that the witness+sigScript combo of each of their inputs is
+	// valid. As the inputs are independent of one another, they're all
+	// verified at once.
+	// TODO(roasbeef): cancel at this stage if invalid sigs?
+	fundingHashCache := txscript.NewTxSigHashes(fundingTx)
+	if err := verifyInputScripts(fundingTx, fundingHashCache, inputs); err != nil {
+		msg.err <- err
+		return
+	}
+
 	// At this point, we can also record and verify their signature for our
 	// commitment transaction.
 	pendingReservation.theirCommitmentSig = msg.theirCommitmentSig
@@ -977,7 +1523,7 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 	// Next, create the spending scriptSig, and then verify that the script
 	// is complete, allowing us to spend from the funding transaction.
 	theirCommitSig := msg.theirCommitmentSig
-	channelValue := int64(pendingReservation.partialState.Capacity)
+	channelValue := int64(pendingReservation.partialState.BtcCapacity)
 	hashCache := txscript.NewTxSigHashes(commitTx)
 	sigHash, err := txscript.CalcWitnessSigHash(redeemScript, hashCache,
 		txscript.SigHashAll, commitTx, 0, channelValue)
@@ -987,7 +1533,8 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 	}
 
 	walletLog.Infof("sighash verify: %v", hex.EncodeToString(sigHash))
-	walletLog.Infof("initer verifying tx: %v", spew.Sdump(commitTx))
+	walletLog.Infof("initer verifying tx: %v", newTxLogClosure(commitTx,
+		pendingReservation.partialState.AssetID))
 
 	// Verify that we've received a valid signature from the remote party
 	// for our version of the commitment transaction.
@@ -995,11 +1542,14 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 	if err != nil {
 		msg.err <- err
 		return
-	} else if !sig.Verify(sigHash, theirKey) {
+	}
+	sigs := []*sigVerification{{sig: sig, hash: sigHash, pubKey: theirKey}}
+	if err := verifySigs(sigs); err != nil {
 		msg.err <- fmt.Errorf("counterparty's commitment signature is invalid")
 		return
 	}
 	pendingReservation.partialState.OurCommitSig = theirCommitSig
+	pendingReservation.setState(ReservationSigned)
 
 	// Funding complete, this entry can be removed from limbo.
 	l.limboMtx.Lock()
@@ -1011,13 +1561,18 @@ func (l *LightningWallet) handleFundingCounterPartySigs(msg *addCounterPartySigs
 
 	walletLog.Infof("Broadcasting funding tx for ChannelPoint(%v): %v",
 		pendingReservation.partialState.FundingOutpoint,
-		spew.Sdump(fundingTx))
+		newTxLogClosure(fundingTx,
+			pendingReservation.partialState.AssetID))
 
 	// Broacast the finalized funding transaction to the network.
-	if err := l.PublishTransaction(fundingTx); err != nil {
+	err = l.PublishAuditedTransaction(fundingTx, channeldb.TxFunding,
+		*pendingReservation.partialState.FundingOutpoint,
+		pendingReservation.partialState.AssetID)
+	if err != nil {
 		msg.err <- err
 		return
 	}
+	pendingReservation.setState(ReservationBroadcast)
 
 	// Add the complete funding transaction to the DB, in it's open bucket
 	// which will be used for the lifetime of this channel.
@@ -1051,7 +1606,17 @@ func (l *LightningWallet) handleSingleFunderSigs(req *addSingleFunderSigsMsg) {
 	pendingReservation.Lock()
 	defer pendingReservation.Unlock()
 
+	// The initiator colorified the funding transaction, so we rely upon
+	// them for the value of the funding output. It's verified once the
+	// funding transaction has confirmed, before the channel is opened.
+	if req.fundingValue <= 0 {
+		req.err <- fmt.Errorf("invalid funding output value: %v",
+			req.fundingValue)
+		return
+	}
+
 	pendingReservation.partialState.FundingOutpoint = req.fundingOutpoint
+	pendingReservation.partialState.BtcCapacity = req.fundingValue
 	pendingReservation.partialState.TheirCurrentRevocation = req.revokeKey
 	pendingReservation.partialState.ChanID = req.fundingOutpoint
 	fundingTxIn := wire.NewTxIn(req.fundingOutpoint, nil, nil)
@@ -1061,8 +1626,8 @@ func (l *LightningWallet) handleSingleFunderSigs(req *addSingleFunderSigsMsg) {
 	// remote node's commitment transactions.
 	ourCommitKey := pendingReservation.ourContribution.CommitKey
 	theirCommitKey := pendingReservation.theirContribution.CommitKey
-	ourBalance := pendingReservation.ourContribution.FundingAmount
-	theirBalance := pendingReservation.theirContribution.FundingAmount
+	ourBalance := pendingReservation.partialState.OurBalance
+	theirBalance := pendingReservation.partialState.TheirBalance
 	ourCommitTx, err := CreateCommitTx(fundingTxIn, ourCommitKey, theirCommitKey,
 		pendingReservation.ourContribution.RevocationKey,
 		pendingReservation.ourContribution.CsvDelay, ourBalance, theirBalance)
@@ -1079,25 +1644,33 @@ func (l *LightningWallet) handleSingleFunderSigs(req *addSingleFunderSigsMsg) {
 	}
 
 	// Sort both transactions according to the agreed upon cannonical
-	// ordering. This ensures that both parties sign the same sighash
-	// without further synchronization.
-	txsort.InPlaceSort(ourCommitTx)
-	ourCommitTx, err = lndcc.ColorifyTx(ourCommitTx, false)
+	// ordering, then colorify them. This ensures that both parties sign
+	// the same sighash without further synchronization.
+	ourCommitTx, err = colorifyCanonical(ourCommitTx, false)
+	if err != nil {
+		req.err <- err
+		return
+	}
+	err = addCommitAnchors(ourCommitTx, ourCommitKey, theirCommitKey)
 	if err != nil {
 		req.err <- err
 		return
 	}
 	pendingReservation.partialState.OurCommitTx = ourCommitTx
 
-	txsort.InPlaceSort(theirCommitTx)
-	theirCommitTx, err = lndcc.ColorifyTx(theirCommitTx, false)
+	theirCommitTx, err = colorifyCanonical(theirCommitTx, false)
+	if err != nil {
+		req.err <- err
+		return
+	}
+	err = addCommitAnchors(theirCommitTx, theirCommitKey, ourCommitKey)
 	if err != nil {
 		req.err <- err
 		return
 	}
 
 	redeemScript := pendingReservation.partialState.FundingRedeemScript
-	channelValue := int64(pendingReservation.partialState.Capacity)
+	channelValue := int64(pendingReservation.partialState.BtcCapacity)
 	hashCache := txscript.NewTxSigHashes(ourCommitTx)
 	theirKey := pendingReservation.theirContribution.MultiSigKey
 	ourKey := pendingReservation.partialState.OurMultiSigKey
@@ -1115,7 +1688,9 @@ func (l *LightningWallet) handleSingleFunderSigs(req *addSingleFunderSigsMsg) {
 	if err != nil {
 		req.err <- err
 		return
-	} else if !sig.Verify(sigHash, theirKey) {
+	}
+	sigs := []*sigVerification{{sig: sig, hash: sigHash, pubKey: theirKey}}
+	if err := verifySigs(sigs); err != nil {
 		req.err <- fmt.Errorf("counterparty's commitment signature is invalid")
 		return
 	}
@@ -1173,6 +1748,15 @@ func (l *LightningWallet) handleChannelOpen(req *channelOpenMsg) {
 	delete(l.fundingLimbo, res.reservationID)
 	l.limboMtx.Unlock()
 
+	// Ensure the confirmed funding output carries the value the
+	// initiator claimed, as otherwise the signatures for our commitment
+	// transaction would be invalid, leaving us unable to broadcast it.
+	if err := l.verifyFundingValue(res.partialState); err != nil {
+		req.err <- err
+		res.chanOpen <- nil
+		return
+	}
+
 	// Add the complete funding transaction to the DB, in it's open bucket
 	// which will be used for the lifetime of this channel.
 	if err := res.partialState.FullSync(); err != nil {
@@ -1189,54 +1773,173 @@ func (l *LightningWallet) handleChannelOpen(req *channelOpenMsg) {
 	req.err <- nil
 }
 
+// verifyFundingValue checks that the funding output of the passed channel
+// carries the value in satoshis recorded within its state. If the output
+// can't be fetched from the chain, then the check is skipped.
+func (l *LightningWallet) verifyFundingValue(state *channeldb.OpenChannel) error {
+	fundingPoint := state.FundingOutpoint
+	txOut, err := l.chainIO.GetUtxo(&fundingPoint.Hash, fundingPoint.Index)
+	if err != nil {
+		walletLog.Warnf("Unable to fetch funding output %v, skipping "+
+			"verification of its value: %v", fundingPoint, err)
+		return nil
+	}
+
+	if btcutil.Amount(txOut.Value) != state.BtcCapacity {
+		return fmt.Errorf("funding output %v carries %v, expected %v",
+			fundingPoint, btcutil.Amount(txOut.Value),
+			state.BtcCapacity)
+	}
+
+	return nil
+}
+
 // openChannelAfterConfirmations creates, and opens a payment channel after
 // the funding transaction created within the passed channel reservation
-// obtains the specified number of confirmations.
+// obtains the specified number of confirmations. A zero-conf channel is
+// opened immediately, its funding transaction's first confirmation then being
+// watched for as with the re-orgs of any other channel.
 func (l *LightningWallet) openChannelAfterConfirmations(res *ChannelReservation) {
 	// Register with the ChainNotifier for a notification once the funding
 	// transaction reaches `numConfs` confirmations.
 	txid := res.fundingTx.TxSha()
+	zeroConf := res.ZeroConf()
 	numConfs := uint32(res.numConfsToOpen)
+	if zeroConf {
+		numConfs = 1
+	}
 	confNtfn, _ := l.chainNotifier.RegisterConfirmationsNtfn(&txid, numConfs)
 
-	walletLog.Infof("Waiting for funding tx (txid: %v) to reach %v confirmations",
-		txid, numConfs)
+	if zeroConf {
+		walletLog.Infof("Opening zero-conf channel with unconfirmed "+
+			"funding tx (txid: %v)", txid)
+	} else {
+		walletLog.Infof("Waiting for funding tx (txid: %v) to reach "+
+			"%v confirmations", txid, numConfs)
+	}
+	res.setState(ReservationConfirming)
 
 	// Wait until the specified number of confirmations has been reached,
 	// or the wallet signals a shutdown.
 out:
-	select {
-	case _, ok := <-confNtfn.Confirmed:
-		// Reading a falsey value for the second parameter indicates that
-		// the notifier is in the process of shutting down. Therefore, we
-		// don't count this as the signal that the funding transaction has
-		// been confirmed.
-		if !ok {
+	for !zeroConf {
+		select {
+		case confHeight, ok := <-confNtfn.Confirmed:
+			// Reading a falsey value for the second parameter
+			// indicates that the notifier is in the process of
+			// shutting down. Therefore, we don't count this as the
+			// signal that the funding transaction has been
+			// confirmed.
+			if !ok {
+				res.setState(ReservationFailed)
+				res.chanOpen <- nil
+				return
+			}
+
+			// The notification is dispatched at the height of the
+			// block bringing the funding transaction to numConfs
+			// confirmations, so it was included numConfs-1 blocks
+			// prior. This allows us to later prove its inclusion.
+			res.Lock()
+			res.fundingHeight = uint32(confHeight) - numConfs + 1
+			res.Unlock()
+
+			break out
+		case depth, ok := <-confNtfn.NegativeConf:
+			if !ok {
+				res.setState(ReservationFailed)
+				res.chanOpen <- nil
+				return
+			}
+
+			// The funding transaction has been re-org'd out before
+			// reaching the required number of confirmations. The
+			// notification is re-armed by the notifier, so we
+			// simply continue waiting.
+			walletLog.Warnf("Funding tx (txid: %v) re-org'd out at "+
+				"depth %v, waiting for re-confirmation", txid,
+				depth)
+		case <-res.fundingAborted:
+			walletLog.Infof("Funding tx (txid: %v) aborted, no "+
+				"longer waiting for confirmation", txid)
+			res.chanOpen <- nil
+			return
+		case <-l.quit:
+			res.setState(ReservationFailed)
 			res.chanOpen <- nil
 			return
 		}
-
-		break out
-	case <-l.quit:
-		res.chanOpen <- nil
-		return
 	}
 
 	// Finally, create and officially open the payment channel!
 	// TODO(roasbeef): CreationTime once tx is 'open'
 	channel, _ := NewLightningChannel(l.Signer, l.chainIO, l.chainNotifier,
 		res.partialState)
+	if channel != nil {
+		res.setState(ReservationOpen)
+	} else {
+		res.setState(ReservationFailed)
+	}
 	res.chanOpen <- channel
+
+	// Continue to watch the funding transaction for any re-orgs which
+	// would invalidate the channel.
+	if channel != nil {
+		l.wg.Add(1)
+		go l.watchFundingReorgs(channel, confNtfn, &txid)
+	}
+}
+
+// watchFundingReorgs monitors the confirmation status of an open channel's
+// funding transaction. If the funding transaction is disconnected from the
+// main chain, the open channel transitions into the channelReorged state,
+// rejecting any new HTLCs, until the funding transaction has been
+// re-confirmed.
+//
+// NOTE: This MUST be run as a goroutine.
+func (l *LightningWallet) watchFundingReorgs(channel *LightningChannel,
+	confNtfn *chainntnfs.ConfirmationEvent, txid *wire.ShaHash) {
+
+	defer l.wg.Done()
+
+	for {
+		select {
+		case depth, ok := <-confNtfn.NegativeConf:
+			if !ok {
+				return
+			}
+
+			walletLog.Warnf("Funding tx (txid: %v) of open channel "+
+				"re-org'd out at depth %v", txid, depth)
+
+			// No new HTLCs may be added until the funding
+			// transaction has been re-confirmed.
+			channel.setReorged(true)
+		case _, ok := <-confNtfn.Confirmed:
+			if !ok {
+				return
+			}
+
+			if channel.setReorged(false) {
+				walletLog.Infof("Funding tx (txid: %v) "+
+					"re-confirmed, re-opening channel", txid)
+			}
+		case <-l.quit:
+			return
+		}
+	}
 }
 
 // selectCoinsAndChange performs coin selection in order to obtain witness
 // outputs which sum to at least 'numCoins' amount of satoshis. If coin
 // selection is succesful/possible, then the selected coins are available
 // within the passed contribution's inputs. If necessary, a change address will
-// also be generated.
+// also be generated. Should the selected coins carry too few satoshis to pay
+// for the carrier outputs, and fee of the funding transaction, then fuel
+// outputs are selected as well, with any fuel change returned.
 // TODO(roasbeef): remove hardcoded fees and req'd confs for outputs.
 func (l *LightningWallet) selectCoinsAndChange(feeRate uint64, amt btcutil.Amount,
-	contribution *ChannelContribution) error {
+	contribution *ChannelContribution) (*wire.TxOut, error) {
 
 	// We hold the coin select mutex while querying for outputs, and
 	// performing coin selection in order to avoid inadvertent double
@@ -1249,7 +1952,7 @@ func (l *LightningWallet) selectCoinsAndChange(feeRate uint64, amt btcutil.Amoun
 	// TODO(roasbeef): make num confs a configuration paramter
 	coins, err := l.ListUnspentWitness(1)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Peform coin selection over our available, unlocked unspent outputs
@@ -1257,32 +1960,57 @@ func (l *LightningWallet) selectCoinsAndChange(feeRate uint64, amt btcutil.Amoun
 	// requirements.
 	selectedCoins, changeAmt, err := coinSelect(feeRate, amt, coins, globallyActiveAssetId)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Each of our outputs within the funding transaction, the funding
+	// output itself and any change, is given a carrier amount of
+	// satoshis. If the satoshis carried by the selected coins don't cover
+	// these, and the fee, then top them up with fuel.
+	numOutputs := 1
+	if changeAmt != 0 {
+		numOutputs++
 	}
+	carrierAmt := lndcc.FundingOutputValue() * btcutil.Amount(numOutputs)
+	carried := carriedValue(coins, selectedCoins)
+	fuel, fuelChangeAmt, err := fuelSelect(feeRate, carrierAmt, carried,
+		len(selectedCoins), numOutputs, coins)
+	if err != nil {
+		return nil, err
+	}
+	if len(fuel) != 0 {
+		l.checkFuel(coins, fuel)
+	}
+	selectedCoins = append(selectedCoins, fuel...)
 
 	// Lock the selected coins. These coins are now "reserved", this
 	// prevents concurrent funding requests from referring to and this
 	// double-spending the same set of coins.
 	contribution.Inputs = make([]*wire.TxIn, len(selectedCoins))
 	for i, coin := range selectedCoins {
-		l.lockedOutPoints[*coin] = struct{}{}
-		l.LockOutpoint(*coin)
+		if err := l.lockOutpoint(*coin); err != nil {
+			for _, lockedCoin := range selectedCoins[:i] {
+				l.unlockOutpoint(*lockedCoin)
+			}
+			return nil, err
+		}
 
 		// Empty sig script, we'll actually sign if this reservation is
 		// queued up to be completed (the other side accepts).
 		contribution.Inputs[i] = wire.NewTxIn(coin, nil, nil)
+		contribution.Inputs[i].Sequence = fundingInputSequence
 	}
 
 	// Record any change output(s) generated as a result of the coin
 	// selection.
 	if changeAmt != 0 {
-		changeAddr, err := l.NewAddress(WitnessPubKey, true)
+		changeAddr, err := l.NewChannelAddress(WitnessPubKey, true)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		changeScript, err := txscript.PayToAddrScript(changeAddr)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		contribution.ChangeOutputs = make([]*wire.TxOut, 1)
@@ -1292,7 +2020,168 @@ func (l *LightningWallet) selectCoinsAndChange(feeRate uint64, amt btcutil.Amoun
 		}
 	}
 
-	return nil
+	// Any fuel left over is returned to us within an uncolored output,
+	// which is added to the funding transaction once colorified.
+	if fuelChangeAmt == 0 {
+		return nil, nil
+	}
+	fuelChangeAddr, err := l.NewChannelAddress(WitnessPubKey, true)
+	if err != nil {
+		return nil, err
+	}
+	fuelChangeScript, err := txscript.PayToAddrScript(fuelChangeAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	return wire.NewTxOut(int64(fuelChangeAmt), fuelChangeScript), nil
+}
+
+// carriedValue returns the total number of satoshis carried by the selected
+// outputs among the passed coins.
+func carriedValue(coins []*Utxo, selectedCoins []*wire.OutPoint) btcutil.Amount {
+	selected := make(map[wire.OutPoint]struct{}, len(selectedCoins))
+	for _, coin := range selectedCoins {
+		selected[*coin] = struct{}{}
+	}
+
+	var carried btcutil.Amount
+	for _, coin := range coins {
+		if _, ok := selected[coin.OutPoint]; ok {
+			carried += coin.Value
+		}
+	}
+
+	return carried
+}
+
+// TxEstimate is the result of a dry-run transaction construction. It details
+// the inputs which would be selected to fund a set of outputs, along with the
+// estimated virtual size, fee, and change of the final transaction.
+type TxEstimate struct {
+	// Inputs is the set of outpoints selected to fund the transaction.
+	// NOTE: these outpoints are NOT locked.
+	Inputs []*wire.OutPoint
+
+	// VSize is the estimated virtual size of the fully signed transaction,
+	// including the colored coins OP_RETURN output, and the change and
+	// fuel change outputs if they're required.
+	VSize int
+
+	// Fee is the fee in satoshis required to pay for the transaction at
+	// the requested fee rate.
+	Fee btcutil.Amount
+
+	// Change is the amount of the active asset which will be returned to
+	// the wallet as change.
+	Change btcutil.Amount
+
+	// FuelChange is the amount of satoshis which will be returned to the
+	// wallet within an uncolored output, after paying for the carrier
+	// outputs and fee.
+	FuelChange btcutil.Amount
+}
+
+// EstimateTx performs coin selection over the wallet's available outputs in
+// order to fund the passed outputs, returning the estimated size, fee and
+// change of the resulting transaction. The outputs carry their value in
+// satoshis, while assetAmt is the total amount of the active asset paid to
+// them. Outputs of the active asset are selected to pay the latter, with
+// fuel added should they carry too few satoshis to pay for the former, and
+// the fee. No outputs are signed or locked, so this method is safe to use in
+// order to quote the cost of a funding transaction or to negotiate a closing
+// fee. The fee rate should be expressed in sat/byte.
+func (l *LightningWallet) EstimateTx(outputs []*wire.TxOut,
+	assetAmt btcutil.Amount, feeRate uint64) (*TxEstimate, error) {
+
+	// We grab the coin select mutex in order to ensure that the view of
+	// the wallet's unlocked outputs doesn't shift underneath us. Any
+	// outputs already reserved by a pending funding workflow are excluded
+	// from the returned list.
+	l.coinSelectMtx.Lock()
+	defer l.coinSelectMtx.Unlock()
+
+	coins, err := l.ListUnspentWitness(1)
+	if err != nil {
+		return nil, err
+	}
+
+	selectedCoins, changeAmt, err := coinSelect(feeRate, assetAmt, coins,
+		globallyActiveAssetId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any asset change is returned within an output of its own, carrying
+	// the same amount of satoshis as a funding output.
+	var carrierAmt btcutil.Amount
+	for _, output := range outputs {
+		carrierAmt += btcutil.Amount(output.Value)
+	}
+	numOutputs := len(outputs)
+	if changeAmt != 0 {
+		carrierAmt += lndcc.FundingOutputValue()
+		numOutputs++
+	}
+
+	carried := carriedValue(coins, selectedCoins)
+	fuel, fuelChangeAmt, err := fuelSelect(feeRate, carrierAmt, carried,
+		len(selectedCoins), numOutputs, coins)
+	if err != nil {
+		return nil, err
+	}
+	selectedCoins = append(selectedCoins, fuel...)
+	if fuelChangeAmt != 0 {
+		numOutputs++
+	}
+
+	vsize := estimateVSize(len(selectedCoins), numOutputs)
+	return &TxEstimate{
+		Inputs:     selectedCoins,
+		VSize:      vsize,
+		Fee:        btcutil.Amount(uint64(vsize) * feeRate),
+		Change:     changeAmt,
+		FuelChange: fuelChangeAmt,
+	}, nil
+}
+
+// estimateVSize returns an estimate of the virtual size of a transaction
+// spending numInputs p2wkh outputs, and creating numOutputs p2wsh outputs
+// along with the OP_RETURN output carrying the colored coins instructions.
+// Witness data is discounted by a factor of four.
+func estimateVSize(numInputs, numOutputs int) int {
+	const (
+		// txOverhead is the overhead of a transaction residing within
+		// the version number, lock time, and the input/output counts.
+		txOverhead = 4 + 4 + 1 + 1
+
+		// witnessOverhead is the size of the segwit marker and flag,
+		// which are witness data, so they only weigh 1 WU each.
+		witnessOverhead = 1 + 1
+
+		// p2wkhInputSize is the size of the non-witness portion of an
+		// input: txid + index + varint script size + sequence.
+		p2wkhInputSize = 32 + 4 + 1 + 4
+
+		// p2wkhWitnessSize is the size of the witness spending a p2wkh
+		// output: item count + sig + pubkey.
+		p2wkhWitnessSize = 1 + 1 + 73 + 1 + 33
+
+		// p2wshOutputSize is an estimate of the size of a p2wsh
+		// output: 8 (value) + 1 (var int script) + 34 (p2wsh script).
+		p2wshOutputSize = 8 + 1 + 34
+
+		// ccOutputSize is an estimate of the size of the OP_RETURN
+		// output holding the encoded colored coins instructions, which
+		// is bounded by the 80 byte standardness limit.
+		ccOutputSize = 8 + 1 + 83
+	)
+
+	baseSize := txOverhead + (numInputs * p2wkhInputSize) +
+		(numOutputs * p2wshOutputSize) + ccOutputSize
+	witnessSize := witnessOverhead + (numInputs * p2wkhWitnessSize)
+
+	return baseSize + (witnessSize+3)/4
 }
 
 // deriveMasterElkremRoot derives the private key which serves as the master
@@ -0,0 +1,392 @@
+package lnwallet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/chaincfg"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// mockSyncState is a WalletController whose sync state is fixed.
+type mockSyncState struct {
+	WalletController
+
+	synced bool
+	err    error
+}
+
+func (m *mockSyncState) IsSynced() (bool, error) {
+	return m.synced, m.err
+}
+
+// mockChainIO is a BlockChainIO backed by an in-memory chain of blocks, and
+// set of unspent outputs.
+type mockChainIO struct {
+	blocks []*wire.MsgBlock
+
+	// spentOutputs is the set of outputs reported as spent by GetUtxo.
+	spentOutputs map[wire.OutPoint]struct{}
+
+	// utxos is the set of outputs reported as unspent by GetUtxo.
+	utxos map[wire.OutPoint]*wire.TxOut
+
+	// unconfirmed is the set of outputs reported as unconfirmed by
+	// GetUtxo.
+	unconfirmed map[wire.OutPoint]struct{}
+
+	// txns are the transactions returned by GetTransaction.
+	txns map[wire.ShaHash]*wire.MsgTx
+}
+
+func (m *mockChainIO) GetCurrentHeight() (int32, error) {
+	return int32(len(m.blocks) - 1), nil
+}
+
+func (m *mockChainIO) GetUtxo(txid *wire.ShaHash,
+	index uint32) (*wire.TxOut, error) {
+
+	if _, ok := m.spentOutputs[*wire.NewOutPoint(txid, index)]; ok {
+		return nil, ErrOutputSpent
+	}
+	if txOut, ok := m.utxos[*wire.NewOutPoint(txid, index)]; ok {
+		return txOut, nil
+	}
+	if _, ok := m.unconfirmed[*wire.NewOutPoint(txid, index)]; ok {
+		return nil, ErrOutputUnconfirmed
+	}
+
+	return nil, ErrOutputNotFound
+}
+
+func (m *mockChainIO) GetTransaction(txid *wire.ShaHash) (*wire.MsgTx, error) {
+	if tx, ok := m.txns[*txid]; ok {
+		return tx, nil
+	}
+
+	return nil, fmt.Errorf("transaction not found")
+}
+
+func (m *mockChainIO) GetBlock(blockHash *wire.ShaHash) (*wire.MsgBlock, error) {
+	for _, block := range m.blocks {
+		if block.BlockSha() == *blockHash {
+			return block, nil
+		}
+	}
+
+	return nil, fmt.Errorf("block %v not found", blockHash)
+}
+
+func (m *mockChainIO) GetBlockHash(blockHeight int64) (*wire.ShaHash, error) {
+	if blockHeight < 0 || blockHeight >= int64(len(m.blocks)) {
+		return nil, fmt.Errorf("no block at height %v", blockHeight)
+	}
+
+	hash := m.blocks[blockHeight].BlockSha()
+	return &hash, nil
+}
+
+// TestReserveWalletNotSynced tests that no funding workflow is started until
+// the backing wallet has fully synced to the main chain.
+func TestReserveWalletNotSynced(t *testing.T) {
+	syncState := &mockSyncState{}
+	wallet := &LightningWallet{
+		cfg:              DefaultConfig(),
+		WalletController: syncState,
+		queues:           newRequestQueues(),
+		fundingLimbo:     make(map[uint64]*ChannelReservation),
+		quit:             make(chan struct{}),
+	}
+	wallet.wg.Add(1)
+	go wallet.requestHandler()
+	defer func() {
+		close(wallet.quit)
+		wallet.wg.Wait()
+	}()
+
+	reserve := func() error {
+		res, err := wallet.InitChannelReservation(1000, 0, [32]byte{},
+			1, DefaultMinCsvDelay)
+		if res != nil {
+			t.Fatalf("reservation created by syncing wallet")
+		}
+		return err
+	}
+
+	if err := reserve(); err != ErrWalletNotSynced {
+		t.Fatalf("expected ErrWalletNotSynced, got %v", err)
+	}
+
+	// An error querying the sync state is returned as is.
+	syncState.err = errors.New("backend unreachable")
+	if err := reserve(); err != syncState.err {
+		t.Fatalf("expected %v, got %v", syncState.err, err)
+	}
+
+	// Once synced, the request carries on to the remaining checks, so
+	// it's refused for the unknown asset instead.
+	syncState.synced = true
+	syncState.err = nil
+	wallet.assetErr = errors.New("unknown asset")
+	if err := reserve(); err != wallet.assetErr {
+		t.Fatalf("expected %v, got %v", wallet.assetErr, err)
+	}
+}
+
+// TestChannelAcceptor tests that the configured ChannelAcceptor is consulted
+// with the details of a valid inbound channel, and that the channel is
+// rejected, without recording the remote contribution, if it refuses it.
+func TestChannelAcceptor(t *testing.T) {
+	cfg := DefaultConfig()
+	capacity := btcutil.Amount(10000)
+
+	newKey := func() *btcec.PublicKey {
+		priv, err := btcec.NewPrivateKey(btcec.S256())
+		if err != nil {
+			t.Fatalf("unable to generate key: %v", err)
+		}
+		return priv.PubKey()
+	}
+
+	peerID := [32]byte{0x01}
+	res := &ChannelReservation{
+		reservationID: 1,
+		ourContribution: &ChannelContribution{
+			CommitKey:   newKey(),
+			AssetParams: cfg.assetParams(capacity),
+		},
+		partialState: &channeldb.OpenChannel{
+			TheirLNID:      peerID,
+			AssetCapacity:  capacity,
+			OurMultiSigKey: newKey(),
+		},
+	}
+
+	var acceptReqs []*ChannelAcceptRequest
+	cfg.ChannelAcceptor = ChannelAcceptorFunc(
+		func(req *ChannelAcceptRequest) error {
+			acceptReqs = append(acceptReqs, req)
+			return errors.New("no channels today")
+		})
+	wallet := &LightningWallet{
+		cfg: cfg,
+		fundingLimbo: map[uint64]*ChannelReservation{
+			res.reservationID: res,
+		},
+	}
+
+	theirKey := newKey()
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(
+		btcutil.Hash160(theirKey.SerializeCompressed()),
+		&chaincfg.TestNet3Params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	contribution := &ChannelContribution{
+		FundingAmount:   capacity,
+		MultiSigKey:     theirKey,
+		CommitKey:       theirKey,
+		DeliveryAddress: addr,
+		CsvDelay:        cfg.MinCsvDelay,
+		AssetParams: AssetParams{
+			AssetID:          res.ourContribution.AssetParams.AssetID,
+			DustLimit:        10,
+			MaxHTLCValue:     5000,
+			CarrierSatBudget: DefaultCarrierSatBudget,
+		},
+	}
+	addContribution := func(c *ChannelContribution) error {
+		errChan := make(chan error, 1)
+		wallet.handleSingleContribution(&addSingleContributionMsg{
+			pendingFundingID: res.reservationID,
+			contribution:     c,
+			err:              errChan,
+		})
+		return <-errChan
+	}
+
+	// An invalid contribution is rejected before the acceptor is
+	// consulted.
+	invalid := *contribution
+	invalid.MultiSigKey = nil
+	if err := addContribution(&invalid); err == nil {
+		t.Fatalf("invalid contribution accepted")
+	}
+	if len(acceptReqs) != 0 {
+		t.Fatalf("acceptor consulted for invalid contribution")
+	}
+
+	err = addContribution(contribution)
+	if err == nil ||
+		!strings.HasPrefix(err.Error(), ErrChannelRejected.Error()) {
+
+		t.Fatalf("expected ErrChannelRejected, got %v", err)
+	}
+	if res.theirContribution != nil {
+		t.Fatalf("rejected contribution recorded within reservation")
+	}
+
+	if len(acceptReqs) != 1 {
+		t.Fatalf("expected acceptor to be consulted once, got %v",
+			len(acceptReqs))
+	}
+	expectedReq := ChannelAcceptRequest{
+		PeerID:   peerID,
+		Capacity: capacity,
+		AssetID:  contribution.AssetParams.AssetID,
+		CsvDelay: contribution.CsvDelay,
+	}
+	if *acceptReqs[0] != expectedReq {
+		t.Fatalf("expected accept request %v, got %v", expectedReq,
+			*acceptReqs[0])
+	}
+}
+
+// TestVerifyRemoteInputs tests that the inputs contributed by the remote
+// party must be unspent outputs carrying enough of the channel's asset to
+// cover their funding amount and change, and enough fuel to cover their fuel
+// change.
+func TestVerifyRemoteInputs(t *testing.T) {
+	const assetID = "asset"
+
+	defer func(encoder func([]lndcc.Instruction) ([]byte, error)) {
+		lndcc.Encoder = encoder
+	}(lndcc.Encoder)
+
+	// A coinbase transaction, whose outputs never carry any asset.
+	coinbase := wire.NewMsgTx()
+	coinbase.AddTxIn(wire.NewTxIn(
+		wire.NewOutPoint(&wire.ShaHash{}, math.MaxUint32), nil, nil))
+	coinbase.AddTxOut(wire.NewTxOut(5000, nil))
+	coinbaseHash := coinbase.TxSha()
+
+	first := wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 0}
+	second := wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 1}
+	uncolored := wire.OutPoint{Hash: coinbaseHash, Index: 0}
+	spent := wire.OutPoint{Hash: wire.ShaHash{0x02}, Index: 0}
+	unknown := wire.OutPoint{Hash: wire.ShaHash{0x03}, Index: 0}
+
+	chain := &mockChainIO{
+		spentOutputs: map[wire.OutPoint]struct{}{
+			spent: struct{}{},
+		},
+		utxos: map[wire.OutPoint]*wire.TxOut{
+			first:     wire.NewTxOut(1000, nil),
+			second:    wire.NewTxOut(1000, nil),
+			uncolored: coinbase.TxOut[0],
+		},
+		txns: map[wire.ShaHash]*wire.MsgTx{
+			coinbaseHash: coinbase,
+		},
+	}
+	kernel := lndcc.NewLocalKernel(chain, assetID)
+	kernel.Issue(first, 100)
+	kernel.Issue(second, 50)
+	kernel.Issue(spent, 100)
+	lndcc.UseLocalKernel(kernel)
+
+	wallet := &LightningWallet{chainIO: chain}
+
+	newContribution := func(change int64,
+		inputs ...wire.OutPoint) *ChannelContribution {
+
+		c := &ChannelContribution{FundingAmount: 120}
+		if change != 0 {
+			c.ChangeOutputs = []*wire.TxOut{wire.NewTxOut(change, nil)}
+		}
+		for i := range inputs {
+			c.Inputs = append(c.Inputs,
+				wire.NewTxIn(&inputs[i], nil, nil))
+		}
+		return c
+	}
+
+	err := wallet.verifyRemoteInputs(newContribution(30, first, second),
+		assetID)
+	if err != nil {
+		t.Fatalf("valid inputs rejected: %v", err)
+	}
+	err = wallet.verifyRemoteInputs(newContribution(30, first, second,
+		uncolored), assetID)
+	if err != nil {
+		t.Fatalf("valid inputs with fuel rejected: %v", err)
+	}
+
+	// The satoshis of fuel change are covered by the fuel inputs alone,
+	// without counting towards the asset amount.
+	withFuelChange := func(c *ChannelContribution,
+		fuelChange int64) *ChannelContribution {
+
+		c.FuelChangeOutputs = []*wire.TxOut{
+			wire.NewTxOut(fuelChange, nil),
+		}
+		return c
+	}
+	err = wallet.verifyRemoteInputs(withFuelChange(newContribution(30,
+		first, second, uncolored), 4000), assetID)
+	if err != nil {
+		t.Fatalf("valid inputs with fuel change rejected: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		contribution *ChannelContribution
+		assetID      string
+	}{
+		{
+			name:         "insufficient asset amount",
+			contribution: newContribution(31, first, second),
+			assetID:      assetID,
+		},
+		{
+			name:         "spent input",
+			contribution: newContribution(0, first, spent),
+			assetID:      assetID,
+		},
+		{
+			name:         "unknown input",
+			contribution: newContribution(0, first, unknown),
+			assetID:      assetID,
+		},
+		{
+			// Uncolored inputs are only accepted as fuel, so they
+			// don't count towards the asset amount.
+			name: "uncolored input",
+			contribution: newContribution(31, first, second,
+				uncolored),
+			assetID: assetID,
+		},
+		{
+			name:         "different asset",
+			contribution: newContribution(0, first, second),
+			assetID:      "other",
+		},
+		{
+			name: "insufficient fuel",
+			contribution: withFuelChange(newContribution(30, first,
+				second, uncolored), 6000),
+			assetID: assetID,
+		},
+		{
+			name: "fuel change without fuel",
+			contribution: withFuelChange(newContribution(30, first,
+				second), 1000),
+			assetID: assetID,
+		},
+	}
+	for _, test := range testCases {
+		err := wallet.verifyRemoteInputs(test.contribution, test.assetID)
+		rejectErr, ok := err.(*ContributionError)
+		if !ok || rejectErr.Reason != RejectRemoteInput {
+			t.Fatalf("%v: expected RejectRemoteInput, got %v",
+				test.name, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package lnwallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// ErrUnconfirmedFundingMismatch is returned when the funding transaction of a
+// zero-conf channel doesn't fund the channel described by the reservation.
+var ErrUnconfirmedFundingMismatch = errors.New("unconfirmed funding " +
+	"transaction doesn't match the channel state")
+
+// ZeroConf returns true if the channel is to be opened as soon as its funding
+// transaction has been broadcast, without waiting for any confirmations.
+// This is requested by the initiator by requiring zero confirmations to open.
+func (r *ChannelReservation) ZeroConf() bool {
+	return r.numConfsToOpen == 0
+}
+
+// VerifyUnconfirmedFunding verifies the funding transaction of a zero-conf
+// channel to which we are the responder. As the transaction may not yet have
+// been mined, no SPV proof is available, so the transaction is instead
+// fetched from the mempool of our chain backend. The funding output must pay
+// the channel's capacity in satoshis to the channel's multi-sig script, and
+// carry the channel's asset capacity, as reported by the colored coins
+// service.
+func (r *ChannelReservation) VerifyUnconfirmedFunding() error {
+	r.Lock()
+	defer r.Unlock()
+
+	fundingPoint := r.partialState.FundingOutpoint
+	if fundingPoint == nil {
+		return fmt.Errorf("funding outpoint not yet known")
+	}
+
+	fundingTx, err := r.wallet.chainIO.GetTransaction(&fundingPoint.Hash)
+	if err != nil {
+		return fmt.Errorf("unable to fetch funding tx %v: %v",
+			fundingPoint.Hash, err)
+	}
+	txoData, err := lndcc.GetTxoData(*fundingPoint)
+	if err != nil {
+		return fmt.Errorf("unable to fetch color data of funding "+
+			"output %v: %v", fundingPoint, err)
+	}
+
+	return checkUnconfirmedFunding(fundingTx, r.partialState, txoData)
+}
+
+// checkUnconfirmedFunding checks that the funding output of the passed
+// transaction funds the channel described by state, carrying the color data
+// reported for it.
+func checkUnconfirmedFunding(fundingTx *wire.MsgTx,
+	state *channeldb.OpenChannel, txoData *lndcc.TxoData) error {
+
+	fundingPoint := state.FundingOutpoint
+	if fundingTx.TxSha() != fundingPoint.Hash ||
+		int(fundingPoint.Index) >= len(fundingTx.TxOut) {
+
+		return fmt.Errorf("%v: transaction %v lacks funding output %v",
+			ErrUnconfirmedFundingMismatch, fundingTx.TxSha(),
+			fundingPoint)
+	}
+
+	p2wsh, err := witnessScriptHash(state.FundingRedeemScript)
+	if err != nil {
+		return err
+	}
+	txOut := fundingTx.TxOut[fundingPoint.Index]
+	if !bytes.Equal(txOut.PkScript, p2wsh) {
+		return fmt.Errorf("%v: funding output %v doesn't pay to the "+
+			"channel's multi-sig script", ErrUnconfirmedFundingMismatch,
+			fundingPoint)
+	}
+	if btcutil.Amount(txOut.Value) != state.BtcCapacity {
+		return fmt.Errorf("%v: funding output %v carries %v, expected "+
+			"%v", ErrUnconfirmedFundingMismatch, fundingPoint,
+			btcutil.Amount(txOut.Value), state.BtcCapacity)
+	}
+
+	if txoData.AssetId != state.AssetID ||
+		txoData.Value != state.AssetCapacity {
+
+		return fmt.Errorf("%v: funding output %v carries %v, expected "+
+			"%v of %v", ErrUnconfirmedFundingMismatch, fundingPoint,
+			txoData, state.AssetCapacity, state.AssetID)
+	}
+
+	return nil
+}
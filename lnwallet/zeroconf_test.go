@@ -0,0 +1,127 @@
+package lnwallet
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestCheckUnconfirmedFunding tests that the unconfirmed funding transaction
+// of a zero-conf channel is only accepted if its funding output pays the
+// channel's satoshi capacity to the multi-sig script, and carries the
+// channel's asset capacity.
+func TestCheckUnconfirmedFunding(t *testing.T) {
+	redeemScript := []byte{1, 2, 3}
+	p2wsh, err := witnessScriptHash(redeemScript)
+	if err != nil {
+		t.Fatalf("unable to create p2wsh: %v", err)
+	}
+
+	fundingTx := wire.NewMsgTx()
+	fundingTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	fundingTx.AddTxOut(wire.NewTxOut(1000, []byte{0x6a}))
+	fundingTx.AddTxOut(wire.NewTxOut(5000, p2wsh))
+	fundingHash := fundingTx.TxSha()
+
+	newState := func() *channeldb.OpenChannel {
+		return &channeldb.OpenChannel{
+			FundingOutpoint:     wire.NewOutPoint(&fundingHash, 1),
+			FundingRedeemScript: redeemScript,
+			BtcCapacity:         5000,
+			AssetID:             "assetA",
+			AssetCapacity:       300,
+		}
+	}
+	txoData := &lndcc.TxoData{AssetId: "assetA", Value: 300}
+
+	if err := checkUnconfirmedFunding(fundingTx, newState(), txoData); err != nil {
+		t.Fatalf("valid funding tx rejected: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(*channeldb.OpenChannel)
+		txoData *lndcc.TxoData
+	}{
+		{
+			name: "missing output",
+			mutate: func(s *channeldb.OpenChannel) {
+				s.FundingOutpoint = wire.NewOutPoint(&fundingHash, 2)
+			},
+		},
+		{
+			name: "wrong script",
+			mutate: func(s *channeldb.OpenChannel) {
+				s.FundingRedeemScript = []byte{4}
+			},
+		},
+		{
+			name: "wrong satoshi capacity",
+			mutate: func(s *channeldb.OpenChannel) {
+				s.BtcCapacity = 6000
+			},
+		},
+		{
+			name:    "wrong asset",
+			txoData: &lndcc.TxoData{AssetId: "assetB", Value: 300},
+		},
+		{
+			name:    "wrong asset capacity",
+			txoData: &lndcc.TxoData{AssetId: "assetA", Value: 200},
+		},
+	}
+	for _, test := range tests {
+		state := newState()
+		if test.mutate != nil {
+			test.mutate(state)
+		}
+		data := txoData
+		if test.txoData != nil {
+			data = test.txoData
+		}
+
+		if err := checkUnconfirmedFunding(fundingTx, state, data); err == nil {
+			t.Fatalf("%v: invalid funding tx accepted", test.name)
+		}
+	}
+}
+
+// TestLoadUnconfirmedChannel tests that a zero-conf channel loaded while its
+// funding transaction is still unconfirmed is flagged as such, rather than
+// as closed while we were offline.
+func TestLoadUnconfirmedChannel(t *testing.T) {
+	aliceChannel, _, cleanUp, err := createTestChannels(1)
+	if err != nil {
+		t.Fatalf("unable to create test channels: %v", err)
+	}
+	defer cleanUp()
+
+	// The chain has no confirmed output for the channel, as its funding
+	// transaction is only within the mempool.
+	state := aliceChannel.channelState
+	chain := &mockChainIO{
+		unconfirmed: map[wire.OutPoint]struct{}{
+			*state.FundingOutpoint: struct{}{},
+		},
+	}
+	channel, err := NewLightningChannel(aliceChannel.signer, chain,
+		aliceChannel.channelEvents, state)
+	if err != nil {
+		t.Fatalf("unable to load channel: %v", err)
+	}
+	defer channel.Stop()
+
+	if !channel.FundingUnconfirmed {
+		t.Fatalf("channel not flagged as unconfirmed")
+	}
+	if channel.FundingSpentOffline {
+		t.Fatalf("unconfirmed channel flagged as spent offline")
+	}
+	select {
+	case <-channel.UnilateralCloseSignal:
+		t.Fatalf("close observer signalled for unconfirmed channel")
+	default:
+	}
+}
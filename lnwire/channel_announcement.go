@@ -0,0 +1,173 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// ChannelAnnouncement is gossiped between nodes in order to advertise a
+// channel to the rest of the network, allowing it to be used when routing
+// payments. As colored channels are each denominated in a single asset, the
+// announcement carries the channel's asset, along with the fee policy for
+// forwarding HTLCs of that asset over the channel. Upon receipt, the color of
+// the funding output SHOULD be verified against the colored coins service
+// before the channel is added to the routing graph and relayed further.
+type ChannelAnnouncement struct {
+	// ChannelPoint is the outpoint of the funding output of the channel
+	// being announced.
+	ChannelPoint *wire.OutPoint
+
+	// NodeID1 and NodeID2 are the lightning IDs of the two endpoints of
+	// the channel.
+	NodeID1 [32]byte
+	NodeID2 [32]byte
+
+	// AssetID is the identifier of the colored asset the channel is
+	// denominated in. An empty AssetID denotes a channel paying satoshis.
+	AssetID string
+
+	// Capacity is the total amount of the asset held within the funding
+	// output of the channel.
+	Capacity btcutil.Amount
+
+	// FeeBase is the fixed fee, denominated in the channel's asset,
+	// charged for forwarding an HTLC over the channel.
+	FeeBase btcutil.Amount
+
+	// FeeRate is the proportional fee, in millionths of the amount
+	// forwarded, charged for forwarding an HTLC over the channel.
+	FeeRate uint32
+
+	// CarrierFee is the number of satoshis charged for forwarding an HTLC
+	// over the channel, covering the dust output carrying the HTLC's
+	// asset within the commitment transactions.
+	CarrierFee btcutil.Amount
+
+	// TimeLockDelta is the minimum number of blocks required between the
+	// expiry of an incoming HTLC, and the expiry of the HTLC it's
+	// forwarded as over the channel.
+	TimeLockDelta uint32
+}
+
+// A compile time check to ensure ChannelAnnouncement implements the
+// lnwire.Message interface.
+var _ Message = (*ChannelAnnouncement)(nil)
+
+// Decode deserializes a serialized ChannelAnnouncement stored in the passed
+// io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelAnnouncement) Decode(r io.Reader, pver uint32) error {
+	// ChannelPoint (36)
+	// NodeID1 (32)
+	// NodeID2 (32)
+	// AssetID (max 1 + 64)
+	// Capacity (8)
+	// FeeBase (8)
+	// FeeRate (4)
+	// CarrierFee (8)
+	// TimeLockDelta (4)
+	err := readElements(r,
+		&c.ChannelPoint,
+		&c.NodeID1,
+		&c.NodeID2,
+		&c.AssetID,
+		&c.Capacity,
+		&c.FeeBase,
+		&c.FeeRate,
+		&c.CarrierFee,
+		&c.TimeLockDelta)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target ChannelAnnouncement into the passed io.Writer
+// observing the protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelAnnouncement) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		c.ChannelPoint,
+		c.NodeID1,
+		c.NodeID2,
+		c.AssetID,
+		c.Capacity,
+		c.FeeBase,
+		c.FeeRate,
+		c.CarrierFee,
+		c.TimeLockDelta)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelAnnouncement) Command() uint32 {
+	return CmdChannelAnnouncement
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a
+// ChannelAnnouncement observing the specified protocol version. The final
+// breakdown is: 36 + 32 + 32 + (1 + 64) + 8 + 8 + 4 + 8 + 4 = 197.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelAnnouncement) MaxPayloadLength(uint32) uint32 {
+	return 197
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the ChannelAnnouncement are valid.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelAnnouncement) Validate() error {
+	if c.ChannelPoint == nil {
+		return fmt.Errorf("ChannelPoint must be non-nil")
+	}
+	if c.NodeID1 == c.NodeID2 {
+		return fmt.Errorf("Channel endpoints must be distinct")
+	}
+	if len(c.AssetID) > MaxAssetIDLength {
+		return fmt.Errorf("AssetID cannot exceed %v bytes",
+			MaxAssetIDLength)
+	}
+
+	// The capacity MUST be positive, and the fees MUST NOT be negative.
+	if c.Capacity <= 0 {
+		return fmt.Errorf("Capacity must be positive")
+	}
+	if c.FeeBase < 0 || c.CarrierFee < 0 {
+		return fmt.Errorf("Fees cannot be negative")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target
+// ChannelAnnouncement.
+//
+// This is part of the lnwire.Message interface.
+func (c *ChannelAnnouncement) String() string {
+	return fmt.Sprintf("\n--- Begin ChannelAnnouncement ---\n") +
+		fmt.Sprintf("ChannelPoint:\t\t%v\n", c.ChannelPoint) +
+		fmt.Sprintf("NodeID1:\t\t%x\n", c.NodeID1) +
+		fmt.Sprintf("NodeID2:\t\t%x\n", c.NodeID2) +
+		fmt.Sprintf("AssetID:\t\t%s\n", c.AssetID) +
+		fmt.Sprintf("Capacity:\t\t%d\n", c.Capacity) +
+		fmt.Sprintf("FeeBase:\t\t%d\n", c.FeeBase) +
+		fmt.Sprintf("FeeRate:\t\t%d\n", c.FeeRate) +
+		fmt.Sprintf("CarrierFee:\t\t%d\n", c.CarrierFee) +
+		fmt.Sprintf("TimeLockDelta:\t\t%d\n", c.TimeLockDelta) +
+		fmt.Sprintf("--- End ChannelAnnouncement ---\n")
+}
@@ -0,0 +1,41 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcutil"
+)
+
+func TestChannelAnnouncementEncodeDecode(t *testing.T) {
+	ca := &ChannelAnnouncement{
+		ChannelPoint:  outpoint1,
+		NodeID1:       [32]byte{1},
+		NodeID2:       [32]byte{2},
+		AssetID:       "La3JCM2DMgLuH2ZT6ibLGf4HFVhHbW5rWMtvhd",
+		Capacity:      btcutil.Amount(50000),
+		FeeBase:       btcutil.Amount(10),
+		FeeRate:       1000,
+		CarrierFee:    btcutil.Amount(600),
+		TimeLockDelta: 144,
+	}
+
+	// Next encode the CA message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := ca.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode ChannelAnnouncement: %v", err)
+	}
+
+	// Deserialize the encoded CA message into a new empty struct.
+	ca2 := &ChannelAnnouncement{}
+	if err := ca2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode ChannelAnnouncement: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(ca, ca2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			ca, ca2)
+	}
+}
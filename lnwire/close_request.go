@@ -33,8 +33,17 @@ type CloseRequest struct {
 	// timely channel closure.
 	// TODO(roasbeef): if initiator always pays fees, then no longer needed.
 	Fee btcutil.Amount
+
+	// DeliveryScript, if non-empty, overrides the delivery script of the
+	// requester negotiated during the funding workflow, directing the
+	// requester's settled balance elsewhere.
+	DeliveryScript []byte
 }
 
+// maxDeliveryScriptLength is the length of the largest delivery script which
+// may override the negotiated one, that of a p2wsh output.
+const maxDeliveryScriptLength = 34
+
 // NewCloseRequest creates a new CloseRequest.
 func NewCloseRequest(cp *wire.OutPoint, sig *btcec.Signature) *CloseRequest {
 	// TODO(roasbeef): update once fees aren't hardcoded
@@ -57,10 +66,13 @@ func (c *CloseRequest) Decode(r io.Reader, pver uint32) error {
 	// RequesterCloseSig (73)
 	// 	First byte length then sig
 	// Fee (8)
+	// DeliveryScript (35)
+	// 	First byte length then script
 	err := readElements(r,
 		&c.ChannelPoint,
 		&c.RequesterCloseSig,
-		&c.Fee)
+		&c.Fee,
+		&c.DeliveryScript)
 	if err != nil {
 		return err
 	}
@@ -76,10 +88,12 @@ func (c *CloseRequest) Encode(w io.Writer, pver uint32) error {
 	// ChannelID
 	// RequesterCloseSig
 	// Fee
+	// DeliveryScript
 	err := writeElements(w,
 		c.ChannelPoint,
 		c.RequesterCloseSig,
-		c.Fee)
+		c.Fee,
+		c.DeliveryScript)
 	if err != nil {
 		return err
 	}
@@ -100,8 +114,8 @@ func (c *CloseRequest) Command() uint32 {
 //
 // This is part of the lnwire.Message interface.
 func (c *CloseRequest) MaxPayloadLength(pver uint32) uint32 {
-	// 36 + 73 + 8
-	return 117
+	// 36 + 73 + 8 + 35
+	return 152
 }
 
 // Validate performs any necessary sanity checks to ensure all fields present
@@ -114,6 +128,11 @@ func (c *CloseRequest) Validate() error {
 		return fmt.Errorf("Fee must be greater than zero.")
 	}
 
+	if len(c.DeliveryScript) > maxDeliveryScriptLength {
+		return fmt.Errorf("delivery script of %v bytes exceeds max of "+
+			"%v", len(c.DeliveryScript), maxDeliveryScriptLength)
+	}
+
 	// We're good!
 	return nil
 }
@@ -131,5 +150,6 @@ func (c *CloseRequest) String() string {
 		fmt.Sprintf("ChannelPoint:\t\t%v\n", c.ChannelPoint) +
 		fmt.Sprintf("CloseSig\t\t%x\n", serializedSig) +
 		fmt.Sprintf("Fee:\t\t\t%d\n", c.Fee) +
+		fmt.Sprintf("DeliveryScript:\t\t%x\n", c.DeliveryScript) +
 		fmt.Sprintf("--- End CloseRequest ---\n")
 }
@@ -13,6 +13,7 @@ func TestCloseRequestEncodeDecode(t *testing.T) {
 		ChannelPoint:      outpoint1,
 		RequesterCloseSig: commitSig,
 		Fee:               btcutil.Amount(10000),
+		DeliveryScript:    bytes.Repeat([]byte{0x01}, 22),
 	}
 
 	// Next encode the CR message into an empty bytes buffer.
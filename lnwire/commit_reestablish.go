@@ -0,0 +1,140 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// CommitReestablish is sent by both sides of a channel each time a
+// connection is established, before any other state update messages. It
+// conveys how far the sender's view of the channel's commitment chains has
+// progressed, allowing the receiver to retransmit any CommitSignature or
+// CommitRevocation messages which were lost along with the prior connection.
+type CommitReestablish struct {
+	// ChannelPoint uniquely identifies to which currently active channel
+	// this CommitReestablish applies to.
+	ChannelPoint *wire.OutPoint
+
+	// LocalHeight is the height of the sender's current commitment
+	// transaction. Any CommitSignature for a commitment at or below this
+	// height has been received by the sender.
+	LocalHeight uint64
+
+	// RemoteRevocations is the number of revocation pre-images the sender
+	// has received for the receiver's prior commitment transactions.
+	RemoteRevocations uint64
+
+	// RevocationKey is the revocation key of the sender's current
+	// commitment transaction. Should the receiver have missed the
+	// revocation of the sender's prior commitment, this key replaces the
+	// one it was revoked with once the revocation is retransmitted.
+	RevocationKey *btcec.PublicKey
+
+	// RevocationHash is the revocation hash of the sender's current
+	// commitment transaction.
+	RevocationHash [32]byte
+}
+
+// NewCommitReestablish creates a new CommitReestablish message.
+func NewCommitReestablish() *CommitReestablish {
+	return &CommitReestablish{}
+}
+
+// A compile time check to ensure CommitReestablish implements the
+// lnwire.Message interface.
+var _ Message = (*CommitReestablish)(nil)
+
+// Decode deserializes a serialized CommitReestablish message stored in the
+// passed io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *CommitReestablish) Decode(r io.Reader, pver uint32) error {
+	// ChannelPoint (36)
+	// LocalHeight (8)
+	// RemoteRevocations (8)
+	// RevocationKey (33)
+	// RevocationHash (32)
+	err := readElements(r,
+		&c.ChannelPoint,
+		&c.LocalHeight,
+		&c.RemoteRevocations,
+		&c.RevocationKey,
+		&c.RevocationHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Encode serializes the target CommitReestablish into the passed io.Writer
+// observing the protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (c *CommitReestablish) Encode(w io.Writer, pver uint32) error {
+	err := writeElements(w,
+		c.ChannelPoint,
+		c.LocalHeight,
+		c.RemoteRevocations,
+		c.RevocationKey,
+		c.RevocationHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying this message type on the
+// wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *CommitReestablish) Command() uint32 {
+	return CmdCommitReestablish
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a
+// CommitReestablish complete message observing the specified protocol
+// version.
+//
+// This is part of the lnwire.Message interface.
+func (c *CommitReestablish) MaxPayloadLength(uint32) uint32 {
+	// 36 + 8 + 8 + 33 + 32
+	return 117
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the CommitReestablish are valid.
+//
+// This is part of the lnwire.Message interface.
+func (c *CommitReestablish) Validate() error {
+	if c.ChannelPoint == nil {
+		return fmt.Errorf("ChannelPoint must be set")
+	}
+	if c.RevocationKey == nil {
+		return fmt.Errorf("RevocationKey must be set")
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target CommitReestablish.
+//
+// This is part of the lnwire.Message interface.
+func (c *CommitReestablish) String() string {
+	keySer := c.RevocationKey.SerializeCompressed()
+
+	return fmt.Sprintf("\n--- Begin CommitReestablish ---\n") +
+		fmt.Sprintf("ChannelPoint:\t%v\n", c.ChannelPoint) +
+		fmt.Sprintf("LocalHeight:\t%d\n", c.LocalHeight) +
+		fmt.Sprintf("RemoteRevocations:\t%d\n", c.RemoteRevocations) +
+		fmt.Sprintf("RevocationKey:\t%x\n", keySer) +
+		fmt.Sprintf("RevocationHash:\t%x\n", c.RevocationHash) +
+		fmt.Sprintf("--- End CommitReestablish ---\n")
+}
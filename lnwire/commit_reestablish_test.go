@@ -0,0 +1,35 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCommitReestablishEncodeDecode(t *testing.T) {
+	cr := &CommitReestablish{
+		ChannelPoint:      outpoint1,
+		LocalHeight:       12,
+		RemoteRevocations: 11,
+		RevocationKey:     pubKey,
+		RevocationHash:    revHash,
+	}
+
+	// Next encode the CR message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := cr.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode CommitReestablish: %v", err)
+	}
+
+	// Deserialize the encoded CR message into a new empty struct.
+	cr2 := &CommitReestablish{}
+	if err := cr2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode CommitReestablish: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(cr, cr2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			cr, cr2)
+	}
+}
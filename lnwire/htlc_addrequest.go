@@ -19,6 +19,11 @@ type HTLCAddRequest struct {
 	// is binded to.
 	ChannelPoint *wire.OutPoint
 
+	// ID is the index of this HTLC within the sender's update log. As IDs
+	// are assigned sequentially, the receiver is able to detect, and
+	// safely ignore HTLCs which are retransmitted after a reconnection.
+	ID uint64
+
 	// Expiry is the number of blocks after which this HTLC should expire.
 	// It is the receiver's duty to ensure that the outgoing HTLC has a
 	// sufficient expiry value to allow her to redeem the incmoing HTLC.
@@ -75,6 +80,7 @@ var _ Message = (*HTLCAddRequest)(nil)
 // This is part of the lnwire.Message interface.
 func (c *HTLCAddRequest) Decode(r io.Reader, pver uint32) error {
 	// ChannelPoint(8)
+	// ID(8)
 	// Expiry(4)
 	// Amount(4)
 	// ContractType(1)
@@ -82,6 +88,7 @@ func (c *HTLCAddRequest) Decode(r io.Reader, pver uint32) error {
 	// OnionBlog
 	err := readElements(r,
 		&c.ChannelPoint,
+		&c.ID,
 		&c.Expiry,
 		&c.Amount,
 		&c.ContractType,
@@ -102,6 +109,7 @@ func (c *HTLCAddRequest) Decode(r io.Reader, pver uint32) error {
 func (c *HTLCAddRequest) Encode(w io.Writer, pver uint32) error {
 	err := writeElements(w,
 		c.ChannelPoint,
+		c.ID,
 		c.Expiry,
 		c.Amount,
 		c.ContractType,
@@ -159,6 +167,7 @@ func (c *HTLCAddRequest) String() string {
 
 	return fmt.Sprintf("\n--- Begin HTLCAddRequest ---\n") +
 		fmt.Sprintf("ChannelPoint:\t%v\n", c.ChannelPoint) +
+		fmt.Sprintf("ID:\t\t%d\n", c.ID) +
 		fmt.Sprintf("Expiry:\t\t%d\n", c.Expiry) +
 		fmt.Sprintf("Amount\t\t%d\n", c.Amount) +
 		fmt.Sprintf("ContractType:\t%d (%b)\n", c.ContractType, c.ContractType) +
@@ -13,6 +13,7 @@ func TestHTLCAddRequestEncodeDecode(t *testing.T) {
 	// First create a new HTLCAR message.
 	addReq := &HTLCAddRequest{
 		ChannelPoint:     outpoint1,
+		ID:               uint64(1 << 40),
 		Expiry:           uint32(144),
 		Amount:           CreditsAmount(123456000),
 		ContractType:     uint8(17),
@@ -7,6 +7,34 @@ import (
 	"github.com/roasbeef/btcd/wire"
 )
 
+// FailReason describes why an HTLC was failed back by a node along its route.
+type FailReason uint8
+
+const (
+	// FailUnspecified is the reason of an HTLC failed for an unknown
+	// reason, such as its timing out, or by a peer which doesn't report
+	// its reasons.
+	FailUnspecified FailReason = 0
+
+	// FailUnknownPaymentHash is the reason of an HTLC failed by its
+	// destination, as it doesn't know the preimage of the HTLC's payment
+	// hash. A failure for this reason implies the HTLC made it through
+	// each hop of its route.
+	FailUnknownPaymentHash FailReason = 1
+)
+
+// String returns a human readable version of the FailReason.
+func (r FailReason) String() string {
+	switch r {
+	case FailUnspecified:
+		return "unspecified"
+	case FailUnknownPaymentHash:
+		return "unknown payment hash"
+	default:
+		return fmt.Sprintf("unknown reason %d", uint8(r))
+	}
+}
+
 // HTLCTimeoutRequest is sent by Alice to Bob in order to timeout a previously
 // added HTLC. Upon receipt of an HTLCTimeoutRequest the HTLC should be removed
 // from the next commitment transaction, with the HTLCTimeoutRequest propgated
@@ -19,6 +47,11 @@ type HTLCTimeoutRequest struct {
 	// HTLCKey references which HTLC on the remote node's commitment
 	// transaction has timed out.
 	HTLCKey HTLCKey
+
+	// Reason is the reason the HTLC was failed, as reported by the node
+	// which failed it. This is propagated backwards along the route
+	// alongside the failure.
+	Reason FailReason
 }
 
 // Decode deserializes a serialized HTLCTimeoutRequest message stored in the passed
@@ -36,6 +69,18 @@ func (c *HTLCTimeoutRequest) Decode(r io.Reader, pver uint32) error {
 		return err
 	}
 
+	// Reason(1)
+	// The reason trails the message, as it's omitted by older peers, in
+	// which case it remains unspecified.
+	var reason [1]byte
+	switch _, err := io.ReadFull(r, reason[:]); err {
+	case nil:
+		c.Reason = FailReason(reason[0])
+	case io.EOF:
+	default:
+		return err
+	}
+
 	return nil
 }
 
@@ -60,6 +105,9 @@ func (c *HTLCTimeoutRequest) Encode(w io.Writer, pver uint32) error {
 	if err != nil {
 		return err
 	}
+	if _, err := w.Write([]byte{byte(c.Reason)}); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -77,8 +125,8 @@ func (c *HTLCTimeoutRequest) Command() uint32 {
 //
 // This is part of the lnwire.Message interface.
 func (c *HTLCTimeoutRequest) MaxPayloadLength(uint32) uint32 {
-	// 36 + 8
-	return 44
+	// 36 + 8 + 1
+	return 45
 }
 
 // Validate performs any necessary sanity checks to ensure all fields present
@@ -96,5 +144,6 @@ func (c *HTLCTimeoutRequest) String() string {
 	return fmt.Sprintf("\n--- Begin HTLCTimeoutRequest ---\n") +
 		fmt.Sprintf("ChannelPoint:\t%d\n", c.ChannelPoint) +
 		fmt.Sprintf("HTLCKey:\t%d\n", c.HTLCKey) +
+		fmt.Sprintf("Reason:\t\t%v\n", c.Reason) +
 		fmt.Sprintf("--- End HTLCTimeoutRequest ---\n")
 }
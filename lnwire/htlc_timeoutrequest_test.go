@@ -11,6 +11,7 @@ func TestHTLCTimeoutRequestEncodeDecode(t *testing.T) {
 	timeoutReq := &HTLCTimeoutRequest{
 		ChannelPoint: outpoint1,
 		HTLCKey:      22,
+		Reason:       FailUnknownPaymentHash,
 	}
 
 	// Next encode the HTLCTR message into an empty bytes buffer.
@@ -31,3 +32,32 @@ func TestHTLCTimeoutRequestEncodeDecode(t *testing.T) {
 			timeoutReq, timeoutReq2)
 	}
 }
+
+// TestHTLCTimeoutRequestNoReason tests that a HTLCTimeoutRequest sent by an
+// older peer, without a reason, is decoded with an unspecified reason.
+func TestHTLCTimeoutRequestNoReason(t *testing.T) {
+	timeoutReq := &HTLCTimeoutRequest{
+		ChannelPoint: outpoint1,
+		HTLCKey:      22,
+		Reason:       FailUnknownPaymentHash,
+	}
+
+	var b bytes.Buffer
+	if err := timeoutReq.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode HTLCTimeoutRequest: %v", err)
+	}
+	b.Truncate(b.Len() - 1)
+
+	timeoutReq2 := &HTLCTimeoutRequest{}
+	if err := timeoutReq2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode HTLCTimeoutRequest: %v", err)
+	}
+	if timeoutReq2.Reason != FailUnspecified {
+		t.Fatalf("expected unspecified reason, got %v",
+			timeoutReq2.Reason)
+	}
+	if timeoutReq2.HTLCKey != timeoutReq.HTLCKey {
+		t.Fatalf("expected htlc key %v, got %v", timeoutReq.HTLCKey,
+			timeoutReq2.HTLCKey)
+	}
+}
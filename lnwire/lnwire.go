@@ -16,6 +16,10 @@ import (
 // the wire protocol.
 const MaxSliceLength = 65535
 
+// MaxAssetIDLength is the maximum allowed length of the colored coins asset
+// identifiers exchanged within the wire protocol.
+const MaxAssetIDLength = 64
+
 // PkScript is simple type definition which represents a raw serialized public
 // key script.
 type PkScript []byte
@@ -41,8 +41,9 @@ const (
 	CmdHTLCTimeoutRequest = uint32(1300)
 
 	// Commands for modifying commitment transactions.
-	CmdCommitSignature  = uint32(2000)
-	CmdCommitRevocation = uint32(2010)
+	CmdCommitSignature   = uint32(2000)
+	CmdCommitRevocation  = uint32(2010)
+	CmdCommitReestablish = uint32(2020)
 
 	// Commands for routing
 	CmdNeighborHelloMessage        = uint32(3000)
@@ -51,9 +52,14 @@ const (
 	CmdNeighborRstMessage          = uint32(3030)
 	CmdRoutingTableRequestMessage  = uint32(3040)
 	CmdRoutingTableTransferMessage = uint32(3050)
+	CmdChannelAnnouncement         = uint32(3100)
 
 	// Commands for reporting protocol errors.
 	CmdErrorGeneric = uint32(4000)
+
+	// Commands for exchanging backups of channel state with peers.
+	CmdPeerBackup        = uint32(5000)
+	CmdPeerBackupRequest = uint32(5010)
 )
 
 // Message is an interface that defines a lightning wire protocol message. The
@@ -100,6 +106,8 @@ func makeEmptyMessage(command uint32) (Message, error) {
 		msg = &CommitSignature{}
 	case CmdCommitRevocation:
 		msg = &CommitRevocation{}
+	case CmdCommitReestablish:
+		msg = &CommitReestablish{}
 	case CmdErrorGeneric:
 		msg = &ErrorGeneric{}
 	case CmdNeighborHelloMessage:
@@ -114,6 +122,12 @@ func makeEmptyMessage(command uint32) (Message, error) {
 		msg = &RoutingTableRequestMessage{}
 	case CmdRoutingTableTransferMessage:
 		msg = &RoutingTableTransferMessage{}
+	case CmdChannelAnnouncement:
+		msg = &ChannelAnnouncement{}
+	case CmdPeerBackup:
+		msg = &PeerBackup{}
+	case CmdPeerBackupRequest:
+		msg = &PeerBackupRequest{}
 	default:
 		return nil, fmt.Errorf("unhandled command [%d]", command)
 	}
@@ -0,0 +1,175 @@
+package lnwire
+
+import (
+	"fmt"
+	"io"
+)
+
+// MaxPeerBackupSize is the largest backup a peer may send for safekeeping.
+const MaxPeerBackupSize = 32768
+
+// PeerBackup carries an opaque, encrypted backup of the sender's state of
+// the channels it has with the receiver. It's sent by the owner of the backup
+// for the receiver to store, replacing any backup previously stored. Once
+// the owner has lost its own state, it may request the backup back with a
+// PeerBackupRequest, to which the receiver replies with a PeerBackup with
+// Returned set.
+type PeerBackup struct {
+	// Backup is the encrypted backup, which is opaque to all but its
+	// owner.
+	Backup []byte
+
+	// Returned is set if the backup is being returned to its owner in
+	// reply to a PeerBackupRequest, rather than sent for safekeeping.
+	Returned bool
+}
+
+// NewPeerBackup creates a new PeerBackup message.
+func NewPeerBackup(backup []byte) *PeerBackup {
+	return &PeerBackup{
+		Backup: backup,
+	}
+}
+
+// A compile time check to ensure PeerBackup implements the lnwire.Message
+// interface.
+var _ Message = (*PeerBackup)(nil)
+
+// Decode deserializes a serialized PeerBackup message stored in the passed
+// io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackup) Decode(r io.Reader, pver uint32) error {
+	// Backup (2+32768)
+	// Returned (1)
+	var returned uint8
+	err := readElements(r,
+		&c.Backup,
+		&returned,
+	)
+	if err != nil {
+		return err
+	}
+	c.Returned = returned != 0
+
+	return nil
+}
+
+// Encode serializes the target PeerBackup into the passed io.Writer observing
+// the protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackup) Encode(w io.Writer, pver uint32) error {
+	var returned uint8
+	if c.Returned {
+		returned = 1
+	}
+
+	err := writeElements(w,
+		c.Backup,
+		returned,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Command returns the integer uniquely identifying a PeerBackup message on
+// the wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackup) Command() uint32 {
+	return CmdPeerBackup
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a PeerBackup
+// message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackup) MaxPayloadLength(uint32) uint32 {
+	// 3+32768+1
+	return 32772
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the PeerBackup are valid.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackup) Validate() error {
+	if len(c.Backup) > MaxPeerBackupSize {
+		return fmt.Errorf("backup of %v bytes exceeds max of %v",
+			len(c.Backup), MaxPeerBackupSize)
+	}
+
+	// We're good!
+	return nil
+}
+
+// String returns the string representation of the target PeerBackup.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackup) String() string {
+	return fmt.Sprintf("\n--- Begin PeerBackup ---\n") +
+		fmt.Sprintf("Backup:\t\t%v bytes\n", len(c.Backup)) +
+		fmt.Sprintf("Returned:\t%v\n", c.Returned) +
+		fmt.Sprintf("--- End PeerBackup ---\n")
+}
+
+// PeerBackupRequest is sent by a node which has lost its state of the
+// channels it has with the receiver, requesting the receiver return the
+// latest PeerBackup it has stored on the sender's behalf.
+type PeerBackupRequest struct{}
+
+// A compile time check to ensure PeerBackupRequest implements the
+// lnwire.Message interface.
+var _ Message = (*PeerBackupRequest)(nil)
+
+// Decode deserializes a serialized PeerBackupRequest message stored in the
+// passed io.Reader observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackupRequest) Decode(r io.Reader, pver uint32) error {
+	return nil
+}
+
+// Encode serializes the target PeerBackupRequest into the passed io.Writer
+// observing the protocol version specified.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackupRequest) Encode(w io.Writer, pver uint32) error {
+	return nil
+}
+
+// Command returns the integer uniquely identifying a PeerBackupRequest
+// message on the wire.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackupRequest) Command() uint32 {
+	return CmdPeerBackupRequest
+}
+
+// MaxPayloadLength returns the maximum allowed payload size for a
+// PeerBackupRequest message observing the specified protocol version.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackupRequest) MaxPayloadLength(uint32) uint32 {
+	return 0
+}
+
+// Validate performs any necessary sanity checks to ensure all fields present
+// on the PeerBackupRequest are valid.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackupRequest) Validate() error {
+	return nil
+}
+
+// String returns the string representation of the target PeerBackupRequest.
+//
+// This is part of the lnwire.Message interface.
+func (c *PeerBackupRequest) String() string {
+	return fmt.Sprintf("\n--- Begin PeerBackupRequest ---\n") +
+		fmt.Sprintf("--- End PeerBackupRequest ---\n")
+}
@@ -0,0 +1,32 @@
+package lnwire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestPeerBackupEncodeDecode(t *testing.T) {
+	pb := &PeerBackup{
+		Backup:   bytes.Repeat([]byte{0xaa}, 500),
+		Returned: true,
+	}
+
+	// Next encode the PeerBackup message into an empty bytes buffer.
+	var b bytes.Buffer
+	if err := pb.Encode(&b, 0); err != nil {
+		t.Fatalf("unable to encode PeerBackup: %v", err)
+	}
+
+	// Deserialize the encoded PeerBackup message into a new empty struct.
+	pb2 := &PeerBackup{}
+	if err := pb2.Decode(&b, 0); err != nil {
+		t.Fatalf("unable to decode PeerBackup: %v", err)
+	}
+
+	// Assert equality of the two instances.
+	if !reflect.DeepEqual(pb, pb2) {
+		t.Fatalf("encode/decode error messages don't match %#v vs %#v",
+			pb, pb2)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
 )
 
 // SingleFundingComplete is the message Alice sends to Bob once she is able to
@@ -25,6 +26,12 @@ type SingleFundingComplete struct {
 	// signature for Alice's version of the commitment transaction.
 	FundingOutPoint *wire.OutPoint
 
+	// FundingValue is the value in satoshis of the funding output. As the
+	// output only carries the channel's asset, this bears no relation to
+	// the channel's capacity. Bob requires it in order to generate, and
+	// verify signatures for either version of the commitment transaction.
+	FundingValue btcutil.Amount
+
 	// CommitSignature is Alice's signature for Bob's version of the
 	// commitment transaction.
 	CommitSignature *btcec.Signature
@@ -58,11 +65,13 @@ func NewSingleFundingComplete(chanID uint64, fundingPoint *wire.OutPoint,
 func (s *SingleFundingComplete) Decode(r io.Reader, pver uint32) error {
 	// ChannelID (8)
 	// FundingOutPoint (36)
+	// FundingValue (8)
 	// CommitmentSignature (73)
 	// RevocationKey (33)
 	err := readElements(r,
 		&s.ChannelID,
 		&s.FundingOutPoint,
+		&s.FundingValue,
 		&s.CommitSignature,
 		&s.RevocationKey)
 	if err != nil {
@@ -80,11 +89,13 @@ func (s *SingleFundingComplete) Decode(r io.Reader, pver uint32) error {
 func (s *SingleFundingComplete) Encode(w io.Writer, pver uint32) error {
 	// ChannelID (8)
 	// FundingOutPoint (36)
+	// FundingValue (8)
 	// Commitment Signature (73)
 	// RevocationKey (33)
 	err := writeElements(w,
 		s.ChannelID,
 		s.FundingOutPoint,
+		s.FundingValue,
 		s.CommitSignature,
 		s.RevocationKey)
 	if err != nil {
@@ -105,11 +116,11 @@ func (s *SingleFundingComplete) Command() uint32 {
 // MaxPayloadLength returns the maximum allowed payload length for a
 // SingleFundingComplete. This is calculated by summing the max length of all
 // the fields within a SingleFundingResponse. Therefore, the final breakdown
-// is: 8 + 36 + 73 = 150
+// is: 8 + 36 + 8 + 73 + 33 = 158
 //
 // This is part of the lnwire.Message interface.
 func (s *SingleFundingComplete) MaxPayloadLength(uint32) uint32 {
-	return 150
+	return 158
 }
 
 // Validate examines each populated field within the SingleFundingComplete for
@@ -122,6 +133,10 @@ func (s *SingleFundingComplete) Validate() error {
 		return fmt.Errorf("funding outpoint hash must be non-zero")
 	}
 
+	if s.FundingValue <= 0 {
+		return fmt.Errorf("funding value must be positive")
+	}
+
 	if s.CommitSignature == nil {
 		return fmt.Errorf("commitment signature must be non-nil")
 	}
@@ -144,6 +159,7 @@ func (s *SingleFundingComplete) String() string {
 	return fmt.Sprintf("\n--- Begin SingleFundingComplete ---\n") +
 		fmt.Sprintf("ChannelID:\t\t\t%d\n", s.ChannelID) +
 		fmt.Sprintf("FundingOutPoint:\t\t\t%x\n", s.FundingOutPoint) +
+		fmt.Sprintf("FundingValue:\t\t\t%v\n", s.FundingValue) +
 		fmt.Sprintf("CommitSignature\t\t\t\t%x\n", s.CommitSignature) +
 		fmt.Sprintf("RevocationKey\t\t\t\t%x\n", rk) +
 		fmt.Sprintf("--- End SingleFundingComplete ---\n")
@@ -9,6 +9,7 @@ import (
 func TestSingleFundingCompleteWire(t *testing.T) {
 	// First create a new SFC message.
 	sfc := NewSingleFundingComplete(22, outpoint1, commitSig1, pubKey)
+	sfc.FundingValue = 8190
 
 	// Next encode the SFC message into an empty bytes buffer.
 	var b bytes.Buffer
@@ -19,12 +19,17 @@ type SingleFundingOpenProof struct {
 	ChannelID uint64
 
 	// SpvProof is an merkle proof of the inclusion of the funding
-	// transaction within a block.
-	// TODO(roasbeef): spec out format for SPV proof, only of single tx so
-	// can be compact
+	// transaction within a block. It consists of the height of the block,
+	// its header, the index of the funding transaction within the block,
+	// and the merkle branch connecting the transaction to the header.
 	SpvProof []byte
 }
 
+// maxSpvProofLength is the length of an SpvProof with a merkle branch of the
+// maximum depth of 32 hashes: the block height (4), the block header (80),
+// the transaction index (4), and the merkle branch (1 + 32*32).
+const maxSpvProofLength = 4 + 80 + 4 + 1 + 32*32
+
 // NewSingleFundingSignComplete creates a new empty SingleFundingOpenProof
 // message.
 func NewSingleFundingOpenProof(chanID uint64, spvProof []byte) *SingleFundingOpenProof {
@@ -82,8 +87,8 @@ func (s *SingleFundingOpenProof) Command() uint32 {
 //
 // This is part of the lnwire.Message interface.
 func (s *SingleFundingOpenProof) MaxPayloadLength(uint32) uint32 {
-	// 8 +
-	return 81
+	// 8 + 3 + 1113
+	return 8 + 3 + maxSpvProofLength
 }
 
 // Validate examines each populated field within the SingleFundingOpenProof for
@@ -91,6 +96,11 @@ func (s *SingleFundingOpenProof) MaxPayloadLength(uint32) uint32 {
 //
 // This is part of the lnwire.Message interface.
 func (s *SingleFundingOpenProof) Validate() error {
+	if len(s.SpvProof) > maxSpvProofLength {
+		return fmt.Errorf("spv proof of %v bytes exceeds max of %v",
+			len(s.SpvProof), maxSpvProofLength)
+	}
+
 	// We're good!
 	return nil
 }
@@ -101,6 +111,6 @@ func (s *SingleFundingOpenProof) Validate() error {
 func (s *SingleFundingOpenProof) String() string {
 	return fmt.Sprintf("\n--- Begin FundingSignComplete ---\n") +
 		fmt.Sprintf("ChannelID:\t\t%d\n", s.ChannelID) +
-		fmt.Sprintf("SpvProof\t\t%s\n", s.SpvProof) +
+		fmt.Sprintf("SpvProof\t\t%x\n", s.SpvProof) +
 		fmt.Sprintf("--- End FundingSignComplete ---\n")
 }
@@ -8,6 +8,16 @@ import (
 	"github.com/roasbeef/btcutil"
 )
 
+// FeatureSecondLevelHTLCs is the bit within the FeatureFlags of the single
+// funding messages signalling support for spending the HTLC outputs of the
+// commitment transactions via second-level HTLC transactions.
+const FeatureSecondLevelHTLCs uint8 = 1 << 0
+
+// FeatureShaChain is the bit within the FeatureFlags of the single funding
+// messages signalling support for deriving the revocation secrets of a
+// channel from a BOLT shachain rather than an elkrem.
+const FeatureShaChain uint8 = 1 << 1
+
 // SingleFundingRequest is the message Alice sends to Bob if we should like
 // to create a channel with Bob where she's the sole provider of funds to the
 // channel. Single funder channels simplify the initial funding workflow, are
@@ -68,6 +78,34 @@ type SingleFundingRequest struct {
 	// supported: P2PKH, P2WKH, P2SH, and P2WSH.
 	DeliveryPkScript PkScript
 
+	// AssetID is the identifier of the colored asset the channel is to be
+	// denominated in.
+	AssetID string
+
+	// AssetDustLimit is the smallest asset amount for which the initiator
+	// will create an output within their commitment transaction.
+	AssetDustLimit btcutil.Amount
+
+	// MaxHTLCAssetValue is the largest asset amount the initiator will
+	// accept within a single HTLC.
+	MaxHTLCAssetValue btcutil.Amount
+
+	// CarrierSatBudget is the number of satoshis the initiator is willing to
+	// spend on dust carrier outputs and fees within the channel.
+	CarrierSatBudget btcutil.Amount
+
+	// InstructionVersion is the latest version of the colored coins
+	// instruction encoding supported by the initiator.
+	InstructionVersion uint8
+
+	// DustPolicy is the policy the initiator proposes for valuing the
+	// colored outputs of the channel's transactions.
+	DustPolicy uint8
+
+	// FeatureFlags is a bit field of the optional channel features
+	// supported by the initiator, such as FeatureSecondLevelHTLCs.
+	FeatureFlags uint8
+
 	// TODO(roasbeef): confirmation depth
 }
 
@@ -104,6 +142,13 @@ func (c *SingleFundingRequest) Decode(r io.Reader, pver uint32) error {
 	// Pubkey (33)
 	// Pubkey (33)
 	// DeliveryPkScript (final delivery)
+	// AssetID (max 1 + 64)
+	// AssetDustLimit (8)
+	// MaxHTLCAssetValue (8)
+	// CarrierSatBudget (8)
+	// InstructionVersion (1)
+	// DustPolicy (1)
+	// FeatureFlags (1)
 	err := readElements(r,
 		&c.ChannelID,
 		&c.ChannelType,
@@ -113,7 +158,14 @@ func (c *SingleFundingRequest) Decode(r io.Reader, pver uint32) error {
 		&c.CsvDelay,
 		&c.CommitmentKey,
 		&c.ChannelDerivationPoint,
-		&c.DeliveryPkScript)
+		&c.DeliveryPkScript,
+		&c.AssetID,
+		&c.AssetDustLimit,
+		&c.MaxHTLCAssetValue,
+		&c.CarrierSatBudget,
+		&c.InstructionVersion,
+		&c.DustPolicy,
+		&c.FeatureFlags)
 	if err != nil {
 		return err
 	}
@@ -136,6 +188,13 @@ func (c *SingleFundingRequest) Encode(w io.Writer, pver uint32) error {
 	// Pubkey (33)
 	// Pubkey (33)
 	// DeliveryPkScript (final delivery)
+	// AssetID (max 1 + 64)
+	// AssetDustLimit (8)
+	// MaxHTLCAssetValue (8)
+	// CarrierSatBudget (8)
+	// InstructionVersion (1)
+	// DustPolicy (1)
+	// FeatureFlags (1)
 	err := writeElements(w,
 		c.ChannelID,
 		c.ChannelType,
@@ -145,7 +204,14 @@ func (c *SingleFundingRequest) Encode(w io.Writer, pver uint32) error {
 		c.CsvDelay,
 		c.CommitmentKey,
 		c.ChannelDerivationPoint,
-		c.DeliveryPkScript)
+		c.DeliveryPkScript,
+		c.AssetID,
+		c.AssetDustLimit,
+		c.MaxHTLCAssetValue,
+		c.CarrierSatBudget,
+		c.InstructionVersion,
+		c.DustPolicy,
+		c.FeatureFlags)
 	if err != nil {
 		return err
 	}
@@ -165,11 +231,12 @@ func (c *SingleFundingRequest) Command() uint32 {
 // SingleFundingRequest. This is calculated by summing the max length of all
 // the fields within a SingleFundingRequest. To enforce a maximum
 // DeliveryPkScript size, the size of a P2PKH public key script is used.
-// Therefore, the final breakdown is: 8 + 1 + 8 + 8 + 8 + 4 + 33 + 33 + 25 = 158.
+// Therefore, the final breakdown is: 8 + 1 + 8 + 8 + 8 + 4 + 33 + 33 + 25 +
+// (1 + 64) + 8 + 8 + 8 + 1 + 1 + 1 = 250.
 //
 // This is part of the lnwire.Message interface.
 func (c *SingleFundingRequest) MaxPayloadLength(uint32) uint32 {
-	return 158
+	return 250
 }
 
 // Validate examines each populated field within the SingleFundingRequest for
@@ -186,6 +253,17 @@ func (c *SingleFundingRequest) Validate() error {
 		return fmt.Errorf("FundingAmount cannot be negative")
 	}
 
+	// The asset ID MUST NOT exceed the maximum length, and all asset
+	// amounts MUST NOT be negative.
+	if len(c.AssetID) > MaxAssetIDLength {
+		return fmt.Errorf("AssetID cannot exceed %v bytes",
+			MaxAssetIDLength)
+	}
+	if c.AssetDustLimit < 0 || c.MaxHTLCAssetValue < 0 ||
+		c.CarrierSatBudget < 0 {
+		return fmt.Errorf("Asset parameters cannot be negative")
+	}
+
 	// The CSV delay MUST be non-zero.
 	if c.CsvDelay == 0 {
 		return fmt.Errorf("Commitment transaction must have non-zero " +
@@ -234,5 +312,12 @@ func (c *SingleFundingRequest) String() string {
 		fmt.Sprintf("CsvDelay\t\t\t%d\n", c.CsvDelay) +
 		fmt.Sprintf("ChannelDerivationPoint\t\t\t\t%x\n", serializedPubkey) +
 		fmt.Sprintf("DeliveryPkScript\t\t%x\n", c.DeliveryPkScript) +
+		fmt.Sprintf("AssetID\t\t\t%s\n", c.AssetID) +
+		fmt.Sprintf("AssetDustLimit\t\t%d\n", c.AssetDustLimit) +
+		fmt.Sprintf("MaxHTLCAssetValue\t\t%d\n", c.MaxHTLCAssetValue) +
+		fmt.Sprintf("CarrierSatBudget\t\t%d\n", c.CarrierSatBudget) +
+		fmt.Sprintf("InstructionVersion\t\t%d\n", c.InstructionVersion) +
+		fmt.Sprintf("DustPolicy\t\t%d\n", c.DustPolicy) +
+		fmt.Sprintf("FeatureFlags\t\t%08b\n", c.FeatureFlags) +
 		fmt.Sprintf("--- End SingleFundingRequest ---\n")
 }
@@ -11,6 +11,12 @@ func TestSingleFundingRequestWire(t *testing.T) {
 	cdp := pubKey
 	delivery := PkScript(bytes.Repeat([]byte{0x02}, 25))
 	sfr := NewSingleFundingRequest(20, 21, 22, 23, 5, 5, cdp, cdp, delivery)
+	sfr.AssetID = "La4szjzKfJyHQ75qgDEnbzp4qY8GQeDR5Z7h2W"
+	sfr.AssetDustLimit = 1
+	sfr.MaxHTLCAssetValue = 5000
+	sfr.CarrierSatBudget = 8190
+	sfr.InstructionVersion = 1
+	sfr.FeatureFlags = FeatureSecondLevelHTLCs
 
 	// Next encode the SFR message into an empty bytes buffer.
 	var b bytes.Buffer
@@ -5,6 +5,7 @@ import (
 	"io"
 
 	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcutil"
 )
 
 // SingleFundingResponse is the message Bob sends to Alice after she initiates
@@ -45,6 +46,37 @@ type SingleFundingResponse struct {
 	// cooperative close. Only the following script templates are
 	// supported: P2PKH, P2WKH, P2SH, and P2WSH.
 	DeliveryPkScript PkScript
+
+	// AssetID is the identifier of the colored asset the channel is to be
+	// denominated in.
+	AssetID string
+
+	// AssetDustLimit is the smallest asset amount for which the responder
+	// will create an output within their commitment transaction.
+	AssetDustLimit btcutil.Amount
+
+	// MaxHTLCAssetValue is the largest asset amount the responder will
+	// accept within a single HTLC.
+	MaxHTLCAssetValue btcutil.Amount
+
+	// CarrierSatBudget is the number of satoshis the responder is willing to
+	// spend on dust carrier outputs and fees within the channel.
+	CarrierSatBudget btcutil.Amount
+
+	// InstructionVersion is the version of the colored coins instruction
+	// encoding selected by the responder, which MUST NOT exceed that
+	// proposed by the initiator.
+	InstructionVersion uint8
+
+	// DustPolicy is the policy for valuing the colored outputs of the
+	// channel's transactions, which MUST match that proposed by the
+	// initiator.
+	DustPolicy uint8
+
+	// FeatureFlags is the bit field of the optional channel features
+	// selected by the responder, which MUST be a subset of those proposed
+	// by the initiator.
+	FeatureFlags uint8
 }
 
 // NewSingleFundingResponse creates, and returns a new empty
@@ -78,13 +110,27 @@ func (c *SingleFundingResponse) Decode(r io.Reader, pver uint32) error {
 	// RevocationKey (33)
 	// CsvDelay (4)
 	// DeliveryPkScript (final delivery)
+	// AssetID (max 1 + 64)
+	// AssetDustLimit (8)
+	// MaxHTLCAssetValue (8)
+	// CarrierSatBudget (8)
+	// InstructionVersion (1)
+	// DustPolicy (1)
+	// FeatureFlags (1)
 	err := readElements(r,
 		&c.ChannelID,
 		&c.ChannelDerivationPoint,
 		&c.CommitmentKey,
 		&c.RevocationKey,
 		&c.CsvDelay,
-		&c.DeliveryPkScript)
+		&c.DeliveryPkScript,
+		&c.AssetID,
+		&c.AssetDustLimit,
+		&c.MaxHTLCAssetValue,
+		&c.CarrierSatBudget,
+		&c.InstructionVersion,
+		&c.DustPolicy,
+		&c.FeatureFlags)
 	if err != nil {
 		return err
 	}
@@ -104,13 +150,27 @@ func (c *SingleFundingResponse) Encode(w io.Writer, pver uint32) error {
 	// RevocationKey (33)
 	// CsvDelay (4)
 	// DeliveryPkScript (final delivery)
+	// AssetID (max 1 + 64)
+	// AssetDustLimit (8)
+	// MaxHTLCAssetValue (8)
+	// CarrierSatBudget (8)
+	// InstructionVersion (1)
+	// DustPolicy (1)
+	// FeatureFlags (1)
 	err := writeElements(w,
 		c.ChannelID,
 		c.ChannelDerivationPoint,
 		c.CommitmentKey,
 		c.RevocationKey,
 		c.CsvDelay,
-		c.DeliveryPkScript)
+		c.DeliveryPkScript,
+		c.AssetID,
+		c.AssetDustLimit,
+		c.MaxHTLCAssetValue,
+		c.CarrierSatBudget,
+		c.InstructionVersion,
+		c.DustPolicy,
+		c.FeatureFlags)
 	if err != nil {
 		return err
 	}
@@ -130,11 +190,12 @@ func (c *SingleFundingResponse) Command() uint32 {
 // SingleFundingResponse. This is calculated by summing the max length of all
 // the fields within a SingleFundingResponse. To enforce a maximum
 // DeliveryPkScript size, the size of a P2PKH public key script is used.
-// Therefore, the final breakdown is: 8 + (33 * 3) + 8 + 25
+// Therefore, the final breakdown is: 8 + (33 * 3) + 8 + 25 + (1 + 64) + 8 +
+// 8 + 8 + 1 + 1 + 1
 //
 // This is part of the lnwire.Message interface.
 func (c *SingleFundingResponse) MaxPayloadLength(uint32) uint32 {
-	return 140
+	return 232
 }
 
 // Validate examines each populated field within the SingleFundingResponse for
@@ -153,6 +214,17 @@ func (c *SingleFundingResponse) Validate() error {
 	//		"y-coordinate")
 	//}
 
+	// The asset ID MUST NOT exceed the maximum length, and all asset
+	// amounts MUST NOT be negative.
+	if len(c.AssetID) > MaxAssetIDLength {
+		return fmt.Errorf("AssetID cannot exceed %v bytes",
+			MaxAssetIDLength)
+	}
+	if c.AssetDustLimit < 0 || c.MaxHTLCAssetValue < 0 ||
+		c.CarrierSatBudget < 0 {
+		return fmt.Errorf("Asset parameters cannot be negative")
+	}
+
 	// The delivery pkScript must be amongst the supported script
 	// templates.
 	if !isValidPkScript(c.DeliveryPkScript) {
@@ -188,5 +260,12 @@ func (c *SingleFundingResponse) String() string {
 		fmt.Sprintf("RevocationKey\t\t\t\t%x\n", rk) +
 		fmt.Sprintf("CsvDelay\t\t%d\n", c.CsvDelay) +
 		fmt.Sprintf("DeliveryPkScript\t\t%x\n", c.DeliveryPkScript) +
+		fmt.Sprintf("AssetID\t\t%s\n", c.AssetID) +
+		fmt.Sprintf("AssetDustLimit\t\t%d\n", c.AssetDustLimit) +
+		fmt.Sprintf("MaxHTLCAssetValue\t\t%d\n", c.MaxHTLCAssetValue) +
+		fmt.Sprintf("CarrierSatBudget\t\t%d\n", c.CarrierSatBudget) +
+		fmt.Sprintf("InstructionVersion\t\t%d\n", c.InstructionVersion) +
+		fmt.Sprintf("DustPolicy\t\t%d\n", c.DustPolicy) +
+		fmt.Sprintf("FeatureFlags\t\t%08b\n", c.FeatureFlags) +
 		fmt.Sprintf("--- End SingleFundingResponse ---\n")
 }
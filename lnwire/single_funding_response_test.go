@@ -10,6 +10,12 @@ func TestSingleFundingResponseWire(t *testing.T) {
 	// First create a new SFR message.
 	delivery := PkScript(bytes.Repeat([]byte{0x02}, 25))
 	sfr := NewSingleFundingResponse(22, pubKey, pubKey, pubKey, 5, delivery)
+	sfr.AssetID = "La4szjzKfJyHQ75qgDEnbzp4qY8GQeDR5Z7h2W"
+	sfr.AssetDustLimit = 1
+	sfr.MaxHTLCAssetValue = 5000
+	sfr.CarrierSatBudget = 8190
+	sfr.InstructionVersion = 1
+	sfr.FeatureFlags = FeatureSecondLevelHTLCs
 
 	// Next encode the SFR message into an empty bytes buffer.
 	var b bytes.Buffer
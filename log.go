@@ -6,9 +6,13 @@ import (
 
 	"github.com/btcsuite/btclog"
 	"github.com/btcsuite/seelog"
+	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/replication"
+	"github.com/lightningnetwork/lnd/watchtower"
+	"github.com/roasbeef/btcd/wire"
 )
 
 // Loggers per subsystem.  Note that backendLog is a seelog logger that all of
@@ -27,6 +31,8 @@ var (
 	chdbLog    = btclog.Disabled
 	hswcLog    = btclog.Disabled
 	utxnLog    = btclog.Disabled
+	wtwrLog    = btclog.Disabled
+	replLog    = btclog.Disabled
 )
 
 // subsystemLoggers maps each subsystem identifier to its associated logger.
@@ -41,6 +47,8 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"FNDG": fndgLog,
 	"HSWC": hswcLog,
 	"UTXN": utxnLog,
+	"WTWR": wtwrLog,
+	"REPL": replLog,
 }
 
 // useLogger updates the logger references for subsystemID to logger.  Invalid
@@ -83,6 +91,14 @@ func useLogger(subsystemID string, logger btclog.Logger) {
 		hswcLog = logger
 	case "UTXN":
 		utxnLog = logger
+
+	case "WTWR":
+		wtwrLog = logger
+		watchtower.UseLogger(logger)
+
+	case "REPL":
+		replLog = logger
+		replication.UseLogger(logger)
 	}
 }
 
@@ -161,3 +177,16 @@ func (c logClosure) String() string {
 func newLogClosure(c func() string) logClosure {
 	return logClosure(c)
 }
+
+// newTxLogClosure returns a log closure which dumps the passed transaction in
+// full if the dumptxns option is set, and otherwise summarizes it on a single
+// line. Either way, the transaction is only formatted once the log level
+// warrants it.
+func newTxLogClosure(tx *wire.MsgTx, assetID string) logClosure {
+	return newLogClosure(func() string {
+		if lnwallet.TxDumpsEnabled() {
+			return spew.Sdump(tx)
+		}
+		return lnwallet.TxSummary(tx, assetID)
+	})
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/lightningnetwork/lnd/metrics"
+)
+
+// registerMetrics registers the metrics computed from the state of the server
+// itself within the default metrics registry, alongside those recorded by the
+// wallet and the colored coins services.
+func (s *server) registerMetrics() {
+	metrics.DefaultRegistry.NewGaugeFunc("lnd_open_channels",
+		"Number of open channels with connected peers, by asset.",
+		"asset", s.channelsByAsset)
+}
+
+// channelsByAsset returns the number of open channels with connected peers,
+// keyed by the asset they carry. Plain bitcoin channels are keyed by
+// btcAssetID.
+func (s *server) channelsByAsset() map[string]float64 {
+	counts := make(map[string]float64)
+	for _, peer := range s.Peers() {
+		for _, snapshot := range peer.ChannelSnapshots() {
+			assetID := snapshot.AssetID
+			if assetID == "" {
+				assetID = btcAssetID
+			}
+			counts[assetID]++
+		}
+	}
+
+	return counts
+}
@@ -0,0 +1,377 @@
+// Package metrics implements a minimal registry of counters, histograms and
+// gauges, which is exposed over HTTP in the Prometheus text exposition format
+// so the daemon can be scraped by a Prometheus server.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of a
+// histogram tracking the latency of requests to an external service.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1,
+	2.5, 5, 10}
+
+// DefaultRegistry is the registry the metrics of the daemon's subsystems are
+// recorded within.
+var DefaultRegistry = NewRegistry()
+
+// labelSeparator separates the label values of a sample when joined into a
+// single map key.
+const labelSeparator = "\xff"
+
+// metric is a single named metric within a registry, holding one sample per
+// distinct set of label values.
+type metric interface {
+	// write writes all samples of the metric to the passed writer, in the
+	// Prometheus text exposition format.
+	write(w io.Writer)
+}
+
+// Registry holds a set of metrics, serving their current values over HTTP.
+// The metrics are written in the order they were registered in.
+type Registry struct {
+	sync.Mutex
+
+	names   map[string]struct{}
+	metrics []metric
+}
+
+// NewRegistry creates a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]struct{}),
+	}
+}
+
+// register adds the passed metric to the registry. As metrics are registered
+// at initialization, registering two metrics of the same name is a
+// programming error, and panics.
+func (r *Registry) register(name string, m metric) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.names[name]; ok {
+		panic(fmt.Sprintf("metric %v registered twice", name))
+	}
+	r.names[name] = struct{}{}
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo writes the current value of every metric within the registry to
+// the passed writer, in the Prometheus text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.Lock()
+	metrics := make([]metric, len(r.metrics))
+	copy(metrics, r.metrics)
+	r.Unlock()
+
+	var b bytes.Buffer
+	for _, m := range metrics {
+		m.write(&b)
+	}
+
+	return b.WriteTo(w)
+}
+
+// ServeHTTP serves the current value of every metric within the registry, in
+// the Prometheus text exposition format. This allows the registry to be
+// mounted as an http.Handler.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteTo(w)
+}
+
+// desc is the description shared by all metric types: its name, help text,
+// and the names of the labels distinguishing its samples.
+type desc struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+// key joins the passed label values into a key identifying a sample. The
+// number of values must match the number of label names of the metric.
+func (d *desc) key(labelValues []string) string {
+	if len(labelValues) != len(d.labelNames) {
+		panic(fmt.Sprintf("metric %v has %v labels, got %v values",
+			d.name, len(d.labelNames), len(labelValues)))
+	}
+
+	return strings.Join(labelValues, labelSeparator)
+}
+
+// writeHeader writes the HELP and TYPE lines preceding the samples of the
+// metric.
+func (d *desc) writeHeader(w io.Writer, metricType string) {
+	fmt.Fprintf(w, "# HELP %v %v\n", d.name, escapeHelp(d.help))
+	fmt.Fprintf(w, "# TYPE %v %v\n", d.name, metricType)
+}
+
+// labels formats the label pairs of the sample identified by the passed key,
+// followed by the passed extra pairs, as they appear within a sample line.
+func (d *desc) labels(key string, extra ...string) string {
+	var pairs []string
+	if len(d.labelNames) != 0 {
+		for i, value := range strings.Split(key, labelSeparator) {
+			pairs = append(pairs, fmt.Sprintf("%v=\"%v\"",
+				d.labelNames[i], escapeLabel(value)))
+		}
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=\"%v\"", extra[i],
+			escapeLabel(extra[i+1])))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter is a metric whose samples only ever increase, such as the number of
+// failed requests.
+type Counter struct {
+	desc
+
+	mtx    sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter registers a new counter, whose samples are distinguished by the
+// passed label names.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	c := &Counter{
+		desc:   desc{name, help, labelNames},
+		values: make(map[string]float64),
+	}
+	r.register(name, c)
+
+	return c
+}
+
+// Inc increments the sample identified by the passed label values by one.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the sample identified by the passed label values by v, which
+// must not be negative.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("counter %v decreased by %v", c.name, v))
+	}
+
+	key := c.key(labelValues)
+
+	c.mtx.Lock()
+	c.values[key] += v
+	c.mtx.Unlock()
+}
+
+// write writes the samples of the counter. This is part of the metric
+// interface.
+func (c *Counter) write(w io.Writer) {
+	c.writeHeader(w, "counter")
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%v%v %v\n", c.name, c.labels(key),
+			formatValue(c.values[key]))
+	}
+}
+
+// histogramSample is a single sample of a histogram: the number of
+// observations within each bucket, along with the total number and sum of
+// all observations.
+type histogramSample struct {
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// Histogram is a metric which counts observations, such as request
+// latencies, within a set of buckets.
+type Histogram struct {
+	desc
+
+	// bounds are the inclusive upper bounds of each bucket, in increasing
+	// order.
+	bounds []float64
+
+	mtx     sync.Mutex
+	samples map[string]*histogramSample
+}
+
+// NewHistogram registers a new histogram with buckets bounded by the passed
+// upper bounds, whose samples are distinguished by the passed label names.
+func (r *Registry) NewHistogram(name, help string, bounds []float64,
+	labelNames ...string) *Histogram {
+
+	sortedBounds := make([]float64, len(bounds))
+	copy(sortedBounds, bounds)
+	sort.Float64s(sortedBounds)
+
+	h := &Histogram{
+		desc:    desc{name, help, labelNames},
+		bounds:  sortedBounds,
+		samples: make(map[string]*histogramSample),
+	}
+	r.register(name, h)
+
+	return h
+}
+
+// Observe records a single observation within the sample identified by the
+// passed label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	sample, ok := h.samples[key]
+	if !ok {
+		sample = &histogramSample{
+			buckets: make([]uint64, len(h.bounds)),
+		}
+		h.samples[key] = sample
+	}
+
+	if i := sort.SearchFloat64s(h.bounds, v); i < len(h.bounds) {
+		sample.buckets[i]++
+	}
+	sample.count++
+	sample.sum += v
+}
+
+// ObserveSince records the time elapsed since the passed start time, in
+// seconds, within the sample identified by the passed label values.
+func (h *Histogram) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// write writes the samples of the histogram. This is part of the metric
+// interface.
+func (h *Histogram) write(w io.Writer) {
+	h.writeHeader(w, "histogram")
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	keys := make([]string, 0, len(h.samples))
+	for key := range h.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		sample := h.samples[key]
+
+		// Buckets are cumulative within the exposition format, each
+		// counting all observations up to its bound.
+		var cumulative uint64
+		for i, bound := range h.bounds {
+			cumulative += sample.buckets[i]
+			fmt.Fprintf(w, "%v_bucket%v %v\n", h.name,
+				h.labels(key, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%v_bucket%v %v\n", h.name,
+			h.labels(key, "le", "+Inf"), sample.count)
+		fmt.Fprintf(w, "%v_sum%v %v\n", h.name, h.labels(key),
+			formatValue(sample.sum))
+		fmt.Fprintf(w, "%v_count%v %v\n", h.name, h.labels(key),
+			sample.count)
+	}
+}
+
+// GaugeFunc is a metric whose samples are computed on demand each time the
+// registry is scraped, such as the number of open channels.
+type GaugeFunc struct {
+	desc
+
+	values func() map[string]float64
+}
+
+// NewGaugeFunc registers a new gauge, whose samples are returned by the passed
+// function keyed by the value of a single label. If labelName is empty, the
+// gauge has a single sample, keyed by the empty string.
+func (r *Registry) NewGaugeFunc(name, help, labelName string,
+	values func() map[string]float64) *GaugeFunc {
+
+	var labelNames []string
+	if labelName != "" {
+		labelNames = []string{labelName}
+	}
+
+	g := &GaugeFunc{
+		desc:   desc{name, help, labelNames},
+		values: values,
+	}
+	r.register(name, g)
+
+	return g
+}
+
+// write writes the samples of the gauge. This is part of the metric
+// interface.
+func (g *GaugeFunc) write(w io.Writer) {
+	g.writeHeader(w, "gauge")
+
+	values := g.values()
+	for _, key := range sortedKeys(values) {
+		fmt.Fprintf(w, "%v%v %v\n", g.name, g.labels(key),
+			formatValue(values[key]))
+	}
+}
+
+// sortedKeys returns the keys of the passed samples in sorted order, so
+// samples are always written in the same order.
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// formatValue formats a sample value as it appears within the exposition
+// format.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value for the exposition format.
+func escapeLabel(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return strings.Replace(s, `"`, `\"`, -1)
+}
+
+// escapeHelp escapes the help text of a metric for the exposition format.
+func escapeHelp(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return strings.Replace(s, "\n", `\n`, -1)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRegistryExposition tests that the samples of each metric type are
+// written in the Prometheus text exposition format.
+func TestRegistryExposition(t *testing.T) {
+	r := NewRegistry()
+
+	counter := r.NewCounter("test_errors_total", "Failed requests.",
+		"service")
+	counter.Inc("txo")
+	counter.Add(2, "encode")
+	counter.Inc("txo")
+
+	histogram := r.NewHistogram("test_duration_seconds", "Latency.",
+		[]float64{1, 0.5}, "service")
+	histogram.Observe(0.5, "encode")
+	histogram.Observe(0.75, "encode")
+	histogram.Observe(3, "encode")
+
+	r.NewGaugeFunc("test_channels", "Open \"channels\".", "asset",
+		func() map[string]float64 {
+			return map[string]float64{"BTC": 2, `a"b`: 1}
+		})
+
+	var b bytes.Buffer
+	if _, err := r.WriteTo(&b); err != nil {
+		t.Fatalf("unable to write metrics: %v", err)
+	}
+
+	expected := `# HELP test_errors_total Failed requests.
+# TYPE test_errors_total counter
+test_errors_total{service="encode"} 2
+test_errors_total{service="txo"} 2
+# HELP test_duration_seconds Latency.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{service="encode",le="0.5"} 1
+test_duration_seconds_bucket{service="encode",le="1"} 2
+test_duration_seconds_bucket{service="encode",le="+Inf"} 3
+test_duration_seconds_sum{service="encode"} 4.25
+test_duration_seconds_count{service="encode"} 3
+# HELP test_channels Open "channels".
+# TYPE test_channels gauge
+test_channels{asset="BTC"} 2
+test_channels{asset="a\"b"} 1
+`
+	if b.String() != expected {
+		t.Fatalf("unexpected exposition, got:\n%v\nexpected:\n%v",
+			b.String(), expected)
+	}
+
+	// The same exposition should be served over HTTP.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Body.String() != expected {
+		t.Fatalf("unexpected exposition served: %v", rec.Body.String())
+	}
+}
+
+// TestRegistryDuplicate tests that registering two metrics with the same name
+// panics.
+func TestRegistryDuplicate(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounter("test_total", "")
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("duplicate metric registered")
+		}
+	}()
+	r.NewCounter("test_total", "")
+}
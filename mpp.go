@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+const (
+	// mppRecordType is the first byte of an HTLC payload which marks the
+	// HTLC as a single part of a multi-part payment.
+	mppRecordType = 0x6d
+
+	// mppRecordSize is the size of a multi-part record: the record type,
+	// the payment identifier shared by all parts, then the total amount
+	// of the payment.
+	mppRecordSize = 1 + 32 + 8
+
+	// mppTimeout is the duration the parts of a multi-part payment are
+	// held while waiting for the remaining parts to arrive. Once elapsed,
+	// all parts received so far are failed back.
+	mppTimeout = time.Minute
+)
+
+// errNoMPPRecord is returned when an HTLC's payload doesn't carry a
+// multi-part record.
+var errNoMPPRecord = errors.New("payload carries no multi-part record")
+
+// mppRecord is carried within the payload of each HTLC of a multi-part
+// payment, allowing the receiver to aggregate the parts of the payment
+// arriving over several channels.
+type mppRecord struct {
+	// paymentID uniquely identifies the payment all parts belong to.
+	paymentID [32]byte
+
+	// totalAmt is the total amount of the payment, across all parts.
+	totalAmt btcutil.Amount
+}
+
+// encode serializes the multi-part record into an HTLC payload.
+func (r *mppRecord) encode() []byte {
+	var b bytes.Buffer
+	b.WriteByte(mppRecordType)
+	b.Write(r.paymentID[:])
+	binary.Write(&b, binary.BigEndian, uint64(r.totalAmt))
+
+	return b.Bytes()
+}
+
+// decodeMPPRecord parses the multi-part record within the passed HTLC payload.
+// If the payload doesn't carry a multi-part record, then errNoMPPRecord is
+// returned.
+func decodeMPPRecord(payload []byte) (*mppRecord, error) {
+	if len(payload) != mppRecordSize || payload[0] != mppRecordType {
+		return nil, errNoMPPRecord
+	}
+
+	record := &mppRecord{}
+	copy(record.paymentID[:], payload[1:33])
+	record.totalAmt = btcutil.Amount(binary.BigEndian.Uint64(payload[33:]))
+	if record.totalAmt <= 0 {
+		return nil, fmt.Errorf("invalid multi-part total %v",
+			record.totalAmt)
+	}
+
+	return record, nil
+}
+
+// paymentSet is the set of parts of a multi-part payment received so far.
+type paymentSet struct {
+	record *mppRecord
+
+	paymentHash [32]byte
+	assetID     string
+
+	receivedAmt btcutil.Amount
+	parts       []*InterceptedHTLC
+
+	timer *time.Timer
+}
+
+// paymentAggregator holds the parts of incoming multi-part payments, which
+// may arrive over several channels, until every part has been received. As
+// the capacity of individual colored channels is limited, this allows a large
+// payment to be carried by the combined capacity of several channels.
+type paymentAggregator struct {
+	sync.Mutex
+
+	timeout time.Duration
+	sets    map[[32]byte]*paymentSet
+}
+
+// newPaymentAggregator creates a new paymentAggregator which fails back all
+// parts of a payment which isn't completed within the passed timeout.
+func newPaymentAggregator(timeout time.Duration) *paymentAggregator {
+	return &paymentAggregator{
+		timeout: timeout,
+		sets:    make(map[[32]byte]*paymentSet),
+	}
+}
+
+// addPart adds an incoming HTLC to the set of the multi-part payment it
+// belongs to. Once the combined amount of all parts reaches the total amount
+// of the payment, the complete set of parts is returned, and should be
+// settled. An error is returned if the part is inconsistent with the parts
+// already received, in which case it should be failed back.
+func (a *paymentAggregator) addPart(record *mppRecord,
+	part *InterceptedHTLC) ([]*InterceptedHTLC, error) {
+
+	a.Lock()
+	defer a.Unlock()
+
+	set, ok := a.sets[record.paymentID]
+	if !ok {
+		set = &paymentSet{
+			record:      record,
+			paymentHash: part.PaymentHash,
+			assetID:     part.AssetID,
+		}
+		paymentID := record.paymentID
+		set.timer = time.AfterFunc(a.timeout, func() {
+			a.expire(paymentID)
+		})
+		a.sets[paymentID] = set
+	}
+
+	switch {
+	case set.record.totalAmt != record.totalAmt:
+		return nil, fmt.Errorf("part declares total %v, payment "+
+			"total is %v", record.totalAmt, set.record.totalAmt)
+
+	case set.paymentHash != part.PaymentHash:
+		return nil, fmt.Errorf("part pays to hash %x, payment pays "+
+			"to %x", part.PaymentHash[:], set.paymentHash[:])
+
+	case set.assetID != part.AssetID:
+		return nil, fmt.Errorf("part is denominated in asset %q, "+
+			"payment in %q", part.AssetID, set.assetID)
+	}
+
+	set.parts = append(set.parts, part)
+	set.receivedAmt += part.Amount
+	if set.receivedAmt < set.record.totalAmt {
+		return nil, nil
+	}
+
+	set.timer.Stop()
+	delete(a.sets, record.paymentID)
+
+	return set.parts, nil
+}
+
+// expire fails back all parts received for the target payment, if it still
+// hasn't been completed.
+func (a *paymentAggregator) expire(paymentID [32]byte) {
+	a.Lock()
+	set, ok := a.sets[paymentID]
+	delete(a.sets, paymentID)
+	a.Unlock()
+
+	if !ok {
+		return
+	}
+
+	peerLog.Warnf("Multi-part payment %x timed out with %v of %v "+
+		"received", paymentID[:], set.receivedAmt, set.record.totalAmt)
+
+	for _, part := range set.parts {
+		if err := part.Fail(); err != nil {
+			peerLog.Errorf("unable to fail part of multi-part "+
+				"payment: %v", err)
+		}
+	}
+}
+
+// paymentPart is a single part of an outgoing multi-part payment, along with
+// the channel it's to be sent over.
+type paymentPart struct {
+	chanPoint wire.OutPoint
+	amt       btcutil.Amount
+}
+
+// byBandwidth sorts payment parts by descending amount, breaking ties by
+// channel point in order to split payments deterministically.
+type byBandwidth []*paymentPart
+
+func (b byBandwidth) Len() int      { return len(b) }
+func (b byBandwidth) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b byBandwidth) Less(i, j int) bool {
+	if b[i].amt != b[j].amt {
+		return b[i].amt > b[j].amt
+	}
+	return b[i].chanPoint.String() < b[j].chanPoint.String()
+}
+
+// splitPayment carves the passed amount into parts, each sent over a distinct
+// channel. Channels with the largest available bandwidth are used first, in
+// order to minimize the number of parts. An error is returned if the combined
+// bandwidth of all channels is insufficient.
+func splitPayment(amt btcutil.Amount,
+	bandwidths map[wire.OutPoint]btcutil.Amount) ([]*paymentPart, error) {
+
+	candidates := make(byBandwidth, 0, len(bandwidths))
+	for chanPoint, bandwidth := range bandwidths {
+		if bandwidth <= 0 {
+			continue
+		}
+		candidates = append(candidates, &paymentPart{chanPoint, bandwidth})
+	}
+	sort.Sort(candidates)
+
+	var parts []*paymentPart
+	remaining := amt
+	for _, candidate := range candidates {
+		if remaining == 0 {
+			break
+		}
+
+		partAmt := candidate.amt
+		if partAmt > remaining {
+			partAmt = remaining
+		}
+
+		parts = append(parts, &paymentPart{candidate.chanPoint, partAmt})
+		remaining -= partAmt
+	}
+	if remaining != 0 {
+		return nil, fmt.Errorf("insufficient bandwidth for payment of "+
+			"%v, short by %v", amt, remaining)
+	}
+
+	return parts, nil
+}
+
+// sendMultiPartPayment pays the passed amount of an asset to the target node
+// by splitting the payment into several HTLCs, each sent over a distinct
+// channel to the node. All parts pay to the same payment hash, and share a
+// payment identifier, allowing the receiver to only settle the payment once
+// every part has arrived.
+func (s *server) sendMultiPartPayment(dest wire.ShaHash, assetID string,
+	amt btcutil.Amount, paymentHash [32]byte) error {
+
+	bandwidths := s.htlcSwitch.LinkBandwidths(dest, assetID)
+	parts, err := splitPayment(amt, bandwidths)
+	if err != nil {
+		return err
+	}
+
+	record := &mppRecord{totalAmt: amt}
+	if _, err := rand.Read(record.paymentID[:]); err != nil {
+		return err
+	}
+	payload := record.encode()
+
+	// Each part is only settled once all parts have been received, so the
+	// parts must be sent concurrently.
+	errChan := make(chan error, len(parts))
+	for _, part := range parts {
+		chanPoint := part.chanPoint
+		htlcPkt := &htlcPacket{
+			dest: dest,
+			msg: &lnwire.HTLCAddRequest{
+				Amount:           lnwire.CreditsAmount(part.amt),
+				RedemptionHashes: [][32]byte{paymentHash},
+				OnionBlob:        payload,
+			},
+			outgoingChan: &chanPoint,
+		}
+
+		go func() {
+			errChan <- s.htlcSwitch.SendHTLC(htlcPkt)
+		}()
+	}
+
+	for range parts {
+		if err := <-errChan; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
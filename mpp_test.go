@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestSplitPayment tests that payments are split across the channels with the
+// largest bandwidth first, and rejected if the combined bandwidth is
+// insufficient.
+func TestSplitPayment(t *testing.T) {
+	chanA := wire.OutPoint{Index: 1}
+	chanB := wire.OutPoint{Index: 2}
+	chanC := wire.OutPoint{Index: 3}
+	bandwidths := map[wire.OutPoint]btcutil.Amount{
+		chanA: 100,
+		chanB: 300,
+		chanC: 0,
+	}
+
+	parts, err := splitPayment(350, bandwidths)
+	if err != nil {
+		t.Fatalf("unable to split payment: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %v", len(parts))
+	}
+	if parts[0].chanPoint != chanB || parts[0].amt != 300 {
+		t.Fatalf("unexpected first part: %v", parts[0])
+	}
+	if parts[1].chanPoint != chanA || parts[1].amt != 50 {
+		t.Fatalf("unexpected second part: %v", parts[1])
+	}
+
+	// A payment fitting within a single channel isn't split.
+	parts, err = splitPayment(200, bandwidths)
+	if err != nil {
+		t.Fatalf("unable to split payment: %v", err)
+	}
+	if len(parts) != 1 || parts[0].chanPoint != chanB {
+		t.Fatalf("payment shouldn't have been split: %v", parts)
+	}
+
+	if _, err := splitPayment(401, bandwidths); err == nil {
+		t.Fatalf("payment exceeding bandwidth should be rejected")
+	}
+}
+
+// TestPaymentAggregator tests that the parts of a multi-part payment are only
+// released once all have arrived, that inconsistent parts are rejected, and
+// that incomplete payments are failed back once they time out.
+func TestPaymentAggregator(t *testing.T) {
+	record := &mppRecord{paymentID: [32]byte{1}, totalAmt: 500}
+	decoded, err := decodeMPPRecord(record.encode())
+	if err != nil {
+		t.Fatalf("unable to decode record: %v", err)
+	}
+	if *decoded != *record {
+		t.Fatalf("expected record %v, got %v", record, decoded)
+	}
+	if _, err := decodeMPPRecord(nil); err != errNoMPPRecord {
+		t.Fatalf("expected errNoMPPRecord, got %v", err)
+	}
+
+	aggregator := newPaymentAggregator(time.Hour)
+	newPart := func(amt btcutil.Amount) *InterceptedHTLC {
+		return &InterceptedHTLC{
+			PaymentHash: [32]byte{2},
+			AssetID:     "assetA",
+			Amount:      amt,
+			resolutions: make(chan *htlcResolution, 1),
+			quit:        make(chan struct{}),
+		}
+	}
+
+	parts, err := aggregator.addPart(record, newPart(200))
+	if err != nil || parts != nil {
+		t.Fatalf("incomplete payment released: %v, %v", parts, err)
+	}
+
+	// Parts disagreeing on the payment's total or asset are rejected.
+	conflicting := *record
+	conflicting.totalAmt = 600
+	if _, err := aggregator.addPart(&conflicting, newPart(300)); err == nil {
+		t.Fatalf("part with conflicting total accepted")
+	}
+	otherAsset := newPart(300)
+	otherAsset.AssetID = "assetB"
+	if _, err := aggregator.addPart(record, otherAsset); err == nil {
+		t.Fatalf("part of another asset accepted")
+	}
+
+	parts, err = aggregator.addPart(record, newPart(300))
+	if err != nil {
+		t.Fatalf("unable to add part: %v", err)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %v", len(parts))
+	}
+
+	// An incomplete payment should have its parts failed back once it
+	// times out.
+	aggregator = newPaymentAggregator(10 * time.Millisecond)
+	resolutions := make(chan *htlcResolution, 1)
+	part := newPart(200)
+	part.resolutions = resolutions
+	if _, err := aggregator.addPart(record, part); err != nil {
+		t.Fatalf("unable to add part: %v", err)
+	}
+	select {
+	case res := <-resolutions:
+		if res.preimage != nil {
+			t.Fatalf("timed out part should be failed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("timed out part wasn't failed")
+	}
+}
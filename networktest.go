@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"io/ioutil"
@@ -19,6 +20,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/grpclog"
 
+	"github.com/lightningnetwork/lnd/lndcc"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/roasbeef/btcd/chaincfg"
 	"github.com/roasbeef/btcd/rpctest"
@@ -47,6 +49,11 @@ var (
 	defaultClientPort = 19556
 
 	harnessNetParams = &chaincfg.SimNetParams
+
+	// harnessAssetID is the identifier of the asset issued to the wallets
+	// of the seeder nodes, which all channels within the test network are
+	// denominated in.
+	harnessAssetID = "Ua3Kt7WtnL8rVBNDrE9dbQkF4iBnDzfTgmG1Yv"
 )
 
 // generateListeningPorts returns two strings representing ports to listen on
@@ -89,6 +96,11 @@ type lightningNode struct {
 
 	extraArgs []string
 
+	// env holds additional environment variables the lnd process is
+	// launched with, such as those pointing it towards the harness' color
+	// service.
+	env []string
+
 	lnrpc.LightningClient
 }
 
@@ -157,6 +169,7 @@ func (l *lightningNode) start() error {
 	args := l.genArgs()
 
 	l.cmd = exec.Command("lnd", args...)
+	l.cmd.Env = append(os.Environ(), l.env...)
 	if err := l.cmd.Start(); err != nil {
 		return err
 	}
@@ -258,6 +271,10 @@ type networkHarness struct {
 	Alice *lightningNode
 	Bob   *lightningNode
 
+	// ColorService is the mock color service the nodes within the test
+	// network encode transfers with, and query the color of outputs from.
+	ColorService *mockColorService
+
 	seenTxns      chan wire.ShaHash
 	watchRequests chan *watchRequest
 }
@@ -284,6 +301,7 @@ func (n *networkHarness) InitializeSeedNodes(r *rpctest.Harness, lndArgs []strin
 	n.netParams = r.ActiveNet
 	n.Miner = r
 	n.rpcConfig = nodeConfig
+	n.ColorService = newMockColorService(r.Node, harnessAssetID)
 
 	var err error
 	n.Alice, err = newLightningNode(&nodeConfig, lndArgs)
@@ -294,6 +312,8 @@ func (n *networkHarness) InitializeSeedNodes(r *rpctest.Harness, lndArgs []strin
 	if err != nil {
 		return err
 	}
+	n.Alice.env = n.ColorService.Env()
+	n.Bob.env = n.ColorService.Env()
 
 	n.activeNodes[n.Alice.nodeId] = n.Alice
 	n.activeNodes[n.Bob.nodeId] = n.Bob
@@ -313,7 +333,8 @@ func (f *fakeLogger) Printf(format string, args ...interface{}) {}
 func (f *fakeLogger) Println(args ...interface{})               {}
 
 // SetUp starts the initial seeder nodes within the test harness. The initial
-// node's wallets will be funded wallets with ten 1 BTC outputs each. Finally
+// node's wallets will be funded wallets with ten 1 BTC outputs each, with
+// each output additionally issued 1e8 units of the harness' asset. Finally
 // rpc clients capable of communicating with the initial seeder nodes are
 // created.
 func (n *networkHarness) SetUp() error {
@@ -373,7 +394,11 @@ func (n *networkHarness) SetUp() error {
 				PkScript: addrScript,
 				Value:    btcutil.SatoshiPerBitcoin,
 			}
-			if _, err := n.Miner.CoinbaseSpend([]*wire.TxOut{output}); err != nil {
+			txid, err := n.Miner.CoinbaseSpend([]*wire.TxOut{output})
+			if err != nil {
+				return err
+			}
+			if err := n.issueAsset(txid, output); err != nil {
 				return err
 			}
 		}
@@ -431,7 +456,54 @@ out:
 	return nil
 }
 
-// TearDownAll tears down all active nodes within the test lightning network.
+// issueAsset issues the harness' asset to the output of the target
+// transaction matching the passed output. The output's value in satoshis is
+// used as the amount of the asset issued.
+func (n *networkHarness) issueAsset(txid *wire.ShaHash, output *wire.TxOut) error {
+	tx, err := n.Miner.Node.GetRawTransaction(txid)
+	if err != nil {
+		return err
+	}
+
+	for i, txOut := range tx.MsgTx().TxOut {
+		if txOut.Value != output.Value ||
+			!bytes.Equal(txOut.PkScript, output.PkScript) {
+			continue
+		}
+
+		op := wire.OutPoint{Hash: *txid, Index: uint32(i)}
+		n.ColorService.Issue(op, btcutil.Amount(output.Value))
+		return nil
+	}
+
+	return fmt.Errorf("output not found within tx %v", txid)
+}
+
+// FetchTxAssets fetches the target transaction, along with the asset carried
+// by each of its outputs according to the harness' color service.
+func (n *networkHarness) FetchTxAssets(txid *wire.ShaHash) (*wire.MsgTx,
+	[]*lndcc.TxoData, error) {
+
+	tx, err := n.Miner.Node.GetRawTransaction(txid)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msgTx := tx.MsgTx()
+	assets := make([]*lndcc.TxoData, len(msgTx.TxOut))
+	for i := range msgTx.TxOut {
+		op := wire.OutPoint{Hash: *txid, Index: uint32(i)}
+		assets[i], err = n.ColorService.TxoData(op)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return msgTx, assets, nil
+}
+
+// TearDownAll tears down all active nodes within the test lightning network,
+// along with the color service.
 func (n *networkHarness) TearDownAll() error {
 	for _, node := range n.activeNodes {
 		if err := node.shutdown(); err != nil {
@@ -439,6 +511,10 @@ func (n *networkHarness) TearDownAll() error {
 		}
 	}
 
+	if n.ColorService != nil {
+		n.ColorService.Close()
+	}
+
 	return nil
 }
 
@@ -50,6 +50,12 @@ type chanSnapshotReq struct {
 	resp chan []*channeldb.ChannelSnapshot
 }
 
+// activeChansReq is a message sent by outside sub-systems to a peer in order
+// to obtain its currently active channels.
+type activeChansReq struct {
+	resp chan []*lnwallet.LightningChannel
+}
+
 // peer is an active peer on the Lightning Network. This struct is responsible
 // for managing any channel state related to this peer. To do so, it has several
 // helper goroutines to handle events such as HTLC timeouts, new funding
@@ -103,6 +109,7 @@ type peer struct {
 	// the funding transaction which opened the channel.
 	activeChannels   map[wire.OutPoint]*lnwallet.LightningChannel
 	chanSnapshotReqs chan *chanSnapshotReq
+	activeChansReqs  chan *activeChansReq
 
 	htlcManagers map[wire.OutPoint]chan lnwire.Message
 
@@ -127,6 +134,14 @@ type peer struct {
 	// over.
 	remoteCloseChanReqs chan *lnwire.CloseRequest
 
+	// backupUpdates is signalled whenever the backup held by the remote
+	// peer should be refreshed. returnedBackups carries backups the peer
+	// returns to us, and dataLossReqs the channels for which the peer
+	// reports having lost state.
+	backupUpdates   chan struct{}
+	returnedBackups chan []byte
+	dataLossReqs    chan *wire.OutPoint
+
 	// nextPendingChannelID is an integer which represents the id of the
 	// next pending channel. Pending channels are tracked by this id
 	// throughout their lifetime until they become active channels, or are
@@ -138,6 +153,10 @@ type peer struct {
 
 	server *server
 
+	// updateLimiter limits the rate of the channel updates sent by the
+	// peer, and the number of its commitments awaiting processing.
+	updateLimiter *updateLimiter
+
 	queueQuit chan struct{}
 	quit      chan struct{}
 	wg        sync.WaitGroup
@@ -156,7 +175,8 @@ func newPeer(conn net.Conn, server *server, btcNet wire.BitcoinNet, inbound bool
 		chainNet:    btcNet,
 		inbound:     inbound,
 
-		server: server,
+		server:        server,
+		updateLimiter: newUpdateLimiter(server.updateLimits),
 
 		lastNMessages: make(map[lnwire.Message]struct{}),
 
@@ -169,11 +189,16 @@ func newPeer(conn net.Conn, server *server, btcNet wire.BitcoinNet, inbound bool
 		activeChannels:   make(map[wire.OutPoint]*lnwallet.LightningChannel),
 		htlcManagers:     make(map[wire.OutPoint]chan lnwire.Message),
 		chanSnapshotReqs: make(chan *chanSnapshotReq),
+		activeChansReqs:  make(chan *activeChansReq),
 		newChannels:      make(chan *lnwallet.LightningChannel, 1),
 
 		localCloseChanReqs:  make(chan *closeLinkReq),
 		remoteCloseChanReqs: make(chan *lnwire.CloseRequest),
 
+		backupUpdates:   make(chan struct{}, 1),
+		returnedBackups: make(chan []byte),
+		dataLossReqs:    make(chan *wire.OutPoint),
+
 		queueQuit: make(chan struct{}),
 		quit:      make(chan struct{}),
 	}
@@ -231,6 +256,13 @@ func (p *peer) loadActiveChannels(chans []*channeldb.OpenChannel) error {
 		p.activeChannels[chanPoint] = lnChan
 		peerLog.Infof("peerID(%v) loaded ChannelPoint(%v)", p.id, chanPoint)
 
+		// The funding transaction of a zero-conf channel may not have
+		// confirmed before we restarted, so it's watched for once
+		// again.
+		if lnChan.FundingUnconfirmed {
+			go p.server.watchZeroConfChannel(chanPoint)
+		}
+
 		// Register this new channel link with the HTLC Switch. This is
 		// necessary to properly route multi-hop payments, and forward
 		// new payments triggered by RPC clients.
@@ -257,6 +289,17 @@ func (p *peer) Start() error {
 
 	peerLog.Tracef("peer %v starting", p)
 
+	// If we have channels open with the peer, refresh the backup it holds
+	// of them. Otherwise, we may have lost our state, so request the
+	// backup back.
+	if cfg.PeerBackup {
+		if len(p.activeChannels) != 0 {
+			p.signalBackupUpdate()
+		} else {
+			p.queueMsg(&lnwire.PeerBackupRequest{}, nil)
+		}
+	}
+
 	p.wg.Add(4)
 	go p.readHandler()
 	go p.queueHandler()
@@ -374,12 +417,18 @@ out:
 		case *lnwire.HTLCSettleRequest:
 			isChanUpate = true
 			targetChan = msg.ChannelPoint
+		case *lnwire.HTLCTimeoutRequest:
+			isChanUpate = true
+			targetChan = msg.ChannelPoint
 		case *lnwire.CommitRevocation:
 			isChanUpate = true
 			targetChan = msg.ChannelPoint
 		case *lnwire.CommitSignature:
 			isChanUpate = true
 			targetChan = msg.ChannelPoint
+		case *lnwire.CommitReestablish:
+			isChanUpate = true
+			targetChan = msg.ChannelPoint
 		case *lnwire.NeighborAckMessage,
 			*lnwire.NeighborHelloMessage,
 			*lnwire.NeighborRstMessage,
@@ -388,6 +437,17 @@ out:
 			*lnwire.RoutingTableTransferMessage:
 			// Convert to base routing message and set sender and receiver
 			p.server.routingMgr.ReceiveRoutingMessage(msg, graph.NewID(([32]byte)(p.lightningID)))
+		case *lnwire.ChannelAnnouncement:
+			go p.server.processChannelAnnouncement(msg, p)
+		case *lnwire.PeerBackup, *lnwire.PeerBackupRequest:
+			p.handlePeerBackupMsg(msg)
+		case *lnwire.ErrorGeneric:
+			if msg.ErrorID == errChannelDataLoss && cfg.PeerBackup {
+				select {
+				case p.dataLossReqs <- msg.ChannelPoint:
+				case <-p.quit:
+				}
+			}
 		}
 
 		if isChanUpate {
@@ -410,6 +470,12 @@ out:
 					"closed", targetChan)
 			}
 
+			// Peers flooding us with updates are penalized before
+			// the updates reach their channel.
+			if !p.limitUpdate(nextMsg, targetChan) {
+				continue
+			}
+
 			// Dispatch the commitment update message to the proper
 			// active goroutine dedicated to this channel.
 			targetChan, ok := p.htlcManagers[*targetChan]
@@ -418,6 +484,7 @@ out:
 					targetChan)
 				continue
 			}
+			p.updateLimiter.dispatched(nextMsg)
 			targetChan <- nextMsg
 		}
 	}
@@ -555,12 +622,30 @@ func (p *peer) ChannelSnapshots() []*channeldb.ChannelSnapshot {
 	return <-resp
 }
 
+// ActiveChannels returns the channels currently active with the peer.
+func (p *peer) ActiveChannels() []*lnwallet.LightningChannel {
+	resp := make(chan []*lnwallet.LightningChannel, 1)
+	select {
+	case p.activeChansReqs <- &activeChansReq{resp}:
+	case <-p.quit:
+		return nil
+	}
+	return <-resp
+}
+
 // channelManager is goroutine dedicated to handling all requests/signals
 // pertaining to the opening, cooperative closing, and force closing of all
 // channels maintained with the remote peer.
 //
 // NOTE: This method MUST be run as a goroutine.
 func (p *peer) channelManager() {
+	var backupRefresh <-chan time.Time
+	if cfg.PeerBackup {
+		ticker := time.NewTicker(peerBackupRefreshInterval)
+		defer ticker.Stop()
+		backupRefresh = ticker.C
+	}
+
 out:
 	for {
 		select {
@@ -572,6 +657,13 @@ out:
 			}
 			req.resp <- snapshots
 
+		case req := <-p.activeChansReqs:
+			channels := make([]*lnwallet.LightningChannel, 0, len(p.activeChannels))
+			for _, activeChan := range p.activeChannels {
+				channels = append(channels, activeChan)
+			}
+			req.resp <- channels
+
 		case pendingChanPoint := <-p.barrierInits:
 			p.barrierMtx.Lock()
 			peerLog.Tracef("Creating chan barrier for "+
@@ -601,6 +693,8 @@ out:
 			p.wg.Add(1)
 			go p.htlcManager(newChan, plexChan, downstreamLink, upstreamLink)
 
+			p.server.notifyChannelEvent(ChannelOpened, chanSnapShot, nil)
+
 			// Close the active channel barrier signalling the
 			// readHandler that commitment related modifications to
 			// this channel can now proceed.
@@ -610,6 +704,25 @@ out:
 			delete(p.newChanBarriers, chanPoint)
 			p.barrierMtx.Unlock()
 
+			p.signalBackupUpdate()
+
+		case <-p.backupUpdates:
+			p.sendPeerBackup()
+
+		// A periodic refresh is skipped while we have no channels, so
+		// a backup held from before we lost our state isn't overwritten
+		// before we've had the chance to request it back.
+		case <-backupRefresh:
+			if len(p.activeChannels) != 0 {
+				p.sendPeerBackup()
+			}
+
+		case blob := <-p.returnedBackups:
+			p.handleReturnedBackup(blob)
+
+		case chanPoint := <-p.dataLossReqs:
+			p.handleDataLoss(chanPoint)
+
 		case req := <-p.localCloseChanReqs:
 			p.handleLocalClose(req)
 
@@ -642,17 +755,24 @@ func (p *peer) executeForceClose(channel *lnwallet.LightningChannel) (*wire.ShaH
 
 	// With the close transaction in hand, broadcast the transaction to the
 	// network, thereby entering the psot channel resolution state.
-	peerLog.Infof("Broadcasting force close transaction: %v",
-		channel.ChannelPoint(), newLogClosure(func() string {
-			return spew.Sdump(closeTx)
-		}))
-	if err := p.server.lnwallet.PublishTransaction(closeTx); err != nil {
+	peerLog.Infof("Broadcasting force close tx for ChannelPoint(%v): %v",
+		channel.ChannelPoint(), newTxLogClosure(closeTx,
+			channel.StateSnapshot().AssetID))
+	err = p.server.lnwallet.PublishAuditedTransaction(closeTx,
+		channeldb.TxForceClose, *channel.ChannelPoint(),
+		channel.StateSnapshot().AssetID)
+	if err != nil {
+		channel.Stop()
 		return nil, err
 	}
 
 	// Send the closed channel sumary over to the utxoNursery in order to
 	// have its outputs sweeped back into the wallet once they're mature.
+	// The nursery then watches the outputs of the commitment until our
+	// delayed output has been spent.
 	p.server.utxoNursery.incubateOutputs(closeSummary)
+	p.server.utxoNursery.watchCommitSpends(channel,
+		closeSummary.SelfOutpoint)
 
 	return &txid, nil
 }
@@ -660,14 +780,17 @@ func (p *peer) executeForceClose(channel *lnwallet.LightningChannel) (*wire.ShaH
 // executeCooperativeClose executes the initial phase of a user-executed
 // cooperative channel close. The channel state machine is transitioned to the
 // closing phase, then our half of the closing witness is sent over to the
-// remote peer.
-func (p *peer) executeCooperativeClose(channel *lnwallet.LightningChannel) (*wire.ShaHash, error) {
+// remote peer, along with the delivery script overriding our negotiated one,
+// if any.
+func (p *peer) executeCooperativeClose(channel *lnwallet.LightningChannel,
+	deliveryScript []byte) (*wire.ShaHash, error) {
+
 	// Shift the channel state machine into a 'closing' state. This
 	// generates a signature for the closing tx, as well as a txid of the
 	// closing tx itself, allowing us to watch the network to determine
 	// when the remote node broadcasts the fully signed closing
 	// transaction.
-	sig, txid, err := channel.InitCooperativeClose()
+	sig, txid, err := channel.InitCooperativeClose(deliveryScript)
 	if err != nil {
 		return nil, err
 	}
@@ -685,6 +808,7 @@ func (p *peer) executeCooperativeClose(channel *lnwallet.LightningChannel) (*wir
 		return nil, err
 	}
 	closeReq := lnwire.NewCloseRequest(chanPoint, closeSig)
+	closeReq.DeliveryScript = deliveryScript
 	p.queueMsg(closeReq, nil)
 
 	return txid, nil
@@ -705,7 +829,8 @@ func (p *peer) handleLocalClose(req *closeLinkReq) {
 		peerLog.Infof("Force closing ChannelPoint(%v) with txid: %v",
 			req.chanPoint, closingTxid)
 	} else {
-		closingTxid, err = p.executeCooperativeClose(channel)
+		closingTxid, err = p.executeCooperativeClose(channel,
+			req.deliveryScript)
 		peerLog.Infof("Attempting cooperative close of "+
 			"ChannelPoint(%v) with txid: %v", req.chanPoint,
 			closingTxid)
@@ -750,10 +875,18 @@ func (p *peer) handleLocalClose(req *closeLinkReq) {
 			// active indexes, and the database state.
 			peerLog.Infof("ChannelPoint(%v) is now "+
 				"closed at height %v", req.chanPoint, height)
-			if err := wipeChannel(p, channel); err != nil {
+			closeType := channeldb.CooperativeClose
+			if req.forceClose {
+				closeType = channeldb.ForceClose
+			}
+			snapshot := channel.StateSnapshot()
+			err := wipeChannel(p, channel, closeType, closingTxid,
+				uint32(height))
+			if err != nil {
 				req.err <- err
 				return
 			}
+			p.notifyChannelClosed(snapshot, closeType, closingTxid)
 		case <-p.quit:
 			return
 		}
@@ -784,9 +917,17 @@ func (p *peer) handleRemoteClose(req *lnwire.CloseRequest) {
 	// Now that we have their signature for the closure transaction, we
 	// can assemble the final closure transaction, complete with our
 	// signature.
+	// An empty delivery script indicates the remote party hasn't
+	// overridden the one negotiated during the funding workflow.
+	var deliveryScript []byte
+	if len(req.DeliveryScript) != 0 {
+		deliveryScript = req.DeliveryScript
+	}
+
 	sig := req.RequesterCloseSig
 	closeSig := append(sig.Serialize(), byte(txscript.SigHashAll))
-	closeTx, err := channel.CompleteCooperativeClose(closeSig)
+	closeTx, err := channel.CompleteCooperativeClose(closeSig,
+		deliveryScript)
 	if err != nil {
 		peerLog.Errorf("unable to complete cooperative "+
 			"close for ChannelPoint(%v): %v",
@@ -796,10 +937,11 @@ func (p *peer) handleRemoteClose(req *lnwire.CloseRequest) {
 	}
 
 	// Finally, broadcast the closure transaction, to the network.
-	peerLog.Infof("Broadcasting cooperative close tx: %v", newLogClosure(func() string {
-		return spew.Sdump(closeTx)
-	}))
-	if err := p.server.lnwallet.PublishTransaction(closeTx); err != nil {
+	peerLog.Infof("Broadcasting cooperative close tx: %v",
+		newTxLogClosure(closeTx, channel.StateSnapshot().AssetID))
+	err = p.server.lnwallet.PublishAuditedTransaction(closeTx,
+		channeldb.TxCooperativeClose, key, channel.StateSnapshot().AssetID)
+	if err != nil {
 		peerLog.Errorf("channel close tx from "+
 			"ChannelPoint(%v) rejected: %v",
 			chanPoint, err)
@@ -807,22 +949,77 @@ func (p *peer) handleRemoteClose(req *lnwire.CloseRequest) {
 		return
 	}
 
-	// TODO(roasbeef): also wait for confs before removing state
-	peerLog.Infof("ChannelPoint(%v) is now "+
-		"closed", key)
-	wipeChannel(p, channel)
+	// Only remove the channel's state once the closure transaction has
+	// confirmed, so the height it confirmed at can be recorded.
+	closeTxid := closeTx.TxSha()
+	go func() {
+		height, ok := p.closeConfHeight(&closeTxid, 0)
+		if !ok {
+			return
+		}
+
+		peerLog.Infof("ChannelPoint(%v) is now closed at height %v",
+			key, height)
+		snapshot := channel.StateSnapshot()
+		err := wipeChannel(p, channel, channeldb.CooperativeClose,
+			&closeTxid, height)
+		if err != nil {
+			return
+		}
+		p.notifyChannelClosed(snapshot, channeldb.CooperativeClose,
+			&closeTxid)
+	}()
+}
+
+// closeConfHeight returns the height at which the passed closing transaction
+// confirmed. If the passed height is zero, as is the case for a spend
+// detected within the mempool, then we wait for the transaction to obtain a
+// single confirmation. False is returned if the peer is shutting down before
+// then.
+func (p *peer) closeConfHeight(closingTxid *wire.ShaHash,
+	height uint32) (uint32, bool) {
+
+	if height != 0 {
+		return height, true
+	}
+
+	notifier := p.server.chainNotifier
+	confNtfn, err := notifier.RegisterConfirmationsNtfn(closingTxid, 1)
+	if err != nil {
+		peerLog.Errorf("Unable to register for confirmation of "+
+			"closing tx %v: %v", closingTxid, err)
+		return 0, false
+	}
+
+	select {
+	case confHeight, ok := <-confNtfn.Confirmed:
+		// A nil receive indicates that the ChainNotifier is shutting
+		// down.
+		if !ok {
+			return 0, false
+		}
+		return uint32(confHeight), true
+	case <-p.quit:
+		return 0, false
+	}
 }
 
 // wipeChannel removes the passed channel from all indexes associated with the
-// peer, and deletes the channel from the database.
-func wipeChannel(p *peer, channel *lnwallet.LightningChannel) error {
+// peer, and deletes the channel from the database. A summary of the closed
+// channel detailing how it was closed is left behind in its place.
+func wipeChannel(p *peer, channel *lnwallet.LightningChannel,
+	closeType channeldb.ClosureType, closingTxid *wire.ShaHash,
+	closeHeight uint32) error {
+
 	chanID := channel.ChannelPoint()
 
 	delete(p.activeChannels, *chanID)
+	p.signalBackupUpdate()
 
 	// Instruct the Htlc Switch to close this link as the channel is no
 	// longer active.
 	p.server.htlcSwitch.UnregisterLink(p.lightningID, chanID)
+	p.server.chanGraph.RemoveChannel(*chanID)
 	htlcWireLink, ok := p.htlcManagers[*chanID]
 	if !ok {
 		return nil
@@ -831,7 +1028,7 @@ func wipeChannel(p *peer, channel *lnwallet.LightningChannel) error {
 	delete(p.htlcManagers, *chanID)
 	close(htlcWireLink)
 
-	if err := channel.DeleteState(); err != nil {
+	if err := channel.DeleteState(closeType, closingTxid, closeHeight); err != nil {
 		peerLog.Errorf("Unable to delete ChannelPoint(%v) "+
 			"from db %v", chanID, err)
 		return err
@@ -847,25 +1044,37 @@ func wipeChannel(p *peer, channel *lnwallet.LightningChannel) error {
 // fufilled.
 type pendingPayment struct {
 	htlc  *lnwire.HTLCAddRequest
-	index uint32
+	index uint64
 
 	err chan error
 }
 
+// htlcFailError is sent to the requester of a payment once the outgoing HTLC
+// has been failed back by the remote peer, carrying the reason of the
+// failure.
+type htlcFailError struct {
+	reason lnwire.FailReason
+}
+
+// Error returns a human readable description of the failure.
+func (e *htlcFailError) Error() string {
+	return fmt.Sprintf("htlc failed by remote peer: %v", e.reason)
+}
+
 // commitmentState is the volatile+persistent state of an active channel's
 // commitment update state-machine. This struct is used by htlcManager's to
 // save meta-state required for proper functioning.
 type commitmentState struct {
-	// htlcsToSettle is a list of preimages which allow us to settle one or
+	// htlcsToSettle is a list of invoices which allow us to settle one or
 	// many of the pending HTLC's we've received from the upstream peer.
-	htlcsToSettle map[uint32]invoice
+	htlcsToSettle map[uint64]*channeldb.Invoice
 
 	// TODO(roasbeef): use once trickle+batch logic is in
 	pendingBatch []*pendingPayment
 
 	// clearedHTCLs is a map of outgoing HTLC's we've committed to in our
 	// chain which have not yet been settled by the upstream peer.
-	clearedHTCLs map[uint32]*pendingPayment
+	clearedHTCLs map[uint64]*pendingPayment
 
 	// numUnAcked is a counter tracking the number of unacked changes we've
 	// sent. A change is acked once we receive a new update to our local
@@ -884,6 +1093,28 @@ type commitmentState struct {
 
 	channel   *lnwallet.LightningChannel
 	chanPoint *wire.OutPoint
+
+	// assetID is the identifier of the asset the channel is denominated
+	// in. Incoming HTLCs are only settled if the invoice they pay to is
+	// denominated in the same asset.
+	assetID string
+
+	// resolutions is sent upon by HTLCInterceptors in order to settle, or
+	// fail an intercepted HTLC.
+	resolutions chan *htlcResolution
+
+	// settledHashes holds the payment hashes of outgoing HTLCs settled by
+	// the remote party, keyed by their index within our update log, until
+	// each settle has been locked in.
+	settledHashes map[uint64][32]byte
+
+	// failReasons holds the reasons cited by the remote party for failing
+	// outgoing HTLCs, keyed by their index within our update log, until
+	// each failure has been locked in.
+	failReasons map[uint64]lnwire.FailReason
+
+	// quit is closed once the htlcManager for the channel exits.
+	quit chan struct{}
 }
 
 // htlcManager is the primary goroutine which drives a channel's commitment
@@ -905,8 +1136,27 @@ func (p *peer) htlcManager(channel *lnwallet.LightningChannel,
 		channel.ChannelPoint(), chanStats.LocalBalance,
 		chanStats.RemoteBalance, chanStats.NumUpdates)
 
-	// A new session for this active channel has just started, therefore we
-	// need to send our initial revocation window to the remote peer.
+	// Bound the number of revocations the remote peer may have us hold for
+	// this channel.
+	channel.MaxRevocationWindow = cfg.MaxRevocationWindow
+
+	// Both parties must construct the same cooperative close transaction,
+	// so the close policy is expected to match the remote peer's.
+	channel.ClosePolicy = cfg.closePolicy()
+
+	// A new session for this active channel has just started, so we first
+	// let the remote peer know how far our view of the channel has
+	// progressed, allowing it to retransmit any state updates which were
+	// lost along with the prior connection.
+	reestablish, err := channel.ReestablishMsg()
+	if err != nil {
+		peerLog.Errorf("unable to create reestablish msg: %v", err)
+	} else {
+		p.queueMsg(reestablish, nil)
+	}
+
+	// Next, we need to send our initial revocation window to the remote
+	// peer.
 	for i := 0; i < lnwallet.InitialRevocationWindow; i++ {
 		rev, err := channel.ExtendRevocationWindow()
 		if err != nil {
@@ -919,23 +1169,76 @@ func (p *peer) htlcManager(channel *lnwallet.LightningChannel,
 	state := &commitmentState{
 		channel:       channel,
 		chanPoint:     channel.ChannelPoint(),
-		clearedHTCLs:  make(map[uint32]*pendingPayment),
-		htlcsToSettle: make(map[uint32]invoice),
+		clearedHTCLs:  make(map[uint64]*pendingPayment),
+		htlcsToSettle: make(map[uint64]*channeldb.Invoice),
 		switchChan:    htlcPlex,
+		assetID:       chanStats.AssetID,
+		resolutions:   make(chan *htlcResolution),
+		settledHashes: make(map[uint64][32]byte),
+		failReasons:   make(map[uint64]lnwire.FailReason),
+		quit:          make(chan struct{}),
 	}
+	defer close(state.quit)
+
+	// The close signal is only handled once, after which we keep running
+	// until the channel has been wiped, closing the upstream link.
+	closeSignal := channel.UnilateralCloseSignal
 
 	batchTimer := time.Tick(10 * time.Millisecond)
 out:
 	for {
 		select {
-		case <-channel.UnilateralCloseSignal:
+		case <-closeSignal:
+			closeSignal = nil
+
 			// TODO(roasbeef): eliminate false positive via local close
 			peerLog.Warnf("Remote peer has closed ChannelPoint(%v) on-chain",
 				state.chanPoint)
-			if err := wipeChannel(p, channel); err != nil {
-				peerLog.Errorf("Unable to wipe channel %v", err)
+			if channel.FundingSpentOffline {
+				peerLog.Warnf("ChannelPoint(%v) was closed while "+
+					"we were offline", state.chanPoint)
 			}
-			break out
+			// If the remote party broadcast one of its revoked
+			// states, then the closure is recorded as a breach.
+			closeType := channeldb.RemoteForceClose
+			closingTxid, isBreach, err := channel.UnilateralCloseSummary()
+			if err != nil {
+				peerLog.Errorf("Unable to determine closing tx of "+
+					"ChannelPoint(%v): %v", state.chanPoint, err)
+			} else if isBreach {
+				peerLog.Warnf("Remote peer has broadcast revoked "+
+					"state %v of ChannelPoint(%v)", closingTxid,
+					state.chanPoint)
+				closeType = channeldb.BreachClose
+			}
+
+			// No new HTLCs may be routed over the channel while we
+			// wait for the closing transaction to confirm. If the
+			// transaction couldn't be located, then the height it
+			// confirmed at is unknown.
+			p.server.htlcSwitch.UnregisterLink(p.lightningID,
+				state.chanPoint)
+			closeHeight := channel.CloseHeight()
+			go func() {
+				if closingTxid != nil {
+					height, ok := p.closeConfHeight(closingTxid,
+						closeHeight)
+					if !ok {
+						return
+					}
+					closeHeight = height
+				}
+
+				snapshot := channel.StateSnapshot()
+				err := wipeChannel(p, channel, closeType,
+					closingTxid, closeHeight)
+				if err != nil {
+					peerLog.Errorf("Unable to wipe channel %v", err)
+					return
+				}
+				p.notifyChannelClosed(snapshot, closeType,
+					closingTxid)
+			}()
 		case <-channel.ForceCloseSignal:
 			peerLog.Warnf("ChannelPoint(%v) has been force "+
 				"closed, disconnecting from peerID(%x)",
@@ -994,11 +1297,21 @@ out:
 			}
 
 			p.handleUpstreamMsg(state, msg)
+		case res := <-state.resolutions:
+			p.handleHTLCResolution(state, res)
 		case <-p.quit:
 			break out
 		}
 	}
 
+	// Once force closed, the utxoNursery watches the outputs of the
+	// channel's commitment, stopping the channel once done.
+	select {
+	case <-channel.ForceCloseSignal:
+	default:
+		channel.Stop()
+	}
+
 	p.wg.Done()
 	peerLog.Tracef("htlcManager for peer %v done", p)
 }
@@ -1014,7 +1327,28 @@ func (p *peer) handleDownStreamPkt(state *commitmentState, pkt *htlcPacket) {
 		// downstream channel, so we add the new HTLC
 		// to our local log, then update the commitment
 		// chains.
-		index := state.channel.AddHTLC(htlc)
+		//
+		// Before doing so, ensure the HTLC can actually be covered by
+		// our balance, otherwise it would only fail once we attempt to
+		// sign the next commitment.
+		amt := btcutil.Amount(htlc.Amount)
+		if available := state.channel.AvailableBalance(); amt > available {
+			peerLog.Errorf("unable to send htlc of %v over "+
+				"ChannelPoint(%v), only %v available", amt,
+				state.chanPoint, available)
+			p.server.htlcSwitch.UpdateLink(state.chanPoint, amt)
+			pkt.err <- fmt.Errorf("insufficient available balance")
+			return
+		}
+
+		index, err := state.channel.AddHTLC(htlc)
+		if err != nil {
+			peerLog.Errorf("unable to add htlc to ChannelPoint(%v): "+
+				"%v", state.chanPoint, err)
+			p.server.htlcSwitch.UpdateLink(state.chanPoint, amt)
+			pkt.err <- err
+			return
+		}
 		p.queueMsg(htlc, nil)
 
 		state.pendingBatch = append(state.pendingBatch, &pendingPayment{
@@ -1052,50 +1386,95 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 		// We just received an add request from an upstream peer, so we
 		// add it to our state machine, then add the HTLC to our
 		// "settle" list in the event that we know the pre-image
-		index := state.channel.ReceiveHTLC(htlcPkt)
+		index, err := state.channel.ReceiveHTLC(htlcPkt)
+		if err == lnwallet.ErrReplayedHTLC {
+			peerLog.Debugf("ignoring retransmitted htlc %v",
+				index)
+			return
+		} else if err != nil {
+			// Reusing the payment hash of an active HTLC is a
+			// protocol violation, so we drop the link.
+			peerLog.Errorf("unable to accept htlc: %v", err)
+			p.Disconnect()
+			return
+		}
 
+		// If we have an invoice paying to this HTLC, then we'll check
+		// it against the invoice's terms once the HTLC has been fully
+		// locked in.
+		// TODO(roasbeef): onion layer strip should also be before
+		// invoice lookup
 		rHash := htlcPkt.RedemptionHashes[0]
-		if invoice, found := p.server.invoices.lookupInvoice(rHash); found {
-			// TODO(roasbeef): check value
-			//  * onion layer strip should also be before invoice lookup
-			//  * also can immediately send the settle msg
-			invCopy := *invoice
-			invCopy.value = btcutil.Amount(htlcPkt.Amount)
-			state.htlcsToSettle[index] = invCopy
+		invoice, err := p.server.invoices.lookupInvoice(rHash)
+		if err == nil {
+			state.htlcsToSettle[index] = invoice
 		}
 	case *lnwire.HTLCSettleRequest:
 		// TODO(roasbeef): this assumes no "multi-sig"
 		pre := htlcPkt.RedemptionProofs[0]
-		idx := uint32(htlcPkt.HTLCKey)
-		if err := state.channel.ReceiveHTLCSettle(pre, idx); err != nil {
+		idx := uint64(htlcPkt.HTLCKey)
+		err := state.channel.ReceiveHTLCSettle(pre, idx)
+		if err == lnwallet.ErrReplayedHTLC {
+			peerLog.Debugf("ignoring retransmitted settle for htlc "+
+				"%v", idx)
+			return
+		} else if err != nil {
 			// TODO(roasbeef): broadcast on-chain
 			peerLog.Errorf("settle for outgoing HTLC rejected: %v", err)
 			p.Disconnect()
 			return
 		}
+		state.settledHashes[idx] = fastsha256.Sum256(pre[:])
+	case *lnwire.HTLCTimeoutRequest:
+		idx := uint64(htlcPkt.HTLCKey)
+		err := state.channel.ReceiveHTLCTimeout(idx)
+		if err == lnwallet.ErrReplayedHTLC {
+			peerLog.Debugf("ignoring retransmitted timeout for htlc "+
+				"%v", idx)
+			return
+		} else if err != nil {
+			peerLog.Errorf("timeout for outgoing HTLC rejected: %v",
+				err)
+			p.Disconnect()
+			return
+		}
+		state.failReasons[idx] = htlcPkt.Reason
 	case *lnwire.CommitSignature:
+		defer p.updateLimiter.commitProcessed()
+
 		// We just received a new update to our local commitment chain,
 		// validate this new commitment, closing the link if invalid.
-		// TODO(roasbeef): use uint64 for indexes?
-		logIndex := uint32(htlcPkt.LogIndex)
+		logIndex := htlcPkt.LogIndex
 		sig := htlcPkt.CommitSig.Serialize()
-		if err := state.channel.ReceiveNewCommitment(sig, logIndex); err != nil {
-			peerLog.Errorf("unable to accept new commitment: %v", err)
-			p.Disconnect()
-			return
-		}
 
-		if state.numUnAcked > 0 {
-			state.numUnAcked -= 1
-			// TODO(roasbeef): only start if numUnacked == 0?
-			state.logCommitTimer = time.Tick(300 * time.Millisecond)
+		// If we'll be responding with a new commitment of our own, then
+		// both commitments may be constructed at once if enabled.
+		if state.numUnAcked == 0 && cfg.ParallelCommitments {
+			if err := p.receiveAndUpdateCommitTx(state, sig, logIndex); err != nil {
+				peerLog.Errorf("unable to accept new commitment: %v",
+					err)
+				p.Disconnect()
+				return
+			}
 		} else {
-			if _, err := p.updateCommitTx(state); err != nil {
-				peerLog.Errorf("unable to update "+
-					"commitment: %v", err)
+			if err := state.channel.ReceiveNewCommitment(sig, logIndex); err != nil {
+				peerLog.Errorf("unable to accept new commitment: %v", err)
 				p.Disconnect()
 				return
 			}
+
+			if state.numUnAcked > 0 {
+				state.numUnAcked -= 1
+				// TODO(roasbeef): only start if numUnacked == 0?
+				state.logCommitTimer = time.Tick(300 * time.Millisecond)
+			} else {
+				if _, err := p.updateCommitTx(state); err != nil {
+					peerLog.Errorf("unable to update "+
+						"commitment: %v", err)
+					p.Disconnect()
+					return
+				}
+			}
 		}
 
 		// Finally, since we just accepted a new state, send the remote
@@ -1105,7 +1484,52 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 			peerLog.Errorf("unable to revoke current commitment: %v", err)
 			return
 		}
+		if err := state.channel.TrackRetransmission(nextRevocation); err != nil {
+			peerLog.Errorf("unable to track revocation: %v", err)
+		}
+
+		// If we're replicating our channel states to a standby, then
+		// the revocation is withheld until the standby holds our new
+		// commitment, lest a failover leave it with the revoked one.
+		// The revocation is retransmitted once the peer reconnects.
+		if p.server.replicator != nil {
+			err := p.server.replicator.Sync(*state.chanPoint)
+			if err != nil {
+				peerLog.Errorf("unable to sync ChannelPoint(%v) "+
+					"with standby: %v", state.chanPoint, err)
+				p.Disconnect()
+				return
+			}
+		}
+
 		p.queueMsg(nextRevocation, nil)
+	case *lnwire.CommitReestablish:
+		// The remote peer has reported how far its view of the channel
+		// progressed, so retransmit any of our signatures and
+		// revocations which it never received.
+		resend, err := state.channel.ProcessReestablish(htlcPkt)
+		if err != nil {
+			peerLog.Errorf("unable to reestablish ChannelPoint(%v): %v",
+				state.chanPoint, err)
+			p.Disconnect()
+			return
+		}
+
+		// Any revocation withheld while the standby was unreachable
+		// may only be retransmitted once the standby has caught up.
+		if p.server.replicator != nil && len(resend) != 0 {
+			err := p.server.replicator.Sync(*state.chanPoint)
+			if err != nil {
+				peerLog.Errorf("unable to sync ChannelPoint(%v) "+
+					"with standby: %v", state.chanPoint, err)
+				p.Disconnect()
+				return
+			}
+		}
+
+		for _, msg := range resend {
+			p.queueMsg(msg, nil)
+		}
 	case *lnwire.CommitRevocation:
 		// We've received a revocation from the remote chain, if valid,
 		// this moves the remote chain forward, and expands our
@@ -1117,6 +1541,21 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 			return
 		}
 
+		// If we're backing up our channel states to a set of
+		// watchtowers, then hand off the newly revoked state so the
+		// towers can punish a broadcast of it while we're offline.
+		revoked := state.channel.LastRevokedCommitment()
+		if p.server.towerClient != nil && revoked != nil {
+			commitKey := state.channel.LocalCommitKey()
+			csvDelay := state.channel.RemoteCsvDelay()
+			err := p.server.towerClient.BackupState(commitKey,
+				csvDelay, revoked)
+			if err != nil {
+				peerLog.Errorf("unable to back up revoked "+
+					"state: %v", err)
+			}
+		}
+
 		// We perform the HTLC forwarding to the switch in a distinct
 		// goroutine in order not to block the post-processing of
 		// HTLC's that are eligble for forwarding.
@@ -1126,7 +1565,8 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 			for _, htlc := range htlcsToForward {
 				// Send this fully activated HTLC to the htlc
 				// switch to continue the chained clear/settle.
-				state.switchChan <- p.logEntryToHtlcPkt(htlc)
+				state.switchChan <- p.logEntryToHtlcPkt(htlc,
+					state.chanPoint)
 			}
 
 		}()
@@ -1139,27 +1579,117 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 		numSettled := 0
 		for _, htlc := range htlcsToForward {
 			if p, ok := state.clearedHTCLs[htlc.ParentIndex]; ok {
-				p.err <- nil
+				if htlc.EntryType == lnwallet.Timeout {
+					reason := state.failReasons[htlc.ParentIndex]
+					p.err <- &htlcFailError{reason}
+				} else {
+					p.err <- nil
+				}
 				delete(state.clearedHTCLs, htlc.ParentIndex)
 			}
 
+			// If one of our outgoing HTLCs has been failed back,
+			// then its value is once again available to us.
+			if htlc.EntryType == lnwallet.Timeout {
+				delete(state.failReasons, htlc.ParentIndex)
+				bandwidthUpdate += htlc.Amount
+			}
+
+			// Once the settle of one of our outgoing HTLCs is
+			// locked in, the payment is complete.
+			if rHash, ok := state.settledHashes[htlc.ParentIndex]; ok &&
+				htlc.EntryType == lnwallet.Settle {
+
+				delete(state.settledHashes, htlc.ParentIndex)
+				p.notifyHTLCSettled(state, false, rHash,
+					htlc.Amount)
+			}
+
 			// TODO(roasbeef): rework log entries to a shared
 			// interface.
 			if htlc.EntryType != lnwallet.Add {
 				continue
 			}
 
-			// If we can't immediately settle this HTLC, then we
-			// can halt processing here.
+			// If we don't have an invoice for this HTLC, then it
+			// may be a spontaneous payment carrying its own
+			// preimage. Otherwise, we hand it off to the
+			// registered interceptor, if any, which may resolve
+			// it at a later point.
 			invoice, ok := state.htlcsToSettle[htlc.Index]
 			if !ok {
+				preimage, err := decodeKeysendRecord(htlc.Payload,
+					p.server.identityPriv, htlc.RHash)
+				switch {
+				case err == errNoKeysendRecord:
+					// Probes are failed back as paying
+					// to an unknown hash, HTLCs carrying
+					// a forwarding record are sent
+					// onwards, all others are handed to
+					// the interceptor.
+					record, err := decodeForwardRecord(htlc.Payload)
+					switch {
+					case isProbeRecord(htlc.Payload):
+						peerLog.Debugf("Failing probe "+
+							"htlc %v", htlc.Index)
+						probe := newInterceptedHTLC(state, htlc)
+						reason := lnwire.FailUnknownPaymentHash
+						go probe.failWithReason(reason)
+					case err == errNoForwardRecord:
+						p.interceptHTLC(state, htlc)
+					case err != nil:
+						peerLog.Errorf("invalid forwarding "+
+							"htlc %v: %v", htlc.Index, err)
+						go newInterceptedHTLC(state, htlc).Fail()
+					default:
+						p.forwardHTLC(state, htlc, record)
+					}
+					continue
+				case err != nil:
+					peerLog.Errorf("invalid keysend htlc "+
+						"%v: %v", htlc.Index, err)
+					continue
+				}
+
+				peerLog.Infof("Settling keysend htlc %v of %v",
+					htlc.Index, htlc.Amount)
+				invoice = &channeldb.Invoice{
+					Terms: channeldb.ContractTerm{
+						PaymentPreimage: preimage,
+						Value:           htlc.Amount,
+						AssetID:         state.assetID,
+					},
+				}
+			}
+
+			// If the HTLC is a single part of a multi-part
+			// payment, then it's held until all parts of the
+			// payment have arrived.
+			record, err := decodeMPPRecord(htlc.Payload)
+			if ok && err == nil {
+				delete(state.htlcsToSettle, htlc.Index)
+				p.addPaymentPart(state, htlc, invoice, record)
+				continue
+			}
+
+			// Before revealing the preimage, ensure the HTLC pays
+			// the amount of the asset the invoice was created
+			// for. Otherwise, an exotic asset invoice could be
+			// settled by an HTLC of a cheaper asset.
+			err = p.server.invoices.checkInvoiceTerms(invoice,
+				state.assetID, htlc.Amount)
+			if err != nil {
+				peerLog.Errorf("refusing to settle htlc %v: %v",
+					htlc.Index, err)
+				delete(state.htlcsToSettle, htlc.Index)
 				continue
 			}
+			preimage := invoice.Terms.PaymentPreimage
 
 			// Otherwise, we settle this HTLC within our local
 			// state update log, then send the update entry to the
 			// remote party.
-			logIndex, err := state.channel.SettleHTLC(invoice.paymentPreimage)
+			logIndex, err := state.channel.SettleHTLC(preimage)
 			if err != nil {
 				peerLog.Errorf("unable to settle htlc: %v", err)
 				p.Disconnect()
@@ -1169,18 +1699,26 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 			settleMsg := &lnwire.HTLCSettleRequest{
 				ChannelPoint:     state.chanPoint,
 				HTLCKey:          lnwire.HTLCKey(logIndex),
-				RedemptionProofs: [][32]byte{invoice.paymentPreimage},
+				RedemptionProofs: [][32]byte{preimage},
 			}
 			p.queueMsg(settleMsg, nil)
 			delete(state.htlcsToSettle, htlc.Index)
+			p.notifyHTLCSettled(state, true, htlc.RHash, htlc.Amount)
 
-			bandwidthUpdate += invoice.value
+			// Spontaneous payments have no invoice to be marked
+			// as settled.
+			rHash := wire.ShaHash(htlc.RHash)
+			if ok {
+				err := p.server.invoices.settleInvoice(rHash)
+				if err != nil {
+					peerLog.Errorf("unable to settle "+
+						"invoice: %v", err)
+				}
+			}
 
-			numSettled++
-		}
+			bandwidthUpdate += htlc.Amount
 
-		if numSettled == 0 {
-			return
+			numSettled++
 		}
 
 		// Send an update to the htlc switch of our newly available
@@ -1191,6 +1729,10 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 				bandwidthUpdate)
 		}
 
+		if numSettled == 0 {
+			return
+		}
+
 		// With all the settle updates added to the local and remote
 		// HTLC logs, initiate a state transition by updating the
 		// remote commitment chain.
@@ -1205,6 +1747,135 @@ func (p *peer) handleUpstreamMsg(state *commitmentState, msg lnwire.Message) {
 	}
 }
 
+// interceptHTLC hands a fully locked-in incoming HTLC to the registered
+// HTLCInterceptor. If no interceptor is registered, then the HTLC is left
+// untouched.
+func (p *peer) interceptHTLC(state *commitmentState,
+	htlc *lnwallet.PaymentDescriptor) {
+
+	interceptor := p.server.htlcInterceptor()
+	if interceptor == nil {
+		return
+	}
+
+	interceptor.InterceptHTLC(newInterceptedHTLC(state, htlc))
+}
+
+// newInterceptedHTLC returns a handle to a locked-in incoming HTLC, which
+// allows the HTLC to be resolved asynchronously by the htlcManager of its
+// channel.
+func newInterceptedHTLC(state *commitmentState,
+	htlc *lnwallet.PaymentDescriptor) *InterceptedHTLC {
+
+	return &InterceptedHTLC{
+		ChanPoint:   *state.chanPoint,
+		Index:       htlc.Index,
+		PaymentHash: htlc.RHash,
+		AssetID:     state.assetID,
+		Amount:      htlc.Amount,
+		Expiry:      htlc.Timeout,
+		Payload:     htlc.Payload,
+		resolutions: state.resolutions,
+		quit:        state.quit,
+	}
+}
+
+// addPaymentPart holds a locked-in incoming HTLC which is a single part of a
+// multi-part payment to one of our invoices. Once all parts of the payment
+// have arrived, possibly over several channels, every part is settled. If the
+// part is inconsistent with the invoice or the other parts, then it's failed
+// back.
+func (p *peer) addPaymentPart(state *commitmentState,
+	htlc *lnwallet.PaymentDescriptor, invoice *channeldb.Invoice,
+	record *mppRecord) {
+
+	part := newInterceptedHTLC(state, htlc)
+
+	// The resolution of each part is handled by the htlcManager of its
+	// channel, which is the caller for at least one of the parts, so all
+	// resolutions are dispatched asynchronously.
+	var parts []*InterceptedHTLC
+	err := p.server.invoices.checkInvoiceTerms(invoice, state.assetID,
+		record.totalAmt)
+	if err == nil {
+		parts, err = p.server.payments.addPart(record, part)
+	}
+	if err != nil {
+		peerLog.Errorf("rejecting part of multi-part payment: %v", err)
+		go part.Fail()
+		return
+	}
+
+	if parts == nil {
+		return
+	}
+
+	preimage := invoice.Terms.PaymentPreimage
+	go func() {
+		for _, part := range parts {
+			if err := part.Settle(preimage); err != nil {
+				peerLog.Errorf("unable to settle part of "+
+					"multi-part payment: %v", err)
+			}
+		}
+
+		rHash := wire.ShaHash(part.PaymentHash)
+		if err := p.server.invoices.settleInvoice(rHash); err != nil {
+			peerLog.Errorf("unable to settle invoice: %v", err)
+		}
+	}()
+}
+
+// handleHTLCResolution settles, or fails back an intercepted HTLC according
+// to the resolution decided by the HTLCInterceptor, then initiates a state
+// transition to remove the HTLC from both commitment chains.
+func (p *peer) handleHTLCResolution(state *commitmentState,
+	res *htlcResolution) {
+
+	var msg lnwire.Message
+	if res.preimage != nil {
+		logIndex, err := state.channel.SettleHTLC(*res.preimage)
+		if err != nil {
+			peerLog.Errorf("unable to settle intercepted htlc %v: %v",
+				res.index, err)
+			return
+		}
+
+		msg = &lnwire.HTLCSettleRequest{
+			ChannelPoint:     state.chanPoint,
+			HTLCKey:          lnwire.HTLCKey(logIndex),
+			RedemptionProofs: [][32]byte{*res.preimage},
+		}
+		p.notifyHTLCSettled(state, true,
+			fastsha256.Sum256(res.preimage[:]), res.amt)
+	} else {
+		if err := state.channel.TimeoutHTLC(res.index); err != nil {
+			peerLog.Errorf("unable to fail intercepted htlc %v: %v",
+				res.index, err)
+			return
+		}
+
+		msg = &lnwire.HTLCTimeoutRequest{
+			ChannelPoint: state.chanPoint,
+			HTLCKey:      lnwire.HTLCKey(res.index),
+			Reason:       res.reason,
+		}
+	}
+	p.queueMsg(msg, nil)
+
+	if res.preimage != nil {
+		p.server.htlcSwitch.UpdateLink(state.chanPoint, res.amt)
+	}
+
+	if sent, err := p.updateCommitTx(state); err != nil {
+		peerLog.Errorf("unable to update commitment: %v", err)
+		p.Disconnect()
+		return
+	} else if sent {
+		state.numUnAcked += 1
+	}
+}
+
 // updateCommitTx signs, then sends an update to the remote peer adding a new
 // commitment to their commitment chain which includes all the latest updates
 // we've received+processed up to this point.
@@ -1218,15 +1889,50 @@ func (p *peer) updateCommitTx(state *commitmentState) (bool, error) {
 		return false, err
 	}
 
+	if err := p.sendCommitSig(state, sigTheirs, logIndexTheirs); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// receiveAndUpdateCommitTx accepts a new commitment signed by the remote peer,
+// while concurrently signing a new commitment for the remote peer in response,
+// which is then sent to them along with any pending updates.
+func (p *peer) receiveAndUpdateCommitTx(state *commitmentState, sig []byte,
+	logIndex uint64) error {
+
+	sigTheirs, logIndexTheirs, err := state.channel.ReceiveAndSignCommitment(
+		sig, logIndex)
+	if err != nil {
+		return err
+	}
+	if sigTheirs == nil {
+		peerLog.Tracef("revocation window exhausted, unable to send %v",
+			len(state.pendingBatch))
+		return nil
+	}
+
+	return p.sendCommitSig(state, sigTheirs, logIndexTheirs)
+}
+
+// sendCommitSig sends the remote peer our signature for their new commitment,
+// moving all pending updates included within it to the set of cleared HTLC's.
+func (p *peer) sendCommitSig(state *commitmentState, sigTheirs []byte,
+	logIndexTheirs uint64) error {
+
 	parsedSig, err := btcec.ParseSignature(sigTheirs, btcec.S256())
 	if err != nil {
-		return false, fmt.Errorf("unable to parse sig: %v", err)
+		return fmt.Errorf("unable to parse sig: %v", err)
 	}
 
 	commitSig := &lnwire.CommitSignature{
 		ChannelPoint: state.chanPoint,
 		CommitSig:    parsedSig,
-		LogIndex:     uint64(logIndexTheirs),
+		LogIndex:     logIndexTheirs,
+	}
+	if err := state.channel.TrackRetransmission(commitSig); err != nil {
+		return err
 	}
 	p.queueMsg(commitSig, nil)
 
@@ -1240,15 +1946,20 @@ func (p *peer) updateCommitTx(state *commitmentState) (bool, error) {
 	state.logCommitTimer = nil
 	state.pendingBatch = nil
 
-	return true, nil
+	return nil
 }
 
 // logEntryToHtlcPkt converts a particular Lightning Commitment Protocol (LCP)
 // log entry the corresponding htlcPacket with src/dest set along with the
 // proper wire message. This helepr method is provided in order to aide an
-// htlcManager in forwarding packets to the htlcSwitch.
-func (p *peer) logEntryToHtlcPkt(pd *lnwallet.PaymentDescriptor) *htlcPacket {
-	pkt := &htlcPacket{}
+// htlcManager in forwarding packets to the htlcSwitch. The passed chanPoint is
+// the channel the log entry was received on.
+func (p *peer) logEntryToHtlcPkt(pd *lnwallet.PaymentDescriptor,
+	chanPoint *wire.OutPoint) *htlcPacket {
+
+	pkt := &htlcPacket{
+		incomingChan: chanPoint,
+	}
 
 	// TODO(roasbeef): alter after switch to log entry interface
 	var msg lnwire.Message
@@ -1264,6 +1975,10 @@ func (p *peer) logEntryToHtlcPkt(pd *lnwallet.PaymentDescriptor) *htlcPacket {
 		msg = &lnwire.HTLCSettleRequest{
 			HTLCKey: lnwire.HTLCKey(pd.ParentIndex),
 		}
+	case lnwallet.Timeout:
+		msg = &lnwire.HTLCTimeoutRequest{
+			HTLCKey: lnwire.HTLCKey(pd.ParentIndex),
+		}
 	}
 
 	// TODO(roasbeef): set dest via onion blob or state
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// Peer backups offer a measure of protection against the loss of local
+// channel state. When enabled, each peer is sent an encrypted summary of the
+// channels we have open with it, which it stores on our behalf. Having lost
+// our state, we request the backup back upon reconnecting, and ask the peer
+// to force close each channel listed within it. The peer's commitment
+// transaction pays our balance directly to our wallet, so the colored funds
+// are settled without us having to know the latest channel state. The backup
+// itself can't be used to update the channels, as it lacks the revocation
+// state needed to do so safely.
+
+const (
+	// peerBackupVersion is the version of the serialization of the
+	// channel backups encrypted within a peer backup.
+	peerBackupVersion = 0
+
+	// peerBackupRefreshInterval is the interval at which the backup held
+	// by a peer is refreshed, capturing balance updates since it was last
+	// sent.
+	peerBackupRefreshInterval = 10 * time.Minute
+
+	// maxChannelBackups is the maximum number of channels a single peer
+	// backup may list.
+	maxChannelBackups = 256
+)
+
+// errChannelDataLoss is the ErrorID of the ErrorGeneric message sent to a
+// peer for each channel listed within a returned backup which we no longer
+// have any state for, requesting the peer force close the channel.
+const errChannelDataLoss uint16 = 2
+
+// channelBackup summarizes our state of a single channel within a peer
+// backup.
+type channelBackup struct {
+	ChanPoint wire.OutPoint
+
+	AssetID string
+
+	// Capacity and both balances are denominated in the channel's asset.
+	Capacity      btcutil.Amount
+	LocalBalance  btcutil.Amount
+	RemoteBalance btcutil.Amount
+
+	NumUpdates uint64
+}
+
+// serializeChannelBackups writes the passed channel backups to w.
+func serializeChannelBackups(w io.Writer, backups []*channelBackup) error {
+	if len(backups) > maxChannelBackups {
+		return fmt.Errorf("%v channels exceed max of %v per backup",
+			len(backups), maxChannelBackups)
+	}
+
+	if _, err := w.Write([]byte{peerBackupVersion}); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, uint16(len(backups))); err != nil {
+		return err
+	}
+
+	for _, backup := range backups {
+		if _, err := w.Write(backup.ChanPoint.Hash[:]); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.BigEndian, backup.ChanPoint.Index); err != nil {
+			return err
+		}
+
+		if err := wire.WriteVarString(w, 0, backup.AssetID); err != nil {
+			return err
+		}
+
+		fields := []uint64{
+			uint64(backup.Capacity),
+			uint64(backup.LocalBalance),
+			uint64(backup.RemoteBalance),
+			backup.NumUpdates,
+		}
+		for _, field := range fields {
+			if err := binary.Write(w, binary.BigEndian, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deserializeChannelBackups reads channel backups written by
+// serializeChannelBackups from r.
+func deserializeChannelBackups(r io.Reader) ([]*channelBackup, error) {
+	var version [1]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return nil, err
+	}
+	if version[0] != peerBackupVersion {
+		return nil, fmt.Errorf("unknown peer backup version %v",
+			version[0])
+	}
+
+	var numBackups uint16
+	if err := binary.Read(r, binary.BigEndian, &numBackups); err != nil {
+		return nil, err
+	}
+	if numBackups > maxChannelBackups {
+		return nil, fmt.Errorf("%v channels exceed max of %v per "+
+			"backup", numBackups, maxChannelBackups)
+	}
+
+	backups := make([]*channelBackup, numBackups)
+	for i := range backups {
+		backup := &channelBackup{}
+		if _, err := io.ReadFull(r, backup.ChanPoint.Hash[:]); err != nil {
+			return nil, err
+		}
+		if err := binary.Read(r, binary.BigEndian, &backup.ChanPoint.Index); err != nil {
+			return nil, err
+		}
+
+		assetID, err := wire.ReadVarString(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		backup.AssetID = assetID
+
+		var fields [4]uint64
+		if err := binary.Read(r, binary.BigEndian, &fields); err != nil {
+			return nil, err
+		}
+		backup.Capacity = btcutil.Amount(fields[0])
+		backup.LocalBalance = btcutil.Amount(fields[1])
+		backup.RemoteBalance = btcutil.Amount(fields[2])
+		backup.NumUpdates = fields[3]
+
+		backups[i] = backup
+	}
+
+	return backups, nil
+}
+
+// peerBackupKey derives the key used to encrypt our peer backups from our
+// identity key, so the backups can be decrypted after losing all state but
+// the identity key itself.
+func peerBackupKey(identityPriv *btcec.PrivateKey) *[32]byte {
+	key := fastsha256.Sum256(append(identityPriv.Serialize(),
+		[]byte("peerbackup")...))
+	return &key
+}
+
+// encryptPeerBackup serializes, then encrypts the passed channel backups
+// under the passed key. The random nonce used is prepended to the result.
+func encryptPeerBackup(key *[32]byte, backups []*channelBackup) ([]byte, error) {
+	var b bytes.Buffer
+	if err := serializeChannelBackups(&b, backups); err != nil {
+		return nil, err
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, err
+	}
+
+	return secretbox.Seal(nonce[:], b.Bytes(), &nonce, key), nil
+}
+
+// decryptPeerBackup decrypts, then deserializes a peer backup created by
+// encryptPeerBackup.
+func decryptPeerBackup(key *[32]byte, blob []byte) ([]*channelBackup, error) {
+	if len(blob) < 24 {
+		return nil, fmt.Errorf("peer backup too short")
+	}
+
+	var nonce [24]byte
+	copy(nonce[:], blob[:24])
+
+	plaintext, ok := secretbox.Open(nil, blob[24:], &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("unable to decrypt peer backup")
+	}
+
+	return deserializeChannelBackups(bytes.NewReader(plaintext))
+}
+
+// signalBackupUpdate signals the channelManager to send the peer a fresh
+// backup of our channels. Signals sent while a previous one is still pending
+// are coalesced.
+func (p *peer) signalBackupUpdate() {
+	if !cfg.PeerBackup {
+		return
+	}
+
+	select {
+	case p.backupUpdates <- struct{}{}:
+	default:
+	}
+}
+
+// sendPeerBackup sends the peer an encrypted backup of our state of the
+// channels we have open with it, replacing the backup it currently holds.
+//
+// NOTE: This method MUST only be called from the channelManager goroutine.
+func (p *peer) sendPeerBackup() {
+	backups := make([]*channelBackup, 0, len(p.activeChannels))
+	for chanPoint, channel := range p.activeChannels {
+		snapshot := channel.StateSnapshot()
+		backups = append(backups, &channelBackup{
+			ChanPoint:     chanPoint,
+			AssetID:       snapshot.AssetID,
+			Capacity:      snapshot.Capacity,
+			LocalBalance:  snapshot.LocalBalance,
+			RemoteBalance: snapshot.RemoteBalance,
+			NumUpdates:    snapshot.NumUpdates,
+		})
+	}
+
+	key := peerBackupKey(p.server.identityPriv)
+	blob, err := encryptPeerBackup(key, backups)
+	if err != nil {
+		peerLog.Errorf("unable to create backup for peerID(%v): %v",
+			p.id, err)
+		return
+	}
+
+	peerLog.Debugf("Sending backup of %v channels to peerID(%v)",
+		len(backups), p.id)
+
+	p.queueMsg(lnwire.NewPeerBackup(blob), nil)
+}
+
+// handleReturnedBackup decrypts a backup returned by the peer, and requests
+// the peer force close each channel listed within it which we no longer have
+// any state for.
+//
+// NOTE: This method MUST only be called from the channelManager goroutine.
+func (p *peer) handleReturnedBackup(blob []byte) {
+	key := peerBackupKey(p.server.identityPriv)
+	backups, err := decryptPeerBackup(key, blob)
+	if err != nil {
+		peerLog.Errorf("unable to read backup returned by peerID(%v): "+
+			"%v", p.id, err)
+		return
+	}
+
+	for _, backup := range backups {
+		if _, ok := p.activeChannels[backup.ChanPoint]; ok {
+			continue
+		}
+
+		peerLog.Warnf("Lost state of ChannelPoint(%v) with peerID(%v), "+
+			"last backed up with local_balance=%v, remote_balance=%v "+
+			"of asset %v after %v updates, requesting force close",
+			backup.ChanPoint, p.id, backup.LocalBalance,
+			backup.RemoteBalance, backup.AssetID, backup.NumUpdates)
+
+		chanPoint := backup.ChanPoint
+		errMsg := lnwire.NewErrorGeneric()
+		errMsg.ChannelPoint = &chanPoint
+		errMsg.ErrorID = errChannelDataLoss
+		errMsg.Problem = fmt.Sprintf("state of ChannelPoint(%v) lost, "+
+			"force close requested", chanPoint)
+		p.queueMsg(errMsg, nil)
+	}
+}
+
+// handleDataLoss force closes the target channel in response to the peer
+// reporting it has lost its state of the channel. As the peer can no longer
+// update the channel safely, closing it is the only way to settle its
+// balance. A peer may of course claim data loss falsely, forcing the channel
+// closed at the cost of its own funds being time locked, which is why the
+// request is only honored if peer backups are enabled.
+//
+// NOTE: This method MUST only be called from the channelManager goroutine.
+func (p *peer) handleDataLoss(chanPoint *wire.OutPoint) {
+	if _, ok := p.activeChannels[*chanPoint]; !ok {
+		peerLog.Warnf("peerID(%v) reported data loss for unknown "+
+			"ChannelPoint(%v)", p.id, chanPoint)
+		return
+	}
+
+	peerLog.Warnf("peerID(%v) lost state of ChannelPoint(%v), force "+
+		"closing", p.id, chanPoint)
+
+	// The switch hands the request back to our channelManager, so the
+	// close must be requested from a separate goroutine.
+	go func() {
+		_, errChan := p.server.htlcSwitch.CloseLink(chanPoint, true, nil)
+		if err := <-errChan; err != nil {
+			peerLog.Errorf("unable to force close ChannelPoint(%v): "+
+				"%v", chanPoint, err)
+		}
+	}()
+}
+
+// handlePeerBackupMsg handles a PeerBackup or PeerBackupRequest message sent
+// by the peer, ignoring either if peer backups are disabled.
+func (p *peer) handlePeerBackupMsg(msg lnwire.Message) {
+	if !cfg.PeerBackup {
+		return
+	}
+
+	switch msg := msg.(type) {
+	case *lnwire.PeerBackup:
+		if msg.Returned {
+			select {
+			case p.returnedBackups <- msg.Backup:
+			case <-p.quit:
+			}
+			return
+		}
+
+		if err := p.server.chanDB.PutPeerBackup(&p.lightningID,
+			msg.Backup); err != nil {
+
+			peerLog.Errorf("unable to store backup of peerID(%v): %v",
+				p.id, err)
+		}
+
+	case *lnwire.PeerBackupRequest:
+		backup, err := p.server.chanDB.FetchPeerBackup(&p.lightningID)
+		if err == channeldb.ErrPeerBackupNotFound {
+			peerLog.Infof("peerID(%v) requested backup, none "+
+				"stored", p.id)
+			return
+		} else if err != nil {
+			peerLog.Errorf("unable to fetch backup of peerID(%v): %v",
+				p.id, err)
+			return
+		}
+
+		p.queueMsg(&lnwire.PeerBackup{
+			Backup:   backup,
+			Returned: true,
+		}, nil)
+	}
+}
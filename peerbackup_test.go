@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestPeerBackupEncryption tests that channel backups survive a round trip
+// through encryption, and that a backup can't be decrypted, or tampered
+// with, without the key of its owner.
+func TestPeerBackupEncryption(t *testing.T) {
+	backups := []*channelBackup{
+		{
+			ChanPoint:     wire.OutPoint{Hash: wire.ShaHash{1}, Index: 1},
+			AssetID:       "asset",
+			Capacity:      1000,
+			LocalBalance:  400,
+			RemoteBalance: 600,
+			NumUpdates:    12,
+		},
+		{
+			ChanPoint: wire.OutPoint{Hash: wire.ShaHash{2}},
+			Capacity:  50,
+		},
+	}
+
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	key := peerBackupKey(priv)
+
+	blob, err := encryptPeerBackup(key, backups)
+	if err != nil {
+		t.Fatalf("unable to encrypt backup: %v", err)
+	}
+
+	decrypted, err := decryptPeerBackup(key, blob)
+	if err != nil {
+		t.Fatalf("unable to decrypt backup: %v", err)
+	}
+	if !reflect.DeepEqual(backups, decrypted) {
+		t.Fatalf("backups don't match: %v vs %v", backups, decrypted)
+	}
+
+	otherPriv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	if _, err := decryptPeerBackup(peerBackupKey(otherPriv), blob); err == nil {
+		t.Fatalf("backup decrypted with the wrong key")
+	}
+
+	blob[len(blob)-1] ^= 1
+	if _, err := decryptPeerBackup(key, blob); err == nil {
+		t.Fatalf("tampered backup decrypted")
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcutil"
+)
+
+// probeRecordType is the sole byte of the payload carried by a probe once it
+// reaches its destination. The destination fails the probe back as paying to
+// an unknown hash, without consulting its invoices or interceptor.
+const probeRecordType = 0x70
+
+// isProbeRecord returns true if the passed HTLC payload marks the HTLC as a
+// probe which has reached its destination.
+func isProbeRecord(payload []byte) bool {
+	return len(payload) == 1 && payload[0] == probeRecordType
+}
+
+// newProbePayload returns the payload of a probe which, once sent over the
+// first hop of a route, is forwarded along the passed remaining hops, each
+// HTLC expiring at the corresponding height of expiries. The final node of
+// the route receives the probe record.
+func newProbePayload(hops []*router.Hop, expiries []uint32) []byte {
+	return newForwardPayload(hops, expiries, []byte{probeRecordType})
+}
+
+// ProbeRoute tests whether amt of an asset may currently be paid to dest,
+// without paying it. An HTLC paying to a random hash, which no node knows the
+// preimage of, is sent along a route to dest. If the HTLC makes it to dest,
+// then it's failed back as paying to an unknown hash, proving that each
+// channel along the route had sufficient bandwidth. Any other failure
+// indicates the route is unable to carry the payment. The route probed is
+// returned if the probe succeeded. ProbeRoute blocks until the probe has been
+// failed back.
+func (s *server) ProbeRoute(dest router.NodeID, assetID string,
+	amt btcutil.Amount) (*router.Route, error) {
+
+	if amt <= 0 {
+		return nil, fmt.Errorf("invalid probe amount %v", amt)
+	}
+
+	assetID = graphAssetID(assetID)
+	route, err := s.chanGraph.FindRoute(router.NodeID(s.lightningID), dest,
+		assetID, assetID, amt)
+	if err != nil {
+		return nil, err
+	}
+	if len(route.Hops) == 0 {
+		return nil, fmt.Errorf("can't probe a route to ourselves")
+	}
+
+	height, err := s.currentHeight()
+	if err != nil {
+		return nil, err
+	}
+	expiries := route.HopExpiries(height, finalExpiryDelta)
+	payload := newProbePayload(route.Hops[1:], expiries[1:])
+
+	var paymentHash [32]byte
+	if _, err := rand.Read(paymentHash[:]); err != nil {
+		return nil, err
+	}
+
+	srvrLog.Infof("Probing route %v for %v of %q", route, amt, assetID)
+
+	first := route.Hops[0]
+	htlcPkt := &htlcPacket{
+		msg: &lnwire.HTLCAddRequest{
+			Expiry:           expiries[0],
+			Amount:           lnwire.CreditsAmount(first.Amount),
+			RedemptionHashes: [][32]byte{paymentHash},
+			OnionBlob:        payload,
+		},
+		outgoingChan: &first.Channel.ChanPoint,
+	}
+	err = s.htlcSwitch.SendHTLC(htlcPkt)
+	if err == nil {
+		return nil, fmt.Errorf("probe of route %v was settled", route)
+	}
+
+	// Only the destination fails a probe as paying to an unknown hash,
+	// any other failure occurred along the way.
+	failErr, ok := err.(*htlcFailError)
+	if !ok || failErr.reason != lnwire.FailUnknownPaymentHash {
+		return nil, fmt.Errorf("probe of route %v failed: %v", route,
+			err)
+	}
+
+	return route, nil
+}
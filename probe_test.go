@@ -0,0 +1,79 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestProbePayload tests that the payload of a probe instructs each node
+// along the route to forward the probe onwards, until the destination
+// receives the probe record.
+func TestProbePayload(t *testing.T) {
+	alice, bob := router.NodeID{2}, router.NodeID{3}
+	hops := []*router.Hop{
+		{
+			Channel:  &router.ChannelEdge{ChanPoint: wire.OutPoint{Index: 2}},
+			NextNode: alice,
+			Amount:   1000,
+		},
+		{
+			Channel:  &router.ChannelEdge{ChanPoint: wire.OutPoint{Index: 3}},
+			NextNode: bob,
+			Amount:   990,
+		},
+	}
+
+	// A probe sent directly to a neighbour carries only the probe record.
+	if !isProbeRecord(newProbePayload(nil, nil)) {
+		t.Fatalf("probe to neighbour carries no probe record")
+	}
+
+	expiries := []uint32{1200, 1100}
+	payload := newProbePayload(hops, expiries)
+	for i, hop := range hops {
+		if isProbeRecord(payload) {
+			t.Fatalf("hop #%v: probe record received early", i)
+		}
+		record, err := decodeForwardRecord(payload)
+		if err != nil {
+			t.Fatalf("hop #%v: unable to decode record: %v", i, err)
+		}
+		if record.nextChan != hop.Channel.ChanPoint ||
+			record.amt != hop.Amount ||
+			record.expiry != expiries[i] {
+			t.Fatalf("hop #%v: unexpected record %v", i, record)
+		}
+		payload = record.payload
+	}
+	if !isProbeRecord(payload) {
+		t.Fatalf("destination didn't receive probe record, got %x",
+			payload)
+	}
+	if _, err := decodeForwardRecord(payload); err != errNoForwardRecord {
+		t.Fatalf("expected errNoForwardRecord, got %v", err)
+	}
+}
+
+// TestInterceptedHTLCFailReason tests that the reason an intercepted HTLC is
+// failed with is passed on within its resolution.
+func TestInterceptedHTLCFailReason(t *testing.T) {
+	resolutions := make(chan *htlcResolution, 1)
+	htlc := &InterceptedHTLC{
+		Index:       5,
+		resolutions: resolutions,
+		quit:        make(chan struct{}),
+	}
+
+	err := htlc.failWithReason(lnwire.FailUnknownPaymentHash)
+	if err != nil {
+		t.Fatalf("unable to fail htlc: %v", err)
+	}
+	res := <-resolutions
+	if res.preimage != nil || res.index != 5 ||
+		res.reason != lnwire.FailUnknownPaymentHash {
+		t.Fatalf("unexpected resolution %v", res)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+)
+
+const (
+	// defaultPeerUpdateRate is the default number of channel updates per
+	// second a peer may send us, across all of its channels.
+	defaultPeerUpdateRate = 50
+
+	// defaultPeerUpdateBurst is the default number of channel updates a
+	// peer may send us in a burst, beyond its update rate.
+	defaultPeerUpdateBurst = 100
+
+	// defaultMaxPendingCommits is the default number of commitment
+	// signatures sent by a peer which may await processing at once.
+	defaultMaxPendingCommits = 10
+
+	// defaultPeerIgnorePeriod is the default period during which we stop
+	// reading from a peer exceeding its limits.
+	defaultPeerIgnorePeriod = 10 * time.Second
+)
+
+// updatePenalty is the action taken against a peer which exceeds the limits
+// on its channel updates.
+type updatePenalty uint8
+
+const (
+	// penaltyIgnore stops reading from the peer for the ignore period.
+	// As the messages of the peer are delayed rather than dropped, the
+	// state of its channels is left intact.
+	penaltyIgnore updatePenalty = iota
+
+	// penaltyClose force closes the channel targeted by the update which
+	// exceeded the limits, dropping all further updates for it.
+	penaltyClose
+)
+
+// String returns the name of the penalty, as given within the config.
+func (p updatePenalty) String() string {
+	switch p {
+	case penaltyIgnore:
+		return "ignore"
+	case penaltyClose:
+		return "close"
+	default:
+		return fmt.Sprintf("updatePenalty(%d)", uint8(p))
+	}
+}
+
+// parseUpdatePenalty parses the name of a penalty.
+func parseUpdatePenalty(name string) (updatePenalty, error) {
+	switch name {
+	case "ignore":
+		return penaltyIgnore, nil
+	case "close":
+		return penaltyClose, nil
+	default:
+		return 0, fmt.Errorf("unknown rate limit penalty %q, must be "+
+			"one of: ignore, close", name)
+	}
+}
+
+// updateLimitPolicy houses the limits enforced on the channel updates of
+// each peer, as updates are costly to process: each commitment signature
+// received requires us to sign, and colorify new commitments.
+type updateLimitPolicy struct {
+	// updateRate is the number of channel updates per second a peer may
+	// send us. If zero, the rate of updates isn't limited.
+	updateRate float64
+
+	// updateBurst is the number of channel updates a peer may send us in
+	// a burst, beyond its update rate.
+	updateBurst int
+
+	// maxPendingCommits is the number of commitment signatures sent by a
+	// peer which may await processing at once. If zero, the number of
+	// pending commitments isn't limited.
+	maxPendingCommits int
+
+	// penalty is the action taken against a peer exceeding either limit.
+	penalty updatePenalty
+
+	// ignorePeriod is the period during which we stop reading from a peer
+	// exceeding either limit, if the penalty is penaltyIgnore.
+	ignorePeriod time.Duration
+}
+
+// newUpdateLimitPolicy creates a new updateLimitPolicy, validating the passed
+// limits.
+func newUpdateLimitPolicy(updateRate float64, updateBurst,
+	maxPendingCommits int, penalty string,
+	ignorePeriod time.Duration) (*updateLimitPolicy, error) {
+
+	switch {
+	case updateRate < 0:
+		return nil, fmt.Errorf("peer update rate cannot be negative")
+	case updateRate > 0 && updateBurst < 1:
+		return nil, fmt.Errorf("peer update burst must be positive")
+	case maxPendingCommits < 0:
+		return nil, fmt.Errorf("max pending commitments cannot be " +
+			"negative")
+	case ignorePeriod < 0:
+		return nil, fmt.Errorf("peer ignore period cannot be negative")
+	}
+
+	p, err := parseUpdatePenalty(penalty)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updateLimitPolicy{
+		updateRate:        updateRate,
+		updateBurst:       updateBurst,
+		maxPendingCommits: maxPendingCommits,
+		penalty:           p,
+		ignorePeriod:      ignorePeriod,
+	}, nil
+}
+
+// tokenBucket is a token bucket refilled at a constant rate, up to its
+// burst size.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a new, full token bucket.
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+// take removes a single token from the bucket, returning false if the bucket
+// is empty.
+func (b *tokenBucket) take(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+
+	return true
+}
+
+// updateLimiter enforces the updateLimitPolicy on the channel updates of a
+// single peer.
+//
+// NOTE: With the exception of commitProcessed, the methods of the limiter
+// MUST only be called from the peer's readHandler goroutine.
+type updateLimiter struct {
+	policy *updateLimitPolicy
+
+	// bucket limits the rate of updates. It's nil if the rate isn't
+	// limited.
+	bucket *tokenBucket
+
+	// pendingCommits is the number of commitment signatures dispatched
+	// to the channels of the peer, which are yet to be processed.
+	//
+	// NOTE: This MUST be used atomically.
+	pendingCommits int32
+
+	// penalized is the set of channels being force closed as a penalty,
+	// for which all updates are dropped.
+	penalized map[wire.OutPoint]struct{}
+}
+
+// newUpdateLimiter creates a new updateLimiter enforcing the passed policy.
+func newUpdateLimiter(policy *updateLimitPolicy) *updateLimiter {
+	l := &updateLimiter{
+		policy:    policy,
+		penalized: make(map[wire.OutPoint]struct{}),
+	}
+	if policy.updateRate > 0 {
+		l.bucket = newTokenBucket(policy.updateRate,
+			policy.updateBurst, time.Now())
+	}
+
+	return l
+}
+
+// checkUpdate checks the passed channel update against the limits, returning
+// a non-nil error describing the violation if it exceeds either.
+func (l *updateLimiter) checkUpdate(msg lnwire.Message, now time.Time) error {
+	if l.bucket != nil && !l.bucket.take(now) {
+		return fmt.Errorf("exceeded %v channel updates per second",
+			l.policy.updateRate)
+	}
+
+	if _, ok := msg.(*lnwire.CommitSignature); ok {
+		pending := atomic.LoadInt32(&l.pendingCommits)
+		max := l.policy.maxPendingCommits
+		if max > 0 && int(pending) >= max {
+			return fmt.Errorf("exceeded %v pending commitments", max)
+		}
+	}
+
+	return nil
+}
+
+// dispatched records that the passed channel update has been dispatched to
+// its channel.
+func (l *updateLimiter) dispatched(msg lnwire.Message) {
+	if _, ok := msg.(*lnwire.CommitSignature); ok {
+		atomic.AddInt32(&l.pendingCommits, 1)
+	}
+}
+
+// commitProcessed records that a channel of the peer has finished processing
+// a commitment signature.
+func (l *updateLimiter) commitProcessed() {
+	atomic.AddInt32(&l.pendingCommits, -1)
+}
+
+// limitUpdate enforces the peer's limits on the passed update targeting the
+// passed channel, returning false if the update is to be dropped. If the
+// update exceeds either limit, then the configured penalty is applied.
+//
+// NOTE: This method MUST only be called from the readHandler goroutine.
+func (p *peer) limitUpdate(msg lnwire.Message, chanPoint *wire.OutPoint) bool {
+	limiter := p.updateLimiter
+	if _, ok := limiter.penalized[*chanPoint]; ok {
+		return false
+	}
+
+	violation := limiter.checkUpdate(msg, time.Now())
+	if violation == nil {
+		return true
+	}
+
+	switch limiter.policy.penalty {
+	case penaltyClose:
+		peerLog.Warnf("peerID(%v) %v, force closing ChannelPoint(%v)",
+			p.id, violation, chanPoint)
+
+		limiter.penalized[*chanPoint] = struct{}{}
+		go func() {
+			_, errChan := p.server.htlcSwitch.CloseLink(chanPoint,
+				true, nil)
+			if err := <-errChan; err != nil {
+				peerLog.Errorf("unable to force close "+
+					"ChannelPoint(%v): %v", chanPoint, err)
+			}
+		}()
+
+		return false
+
+	default:
+		peerLog.Warnf("peerID(%v) %v, ignoring peer for %v", p.id,
+			violation, limiter.policy.ignorePeriod)
+
+		// The update is still delivered once the ignore period has
+		// elapsed, as dropping it would desynchronize the state of
+		// the channel with the peer.
+		select {
+		case <-time.After(limiter.policy.ignorePeriod):
+		case <-p.quit:
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwire"
+)
+
+// TestTokenBucket tests that a token bucket allows bursts up to its size,
+// and refills at its rate.
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1e9, 0)
+	bucket := newTokenBucket(2, 3, now)
+
+	for i := 0; i < 3; i++ {
+		if !bucket.take(now) {
+			t.Fatalf("take #%v refused within burst", i)
+		}
+	}
+	if bucket.take(now) {
+		t.Fatalf("take allowed beyond burst")
+	}
+
+	// After half a second, a single token has been refilled.
+	now = now.Add(500 * time.Millisecond)
+	if !bucket.take(now) {
+		t.Fatalf("refilled token refused")
+	}
+	if bucket.take(now) {
+		t.Fatalf("take allowed beyond refill")
+	}
+
+	// However long the bucket is left idle, it never holds more than its
+	// burst size.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !bucket.take(now) {
+			t.Fatalf("take #%v refused within burst", i)
+		}
+	}
+	if bucket.take(now) {
+		t.Fatalf("take allowed beyond burst")
+	}
+}
+
+// TestUpdateLimiter tests that the update limiter enforces both the rate of
+// updates, and the number of pending commitments.
+func TestUpdateLimiter(t *testing.T) {
+	policy, err := newUpdateLimitPolicy(1, 4, 2, "close", 0)
+	if err != nil {
+		t.Fatalf("unable to create policy: %v", err)
+	}
+	limiter := newUpdateLimiter(policy)
+	now := time.Now()
+
+	commitSig := &lnwire.CommitSignature{}
+	for i := 0; i < 2; i++ {
+		if err := limiter.checkUpdate(commitSig, now); err != nil {
+			t.Fatalf("commitment #%v refused: %v", i, err)
+		}
+		limiter.dispatched(commitSig)
+	}
+
+	// With two commitments pending, a third is refused, though other
+	// updates are still accepted within the rate.
+	if err := limiter.checkUpdate(commitSig, now); err == nil {
+		t.Fatalf("commitment accepted beyond pending limit")
+	}
+	addReq := &lnwire.HTLCAddRequest{}
+	if err := limiter.checkUpdate(addReq, now); err != nil {
+		t.Fatalf("update refused: %v", err)
+	}
+
+	// Once a commitment has been processed, another may be sent, until the
+	// rate of updates is exceeded.
+	limiter.commitProcessed()
+	now = now.Add(time.Second)
+	if err := limiter.checkUpdate(commitSig, now); err != nil {
+		t.Fatalf("commitment refused: %v", err)
+	}
+	if err := limiter.checkUpdate(addReq, now); err == nil {
+		t.Fatalf("update accepted beyond rate limit")
+	}
+}
+
+// TestUpdateLimitPolicyValidation tests that invalid limits are rejected.
+func TestUpdateLimitPolicyValidation(t *testing.T) {
+	tests := []struct {
+		rate       float64
+		burst      int
+		maxPending int
+		penalty    string
+		period     time.Duration
+		valid      bool
+	}{
+		{rate: 1, burst: 1, penalty: "ignore", valid: true},
+		{penalty: "close", valid: true},
+		{rate: -1, burst: 1, penalty: "ignore"},
+		{rate: 1, burst: 0, penalty: "ignore"},
+		{maxPending: -1, penalty: "ignore"},
+		{penalty: "ignore", period: -time.Second},
+		{penalty: "ban"},
+	}
+
+	for i, test := range tests {
+		_, err := newUpdateLimitPolicy(test.rate, test.burst,
+			test.maxPending, test.penalty, test.period)
+		if (err == nil) != test.valid {
+			t.Fatalf("test #%v: expected valid=%v, got err=%v", i,
+				test.valid, err)
+		}
+	}
+}
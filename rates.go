@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcutil"
+)
+
+// btcAssetID is the asset ID used to denote plain bitcoin when quoting
+// exchange rates.
+const btcAssetID = "BTC"
+
+// maxAssetDivisibility is the largest number of decimal places an asset may
+// be divided into.
+const maxAssetDivisibility = 18
+
+// RateProvider is consulted by the htlcSwitch when forwarding an HTLC between
+// two channels denominated in different assets. The same payment hash locks
+// both the incoming and outgoing HTLC, so the swap between the two assets is
+// atomic: either both HTLCs are settled, or neither is.
+type RateProvider interface {
+	// ConvertAmount returns the amount of toAsset to be forwarded in
+	// exchange for receiving amt of fromAsset. An error is returned if the
+	// provider doesn't support swaps between the two assets.
+	ConvertAmount(fromAsset, toAsset string,
+		amt btcutil.Amount) (btcutil.Amount, error)
+}
+
+// assetPair is a directed pair of assets for which an exchange rate is
+// quoted.
+type assetPair struct {
+	from string
+	to   string
+}
+
+// staticRateProvider is a RateProvider backed by a fixed set of exchange
+// rates specified at start up.
+type staticRateProvider struct {
+	rates map[assetPair]*big.Rat
+
+	// divisibility is the number of decimal places of each asset with a
+	// known divisibility. Rates between two such assets are quoted per
+	// whole unit, while all others are quoted per base unit.
+	divisibility map[string]int
+
+	// rounders rounds the converted amounts of each pair of assets to
+	// whole base units of the asset forwarded.
+	rounders map[assetPair]*lnwallet.AmountRounder
+}
+
+// newStaticRateProvider creates a new staticRateProvider from the passed set
+// of rates. Each rate is of the form: <from_asset>:<to_asset>:<rate>, where
+// rate is the number of units of to_asset forwarded for each unit of
+// from_asset received. Each divisibility is of the form:
+// <asset>:<decimal_places>. Converted amounts are rounded to whole base units
+// according to the passed policy.
+func newStaticRateProvider(rates, divisibilities []string,
+	policy lnwallet.RoundingPolicy) (*staticRateProvider, error) {
+
+	s := &staticRateProvider{
+		rates:        make(map[assetPair]*big.Rat),
+		divisibility: make(map[string]int),
+		rounders:     make(map[assetPair]*lnwallet.AmountRounder),
+	}
+
+	for _, rate := range rates {
+		parts := strings.Split(rate, ":")
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid swap rate %q, must be of "+
+				"the form <from_asset>:<to_asset>:<rate>", rate)
+		}
+
+		// The rate is parsed as an exact decimal, so converted
+		// amounts aren't skewed by its binary approximation.
+		r, ok := new(big.Rat).SetString(parts[2])
+		if !ok {
+			return nil, fmt.Errorf("invalid swap rate %q", rate)
+		}
+		if r.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid swap rate %q, rate must "+
+				"be positive", rate)
+		}
+
+		pair := assetPair{parts[0], parts[1]}
+		s.rates[pair] = r
+		s.rounders[pair] = lnwallet.NewAmountRounder(policy)
+	}
+
+	for _, divisibility := range divisibilities {
+		parts := strings.Split(divisibility, ":")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid asset divisibility %q, "+
+				"must be of the form <asset>:<decimal_places>",
+				divisibility)
+		}
+
+		places, err := strconv.Atoi(parts[1])
+		if err != nil || places < 0 || places > maxAssetDivisibility {
+			return nil, fmt.Errorf("invalid asset divisibility %q, "+
+				"decimal places must be between 0 and %v",
+				divisibility, maxAssetDivisibility)
+		}
+
+		s.divisibility[parts[0]] = places
+	}
+
+	return s, nil
+}
+
+// baseUnitRate returns the number of base units of the pair's toAsset
+// forwarded for each base unit of its fromAsset received. Rates between two
+// assets of known divisibility are quoted per whole unit, and are scaled by
+// the difference in their divisibility.
+func (s *staticRateProvider) baseUnitRate(pair assetPair) (*big.Rat, bool) {
+	rate, ok := s.rates[pair]
+	if !ok {
+		return nil, false
+	}
+
+	fromPlaces, fromOk := s.divisibility[pair.from]
+	toPlaces, toOk := s.divisibility[pair.to]
+	if !fromOk || !toOk {
+		return rate, true
+	}
+
+	scale := lnwallet.DivisibilityScale(fromPlaces, toPlaces)
+	return new(big.Rat).Mul(rate, scale), true
+}
+
+// ConvertAmount returns the amount of toAsset to be forwarded in exchange for
+// receiving amt of fromAsset. The converted amount is rounded to whole base
+// units of toAsset according to the provider's rounding policy.
+//
+// This is a part of the RateProvider interface.
+func (s *staticRateProvider) ConvertAmount(fromAsset, toAsset string,
+	amt btcutil.Amount) (btcutil.Amount, error) {
+
+	if fromAsset == toAsset {
+		return amt, nil
+	}
+
+	pair := assetPair{fromAsset, toAsset}
+	rate, ok := s.baseUnitRate(pair)
+	if !ok {
+		return 0, fmt.Errorf("no swap rate from %v to %v", fromAsset,
+			toAsset)
+	}
+
+	// An amount worth less than a single base unit is rejected before
+	// it's rounded, so the remainder of a swap which never takes place
+	// isn't accumulated.
+	exact := new(big.Rat).SetInt64(int64(amt))
+	exact.Mul(exact, rate)
+	if exact.Cmp(big.NewRat(1, 1)) < 0 {
+		return 0, fmt.Errorf("%v of %v is worth less than a single "+
+			"unit of %v", amt, fromAsset, toAsset)
+	}
+
+	return s.rounders[pair].Round(exact), nil
+}
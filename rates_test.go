@@ -0,0 +1,205 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// TestCrossAssetForwardAmount tests that the htlcSwitch consults its
+// RateProvider when forwarding an HTLC between channels of different assets.
+func TestCrossAssetForwardAmount(t *testing.T) {
+	_, err := newStaticRateProvider([]string{"assetA:1.5"}, nil,
+		lnwallet.RoundDown)
+	if err == nil {
+		t.Fatalf("malformed swap rate accepted")
+	}
+	_, err = newStaticRateProvider([]string{"assetA:BTC:-1"}, nil,
+		lnwallet.RoundDown)
+	if err == nil {
+		t.Fatalf("negative swap rate accepted")
+	}
+
+	rates, err := newStaticRateProvider([]string{
+		"assetA:assetB:2.5",
+		"assetA:BTC:0.001",
+	}, nil, lnwallet.RoundDown)
+	if err != nil {
+		t.Fatalf("unable to create rate provider: %v", err)
+	}
+
+	h := newHtlcSwitch(rates)
+
+	chanA := &wire.OutPoint{Index: 1}
+	chanB := &wire.OutPoint{Index: 2}
+	chanBTC := &wire.OutPoint{Index: 3}
+	linkA := &link{assetID: "assetA", chanPoint: chanA}
+	linkB := &link{assetID: "assetB", chanPoint: chanB}
+	linkBTC := &link{chanPoint: chanBTC}
+	h.chanIndex[*chanA] = linkA
+	h.chanIndex[*chanB] = linkB
+	h.chanIndex[*chanBTC] = linkBTC
+
+	testCases := []struct {
+		incoming *wire.OutPoint
+		outgoing *link
+		amtIn    btcutil.Amount
+		amtOut   btcutil.Amount
+		fail     bool
+	}{
+		// Locally initiated payments aren't converted.
+		{nil, linkB, 1000, 1000, false},
+
+		// Forwards between channels of the same asset aren't converted.
+		{chanA, linkA, 1000, 1000, false},
+
+		// Forwards between assets with a quoted rate are converted,
+		// rounding down.
+		{chanA, linkB, 1001, 2502, false},
+		{chanA, linkBTC, 5000, 5, false},
+
+		// An amount worth less than a single outgoing unit can't be
+		// forwarded.
+		{chanA, linkBTC, 999, 0, true},
+
+		// Without a quoted rate, the forward should fail.
+		{chanB, linkA, 1000, 0, true},
+	}
+	for i, testCase := range testCases {
+		pkt := &htlcPacket{incomingChan: testCase.incoming}
+		amt, err := h.outgoingAmount(pkt, testCase.amtIn,
+			testCase.outgoing)
+		switch {
+		case testCase.fail && err == nil:
+			t.Fatalf("#%v: forward should have failed", i)
+		case !testCase.fail && err != nil:
+			t.Fatalf("#%v: unable to forward: %v", i, err)
+		case amt != testCase.amtOut:
+			t.Fatalf("#%v: expected %v, got %v", i,
+				testCase.amtOut, amt)
+		}
+	}
+}
+
+// TestSwapRateDivisibility tests that swap rates between assets of known
+// divisibility are quoted per whole unit, and that the remainders of
+// conversions are accumulated under the accumulate rounding policy.
+func TestSwapRateDivisibility(t *testing.T) {
+	_, err := newStaticRateProvider(nil, []string{"assetA:-1"},
+		lnwallet.RoundDown)
+	if err == nil {
+		t.Fatalf("negative divisibility accepted")
+	}
+
+	// A whole unit of assetA, divided into 2 decimal places, is worth 0.3
+	// whole units of assetB, divided into 3 decimal places. So each base
+	// unit of assetA is worth 3 base units of assetB. In the other
+	// direction, each base unit of assetB is worth 0.334 base units of
+	// assetA.
+	rates, err := newStaticRateProvider([]string{
+		"assetA:assetB:0.3",
+		"assetB:assetA:3.34",
+	}, []string{"assetA:2", "assetB:3"}, lnwallet.AccumulateRemainder)
+	if err != nil {
+		t.Fatalf("unable to create rate provider: %v", err)
+	}
+
+	amt, err := rates.ConvertAmount("assetA", "assetB", 7)
+	if err != nil {
+		t.Fatalf("unable to convert amount: %v", err)
+	}
+	if amt != 21 {
+		t.Fatalf("expected 21, got %v", amt)
+	}
+
+	// Each conversion of 10 base units of assetB is worth 3.34 base units
+	// of assetA. The remainders add up to a whole base unit by the
+	// third conversion.
+	for i, expected := range []btcutil.Amount{3, 3, 4} {
+		amt, err := rates.ConvertAmount("assetB", "assetA", 10)
+		if err != nil {
+			t.Fatalf("unable to convert amount: %v", err)
+		}
+		if amt != expected {
+			t.Fatalf("#%v: expected %v, got %v", i, expected, amt)
+		}
+	}
+
+	// A single base unit of assetB is worth less than a base unit of
+	// assetA, so it can't be forwarded regardless of the remainder.
+	if _, err := rates.ConvertAmount("assetB", "assetA", 1); err == nil {
+		t.Fatalf("amount worth less than a base unit converted")
+	}
+}
+
+// TestForwardConvertsCopy tests that the htlcSwitch sends a converted copy of
+// a forwarded HTLC over the outgoing link, leaving the packet it was handed
+// denominated in the incoming asset.
+func TestForwardConvertsCopy(t *testing.T) {
+	rates, err := newStaticRateProvider([]string{"assetA:assetB:2"}, nil,
+		lnwallet.RoundDown)
+	if err != nil {
+		t.Fatalf("unable to create rate provider: %v", err)
+	}
+
+	h := newHtlcSwitch(rates)
+
+	bob := &peer{lightningID: wire.ShaHash{2}}
+	chanA := &wire.OutPoint{Index: 1}
+	chanB := &wire.OutPoint{Index: 2}
+	linkA := &link{assetID: "assetA", chanPoint: chanA}
+	linkB := &link{
+		assetID:            "assetB",
+		chanPoint:          chanB,
+		availableBandwidth: 1e6,
+		linkChan:           make(chan *htlcPacket, 1),
+		peer:               bob,
+	}
+	h.chanIndex[*chanA] = linkA
+	h.chanIndex[*chanB] = linkB
+	h.interfaces[bob.lightningID] = []*link{linkB}
+
+	if err := h.Start(); err != nil {
+		t.Fatalf("unable to start switch: %v", err)
+	}
+	defer h.Stop()
+
+	msg := &lnwire.HTLCAddRequest{Amount: 1000}
+	pkt := &htlcPacket{
+		dest:         bob.lightningID,
+		msg:          msg,
+		amt:          1000,
+		incomingChan: chanA,
+	}
+	sendErr := make(chan error, 1)
+	go func() {
+		sendErr <- h.SendHTLC(pkt)
+	}()
+
+	var outPkt *htlcPacket
+	select {
+	case outPkt = <-linkB.linkChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("htlc not forwarded")
+	}
+	outMsg := outPkt.msg.(*lnwire.HTLCAddRequest)
+	if outPkt.amt != 2000 || outMsg.Amount != 2000 ||
+		outMsg.ChannelPoint != chanB {
+		t.Fatalf("expected 2000 over ChannelPoint(%v), got %v over "+
+			"ChannelPoint(%v)", chanB, outMsg.Amount,
+			outMsg.ChannelPoint)
+	}
+	if pkt.amt != 1000 || msg.Amount != 1000 || msg.ChannelPoint != nil {
+		t.Fatalf("original packet modified: amount %v", msg.Amount)
+	}
+
+	// The outgoing packet still reports back to the sender.
+	outPkt.err <- nil
+	if err := <-sendErr; err != nil {
+		t.Fatalf("unable to send htlc: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// newCircularPayload returns the payload and amount of an HTLC which, once
+// sent to the first node of a route, is forwarded along the passed hops, each
+// HTLC expiring at the corresponding height of expiries. Each forwarding node
+// is paid the fee it charges for the channel it forwards over.
+func newCircularPayload(hops []*router.Hop,
+	expiries []uint32) (btcutil.Amount, []byte) {
+
+	payload := newForwardPayload(hops, expiries, nil)
+
+	first := hops[0]
+	return first.Amount + first.Channel.Fee(first.Amount), payload
+}
+
+// remoteNode returns the remote endpoint of one of our own channels of the
+// passed asset within the channel graph.
+func (s *server) remoteNode(chanPoint wire.OutPoint,
+	assetID string) (*router.ChannelEdge, router.NodeID, error) {
+
+	edge, err := s.chanGraph.Channel(chanPoint)
+	if err != nil {
+		return nil, router.NodeID{}, err
+	}
+	if edge.AssetID != assetID {
+		return nil, router.NodeID{}, fmt.Errorf("ChannelPoint(%v) is "+
+			"denominated in %q, not %q", chanPoint, edge.AssetID,
+			assetID)
+	}
+
+	switch router.NodeID(s.lightningID) {
+	case edge.Node1:
+		return edge, edge.Node2, nil
+	case edge.Node2:
+		return edge, edge.Node1, nil
+	}
+
+	return nil, router.NodeID{}, fmt.Errorf("ChannelPoint(%v) isn't one "+
+		"of our channels", chanPoint)
+}
+
+// Rebalance shifts amt of an asset from our side of fromChan to our side of
+// toChan, without any on-chain transactions. This is done by paying ourselves:
+// an HTLC is sent out over fromChan, then routed through the network back to
+// us over toChan. The fees charged by the forwarding nodes are paid on top of
+// amt. Rebalance blocks until the payment has completed.
+func (s *server) Rebalance(assetID string, amt btcutil.Amount, fromChan,
+	toChan wire.OutPoint) error {
+
+	if amt <= 0 {
+		return fmt.Errorf("invalid rebalance amount %v", amt)
+	}
+	if fromChan == toChan {
+		return fmt.Errorf("can't rebalance ChannelPoint(%v) with "+
+			"itself", fromChan)
+	}
+
+	assetID = graphAssetID(assetID)
+	fromEdge, first, err := s.remoteNode(fromChan, assetID)
+	if err != nil {
+		return err
+	}
+	toEdge, last, err := s.remoteNode(toChan, assetID)
+	if err != nil {
+		return err
+	}
+
+	// The peer of toChan must receive enough to forward amt back to us,
+	// along with its fee. If both channels are with the same peer, then
+	// the route between the two is empty.
+	lastAmt := amt + toEdge.Fee(amt)
+	route, err := s.chanGraph.FindRoute(first, last, assetID, assetID,
+		lastAmt)
+	if err != nil {
+		return err
+	}
+	hops := append(route.Hops, &router.Hop{
+		Channel:  toEdge,
+		NextNode: router.NodeID(s.lightningID),
+		AssetID:  assetID,
+		Amount:   amt,
+	})
+
+	// Each forwarding node is granted its time lock delta, counting back
+	// from the current height, with the HTLC sent over fromChan expiring
+	// last.
+	height, err := s.currentHeight()
+	if err != nil {
+		return err
+	}
+	circuit := &router.Route{
+		Hops: append([]*router.Hop{{
+			Channel:  fromEdge,
+			NextNode: first,
+			AssetID:  assetID,
+		}}, hops...),
+	}
+	expiries := circuit.HopExpiries(height, finalExpiryDelta)
+	sendAmt, payload := newCircularPayload(hops, expiries[1:])
+
+	// The payment is received by an invoice of our own, which is settled
+	// as the HTLC returns over toChan.
+	var preimage [32]byte
+	if _, err := rand.Read(preimage[:]); err != nil {
+		return err
+	}
+	invoice := &channeldb.Invoice{
+		CreationDate: time.Now(),
+		Terms: channeldb.ContractTerm{
+			Value:           amt,
+			PaymentPreimage: preimage,
+			AssetID:         assetID,
+		},
+	}
+	copy(invoice.Memo[:], "rebalance")
+	if err := s.invoices.addInvoice(invoice); err != nil {
+		return err
+	}
+
+	srvrLog.Infof("Rebalancing %v of %q from ChannelPoint(%v) to "+
+		"ChannelPoint(%v) over %v hops, sending %v", amt, assetID,
+		fromChan, toChan, len(hops)+1, sendAmt)
+
+	htlcPkt := &htlcPacket{
+		msg: &lnwire.HTLCAddRequest{
+			Expiry:           expiries[0],
+			Amount:           lnwire.CreditsAmount(sendAmt),
+			RedemptionHashes: [][32]byte{fastsha256.Sum256(preimage[:])},
+			OnionBlob:        payload,
+		},
+		outgoingChan: &fromChan,
+	}
+
+	return s.htlcSwitch.SendHTLC(htlcPkt)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestForwardRecord tests the encoding of forwarding records, and that the
+// payload of a circular payment instructs each node along the route to
+// forward the HTLC onwards, while paying each node its fee.
+func TestForwardRecord(t *testing.T) {
+	record := &forwardRecord{
+		nextChan: wire.OutPoint{Hash: wire.ShaHash{1}, Index: 2},
+		amt:      500,
+		expiry:   1200,
+		payload:  []byte{1, 2, 3},
+	}
+	decoded, err := decodeForwardRecord(record.encode())
+	if err != nil {
+		t.Fatalf("unable to decode record: %v", err)
+	}
+	if decoded.nextChan != record.nextChan || decoded.amt != record.amt ||
+		decoded.expiry != record.expiry ||
+		!bytes.Equal(decoded.payload, record.payload) {
+		t.Fatalf("expected record %v, got %v", record, decoded)
+	}
+	if _, err := decodeForwardRecord([]byte{forwardRecordType}); err != errNoForwardRecord {
+		t.Fatalf("expected errNoForwardRecord, got %v", err)
+	}
+
+	// us -> alice -> bob -> us, where alice charges a base fee of 10, and
+	// bob charges 1% for forwarding back to us.
+	us, alice, bob := router.NodeID{1}, router.NodeID{2}, router.NodeID{3}
+	aliceBob := &router.ChannelEdge{
+		ChanPoint: wire.OutPoint{Index: 2},
+		Node1:     alice,
+		Node2:     bob,
+		FeeBase:   10,
+	}
+	bobUs := &router.ChannelEdge{
+		ChanPoint: wire.OutPoint{Index: 3},
+		Node1:     bob,
+		Node2:     us,
+		FeeRate:   10000,
+	}
+	hops := []*router.Hop{
+		{Channel: aliceBob, NextNode: bob, Amount: 1010},
+		{Channel: bobUs, NextNode: us, Amount: 1000},
+	}
+
+	expiries := []uint32{1150, 1009}
+	sendAmt, payload := newCircularPayload(hops, expiries)
+	if sendAmt != 1020 {
+		t.Fatalf("expected to send 1020, got %v", sendAmt)
+	}
+
+	// Each node peels off its own record, revealing the payload of the
+	// HTLC it forwards.
+	for i, hop := range hops {
+		record, err := decodeForwardRecord(payload)
+		if err != nil {
+			t.Fatalf("hop #%v: unable to decode record: %v", i, err)
+		}
+		if record.nextChan != hop.Channel.ChanPoint ||
+			record.amt != hop.Amount ||
+			record.expiry != expiries[i] {
+			t.Fatalf("hop #%v: unexpected record %v", i, record)
+		}
+		payload = record.payload
+	}
+	if payload != nil {
+		t.Fatalf("final hop should carry no payload, got %x", payload)
+	}
+}
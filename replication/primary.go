@@ -0,0 +1,349 @@
+package replication
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+const (
+	// DefaultSyncTimeout is the default time Sync waits for the standby
+	// to acknowledge the outstanding deltas of a channel.
+	DefaultSyncTimeout = 5 * time.Second
+
+	// ackTimeout is the time the primary waits for the standby to
+	// acknowledge a single delta before the connection is deemed dead.
+	ackTimeout = 30 * time.Second
+
+	// retryInterval is the interval at which the primary re-attempts to
+	// connect to an unreachable standby.
+	retryInterval = 5 * time.Second
+
+	// maxPendingDeltas is the maximum number of deltas kept while awaiting
+	// the standby's acknowledgement. Once exceeded, the oldest deltas are
+	// dropped, as the standby has fallen too far behind.
+	maxPendingDeltas = 10000
+)
+
+// ErrSyncTimeout is returned by Sync when the standby fails to acknowledge
+// the outstanding deltas of a channel in time.
+var ErrSyncTimeout = errors.New("standby failed to acknowledge channel " +
+	"state in time")
+
+// PrimaryConfig houses the resources and parameters required by the primary
+// side of a replication stream.
+type PrimaryConfig struct {
+	// IdentityKey is our identity key, which authenticates us to the
+	// standby.
+	IdentityKey *btcec.PrivateKey
+
+	// StandbyAddr is the host:port the standby listens on.
+	StandbyAddr string
+
+	// StandbyPubKey is the identity key of the standby. Connections to a
+	// host unable to prove ownership of the key are refused.
+	StandbyPubKey *btcec.PublicKey
+
+	// SyncTimeout is the time Sync waits for the standby to acknowledge
+	// the outstanding deltas of a channel.
+	SyncTimeout time.Duration
+}
+
+// pendingDelta is a delta yet to be acknowledged by the standby.
+type pendingDelta struct {
+	seq   uint64
+	delta *channeldb.ReplicatedDelta
+
+	// acked is closed once the standby acknowledges the delta.
+	acked chan struct{}
+}
+
+// Primary streams each committed channel delta to a standby instance, in the
+// order the deltas were committed. Deltas are kept until acknowledged, and
+// resent in full after each reconnection.
+type Primary struct {
+	started int32 // atomic
+	stopped int32 // atomic
+
+	cfg *PrimaryConfig
+
+	mtx     sync.Mutex
+	nextSeq uint64
+	pending []*pendingDelta
+
+	// newDelta is signalled each time a delta is queued.
+	newDelta chan struct{}
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPrimary creates a new Primary streaming deltas to the configured
+// standby.
+func NewPrimary(cfg *PrimaryConfig) *Primary {
+	if cfg.SyncTimeout == 0 {
+		cfg.SyncTimeout = DefaultSyncTimeout
+	}
+
+	return &Primary{
+		cfg:      cfg,
+		newDelta: make(chan struct{}, 1),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Start begins streaming deltas to the standby.
+func (p *Primary) Start() error {
+	if atomic.AddInt32(&p.started, 1) != 1 {
+		return nil
+	}
+
+	p.wg.Add(1)
+	go p.streamer()
+
+	return nil
+}
+
+// Stop signals the primary to exit, blocking until all goroutines have
+// exited. Any deltas not yet acknowledged are lost, though they remain
+// within our own database.
+func (p *Primary) Stop() error {
+	if atomic.AddInt32(&p.stopped, 1) != 1 {
+		return nil
+	}
+
+	close(p.quit)
+	p.wg.Wait()
+
+	return nil
+}
+
+// Replicate queues the passed committed delta to be streamed to the standby.
+// It never blocks, so it may be registered as the DeltaObserver of the
+// channel database. If more than maxPendingDeltas are queued, then the
+// oldest delta is dropped.
+func (p *Primary) Replicate(delta *channeldb.ReplicatedDelta) {
+	p.mtx.Lock()
+	p.nextSeq++
+	p.pending = append(p.pending, &pendingDelta{
+		seq:   p.nextSeq,
+		delta: delta,
+		acked: make(chan struct{}),
+	})
+	if len(p.pending) > maxPendingDeltas {
+		dropped := p.pending[0]
+		log.Errorf("Standby %v has fallen behind, dropping %v delta "+
+			"#%v of ChannelPoint(%v)", p.cfg.StandbyAddr,
+			dropped.delta.Kind, dropped.delta.Delta.UpdateNum,
+			dropped.delta.ChanPoint)
+
+		p.pending[0] = nil
+		p.pending = p.pending[1:]
+	}
+	p.mtx.Unlock()
+
+	select {
+	case p.newDelta <- struct{}{}:
+	default:
+	}
+}
+
+// Sync blocks until the standby has acknowledged each delta of the passed
+// channel queued so far, returning ErrSyncTimeout if it fails to do so
+// within the configured timeout. It must be called before revoking a
+// commitment to the remote party, so the standby never holds a revoked state
+// as its latest.
+func (p *Primary) Sync(chanPoint wire.OutPoint) error {
+	// Deltas are acknowledged in order, so waiting for the most recent
+	// delta of the channel suffices.
+	var last *pendingDelta
+	p.mtx.Lock()
+	for _, pending := range p.pending {
+		if pending.delta.ChanPoint == chanPoint {
+			last = pending
+		}
+	}
+	p.mtx.Unlock()
+
+	if last == nil {
+		return nil
+	}
+
+	select {
+	case <-last.acked:
+		return nil
+	case <-time.After(p.cfg.SyncTimeout):
+		return ErrSyncTimeout
+	case <-p.quit:
+		return ErrSyncTimeout
+	}
+}
+
+// streamer maintains the connection to the standby, reconnecting whenever
+// the connection fails.
+//
+// NOTE: This MUST be run as a goroutine.
+func (p *Primary) streamer() {
+	defer p.wg.Done()
+
+	for {
+		conn, err := p.dial()
+		if err != nil {
+			log.Errorf("Unable to connect to standby %v: %v",
+				p.cfg.StandbyAddr, err)
+		} else {
+			log.Infof("Replicating channel state to standby %v",
+				p.cfg.StandbyAddr)
+
+			err := p.stream(conn)
+			conn.Close()
+			if err == nil {
+				return
+			}
+
+			log.Errorf("Replication stream to standby %v failed: %v",
+				p.cfg.StandbyAddr, err)
+		}
+
+		select {
+		case <-time.After(retryInterval):
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// dial establishes an authenticated connection to the standby.
+func (p *Primary) dial() (net.Conn, error) {
+	conn := lndc.NewConn(nil)
+	err := conn.Dial(p.cfg.IdentityKey, p.cfg.StandbyAddr,
+		p.cfg.StandbyPubKey.SerializeCompressed())
+	if err != nil {
+		if conn.Conn != nil {
+			conn.Conn.Close()
+		}
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// stream sends each pending delta to the standby in turn, waiting for each
+// to be acknowledged before sending the next. A nil error is returned once
+// the primary is stopped.
+func (p *Primary) stream(conn net.Conn) error {
+	// Closing the connection on shutdown unblocks any pending read.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-p.quit:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		next := p.nextPending()
+		if next == nil {
+			return nil
+		}
+
+		// A delta which can't be encoded, or doesn't fit within a
+		// single frame can never be sent, so it's dropped rather than
+		// stalling the stream.
+		record, err := encodeRecord(next.seq, next.delta)
+		if err != nil {
+			log.Errorf("Unable to encode %v delta #%v of "+
+				"ChannelPoint(%v): %v", next.delta.Kind,
+				next.delta.Delta.UpdateNum,
+				next.delta.ChanPoint, err)
+			p.markAcked(next)
+			continue
+		}
+		if len(record) > maxFrameSize {
+			log.Errorf("Unable to send %v delta #%v of "+
+				"ChannelPoint(%v): record of %v bytes exceeds "+
+				"maximum of %v", next.delta.Kind,
+				next.delta.Delta.UpdateNum,
+				next.delta.ChanPoint, len(record), maxFrameSize)
+			p.markAcked(next)
+			continue
+		}
+
+		conn.SetDeadline(time.Now().Add(ackTimeout))
+		if err := writeFrame(conn, record); err != nil {
+			return p.streamErr(err)
+		}
+		payload, err := readFrame(conn)
+		if err != nil {
+			return p.streamErr(err)
+		}
+		seq, err := decodeAck(payload)
+		if err != nil {
+			return err
+		}
+		if seq != next.seq {
+			return errUnexpectedAck(seq, next.seq)
+		}
+
+		log.Tracef("Standby acknowledged %v delta #%v of "+
+			"ChannelPoint(%v)", next.delta.Kind,
+			next.delta.Delta.UpdateNum, next.delta.ChanPoint)
+
+		p.markAcked(next)
+	}
+}
+
+// streamErr returns nil if the primary is shutting down, as the connection
+// was then closed on purpose, and the passed error otherwise.
+func (p *Primary) streamErr(err error) error {
+	select {
+	case <-p.quit:
+		return nil
+	default:
+		return err
+	}
+}
+
+// nextPending returns the oldest delta yet to be acknowledged, blocking until
+// one is queued. Nil is returned once the primary is stopped.
+func (p *Primary) nextPending() *pendingDelta {
+	for {
+		p.mtx.Lock()
+		if len(p.pending) != 0 {
+			next := p.pending[0]
+			p.mtx.Unlock()
+			return next
+		}
+		p.mtx.Unlock()
+
+		select {
+		case <-p.newDelta:
+		case <-p.quit:
+			return nil
+		}
+	}
+}
+
+// markAcked removes the passed delta, which must be the oldest pending
+// delta, and signals its acknowledgement. The delta may have already been
+// dropped from the queue while awaiting acknowledgement, in which case only
+// the acknowledgement is signalled.
+func (p *Primary) markAcked(acked *pendingDelta) {
+	p.mtx.Lock()
+	if len(p.pending) != 0 && p.pending[0] == acked {
+		p.pending[0] = nil
+		p.pending = p.pending[1:]
+	}
+	p.mtx.Unlock()
+
+	close(acked.acked)
+}
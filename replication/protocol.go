@@ -0,0 +1,121 @@
+// Package replication ships the channel state of a node to a cold standby
+// instance, allowing an asset routing node to fail over to the standby
+// without losing, or regressing, the state of its channels.
+//
+// The primary hands each channel delta to the standby once it has been
+// committed to its own database, over a connection authenticated by the
+// identity keys of both nodes. Broadcasting a revoked commitment forfeits the
+// funds of a channel, so the standby must never lag behind a state we've
+// revoked: the primary waits for the standby to acknowledge a new local
+// commitment before sending the revocation of the prior one to the remote
+// party. Should the standby be unreachable, the revocation is withheld, and
+// retransmitted once the peer reconnects.
+package replication
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+)
+
+// maxFrameSize is the largest frame sent over a replication stream. Each
+// frame is sent as a single message over the encrypted connection, so it
+// must fit within a single message.
+const maxFrameSize = 65000
+
+// byteOrder is the byte order used to serialize all integers within the
+// replication protocol.
+var byteOrder = binary.BigEndian
+
+// writeFrame writes the passed payload to w as a single length prefixed
+// frame.
+func writeFrame(w io.Writer, payload []byte) error {
+	if len(payload) > maxFrameSize {
+		return fmt.Errorf("frame of %v bytes exceeds maximum of %v",
+			len(payload), maxFrameSize)
+	}
+
+	frame := make([]byte, 4+len(payload))
+	byteOrder.PutUint32(frame[:4], uint32(len(payload)))
+	copy(frame[4:], payload)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// readFrame reads a single length prefixed frame from r.
+func readFrame(r io.Reader) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+
+	size := byteOrder.Uint32(length[:])
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("frame of %v bytes exceeds maximum of "+
+			"%v", size, maxFrameSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// encodeRecord serializes a replicated delta along with the sequence number
+// assigned to it by the primary.
+func encodeRecord(seq uint64, delta *channeldb.ReplicatedDelta) ([]byte,
+	error) {
+
+	var b bytes.Buffer
+	var scratch [8]byte
+	byteOrder.PutUint64(scratch[:], seq)
+	b.Write(scratch[:])
+
+	if err := delta.Encode(&b); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// decodeRecord deserializes a replicated delta along with its sequence
+// number.
+func decodeRecord(payload []byte) (uint64, *channeldb.ReplicatedDelta,
+	error) {
+
+	if len(payload) < 8 {
+		return 0, nil, fmt.Errorf("record of %v bytes too short",
+			len(payload))
+	}
+
+	delta := &channeldb.ReplicatedDelta{}
+	if err := delta.Decode(bytes.NewReader(payload[8:])); err != nil {
+		return 0, nil, err
+	}
+
+	return byteOrder.Uint64(payload[:8]), delta, nil
+}
+
+// encodeAck serializes the acknowledgement of the record with the passed
+// sequence number.
+func encodeAck(seq uint64) []byte {
+	var ack [8]byte
+	byteOrder.PutUint64(ack[:], seq)
+	return ack[:]
+}
+
+// decodeAck deserializes an acknowledgement, returning the sequence number
+// of the record acknowledged.
+func decodeAck(payload []byte) (uint64, error) {
+	if len(payload) != 8 {
+		return 0, fmt.Errorf("ack of %v bytes, expected 8", len(payload))
+	}
+
+	return byteOrder.Uint64(payload), nil
+}
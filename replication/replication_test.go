@@ -0,0 +1,271 @@
+package replication
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// newTestStandby creates and starts a standby listening on an ephemeral
+// loopback port, accepting the stream of the passed primary key.
+func newTestStandby(t *testing.T, primaryPub *btcec.PublicKey) (*Standby,
+	*btcec.PrivateKey, func()) {
+
+	tempDir, err := ioutil.TempDir("", "standby")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+
+	standbyKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	standby, err := NewStandby(&StandbyConfig{
+		DBPath:        tempDir,
+		ListenAddr:    "127.0.0.1:0",
+		IdentityKey:   standbyKey,
+		PrimaryPubKey: primaryPub,
+	})
+	if err != nil {
+		os.RemoveAll(tempDir)
+		t.Fatalf("unable to create standby: %v", err)
+	}
+	if err := standby.Start(); err != nil {
+		os.RemoveAll(tempDir)
+		t.Fatalf("unable to start standby: %v", err)
+	}
+
+	return standby, standbyKey, func() {
+		standby.Stop()
+		os.RemoveAll(tempDir)
+	}
+}
+
+// newTestDelta returns a local delta of the passed channel and update number.
+func newTestDelta(chanPoint wire.OutPoint,
+	updateNum uint32) *channeldb.ReplicatedDelta {
+
+	commitTx := wire.NewMsgTx()
+	commitTx.AddTxIn(&wire.TxIn{PreviousOutPoint: chanPoint})
+	commitTx.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{0x00}, 22)))
+	commitTx.AddTxOut(wire.NewTxOut(0, []byte{0x6a, 0x01, byte(updateNum)}))
+
+	return &channeldb.ReplicatedDelta{
+		ChanPoint: chanPoint,
+		Kind:      channeldb.LocalDelta,
+		Delta: &channeldb.ChannelDelta{
+			LocalBalance:  btcutil.Amount(5000 - updateNum),
+			RemoteBalance: btcutil.Amount(updateNum),
+			UpdateNum:     updateNum,
+		},
+		CommitTx:  commitTx,
+		CommitSig: bytes.Repeat([]byte{0x30}, 71),
+	}
+}
+
+// assertLatestDelta asserts that the latest local delta held by the standby
+// for the channel of the passed delta matches it.
+func assertLatestDelta(t *testing.T, standby *Standby,
+	expected *channeldb.ReplicatedDelta) {
+
+	latest, err := standby.LatestDelta(&expected.ChanPoint,
+		channeldb.LocalDelta)
+	if err != nil {
+		t.Fatalf("unable to fetch latest delta: %v", err)
+	}
+	if !reflect.DeepEqual(latest.Delta, expected.Delta) ||
+		latest.CommitTx.TxSha() != expected.CommitTx.TxSha() ||
+		!bytes.Equal(latest.CommitSig, expected.CommitSig) {
+
+		t.Fatalf("expected latest delta %v, got %v",
+			spew.Sdump(expected), spew.Sdump(latest))
+	}
+}
+
+// TestReplicationSync tests that Sync only returns once the standby has
+// durably stored each delta of a channel, and that replayed deltas never
+// regress the state held by the standby.
+func TestReplicationSync(t *testing.T) {
+	primaryKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	standby, standbyKey, cleanUp := newTestStandby(t, primaryKey.PubKey())
+	defer cleanUp()
+
+	primary := NewPrimary(&PrimaryConfig{
+		IdentityKey:   primaryKey,
+		StandbyAddr:   standby.Addr().String(),
+		StandbyPubKey: standbyKey.PubKey(),
+		SyncTimeout:   10 * time.Second,
+	})
+	if err := primary.Start(); err != nil {
+		t.Fatalf("unable to start primary: %v", err)
+	}
+	defer primary.Stop()
+
+	chanPoint := wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 1}
+	otherChanPoint := wire.OutPoint{Hash: wire.ShaHash{0x02}, Index: 0}
+
+	// A channel without any outstanding deltas is trivially in sync.
+	if err := primary.Sync(chanPoint); err != nil {
+		t.Fatalf("unable to sync idle channel: %v", err)
+	}
+
+	for i := uint32(1); i <= 3; i++ {
+		primary.Replicate(newTestDelta(chanPoint, i))
+	}
+	primary.Replicate(newTestDelta(otherChanPoint, 1))
+
+	if err := primary.Sync(chanPoint); err != nil {
+		t.Fatalf("unable to sync channel: %v", err)
+	}
+	assertLatestDelta(t, standby, newTestDelta(chanPoint, 3))
+
+	if err := primary.Sync(otherChanPoint); err != nil {
+		t.Fatalf("unable to sync channel: %v", err)
+	}
+	assertLatestDelta(t, standby, newTestDelta(otherChanPoint, 1))
+
+	// Replaying a prior delta, as happens after a reconnection, must be
+	// acknowledged, yet leave the latest state untouched.
+	primary.Replicate(newTestDelta(chanPoint, 2))
+	if err := primary.Sync(chanPoint); err != nil {
+		t.Fatalf("unable to sync replayed delta: %v", err)
+	}
+	assertLatestDelta(t, standby, newTestDelta(chanPoint, 3))
+
+	// Remote deltas are tracked separately from local ones.
+	if _, err := standby.LatestDelta(&chanPoint,
+		channeldb.RemoteDelta); err != ErrUnknownChannel {
+
+		t.Fatalf("expected ErrUnknownChannel, got %v", err)
+	}
+}
+
+// TestReplicationUnauthorizedPrimary tests that the standby refuses the
+// stream of any node other than its configured primary, causing Sync to time
+// out on that node.
+func TestReplicationUnauthorizedPrimary(t *testing.T) {
+	primaryKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	imposterKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	standby, standbyKey, cleanUp := newTestStandby(t, primaryKey.PubKey())
+	defer cleanUp()
+
+	imposter := NewPrimary(&PrimaryConfig{
+		IdentityKey:   imposterKey,
+		StandbyAddr:   standby.Addr().String(),
+		StandbyPubKey: standbyKey.PubKey(),
+		SyncTimeout:   500 * time.Millisecond,
+	})
+	if err := imposter.Start(); err != nil {
+		t.Fatalf("unable to start primary: %v", err)
+	}
+	defer imposter.Stop()
+
+	delta := newTestDelta(wire.OutPoint{Index: 1}, 1)
+	imposter.Replicate(delta)
+	if err := imposter.Sync(delta.ChanPoint); err != ErrSyncTimeout {
+		t.Fatalf("expected ErrSyncTimeout, got %v", err)
+	}
+
+	if _, err := standby.LatestDelta(&delta.ChanPoint,
+		channeldb.LocalDelta); err != ErrUnknownChannel {
+
+		t.Fatalf("expected ErrUnknownChannel, got %v", err)
+	}
+}
+
+// TestReplicationOversizedDelta tests that a delta too large to fit within a
+// single frame is dropped, rather than stalling the deltas queued after it.
+func TestReplicationOversizedDelta(t *testing.T) {
+	primaryKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	standby, standbyKey, cleanUp := newTestStandby(t, primaryKey.PubKey())
+	defer cleanUp()
+
+	primary := NewPrimary(&PrimaryConfig{
+		IdentityKey:   primaryKey,
+		StandbyAddr:   standby.Addr().String(),
+		StandbyPubKey: standbyKey.PubKey(),
+		SyncTimeout:   10 * time.Second,
+	})
+	if err := primary.Start(); err != nil {
+		t.Fatalf("unable to start primary: %v", err)
+	}
+	defer primary.Stop()
+
+	chanPoint := wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 1}
+	otherChanPoint := wire.OutPoint{Hash: wire.ShaHash{0x02}, Index: 0}
+
+	oversized := newTestDelta(chanPoint, 1)
+	oversized.CommitSig = bytes.Repeat([]byte{0x30}, maxFrameSize)
+	primary.Replicate(oversized)
+	primary.Replicate(newTestDelta(otherChanPoint, 1))
+
+	if err := primary.Sync(otherChanPoint); err != nil {
+		t.Fatalf("unable to sync channel: %v", err)
+	}
+	assertLatestDelta(t, standby, newTestDelta(otherChanPoint, 1))
+
+	if _, err := standby.LatestDelta(&chanPoint,
+		channeldb.LocalDelta); err != ErrUnknownChannel {
+
+		t.Fatalf("expected ErrUnknownChannel, got %v", err)
+	}
+}
+
+// TestReplicationPendingLimit tests that once more than maxPendingDeltas are
+// awaiting acknowledgement, the oldest deltas are dropped.
+func TestReplicationPendingLimit(t *testing.T) {
+	primary := NewPrimary(&PrimaryConfig{})
+
+	chanPoint := wire.OutPoint{Hash: wire.ShaHash{0x01}, Index: 1}
+	for i := uint32(1); i <= maxPendingDeltas+2; i++ {
+		primary.Replicate(newTestDelta(chanPoint, i))
+	}
+
+	if len(primary.pending) != maxPendingDeltas {
+		t.Fatalf("expected %v pending deltas, got %v",
+			maxPendingDeltas, len(primary.pending))
+	}
+	if primary.pending[0].seq != 3 {
+		t.Fatalf("expected oldest pending delta #3, got #%v",
+			primary.pending[0].seq)
+	}
+
+	// An in-flight delta dropped from the queue may still be
+	// acknowledged, leaving the queue untouched.
+	dropped := &pendingDelta{acked: make(chan struct{})}
+	primary.markAcked(dropped)
+	if len(primary.pending) != maxPendingDeltas {
+		t.Fatalf("expected %v pending deltas, got %v",
+			maxPendingDeltas, len(primary.pending))
+	}
+	select {
+	case <-dropped.acked:
+	default:
+		t.Fatalf("acknowledgement of dropped delta not signalled")
+	}
+}
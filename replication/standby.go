@@ -0,0 +1,355 @@
+package replication
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lndc"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// standbyDBName is the name of the database file the standby stores all
+// replicated deltas within.
+const standbyDBName = "standby.db"
+
+var (
+	// channelsBucket houses a sub-bucket for each replicated channel,
+	// keyed by its serialized funding outpoint. Each sub-bucket stores
+	// the channel's deltas keyed by: kind || updateNum, along with the
+	// update number of the latest delta of each kind.
+	channelsBucket = []byte("channels")
+
+	// latestKeyPrefix prefixes the key of the latest update number of
+	// each kind of delta within a channel's bucket.
+	latestKeyPrefix = []byte("latest")
+
+	// ErrUnknownChannel is returned when querying the state of a channel
+	// of which no delta has been replicated.
+	ErrUnknownChannel = fmt.Errorf("no replicated state for channel")
+)
+
+// errUnexpectedAck returns the error of an acknowledgement of another delta
+// than the one sent.
+func errUnexpectedAck(seq, expected uint64) error {
+	return fmt.Errorf("standby acknowledged delta %v, expected %v", seq,
+		expected)
+}
+
+// StandbyConfig houses the resources and parameters required by the standby
+// side of a replication stream.
+type StandbyConfig struct {
+	// DBPath is the directory in which the standby's database is stored.
+	DBPath string
+
+	// ListenAddr is the address the standby listens on for the primary.
+	ListenAddr string
+
+	// IdentityKey is the standby's identity key, which authenticates it
+	// to the primary.
+	IdentityKey *btcec.PrivateKey
+
+	// PrimaryPubKey is the identity key of the primary. Connections
+	// authenticated by any other key are refused.
+	PrimaryPubKey *btcec.PublicKey
+}
+
+// Standby accepts the channel deltas streamed by a primary instance, and
+// stores them until they're needed to fail over. Deltas are only ever
+// stored in increasing order of their update number, so a delta replayed
+// after a reconnection can't regress the state of a channel.
+//
+// TODO: apply the replicated deltas to a channel database seeded with a copy
+// of the primary's, once promoted.
+type Standby struct {
+	started int32 // atomic
+	stopped int32 // atomic
+
+	cfg *StandbyConfig
+
+	db       *bolt.DB
+	listener net.Listener
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewStandby creates a new standby, opening, or creating its database within
+// the configured directory.
+func NewStandby(cfg *StandbyConfig) (*Standby, error) {
+	if err := os.MkdirAll(cfg.DBPath, 0700); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(cfg.DBPath, standbyDBName), 0600,
+		nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(channelsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Standby{
+		cfg:  cfg,
+		db:   db,
+		quit: make(chan struct{}),
+	}, nil
+}
+
+// Start begins accepting the replication stream of the primary.
+func (s *Standby) Start() error {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return nil
+	}
+
+	listener, err := lndc.NewListener(s.cfg.IdentityKey, s.cfg.ListenAddr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
+	log.Infof("Standby listening for primary on %v", listener.Addr())
+
+	s.wg.Add(1)
+	go s.acceptConns()
+
+	return nil
+}
+
+// Stop signals the standby to exit, blocking until all goroutines have
+// exited.
+func (s *Standby) Stop() error {
+	if atomic.AddInt32(&s.stopped, 1) != 1 {
+		return nil
+	}
+
+	close(s.quit)
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.wg.Wait()
+
+	return s.db.Close()
+}
+
+// Addr returns the address the standby is listening on.
+func (s *Standby) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// acceptConns accepts connections from the primary until the standby is
+// stopped.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *Standby) acceptConns() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+				return
+			default:
+			}
+
+			// The handshake of a single connection failing
+			// doesn't affect the listener itself.
+			log.Errorf("Unable to accept connection: %v", err)
+			continue
+		}
+
+		remotePub := conn.(*lndc.LNDConn).RemotePub
+		if !remotePub.IsEqual(s.cfg.PrimaryPubKey) {
+			log.Warnf("Refusing replication stream from "+
+				"unauthorized node %x at %v",
+				remotePub.SerializeCompressed(),
+				conn.RemoteAddr())
+			conn.Close()
+			continue
+		}
+
+		s.wg.Add(1)
+		go s.handleConn(conn)
+	}
+}
+
+// handleConn stores, and acknowledges each delta streamed by the primary
+// over the passed connection.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *Standby) handleConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer conn.Close()
+
+	// Closing the connection on shutdown unblocks any pending read.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-s.quit:
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	log.Infof("Accepted replication stream from primary at %v",
+		conn.RemoteAddr())
+
+	for {
+		payload, err := readFrame(conn)
+		if err != nil {
+			log.Infof("Replication stream from %v closed: %v",
+				conn.RemoteAddr(), err)
+			return
+		}
+
+		seq, delta, err := decodeRecord(payload)
+		if err != nil {
+			log.Errorf("Invalid record from primary: %v", err)
+			return
+		}
+
+		if err := s.storeDelta(delta); err != nil {
+			log.Errorf("Unable to store %v delta #%v of "+
+				"ChannelPoint(%v): %v", delta.Kind,
+				delta.Delta.UpdateNum, delta.ChanPoint, err)
+			return
+		}
+
+		if err := writeFrame(conn, encodeAck(seq)); err != nil {
+			log.Errorf("Unable to acknowledge delta: %v", err)
+			return
+		}
+	}
+}
+
+// storeDelta stores the passed delta, unless a delta of the same kind with
+// an equal, or greater update number has already been stored, in which case
+// it's a replay and ignored.
+func (s *Standby) storeDelta(delta *channeldb.ReplicatedDelta) error {
+	var record bytes.Buffer
+	if err := delta.Encode(&record); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		chanKey, err := outpointKey(&delta.ChanPoint)
+		if err != nil {
+			return err
+		}
+		chanBucket, err := tx.Bucket(channelsBucket).
+			CreateBucketIfNotExists(chanKey)
+		if err != nil {
+			return err
+		}
+
+		updateNum := delta.Delta.UpdateNum
+		latestKey := latestDeltaKey(delta.Kind)
+		if latest := chanBucket.Get(latestKey); latest != nil {
+			latestNum := byteOrder.Uint32(latest)
+			if updateNum <= latestNum {
+				log.Debugf("Ignoring replayed %v delta #%v of "+
+					"ChannelPoint(%v)", delta.Kind,
+					updateNum, delta.ChanPoint)
+				return nil
+			}
+			if updateNum != latestNum+1 {
+				log.Warnf("Missing %v deltas #%v to #%v of "+
+					"ChannelPoint(%v)", delta.Kind,
+					latestNum+1, updateNum-1,
+					delta.ChanPoint)
+			}
+		}
+
+		key := deltaKey(delta.Kind, updateNum)
+		if err := chanBucket.Put(key, record.Bytes()); err != nil {
+			return err
+		}
+
+		return chanBucket.Put(latestKey, key[1:])
+	})
+}
+
+// LatestDelta returns the most recent delta of the passed kind replicated
+// for the passed channel.
+func (s *Standby) LatestDelta(chanPoint *wire.OutPoint,
+	kind channeldb.DeltaKind) (*channeldb.ReplicatedDelta, error) {
+
+	chanKey, err := outpointKey(chanPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := &channeldb.ReplicatedDelta{}
+	err = s.db.View(func(tx *bolt.Tx) error {
+		chanBucket := tx.Bucket(channelsBucket).Bucket(chanKey)
+		if chanBucket == nil {
+			return ErrUnknownChannel
+		}
+
+		latest := chanBucket.Get(latestDeltaKey(kind))
+		if latest == nil {
+			return ErrUnknownChannel
+		}
+
+		record := chanBucket.Get(deltaKey(kind, byteOrder.Uint32(latest)))
+		if record == nil {
+			return ErrUnknownChannel
+		}
+
+		return delta.Decode(bytes.NewReader(record))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return delta, nil
+}
+
+// deltaKey returns the key of a delta within its channel's bucket.
+func deltaKey(kind channeldb.DeltaKind, updateNum uint32) []byte {
+	key := make([]byte, 5)
+	key[0] = byte(kind)
+	byteOrder.PutUint32(key[1:], updateNum)
+	return key
+}
+
+// latestDeltaKey returns the key of the update number of the latest delta of
+// the passed kind within a channel's bucket.
+func latestDeltaKey(kind channeldb.DeltaKind) []byte {
+	key := make([]byte, len(latestKeyPrefix)+1)
+	copy(key, latestKeyPrefix)
+	key[len(latestKeyPrefix)] = byte(kind)
+	return key
+}
+
+// outpointKey returns the key of the passed channel's bucket.
+func outpointKey(o *wire.OutPoint) ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := b.Write(o.Hash[:]); err != nil {
+		return nil, err
+	}
+
+	var index [4]byte
+	byteOrder.PutUint32(index[:], o.Index)
+	if _, err := b.Write(index[:]); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,420 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+var (
+	// ErrNoRoute is returned when no route satisfying the requested asset
+	// and amount exists between two nodes.
+	ErrNoRoute = errors.New("unable to find a route to destination")
+
+	// ErrUnknownChannel is returned when an operation targets a channel
+	// which isn't within the graph.
+	ErrUnknownChannel = errors.New("channel not found in graph")
+)
+
+// NodeID is the lightning ID of a node within the graph: the sha256 of its
+// identity public key.
+type NodeID [32]byte
+
+// ChannelEdge is a channel between two nodes within the graph. Each channel
+// is denominated in a single asset, so an HTLC of an asset can only be
+// carried by channels of the same asset.
+type ChannelEdge struct {
+	// ChanPoint is the outpoint of the channel's funding transaction.
+	ChanPoint wire.OutPoint
+
+	// Node1 and Node2 are the two endpoints of the channel.
+	Node1 NodeID
+	Node2 NodeID
+
+	// AssetID is the identifier of the asset the channel is denominated
+	// in. An empty AssetID denotes a channel paying satoshis.
+	AssetID string
+
+	// Capacity is the total amount of the asset held within the channel.
+	Capacity btcutil.Amount
+
+	// FeeBase is the fixed fee, denominated in the channel's asset,
+	// charged for forwarding an HTLC over the channel.
+	FeeBase btcutil.Amount
+
+	// FeeRate is the proportional fee, in millionths of the amount
+	// forwarded, charged for forwarding an HTLC over the channel.
+	FeeRate uint32
+
+	// CarrierFee is the number of satoshis charged for forwarding an HTLC
+	// over the channel, covering the dust output carrying the HTLC's
+	// asset.
+	CarrierFee btcutil.Amount
+
+	// TimeLockDelta is the minimum number of blocks required by the node
+	// forwarding an HTLC over the channel between the expiry of the
+	// incoming HTLC, and the expiry of the HTLC it forwards.
+	TimeLockDelta uint32
+}
+
+// Fee returns the fee, denominated in the channel's asset, charged for
+// forwarding amt over the channel.
+func (c *ChannelEdge) Fee(amt btcutil.Amount) btcutil.Amount {
+	return c.FeeBase + amt*btcutil.Amount(c.FeeRate)/1000000
+}
+
+// other returns the endpoint of the channel opposite to the passed node.
+func (c *ChannelEdge) other(node NodeID) NodeID {
+	if c.Node1 == node {
+		return c.Node2
+	}
+	return c.Node1
+}
+
+// Hop is a single hop within a route.
+type Hop struct {
+	// Channel is the channel the HTLC is sent over.
+	Channel *ChannelEdge
+
+	// NextNode is the node receiving the HTLC at the end of this hop.
+	NextNode NodeID
+
+	// AssetID is the asset the HTLC sent over this hop is denominated in.
+	AssetID string
+
+	// Amount is the amount of the asset sent over this hop, including the
+	// fees charged by all following hops.
+	Amount btcutil.Amount
+}
+
+// Route is a path through the graph from a source to a destination node.
+// Consecutive hops of different assets are joined by a node which swaps the
+// incoming asset for the outgoing asset.
+type Route struct {
+	Hops []*Hop
+}
+
+// CarrierFees returns the total number of satoshis charged by the forwarding
+// nodes along the route for the dust outputs carrying the payment's asset.
+func (r *Route) CarrierFees() btcutil.Amount {
+	var fees btcutil.Amount
+	for i, hop := range r.Hops {
+		// The source doesn't charge itself for the first hop.
+		if i == 0 {
+			continue
+		}
+		fees += hop.Channel.CarrierFee
+	}
+	return fees
+}
+
+// HopExpiries returns the absolute expiry height of the HTLC sent over each
+// hop of the route, given the current height, and the number of blocks the
+// final node is given to settle the HTLC. Each forwarding node is granted the
+// TimeLockDelta it requires for the channel it forwards over, so the
+// expiries decrease along the route.
+func (r *Route) HopExpiries(height, finalDelta uint32) []uint32 {
+	expiries := make([]uint32, len(r.Hops))
+	expiry := height + finalDelta
+	for i := len(r.Hops) - 1; i >= 0; i-- {
+		expiries[i] = expiry
+
+		// The source doesn't require a delta of itself for the first
+		// hop.
+		if i > 0 {
+			expiry += r.Hops[i].Channel.TimeLockDelta
+		}
+	}
+
+	return expiries
+}
+
+// String returns a human readable version of the Route.
+func (r *Route) String() string {
+	s := ""
+	for i, hop := range r.Hops {
+		if i != 0 {
+			s += " -> "
+		}
+		s += fmt.Sprintf("%v(%v of %q)", hop.Channel.ChanPoint,
+			hop.Amount, hop.AssetID)
+	}
+	return s
+}
+
+// assetPair is a directed pair of assets a node is willing to swap between.
+type assetPair struct {
+	from string
+	to   string
+}
+
+// Graph tracks all known channels, tagged with the asset they're denominated
+// in, along with the nodes willing to swap between assets. Routes are
+// constrained such that each hop is carried by a channel of the asset being
+// sent, only switching assets at a node which quotes a swap rate.
+type Graph struct {
+	sync.RWMutex
+
+	channels  map[wire.OutPoint]*ChannelEdge
+	nodeChans map[NodeID]map[wire.OutPoint]*ChannelEdge
+
+	// swapRates maps a node to the rates it quotes. A rate is the number
+	// of units of the outgoing asset forwarded for each unit of the
+	// incoming asset received.
+	swapRates map[NodeID]map[assetPair]float64
+}
+
+// NewGraph returns a new empty Graph.
+func NewGraph() *Graph {
+	return &Graph{
+		channels:  make(map[wire.OutPoint]*ChannelEdge),
+		nodeChans: make(map[NodeID]map[wire.OutPoint]*ChannelEdge),
+		swapRates: make(map[NodeID]map[assetPair]float64),
+	}
+}
+
+// AddChannel adds the channel to the graph, replacing any existing channel
+// with the same channel point.
+func (g *Graph) AddChannel(edge *ChannelEdge) error {
+	if edge.Node1 == edge.Node2 {
+		return fmt.Errorf("channel %v has identical endpoints",
+			edge.ChanPoint)
+	}
+	if edge.Capacity <= 0 {
+		return fmt.Errorf("channel %v has invalid capacity %v",
+			edge.ChanPoint, edge.Capacity)
+	}
+
+	g.Lock()
+	defer g.Unlock()
+
+	g.removeChannel(edge.ChanPoint)
+
+	g.channels[edge.ChanPoint] = edge
+	for _, node := range []NodeID{edge.Node1, edge.Node2} {
+		chans, ok := g.nodeChans[node]
+		if !ok {
+			chans = make(map[wire.OutPoint]*ChannelEdge)
+			g.nodeChans[node] = chans
+		}
+		chans[edge.ChanPoint] = edge
+	}
+
+	return nil
+}
+
+// RemoveChannel removes the target channel from the graph.
+func (g *Graph) RemoveChannel(chanPoint wire.OutPoint) error {
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.channels[chanPoint]; !ok {
+		return ErrUnknownChannel
+	}
+	g.removeChannel(chanPoint)
+
+	return nil
+}
+
+// removeChannel removes the target channel, if it exists.
+//
+// NOTE: This method MUST be called with the graph's mutex held.
+func (g *Graph) removeChannel(chanPoint wire.OutPoint) {
+	edge, ok := g.channels[chanPoint]
+	if !ok {
+		return
+	}
+
+	delete(g.channels, chanPoint)
+	for _, node := range []NodeID{edge.Node1, edge.Node2} {
+		delete(g.nodeChans[node], chanPoint)
+		if len(g.nodeChans[node]) == 0 {
+			delete(g.nodeChans, node)
+		}
+	}
+}
+
+// Channel returns the target channel.
+func (g *Graph) Channel(chanPoint wire.OutPoint) (*ChannelEdge, error) {
+	g.RLock()
+	defer g.RUnlock()
+
+	edge, ok := g.channels[chanPoint]
+	if !ok {
+		return nil, ErrUnknownChannel
+	}
+
+	return edge, nil
+}
+
+// Channels returns all channels within the graph.
+func (g *Graph) Channels() []*ChannelEdge {
+	g.RLock()
+	defer g.RUnlock()
+
+	edges := make([]*ChannelEdge, 0, len(g.channels))
+	for _, edge := range g.channels {
+		edges = append(edges, edge)
+	}
+
+	return edges
+}
+
+// SetSwapRate records that the node forwards rate units of toAsset for each
+// unit of fromAsset received. A non-positive rate removes the quote.
+func (g *Graph) SetSwapRate(node NodeID, fromAsset, toAsset string,
+	rate float64) {
+
+	g.Lock()
+	defer g.Unlock()
+
+	pair := assetPair{fromAsset, toAsset}
+	if rate <= 0 {
+		delete(g.swapRates[node], pair)
+		return
+	}
+
+	rates, ok := g.swapRates[node]
+	if !ok {
+		rates = make(map[assetPair]float64)
+		g.swapRates[node] = rates
+	}
+	rates[pair] = rate
+}
+
+// routeState is a vertex within the search for a route: a node, along with
+// the asset, and amount of the HTLC it must forward onwards to reach the
+// destination.
+type routeState struct {
+	node    NodeID
+	assetID string
+	amt     btcutil.Amount
+
+	// hop is the hop taken from this node towards the destination, or nil
+	// if the node instead swaps assets. next is the state following this
+	// one, and is only nil for the destination itself.
+	hop  *Hop
+	next *routeState
+}
+
+// FindRoute returns the route with the fewest hops and swaps from source to
+// target, which delivers amt of dstAsset to the target, while sending
+// srcAsset from the source. If the two assets differ, then the route must
+// cross at least one node which swaps between assets. Each hop is carried by
+// a channel of the asset sent over it, with sufficient capacity for the
+// amount sent, including the fees charged by each forwarding node for the
+// channel it forwards over.
+func (g *Graph) FindRoute(source, target NodeID, srcAsset, dstAsset string,
+	amt btcutil.Amount) (*Route, error) {
+
+	if amt <= 0 {
+		return nil, fmt.Errorf("invalid amount %v", amt)
+	}
+
+	g.RLock()
+	defer g.RUnlock()
+
+	type vertex struct {
+		node    NodeID
+		assetID string
+	}
+
+	// As the amount to be delivered is fixed, the search starts at the
+	// target, and proceeds backwards towards the source, computing the
+	// amount each node must receive along the way.
+	start := &routeState{node: target, assetID: dstAsset, amt: amt}
+	visited := map[vertex]struct{}{
+		{target, dstAsset}: {},
+	}
+	queue := []*routeState{start}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		if state.node == source && state.assetID == srcAsset {
+			return state.route(), nil
+		}
+
+		var candidates []*routeState
+
+		// If this node forwards the HTLC over a channel, then it must
+		// receive the forwarded amount along with the fee it charges
+		// for that channel, whether or not it swaps assets first.
+		amtIn := state.amt
+		if state.hop != nil {
+			amtIn += state.hop.Channel.Fee(state.amt)
+		}
+
+		// The HTLC may reach this node over any channel of the same
+		// asset with sufficient capacity.
+		for _, edge := range g.nodeChans[state.node] {
+			if edge.AssetID != state.assetID ||
+				edge.Capacity < amtIn {
+				continue
+			}
+
+			prev := edge.other(state.node)
+			candidates = append(candidates, &routeState{
+				node:    prev,
+				assetID: state.assetID,
+				amt:     amtIn,
+				hop: &Hop{
+					Channel:  edge,
+					NextNode: state.node,
+					AssetID:  state.assetID,
+					Amount:   amtIn,
+				},
+				next: state,
+			})
+		}
+
+		// Alternatively, if this node swaps some other asset for the
+		// asset it must forward, then it may instead receive the
+		// other asset. The amount to be received is rounded up, as
+		// the node rounds down when converting.
+		for pair, rate := range g.swapRates[state.node] {
+			if pair.to != state.assetID {
+				continue
+			}
+
+			needed := math.Ceil(float64(amtIn) / rate)
+			if needed > math.MaxInt64 {
+				continue
+			}
+			candidates = append(candidates, &routeState{
+				node:    state.node,
+				assetID: pair.from,
+				amt:     btcutil.Amount(needed),
+				next:    state,
+			})
+		}
+
+		for _, candidate := range candidates {
+			v := vertex{candidate.node, candidate.assetID}
+			if _, ok := visited[v]; ok {
+				continue
+			}
+			visited[v] = struct{}{}
+
+			queue = append(queue, candidate)
+		}
+	}
+
+	return nil, ErrNoRoute
+}
+
+// route assembles the route from this state to the destination.
+func (s *routeState) route() *Route {
+	route := &Route{}
+	for state := s; state != nil; state = state.next {
+		if state.hop != nil {
+			route.Hops = append(route.Hops, state.hop)
+		}
+	}
+
+	return route
+}
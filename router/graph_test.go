@@ -0,0 +1,201 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+	"github.com/roasbeef/btcutil"
+)
+
+// newEdge returns a fee-free channel of the asset between the two nodes.
+func newEdge(index uint32, node1, node2 NodeID, assetID string,
+	capacity btcutil.Amount) *ChannelEdge {
+
+	return &ChannelEdge{
+		ChanPoint: wire.OutPoint{Index: index},
+		Node1:     node1,
+		Node2:     node2,
+		AssetID:   assetID,
+		Capacity:  capacity,
+	}
+}
+
+// TestFindRoute tests that routes only traverse channels of the asset being
+// sent with sufficient capacity, switching assets only at swap-capable nodes.
+func TestFindRoute(t *testing.T) {
+	alice, bob, carol, dave := NodeID{1}, NodeID{2}, NodeID{3}, NodeID{4}
+
+	g := NewGraph()
+	edges := []*ChannelEdge{
+		// alice -> bob -> carol in assetA.
+		newEdge(1, alice, bob, "assetA", 1000),
+		newEdge(2, bob, carol, "assetA", 500),
+
+		// A direct assetB channel between alice and carol which is
+		// unusable for assetA payments.
+		newEdge(3, alice, carol, "assetB", 5000),
+
+		// carol -> dave in assetB.
+		newEdge(4, carol, dave, "assetB", 2000),
+	}
+	for _, edge := range edges {
+		if err := g.AddChannel(edge); err != nil {
+			t.Fatalf("unable to add channel: %v", err)
+		}
+	}
+
+	route, err := g.FindRoute(alice, carol, "assetA", "assetA", 400)
+	if err != nil {
+		t.Fatalf("unable to find route: %v", err)
+	}
+	if len(route.Hops) != 2 || route.Hops[0].NextNode != bob ||
+		route.Hops[1].NextNode != carol {
+		t.Fatalf("unexpected route: %v", route)
+	}
+
+	// bob -> carol lacks the capacity for a larger payment.
+	if _, err := g.FindRoute(alice, carol, "assetA", "assetA", 600); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute, got %v", err)
+	}
+
+	// Without a swap-capable node, assetA can't reach dave.
+	if _, err := g.FindRoute(alice, dave, "assetA", "assetB", 100); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute, got %v", err)
+	}
+
+	// Once carol swaps assetA for assetB, the payment can cross assets.
+	// 100 of assetB at a rate of 2.5 requires 40 of assetA.
+	g.SetSwapRate(carol, "assetA", "assetB", 2.5)
+	route, err = g.FindRoute(alice, dave, "assetA", "assetB", 100)
+	if err != nil {
+		t.Fatalf("unable to find route: %v", err)
+	}
+	if len(route.Hops) != 3 {
+		t.Fatalf("expected 3 hops, got %v", route)
+	}
+	expected := []struct {
+		assetID string
+		amt     int64
+	}{
+		{"assetA", 40}, {"assetA", 40}, {"assetB", 100},
+	}
+	for i, hop := range route.Hops {
+		if hop.AssetID != expected[i].assetID ||
+			int64(hop.Amount) != expected[i].amt {
+			t.Fatalf("hop #%v: expected %v of %q, got %v of %q", i,
+				expected[i].amt, expected[i].assetID,
+				hop.Amount, hop.AssetID)
+		}
+	}
+
+	// Removing a channel along the route should render it unusable.
+	if err := g.RemoveChannel(wire.OutPoint{Index: 2}); err != nil {
+		t.Fatalf("unable to remove channel: %v", err)
+	}
+	if _, err := g.FindRoute(alice, dave, "assetA", "assetB", 100); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute, got %v", err)
+	}
+	if err := g.RemoveChannel(wire.OutPoint{Index: 2}); err != ErrUnknownChannel {
+		t.Fatalf("expected ErrUnknownChannel, got %v", err)
+	}
+}
+
+// TestFindRouteFees tests that each forwarding node along a route is paid the
+// fee it charges for the channel it forwards over, including when it swaps
+// assets, and that the fees count towards the capacity required of earlier
+// channels.
+func TestFindRouteFees(t *testing.T) {
+	alice, bob, carol, dave := NodeID{1}, NodeID{2}, NodeID{3}, NodeID{4}
+
+	g := NewGraph()
+	aliceBob := newEdge(1, alice, bob, "assetA", 1000)
+	bobCarol := newEdge(2, bob, carol, "assetA", 1000)
+	bobCarol.FeeBase = 5
+	bobCarol.FeeRate = 10000
+	bobCarol.CarrierFee = 600
+	carolDave := newEdge(3, carol, dave, "assetB", 1000)
+	carolDave.FeeBase = 10
+	carolDave.CarrierFee = 400
+	for _, edge := range []*ChannelEdge{aliceBob, bobCarol, carolDave} {
+		if err := g.AddChannel(edge); err != nil {
+			t.Fatalf("unable to add channel: %v", err)
+		}
+	}
+
+	// bob charges 5 + 1% of the 200 forwarded to carol.
+	route, err := g.FindRoute(alice, carol, "assetA", "assetA", 200)
+	if err != nil {
+		t.Fatalf("unable to find route: %v", err)
+	}
+	if len(route.Hops) != 2 || route.Hops[0].Amount != 207 ||
+		route.Hops[1].Amount != 200 {
+		t.Fatalf("unexpected route: %v", route)
+	}
+	if route.CarrierFees() != 600 {
+		t.Fatalf("expected carrier fees of 600, got %v",
+			route.CarrierFees())
+	}
+
+	// carol swaps assetA for assetB at a rate of 2, so must receive
+	// (100 + 10) / 2 = 55 of assetA to forward 100 of assetB to dave. bob
+	// then charges 5 + 1% of the 55 forwarded.
+	g.SetSwapRate(carol, "assetA", "assetB", 2)
+	route, err = g.FindRoute(alice, dave, "assetA", "assetB", 100)
+	if err != nil {
+		t.Fatalf("unable to find route: %v", err)
+	}
+	expected := []btcutil.Amount{60, 55, 100}
+	if len(route.Hops) != len(expected) {
+		t.Fatalf("expected %v hops, got %v", len(expected), route)
+	}
+	for i, hop := range route.Hops {
+		if hop.Amount != expected[i] {
+			t.Fatalf("hop #%v: expected %v, got %v", i,
+				expected[i], hop.Amount)
+		}
+	}
+	if route.CarrierFees() != 1000 {
+		t.Fatalf("expected carrier fees of 1000, got %v",
+			route.CarrierFees())
+	}
+
+	// The fees push the amount over alice's channel to bob beyond its
+	// capacity.
+	if _, err := g.FindRoute(alice, carol, "assetA", "assetA", 990); err != ErrNoRoute {
+		t.Fatalf("expected ErrNoRoute, got %v", err)
+	}
+}
+
+// TestHopExpiries tests that each forwarding node along a route is granted
+// the time lock delta it requires for the channel it forwards over.
+func TestHopExpiries(t *testing.T) {
+	alice, bob, carol, dave := NodeID{1}, NodeID{2}, NodeID{3}, NodeID{4}
+
+	aliceBob := newEdge(1, alice, bob, "assetA", 1000)
+	aliceBob.TimeLockDelta = 5
+	bobCarol := newEdge(2, bob, carol, "assetA", 1000)
+	bobCarol.TimeLockDelta = 40
+	carolDave := newEdge(3, carol, dave, "assetA", 1000)
+	carolDave.TimeLockDelta = 144
+	route := &Route{Hops: []*Hop{
+		{Channel: aliceBob, NextNode: bob},
+		{Channel: bobCarol, NextNode: carol},
+		{Channel: carolDave, NextNode: dave},
+	}}
+
+	// dave is given 9 blocks, carol requires 144 blocks to forward to
+	// dave, and bob 40 blocks to forward to carol. alice's own delta for
+	// the first hop isn't charged.
+	expected := []uint32{1193, 1153, 1009}
+	expiries := route.HopExpiries(1000, 9)
+	if len(expiries) != len(expected) {
+		t.Fatalf("expected %v expiries, got %v", len(expected),
+			len(expiries))
+	}
+	for i, expiry := range expiries {
+		if expiry != expected[i] {
+			t.Fatalf("hop #%v: expected expiry %v, got %v", i,
+				expected[i], expiry)
+		}
+	}
+}
@@ -1,17 +1,24 @@
 package main
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"io"
 
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/btcsuite/fastsha256"
+	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lndc"
+	"github.com/lightningnetwork/lnd/lndcc"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
 	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/roasbeef/btcd/btcec"
 	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcd/wire"
 	"github.com/roasbeef/btcutil"
@@ -23,6 +30,10 @@ var (
 	defaultAccount uint32 = waddrmgr.DefaultAccountNum
 )
 
+// defaultEstimateFeeRate is the fee rate, in sat/byte, quoted by EstimateTx
+// if none is given, matching the fee rate paid by funding transactions.
+const defaultEstimateFeeRate = 10
+
 // rpcServer is a gRPC, RPC front end to the lnd daemon.
 type rpcServer struct {
 	started  int32 // To be used atomically.
@@ -30,6 +41,10 @@ type rpcServer struct {
 
 	server *server
 
+	// htlcHolder holds incoming HTLCs until they're resolved via the
+	// ResolveHTLC RPC, if lnd was started with holdhtlcs.
+	htlcHolder *htlcHolder
+
 	wg sync.WaitGroup
 
 	quit chan struct{}
@@ -41,7 +56,11 @@ var _ lnrpc.LightningServer = (*rpcServer)(nil)
 
 // newRpcServer creates and returns a new instance of the rpcServer.
 func newRpcServer(s *server) *rpcServer {
-	return &rpcServer{server: s, quit: make(chan struct{}, 1)}
+	return &rpcServer{
+		server:     s,
+		htlcHolder: newHTLCHolder(),
+		quit:       make(chan struct{}, 1),
+	}
 }
 
 // Start launches any helper goroutines required for the rpcServer
@@ -257,7 +276,31 @@ func (r *rpcServer) CloseChannel(in *lnrpc.CloseChannelRequest,
 	rpcsLog.Tracef("[closechannel] request for ChannelPoint(%v)",
 		targetChannelPoint)
 
-	updateChan, errChan := r.server.htlcSwitch.CloseLink(targetChannelPoint, force)
+	// If a delivery address was specified, then our settled balance is
+	// sent to it rather than to the address negotiated during funding.
+	var deliveryScript []byte
+	if in.DeliveryAddress != "" {
+		if force {
+			return fmt.Errorf("delivery address can't be overridden " +
+				"for a force closure")
+		}
+
+		addr, err := btcutil.DecodeAddress(in.DeliveryAddress,
+			activeNetParams.Params)
+		if err != nil {
+			return err
+		}
+		deliveryScript, err = txscript.PayToAddrScript(addr)
+		if err != nil {
+			return err
+		}
+		if err := lnwallet.ValidateDeliveryScript(deliveryScript); err != nil {
+			return err
+		}
+	}
+
+	updateChan, errChan := r.server.htlcSwitch.CloseLink(targetChannelPoint,
+		force, deliveryScript)
 
 out:
 	for {
@@ -416,15 +459,19 @@ func (r *rpcServer) PendingChannels(ctx context.Context,
 	if includeOpen {
 		pendingOpenChans := r.server.fundingMgr.PendingChannels()
 		for _, pendingOpen := range pendingOpenChans {
+			rpcsLog.Debugf("[pendingchannels] peer=%v, state=%v",
+				pendingOpen.peerId, pendingOpen.state)
+
 			// TODO(roasbeef): add confirmation progress
 			pendingChan := &lnrpc.PendingChannelResponse_PendingChannel{
-				PeerId:        pendingOpen.peerId,
-				LightningId:   hex.EncodeToString(pendingOpen.lightningID[:]),
-				ChannelPoint:  pendingOpen.channelPoint.String(),
-				Capacity:      int64(pendingOpen.capacity),
-				LocalBalance:  int64(pendingOpen.localBalance),
-				RemoteBalance: int64(pendingOpen.remoteBalance),
-				Status:        lnrpc.ChannelStatus_OPENING,
+				PeerId:           pendingOpen.peerId,
+				LightningId:      hex.EncodeToString(pendingOpen.lightningID[:]),
+				ChannelPoint:     pendingOpen.channelPoint.String(),
+				Capacity:         int64(pendingOpen.capacity),
+				LocalBalance:     int64(pendingOpen.localBalance),
+				RemoteBalance:    int64(pendingOpen.remoteBalance),
+				Status:           lnrpc.ChannelStatus_OPENING,
+				ReservationState: pendingOpen.state.String(),
 			}
 			pendingChannels = append(pendingChannels, pendingChan)
 		}
@@ -519,3 +566,558 @@ func (r *rpcServer) ShowRoutingTable(ctx context.Context,
 		Channels: channels,
 	}, nil
 }
+
+// ListHeldHTLCs returns all incoming HTLCs currently held awaiting their
+// resolution via ResolveHTLC.
+func (r *rpcServer) ListHeldHTLCs(ctx context.Context,
+	in *lnrpc.ListHeldHTLCsRequest) (*lnrpc.ListHeldHTLCsResponse, error) {
+
+	rpcsLog.Debugf("[listheldhtlcs]")
+
+	heldHTLCs := r.htlcHolder.heldHTLCs()
+	htlcs := make([]*lnrpc.HeldHTLC, 0, len(heldHTLCs))
+	for _, htlc := range heldHTLCs {
+		htlcs = append(htlcs, &lnrpc.HeldHTLC{
+			ChannelPoint: htlc.ChanPoint.String(),
+			Index:        htlc.Index,
+			PaymentHash:  htlc.PaymentHash[:],
+			AssetId:      htlc.AssetID,
+			Amount:       int64(htlc.Amount),
+			Expiry:       htlc.Expiry,
+			Payload:      htlc.Payload,
+		})
+	}
+
+	return &lnrpc.ListHeldHTLCsResponse{Htlcs: htlcs}, nil
+}
+
+// ResolveHTLC settles a held incoming HTLC with the given preimage, or fails
+// it back to the remote party.
+func (r *rpcServer) ResolveHTLC(ctx context.Context,
+	in *lnrpc.ResolveHTLCRequest) (*lnrpc.ResolveHTLCResponse, error) {
+
+	if in.ChannelPoint == nil {
+		return nil, fmt.Errorf("channel point must be specified")
+	}
+	txid, err := wire.NewShaHash(in.ChannelPoint.FundingTxid)
+	if err != nil {
+		return nil, err
+	}
+	chanPoint := wire.NewOutPoint(txid, in.ChannelPoint.OutputIndex)
+
+	var preimage *[32]byte
+	if !in.Fail {
+		if len(in.Preimage) != 32 {
+			return nil, fmt.Errorf("preimage must be 32 bytes, "+
+				"got %v", len(in.Preimage))
+		}
+		preimage = new([32]byte)
+		copy(preimage[:], in.Preimage)
+	}
+
+	rpcsLog.Debugf("[resolvehtlc] htlc %v of ChannelPoint(%v), fail=%v",
+		in.Index, chanPoint, in.Fail)
+
+	if err := r.htlcHolder.resolve(*chanPoint, in.Index, preimage); err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.ResolveHTLCResponse{}, nil
+}
+
+// ForwardingHistory returns the payments forwarded by us within the given
+// time slice, along with the fee earned for each. The fee of a payment
+// converted from one asset into another is left unset.
+func (r *rpcServer) ForwardingHistory(ctx context.Context,
+	in *lnrpc.ForwardingHistoryRequest) (*lnrpc.ForwardingHistoryResponse, error) {
+
+	endTime := time.Now()
+	if in.EndTime != 0 {
+		endTime = time.Unix(in.EndTime, 0)
+	}
+
+	rpcsLog.Debugf("[forwardinghistory] start=%v, end=%v, asset=%q",
+		in.StartTime, endTime.Unix(), in.AssetId)
+
+	events, err := r.server.chanDB.QueryForwardingEvents(
+		channeldb.ForwardingEventQuery{
+			StartTime:    time.Unix(in.StartTime, 0),
+			EndTime:      endTime,
+			AssetID:      in.AssetId,
+			NumMaxEvents: in.NumMaxEvents,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &lnrpc.ForwardingHistoryResponse{
+		ForwardingEvents: make([]*lnrpc.ForwardingEvent, 0, len(events)),
+	}
+	for i := range events {
+		event := &events[i]
+		fee, _ := event.Fee()
+		resp.ForwardingEvents = append(resp.ForwardingEvents,
+			&lnrpc.ForwardingEvent{
+				Timestamp:    event.Timestamp.Unix(),
+				ChanPointIn:  event.IncomingChanID.String(),
+				ChanPointOut: event.OutgoingChanID.String(),
+				AssetIn:      event.IncomingAssetID,
+				AssetOut:     event.OutgoingAssetID,
+				AmtIn:        int64(event.AmtIn),
+				AmtOut:       int64(event.AmtOut),
+				Fee:          int64(fee),
+			},
+		)
+	}
+
+	return resp, nil
+}
+
+// EstimateTx quotes the inputs, size, and fee of a transaction paying the
+// given amount of the active asset to the given number of outputs, each
+// carrying the satoshis of a funding output. No outputs are locked.
+func (r *rpcServer) EstimateTx(ctx context.Context,
+	in *lnrpc.EstimateTxRequest) (*lnrpc.EstimateTxResponse, error) {
+
+	numOutputs := in.NumOutputs
+	if numOutputs == 0 {
+		numOutputs = 1
+	}
+	feeRate := in.SatPerByte
+	if feeRate == 0 {
+		feeRate = defaultEstimateFeeRate
+	}
+
+	outputs := make([]*wire.TxOut, numOutputs)
+	for i := range outputs {
+		outputs[i] = wire.NewTxOut(int64(lndcc.FundingOutputValue()), nil)
+	}
+
+	estimate, err := r.server.lnwallet.EstimateTx(outputs,
+		btcutil.Amount(in.AssetAmount), feeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcsLog.Debugf("[estimatetx] asset_amt=%v, num_outputs=%v: vsize=%v, "+
+		"fee=%v", in.AssetAmount, numOutputs, estimate.VSize,
+		estimate.Fee)
+
+	inputs := make([]string, len(estimate.Inputs))
+	for i, input := range estimate.Inputs {
+		inputs[i] = input.String()
+	}
+
+	return &lnrpc.EstimateTxResponse{
+		Inputs:     inputs,
+		Vsize:      int64(estimate.VSize),
+		FeeSat:     int64(estimate.Fee),
+		Change:     int64(estimate.Change),
+		FuelChange: int64(estimate.FuelChange),
+	}, nil
+}
+
+// SendKeysend sends a spontaneous payment to the target node, which is able
+// to settle it without having issued an invoice. The preimage of the payment
+// is returned once the payment has been sent.
+func (r *rpcServer) SendKeysend(ctx context.Context,
+	in *lnrpc.KeysendRequest) (*lnrpc.KeysendResponse, error) {
+
+	destKey, err := btcec.ParsePubKey(in.DestPubkey, btcec.S256())
+	if err != nil {
+		return nil, err
+	}
+	if in.Amt <= 0 {
+		return nil, fmt.Errorf("invalid payment amount %v", in.Amt)
+	}
+
+	rpcsLog.Debugf("[sendkeysend] dest=%x, amt=%v", in.DestPubkey, in.Amt)
+
+	preimage, err := r.server.sendKeysendPayment(destKey,
+		btcutil.Amount(in.Amt))
+	if err != nil {
+		return nil, err
+	}
+	paymentHash := fastsha256.Sum256(preimage[:])
+
+	return &lnrpc.KeysendResponse{
+		PaymentPreimage: preimage[:],
+		PaymentHash:     paymentHash[:],
+	}, nil
+}
+
+// SendMultiPartPayment pays the given amount of an asset to the target node,
+// splitting the payment across our channels with the node should no single
+// channel be able to carry it. The call returns once all parts have been
+// sent.
+func (r *rpcServer) SendMultiPartPayment(ctx context.Context,
+	in *lnrpc.MultiPartPaymentRequest) (*lnrpc.MultiPartPaymentResponse, error) {
+
+	dest, err := wire.NewShaHash(in.Dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(in.PaymentHash) != 32 {
+		return nil, fmt.Errorf("payment hash must be 32 bytes, got %v",
+			len(in.PaymentHash))
+	}
+	var paymentHash [32]byte
+	copy(paymentHash[:], in.PaymentHash)
+
+	rpcsLog.Debugf("[sendmultipartpayment] dest=%v, asset=%q, amt=%v, "+
+		"hash=%x", dest, in.AssetId, in.Amt, in.PaymentHash)
+
+	err = r.server.sendMultiPartPayment(*dest, in.AssetId,
+		btcutil.Amount(in.Amt), paymentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.MultiPartPaymentResponse{}, nil
+}
+
+// Rebalance shifts the given amount of an asset from our side of one channel
+// to our side of another, by paying ourselves along a circular route. The
+// call returns once the payment has completed.
+func (r *rpcServer) Rebalance(ctx context.Context,
+	in *lnrpc.RebalanceRequest) (*lnrpc.RebalanceResponse, error) {
+
+	fromChan, err := parseChanPoint(in.FromChan)
+	if err != nil {
+		return nil, err
+	}
+	toChan, err := parseChanPoint(in.ToChan)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcsLog.Debugf("[rebalance] asset=%q, amt=%v, from=%v, to=%v",
+		in.AssetId, in.Amt, fromChan, toChan)
+
+	err = r.server.Rebalance(in.AssetId, btcutil.Amount(in.Amt),
+		*fromChan, *toChan)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.RebalanceResponse{}, nil
+}
+
+// parseChanPoint converts the passed rpc channel point into an outpoint.
+func parseChanPoint(chanPoint *lnrpc.ChannelPoint) (*wire.OutPoint, error) {
+	if chanPoint == nil {
+		return nil, fmt.Errorf("channel point must be specified")
+	}
+	txid, err := wire.NewShaHash(chanPoint.FundingTxid)
+	if err != nil {
+		return nil, err
+	}
+
+	return wire.NewOutPoint(txid, chanPoint.OutputIndex), nil
+}
+
+// RecoverChannels verifies every open channel within the channel database
+// against the chain, reporting the channels which were recovered, along with
+// those requiring manual action. The channels rebuilt during recovery are
+// released once reported, as each is loaded by its peer upon connection.
+func (r *rpcServer) RecoverChannels(ctx context.Context,
+	in *lnrpc.RecoverChannelsRequest) (*lnrpc.RecoverChannelsResponse, error) {
+
+	rpcsLog.Debugf("[recoverchannels]")
+
+	report, err := r.server.lnwallet.RecoverChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &lnrpc.RecoverChannelsResponse{
+		Recovered: make([]*lnrpc.RecoveredChannel, 0,
+			len(report.Channels)),
+		NeedsAction: make([]*lnrpc.ChannelRecoveryIssue, 0,
+			len(report.NeedsAction)),
+	}
+	for _, lnChan := range report.Channels {
+		resp.Recovered = append(resp.Recovered, &lnrpc.RecoveredChannel{
+			ChannelPoint: lnChan.ChannelPoint().String(),
+			SpentOffline: lnChan.FundingSpentOffline,
+		})
+		lnChan.Stop()
+	}
+	for _, issue := range report.NeedsAction {
+		resp.NeedsAction = append(resp.NeedsAction,
+			&lnrpc.ChannelRecoveryIssue{
+				ChannelPoint: issue.ChanPoint.String(),
+				RemoteId:     issue.RemoteID.String(),
+				Error:        issue.Err.Error(),
+			},
+		)
+	}
+
+	return resp, nil
+}
+
+// PayBTCInvoice pays the given number of satoshis to the target node from
+// our channels of an asset, converting the asset into bitcoin at the asset's
+// designated conversion node. The quote the payment was sent under is
+// returned once the payment has been settled.
+func (r *rpcServer) PayBTCInvoice(ctx context.Context,
+	in *lnrpc.PayBTCInvoiceRequest) (*lnrpc.PayBTCInvoiceResponse, error) {
+
+	if len(in.Dest) != 32 {
+		return nil, fmt.Errorf("destination must be 32 bytes, got %v",
+			len(in.Dest))
+	}
+	var dest router.NodeID
+	copy(dest[:], in.Dest)
+
+	if len(in.PaymentHash) != 32 {
+		return nil, fmt.Errorf("payment hash must be 32 bytes, got %v",
+			len(in.PaymentHash))
+	}
+	var paymentHash [32]byte
+	copy(paymentHash[:], in.PaymentHash)
+
+	rpcsLog.Debugf("[paybtcinvoice] asset=%q, dest=%x, amt=%v, hash=%x",
+		in.AssetId, in.Dest, in.Amt, in.PaymentHash)
+
+	quote, err := r.server.PayBTCInvoice(in.AssetId, dest, paymentHash,
+		btcutil.Amount(in.Amt))
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.PayBTCInvoiceResponse{
+		ConversionNode:    quote.node.node[:],
+		AssetSent:         int64(quote.sendAmt),
+		SlippageAllowance: int64(quote.allowance),
+		NumHops:           uint32(len(quote.route.Hops)),
+	}, nil
+}
+
+// ProbeRoute tests whether the given amount of an asset may currently be paid
+// to the target node, without paying it. The route probed is returned if the
+// payment may be carried.
+func (r *rpcServer) ProbeRoute(ctx context.Context,
+	in *lnrpc.ProbeRouteRequest) (*lnrpc.ProbeRouteResponse, error) {
+
+	if len(in.Dest) != 32 {
+		return nil, fmt.Errorf("destination must be 32 bytes, got %v",
+			len(in.Dest))
+	}
+	var dest router.NodeID
+	copy(dest[:], in.Dest)
+
+	rpcsLog.Debugf("[proberoute] dest=%x, asset=%q, amt=%v", in.Dest,
+		in.AssetId, in.Amt)
+
+	route, err := r.server.ProbeRoute(dest, in.AssetId,
+		btcutil.Amount(in.Amt))
+	if err != nil {
+		return nil, err
+	}
+
+	hops := make([]*lnrpc.RouteHop, len(route.Hops))
+	for i, hop := range route.Hops {
+		hops[i] = &lnrpc.RouteHop{
+			ChannelPoint: hop.Channel.ChanPoint.String(),
+			NextNode:     hop.NextNode[:],
+			AssetId:      hop.AssetID,
+			Amount:       int64(hop.Amount),
+		}
+	}
+
+	return &lnrpc.ProbeRouteResponse{Hops: hops}, nil
+}
+
+// AbortFunding cancels a pending channel we funded, whose funding transaction
+// has yet to confirm, by double spending the funding transaction's inputs
+// back to the wallet. This recovers the funds of a channel whose peer
+// disappeared after the funding transaction was broadcast.
+func (r *rpcServer) AbortFunding(ctx context.Context,
+	in *lnrpc.AbortFundingRequest) (*lnrpc.AbortFundingResponse, error) {
+
+	chanPoint, err := parseChanPoint(in.ChannelPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcsLog.Debugf("[abortfunding] chan_point=%v", chanPoint)
+
+	replacementTx, err := r.server.fundingMgr.AbortChannelFunding(*chanPoint)
+	if err != nil {
+		return nil, err
+	}
+	txid := replacementTx.TxSha()
+
+	rpcsLog.Infof("Aborted funding of ChannelPoint(%v), replaced by %v",
+		chanPoint, txid)
+
+	return &lnrpc.AbortFundingResponse{ReplacementTxid: txid[:]}, nil
+}
+
+// ClosedChannels returns a summary of each channel which has been closed,
+// detailing how it was closed, and the final balances of both parties.
+func (r *rpcServer) ClosedChannels(ctx context.Context,
+	in *lnrpc.ClosedChannelsRequest) (*lnrpc.ClosedChannelsResponse, error) {
+
+	rpcsLog.Debugf("[closedchannels]")
+
+	summaries, err := r.server.chanDB.ListClosedChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]*lnrpc.ClosedChannel, len(summaries))
+	for i, summary := range summaries {
+		channels[i] = &lnrpc.ClosedChannel{
+			ChannelPoint: summary.ChanPoint.String(),
+			RemoteId:     hex.EncodeToString(summary.RemoteID[:]),
+			AssetId:      summary.AssetID,
+			Capacity:     int64(summary.Capacity),
+			OurBalance:   int64(summary.OurBalance),
+			TheirBalance: int64(summary.TheirBalance),
+			CloseType:    summary.CloseType.String(),
+			ClosingTxid:  summary.ClosingTXID.String(),
+			CloseHeight:  summary.CloseHeight,
+		}
+	}
+
+	return &lnrpc.ClosedChannelsResponse{Channels: channels}, nil
+}
+
+// LiquidityReport returns the liquidity of all open channels, grouped by
+// remote peer and asset, including the amounts locked within HTLCs still in
+// flight.
+func (r *rpcServer) LiquidityReport(ctx context.Context,
+	in *lnrpc.LiquidityReportRequest) (*lnrpc.LiquidityReportResponse, error) {
+
+	rpcsLog.Debugf("[liquidityreport]")
+
+	report := r.server.LiquidityReport()
+
+	liquidity := make([]*lnrpc.AssetLiquidity, len(report))
+	for i, group := range report {
+		liquidity[i] = &lnrpc.AssetLiquidity{
+			LightningId:     hex.EncodeToString(group.LightningID[:]),
+			AssetId:         group.AssetID,
+			NumChannels:     int64(group.NumChannels),
+			Capacity:        int64(group.Capacity),
+			LocalBalance:    int64(group.LocalBalance),
+			RemoteBalance:   int64(group.RemoteBalance),
+			NumPendingHtlcs: int64(group.NumPendingHTLCs),
+			PendingIncoming: int64(group.PendingIncoming),
+			PendingOutgoing: int64(group.PendingOutgoing),
+		}
+	}
+
+	return &lnrpc.LiquidityReportResponse{Liquidity: liquidity}, nil
+}
+
+// QueryAuditLog returns the records of the transactions broadcast by the
+// wallet within the given time slice, optionally restricted to those of a
+// single channel, asset, or set of purposes.
+func (r *rpcServer) QueryAuditLog(ctx context.Context,
+	in *lnrpc.AuditLogRequest) (*lnrpc.AuditLogResponse, error) {
+
+	endTime := time.Now()
+	if in.EndTime != 0 {
+		endTime = time.Unix(in.EndTime, 0)
+	}
+
+	rpcsLog.Debugf("[queryauditlog] start=%v, end=%v, asset=%q, "+
+		"purposes=%v", in.StartTime, endTime.Unix(), in.AssetId,
+		in.Purposes)
+
+	query := channeldb.AuditQuery{
+		StartTime:     time.Unix(in.StartTime, 0),
+		EndTime:       endTime,
+		AssetID:       in.AssetId,
+		NumMaxRecords: in.NumMaxRecords,
+	}
+	if in.ChannelPoint != nil {
+		chanPoint, err := parseChanPoint(in.ChannelPoint)
+		if err != nil {
+			return nil, err
+		}
+		query.ChanPoint = chanPoint
+	}
+	for _, name := range in.Purposes {
+		purpose, err := parseTxPurpose(name)
+		if err != nil {
+			return nil, err
+		}
+		query.Purposes = append(query.Purposes, purpose)
+	}
+
+	records, err := r.server.chanDB.QueryAuditLog(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &lnrpc.AuditLogResponse{
+		Records: make([]*lnrpc.AuditRecord, 0, len(records)),
+	}
+	for i := range records {
+		record := &records[i]
+		resp.Records = append(resp.Records, &lnrpc.AuditRecord{
+			Seq:          record.Seq,
+			Timestamp:    record.Timestamp.Unix(),
+			Purpose:      record.Purpose.String(),
+			ChannelPoint: record.ChanPoint.String(),
+			AssetId:      record.AssetID,
+			RawTx:        record.RawTx,
+			Instructions: record.Instructions,
+			Result:       record.Result,
+		})
+	}
+
+	return resp, nil
+}
+
+// parseTxPurpose returns the TxPurpose with the passed human readable name.
+func parseTxPurpose(name string) (channeldb.TxPurpose, error) {
+	for p := channeldb.TxFunding; p <= channeldb.TxSweep; p++ {
+		if p.String() == name {
+			return p, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown transaction purpose %q", name)
+}
+
+// ExportFundingBundle returns the proof bundle of the funding transaction of
+// the target channel, generated as the channel opened, allowing the issuer of
+// the channel's asset to verify, and track the channels holding it.
+func (r *rpcServer) ExportFundingBundle(ctx context.Context,
+	in *lnrpc.FundingBundleRequest) (*lnrpc.FundingBundleResponse, error) {
+
+	chanPoint, err := parseChanPoint(in.ChannelPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcsLog.Debugf("[exportfundingbundle] chan_point=%v", chanPoint)
+
+	rawBundle, err := r.server.chanDB.FetchFundingBundle(chanPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &lnwallet.FundingBundle{}
+	if err := bundle.Decode(bytes.NewReader(rawBundle)); err != nil {
+		return nil, fmt.Errorf("unable to decode funding bundle: %v", err)
+	}
+	info, err := bundle.Info()
+	if err != nil {
+		return nil, err
+	}
+
+	return &lnrpc.FundingBundleResponse{
+		AssetId:       info.AssetID,
+		AssetCapacity: int64(info.AssetCapacity),
+		LocalKey:      info.LocalKey,
+		RemoteKey:     info.RemoteKey,
+		Bundle:        rawBundle,
+	}, nil
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"fmt"
 	"net"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -14,7 +16,11 @@ import (
 	"github.com/lightningnetwork/lnd/lndc"
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/lightningnetwork/lnd/replication"
+	"github.com/lightningnetwork/lnd/router"
+	"github.com/lightningnetwork/lnd/watchtower"
 	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
 	"github.com/roasbeef/btcutil"
 
 	"github.com/BitfuryLightning/tools/routing"
@@ -54,10 +60,65 @@ type server struct {
 	htlcSwitch *htlcSwitch
 	invoices   *invoiceRegistry
 
+	// payments holds the parts of incoming multi-part payments until all
+	// parts have arrived.
+	payments *paymentAggregator
+
+	// interceptor, if registered, decides the fate of incoming HTLCs
+	// which don't pay to one of our invoices.
+	interceptorMtx sync.RWMutex
+	interceptor    HTLCInterceptor
+
+	// chanEvents dispatches the events of our channels to the hooks
+	// registered by external systems.
+	chanEvents *channelEventBus
+
 	routingMgr *routing.RoutingManager
 
+	// chanGraph tracks all known channels along with the asset each is
+	// denominated in, allowing routes to be constrained to a particular
+	// asset.
+	chanGraph *router.Graph
+
+	// policy is the fee policy advertised for forwarding HTLCs over our
+	// channels.
+	policy forwardingPolicy
+
 	utxoNursery *utxoNursery
 
+	// closeArbiter force closes the channels of unresponsive peers as
+	// dictated by the configured force close policy.
+	closeArbiter *closeArbiter
+
+	// updateLimits are the limits enforced on the channel updates sent by
+	// each peer.
+	updateLimits *updateLimitPolicy
+
+	// zeroConf dictates which peers we accept zero-conf channels from.
+	zeroConf *zeroConfPolicy
+
+	// conversion dictates how invoices denominated in satoshis are paid
+	// from our channels of other assets.
+	conversion *conversionPolicy
+
+	// towerClient backs up justice transactions for revoked channel
+	// states to the configured watchtowers. If no towers are configured,
+	// then this is nil.
+	towerClient *watchtower.Client
+
+	// towerServer watches the chain for breaches on behalf of other
+	// nodes. If we aren't acting as a watchtower, then this is nil.
+	towerServer *watchtower.Server
+
+	// replicator streams each committed channel state to our cold
+	// standby. If no standby is configured, then this is nil.
+	replicator *replication.Primary
+
+	// standby accepts the channel states replicated by the primary node
+	// we're a cold standby of. If we aren't acting as a standby, then
+	// this is nil.
+	standby *replication.Standby
+
 	newPeers  chan *peer
 	donePeers chan *peer
 	queries   chan interface{}
@@ -85,14 +146,59 @@ func newServer(listenAddrs []string, notifier chainntnfs.ChainNotifier,
 		}
 	}
 
+	// Any configured swap rates allow us to forward HTLCs between
+	// channels of different assets.
+	roundingPolicy, err := lnwallet.ParseRoundingPolicy(cfg.RoundingPolicy)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := newStaticRateProvider(cfg.SwapRates,
+		cfg.AssetDivisibility, roundingPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	closePolicy, err := newForceClosePolicy(cfg.AutoForceClose,
+		cfg.ForceCloseGrace, cfg.MaxHTLCExposure)
+	if err != nil {
+		return nil, err
+	}
+
+	zeroConf, err := newZeroConfPolicy(cfg.ZeroConfPeers,
+		cfg.ZeroConfTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := newConversionPolicy(cfg.ConversionNodes,
+		cfg.MaxConversionSlippage)
+	if err != nil {
+		return nil, err
+	}
+
+	updateLimits, err := newUpdateLimitPolicy(cfg.PeerUpdateRate,
+		cfg.PeerUpdateBurst, cfg.MaxPendingCommits, cfg.RateLimitPenalty,
+		cfg.PeerIgnorePeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	resLimits := &reservationLimits{
+		maxPerPeer: cfg.MaxPeerPendingChannels,
+		maxTotal:   cfg.MaxPendingChannels,
+		maxQueued:  cfg.MaxQueuedChannels,
+	}
+
 	serializedPubKey := privKey.PubKey().SerializeCompressed()
 	s := &server{
 		bio:           bio,
 		chainNotifier: notifier,
 		chanDB:        chanDB,
-		fundingMgr:    newFundingManager(wallet),
-		htlcSwitch:    newHtlcSwitch(),
-		invoices:      newInvoiceRegistry(),
+		fundingMgr:    newFundingManager(wallet, resLimits),
+		htlcSwitch:    newHtlcSwitch(rates),
+		invoices:      newInvoiceRegistry(chanDB),
+		payments:      newPaymentAggregator(mppTimeout),
+		chanEvents:    newChannelEventBus(),
 		lnwallet:      wallet,
 		identityPriv:  privKey,
 		lightningID:   fastsha256.Sum256(serializedPubKey),
@@ -102,19 +208,127 @@ func newServer(listenAddrs []string, notifier chainntnfs.ChainNotifier,
 		donePeers:     make(chan *peer, 100),
 		queries:       make(chan interface{}),
 		quit:          make(chan struct{}),
+		updateLimits:  updateLimits,
+		zeroConf:      zeroConf,
+		conversion:    conversion,
+		policy: forwardingPolicy{
+			feeBase:       btcutil.Amount(cfg.FeeBase),
+			feeRate:       cfg.FeeRate,
+			carrierFee:    btcutil.Amount(cfg.CarrierFee),
+			timeLockDelta: cfg.TimeLockDelta,
+		},
 	}
 
 	// TODO(roasbeef): remove
-	s.invoices.addInvoice(1000*1e8, *debugPre)
+	s.invoices.addDebugInvoice(1000*1e8, *debugPre)
+
+	s.utxoNursery = newUtxoNursery(notifier, bio, wallet)
+	s.closeArbiter = newCloseArbiter(s, closePolicy)
+
+	// If any watchtowers have been configured, then create a client to
+	// back up each revoked state of our channels to the towers.
+	if len(cfg.Watchtowers) != 0 {
+		towers := make([]watchtower.Tower, len(cfg.Watchtowers))
+		for i, url := range cfg.Watchtowers {
+			towers[i] = watchtower.NewHTTPTower(url)
+		}
+
+		s.towerClient = watchtower.NewClient(&watchtower.ClientConfig{
+			ID:     hex.EncodeToString(serializedPubKey),
+			DB:     chanDB,
+			Signer: wallet.Signer,
+			NewSweepScript: func() ([]byte, error) {
+				addr, err := wallet.NewChannelAddress(
+					lnwallet.WitnessPubKey, false)
+				if err != nil {
+					return nil, err
+				}
+				return txscript.PayToAddrScript(addr)
+			},
+			Towers:   towers,
+			FeeRates: watchtower.DefaultJusticeFeeRates,
+		})
+	}
+
+	// If we're to act as a watchtower for other nodes, then create the
+	// tower server.
+	if cfg.TowerListen != "" {
+		s.towerServer, err = watchtower.NewServer(&watchtower.ServerConfig{
+			DBPath:             filepath.Join(cfg.DataDir, "watchtower"),
+			ListenAddr:         cfg.TowerListen,
+			Notifier:           notifier,
+			ChainIO:            bio,
+			PublishTransaction: wallet.PublishTransaction,
+			MaxBlobsPerClient:  cfg.TowerQuota,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// If a cold standby has been configured, then replicate each
+	// committed channel state to it.
+	if cfg.ReplicateTo != "" {
+		standbyPub, standbyAddr, err := parseReplicaAddr(cfg.ReplicateTo)
+		if err != nil {
+			return nil, err
+		}
 
-	s.utxoNursery = newUtxoNursery(notifier, wallet)
+		s.replicator = replication.NewPrimary(&replication.PrimaryConfig{
+			IdentityKey:   privKey,
+			StandbyAddr:   standbyAddr,
+			StandbyPubKey: standbyPub,
+		})
+		chanDB.SetDeltaObserver(s.replicator.Replicate)
+	}
+
+	// If we're to act as the cold standby of another node, then create
+	// the standby server accepting its channel states.
+	if cfg.StandbyListen != "" {
+		primaryPub, err := parseReplicaPubKey(cfg.StandbyPrimary)
+		if err != nil {
+			return nil, err
+		}
+
+		s.standby, err = replication.NewStandby(&replication.StandbyConfig{
+			DBPath:        filepath.Join(cfg.DataDir, "standby"),
+			ListenAddr:    cfg.StandbyListen,
+			IdentityKey:   privKey,
+			PrimaryPubKey: primaryPub,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// Create a new routing manager with ourself as the sole node within
 	// the graph.
 	s.routingMgr = routing.NewRoutingManager(graph.NewID(s.lightningID), nil)
 
+	// Our own swap rates are added to the asset aware channel graph, so
+	// we're able to route payments across assets through ourselves.
+	s.chanGraph = router.NewGraph()
+	for pair := range rates.rates {
+		rate, _ := rates.baseUnitRate(pair)
+		floatRate, _ := rate.Float64()
+		s.chanGraph.SetSwapRate(router.NodeID(s.lightningID),
+			graphAssetID(pair.from), graphAssetID(pair.to), floatRate)
+	}
+
 	s.rpcServer = newRpcServer(s)
 
+	// If enabled, incoming HTLCs which neither pay to one of our invoices,
+	// nor are forwarded, are held until they're resolved over RPC.
+	if cfg.HoldHTLCs {
+		s.RegisterHTLCInterceptor(s.rpcServer.htlcHolder)
+	}
+
+	// If configured, each channel event is posted to the external system
+	// keeping accounts of the funds within our channels.
+	if cfg.ChanEventURL != "" {
+		s.RegisterChannelEventHook(newChannelEventWebhook(cfg.ChanEventURL))
+	}
+
 	return s, nil
 }
 
@@ -150,13 +364,40 @@ func (s *server) Start() error {
 	if err := s.htlcSwitch.Start(); err != nil {
 		return err
 	}
+	if err := s.chanEvents.Start(); err != nil {
+		return err
+	}
 	if err := s.utxoNursery.Start(); err != nil {
 		return err
 	}
+	if err := s.closeArbiter.Start(); err != nil {
+		return err
+	}
+	if s.towerClient != nil {
+		if err := s.towerClient.Start(); err != nil {
+			return err
+		}
+	}
+	if s.towerServer != nil {
+		if err := s.towerServer.Start(); err != nil {
+			return err
+		}
+	}
+	if s.replicator != nil {
+		if err := s.replicator.Start(); err != nil {
+			return err
+		}
+	}
+	if s.standby != nil {
+		if err := s.standby.Start(); err != nil {
+			return err
+		}
+	}
 	s.routingMgr.Start()
 
-	s.wg.Add(1)
+	s.wg.Add(2)
 	go s.queryHandler()
+	go s.preimagePruner()
 
 	return nil
 }
@@ -183,7 +424,21 @@ func (s *server) Stop() error {
 	s.fundingMgr.Stop()
 	s.routingMgr.Stop()
 	s.htlcSwitch.Stop()
+	s.chanEvents.Stop()
 	s.utxoNursery.Stop()
+	s.closeArbiter.Stop()
+	if s.towerClient != nil {
+		s.towerClient.Stop()
+	}
+	if s.towerServer != nil {
+		s.towerServer.Stop()
+	}
+	if s.replicator != nil {
+		s.replicator.Stop()
+	}
+	if s.standby != nil {
+		s.standby.Stop()
+	}
 
 	s.lnwallet.Shutdown()
 
@@ -213,6 +468,10 @@ func (s *server) addPeer(p *peer) {
 	}
 
 	s.peers[p.id] = p
+	s.closeArbiter.peerOnline(p.lightningID)
+
+	// Bring the new peer up to date with all channels we know of.
+	go s.syncChannelGraph(p)
 }
 
 // removePeer removes the passed peer from the server's state of all active
@@ -231,6 +490,7 @@ func (s *server) removePeer(p *peer) {
 	}
 
 	delete(s.peers, p.id)
+	s.closeArbiter.peerOffline(p.lightningID)
 }
 
 // connectPeerMsg is a message requesting the server to open a connection to a
@@ -268,6 +528,45 @@ type openChanReq struct {
 	err     chan error
 }
 
+// preimagePruner removes preimages from the preimage store upon each new
+// block once the HTLCs they settled have expired, as they're then no longer
+// needed to claim an incoming HTLC output on-chain.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) preimagePruner() {
+	defer s.wg.Done()
+
+	blockEpochs, err := s.chainNotifier.RegisterBlockEpochNtfn()
+	if err != nil {
+		srvrLog.Errorf("unable to register for block epochs, expired "+
+			"preimages won't be pruned: %v", err)
+		return
+	}
+
+	for {
+		select {
+		case epoch, ok := <-blockEpochs.Epochs:
+			if !ok {
+				return
+			}
+
+			numPruned, err := s.chanDB.PrunePreimages(
+				uint32(epoch.Height))
+			if err != nil {
+				srvrLog.Errorf("unable to prune preimages: %v", err)
+				continue
+			}
+			if numPruned != 0 {
+				srvrLog.Debugf("Pruned %v preimages expired by "+
+					"height %v", numPruned, epoch.Height)
+			}
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
 // queryHandler handles any requests to modify the server's internal state of
 // all active peers, or query/mutate the server's global state. Additionally,
 // any queries directed at peers will be handled by this goroutine.
@@ -478,6 +777,22 @@ func (s *server) Peers() []*peer {
 	return <-resp
 }
 
+// newInspector creates a read-only query service over the volatile state of
+// the wallet, our active channels, and the outputs awaiting their sweep.
+func (s *server) newInspector() *lnwallet.Inspector {
+	return lnwallet.NewInspector(&lnwallet.InspectorConfig{
+		Wallet: s.lnwallet,
+		ActiveChannels: func() []*lnwallet.LightningChannel {
+			var channels []*lnwallet.LightningChannel
+			for _, peer := range s.Peers() {
+				channels = append(channels, peer.ActiveChannels()...)
+			}
+			return channels
+		},
+		PendingSweeps: s.utxoNursery.PendingSweeps,
+	})
+}
+
 // listener is a goroutine dedicated to accepting in coming peer connections
 // from the passed listener.
 //
@@ -509,3 +824,74 @@ func (s *server) listener(l net.Listener) {
 
 	s.wg.Done()
 }
+
+// graphAssetID maps an asset ID used when quoting exchange rates to the asset
+// ID used within the channel graph, where channels paying satoshis have an
+// empty asset ID.
+func graphAssetID(assetID string) string {
+	if assetID == btcAssetID {
+		return ""
+	}
+	return assetID
+}
+
+// rateAssetID maps an asset ID used within the channel graph to the asset ID
+// used when quoting exchange rates, where plain bitcoin is denoted by
+// btcAssetID.
+func rateAssetID(assetID string) string {
+	if assetID == "" {
+		return btcAssetID
+	}
+	return assetID
+}
+
+// addChannelToGraph adds one of our newly opened channels to the asset aware
+// channel graph, and announces it to our peers along with our fee policy.
+func (s *server) addChannelToGraph(chanInfo *channeldb.ChannelSnapshot) {
+	edge := &router.ChannelEdge{
+		ChanPoint:     *chanInfo.ChannelPoint,
+		Node1:         router.NodeID(s.lightningID),
+		Node2:         router.NodeID(chanInfo.RemoteID),
+		AssetID:       chanInfo.AssetID,
+		Capacity:      chanInfo.Capacity,
+		FeeBase:       s.policy.feeBase,
+		FeeRate:       s.policy.feeRate,
+		CarrierFee:    s.policy.carrierFee,
+		TimeLockDelta: s.policy.timeLockDelta,
+	}
+	if err := s.chanGraph.AddChannel(edge); err != nil {
+		srvrLog.Errorf("unable to add ChannelPoint(%v) to graph: %v",
+			chanInfo.ChannelPoint, err)
+		return
+	}
+
+	go s.broadcastMessage(newChannelAnnouncement(edge), nil)
+}
+
+// parseReplicaPubKey parses the hex encoded identity public key of a node
+// taking part in the replication of our channel state.
+func parseReplicaPubKey(pubHex string) (*btcec.PublicKey, error) {
+	pubBytes, err := hex.DecodeString(pubHex)
+	if err != nil {
+		return nil, err
+	}
+
+	return btcec.ParsePubKey(pubBytes, btcec.S256())
+}
+
+// parseReplicaAddr parses the address of a cold standby, of the form
+// <pubkey>@<host:port>.
+func parseReplicaAddr(addr string) (*btcec.PublicKey, string, error) {
+	parts := strings.Split(addr, "@")
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, "", fmt.Errorf("standby address %v not of the "+
+			"form <pubkey>@<host:port>", addr)
+	}
+
+	pubKey, err := parseReplicaPubKey(parts[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pubKey, parts[1], nil
+}
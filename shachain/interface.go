@@ -0,0 +1,88 @@
+package shachain
+
+import (
+	"crypto/sha256"
+	"errors"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+const (
+	// treeHeight is the height of the revocation secret tree specified by
+	// BOLT #3, and the number of bits within each of its indexes.
+	treeHeight = 48
+
+	// startIndex is the index within the tree of the secret revoking the
+	// commitment at height zero. Secrets are derived from descending
+	// indexes.
+	startIndex = 1<<treeHeight - 1
+
+	// MaxHeight is the height of the last commitment which may be revoked
+	// by the secrets of a tree.
+	MaxHeight = startIndex
+)
+
+// ErrHeightExceeded is returned when requesting the secret of a commitment
+// beyond MaxHeight.
+var ErrHeightExceeded = errors.New("commitment height exceeds that " +
+	"supported by the shachain")
+
+// Producer produces the secrets revoking each of our commitments, indexed by
+// the height of the commitment. Both the elkrem sender, and the
+// RevocationProducer implement it, allowing a channel to use either.
+type Producer interface {
+	// AtIndex returns the secret revoking the commitment at the passed
+	// height.
+	AtIndex(height uint64) (*wire.ShaHash, error)
+
+	// ToBytes returns the serialized producer.
+	ToBytes() []byte
+}
+
+// Store stores the secrets revealed by the remote party as it revokes each
+// of its commitments, in order of their height. Both the elkrem receiver, and
+// the RevocationStore implement it, allowing a channel to use either.
+type Store interface {
+	// AddNext adds the secret revoking the remote commitment following
+	// the last one revoked, returning an error if the secret is
+	// inconsistent with those previously added.
+	AddNext(secret *wire.ShaHash) error
+
+	// AtIndex returns the secret revoking the remote commitment at the
+	// passed height, returning an error if it hasn't been added yet.
+	AtIndex(height uint64) (*wire.ShaHash, error)
+
+	// UpTo returns the height of the last commitment revoked, or zero if
+	// none have been.
+	UpTo() uint64
+
+	// ToBytes returns the serialized store.
+	ToBytes() ([]byte, error)
+}
+
+// heightToIndex returns the index within the tree of the secret revoking the
+// commitment at the passed height.
+func heightToIndex(height uint64) (uint64, error) {
+	if height > MaxHeight {
+		return 0, ErrHeightExceeded
+	}
+
+	return startIndex - height, nil
+}
+
+// deriveSecret derives the secret at the passed index from the passed secret
+// whose subtree has the given number of levels below it. The index must lie
+// within the subtree, which is to say it may only differ from the index of
+// the secret within its lowest bits.
+func deriveSecret(secret [32]byte, levels uint8, index uint64) [32]byte {
+	for bit := int(levels) - 1; bit >= 0; bit-- {
+		if index&(1<<uint(bit)) == 0 {
+			continue
+		}
+
+		secret[bit/8] ^= 1 << uint(bit%8)
+		secret = sha256.Sum256(secret[:])
+	}
+
+	return secret
+}
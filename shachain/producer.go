@@ -0,0 +1,52 @@
+package shachain
+
+import "github.com/roasbeef/btcd/wire"
+
+// RevocationProducer derives the secrets revoking each of our commitments
+// from a single seed, as specified by BOLT #3. The secret of any commitment
+// is derived within at most 48 hashes.
+type RevocationProducer struct {
+	seed wire.ShaHash
+}
+
+// A compile time check to ensure RevocationProducer implements the Producer
+// interface.
+var _ Producer = (*RevocationProducer)(nil)
+
+// NewRevocationProducer creates a new RevocationProducer deriving all secrets
+// from the passed seed.
+func NewRevocationProducer(seed wire.ShaHash) *RevocationProducer {
+	return &RevocationProducer{seed: seed}
+}
+
+// RevocationProducerFromBytes deserializes a RevocationProducer serialized
+// by ToBytes.
+func RevocationProducerFromBytes(b []byte) (*RevocationProducer, error) {
+	seed, err := wire.NewShaHash(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRevocationProducer(*seed), nil
+}
+
+// AtIndex returns the secret revoking the commitment at the passed height.
+//
+// This is a part of the Producer interface.
+func (p *RevocationProducer) AtIndex(height uint64) (*wire.ShaHash, error) {
+	index, err := heightToIndex(height)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := wire.ShaHash(deriveSecret([32]byte(p.seed), treeHeight,
+		index))
+	return &secret, nil
+}
+
+// ToBytes returns the seed of the producer.
+//
+// This is a part of the Producer interface.
+func (p *RevocationProducer) ToBytes() []byte {
+	return p.seed[:]
+}
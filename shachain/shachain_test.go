@@ -1 +1,146 @@
 package shachain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestDeriveSecretVectors tests the derivation of secrets against the test
+// vectors of BOLT #3.
+func TestDeriveSecretVectors(t *testing.T) {
+	tests := []struct {
+		seedByte byte
+		index    uint64
+		secret   string
+	}{
+		{
+			index:  281474976710655,
+			secret: "02a40c85b6f28da08dfdbe0926c53fab2de6d28c10301f8f7c4073d5e42e3148",
+		},
+		{
+			seedByte: 0xff,
+			index:    281474976710655,
+			secret:   "7cc854b54e3e0dcdb010d7a3fee464a9687be6e8db3be6854c475621e007a5dc",
+		},
+		{
+			seedByte: 0xff,
+			index:    0xaaaaaaaaaaa,
+			secret:   "56f4008fb007ca9acf0e15b054d5c9fd12ee06cea347914ddbaed70d1c13a528",
+		},
+		{
+			seedByte: 0xff,
+			index:    0x555555555555,
+			secret:   "9015daaeb06dba4ccc05b91b2f73bd54405f2be9f217fbacd3c5ac2e62327d31",
+		},
+		{
+			seedByte: 0x01,
+			index:    1,
+			secret:   "915c75942a26bb3a433a8ce2cb0427c29ec6c1775cfc78328b57f6ba7bfeaa9c",
+		},
+	}
+
+	for i, test := range tests {
+		var seed [32]byte
+		copy(seed[:], bytes.Repeat([]byte{test.seedByte}, 32))
+
+		secret := deriveSecret(seed, treeHeight, test.index)
+		if hex.EncodeToString(secret[:]) != test.secret {
+			t.Fatalf("test #%v: expected secret %v, got %x", i,
+				test.secret, secret[:])
+		}
+	}
+
+	// The producer derives the secret of the commitment at height zero
+	// from the first index of the tree.
+	producer := NewRevocationProducer(wire.ShaHash{})
+	secret, err := producer.AtIndex(0)
+	if err != nil {
+		t.Fatalf("unable to derive secret: %v", err)
+	}
+	if hex.EncodeToString(secret[:]) != tests[0].secret {
+		t.Fatalf("expected secret %v, got %x", tests[0].secret,
+			secret[:])
+	}
+	if _, err := producer.AtIndex(MaxHeight + 1); err != ErrHeightExceeded {
+		t.Fatalf("expected ErrHeightExceeded, got %v", err)
+	}
+}
+
+// TestRevocationStore tests that the store is able to return every secret
+// produced by a RevocationProducer, both before and after serialization.
+func TestRevocationStore(t *testing.T) {
+	producer := NewRevocationProducer(wire.DoubleSha256SH([]byte("shatest")))
+	store := NewRevocationStore()
+
+	const numSecrets = 5000
+	for n := uint64(0); n < numSecrets; n++ {
+		secret, err := producer.AtIndex(n)
+		if err != nil {
+			t.Fatalf("unable to produce secret #%v: %v", n, err)
+		}
+		if err := store.AddNext(secret); err != nil {
+			t.Fatalf("unable to add secret #%v: %v", n, err)
+		}
+		if store.UpTo() != n {
+			t.Fatalf("expected store up to %v, got %v", n,
+				store.UpTo())
+		}
+	}
+
+	storeBytes, err := store.ToBytes()
+	if err != nil {
+		t.Fatalf("unable to serialize store: %v", err)
+	}
+	deserialized, err := RevocationStoreFromBytes(storeBytes)
+	if err != nil {
+		t.Fatalf("unable to deserialize store: %v", err)
+	}
+
+	for n := uint64(0); n < numSecrets; n++ {
+		expected, _ := producer.AtIndex(n)
+		for _, s := range []*RevocationStore{store, deserialized} {
+			secret, err := s.AtIndex(n)
+			if err != nil {
+				t.Fatalf("unable to fetch secret #%v: %v", n, err)
+			}
+			if !bytes.Equal(secret[:], expected[:]) {
+				t.Fatalf("secret #%v mismatch: expected %v, "+
+					"got %v", n, expected, secret)
+			}
+		}
+	}
+
+	if _, err := store.AtIndex(numSecrets); err == nil {
+		t.Fatalf("fetched secret which wasn't added")
+	}
+}
+
+// TestRevocationStoreRejectsInvalid tests that a secret which can't derive
+// those previously added is rejected, leaving the store intact.
+func TestRevocationStoreRejectsInvalid(t *testing.T) {
+	producer := NewRevocationProducer(wire.DoubleSha256SH([]byte("shatest")))
+	store := NewRevocationStore()
+
+	secret, _ := producer.AtIndex(0)
+	if err := store.AddNext(secret); err != nil {
+		t.Fatalf("unable to add secret: %v", err)
+	}
+
+	// The secret at height one is stored within the bucket above that of
+	// height zero, so it must be able to derive it.
+	bogus := wire.DoubleSha256SH([]byte("bogus"))
+	if err := store.AddNext(&bogus); err == nil {
+		t.Fatalf("inconsistent secret added")
+	}
+	if store.UpTo() != 0 {
+		t.Fatalf("rejected secret altered the store")
+	}
+
+	secret, _ = producer.AtIndex(1)
+	if err := store.AddNext(secret); err != nil {
+		t.Fatalf("unable to add secret: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package shachain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/roasbeef/btcd/wire"
+)
+
+// storedSecret is a secret held within a bucket of the RevocationStore.
+type storedSecret struct {
+	index  uint64
+	secret [32]byte
+}
+
+// RevocationStore stores the secrets revealed by the remote party, as
+// specified by BOLT #3. Each secret is stored within the bucket given by the
+// number of trailing zeros of its index, replacing the secrets of the bucket
+// below it which it's able to derive. As a result, all secrets revealed are
+// stored within at most 49 buckets.
+type RevocationStore struct {
+	// buckets holds a secret for each number of trailing zeros of an
+	// index. The first numBuckets buckets are filled.
+	buckets [treeHeight + 1]storedSecret
+
+	// numAdded is the number of secrets added to the store, and thus the
+	// height of the next commitment to be revoked.
+	numAdded uint64
+}
+
+// A compile time check to ensure RevocationStore implements the Store
+// interface.
+var _ Store = (*RevocationStore)(nil)
+
+// NewRevocationStore creates a new, empty RevocationStore.
+func NewRevocationStore() *RevocationStore {
+	return &RevocationStore{}
+}
+
+// numBuckets returns the number of filled buckets. Secrets are added in
+// descending order of their index starting from 2^48 - 1, so the bucket for
+// indexes with b trailing zeros is filled once 2^b secrets have been added.
+func (s *RevocationStore) numBuckets() int {
+	n := 0
+	for n <= treeHeight && s.numAdded >= 1<<uint(n) {
+		n++
+	}
+
+	return n
+}
+
+// bucketFor returns the bucket of the secret with the passed index, which is
+// its number of trailing zeros.
+func bucketFor(index uint64) uint8 {
+	var b uint8
+	for b < treeHeight && index&(1<<b) == 0 {
+		b++
+	}
+
+	return b
+}
+
+// AddNext adds the secret revoking the remote commitment following the last
+// one revoked, returning an error if the secret isn't able to derive those
+// it replaces.
+//
+// This is a part of the Store interface.
+func (s *RevocationStore) AddNext(secret *wire.ShaHash) error {
+	index, err := heightToIndex(s.numAdded)
+	if err != nil {
+		return err
+	}
+
+	bucket := bucketFor(index)
+	for b := uint8(0); b < bucket; b++ {
+		stored := &s.buckets[b]
+		derived := deriveSecret([32]byte(*secret), bucket, stored.index)
+		if derived != stored.secret {
+			return fmt.Errorf("secret #%v doesn't derive secret #%v",
+				s.numAdded, startIndex-stored.index)
+		}
+	}
+
+	s.buckets[bucket] = storedSecret{
+		index:  index,
+		secret: [32]byte(*secret),
+	}
+	s.numAdded++
+
+	return nil
+}
+
+// AtIndex returns the secret revoking the remote commitment at the passed
+// height, returning an error if it hasn't been added yet.
+//
+// This is a part of the Store interface.
+func (s *RevocationStore) AtIndex(height uint64) (*wire.ShaHash, error) {
+	if height >= s.numAdded {
+		return nil, fmt.Errorf("store holds %v secrets, less than "+
+			"requested #%v", s.numAdded, height)
+	}
+
+	index, err := heightToIndex(height)
+	if err != nil {
+		return nil, err
+	}
+
+	// The secret is derived from the first bucket whose subtree it lies
+	// within, meaning the indexes only differ within the bits below the
+	// bucket's.
+	for b := 0; b < s.numBuckets(); b++ {
+		stored := &s.buckets[b]
+		if index>>uint(b) != stored.index>>uint(b) {
+			continue
+		}
+
+		secret := wire.ShaHash(deriveSecret(stored.secret, uint8(b),
+			index))
+		return &secret, nil
+	}
+
+	return nil, fmt.Errorf("unable to derive secret #%v", height)
+}
+
+// UpTo returns the height of the last commitment revoked, or zero if none
+// have been.
+//
+// This is a part of the Store interface.
+func (s *RevocationStore) UpTo() uint64 {
+	if s.numAdded == 0 {
+		return 0
+	}
+
+	return s.numAdded - 1
+}
+
+// ToBytes serializes the store as the number of secrets added, followed by
+// the index and secret held within each filled bucket.
+//
+// This is a part of the Store interface.
+func (s *RevocationStore) ToBytes() ([]byte, error) {
+	var b bytes.Buffer
+	if err := binary.Write(&b, binary.BigEndian, s.numAdded); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < s.numBuckets(); i++ {
+		stored := &s.buckets[i]
+		err := binary.Write(&b, binary.BigEndian, stored.index)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := b.Write(stored.secret[:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Bytes(), nil
+}
+
+// RevocationStoreFromBytes deserializes a RevocationStore serialized by
+// ToBytes. An empty slice deserializes to an empty store.
+func RevocationStoreFromBytes(b []byte) (*RevocationStore, error) {
+	s := NewRevocationStore()
+	if len(b) == 0 {
+		return s, nil
+	}
+
+	r := bytes.NewReader(b)
+	if err := binary.Read(r, binary.BigEndian, &s.numAdded); err != nil {
+		return nil, err
+	}
+	if s.numAdded > MaxHeight+1 {
+		return nil, fmt.Errorf("store claims %v secrets, max %v",
+			s.numAdded, uint64(MaxHeight+1))
+	}
+
+	numBuckets := s.numBuckets()
+	if r.Len() != numBuckets*40 {
+		return nil, fmt.Errorf("store of %v secrets has %v bytes of "+
+			"buckets, expected %v", s.numAdded, r.Len(),
+			numBuckets*40)
+	}
+
+	for i := 0; i < numBuckets; i++ {
+		stored := &s.buckets[i]
+		err := binary.Read(r, binary.BigEndian, &stored.index)
+		if err != nil {
+			return nil, err
+		}
+		if int(bucketFor(stored.index)) != i {
+			return nil, fmt.Errorf("secret #%v within wrong bucket %v",
+				startIndex-stored.index, i)
+		}
+		if _, err := r.Read(stored.secret[:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"sync"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 	"github.com/lightningnetwork/lnd/lnwallet"
@@ -12,6 +11,32 @@ import (
 	"github.com/roasbeef/btcutil"
 )
 
+const (
+	// sweepFeeRate is the fee rate, in sat/byte, paid by a sweep, either
+	// out of the satoshis carried by the outputs being swept, or by fuel
+	// when they're unable to pay for the sweep themselves.
+	sweepFeeRate = 10
+
+	// sweepBumpInterval is the number of blocks a sweep transaction may
+	// remain unconfirmed before it's replaced by one paying double the
+	// fee.
+	sweepBumpInterval = 6
+
+	// maxSweepBumps is the maximum number of times the fee of a sweep is
+	// bumped.
+	maxSweepBumps = 4
+
+	// commitBumpInterval is the number of blocks our force closed
+	// commitment transaction may remain unconfirmed before its fee is
+	// bumped by spending our anchor output.
+	commitBumpInterval = 6
+
+	// commitBumpFeeRate is the fee rate, in sat/byte, paid by the child
+	// transaction spending our anchor output, for both itself, and the
+	// commitment transaction.
+	commitBumpFeeRate = sweepFeeRate * 2
+)
+
 // utxoNursery is a system dedicated to incubating time-locked outputs created
 // by the broadcast of a commitment transaction either by us, or the remote
 // peer. The nursery accepts outputs and "incubates" them until they've reached
@@ -24,16 +49,44 @@ type utxoNursery struct {
 	sync.RWMutex
 
 	notifier chainntnfs.ChainNotifier
+	bio      lnwallet.BlockChainIO
 	wallet   *lnwallet.LightningWallet
 
+	// heights schedules the sweep of staged outputs once they mature,
+	// and the fee bumps of unconfirmed sweeps.
+	heights *chainntnfs.HeightScheduler
+
 	db channeldb.DB
 
 	requests chan *incubationRequest
 
+	// sweepReqs is used to query the outputs awaiting their sweep.
+	sweepReqs chan chan []*lnwallet.SweepInfo
+
 	// TODO(roasbeef): persist to disk afterwards
 	unstagedOutputs map[wire.OutPoint]*immatureOutput
 	stagedOutputs   map[uint32][]*immatureOutput
 
+	// matured is sent upon by the tasks scheduled within stageOutput
+	// once the outputs staged at the sent maturity height may be swept.
+	matured chan uint32
+
+	// sweeps tracks the broadcast sweep transactions which have yet to
+	// confirm, keyed by txid, so their fees may be bumped. sweepConfs is
+	// sent upon once a sweep transaction confirms.
+	sweeps     map[wire.ShaHash]*pendingSweepTx
+	sweepConfs chan wire.ShaHash
+
+	// sweepBumps is sent upon by the tasks scheduled within trackSweep
+	// once a sweep has remained unconfirmed for sweepBumpInterval
+	// blocks.
+	sweepBumps chan wire.ShaHash
+
+	// commitSpends is sent upon by the goroutines launched within
+	// watchCommitSpends once an output of a force closed commitment has
+	// been spent.
+	commitSpends chan *lnwallet.CommitOutputSpend
+
 	started uint32
 	stopped uint32
 	quit    chan struct{}
@@ -41,16 +94,23 @@ type utxoNursery struct {
 }
 
 // newUtxoNursery creates a new instance of the utxoNursery from a
-// ChainNotifier and LightningWallet instance.
-func newUtxoNursery(notifier chainntnfs.ChainNotifier,
+// ChainNotifier, BlockChainIO, and LightningWallet instance.
+func newUtxoNursery(notifier chainntnfs.ChainNotifier, bio lnwallet.BlockChainIO,
 	wallet *lnwallet.LightningWallet) *utxoNursery {
 
 	return &utxoNursery{
 		notifier:        notifier,
+		bio:             bio,
 		wallet:          wallet,
 		requests:        make(chan *incubationRequest),
+		sweepReqs:       make(chan chan []*lnwallet.SweepInfo),
 		unstagedOutputs: make(map[wire.OutPoint]*immatureOutput),
 		stagedOutputs:   make(map[uint32][]*immatureOutput),
+		matured:         make(chan uint32),
+		sweeps:          make(map[wire.ShaHash]*pendingSweepTx),
+		sweepConfs:      make(chan wire.ShaHash),
+		sweepBumps:      make(chan wire.ShaHash),
+		commitSpends:    make(chan *lnwallet.CommitOutputSpend),
 		quit:            make(chan struct{}),
 	}
 }
@@ -58,6 +118,21 @@ func newUtxoNursery(notifier chainntnfs.ChainNotifier,
 // Start launches all goroutines the utxoNursery needs to properly carry out
 // its duties.
 func (u *utxoNursery) Start() error {
+	// Register with the notifier to receive notifications for each newly
+	// connected block, driving the scheduler of our sweeps.
+	newBlocks, err := u.notifier.RegisterBlockEpochNtfn()
+	if err != nil {
+		return err
+	}
+	bestHeight, err := u.bio.GetCurrentHeight()
+	if err != nil {
+		return err
+	}
+	u.heights = chainntnfs.NewHeightScheduler(newBlocks, bestHeight)
+	if err := u.heights.Start(); err != nil {
+		return err
+	}
+
 	u.wg.Add(1)
 	go u.incubator()
 
@@ -67,6 +142,10 @@ func (u *utxoNursery) Start() error {
 // Stop gracefully shutsdown any lingering goroutines launched during normal
 // operation of the utxoNursery.
 func (u *utxoNursery) Stop() error {
+	// The scheduler is stopped first, so no further tasks are triggered
+	// once we start waiting on our goroutines.
+	u.heights.Stop()
+
 	close(u.quit)
 	u.wg.Wait()
 	return nil
@@ -82,14 +161,6 @@ func (u *utxoNursery) Stop() error {
 // "maturity". Once an output is mature, it will be sweeped into the wallet at
 // the earlier possible height.
 func (u *utxoNursery) incubator() {
-	// Register with the notifier to receive notifications for each newly
-	// connected block.
-	newBlocks, err := u.notifier.RegisterBlockEpochNtfn()
-	if err != nil {
-		utxnLog.Errorf("unable to register for block epoch "+
-			"notifications: %v", err)
-	}
-
 	// Outputs that are transitioning from early to mid-stage are sent over
 	// this channel by each output's dedicated watcher goroutine.
 	midStageOutputs := make(chan *immatureOutput)
@@ -137,59 +208,74 @@ out:
 		case midUtxo := <-midStageOutputs:
 			// The transaction creating the output has been
 			// created, so we move it from early stage to
-			// mid-stage.
+			// mid-stage, unless it's already been spent.
+			if _, ok := u.unstagedOutputs[midUtxo.outPoint]; !ok {
+				continue
+			}
 			delete(u.unstagedOutputs, midUtxo.outPoint)
 
-			// TODO(roasbeef): your off-by-one sense are tingling...
-			maturityHeight := midUtxo.confHeight + midUtxo.blocksToMaturity
-			u.stagedOutputs[maturityHeight] = append(u.stagedOutputs[maturityHeight], midUtxo)
-
-			utxnLog.Infof("Outpoint %v now mid-stage, will mature "+
-				"at height %v (delay of %v)", midUtxo.outPoint,
-				maturityHeight, midUtxo.blocksToMaturity)
-		case epoch := <-newBlocks.Epochs:
-			// A new block has just been connected, check to see if
-			// we have any new outputs that can be swept into the
-			// wallet.
-			newHeight := uint32(epoch.Height)
-			matureOutputs, ok := u.stagedOutputs[newHeight]
+			u.stageOutput(midUtxo)
+		case maturityHeight := <-u.matured:
+			// The outputs staged at this height have matured, so
+			// they can now be swept into the wallet, unless
+			// they've all been spent in the meantime.
+			matureOutputs, ok := u.stagedOutputs[maturityHeight]
 			if !ok {
 				continue
 			}
 
-			utxnLog.Infof("New block: height=%v hash=%v, "+
-				"sweeping %v mature outputs", newHeight,
-				epoch.Hash, len(matureOutputs))
+			height := uint32(u.heights.BestHeight())
+			utxnLog.Infof("Height %v reached, sweeping %v outputs "+
+				"matured at height %v", height,
+				len(matureOutputs), maturityHeight)
 
 			// Create a transation which sweeps all the newly
 			// mature outputs into a output controlled by the
-			// wallet.
+			// wallet. Should we fail to do so, the sweep is
+			// retried with the next block.
 			// TODO(roasbeef): can be more intelligent about
 			// buffering outputs to be more efficient on-chain.
-			sweepTx, err := u.createSweepTx(matureOutputs)
+			sweepTx, err := u.createSweepTx(matureOutputs, 0)
 			if err != nil {
-				// TODO(roasbeef): retry logic?
 				utxnLog.Errorf("unable to create sweep tx: %v", err)
+				u.scheduleSweep(maturityHeight, height+1)
 				continue
 			}
 
 			utxnLog.Infof("Sweeping %v time-locked outputs "+
 				"with sweep tx: %v", len(matureOutputs),
-				newLogClosure(func() string {
-					return spew.Sdump(sweepTx)
-				}))
+				newTxLogClosure(sweepTx, ""))
 
 			// With the sweep transaction fully signed, broadcast
 			// the transaction to the network. Additionally, we can
 			// stop tracking these outputs as they've just been
-			// sweeped.
-			err = u.wallet.PublishTransaction(sweepTx)
+			// sweeped. A sweep may span several channels, so it
+			// isn't attributed to any single one.
+			err = u.wallet.PublishAuditedTransaction(sweepTx,
+				channeldb.TxSweep, wire.OutPoint{}, "")
 			if err != nil {
 				utxnLog.Errorf("unable to broadcast sweep tx: %v, %v",
-					err, spew.Sdump(sweepTx))
+					err, newTxLogClosure(sweepTx, ""))
+				u.scheduleSweep(maturityHeight, height+1)
 				continue
 			}
-			delete(u.stagedOutputs, newHeight)
+			delete(u.stagedOutputs, maturityHeight)
+
+			u.trackSweep(sweepTx, &pendingSweepTx{
+				outputs:         matureOutputs,
+				broadcastHeight: height,
+			})
+		case txid := <-u.sweepBumps:
+			u.bumpSweep(txid)
+		case txid := <-u.sweepConfs:
+			if _, ok := u.sweeps[txid]; ok {
+				utxnLog.Infof("Sweep tx %v confirmed", txid)
+				delete(u.sweeps, txid)
+			}
+		case spend := <-u.commitSpends:
+			u.handleCommitSpend(spend)
+		case resp := <-u.sweepReqs:
+			resp <- u.pendingSweeps()
 		case <-u.quit:
 			break out
 		}
@@ -198,11 +284,280 @@ out:
 	u.wg.Done()
 }
 
+// stageOutput stages the passed output, whose source transaction has been
+// confirmed, at its maturity height, scheduling the sweep of the outputs
+// staged at that height if it's the first to be staged there. This method
+// MUST only be called by the incubator.
+func (u *utxoNursery) stageOutput(midUtxo *immatureOutput) {
+	// TODO(roasbeef): your off-by-one sense are tingling...
+	maturityHeight := midUtxo.confHeight + midUtxo.blocksToMaturity
+	u.stagedOutputs[maturityHeight] = append(u.stagedOutputs[maturityHeight], midUtxo)
+
+	utxnLog.Infof("Outpoint %v now mid-stage, will mature "+
+		"at height %v (delay of %v)", midUtxo.outPoint,
+		maturityHeight, midUtxo.blocksToMaturity)
+
+	if len(u.stagedOutputs[maturityHeight]) == 1 {
+		u.scheduleSweep(maturityHeight, maturityHeight)
+	}
+}
+
+// scheduleSweep schedules the sweep of the outputs staged at the passed
+// maturity height once the main chain reaches the target height. If the
+// target height has already been reached, the sweep is triggered with the
+// next iteration of the incubator.
+func (u *utxoNursery) scheduleSweep(maturityHeight, height uint32) {
+	err := u.heights.ScheduleAt(int32(height), func(int32) {
+		u.notifyIncubator(func() {
+			select {
+			case u.matured <- maturityHeight:
+			case <-u.quit:
+			}
+		})
+	})
+	if err != nil {
+		utxnLog.Errorf("unable to schedule sweep of outputs maturing "+
+			"at height %v: %v", maturityHeight, err)
+	}
+}
+
+// notifyIncubator executes the passed function, which sends upon one of the
+// incubator's channels, within a goroutine. Tasks are executed from within
+// the scheduler, or inline when scheduled by the incubator itself, so they
+// must never block on the incubator.
+func (u *utxoNursery) notifyIncubator(notify func()) {
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		notify()
+	}()
+}
+
+// watchCommitSpends forwards the spends of the outputs of the passed force
+// closed channel's commitment to the incubator, until the delayed output
+// paying to us has been spent. The nursery takes ownership of the channel,
+// stopping it once its outputs no longer need to be watched.
+func (u *utxoNursery) watchCommitSpends(channel *lnwallet.LightningChannel,
+	selfOutpoint wire.OutPoint) {
+
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		defer channel.Stop()
+
+		for {
+			select {
+			case spend := <-channel.CommitOutputSpends:
+				select {
+				case u.commitSpends <- spend:
+				case <-u.quit:
+					return
+				}
+
+				if spend.OutPoint == selfOutpoint {
+					return
+				}
+			case <-u.quit:
+				return
+			}
+		}
+	}()
+}
+
+// handleCommitSpend handles the spend of an output of a force closed
+// commitment. Our delayed output is expected to be spent by one of our sweep
+// transactions, if it's spent by any other transaction, then the output is
+// no longer incubated. This method MUST only be called by the incubator.
+func (u *utxoNursery) handleCommitSpend(spend *lnwallet.CommitOutputSpend) {
+	spenderTxid := spend.SpendDetail.SpenderTxHash
+
+	// TODO: sweep the HTLC outputs of force closed commitments.
+	if spend.IsHTLC {
+		utxnLog.Infof("HTLC output %v of commitment height %v spent "+
+			"by %v", spend.OutPoint, spend.CommitHeight, spenderTxid)
+		return
+	}
+
+	if _, ok := u.sweeps[*spenderTxid]; ok {
+		return
+	}
+
+	if _, ok := u.unstagedOutputs[spend.OutPoint]; ok {
+		delete(u.unstagedOutputs, spend.OutPoint)
+		utxnLog.Warnf("Immature output %v spent by %v, no longer "+
+			"incubating", spend.OutPoint, spenderTxid)
+		return
+	}
+	for maturityHeight, outputs := range u.stagedOutputs {
+		for i, output := range outputs {
+			if output.outPoint != spend.OutPoint {
+				continue
+			}
+
+			outputs = append(outputs[:i], outputs[i+1:]...)
+			if len(outputs) == 0 {
+				delete(u.stagedOutputs, maturityHeight)
+			} else {
+				u.stagedOutputs[maturityHeight] = outputs
+			}
+
+			utxnLog.Warnf("Immature output %v spent by %v, no "+
+				"longer incubating", spend.OutPoint, spenderTxid)
+			return
+		}
+	}
+}
+
+// pendingSweeps returns all outputs being incubated, along with the height at
+// which each matures. This method MUST only be called by the incubator.
+func (u *utxoNursery) pendingSweeps() []*lnwallet.SweepInfo {
+	var sweeps []*lnwallet.SweepInfo
+	for _, output := range u.unstagedOutputs {
+		sweeps = append(sweeps, &lnwallet.SweepInfo{
+			OutPoint: output.outPoint,
+			Amount:   output.amt,
+		})
+	}
+	for maturityHeight, outputs := range u.stagedOutputs {
+		for _, output := range outputs {
+			sweeps = append(sweeps, &lnwallet.SweepInfo{
+				OutPoint:       output.outPoint,
+				Amount:         output.amt,
+				MaturityHeight: maturityHeight,
+			})
+		}
+	}
+
+	return sweeps
+}
+
+// PendingSweeps returns all outputs awaiting their sweep into the wallet.
+func (u *utxoNursery) PendingSweeps() []*lnwallet.SweepInfo {
+	resp := make(chan []*lnwallet.SweepInfo, 1)
+	select {
+	case u.sweepReqs <- resp:
+	case <-u.quit:
+		return nil
+	}
+
+	return <-resp
+}
+
+// pendingSweepTx is a broadcast sweep transaction which has yet to confirm.
+type pendingSweepTx struct {
+	// outputs are the mature outputs swept by the transaction.
+	outputs []*immatureOutput
+
+	// broadcastHeight is the height at which the transaction was last
+	// broadcast.
+	broadcastHeight uint32
+
+	// numBumps is the number of times the transaction's fee has been
+	// bumped.
+	numBumps uint32
+}
+
+// trackSweep tracks the passed sweep transaction until it confirms, so its
+// fee may be bumped should it fail to confirm in a timely manner. This method
+// MUST only be called by the incubator.
+func (u *utxoNursery) trackSweep(sweepTx *wire.MsgTx, sweep *pendingSweepTx) {
+	txid := sweepTx.TxSha()
+	confChan, err := u.notifier.RegisterConfirmationsNtfn(&txid, 1)
+	if err != nil {
+		utxnLog.Errorf("unable to register for confirmation of sweep "+
+			"tx %v: %v", txid, err)
+		return
+	}
+
+	u.sweeps[txid] = sweep
+
+	// Should the sweep remain unconfirmed for sweepBumpInterval blocks,
+	// then its fee is bumped.
+	u.scheduleBump(txid, sweep.broadcastHeight+sweepBumpInterval)
+
+	go func() {
+		if _, ok := <-confChan.Confirmed; !ok {
+			return
+		}
+
+		select {
+		case u.sweepConfs <- txid:
+		case <-u.quit:
+		}
+	}()
+}
+
+// scheduleBump schedules the fee bump of the target sweep transaction once
+// the main chain reaches the passed height.
+func (u *utxoNursery) scheduleBump(txid wire.ShaHash, height uint32) {
+	err := u.heights.ScheduleAt(int32(height), func(int32) {
+		u.notifyIncubator(func() {
+			select {
+			case u.sweepBumps <- txid:
+			case <-u.quit:
+			}
+		})
+	})
+	if err != nil {
+		utxnLog.Errorf("unable to schedule fee bump of sweep tx "+
+			"%v: %v", txid, err)
+	}
+}
+
+// bumpSweep replaces the target sweep transaction, which has remained
+// unconfirmed for sweepBumpInterval blocks, with one paying double the fee.
+// As the inputs of a sweep transaction spend time-locked outputs, their
+// sequence numbers signal replace-by-fee as per BIP 125. Should we fail to
+// replace the sweep, then the fee bump is retried with the next block. This
+// method MUST only be called by the incubator.
+func (u *utxoNursery) bumpSweep(txid wire.ShaHash) {
+	// If the sweep has confirmed in the meantime, or its fee has been
+	// bumped as often as we allow, then there's nothing left to do.
+	sweep, ok := u.sweeps[txid]
+	if !ok || sweep.numBumps >= maxSweepBumps {
+		return
+	}
+
+	height := uint32(u.heights.BestHeight())
+	sweepTx, err := u.createSweepTx(sweep.outputs, sweep.numBumps+1)
+	if err != nil {
+		utxnLog.Errorf("unable to create fee bump of sweep tx "+
+			"%v: %v", txid, err)
+		u.scheduleBump(txid, height+1)
+		return
+	}
+
+	utxnLog.Infof("Sweep tx %v unconfirmed after %v blocks, "+
+		"replacing with %v", txid, height-sweep.broadcastHeight,
+		sweepTx.TxSha())
+
+	err = u.wallet.PublishAuditedTransaction(sweepTx,
+		channeldb.TxSweep, wire.OutPoint{}, "")
+	if err != nil {
+		utxnLog.Errorf("unable to broadcast fee bump of sweep "+
+			"tx %v: %v", txid, err)
+		u.scheduleBump(txid, height+1)
+		return
+	}
+
+	delete(u.sweeps, txid)
+	u.trackSweep(sweepTx, &pendingSweepTx{
+		outputs:         sweep.outputs,
+		broadcastHeight: height,
+		numBumps:        sweep.numBumps + 1,
+	})
+}
+
 // createSweepTx creates a final sweeping transaction with all witnesses
 // inplace for all inputs. The created transaction has a single output sending
-// all the funds back to the source wallet.
-func (u *utxoNursery) createSweepTx(matureOutputs []*immatureOutput) (*wire.MsgTx, error) {
-	sweepAddr, err := u.wallet.NewAddress(lnwallet.WitnessPubKey, false)
+// all the funds back to the source wallet, with the asset amounts of the
+// swept outputs re-encoded as a transfer instruction to it. The fee rate paid
+// is doubled for each of the passed number of fee bumps.
+func (u *utxoNursery) createSweepTx(matureOutputs []*immatureOutput,
+	numBumps uint32) (*wire.MsgTx, error) {
+
+	sweepAddr, err := u.wallet.NewChannelAddress(lnwallet.WitnessPubKey,
+		false)
 	if err != nil {
 		return nil, err
 	}
@@ -211,38 +566,56 @@ func (u *utxoNursery) createSweepTx(matureOutputs []*immatureOutput) (*wire.MsgT
 		return nil, err
 	}
 
-	var totalSum btcutil.Amount
-	for _, o := range matureOutputs {
-		totalSum += o.amt
-	}
+	feeRate := uint64(sweepFeeRate << numBumps)
 
-	sweepTx := wire.NewMsgTx()
-	sweepTx.Version = 2
-	sweepTx.AddTxOut(&wire.TxOut{
-		PkScript: pkScript,
-		Value:    int64(totalSum - 1000),
-	})
+	inputs := make([]*lnwallet.SweepInput, 0, len(matureOutputs))
 	for _, utxo := range matureOutputs {
-		sweepTx.AddTxIn(&wire.TxIn{
-			PreviousOutPoint: utxo.outPoint,
+		inputs = append(inputs, &lnwallet.SweepInput{
+			OutPoint: utxo.outPoint,
+			Value:    utxo.carrierAmt,
+			AssetAmt: utxo.amt,
 			// TODO(roasbeef): assumes pure block delays
-			Sequence: utxo.blocksToMaturity,
+			Sequence:    utxo.blocksToMaturity,
+			WitnessSize: utxo.witnessSize,
 		})
 	}
-
-	// TODO(roasbeef): insert fee calculation
-	//  * remove hardcoded fee above
+	sweep, err := lnwallet.BuildColoredSweep(inputs, pkScript, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	sweepTx := sweep.Tx
+
+	// The outputs being swept are dust carriers, which may carry too few
+	// satoshis to pay for the sweep. If so, then the shortfall in funding
+	// the sweep output's dust carrier, along with the fee, is paid for
+	// with fuel from the wallet.
+	if sweep.Fee < sweep.MinFee {
+		var shortfall btcutil.Amount
+		if sweep.Fee < 0 {
+			shortfall = -sweep.Fee
+		}
+		if err := u.wallet.AddFuel(sweepTx, shortfall, feeRate); err != nil {
+			return nil, err
+		}
+	}
 
 	// With all the inputs in place, use each output's unique witness
-	// function to generate the final witness required for spending.
+	// function to generate the final witness required for spending. Any
+	// fuel inputs follow the mature outputs, and are signed for by the
+	// wallet.
 	hashCache := txscript.NewTxSigHashes(sweepTx)
-	for i, txIn := range sweepTx.TxIn {
-		witness, err := matureOutputs[i].witnessFunc(sweepTx, hashCache, i)
+	for i, output := range matureOutputs {
+		witness, err := output.witnessFunc(sweepTx, hashCache, i)
 		if err != nil {
 			return nil, err
 		}
 
-		txIn.Witness = witness
+		sweepTx.TxIn[i].Witness = witness
+	}
+	if len(sweepTx.TxIn) > len(matureOutputs) {
+		if err := u.wallet.SignFuel(sweepTx, hashCache); err != nil {
+			return nil, err
+		}
 	}
 
 	return sweepTx, nil
@@ -259,8 +632,15 @@ type witnessGenerator func(tx *wire.MsgTx, hc *txscript.TxSigHashes, inputIndex
 // to sweep the output once it's mature.
 // TODO(roasbeef): make into interface?  can't gob functions
 type immatureOutput struct {
-	amt      btcutil.Amount
-	outPoint wire.OutPoint
+	// amt is the amount of the channel's asset assigned to the output,
+	// while carrierAmt is the amount of satoshis carried by it.
+	amt        btcutil.Amount
+	carrierAmt btcutil.Amount
+	outPoint   wire.OutPoint
+
+	// witnessSize is the size of the witness spending the output beyond
+	// that of a p2wkh output, used to estimate the fee of its sweep.
+	witnessSize int
 
 	witnessFunc witnessGenerator
 
@@ -291,10 +671,12 @@ func (u *utxoNursery) incubateOutputs(closeSummary *lnwallet.ForceCloseSummary)
 		return lnwallet.CommitSpendTimeout(u.wallet.Signer, desc, tx)
 	}
 
-	outputAmt := btcutil.Amount(closeSummary.SelfOutputSignDesc.Output.Value)
+	signDesc := closeSummary.SelfOutputSignDesc
 	selfOutput := &immatureOutput{
-		amt:              outputAmt,
+		amt:              closeSummary.SelfOutputAssetAmt,
+		carrierAmt:       btcutil.Amount(signDesc.Output.Value),
 		outPoint:         closeSummary.SelfOutpoint,
+		witnessSize:      len(signDesc.RedeemScript),
 		witnessFunc:      witnessFunc,
 		blocksToMaturity: closeSummary.SelfOutputMaturity,
 	}
@@ -302,4 +684,101 @@ func (u *utxoNursery) incubateOutputs(closeSummary *lnwallet.ForceCloseSummary)
 	u.requests <- &incubationRequest{
 		outputs: []*immatureOutput{selfOutput},
 	}
+
+	u.watchCommitConf(closeSummary)
+}
+
+// watchCommitConf fee bumps the commitment transaction within the passed
+// summary via CPFP, should it remain unconfirmed for commitBumpInterval
+// blocks.
+func (u *utxoNursery) watchCommitConf(closeSummary *lnwallet.ForceCloseSummary) {
+	if closeSummary.AnchorSignDesc == nil {
+		return
+	}
+
+	commitTxid := closeSummary.CloseTx.TxSha()
+	confChan, err := u.notifier.RegisterConfirmationsNtfn(&commitTxid, 1)
+	if err != nil {
+		utxnLog.Errorf("unable to register for confirmation of "+
+			"commitment tx %v: %v", commitTxid, err)
+		return
+	}
+
+	bump := make(chan struct{}, 1)
+	height := u.heights.BestHeight() + commitBumpInterval
+	err = u.heights.ScheduleAt(height, func(int32) {
+		bump <- struct{}{}
+	})
+	if err != nil {
+		utxnLog.Errorf("unable to schedule fee bump of commitment tx "+
+			"%v: %v", commitTxid, err)
+		return
+	}
+
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+
+		select {
+		case <-confChan.Confirmed:
+		case <-bump:
+			if err := u.bumpCommit(closeSummary); err != nil {
+				utxnLog.Errorf("unable to fee bump commitment "+
+					"tx %v: %v", commitTxid, err)
+			}
+		case <-u.quit:
+		}
+	}()
+}
+
+// bumpCommit broadcasts a child transaction spending our anchor output of the
+// commitment transaction within the passed summary, paying the fee of both
+// the child, and the commitment transaction at commitBumpFeeRate. As the
+// anchor carries too few satoshis to pay the fee, the child is fueled by the
+// wallet.
+func (u *utxoNursery) bumpCommit(closeSummary *lnwallet.ForceCloseSummary) error {
+	sweepAddr, err := u.wallet.NewChannelAddress(lnwallet.WitnessPubKey,
+		false)
+	if err != nil {
+		return err
+	}
+	pkScript, err := txscript.PayToAddrScript(sweepAddr)
+	if err != nil {
+		return err
+	}
+
+	// The anchor's satoshis are returned to the wallet, while the fuel
+	// pays the fee of the commitment transaction on top of the child's.
+	anchorSignDesc := *closeSummary.AnchorSignDesc
+	childTx := wire.NewMsgTx()
+	childTx.Version = 2
+	childTx.AddTxIn(wire.NewTxIn(&closeSummary.AnchorOutpoint, nil, nil))
+	childTx.AddTxOut(wire.NewTxOut(anchorSignDesc.Output.Value, pkScript))
+
+	commitFee := btcutil.Amount(closeSummary.CloseTx.SerializeSize() *
+		commitBumpFeeRate)
+	err = u.wallet.AddFuel(childTx, commitFee, commitBumpFeeRate)
+	if err != nil {
+		return err
+	}
+
+	hashCache := txscript.NewTxSigHashes(childTx)
+	anchorSignDesc.SigHashes = hashCache
+	anchorSignDesc.InputIndex = 0
+	witness, err := lnwallet.CommitSpendAnchor(u.wallet.Signer,
+		&anchorSignDesc, childTx)
+	if err != nil {
+		return err
+	}
+	childTx.TxIn[0].Witness = witness
+	if err := u.wallet.SignFuel(childTx, hashCache); err != nil {
+		return err
+	}
+
+	utxnLog.Infof("Commitment tx %v unconfirmed after %v blocks, fee "+
+		"bumping with child tx %v", closeSummary.CloseTx.TxSha(),
+		commitBumpInterval, childTx.TxSha())
+
+	return u.wallet.PublishAuditedTransaction(childTx, channeldb.TxSweep,
+		wire.OutPoint{}, "")
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestHandleCommitSpend tests that an incubated output spent by any
+// transaction other than one of our sweeps is no longer incubated, while
+// outputs spent by our sweeps, and HTLC outputs are left as is.
+func TestHandleCommitSpend(t *testing.T) {
+	u := newUtxoNursery(nil, nil, nil)
+
+	commitTxid := wire.ShaHash{1}
+	sweepTxid := wire.ShaHash{2}
+	staged := &immatureOutput{
+		outPoint: wire.OutPoint{Hash: commitTxid, Index: 0},
+	}
+	unstaged := &immatureOutput{
+		outPoint: wire.OutPoint{Hash: commitTxid, Index: 1},
+	}
+	swept := &immatureOutput{
+		outPoint: wire.OutPoint{Hash: commitTxid, Index: 2},
+	}
+	other := &immatureOutput{
+		outPoint: wire.OutPoint{Hash: wire.ShaHash{3}, Index: 0},
+	}
+	u.stagedOutputs[100] = []*immatureOutput{staged, other}
+	u.unstagedOutputs[unstaged.outPoint] = unstaged
+	u.sweeps[sweepTxid] = &pendingSweepTx{
+		outputs: []*immatureOutput{swept},
+	}
+
+	spend := func(op wire.OutPoint, spender wire.ShaHash, isHTLC bool) {
+		u.handleCommitSpend(&lnwallet.CommitOutputSpend{
+			OutPoint: op,
+			IsHTLC:   isHTLC,
+			SpendDetail: &chainntnfs.SpendDetail{
+				SpentOutPoint: &op,
+				SpenderTxHash: &spender,
+			},
+		})
+	}
+
+	// Neither the spend of an HTLC output, nor that of our own sweep
+	// affects the incubated outputs.
+	spend(staged.outPoint, wire.ShaHash{4}, true)
+	spend(swept.outPoint, sweepTxid, false)
+	if len(u.stagedOutputs[100]) != 2 || len(u.unstagedOutputs) != 1 ||
+		len(u.sweeps) != 1 {
+
+		t.Fatalf("incubated outputs modified by htlc or sweep spend")
+	}
+
+	// Once spent by another transaction, the outputs are dropped.
+	spend(staged.outPoint, wire.ShaHash{4}, false)
+	if outputs := u.stagedOutputs[100]; len(outputs) != 1 ||
+		outputs[0] != other {
+
+		t.Fatalf("staged output still incubated")
+	}
+	spend(unstaged.outPoint, wire.ShaHash{4}, false)
+	if len(u.unstagedOutputs) != 0 {
+		t.Fatalf("unstaged output still incubated")
+	}
+
+	spend(other.outPoint, wire.ShaHash{4}, false)
+	if _, ok := u.stagedOutputs[100]; ok {
+		t.Fatalf("empty maturity height not removed")
+	}
+}
+
+// TestNurserySchedulesSweeps tests that the sweep of staged outputs is
+// triggered once, and only once, the main chain reaches their maturity
+// height, and that fee bumps are triggered at their target height.
+func TestNurserySchedulesSweeps(t *testing.T) {
+	u := newUtxoNursery(nil, nil, nil)
+
+	epochs := &chainntnfs.BlockEpochEvent{
+		Epochs: make(chan *chainntnfs.BlockEpoch),
+	}
+	u.heights = chainntnfs.NewHeightScheduler(epochs, 100)
+	if err := u.heights.Start(); err != nil {
+		t.Fatalf("unable to start scheduler: %v", err)
+	}
+	defer u.Stop()
+
+	newBlock := func(height int32) {
+		epochs.Epochs <- &chainntnfs.BlockEpoch{Height: height}
+	}
+	expectMatured := func(height uint32) {
+		select {
+		case maturityHeight := <-u.matured:
+			if maturityHeight != height {
+				t.Fatalf("expected outputs matured at height "+
+					"%v, got %v", height, maturityHeight)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("outputs maturing at height %v not swept",
+				height)
+		}
+	}
+	expectNone := func() {
+		select {
+		case height := <-u.matured:
+			t.Fatalf("unexpected sweep of outputs matured at "+
+				"height %v", height)
+		case txid := <-u.sweepBumps:
+			t.Fatalf("unexpected fee bump of %v", txid)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// Two outputs maturing at the same height only trigger a single
+	// sweep, once that height has been reached.
+	for i := uint32(0); i < 2; i++ {
+		u.stageOutput(&immatureOutput{
+			outPoint:         wire.OutPoint{Index: i},
+			confHeight:       100,
+			blocksToMaturity: 2,
+		})
+	}
+	if len(u.stagedOutputs[102]) != 2 {
+		t.Fatalf("outputs not staged at their maturity height")
+	}
+	newBlock(101)
+	expectNone()
+	newBlock(102)
+	expectMatured(102)
+	expectNone()
+
+	// An output which has already matured by the time it's staged is
+	// swept right away.
+	u.stageOutput(&immatureOutput{
+		outPoint:         wire.OutPoint{Index: 2},
+		confHeight:       90,
+		blocksToMaturity: 2,
+	})
+	expectMatured(92)
+
+	// Finally, a fee bump is only triggered at its target height.
+	sweepTxid := wire.ShaHash{1}
+	u.scheduleBump(sweepTxid, 104)
+	newBlock(103)
+	expectNone()
+	newBlock(104)
+	select {
+	case txid := <-u.sweepBumps:
+		if txid != sweepTxid {
+			t.Fatalf("expected fee bump of %v, got %v", sweepTxid,
+				txid)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("fee bump not triggered")
+	}
+}
@@ -0,0 +1,253 @@
+package watchtower
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/codahale/chacha20poly1305"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// BreachHintSize is the length of a BreachHint.
+const BreachHintSize = 16
+
+// byteOrder is the byte order used to serialize all integers within a
+// justice kit.
+var byteOrder = binary.BigEndian
+
+// BreachHint is the prefix of the txid of a revoked commitment transaction.
+// Encrypted justice blobs are indexed by their breach hint, allowing a tower
+// to efficiently detect if a transaction within a newly connected block is a
+// revoked commitment for which it holds a justice transaction.
+type BreachHint [BreachHintSize]byte
+
+// NewBreachHint returns the breach hint for the passed commitment txid.
+func NewBreachHint(txid *wire.ShaHash) BreachHint {
+	var hint BreachHint
+	copy(hint[:], txid[:BreachHintSize])
+	return hint
+}
+
+// String returns the hex encoding of the breach hint.
+func (b BreachHint) String() string {
+	return hex.EncodeToString(b[:])
+}
+
+// maxFeeBumps is the maximum number of fee bumped justice transactions a
+// single kit may hold.
+const maxFeeBumps = 16
+
+// JusticeKit is the plaintext contents of an encrypted justice blob. The kit
+// holds a fully signed justice transaction sweeping the outputs of a single
+// revoked commitment, along with the colored coins transfer instructions
+// embedded within it.
+type JusticeKit struct {
+	// JusticeTx is the fully signed justice transaction.
+	JusticeTx *wire.MsgTx
+
+	// Instructions is the set of colored coins transfer instructions
+	// encoded within the OP_RETURN output of the justice transaction.
+	Instructions []lndcc.Instruction
+
+	// FeeBumps is a set of fully signed replacements of the justice
+	// transaction, in order of increasing fee. Each spends the same
+	// outputs, and carries the same instructions as the JusticeTx. Should
+	// the justice transaction fail to confirm, the tower broadcasts each
+	// in turn as the breaching party's delayed output nears maturity.
+	FeeBumps []*wire.MsgTx
+
+	// CsvDelay is the relative delay in blocks of the breaching party's
+	// output within the revoked commitment. The justice transaction must
+	// confirm within this many blocks of the revoked commitment, or the
+	// breaching party may sweep its output first.
+	CsvDelay uint32
+}
+
+// Encrypt serializes, then encrypts the justice kit using a key derived from
+// the txid of the revoked commitment it sweeps. As a result, the tower is
+// only able to decrypt the kit once the revoked commitment is broadcast. The
+// returned blob is: nonce || ciphertext.
+func (k *JusticeKit) Encrypt(commitTxid *wire.ShaHash) ([]byte, error) {
+	var plaintext bytes.Buffer
+	if err := k.Encode(&plaintext); err != nil {
+		return nil, err
+	}
+
+	key := fastsha256.Sum256(commitTxid[:])
+	aead, err := chacha20poly1305.New(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext.Bytes(), nil), nil
+}
+
+// DecryptJusticeKit decrypts, then decodes the justice kit within the passed
+// blob using the txid of the revoked commitment the kit sweeps.
+func DecryptJusticeKit(blob []byte, commitTxid *wire.ShaHash) (*JusticeKit, error) {
+	key := fastsha256.Sum256(commitTxid[:])
+	aead, err := chacha20poly1305.New(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(blob) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("justice blob too short")
+	}
+	nonce := blob[:aead.NonceSize()]
+
+	plaintext, err := aead.Open(nil, nonce, blob[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &JusticeKit{}
+	if err := k.Decode(bytes.NewReader(plaintext)); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Encode serializes the justice kit into the passed io.Writer.
+func (k *JusticeKit) Encode(w io.Writer) error {
+	var tx bytes.Buffer
+	if err := k.JusticeTx.Serialize(&tx); err != nil {
+		return err
+	}
+	if err := wire.WriteVarBytes(w, 0, tx.Bytes()); err != nil {
+		return err
+	}
+
+	numInsts := uint64(len(k.Instructions))
+	if err := wire.WriteVarInt(w, 0, numInsts); err != nil {
+		return err
+	}
+	for _, inst := range k.Instructions {
+		var flags byte
+		if inst.Skip {
+			flags |= 1 << 0
+		}
+		if inst.Range {
+			flags |= 1 << 1
+		}
+		if inst.Percent {
+			flags |= 1 << 2
+		}
+
+		var scratch [13]byte
+		scratch[0] = flags
+		byteOrder.PutUint32(scratch[1:5], inst.Output)
+		byteOrder.PutUint64(scratch[5:], uint64(inst.Amount))
+		if _, err := w.Write(scratch[:]); err != nil {
+			return err
+		}
+	}
+
+	// The fee bumps follow the instructions, so kits created before they
+	// were introduced remain decodable.
+	if len(k.FeeBumps) == 0 {
+		return nil
+	}
+	if err := wire.WriteVarInt(w, 0, uint64(len(k.FeeBumps))); err != nil {
+		return err
+	}
+	for _, bump := range k.FeeBumps {
+		tx.Reset()
+		if err := bump.Serialize(&tx); err != nil {
+			return err
+		}
+		if err := wire.WriteVarBytes(w, 0, tx.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	var scratch [4]byte
+	byteOrder.PutUint32(scratch[:], k.CsvDelay)
+	_, err := w.Write(scratch[:])
+	return err
+}
+
+// Decode deserializes a justice kit from the passed io.Reader.
+func (k *JusticeKit) Decode(r io.Reader) error {
+	txBytes, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+		"justice tx")
+	if err != nil {
+		return err
+	}
+	k.JusticeTx = wire.NewMsgTx()
+	if err := k.JusticeTx.Deserialize(bytes.NewReader(txBytes)); err != nil {
+		return err
+	}
+
+	// Each instruction transfers the asset to a distinct output, so a kit
+	// can't hold more instructions than its transaction has outputs. This
+	// also prevents a malformed blob from triggering a huge allocation.
+	numInsts, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+	if numInsts > uint64(len(k.JusticeTx.TxOut)) {
+		return fmt.Errorf("justice kit has %d instructions, but only "+
+			"%d outputs", numInsts, len(k.JusticeTx.TxOut))
+	}
+	k.Instructions = make([]lndcc.Instruction, numInsts)
+	for i := uint64(0); i < numInsts; i++ {
+		var scratch [13]byte
+		if _, err := io.ReadFull(r, scratch[:]); err != nil {
+			return err
+		}
+
+		k.Instructions[i] = lndcc.Instruction{
+			Skip:    scratch[0]&(1<<0) != 0,
+			Range:   scratch[0]&(1<<1) != 0,
+			Percent: scratch[0]&(1<<2) != 0,
+			Output:  byteOrder.Uint32(scratch[1:5]),
+			Amount:  int(byteOrder.Uint64(scratch[5:])),
+		}
+	}
+
+	// A kit ending after its instructions carries no fee bumps.
+	numBumps, err := wire.ReadVarInt(r, 0)
+	if err == io.EOF {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if numBumps > maxFeeBumps {
+		return fmt.Errorf("justice kit has %d fee bumps, max is %d",
+			numBumps, maxFeeBumps)
+	}
+	k.FeeBumps = make([]*wire.MsgTx, numBumps)
+	for i := range k.FeeBumps {
+		txBytes, err := wire.ReadVarBytes(r, 0, wire.MaxBlockPayload,
+			"fee bump tx")
+		if err != nil {
+			return err
+		}
+		k.FeeBumps[i] = wire.NewMsgTx()
+		err = k.FeeBumps[i].Deserialize(bytes.NewReader(txBytes))
+		if err != nil {
+			return err
+		}
+	}
+
+	var scratch [4]byte
+	if _, err := io.ReadFull(r, scratch[:]); err != nil {
+		return err
+	}
+	k.CsvDelay = byteOrder.Uint32(scratch[:])
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package watchtower
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestJusticeKitEncryptDecrypt(t *testing.T) {
+	commitTxid := wire.ShaHash{0x01, 0x02, 0x03}
+
+	justiceTx := wire.NewMsgTx()
+	justiceTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: commitTxid, Index: 1},
+		Witness: wire.TxWitness{
+			bytes.Repeat([]byte{0x30}, 72), {1},
+			bytes.Repeat([]byte{0x63}, 80),
+		},
+		Sequence: wire.MaxTxInSequenceNum,
+	})
+	justiceTx.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{0x00}, 22)))
+	justiceTx.AddTxOut(wire.NewTxOut(0, []byte{0x6a, 0x01, 0x02}))
+
+	kit := &JusticeKit{
+		JusticeTx: justiceTx,
+		Instructions: []lndcc.Instruction{
+			{Output: 0, Amount: 5000},
+			{Skip: true, Percent: true, Output: 1, Amount: 10},
+		},
+	}
+
+	blob, err := kit.Encrypt(&commitTxid)
+	if err != nil {
+		t.Fatalf("unable to encrypt justice kit: %v", err)
+	}
+
+	// The kit should decrypt properly with the txid of the revoked
+	// commitment.
+	decryptedKit, err := DecryptJusticeKit(blob, &commitTxid)
+	if err != nil {
+		t.Fatalf("unable to decrypt justice kit: %v", err)
+	}
+	if !reflect.DeepEqual(kit, decryptedKit) {
+		t.Fatalf("justice kits don't match: %v vs %v",
+			spew.Sdump(kit), spew.Sdump(decryptedKit))
+	}
+
+	// However, with any other txid, decryption should fail.
+	wrongTxid := wire.ShaHash{0x03, 0x02, 0x01}
+	if _, err := DecryptJusticeKit(blob, &wrongTxid); err == nil {
+		t.Fatalf("justice kit decrypted with wrong txid")
+	}
+
+	// Finally, the breach hint should be the prefix of the txid.
+	hint := NewBreachHint(&commitTxid)
+	if !bytes.Equal(hint[:], commitTxid[:BreachHintSize]) {
+		t.Fatalf("breach hint mismatch: %x vs %x", hint[:],
+			commitTxid[:BreachHintSize])
+	}
+}
+
+// TestJusticeKitInstructionBound tests that a justice kit holding more
+// instructions than its transaction has outputs is rejected.
+func TestJusticeKitInstructionBound(t *testing.T) {
+	justiceTx := wire.NewMsgTx()
+	justiceTx.AddTxIn(&wire.TxIn{})
+	justiceTx.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{0x00}, 22)))
+
+	kit := &JusticeKit{
+		JusticeTx: justiceTx,
+		Instructions: []lndcc.Instruction{
+			{Output: 0, Amount: 5000},
+			{Output: 1, Amount: 10},
+		},
+	}
+	var b bytes.Buffer
+	if err := kit.Encode(&b); err != nil {
+		t.Fatalf("unable to encode justice kit: %v", err)
+	}
+	if err := (&JusticeKit{}).Decode(&b); err == nil {
+		t.Fatalf("kit with too many instructions decoded")
+	}
+
+	// The same goes for a kit claiming a huge number of instructions,
+	// which mustn't be allocated up front.
+	b.Reset()
+	kit.Instructions = nil
+	if err := kit.Encode(&b); err != nil {
+		t.Fatalf("unable to encode justice kit: %v", err)
+	}
+	raw := b.Bytes()
+	raw = append(raw[:len(raw)-1], 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff)
+	if err := (&JusticeKit{}).Decode(bytes.NewReader(raw)); err == nil {
+		t.Fatalf("kit with huge instruction count decoded")
+	}
+}
+
+// TestJusticeKitFeeBumps tests that the fee bumps of a justice kit survive
+// an encode/decode round trip.
+func TestJusticeKitFeeBumps(t *testing.T) {
+	newJusticeTx := func(value int64) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.AddTxIn(&wire.TxIn{Sequence: wire.MaxTxInSequenceNum - 2})
+		tx.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{0x00}, 22)))
+		tx.AddTxOut(wire.NewTxOut(value, bytes.Repeat([]byte{0x00}, 22)))
+		return tx
+	}
+
+	kit := &JusticeKit{
+		JusticeTx:    newJusticeTx(3000),
+		Instructions: []lndcc.Instruction{{Output: 0, Amount: 5000}},
+		FeeBumps:     []*wire.MsgTx{newJusticeTx(2000), newJusticeTx(1000)},
+		CsvDelay:     144,
+	}
+
+	var b bytes.Buffer
+	if err := kit.Encode(&b); err != nil {
+		t.Fatalf("unable to encode justice kit: %v", err)
+	}
+	decodedKit := &JusticeKit{}
+	if err := decodedKit.Decode(&b); err != nil {
+		t.Fatalf("unable to decode justice kit: %v", err)
+	}
+	if !reflect.DeepEqual(kit, decodedKit) {
+		t.Fatalf("justice kits don't match: %v vs %v",
+			spew.Sdump(kit), spew.Sdump(decodedKit))
+	}
+}
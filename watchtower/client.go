@@ -0,0 +1,415 @@
+package watchtower
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/parnurzeal/gorequest"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/txscript"
+)
+
+const (
+	// BackupPath is the path relative to a tower's URL at which encrypted
+	// justice blobs are accepted.
+	BackupPath = "backup"
+
+	// uploadTimeout is the time allowed for a tower to accept a single
+	// justice blob.
+	uploadTimeout = time.Second * 30
+
+	// retryInterval is the interval at which the client re-attempts to
+	// upload justice blobs which a tower previously failed to accept.
+	retryInterval = time.Second * 30
+)
+
+// BackupMessage is the message sent to a tower in order to back up a single
+// revoked state. All fields are hex encoded.
+type BackupMessage struct {
+	// ClientID identifies the client to the tower, allowing the tower to
+	// enforce per-client storage quotas.
+	ClientID string `json:"clientId"`
+
+	// Hint is the breach hint of the revoked commitment.
+	Hint string `json:"hint"`
+
+	// Blob is the encrypted justice kit.
+	Blob string `json:"blob"`
+}
+
+// Tower is an abstraction over a remote watchtower which accepts encrypted
+// justice blobs.
+type Tower interface {
+	// SendBackup uploads the encrypted justice blob for the revoked
+	// commitment identified by the passed breach hint.
+	SendBackup(clientID string, hint BreachHint, blob []byte) error
+
+	// String returns a human readable identifier for the tower.
+	String() string
+}
+
+// httpTower is a Tower reachable over HTTP.
+type httpTower struct {
+	url string
+}
+
+// NewHTTPTower returns a Tower which uploads justice blobs to the tower
+// listening at the passed URL.
+func NewHTTPTower(url string) Tower {
+	return &httpTower{url: strings.TrimSuffix(url, "/")}
+}
+
+// SendBackup uploads the encrypted justice blob for the revoked commitment
+// identified by the passed breach hint.
+//
+// This is a part of the Tower interface.
+func (h *httpTower) SendBackup(clientID string, hint BreachHint,
+	blob []byte) error {
+
+	msg := &BackupMessage{
+		ClientID: clientID,
+		Hint:     hint.String(),
+		Blob:     hex.EncodeToString(blob),
+	}
+
+	resp, body, errs := gorequest.New().
+		Timeout(uploadTimeout).
+		Post(fmt.Sprintf("%s/%s", h.url, BackupPath)).
+		Set("Content-Type", "application/json").
+		Send(msg).
+		End()
+	if errs != nil {
+		return errs[0]
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tower rejected backup: %v %v", resp.Status,
+			body)
+	}
+
+	return nil
+}
+
+// String returns a human readable identifier for the tower.
+//
+// This is a part of the Tower interface.
+func (h *httpTower) String() string {
+	return h.url
+}
+
+// ClientConfig houses the resources required by the watchtower client.
+type ClientConfig struct {
+	// ID identifies this client to each of the towers.
+	ID string
+
+	// DB is the database in which the revoked states awaiting backup are
+	// queued, so they aren't lost should we shut down before each tower
+	// has accepted them.
+	DB *channeldb.DB
+
+	// Signer is used to sign the justice transactions backed up to the
+	// towers.
+	Signer lnwallet.Signer
+
+	// NewSweepScript returns a fresh output script under our control to
+	// which the funds swept by a justice transaction are sent.
+	NewSweepScript func() ([]byte, error)
+
+	// Towers is the set of towers each revoked state is backed up to.
+	Towers []Tower
+
+	// FeeRates is the ascending set of fee rates, in sat/vbyte, at which
+	// the justice transactions of each revoked state are signed. The
+	// towers broadcast the justice transaction at the lowest rate first,
+	// replacing it with those at higher rates should it fail to confirm.
+	// A final replacement paying all the carrier satoshis of the revoked
+	// outputs as fees is always included. If empty, then only the latter
+	// is signed.
+	FeeRates []uint64
+}
+
+// DefaultJusticeFeeRates is the default set of fee rates, in sat/vbyte, at
+// which justice transactions are signed.
+var DefaultJusticeFeeRates = []uint64{5, 20, 50}
+
+// pendingUpload is an encrypted justice blob which has yet to be accepted by
+// a particular tower.
+type pendingUpload struct {
+	tower Tower
+	hint  BreachHint
+	blob  []byte
+}
+
+// Client outsources breach protection of our channels to a set of
+// watchtowers. After each revocation received from a remote party, the client
+// creates a fully signed justice transaction sweeping the revoked commitment,
+// encrypts it using the txid of the revoked commitment, then uploads it to
+// each of the towers. Should the revoked commitment ever be broadcast while
+// we're offline, the towers are then able to decrypt, and broadcast the
+// justice transaction on our behalf.
+type Client struct {
+	started int32 // atomic
+	stopped int32 // atomic
+
+	cfg *ClientConfig
+
+	// newBackups is signalled each time a revoked state is queued. As the
+	// queue itself is persisted, a single pending signal suffices.
+	newBackups chan struct{}
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewClient creates a new watchtower client backed by the passed config.
+func NewClient(cfg *ClientConfig) *Client {
+	return &Client{
+		cfg:        cfg,
+		newBackups: make(chan struct{}, 1),
+		quit:       make(chan struct{}),
+	}
+}
+
+// Start launches the goroutine which backs up revoked states.
+func (c *Client) Start() error {
+	if atomic.AddInt32(&c.started, 1) != 1 {
+		return nil
+	}
+
+	log.Infof("Watchtower client starting, backing up to %v towers",
+		len(c.cfg.Towers))
+
+	c.wg.Add(1)
+	go c.backupDispatcher()
+
+	return nil
+}
+
+// Stop signals the client to exit. Revoked states which have yet to be
+// accepted by each of the towers remain queued, and are uploaded once the
+// client is restarted.
+func (c *Client) Stop() error {
+	if atomic.AddInt32(&c.stopped, 1) != 1 {
+		return nil
+	}
+
+	close(c.quit)
+	c.wg.Wait()
+
+	return nil
+}
+
+// BackupState queues the passed revoked commitment to be backed up to each of
+// the towers. commitKey is our commitment key within the channel the revoked
+// commitment belongs to, and csvDelay the delay of the remote party's output
+// within the commitment.
+//
+// The revoked state is persisted before returning, and uploaded in the
+// background, so the caller is never blocked on the towers.
+func (c *Client) BackupState(commitKey *btcec.PublicKey, csvDelay uint32,
+	revoked *channeldb.RevokedCommitment) error {
+
+	err := c.cfg.DB.AddTowerBackup(&channeldb.TowerBackup{
+		CommitKey: commitKey,
+		CsvDelay:  csvDelay,
+		Revoked:   revoked,
+	})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case c.newBackups <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// backupDispatcher creates and uploads a justice blob for each queued revoked
+// state, periodically re-attempting any uploads which previously failed. A
+// revoked state is removed from the queue once each of the towers has
+// accepted its justice blob.
+//
+// NOTE: This MUST be run as a goroutine.
+func (c *Client) backupDispatcher() {
+	defer c.wg.Done()
+
+	retryTicker := time.NewTicker(retryInterval)
+	defer retryTicker.Stop()
+
+	// The uploads yet to be accepted, keyed by the sequence number of
+	// their revoked state within the queue. States left queued by a
+	// previous run are uploaded to each of the towers once again, as the
+	// towers replace any blob previously stored for a breach hint.
+	pending := make(map[uint64][]*pendingUpload)
+	c.uploadQueued(pending, c.loadQueued(pending))
+
+	for {
+		select {
+		case <-c.newBackups:
+			c.uploadQueued(pending, c.loadQueued(pending))
+
+		case <-retryTicker.C:
+			// Revoked states whose justice blob couldn't be
+			// created are re-attempted along with the uploads.
+			c.loadQueued(pending)
+
+			seqs := make([]uint64, 0, len(pending))
+			for seq := range pending {
+				seqs = append(seqs, seq)
+			}
+			c.uploadQueued(pending, seqs)
+
+		case <-c.quit:
+			if len(pending) != 0 {
+				log.Infof("Leaving %v revoked states queued "+
+					"for backup", len(pending))
+			}
+			return
+		}
+	}
+}
+
+// loadQueued creates the justice blob of each queued revoked state not yet
+// within the passed set of pending uploads, adding an upload to each of the
+// towers. The sequence numbers of the newly added states are returned.
+func (c *Client) loadQueued(pending map[uint64][]*pendingUpload) []uint64 {
+	backups, err := c.cfg.DB.FetchTowerBackups()
+	if err != nil {
+		log.Errorf("unable to fetch queued revoked states: %v", err)
+		return nil
+	}
+
+	var added []uint64
+	for _, backup := range backups {
+		if _, ok := pending[backup.Seq]; ok {
+			continue
+		}
+
+		hint, blob, err := c.createJusticeBlob(backup)
+		if err != nil {
+			log.Errorf("unable to create justice blob for revoked "+
+				"commitment %v: %v", backup.Revoked.CommitTxid,
+				err)
+			continue
+		}
+
+		uploads := make([]*pendingUpload, len(c.cfg.Towers))
+		for i, tower := range c.cfg.Towers {
+			uploads[i] = &pendingUpload{
+				tower: tower,
+				hint:  hint,
+				blob:  blob,
+			}
+		}
+		pending[backup.Seq] = uploads
+		added = append(added, backup.Seq)
+	}
+
+	return added
+}
+
+// uploadQueued attempts the pending uploads of the revoked states with the
+// passed sequence numbers. Once each of the towers has accepted a state's
+// justice blob, the state is removed from the queue.
+func (c *Client) uploadQueued(pending map[uint64][]*pendingUpload,
+	seqs []uint64) {
+
+	for _, seq := range seqs {
+		var stillPending []*pendingUpload
+		for _, upload := range pending[seq] {
+			select {
+			case <-c.quit:
+				return
+			default:
+			}
+
+			if !c.upload(upload) {
+				stillPending = append(stillPending, upload)
+			}
+		}
+		if len(stillPending) != 0 {
+			pending[seq] = stillPending
+			continue
+		}
+
+		if err := c.cfg.DB.DeleteTowerBackup(seq); err != nil {
+			log.Errorf("unable to remove backed up state from "+
+				"queue: %v", err)
+			continue
+		}
+		delete(pending, seq)
+	}
+}
+
+// upload attempts to send the passed justice blob to its tower, returning
+// true if the tower accepted it.
+func (c *Client) upload(u *pendingUpload) bool {
+	if err := u.tower.SendBackup(c.cfg.ID, u.hint, u.blob); err != nil {
+		log.Warnf("unable to back up state with hint %v to tower "+
+			"%v: %v", u.hint, u.tower, err)
+		return false
+	}
+
+	log.Debugf("Backed up state with hint %v to tower %v", u.hint,
+		u.tower)
+
+	return true
+}
+
+// createJusticeBlob creates a fully signed justice transaction for the
+// requested revoked state at each of the configured fee rates, then encrypts
+// them, returning the encrypted blob along with its breach hint.
+func (c *Client) createJusticeBlob(req *channeldb.TowerBackup) (BreachHint, []byte, error) {
+	sweepScript, err := c.cfg.NewSweepScript()
+	if err != nil {
+		return BreachHint{}, nil, err
+	}
+
+	// Sign a justice transaction at each fee rate, followed by one paying
+	// all the carrier satoshis as fees. Once a fee rate leaves too few
+	// satoshis to return, the transaction is the same as the latter, so
+	// no higher rates are signed.
+	feeRates := make([]uint64, 0, len(c.cfg.FeeRates)+1)
+	feeRates = append(feeRates, c.cfg.FeeRates...)
+	feeRates = append(feeRates, 0)
+
+	var justiceTxs []*lnwallet.JusticeTx
+	for _, feeRate := range feeRates {
+		justice, err := lnwallet.CreateJusticeTx(c.cfg.Signer,
+			req.CommitKey, req.Revoked, sweepScript, feeRate)
+		if err != nil {
+			return BreachHint{}, nil, err
+		}
+		justiceTxs = append(justiceTxs, justice)
+
+		// Without any satoshis returned, the OP_RETURN output is the
+		// last output.
+		lastOut := justice.Tx.TxOut[len(justice.Tx.TxOut)-1]
+		if txscript.GetScriptClass(lastOut.PkScript) == txscript.NullDataTy {
+			break
+		}
+	}
+
+	kit := &JusticeKit{
+		JusticeTx:    justiceTxs[0].Tx,
+		Instructions: justiceTxs[0].Instructions,
+		CsvDelay:     req.CsvDelay,
+	}
+	for _, justice := range justiceTxs[1:] {
+		kit.FeeBumps = append(kit.FeeBumps, justice.Tx)
+	}
+	blob, err := kit.Encrypt(&req.Revoked.CommitTxid)
+	if err != nil {
+		return BreachHint{}, nil, err
+	}
+
+	return NewBreachHint(&req.Revoked.CommitTxid), blob, nil
+}
@@ -0,0 +1,40 @@
+//go:build gofuzz
+// +build gofuzz
+
+package watchtower
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Fuzz is the go-fuzz entry point for the decoding of justice kits. As the
+// plaintext of a justice blob is chosen by the client, malformed kits must be
+// rejected without crashing the tower. Any kit which decodes successfully
+// must re-encode into a stable serialization.
+func Fuzz(data []byte) int {
+	kit := &JusticeKit{}
+	if err := kit.Decode(bytes.NewReader(data)); err != nil {
+		return 0
+	}
+
+	var b bytes.Buffer
+	if err := kit.Encode(&b); err != nil {
+		panic(fmt.Sprintf("unable to encode decoded kit: %v", err))
+	}
+
+	kit2 := &JusticeKit{}
+	if err := kit2.Decode(bytes.NewReader(b.Bytes())); err != nil {
+		panic(fmt.Sprintf("unable to decode encoded kit: %v", err))
+	}
+	var b2 bytes.Buffer
+	if err := kit2.Encode(&b2); err != nil {
+		panic(fmt.Sprintf("unable to encode decoded kit: %v", err))
+	}
+	if !bytes.Equal(b.Bytes(), b2.Bytes()) {
+		panic(fmt.Sprintf("unstable encoding: %x vs %x", b.Bytes(),
+			b2.Bytes()))
+	}
+
+	return 1
+}
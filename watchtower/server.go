@@ -0,0 +1,512 @@
+package watchtower
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/boltdb/bolt"
+	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/lnwallet"
+	"github.com/roasbeef/btcd/wire"
+)
+
+const (
+	// towerDBName is the name of the database file the tower stores all
+	// accepted justice blobs within.
+	towerDBName = "watchtower.db"
+
+	// maxBlobSize is the largest encrypted justice blob the tower will
+	// accept.
+	maxBlobSize = 1 << 16
+
+	// maxClientIDSize is the longest client ID the tower will accept.
+	maxClientIDSize = 128
+
+	// DefaultMaxBlobsPerClient is the default number of justice blobs the
+	// tower will store on behalf of a single client.
+	DefaultMaxBlobsPerClient = 100000
+)
+
+var (
+	// blobBucket stores all accepted justice blobs. Entries are keyed by:
+	// breachHint || clientID, allowing all blobs for a breach hint to be
+	// found with a single prefix scan, while preventing one client from
+	// overwriting the blobs of another.
+	blobBucket = []byte("blobs")
+
+	// quotaBucket maps each client ID to the number of blobs currently
+	// stored on behalf of the client.
+	quotaBucket = []byte("quotas")
+
+	// ErrQuotaExceeded is returned when a client attempts to store more
+	// than the configured maximum number of blobs with the tower.
+	ErrQuotaExceeded = fmt.Errorf("client blob quota exceeded")
+)
+
+// ServerConfig houses the resources and parameters required by the
+// watchtower server.
+type ServerConfig struct {
+	// DBPath is the directory in which the tower's database is stored.
+	DBPath string
+
+	// ListenAddr is the address the tower listens on for backups from
+	// clients.
+	ListenAddr string
+
+	// Notifier is used to receive notifications of each newly connected
+	// block.
+	Notifier chainntnfs.ChainNotifier
+
+	// ChainIO is used to fetch the full contents of each newly connected
+	// block.
+	ChainIO lnwallet.BlockChainIO
+
+	// PublishTransaction broadcasts the passed justice transaction.
+	PublishTransaction func(*wire.MsgTx) error
+
+	// MaxBlobsPerClient is the maximum number of justice blobs the tower
+	// will store on behalf of a single client.
+	MaxBlobsPerClient uint32
+}
+
+// Server is a watchtower which accepts encrypted justice blobs from clients,
+// and watches the chain for the revoked commitments the blobs sweep. Once a
+// transaction matching the breach hint of a stored blob is confirmed, the
+// blob is decrypted using the transaction's txid, and the justice transaction
+// within broadcast.
+type Server struct {
+	started int32 // atomic
+	stopped int32 // atomic
+
+	cfg *ServerConfig
+
+	db       *bolt.DB
+	listener net.Listener
+
+	// bumps tracks the justice transactions broadcast by the tower which
+	// have yet to confirm, keyed by the txid of the revoked commitment
+	// each sweeps. It's only accessed by the breachWatcher.
+	bumps map[wire.ShaHash]*justiceBump
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewServer creates a new watchtower server, opening, or creating the
+// tower's database within the configured directory.
+func NewServer(cfg *ServerConfig) (*Server, error) {
+	if err := os.MkdirAll(cfg.DBPath, 0700); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(filepath.Join(cfg.DBPath, towerDBName), 0600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(blobBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(quotaBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if cfg.MaxBlobsPerClient == 0 {
+		cfg.MaxBlobsPerClient = DefaultMaxBlobsPerClient
+	}
+
+	return &Server{
+		cfg:   cfg,
+		db:    db,
+		bumps: make(map[wire.ShaHash]*justiceBump),
+		quit:  make(chan struct{}),
+	}, nil
+}
+
+// Start begins accepting backups from clients, and watching the chain for
+// breaches.
+func (s *Server) Start() error {
+	if atomic.AddInt32(&s.started, 1) != 1 {
+		return nil
+	}
+
+	blockEpochs, err := s.cfg.Notifier.RegisterBlockEpochNtfn()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+
+	log.Infof("Watchtower server listening on %v", s.cfg.ListenAddr)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+BackupPath, s.handleBackup)
+
+	s.wg.Add(2)
+	go func() {
+		defer s.wg.Done()
+		http.Serve(listener, mux)
+	}()
+	go s.breachWatcher(blockEpochs)
+
+	return nil
+}
+
+// Stop signals the server to exit, blocking until all goroutines have exited.
+func (s *Server) Stop() error {
+	if atomic.AddInt32(&s.stopped, 1) != 1 {
+		return nil
+	}
+
+	close(s.quit)
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	s.wg.Wait()
+
+	return s.db.Close()
+}
+
+// handleBackup handles a request from a client to store a justice blob.
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var msg BackupMessage
+	body := http.MaxBytesReader(w, r.Body, 2*maxBlobSize+1024)
+	if err := json.NewDecoder(body).Decode(&msg); err != nil {
+		http.Error(w, "malformed backup message", http.StatusBadRequest)
+		return
+	}
+
+	hintBytes, err := hex.DecodeString(msg.Hint)
+	if err != nil || len(hintBytes) != BreachHintSize {
+		http.Error(w, "invalid breach hint", http.StatusBadRequest)
+		return
+	}
+	var hint BreachHint
+	copy(hint[:], hintBytes)
+
+	blob, err := hex.DecodeString(msg.Blob)
+	if err != nil || len(blob) == 0 || len(blob) > maxBlobSize {
+		http.Error(w, "invalid justice blob", http.StatusBadRequest)
+		return
+	}
+
+	if len(msg.ClientID) == 0 || len(msg.ClientID) > maxClientIDSize {
+		http.Error(w, "invalid client id", http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.StoreBlob(msg.ClientID, hint, blob); {
+	case err == ErrQuotaExceeded:
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case err != nil:
+		log.Errorf("unable to store blob for client %v: %v",
+			msg.ClientID, err)
+		http.Error(w, "unable to store blob",
+			http.StatusInternalServerError)
+	}
+}
+
+// StoreBlob stores the passed justice blob on behalf of the client. If the
+// client has already stored a blob for the breach hint, then it is replaced.
+// ErrQuotaExceeded is returned if the client already has the maximum number
+// of blobs stored.
+func (s *Server) StoreBlob(clientID string, hint BreachHint, blob []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		blobs := tx.Bucket(blobBucket)
+		quotas := tx.Bucket(quotaBucket)
+
+		var numBlobs uint32
+		if numBytes := quotas.Get([]byte(clientID)); numBytes != nil {
+			numBlobs = byteOrder.Uint32(numBytes)
+		}
+
+		// Replacing an existing blob doesn't count against the
+		// client's quota.
+		blobKey := makeBlobKey(hint, clientID)
+		if blobs.Get(blobKey) == nil {
+			if numBlobs >= s.cfg.MaxBlobsPerClient {
+				return ErrQuotaExceeded
+			}
+			numBlobs++
+		}
+
+		if err := blobs.Put(blobKey, blob); err != nil {
+			return err
+		}
+
+		var scratch [4]byte
+		byteOrder.PutUint32(scratch[:], numBlobs)
+		return quotas.Put([]byte(clientID), scratch[:])
+	})
+}
+
+// breachWatcher scans each newly connected block for revoked commitments
+// matching the breach hint of a stored justice blob.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *Server) breachWatcher(blockEpochs *chainntnfs.BlockEpochEvent) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case epoch, ok := <-blockEpochs.Epochs:
+			if !ok {
+				return
+			}
+
+			block, err := s.cfg.ChainIO.GetBlock(epoch.Hash)
+			if err != nil {
+				log.Errorf("unable to fetch block %v: %v",
+					epoch.Hash, err)
+				continue
+			}
+
+			height := uint32(epoch.Height)
+			if err := s.handleBlock(block, height); err != nil {
+				log.Errorf("unable to handle block %v: %v",
+					epoch.Hash, err)
+			}
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// handleBlock checks each transaction within the passed block against the
+// set of stored justice blobs. For each match, the blob is decrypted, and the
+// justice transaction within broadcast. Matched blobs are then removed. Any
+// justice transactions broadcast previously which have yet to confirm are
+// then fee bumped as needed.
+func (s *Server) handleBlock(block *wire.MsgBlock, height uint32) error {
+	// First, stop tracking any justice transactions confirmed within the
+	// block.
+	for _, tx := range block.Transactions {
+		for commitTxid, bump := range s.bumps {
+			if bump.isJustice(tx) {
+				log.Infof("Justice tx %v for breach %v "+
+					"confirmed", tx.TxSha(), commitTxid)
+				delete(s.bumps, commitTxid)
+			}
+		}
+	}
+
+	for _, tx := range block.Transactions {
+		txid := tx.TxSha()
+		hint := NewBreachHint(&txid)
+
+		matches, err := s.fetchBlobs(hint)
+		if err != nil {
+			return err
+		}
+		if len(matches) == 0 {
+			continue
+		}
+
+		log.Infof("Found %v justice blobs for breach hint %v within "+
+			"tx %v", len(matches), hint, txid)
+
+		for clientID, blob := range matches {
+			// As the hint is only a prefix of the txid, a match
+			// may be a false positive, in which case decryption
+			// will fail.
+			kit, err := DecryptJusticeKit(blob, &txid)
+			if err != nil {
+				log.Debugf("unable to decrypt blob of client "+
+					"%v for tx %v: %v", clientID, txid, err)
+				continue
+			}
+
+			log.Infof("Breach of client %v detected in tx %v, "+
+				"broadcasting justice tx %v", clientID, txid,
+				kit.JusticeTx.TxSha())
+
+			if err := s.cfg.PublishTransaction(kit.JusticeTx); err != nil {
+				log.Errorf("unable to broadcast justice tx: %v",
+					err)
+				continue
+			}
+
+			// If the kit holds any fee bumps, then track the
+			// justice transaction until it confirms.
+			if len(kit.FeeBumps) != 0 {
+				s.bumps[txid] = &justiceBump{
+					kit:          kit,
+					breachHeight: height,
+				}
+			}
+
+			if err := s.removeBlob(clientID, hint); err != nil {
+				return err
+			}
+		}
+	}
+
+	for commitTxid, bump := range s.bumps {
+		s.bumpJustice(&commitTxid, bump, height)
+	}
+
+	return nil
+}
+
+// justiceBump tracks a broadcast justice transaction which has yet to
+// confirm.
+type justiceBump struct {
+	kit *JusticeKit
+
+	// breachHeight is the height at which the revoked commitment was
+	// confirmed.
+	breachHeight uint32
+
+	// tier is the index of the transaction last broadcast: zero for the
+	// kit's JusticeTx, and i for FeeBumps[i-1].
+	tier int
+}
+
+// isJustice returns true if the passed transaction is one of the justice
+// transactions within the kit.
+func (b *justiceBump) isJustice(tx *wire.MsgTx) bool {
+	txid := tx.TxSha()
+	if txid == b.kit.JusticeTx.TxSha() {
+		return true
+	}
+	for _, bump := range b.kit.FeeBumps {
+		if txid == bump.TxSha() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bumpTier returns the tier of the justice transaction which should have
+// been broadcast once the passed number of blocks have elapsed since the
+// breach. The tiers are spread across the first half of the CSV delay, so
+// that the justice transaction paying the highest fee is broadcast with half
+// the delay remaining before the breaching party may sweep its output.
+func bumpTier(elapsed, csvDelay uint32, numBumps int) int {
+	window := csvDelay / 2
+	if window == 0 || elapsed >= window {
+		return numBumps
+	}
+
+	tier := int(uint64(elapsed) * uint64(numBumps+1) / uint64(window))
+	if tier > numBumps {
+		tier = numBumps
+	}
+
+	return tier
+}
+
+// bumpJustice broadcasts the next fee bump of the tracked justice
+// transaction if its tier has been reached. Once the CSV delay has elapsed,
+// the justice transaction is no longer tracked.
+func (s *Server) bumpJustice(commitTxid *wire.ShaHash, bump *justiceBump,
+	height uint32) {
+
+	elapsed := height - bump.breachHeight
+	if elapsed >= bump.kit.CsvDelay {
+		log.Warnf("Justice tx for breach %v unconfirmed after csv "+
+			"delay of %v blocks", commitTxid, bump.kit.CsvDelay)
+		delete(s.bumps, *commitTxid)
+		return
+	}
+
+	tier := bumpTier(elapsed, bump.kit.CsvDelay, len(bump.kit.FeeBumps))
+	if tier <= bump.tier {
+		return
+	}
+
+	tx := bump.kit.FeeBumps[tier-1]
+	log.Infof("Justice tx for breach %v unconfirmed after %v of %v "+
+		"blocks, replacing with fee bump %v", commitTxid, elapsed,
+		bump.kit.CsvDelay, tx.TxSha())
+
+	if err := s.cfg.PublishTransaction(tx); err != nil {
+		log.Errorf("unable to broadcast fee bump: %v", err)
+		return
+	}
+	bump.tier = tier
+}
+
+// fetchBlobs returns all blobs stored for the passed breach hint, keyed by
+// the client which stored them.
+func (s *Server) fetchBlobs(hint BreachHint) (map[string][]byte, error) {
+	matches := make(map[string][]byte)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(blobBucket).Cursor()
+		for k, v := c.Seek(hint[:]); k != nil &&
+			bytes.HasPrefix(k, hint[:]); k, v = c.Next() {
+
+			clientID := string(k[BreachHintSize:])
+			matches[clientID] = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// removeBlob removes the blob stored by the client for the passed breach
+// hint, crediting the client's quota.
+func (s *Server) removeBlob(clientID string, hint BreachHint) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		blobs := tx.Bucket(blobBucket)
+		quotas := tx.Bucket(quotaBucket)
+
+		blobKey := makeBlobKey(hint, clientID)
+		if blobs.Get(blobKey) == nil {
+			return nil
+		}
+		if err := blobs.Delete(blobKey); err != nil {
+			return err
+		}
+
+		numBytes := quotas.Get([]byte(clientID))
+		if numBytes == nil {
+			return nil
+		}
+		numBlobs := byteOrder.Uint32(numBytes)
+		if numBlobs > 0 {
+			numBlobs--
+		}
+
+		var scratch [4]byte
+		byteOrder.PutUint32(scratch[:], numBlobs)
+		return quotas.Put([]byte(clientID), scratch[:])
+	})
+}
+
+// makeBlobKey returns the key of the blob stored by the client for the passed
+// breach hint: breachHint || clientID.
+func makeBlobKey(hint BreachHint, clientID string) []byte {
+	key := make([]byte, BreachHintSize+len(clientID))
+	copy(key, hint[:])
+	copy(key[BreachHintSize:], clientID)
+	return key
+}
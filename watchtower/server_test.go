@@ -0,0 +1,203 @@
+package watchtower
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/lightningnetwork/lnd/lndcc"
+	"github.com/roasbeef/btcd/wire"
+)
+
+func TestServerQuotaAndBreachMatch(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "watchtower")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var published []*wire.MsgTx
+	server, err := NewServer(&ServerConfig{
+		DBPath: tempDir,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			published = append(published, tx)
+			return nil
+		},
+		MaxBlobsPerClient: 2,
+	})
+	if err != nil {
+		t.Fatalf("unable to create server: %v", err)
+	}
+	defer server.db.Close()
+
+	// Create a revoked commitment transaction, along with a justice kit
+	// sweeping it.
+	revokedCommit := wire.NewMsgTx()
+	revokedCommit.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	revokedCommit.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{1}, 34)))
+	commitTxid := revokedCommit.TxSha()
+
+	justiceTx := wire.NewMsgTx()
+	justiceTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&commitTxid, 0), nil, nil))
+	justiceTx.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{2}, 22)))
+	kit := &JusticeKit{
+		JusticeTx:    justiceTx,
+		Instructions: []lndcc.Instruction{{Output: 0, Amount: 1000}},
+	}
+	blob, err := kit.Encrypt(&commitTxid)
+	if err != nil {
+		t.Fatalf("unable to encrypt justice kit: %v", err)
+	}
+	hint := NewBreachHint(&commitTxid)
+
+	// The client should be able to store two blobs, replacing a blob
+	// shouldn't count against its quota, but a third distinct blob should
+	// be rejected.
+	if err := server.StoreBlob("alice", hint, blob); err != nil {
+		t.Fatalf("unable to store blob: %v", err)
+	}
+	if err := server.StoreBlob("alice", hint, blob); err != nil {
+		t.Fatalf("unable to replace blob: %v", err)
+	}
+	if err := server.StoreBlob("alice", BreachHint{1}, blob); err != nil {
+		t.Fatalf("unable to store blob: %v", err)
+	}
+	if err := server.StoreBlob("alice", BreachHint{2}, blob); err != ErrQuotaExceeded {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+
+	// Another client storing a blob under the same hint shouldn't
+	// clobber the first client's blob.
+	if err := server.StoreBlob("mallory", hint, []byte("junk")); err != nil {
+		t.Fatalf("unable to store blob: %v", err)
+	}
+
+	// A block without the revoked commitment shouldn't trigger anything.
+	block := &wire.MsgBlock{
+		Transactions: []*wire.MsgTx{justiceTx},
+	}
+	if err := server.handleBlock(block, 100); err != nil {
+		t.Fatalf("unable to handle block: %v", err)
+	}
+	if len(published) != 0 {
+		t.Fatalf("no justice tx should've been published")
+	}
+
+	// Once the revoked commitment is confirmed, the justice transaction
+	// should be broadcast, and the blob removed, freeing up the client's
+	// quota.
+	block.Transactions = append(block.Transactions, revokedCommit)
+	if err := server.handleBlock(block, 100); err != nil {
+		t.Fatalf("unable to handle block: %v", err)
+	}
+	if len(published) != 1 {
+		t.Fatalf("expected 1 justice tx to be published, instead "+
+			"have %v", len(published))
+	}
+	if published[0].TxSha() != justiceTx.TxSha() {
+		t.Fatalf("wrong justice tx published")
+	}
+	if err := server.StoreBlob("alice", BreachHint{2}, blob); err != nil {
+		t.Fatalf("unable to store blob after quota freed: %v", err)
+	}
+}
+
+// TestJusticeFeeBumps tests that the tower replaces an unconfirmed justice
+// transaction with each of its fee bumps in turn as the breaching party's
+// output nears maturity, and stops once one of them confirms.
+func TestJusticeFeeBumps(t *testing.T) {
+	// With a CSV delay of 20 blocks, the two fee bumps are spread across
+	// the first 10 blocks.
+	tiers := []struct {
+		elapsed uint32
+		tier    int
+	}{
+		{0, 0}, {3, 0}, {4, 1}, {6, 1}, {7, 2}, {10, 2}, {19, 2},
+	}
+	for _, test := range tiers {
+		tier := bumpTier(test.elapsed, 20, 2)
+		if tier != test.tier {
+			t.Fatalf("expected tier %v after %v blocks, got %v",
+				test.tier, test.elapsed, tier)
+		}
+	}
+
+	tempDir, err := ioutil.TempDir("", "watchtower")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var published []*wire.MsgTx
+	server, err := NewServer(&ServerConfig{
+		DBPath: tempDir,
+		PublishTransaction: func(tx *wire.MsgTx) error {
+			published = append(published, tx)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to create server: %v", err)
+	}
+	defer server.db.Close()
+
+	revokedCommit := wire.NewMsgTx()
+	revokedCommit.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	revokedCommit.AddTxOut(wire.NewTxOut(546, bytes.Repeat([]byte{1}, 34)))
+	commitTxid := revokedCommit.TxSha()
+
+	newJusticeTx := func(value int64) *wire.MsgTx {
+		tx := wire.NewMsgTx()
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&commitTxid, 0), nil, nil))
+		tx.AddTxOut(wire.NewTxOut(value, bytes.Repeat([]byte{2}, 22)))
+		return tx
+	}
+	kit := &JusticeKit{
+		JusticeTx:    newJusticeTx(3000),
+		Instructions: []lndcc.Instruction{{Output: 0, Amount: 1000}},
+		FeeBumps:     []*wire.MsgTx{newJusticeTx(2000), newJusticeTx(1000)},
+		CsvDelay:     20,
+	}
+	blob, err := kit.Encrypt(&commitTxid)
+	if err != nil {
+		t.Fatalf("unable to encrypt justice kit: %v", err)
+	}
+	if err := server.StoreBlob("alice", NewBreachHint(&commitTxid), blob); err != nil {
+		t.Fatalf("unable to store blob: %v", err)
+	}
+
+	// The breach at height 100 triggers the broadcast of the justice
+	// transaction paying the lowest fee.
+	block := &wire.MsgBlock{Transactions: []*wire.MsgTx{revokedCommit}}
+	if err := server.handleBlock(block, 100); err != nil {
+		t.Fatalf("unable to handle block: %v", err)
+	}
+
+	// Each fee bump is broadcast once its tier is reached, and only once.
+	emptyBlock := &wire.MsgBlock{}
+	for height := uint32(101); height <= 108; height++ {
+		if err := server.handleBlock(emptyBlock, height); err != nil {
+			t.Fatalf("unable to handle block: %v", err)
+		}
+	}
+	if len(published) != 3 {
+		t.Fatalf("expected 3 justice txs to be published, instead "+
+			"have %v", len(published))
+	}
+	for i, tx := range append([]*wire.MsgTx{kit.JusticeTx}, kit.FeeBumps...) {
+		if published[i].TxSha() != tx.TxSha() {
+			t.Fatalf("justice tx #%v published out of order", i)
+		}
+	}
+
+	// Once a fee bump confirms, the justice transaction is no longer
+	// tracked.
+	block.Transactions = []*wire.MsgTx{kit.FeeBumps[1]}
+	if err := server.handleBlock(block, 109); err != nil {
+		t.Fatalf("unable to handle block: %v", err)
+	}
+	if len(server.bumps) != 0 {
+		t.Fatalf("confirmed justice tx still tracked")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// defaultZeroConfTimeout is the default number of blocks within which the
+// funding transaction of a zero-conf channel must confirm.
+const defaultZeroConfTimeout = 6
+
+// zeroConfPolicy dictates which peers we accept zero-conf channels from.
+// Such channels are usable as soon as their funding transaction has been
+// broadcast, so the peers must be trusted not to double spend it.
+type zeroConfPolicy struct {
+	// peers is the set of lightning IDs of the peers we accept zero-conf
+	// channels from.
+	peers map[wire.ShaHash]struct{}
+
+	// timeout is the number of blocks within which the funding
+	// transaction of a zero-conf channel must confirm. Otherwise, the
+	// channel is removed from the channel graph, and force closed.
+	timeout uint32
+}
+
+// newZeroConfPolicy creates a new zeroConfPolicy accepting zero-conf channels
+// from the peers with the passed hex encoded identity public keys.
+func newZeroConfPolicy(peers []string, timeout uint32) (*zeroConfPolicy, error) {
+	if len(peers) != 0 && timeout == 0 {
+		return nil, fmt.Errorf("zero-conf timeout must be positive")
+	}
+
+	p := &zeroConfPolicy{
+		peers:   make(map[wire.ShaHash]struct{}),
+		timeout: timeout,
+	}
+	for _, peerHex := range peers {
+		pubKey, err := parseReplicaPubKey(peerHex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zero-conf peer %q: %v",
+				peerHex, err)
+		}
+
+		lightningID := fastsha256.Sum256(pubKey.SerializeCompressed())
+		p.peers[wire.ShaHash(lightningID)] = struct{}{}
+	}
+
+	return p, nil
+}
+
+// accepts returns true if zero-conf channels are accepted from the peer with
+// the passed lightning ID.
+func (p *zeroConfPolicy) accepts(lightningID wire.ShaHash) bool {
+	_, ok := p.peers[lightningID]
+	return ok
+}
+
+// watchZeroConfChannel waits for the funding transaction of the passed
+// zero-conf channel to confirm. If it fails to confirm within the timeout of
+// the zero-conf policy, then the channel is no longer trusted: it's removed
+// from the channel graph so that it isn't routed over, then force closed. As
+// the watch isn't persisted, it's re-armed for each channel whose funding
+// transaction is still unconfirmed once loaded on restart.
+//
+// NOTE: This MUST be run as a goroutine.
+func (s *server) watchZeroConfChannel(chanPoint wire.OutPoint) {
+	confNtfn, err := s.chainNotifier.RegisterConfirmationsNtfn(
+		&chanPoint.Hash, 1)
+	if err != nil {
+		srvrLog.Errorf("unable to watch funding tx of zero-conf "+
+			"ChannelPoint(%v): %v", chanPoint, err)
+		return
+	}
+	blockEpochs, err := s.chainNotifier.RegisterBlockEpochNtfn()
+	if err != nil {
+		srvrLog.Errorf("unable to watch funding tx of zero-conf "+
+			"ChannelPoint(%v): %v", chanPoint, err)
+		return
+	}
+
+	numBlocks := uint32(0)
+	for numBlocks < s.zeroConf.timeout {
+		select {
+		case height, ok := <-confNtfn.Confirmed:
+			if !ok {
+				return
+			}
+
+			srvrLog.Infof("Funding tx of zero-conf ChannelPoint(%v) "+
+				"confirmed at height %v", chanPoint, height)
+			return
+
+		case _, ok := <-blockEpochs.Epochs:
+			if !ok {
+				return
+			}
+			numBlocks++
+
+		case <-s.quit:
+			return
+		}
+	}
+
+	srvrLog.Warnf("Funding tx of zero-conf ChannelPoint(%v) unconfirmed "+
+		"after %v blocks, force closing", chanPoint, numBlocks)
+
+	s.chanGraph.RemoveChannel(chanPoint)
+	updates, errChan := s.htlcSwitch.CloseLink(&chanPoint, true, nil)
+	select {
+	case <-updates:
+	case err := <-errChan:
+		srvrLog.Errorf("unable to force close zero-conf "+
+			"ChannelPoint(%v): %v", chanPoint, err)
+	case <-s.quit:
+	}
+}
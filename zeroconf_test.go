@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/fastsha256"
+	"github.com/roasbeef/btcd/btcec"
+	"github.com/roasbeef/btcd/wire"
+)
+
+// TestZeroConfPolicy tests that zero-conf channels are only accepted from
+// the configured peers, identified by their lightning IDs.
+func TestZeroConfPolicy(t *testing.T) {
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	pubBytes := priv.PubKey().SerializeCompressed()
+	trusted := wire.ShaHash(fastsha256.Sum256(pubBytes))
+
+	policy, err := newZeroConfPolicy([]string{hex.EncodeToString(pubBytes)},
+		defaultZeroConfTimeout)
+	if err != nil {
+		t.Fatalf("unable to create policy: %v", err)
+	}
+	if !policy.accepts(trusted) {
+		t.Fatalf("zero-conf channels from trusted peer rejected")
+	}
+	if policy.accepts(wire.ShaHash{1}) {
+		t.Fatalf("zero-conf channels from untrusted peer accepted")
+	}
+
+	// By default, zero-conf channels aren't accepted from anyone.
+	policy, err = newZeroConfPolicy(nil, defaultZeroConfTimeout)
+	if err != nil {
+		t.Fatalf("unable to create policy: %v", err)
+	}
+	if policy.accepts(trusted) {
+		t.Fatalf("zero-conf channels accepted by default")
+	}
+
+	if _, err := newZeroConfPolicy([]string{"zz"}, 6); err == nil {
+		t.Fatalf("invalid peer key accepted")
+	}
+	_, err = newZeroConfPolicy([]string{hex.EncodeToString(pubBytes)}, 0)
+	if err == nil {
+		t.Fatalf("zero timeout accepted")
+	}
+}